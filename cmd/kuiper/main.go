@@ -1133,6 +1133,44 @@ func main() {
 				},
 			},
 		},
+		{
+			Name:    "test",
+			Aliases: []string{"test"},
+			Usage:   "test rule -f $test_case_file",
+			Subcommands: []cli.Command{
+				{
+					Name:  "rule",
+					Usage: "test rule -f $test_case_file",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:     "file, f",
+							Usage:    "the location of the test case file in yaml or json",
+							FilePath: "/home/mycase.yaml",
+						},
+					},
+					Action: func(c *cli.Context) error {
+						sfile := c.String("file")
+						if sfile == "" {
+							fmt.Printf("Expect test case file.\n")
+							return nil
+						}
+						// The content in yaml or json is parsed by the server
+						content, err := os.ReadFile(sfile)
+						if err != nil {
+							return cli.NewExitError(fmt.Sprintf("Failed to read test case file %s: %v", sfile, err), 1)
+						}
+						var reply string
+						err = client.Call("Server.TestRule", string(content), &reply)
+						if err != nil {
+							// Exit with error code for CI
+							return cli.NewExitError(err.Error(), 1)
+						}
+						fmt.Println(reply)
+						return nil
+					},
+				},
+			},
+		},
 		{
 			Name:    "register",
 			Aliases: []string{"register"},
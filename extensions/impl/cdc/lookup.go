@@ -0,0 +1,261 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdc
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/util"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+// table is the replica of a database table in memory. The rows are indexed by the primary key.
+type table struct {
+	sync.RWMutex
+	rows map[string]map[string]any
+}
+
+func newTable() *table {
+	return &table{rows: make(map[string]map[string]any)}
+}
+
+func (t *table) apply(c *change) {
+	t.Lock()
+	defer t.Unlock()
+	switch c.kind {
+	case ast.RowkindDelete:
+		delete(t.rows, rowKey(c.keys, c.before))
+	case ast.RowkindUpdate:
+		// The primary key may be updated
+		if c.before != nil {
+			delete(t.rows, rowKey(c.keys, c.before))
+		}
+		t.rows[rowKey(c.keys, c.after)] = c.after
+	default:
+		t.rows[rowKey(c.keys, c.after)] = c.after
+	}
+}
+
+func (t *table) replace(rows map[string]map[string]any) {
+	t.Lock()
+	defer t.Unlock()
+	t.rows = rows
+}
+
+func (t *table) lookup(fields []string, keys []string, values []any) []map[string]any {
+	t.RLock()
+	defer t.RUnlock()
+	var result []map[string]any
+	for _, row := range t.rows {
+		if !matchRow(row, keys, values) {
+			continue
+		}
+		r := make(map[string]any, len(row))
+		if len(fields) == 0 {
+			for k, v := range row {
+				r[k] = v
+			}
+		} else {
+			for _, f := range fields {
+				if v, ok := row[f]; ok {
+					r[f] = v
+				}
+			}
+		}
+		result = append(result, r)
+	}
+	return result
+}
+
+// rowKey is the primary key values of the row. The whole row is the key if the primary key is unknown.
+func rowKey(keys []string, row map[string]any) string {
+	var b []byte
+	if len(keys) == 0 {
+		b, _ = json.Marshal(row)
+	} else {
+		vals := make([]any, len(keys))
+		for i, k := range keys {
+			vals[i] = row[k]
+		}
+		b, _ = json.Marshal(vals)
+	}
+	return string(b)
+}
+
+func matchRow(row map[string]any, keys []string, values []any) bool {
+	for i, k := range keys {
+		if !equalValue(row[k], values[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// equalValue compares the numbers by value because the type of the lookup value may be different from the column
+func equalValue(a, b any) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	if isNumber(a) && isNumber(b) {
+		fa, _ := cast.ToFloat64(a, cast.CONVERT_SAMEKIND)
+		fb, _ := cast.ToFloat64(b, cast.CONVERT_SAMEKIND)
+		return fa == fb
+	}
+	return false
+}
+
+func isNumber(v any) bool {
+	switch v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return true
+	}
+	return false
+}
+
+// LookupSource keeps the replica of a table in memory to look up. It reads the snapshot of the table and then
+// applies the changes from the log, so the lookup does not query the database.
+type LookupSource struct {
+	name        string
+	newCapturer func(c *sourceConf) (capturer, error)
+	conf        *sourceConf
+	cap         capturer
+	table       *table
+	// ready is closed when the snapshot is loaded
+	ready  chan struct{}
+	cancel func()
+}
+
+func (s *LookupSource) Provision(ctx api.StreamContext, props map[string]any) error {
+	c, err := newSourceConf(ctx, props)
+	if err != nil {
+		return err
+	}
+	if len(c.tables("")) != 1 {
+		return fmt.Errorf("datasource should be a single table for lookup")
+	}
+	cp, err := s.newCapturer(c)
+	if err != nil {
+		return err
+	}
+	s.conf = c
+	s.cap = cp
+	s.table = newTable()
+	s.ready = make(chan struct{})
+	return nil
+}
+
+// Connect starts to replicate the table in a goroutine. If the stream fails, it reloads the snapshot because the
+// changes may be lost.
+func (s *LookupSource) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	if err := s.cap.connect(ctx); err != nil {
+		sch(api.ConnectionDisconnected, err.Error())
+		return err
+	}
+	sch(api.ConnectionConnected, "")
+	rctx, cancel := ctx.WithCancel()
+	s.cancel = cancel
+	go func() {
+		loaded := false
+		for {
+			err := s.replicate(rctx, func() {
+				if !loaded {
+					loaded = true
+					close(s.ready)
+				}
+			})
+			if rctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				ctx.GetLogger().Errorf("%s lookup table replication error: %v", s.name, err)
+			}
+			select {
+			case <-rctx.Done():
+				return
+			case <-time.After(time.Duration(s.conf.RetryInterval)):
+			}
+		}
+	}()
+	return nil
+}
+
+func (s *LookupSource) replicate(ctx api.StreamContext, loaded func()) error {
+	pos, err := s.cap.position(ctx)
+	if err != nil {
+		return err
+	}
+	// Load the snapshot aside so that the lookup reads the old rows until the snapshot is loaded
+	t := newTable()
+	if err = s.cap.snapshot(ctx, t.apply); err != nil {
+		return err
+	}
+	s.table.replace(t.rows)
+	loaded()
+	return s.cap.stream(ctx, pos, func(t *transaction) {
+		for _, c := range t.changes {
+			s.table.apply(c)
+		}
+		s.cap.confirm(ctx, t.offset)
+	})
+}
+
+// Lookup waits until the snapshot is loaded and then finds the rows matching all the keys
+func (s *LookupSource) Lookup(ctx api.StreamContext, fields []string, keys []string, values []any) ([]map[string]any, error) {
+	select {
+	case <-s.ready:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return s.table.lookup(fields, keys, values), nil
+}
+
+func (s *LookupSource) Ping(ctx api.StreamContext, props map[string]any) error {
+	c, err := newSourceConf(ctx, props)
+	if err != nil {
+		return err
+	}
+	cp, err := s.newCapturer(c)
+	if err != nil {
+		return err
+	}
+	defer cp.close(ctx)
+	return cp.connect(ctx)
+}
+
+func (s *LookupSource) Close(ctx api.StreamContext) error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	if s.cap != nil {
+		s.cap.close(ctx)
+	}
+	return nil
+}
+
+func GetPgLookupSource() api.Source {
+	return &LookupSource{name: "postgres cdc", newCapturer: newPgCapturer}
+}
+
+var (
+	_ api.LookupSource  = &LookupSource{}
+	_ util.PingableConn = &LookupSource{}
+)
@@ -0,0 +1,670 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build mysqlcdc
+
+package cdc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-mysql-org/go-mysql/client"
+	"github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/go-mysql-org/go-mysql/replication"
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+// mysqlCapturer reads the row changes from the binlog as a replica. The binlog_format must be ROW. The column names
+// are read from the table map event if binlog_row_metadata is FULL. Otherwise, they are read from information_schema
+// which may be different from the columns when the change happened if the table is altered.
+type mysqlCapturer struct {
+	conf     *sourceConf
+	host     string
+	port     uint16
+	user     string
+	password string
+	database string
+	tables   []tableName
+
+	mu sync.Mutex
+	// meta is the connection to run the queries
+	meta *client.Conn
+	// columns caches the columns of the tables read from information_schema
+	columns map[string]*mysqlTableInfo
+}
+
+type mysqlTableInfo struct {
+	names    []string
+	unsigned map[int]bool
+	keys     []string
+	enums    map[int][]string
+	sets     map[int][]string
+}
+
+func newMySQLCapturer(c *sourceConf) (capturer, error) {
+	u, err := url.Parse(c.DBUrl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dburl: %v", err)
+	}
+	if u.Scheme != "mysql" {
+		return nil, fmt.Errorf("dburl should start with mysql://")
+	}
+	port := uint64(3306)
+	if u.Port() != "" {
+		port, err = strconv.ParseUint(u.Port(), 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port of dburl %s", u.Port())
+		}
+	}
+	pw, _ := u.User.Password()
+	m := &mysqlCapturer{
+		conf:     c,
+		host:     u.Hostname(),
+		port:     uint16(port),
+		user:     u.User.Username(),
+		password: pw,
+		database: strings.TrimPrefix(u.Path, "/"),
+		columns:  make(map[string]*mysqlTableInfo),
+	}
+	m.tables = c.tables(m.database)
+	for _, t := range m.tables {
+		if t.schema == "" {
+			return nil, fmt.Errorf("the database of table %s is unknown, set it in datasource or dburl", t.name)
+		}
+	}
+	return m, nil
+}
+
+func (m *mysqlCapturer) addr() string {
+	return fmt.Sprintf("%s:%d", m.host, m.port)
+}
+
+func (m *mysqlCapturer) connect(_ api.StreamContext) error {
+	r, err := m.query("SHOW VARIABLES LIKE 'binlog_format'")
+	if err != nil {
+		return err
+	}
+	if r.RowNumber() == 0 {
+		return errors.New("binlog is not enabled")
+	}
+	if f, _ := r.GetString(0, 1); f != "ROW" {
+		return fmt.Errorf("binlog_format should be ROW but got %s", f)
+	}
+	return nil
+}
+
+// query runs the query by the meta connection. The connection is reopened in the next query if fails.
+func (m *mysqlCapturer) query(sql string) (*mysql.Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.ensureMeta(); err != nil {
+		return nil, err
+	}
+	r, err := m.meta.Execute(sql)
+	m.checkMetaErr(err)
+	return r, err
+}
+
+// ensureMeta opens the meta connection in UTC to read the time values like the binlog. Must be called with the lock.
+func (m *mysqlCapturer) ensureMeta() error {
+	if m.meta != nil {
+		return nil
+	}
+	conn, err := client.Connect(m.addr(), m.user, m.password, m.database, func(c *client.Conn) error {
+		if m.conf.tlsConf != nil {
+			c.SetTLSConfig(m.conf.tlsConf)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("connect to mysql %s error: %v", m.addr(), err)
+	}
+	if _, err = conn.Execute("SET time_zone = '+00:00'"); err != nil {
+		_ = conn.Close()
+		return err
+	}
+	m.meta = conn
+	return nil
+}
+
+// checkMetaErr closes the meta connection if the error is not a server error. Must be called with the lock.
+func (m *mysqlCapturer) checkMetaErr(err error) {
+	var me *mysql.MyError
+	if err != nil && !errors.As(err, &me) {
+		_ = m.meta.Close()
+		m.meta = nil
+	}
+}
+
+func (m *mysqlCapturer) position(_ api.StreamContext) (string, error) {
+	// SHOW MASTER STATUS is removed since 8.4
+	r, err := m.query("SHOW BINARY LOG STATUS")
+	if err != nil {
+		r, err = m.query("SHOW MASTER STATUS")
+	}
+	if err != nil {
+		return "", err
+	}
+	if r.RowNumber() == 0 {
+		return "", errors.New("binlog is not enabled")
+	}
+	file, _ := r.GetString(0, 0)
+	pos, _ := r.GetString(0, 1)
+	return file + ":" + pos, nil
+}
+
+func (m *mysqlCapturer) snapshot(ctx api.StreamContext, emit func(*change)) error {
+	tables := m.tables
+	if len(tables) == 0 {
+		if m.database == "" {
+			return errors.New("set the database in dburl or the tables in datasource to read the snapshot")
+		}
+		r, err := m.query(fmt.Sprintf("SELECT TABLE_NAME FROM information_schema.TABLES WHERE TABLE_SCHEMA = %s AND TABLE_TYPE = 'BASE TABLE'", quoteString(m.database)))
+		if err != nil {
+			return err
+		}
+		for i := 0; i < r.RowNumber(); i++ {
+			name, _ := r.GetString(i, 0)
+			tables = append(tables, tableName{schema: m.database, name: name})
+		}
+	}
+	for _, t := range tables {
+		info, err := m.tableInfo(t.schema, t.name)
+		if err != nil {
+			return err
+		}
+		ctx.GetLogger().Infof("read snapshot of table %s", t)
+		err = m.queryEach(fmt.Sprintf("SELECT * FROM %s.%s", quoteIdent(t.schema), quoteIdent(t.name)), func(fields []*mysql.Field, row []mysql.FieldValue) error {
+			after := make(map[string]any, len(fields))
+			for i, f := range fields {
+				after[string(f.Name)] = convertText(f, row[i])
+			}
+			emit(&change{kind: ast.RowkindInsert, schema: t.schema, table: t.name, keys: info.keys, after: after})
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("read snapshot of table %s error: %v", t, err)
+		}
+	}
+	return nil
+}
+
+// queryEach streams the rows of the query so that the large table is not loaded into memory
+func (m *mysqlCapturer) queryEach(sql string, fn func(fields []*mysql.Field, row []mysql.FieldValue) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.ensureMeta(); err != nil {
+		return err
+	}
+	var result mysql.Result
+	err := m.meta.ExecuteSelectStreaming(sql, &result, func(row []mysql.FieldValue) error {
+		return fn(result.Fields, row)
+	}, func(*mysql.Result) error {
+		return nil
+	})
+	m.checkMetaErr(err)
+	return err
+}
+
+// tableInfo reads the columns of the table from information_schema
+func (m *mysqlCapturer) tableInfo(schema, table string) (*mysqlTableInfo, error) {
+	key := schema + "." + table
+	m.mu.Lock()
+	info, ok := m.columns[key]
+	m.mu.Unlock()
+	if ok {
+		return info, nil
+	}
+	r, err := m.query(fmt.Sprintf("SELECT COLUMN_NAME, COLUMN_KEY, COLUMN_TYPE FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = %s AND TABLE_NAME = %s ORDER BY ORDINAL_POSITION", quoteString(schema), quoteString(table)))
+	if err != nil {
+		return nil, fmt.Errorf("read columns of table %s error: %v", key, err)
+	}
+	info = &mysqlTableInfo{unsigned: make(map[int]bool), enums: make(map[int][]string), sets: make(map[int][]string)}
+	for i := 0; i < r.RowNumber(); i++ {
+		name, _ := r.GetString(i, 0)
+		colKey, _ := r.GetString(i, 1)
+		colType, _ := r.GetString(i, 2)
+		info.names = append(info.names, name)
+		info.unsigned[i] = strings.Contains(colType, "unsigned")
+		if colKey == "PRI" {
+			info.keys = append(info.keys, name)
+		}
+		if strings.HasPrefix(colType, "enum(") {
+			info.enums[i] = parseEnumValues(colType[5:])
+		} else if strings.HasPrefix(colType, "set(") {
+			info.sets[i] = parseEnumValues(colType[4:])
+		}
+	}
+	m.mu.Lock()
+	m.columns[key] = info
+	m.mu.Unlock()
+	return info, nil
+}
+
+// parseEnumValues parses the values like 'a','b')
+func parseEnumValues(s string) []string {
+	var (
+		result []string
+		sb     strings.Builder
+		quoted bool
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quoted && c == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			sb.WriteByte('\'')
+			i++
+		case c == '\'':
+			if quoted {
+				result = append(result, sb.String())
+				sb.Reset()
+			}
+			quoted = !quoted
+		case quoted:
+			sb.WriteByte(c)
+		}
+	}
+	return result
+}
+
+func (m *mysqlCapturer) captured(schema, table string) bool {
+	if len(m.tables) == 0 {
+		return m.database == "" || schema == m.database
+	}
+	for _, t := range m.tables {
+		if t.schema == schema && t.name == table {
+			return true
+		}
+	}
+	return false
+}
+
+// rowsTable returns the column information of the rows event. The metadata in the binlog is used if exists, the
+// missing parts are filled from information_schema.
+func (m *mysqlCapturer) rowsTable(t *replication.TableMapEvent) (*mysqlTableInfo, error) {
+	info := &mysqlTableInfo{
+		names:    t.ColumnNameString(),
+		unsigned: t.UnsignedMap(),
+		enums:    t.EnumStrValueMap(),
+		sets:     t.SetStrValueMap(),
+	}
+	for _, i := range t.PrimaryKey {
+		if int(i) < len(info.names) {
+			info.keys = append(info.keys, info.names[i])
+		}
+	}
+	if len(info.names) > 0 {
+		return info, nil
+	}
+	schema, table := string(t.Schema), string(t.Table)
+	is, err := m.tableInfo(schema, table)
+	if err != nil {
+		return nil, err
+	}
+	if len(is.names) != int(t.ColumnCount) {
+		return nil, fmt.Errorf("the columns of table %s.%s are changed, please set binlog_row_metadata to FULL", schema, table)
+	}
+	info.names = is.names
+	info.keys = is.keys
+	// The signedness of the binlog is more accurate if exists
+	if len(info.unsigned) == 0 {
+		info.unsigned = is.unsigned
+	}
+	if len(info.enums) == 0 {
+		info.enums = is.enums
+	}
+	if len(info.sets) == 0 {
+		info.sets = is.sets
+	}
+	return info, nil
+}
+
+// rowsChanges converts the rows of the rows event to the changes
+func (m *mysqlCapturer) rowsChanges(eventType replication.EventType, e *replication.RowsEvent) ([]*change, error) {
+	schema, table := string(e.Table.Schema), string(e.Table.Table)
+	if !m.captured(schema, table) {
+		return nil, nil
+	}
+	info, err := m.rowsTable(e.Table)
+	if err != nil {
+		return nil, err
+	}
+	var kind string
+	switch eventType {
+	case replication.WRITE_ROWS_EVENTv0, replication.WRITE_ROWS_EVENTv1, replication.WRITE_ROWS_EVENTv2:
+		kind = ast.RowkindInsert
+	case replication.UPDATE_ROWS_EVENTv0, replication.UPDATE_ROWS_EVENTv1, replication.UPDATE_ROWS_EVENTv2:
+		kind = ast.RowkindUpdate
+	case replication.DELETE_ROWS_EVENTv0, replication.DELETE_ROWS_EVENTv1, replication.DELETE_ROWS_EVENTv2:
+		kind = ast.RowkindDelete
+	default:
+		return nil, fmt.Errorf("unsupported rows event %s", eventType)
+	}
+	row := func(i int) map[string]any {
+		var skipped []int
+		if i < len(e.SkippedColumns) {
+			skipped = e.SkippedColumns[i]
+		}
+		return info.row(e.Table, e.Rows[i], skipped)
+	}
+	var result []*change
+	for i := 0; i < len(e.Rows); i++ {
+		c := &change{kind: kind, schema: schema, table: table, keys: info.keys}
+		switch kind {
+		case ast.RowkindInsert:
+			c.after = row(i)
+		case ast.RowkindDelete:
+			c.before = row(i)
+		default:
+			// The rows of the update are pairs of the before and after images
+			if i+1 >= len(e.Rows) {
+				return nil, fmt.Errorf("the update rows of table %s.%s are not paired", schema, table)
+			}
+			c.before = row(i)
+			i++
+			c.after = row(i)
+		}
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+// row converts the column values of the binlog row to the map. The columns not in the row image are skipped.
+func (info *mysqlTableInfo) row(t *replication.TableMapEvent, values []any, skipped []int) map[string]any {
+	row := make(map[string]any, len(values))
+	for i, v := range values {
+		if containsInt(skipped, i) {
+			continue
+		}
+		name := fmt.Sprintf("col%d", i+1)
+		if i < len(info.names) {
+			name = info.names[i]
+		}
+		if enums, ok := info.enums[i]; ok {
+			v = enumValue(v, enums)
+		} else if sets, ok := info.sets[i]; ok {
+			v = setValue(v, sets)
+		} else if i < len(t.ColumnType) {
+			v = binlogValue(t.ColumnType[i], v, info.unsigned[i])
+		}
+		row[name] = v
+	}
+	return row
+}
+
+func containsInt(s []int, v int) bool {
+	for _, i := range s {
+		if i == v {
+			return true
+		}
+	}
+	return false
+}
+
+// binlogValue converts the value decoded by the binlog syncer to the eKuiper types like the snapshot
+func binlogValue(tp byte, v any, unsigned bool) any {
+	switch t := v.(type) {
+	case int8:
+		if unsigned {
+			return uint64(uint8(t))
+		}
+		return int64(t)
+	case int16:
+		if unsigned {
+			return uint64(uint16(t))
+		}
+		return int64(t)
+	case int32:
+		if unsigned {
+			if tp == mysql.MYSQL_TYPE_INT24 {
+				return uint64(uint32(t) & 0xffffff)
+			}
+			return uint64(uint32(t))
+		}
+		return int64(t)
+	case int64:
+		if unsigned && tp == mysql.MYSQL_TYPE_LONGLONG {
+			return uint64(t)
+		}
+		return t
+	case int:
+		return int64(t)
+	case float32:
+		return float64(t)
+	case string:
+		switch tp {
+		case mysql.MYSQL_TYPE_NEWDECIMAL, mysql.MYSQL_TYPE_DECIMAL:
+			if f, err := strconv.ParseFloat(t, 64); err == nil {
+				return f
+			}
+		case mysql.MYSQL_TYPE_DATE, mysql.MYSQL_TYPE_NEWDATE:
+			if d, err := time.ParseInLocation(time.DateOnly, t, time.UTC); err == nil {
+				return d
+			}
+		}
+		return t
+	case []byte:
+		switch tp {
+		case mysql.MYSQL_TYPE_JSON:
+			var j any
+			if err := json.Unmarshal(t, &j); err == nil {
+				return j
+			}
+		case mysql.MYSQL_TYPE_GEOMETRY:
+			return t
+		}
+		return string(t)
+	case time.Time:
+		return t.UTC()
+	}
+	return v
+}
+
+// enumValue converts the index of the enum which starts from 1 to the name
+func enumValue(v any, values []string) any {
+	i, ok := v.(int64)
+	if !ok {
+		return v
+	}
+	if i == 0 || int(i) > len(values) {
+		return ""
+	}
+	return values[i-1]
+}
+
+// setValue converts the bitmap of the set to the comma separated names
+func setValue(v any, values []string) any {
+	i, ok := v.(int64)
+	if !ok {
+		return v
+	}
+	var names []string
+	for j, name := range values {
+		if i&(1<<j) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// convertText converts the value of the text result set to the type like the binlog value
+func convertText(f *mysql.Field, v mysql.FieldValue) any {
+	switch val := v.Value().(type) {
+	case nil:
+		return nil
+	case []byte:
+		s := string(val)
+		switch f.Type {
+		case mysql.MYSQL_TYPE_DECIMAL, mysql.MYSQL_TYPE_NEWDECIMAL:
+			if fv, err := strconv.ParseFloat(s, 64); err == nil {
+				return fv
+			}
+		case mysql.MYSQL_TYPE_TIMESTAMP, mysql.MYSQL_TYPE_DATETIME:
+			if t, err := time.ParseInLocation("2006-01-02 15:04:05.999999", s, time.UTC); err == nil {
+				return t
+			}
+		case mysql.MYSQL_TYPE_DATE, mysql.MYSQL_TYPE_NEWDATE:
+			if t, err := time.ParseInLocation(time.DateOnly, s, time.UTC); err == nil {
+				return t
+			}
+		case mysql.MYSQL_TYPE_BIT:
+			var i int64
+			for _, c := range val {
+				i = i<<8 | int64(c)
+			}
+			return i
+		case mysql.MYSQL_TYPE_JSON:
+			var j any
+			if err := json.Unmarshal(val, &j); err == nil {
+				return j
+			}
+		case mysql.MYSQL_TYPE_GEOMETRY:
+			return []byte(s)
+		}
+		return s
+	case uint64:
+		if f.Type == mysql.MYSQL_TYPE_YEAR {
+			return int64(val)
+		}
+		return val
+	default:
+		return val
+	}
+}
+
+func (m *mysqlCapturer) stream(ctx api.StreamContext, offset string, emit func(*transaction)) error {
+	pos, err := parseBinlogPosition(offset)
+	if err != nil {
+		return err
+	}
+	syncer := replication.NewBinlogSyncer(replication.BinlogSyncerConfig{
+		ServerID:  m.conf.ServerId,
+		Flavor:    mysql.MySQLFlavor,
+		Host:      m.host,
+		Port:      m.port,
+		User:      m.user,
+		Password:  m.password,
+		TLSConfig: m.conf.tlsConf,
+		ParseTime: true,
+		// The source retries by itself from the last offset
+		DisableRetrySync: true,
+	})
+	defer syncer.Close()
+	streamer, err := syncer.StartSync(pos)
+	if err != nil {
+		return fmt.Errorf("connect to mysql %s error: %v", m.addr(), err)
+	}
+	ctx.GetLogger().Infof("start to read binlog from %s", offset)
+	var changes []*change
+	for {
+		ev, err := streamer.GetEvent(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		ts := time.Unix(int64(ev.Header.Timestamp), 0)
+		switch e := ev.Event.(type) {
+		case *replication.RotateEvent:
+			next := mysql.Position{Name: string(e.NextLogName), Pos: uint32(e.Position)}
+			if next != pos {
+				pos = next
+				emit(&transaction{offset: binlogOffset(pos), ts: ts})
+			}
+		case *replication.RowsEvent:
+			cs, err := m.rowsChanges(ev.Header.EventType, e)
+			if err != nil {
+				return err
+			}
+			changes = append(changes, cs...)
+		case *replication.XIDEvent:
+			pos.Pos = ev.Header.LogPos
+			emit(&transaction{changes: changes, offset: binlogOffset(pos), ts: ts})
+			changes = nil
+		case *replication.QueryEvent:
+			switch strings.ToUpper(strings.TrimSpace(string(e.Query))) {
+			case "BEGIN":
+				changes = nil
+			case "COMMIT":
+				// The commit of the non-transactional tables
+				pos.Pos = ev.Header.LogPos
+				emit(&transaction{changes: changes, offset: binlogOffset(pos), ts: ts})
+				changes = nil
+			default:
+				// DDL may change the columns
+				m.mu.Lock()
+				m.columns = make(map[string]*mysqlTableInfo)
+				m.mu.Unlock()
+				if ev.Header.LogPos > 0 {
+					pos.Pos = ev.Header.LogPos
+					emit(&transaction{offset: binlogOffset(pos), ts: ts})
+				}
+			}
+		}
+	}
+}
+
+// confirm is no-op because the binlog is kept by the server regardless of the replica
+func (m *mysqlCapturer) confirm(_ api.StreamContext, _ string) {}
+
+func (m *mysqlCapturer) close(_ api.StreamContext) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.meta != nil {
+		_ = m.meta.Close()
+		m.meta = nil
+	}
+}
+
+func binlogOffset(p mysql.Position) string {
+	return fmt.Sprintf("%s:%d", p.Name, p.Pos)
+}
+
+func parseBinlogPosition(offset string) (mysql.Position, error) {
+	i := strings.LastIndexByte(offset, ':')
+	if i <= 0 {
+		return mysql.Position{}, fmt.Errorf("invalid binlog position %s, should be like file:pos", offset)
+	}
+	pos, err := strconv.ParseUint(offset[i+1:], 10, 32)
+	if err != nil {
+		return mysql.Position{}, fmt.Errorf("invalid binlog position %s, should be like file:pos", offset)
+	}
+	return mysql.Position{Name: offset[:i], Pos: uint32(pos)}, nil
+}
+
+func quoteString(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+func quoteIdent(s string) string {
+	return "`" + strings.ReplaceAll(s, "`", "``") + "`"
+}
+
+func GetMySQLSource() api.Source {
+	return &Source{name: "mysql cdc", newCapturer: newMySQLCapturer}
+}
+
+func GetMySQLLookupSource() api.Source {
+	return &LookupSource{name: "mysql cdc", newCapturer: newMySQLCapturer}
+}
@@ -0,0 +1,337 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdc
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+const pgStatusInterval = 10 * time.Second
+
+// pgCapturer reads the row changes by the logical replication with the pgoutput plugin. The wal_level must be
+// logical. The slot and the publication are created if not exist. The slot keeps the WAL until the position is
+// confirmed, so the changes are not lost when the rule is stopped.
+type pgCapturer struct {
+	conf   *sourceConf
+	tables []tableName
+	// confirmed is the LSN confirmed to the server to release the WAL
+	confirmed atomic.Uint64
+}
+
+func newPgCapturer(c *sourceConf) (capturer, error) {
+	if _, err := pgconn.ParseConfig(c.DBUrl); err != nil {
+		return nil, fmt.Errorf("invalid dburl: %v", err)
+	}
+	if c.Slot == "" {
+		return nil, errors.New("slot is required")
+	}
+	if c.Publication == "" {
+		return nil, errors.New("publication is required")
+	}
+	return &pgCapturer{conf: c, tables: c.tables("public")}, nil
+}
+
+// connect checks the wal_level and creates the publication of the captured tables if not exists
+func (p *pgCapturer) connect(ctx api.StreamContext) error {
+	conn, err := pgconn.Connect(ctx, p.conf.DBUrl)
+	if err != nil {
+		return fmt.Errorf("connect to postgres error: %v", err)
+	}
+	defer conn.Close(context.Background())
+	rows, err := queryRows(ctx, conn, "SHOW wal_level")
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 || string(rows[0][0]) != "logical" {
+		return errors.New("wal_level should be logical")
+	}
+	rows, err = queryRows(ctx, conn, "SELECT 1 FROM pg_publication WHERE pubname = $1", p.conf.Publication)
+	if err != nil {
+		return err
+	}
+	if len(rows) > 0 {
+		return nil
+	}
+	sql := fmt.Sprintf("CREATE PUBLICATION %s FOR ALL TABLES", pgIdent(p.conf.Publication))
+	if len(p.tables) > 0 {
+		names := make([]string, len(p.tables))
+		for i, t := range p.tables {
+			names[i] = pgIdent(t.schema) + "." + pgIdent(t.name)
+		}
+		sql = fmt.Sprintf("CREATE PUBLICATION %s FOR TABLE %s", pgIdent(p.conf.Publication), strings.Join(names, ", "))
+	}
+	ctx.GetLogger().Infof("create publication: %s", sql)
+	if _, err = conn.Exec(ctx, sql).ReadAll(); err != nil {
+		return fmt.Errorf("create publication %s error: %v", p.conf.Publication, err)
+	}
+	return nil
+}
+
+// position returns the confirmed position of the slot. The slot is created if not exists.
+func (p *pgCapturer) position(ctx api.StreamContext) (string, error) {
+	conn, err := pgconn.Connect(ctx, p.conf.DBUrl)
+	if err != nil {
+		return "", fmt.Errorf("connect to postgres error: %v", err)
+	}
+	defer conn.Close(context.Background())
+	rows, err := queryRows(ctx, conn, "SELECT confirmed_flush_lsn FROM pg_replication_slots WHERE slot_name = $1", p.conf.Slot)
+	if err != nil {
+		return "", err
+	}
+	if len(rows) > 0 && rows[0][0] != nil {
+		return string(rows[0][0]), nil
+	}
+	ctx.GetLogger().Infof("create replication slot %s", p.conf.Slot)
+	rows, err = queryRows(ctx, conn, "SELECT lsn FROM pg_create_logical_replication_slot($1, 'pgoutput')", p.conf.Slot)
+	if err != nil {
+		return "", fmt.Errorf("create replication slot %s error: %v", p.conf.Slot, err)
+	}
+	if len(rows) == 0 {
+		return "", fmt.Errorf("create replication slot %s error: no lsn returned", p.conf.Slot)
+	}
+	return string(rows[0][0]), nil
+}
+
+func (p *pgCapturer) snapshot(ctx api.StreamContext, emit func(*change)) error {
+	conn, err := pgconn.Connect(ctx, p.conf.DBUrl)
+	if err != nil {
+		return fmt.Errorf("connect to postgres error: %v", err)
+	}
+	defer conn.Close(context.Background())
+	tables := p.tables
+	if len(tables) == 0 {
+		rows, err := queryRows(ctx, conn, "SELECT schemaname, tablename FROM pg_publication_tables WHERE pubname = $1", p.conf.Publication)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			tables = append(tables, tableName{schema: string(row[0]), name: string(row[1])})
+		}
+	}
+	m := pgtype.NewMap()
+	for _, t := range tables {
+		name := pgIdent(t.schema) + "." + pgIdent(t.name)
+		rows, err := queryRows(ctx, conn, "SELECT a.attname FROM pg_index i JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey) WHERE i.indrelid = $1::regclass AND i.indisprimary", name)
+		if err != nil {
+			return fmt.Errorf("read primary key of table %s error: %v", t, err)
+		}
+		var keys []string
+		for _, row := range rows {
+			keys = append(keys, string(row[0]))
+		}
+		ctx.GetLogger().Infof("read snapshot of table %s", t)
+		rr := conn.ExecParams(ctx, "SELECT * FROM "+name, nil, nil, nil, nil)
+		fields := rr.FieldDescriptions()
+		for rr.NextRow() {
+			after := make(map[string]any, len(fields))
+			for i, v := range rr.Values() {
+				if v == nil {
+					after[fields[i].Name] = nil
+				} else {
+					after[fields[i].Name] = pgValue(m, fields[i].DataTypeOID, v)
+				}
+			}
+			emit(&change{kind: ast.RowkindInsert, schema: t.schema, table: t.name, keys: keys, after: after})
+		}
+		if _, err = rr.Close(); err != nil {
+			return fmt.Errorf("read snapshot of table %s error: %v", t, err)
+		}
+	}
+	return nil
+}
+
+func (p *pgCapturer) captured(schema, table string) bool {
+	if len(p.tables) == 0 {
+		return true
+	}
+	for _, t := range p.tables {
+		if t.schema == schema && t.name == table {
+			return true
+		}
+	}
+	return false
+}
+
+// stream starts the replication from the offset. The server sends the transactions committed after the offset.
+// The status with the confirmed position is sent periodically and when the server requests.
+func (p *pgCapturer) stream(ctx api.StreamContext, offset string, emit func(*transaction)) error {
+	start, err := parseLSN(offset)
+	if err != nil {
+		return err
+	}
+	cfg, err := pgconn.ParseConfig(p.conf.DBUrl)
+	if err != nil {
+		return err
+	}
+	cfg.RuntimeParams["replication"] = "database"
+	conn, err := pgconn.ConnectConfig(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("connect to postgres error: %v", err)
+	}
+	defer conn.Close(context.Background())
+	sql := fmt.Sprintf("START_REPLICATION SLOT %s LOGICAL %s (proto_version '1', publication_names %s)", pgIdent(p.conf.Slot), formatLSN(start), pgLiteral(p.conf.Publication))
+	conn.Frontend().SendQuery(&pgproto3.Query{String: sql})
+	if err = conn.Frontend().Flush(); err != nil {
+		return err
+	}
+	for started := false; !started; {
+		msg, err := conn.ReceiveMessage(ctx)
+		if err != nil {
+			return err
+		}
+		switch m := msg.(type) {
+		case *pgproto3.CopyBothResponse:
+			started = true
+		case *pgproto3.ErrorResponse:
+			return pgconn.ErrorResponseToPgError(m)
+		}
+	}
+	ctx.GetLogger().Infof("start replication of slot %s from %s", p.conf.Slot, formatLSN(start))
+	if p.confirmed.Load() < start {
+		p.confirmed.Store(start)
+	}
+	decoder := newPgDecoder(p.captured)
+	// received is the end of the last emitted transaction. walEnd is the end of WAL sent by the server.
+	received, walEnd := start, start
+	nextStatus := time.Now()
+	for {
+		if !time.Now().Before(nextStatus) {
+			flush := p.confirmed.Load()
+			// Skip the WAL without changes of the publication if all the transactions are confirmed
+			if flush >= received && decoder.txn == nil && walEnd > flush {
+				flush = walEnd
+			}
+			if err = sendStandbyStatus(conn, received, flush); err != nil {
+				return err
+			}
+			nextStatus = time.Now().Add(pgStatusInterval)
+		}
+		rctx, cancel := context.WithDeadline(ctx, nextStatus)
+		msg, err := conn.ReceiveMessage(rctx)
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if pgconn.Timeout(err) {
+				continue
+			}
+			return err
+		}
+		switch m := msg.(type) {
+		case *pgproto3.CopyData:
+			if len(m.Data) == 0 {
+				continue
+			}
+			switch m.Data[0] {
+			case 'k':
+				// Primary keepalive: wal end, send time and reply requested
+				if len(m.Data) < 18 {
+					return errors.New("invalid keepalive message")
+				}
+				if end := binary.BigEndian.Uint64(m.Data[1:]); end > walEnd {
+					walEnd = end
+				}
+				if m.Data[17] == 1 {
+					nextStatus = time.Now()
+				}
+			case 'w':
+				// XLogData: start, end, send time and the pgoutput message
+				if len(m.Data) < 25 {
+					return errors.New("invalid xlog data message")
+				}
+				if end := binary.BigEndian.Uint64(m.Data[9:]); end > walEnd {
+					walEnd = end
+				}
+				t, err := decoder.decode(m.Data[25:])
+				if err != nil {
+					return err
+				}
+				if t != nil {
+					emit(t)
+					if lsn, err := parseLSN(t.offset); err == nil {
+						received = lsn
+					}
+				}
+			}
+		case *pgproto3.ErrorResponse:
+			return pgconn.ErrorResponseToPgError(m)
+		case *pgproto3.CopyDone:
+			return errors.New("replication is stopped by the server")
+		}
+	}
+}
+
+// confirm records the position to send in the next status update
+func (p *pgCapturer) confirm(_ api.StreamContext, offset string) {
+	lsn, err := parseLSN(offset)
+	if err != nil {
+		return
+	}
+	for {
+		old := p.confirmed.Load()
+		if lsn <= old || p.confirmed.CompareAndSwap(old, lsn) {
+			return
+		}
+	}
+}
+
+func (p *pgCapturer) close(_ api.StreamContext) {}
+
+func sendStandbyStatus(conn *pgconn.PgConn, write, flush uint64) error {
+	buf := make([]byte, 0, 34)
+	buf = append(buf, 'r')
+	buf = binary.BigEndian.AppendUint64(buf, write)
+	buf = binary.BigEndian.AppendUint64(buf, flush)
+	buf = binary.BigEndian.AppendUint64(buf, flush)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(time.Since(pgEpoch).Microseconds()))
+	buf = append(buf, 0)
+	conn.Frontend().Send(&pgproto3.CopyData{Data: buf})
+	return conn.Frontend().Flush()
+}
+
+// queryRows runs a query with text parameters and returns all the rows
+func queryRows(ctx context.Context, conn *pgconn.PgConn, sql string, args ...string) ([][][]byte, error) {
+	params := make([][]byte, len(args))
+	for i, a := range args {
+		params[i] = []byte(a)
+	}
+	res := conn.ExecParams(ctx, sql, params, nil, nil, nil).Read()
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	return res.Rows, nil
+}
+
+func pgIdent(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+func pgLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
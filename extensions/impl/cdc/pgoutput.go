@@ -0,0 +1,327 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdc
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+// The decoder of the pgoutput plugin of the logical replication protocol version 1.
+// See https://www.postgresql.org/docs/current/protocol-logicalrep-message-formats.html
+
+// pgEpoch is the epoch of the timestamps in the replication protocol
+var pgEpoch = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+var errShortData = errors.New("unexpected end of data")
+
+type pgRelation struct {
+	schema  string
+	table   string
+	columns []pgColumn
+	keys    []string
+	skip    bool
+}
+
+type pgColumn struct {
+	name string
+	oid  uint32
+}
+
+type pgDecoder struct {
+	relations map[uint32]*pgRelation
+	typeMap   *pgtype.Map
+	captured  func(schema, table string) bool
+	// txn is the transaction in progress
+	txn *transaction
+}
+
+func newPgDecoder(captured func(schema, table string) bool) *pgDecoder {
+	return &pgDecoder{
+		relations: make(map[uint32]*pgRelation),
+		typeMap:   pgtype.NewMap(),
+		captured:  captured,
+	}
+}
+
+// decode decodes a pgoutput message. It returns the transaction when it is committed.
+func (d *pgDecoder) decode(data []byte) (*transaction, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty pgoutput message")
+	}
+	r := &beReader{data: data[1:]}
+	switch data[0] {
+	case 'B':
+		r.uint64() // final lsn
+		ts := r.uint64()
+		d.txn = &transaction{ts: pgTime(ts)}
+		return nil, r.err
+	case 'C':
+		r.uint8()  // flags
+		r.uint64() // commit lsn
+		end := r.uint64()
+		r.uint64() // commit time
+		if r.err != nil {
+			return nil, r.err
+		}
+		t := d.txn
+		if t == nil {
+			t = &transaction{}
+		}
+		t.offset = formatLSN(end)
+		d.txn = nil
+		return t, nil
+	case 'R':
+		return nil, d.decodeRelation(r)
+	case 'I', 'U', 'D':
+		c, err := d.decodeChange(data[0], r)
+		if err != nil {
+			return nil, err
+		}
+		if c != nil {
+			if d.txn == nil {
+				return nil, errors.New("row change out of transaction")
+			}
+			d.txn.changes = append(d.txn.changes, c)
+		}
+		return nil, nil
+	default:
+		// Ignore the origin, type, truncate and message
+		return nil, nil
+	}
+}
+
+func (d *pgDecoder) decodeRelation(r *beReader) error {
+	id := r.uint32()
+	rel := &pgRelation{schema: r.string()}
+	rel.table = r.string()
+	r.uint8() // replica identity
+	n := int(r.uint16())
+	rel.columns = make([]pgColumn, n)
+	for i := range rel.columns {
+		flags := r.uint8()
+		rel.columns[i].name = r.string()
+		rel.columns[i].oid = r.uint32()
+		r.uint32() // type modifier
+		if flags&1 != 0 {
+			rel.keys = append(rel.keys, rel.columns[i].name)
+		}
+	}
+	if r.err != nil {
+		return fmt.Errorf("decode relation error: %v", r.err)
+	}
+	rel.skip = d.captured != nil && !d.captured(rel.schema, rel.table)
+	d.relations[id] = rel
+	return nil
+}
+
+func (d *pgDecoder) decodeChange(tp byte, r *beReader) (*change, error) {
+	id := r.uint32()
+	rel, ok := d.relations[id]
+	if !ok {
+		return nil, fmt.Errorf("relation %d not found", id)
+	}
+	c := &change{schema: rel.schema, table: rel.table, keys: rel.keys}
+	var err error
+	switch tp {
+	case 'I':
+		c.kind = ast.RowkindInsert
+		if r.uint8() != 'N' {
+			return nil, errors.New("invalid insert message")
+		}
+		c.after, err = d.decodeTuple(rel, r)
+	case 'U':
+		c.kind = ast.RowkindUpdate
+		flag := r.uint8()
+		// The old tuple is only sent if the key is changed or the replica identity is full
+		if flag == 'K' || flag == 'O' {
+			if c.before, err = d.decodeTuple(rel, r); err != nil {
+				return nil, err
+			}
+			flag = r.uint8()
+		}
+		if flag != 'N' {
+			return nil, errors.New("invalid update message")
+		}
+		c.after, err = d.decodeTuple(rel, r)
+	case 'D':
+		c.kind = ast.RowkindDelete
+		flag := r.uint8()
+		if flag != 'K' && flag != 'O' {
+			return nil, errors.New("invalid delete message")
+		}
+		c.before, err = d.decodeTuple(rel, r)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if rel.skip {
+		return nil, nil
+	}
+	return c, nil
+}
+
+// decodeTuple decodes the text values. The unchanged toasted value is not in the tuple.
+func (d *pgDecoder) decodeTuple(rel *pgRelation, r *beReader) (map[string]any, error) {
+	n := int(r.uint16())
+	row := make(map[string]any, n)
+	for i := 0; i < n; i++ {
+		kind := r.uint8()
+		if r.err != nil {
+			return nil, r.err
+		}
+		var col pgColumn
+		if i < len(rel.columns) {
+			col = rel.columns[i]
+		} else {
+			col.name = fmt.Sprintf("col%d", i+1)
+		}
+		switch kind {
+		case 'n':
+			row[col.name] = nil
+		case 'u':
+		case 't':
+			b := r.bytes(int(r.uint32()))
+			if r.err != nil {
+				return nil, r.err
+			}
+			row[col.name] = pgValue(d.typeMap, col.oid, b)
+		default:
+			return nil, fmt.Errorf("unsupported tuple data kind %c", kind)
+		}
+	}
+	return row, r.err
+}
+
+// pgValue decodes the text value by the type. The value of unknown type is kept as string.
+func pgValue(m *pgtype.Map, oid uint32, data []byte) any {
+	dt, ok := m.TypeForOID(oid)
+	if !ok {
+		return string(data)
+	}
+	v, err := dt.Codec.DecodeValue(m, oid, pgtype.TextFormatCode, data)
+	if err != nil {
+		return string(data)
+	}
+	switch vv := v.(type) {
+	case int16:
+		return int64(vv)
+	case int32:
+		return int64(vv)
+	case float32:
+		return float64(vv)
+	case pgtype.Numeric:
+		f, err := vv.Float64Value()
+		if err != nil || !f.Valid {
+			return string(data)
+		}
+		return f.Float64
+	case [16]byte:
+		return fmt.Sprintf("%x-%x-%x-%x-%x", vv[0:4], vv[4:6], vv[6:8], vv[8:10], vv[10:16])
+	case pgtype.Time, pgtype.Interval:
+		return string(data)
+	}
+	return v
+}
+
+func pgTime(us uint64) time.Time {
+	return pgEpoch.Add(time.Duration(int64(us)) * time.Microsecond)
+}
+
+func formatLSN(lsn uint64) string {
+	return fmt.Sprintf("%X/%X", uint32(lsn>>32), uint32(lsn))
+}
+
+func parseLSN(s string) (uint64, error) {
+	var hi, lo uint32
+	if _, err := fmt.Sscanf(s, "%X/%X", &hi, &lo); err != nil {
+		return 0, fmt.Errorf("invalid lsn %s, should be like 0/16B3748", s)
+	}
+	return uint64(hi)<<32 | uint64(lo), nil
+}
+
+// beReader reads the big endian values of the PostgreSQL protocol. The first error is kept.
+type beReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *beReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || r.pos+n > len(r.data) {
+		r.err = errShortData
+		r.pos = len(r.data)
+		return nil
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *beReader) uint8() byte {
+	b := r.bytes(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (r *beReader) uint16() uint16 {
+	b := r.bytes(2)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint16(b)
+}
+
+func (r *beReader) uint32() uint32 {
+	b := r.bytes(4)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(b)
+}
+
+func (r *beReader) uint64() uint64 {
+	b := r.bytes(8)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(b)
+}
+
+func (r *beReader) string() string {
+	if r.err != nil {
+		return ""
+	}
+	for i := r.pos; i < len(r.data); i++ {
+		if r.data[i] == 0 {
+			s := string(r.data[r.pos:i])
+			r.pos = i + 1
+			return s
+		}
+	}
+	r.err = errShortData
+	return ""
+}
@@ -0,0 +1,133 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdc
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func relationMsg(id uint32, schema, table string, cols ...pgColumn) []byte {
+	b := binary.BigEndian.AppendUint32([]byte{'R'}, id)
+	b = append(append(b, schema...), 0)
+	b = append(append(b, table...), 0)
+	b = append(b, 'd')
+	b = binary.BigEndian.AppendUint16(b, uint16(len(cols)))
+	for i, c := range cols {
+		// The first column is the key
+		flags := byte(0)
+		if i == 0 {
+			flags = 1
+		}
+		b = append(b, flags)
+		b = append(append(b, c.name...), 0)
+		b = binary.BigEndian.AppendUint32(b, c.oid)
+		b = binary.BigEndian.AppendUint32(b, 0xffffffff)
+	}
+	return b
+}
+
+// tupleData encodes the text values. The nil value is null.
+func tupleData(values ...*string) []byte {
+	b := binary.BigEndian.AppendUint16(nil, uint16(len(values)))
+	for _, v := range values {
+		if v == nil {
+			b = append(b, 'n')
+			continue
+		}
+		b = append(b, 't')
+		b = binary.BigEndian.AppendUint32(b, uint32(len(*v)))
+		b = append(b, *v...)
+	}
+	return b
+}
+
+func sp(s string) *string {
+	return &s
+}
+
+func TestPgDecoder(t *testing.T) {
+	d := newPgDecoder(func(schema, table string) bool { return table != "other" })
+	cols := []pgColumn{{name: "id", oid: pgtype.Int4OID}, {name: "name", oid: pgtype.TextOID}, {name: "price", oid: pgtype.NumericOID}}
+	msgs := [][]byte{
+		relationMsg(1, "public", "t1", cols...),
+		relationMsg(2, "public", "other", pgColumn{name: "id", oid: pgtype.Int4OID}),
+		// begin: final lsn, commit time and xid
+		append(binary.BigEndian.AppendUint64(binary.BigEndian.AppendUint64([]byte{'B'}, 0x100), 1000000), 0, 0, 0, 7),
+		append(binary.BigEndian.AppendUint32([]byte{'I'}, 1), append([]byte{'N'}, tupleData(sp("1"), sp("a"), sp("1.5"))...)...),
+		append(binary.BigEndian.AppendUint32([]byte{'I'}, 2), append([]byte{'N'}, tupleData(sp("9"))...)...),
+		append(append(binary.BigEndian.AppendUint32([]byte{'U'}, 1), append([]byte{'O'}, tupleData(sp("1"), sp("a"), sp("1.5"))...)...), append([]byte{'N'}, tupleData(sp("1"), nil, sp("2"))...)...),
+		append(binary.BigEndian.AppendUint32([]byte{'D'}, 1), append([]byte{'K'}, tupleData(sp("1"), nil, nil)...)...),
+		// origin is ignored
+		append([]byte{'O'}, 0, 0, 0, 0, 0, 0, 0, 0, 'o', 0),
+	}
+	for _, m := range msgs {
+		txn, err := d.decode(m)
+		require.NoError(t, err)
+		require.Nil(t, txn)
+	}
+	commit := binary.BigEndian.AppendUint64(binary.BigEndian.AppendUint64([]byte{'C', 0}, 0x100), 0x1_0000_0200)
+	txn, err := d.decode(binary.BigEndian.AppendUint64(commit, 0))
+	require.NoError(t, err)
+	require.Equal(t, &transaction{offset: "1/200", ts: pgEpoch.Add(time.Second), changes: []*change{
+		{kind: "insert", schema: "public", table: "t1", keys: []string{"id"}, after: map[string]any{"id": int64(1), "name": "a", "price": 1.5}},
+		{kind: "update", schema: "public", table: "t1", keys: []string{"id"}, before: map[string]any{"id": int64(1), "name": "a", "price": 1.5}, after: map[string]any{"id": int64(1), "name": nil, "price": float64(2)}},
+		{kind: "delete", schema: "public", table: "t1", keys: []string{"id"}, before: map[string]any{"id": int64(1), "name": nil, "price": nil}},
+	}}, txn)
+
+	_, err = d.decode(append(binary.BigEndian.AppendUint32([]byte{'I'}, 1), append([]byte{'N'}, tupleData(sp("1"))...)...))
+	require.EqualError(t, err, "row change out of transaction")
+	_, err = d.decode(append(binary.BigEndian.AppendUint32([]byte{'I'}, 3), 'N', 0, 0))
+	require.EqualError(t, err, "relation 3 not found")
+	_, err = d.decode([]byte{'R', 0, 0})
+	require.Error(t, err)
+}
+
+func TestPgValue(t *testing.T) {
+	m := pgtype.NewMap()
+	tests := []struct {
+		oid      uint32
+		data     string
+		expected any
+	}{
+		{oid: pgtype.BoolOID, data: "t", expected: true},
+		{oid: pgtype.Int2OID, data: "-3", expected: int64(-3)},
+		{oid: pgtype.Int8OID, data: "10000000000", expected: int64(10000000000)},
+		{oid: pgtype.Float4OID, data: "1.5", expected: 1.5},
+		{oid: pgtype.NumericOID, data: "12.34", expected: 12.34},
+		{oid: pgtype.UUIDOID, data: "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11", expected: "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"},
+		{oid: pgtype.TimestampOID, data: "2024-01-02 03:04:05", expected: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{oid: pgtype.TimeOID, data: "03:04:05", expected: "03:04:05"},
+		{oid: pgtype.JSONBOID, data: `{"a":1}`, expected: map[string]any{"a": float64(1)}},
+		// unknown type
+		{oid: 99999, data: "x", expected: "x"},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.expected, pgValue(m, tt.oid, []byte(tt.data)), tt.data)
+	}
+}
+
+func TestLSN(t *testing.T) {
+	lsn, err := parseLSN("16/B374D848")
+	require.NoError(t, err)
+	require.Equal(t, uint64(0x16B374D848), lsn)
+	require.Equal(t, "16/B374D848", formatLSN(lsn))
+	_, err = parseLSN("f:4")
+	require.EqualError(t, err, "invalid lsn f:4, should be like 0/16B3748")
+}
@@ -0,0 +1,383 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdc
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/util"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cert"
+	"github.com/lf-edge/ekuiper/v2/pkg/model"
+)
+
+const defaultRowkindField = "rowkind"
+
+type sourceConf struct {
+	DBUrl string `json:"dburl"`
+	// Tables are the comma separated tables to capture like "db.table". Capture all tables if empty.
+	Tables        string            `json:"datasource"`
+	Snapshot      bool              `json:"snapshot"`
+	RowkindField  string            `json:"rowkindField"`
+	RetryInterval cast.DurationConf `json:"retryInterval"`
+	// ServerId is the replica server id of the MySQL binlog client
+	ServerId uint32 `json:"serverId"`
+	// Slot and Publication are the replication slot and publication of PostgreSQL
+	Slot        string `json:"slot"`
+	Publication string `json:"publication"`
+	// tlsConf is the TLS configuration of the MySQL connections
+	tlsConf *tls.Config
+}
+
+func newSourceConf(ctx api.StreamContext, props map[string]any) (*sourceConf, error) {
+	c := &sourceConf{
+		RowkindField:  defaultRowkindField,
+		RetryInterval: cast.DurationConf(5 * time.Second),
+		ServerId:      1001,
+		Slot:          "ekuiper",
+		Publication:   "ekuiper",
+	}
+	if err := cast.MapToStruct(props, c); err != nil {
+		return nil, fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	if c.DBUrl == "" {
+		return nil, fmt.Errorf("dburl is required")
+	}
+	if time.Duration(c.RetryInterval) <= 0 {
+		return nil, fmt.Errorf("retryInterval should be positive")
+	}
+	tlsConf, err := cert.GenTLSConfig(ctx, props)
+	if err != nil {
+		return nil, err
+	}
+	c.tlsConf = tlsConf
+	return c, nil
+}
+
+// tables returns the captured tables. The table without the schema is in the default schema.
+func (c *sourceConf) tables(defaultSchema string) []tableName {
+	var result []tableName
+	for _, t := range strings.Split(c.Tables, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		if schema, name, ok := strings.Cut(t, "."); ok {
+			result = append(result, tableName{schema: schema, name: name})
+		} else {
+			result = append(result, tableName{schema: defaultSchema, name: t})
+		}
+	}
+	return result
+}
+
+type tableName struct {
+	schema string
+	name   string
+}
+
+func (t tableName) String() string {
+	return t.schema + "." + t.name
+}
+
+// change is a row change in the database log
+type change struct {
+	// kind is the row kind like insert, update or delete
+	kind   string
+	schema string
+	table  string
+	// keys are the primary key columns. It may be empty if the table has no primary key or the key is unknown
+	keys   []string
+	before map[string]any
+	after  map[string]any
+}
+
+// image returns the row after the change or the deleted row
+func (c *change) image() map[string]any {
+	if c.kind == ast.RowkindDelete {
+		return c.before
+	}
+	return c.after
+}
+
+// transaction is the changes of a committed transaction. Offset is the log position after the transaction.
+type transaction struct {
+	changes []*change
+	offset  string
+	ts      time.Time
+}
+
+// capturer reads the changes from the log of a database
+type capturer interface {
+	// connect connects to the database and checks the log settings
+	connect(ctx api.StreamContext) error
+	// position returns the current position of the log
+	position(ctx api.StreamContext) (string, error)
+	// snapshot reads all the rows of the captured tables as inserts
+	snapshot(ctx api.StreamContext, emit func(*change)) error
+	// stream reads the committed transactions after the offset until the context is done or an error happens
+	stream(ctx api.StreamContext, offset string, emit func(*transaction)) error
+	// confirm tells the database that the changes until the offset are processed
+	confirm(ctx api.StreamContext, offset string)
+	close(ctx api.StreamContext)
+}
+
+// Source emits the row changes captured from the database log. Each change is a tuple of the row after the change
+// or the deleted row with the row kind. The log position after the last ingested transaction is the offset, so the
+// rule can restart from the checkpoint with qos.
+type Source struct {
+	name        string
+	newCapturer func(c *sourceConf) (capturer, error)
+	conf        *sourceConf
+	cap         capturer
+
+	mu sync.Mutex
+	// offset is the log position after the last ingested transaction
+	offset string
+	// restart stops the current stream to restart from the offset
+	restart         func()
+	ackOnCheckpoint bool
+	// checkpoints are the offsets of the ongoing checkpoints to confirm when completed
+	checkpoints map[int64]string
+}
+
+func (s *Source) Provision(ctx api.StreamContext, props map[string]any) error {
+	c, err := newSourceConf(ctx, props)
+	if err != nil {
+		return err
+	}
+	cp, err := s.newCapturer(c)
+	if err != nil {
+		return err
+	}
+	s.conf = c
+	s.cap = cp
+	return nil
+}
+
+func (s *Source) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	if err := s.cap.connect(ctx); err != nil {
+		sch(api.ConnectionDisconnected, err.Error())
+		return err
+	}
+	sch(api.ConnectionConnected, "")
+	return nil
+}
+
+func (s *Source) EnableCheckpoint(ctx api.StreamContext) {
+	ctx.GetLogger().Infof("%s source confirms the log position on checkpoint", s.name)
+	s.ackOnCheckpoint = true
+	s.checkpoints = make(map[int64]string)
+}
+
+func (s *Source) OnCheckpoint(_ api.StreamContext, checkpointId int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.checkpoints != nil {
+		s.checkpoints[checkpointId] = s.offset
+	}
+}
+
+func (s *Source) OnCheckpointComplete(ctx api.StreamContext, checkpointId int64) {
+	s.mu.Lock()
+	offset, ok := s.checkpoints[checkpointId]
+	for id := range s.checkpoints {
+		if id <= checkpointId {
+			delete(s.checkpoints, id)
+		}
+	}
+	s.mu.Unlock()
+	if ok && offset != "" {
+		s.cap.confirm(ctx, offset)
+	}
+}
+
+// Subscribe reads the log in a goroutine. If the offset is not set by rewinding, it starts from the current
+// position of the log after reading the snapshot if enabled. The stream restarts from the offset if it fails.
+func (s *Source) Subscribe(ctx api.StreamContext, ingest api.TupleIngest, ingestError api.ErrorIngest) error {
+	go func() {
+		for {
+			err := s.run(ctx, ingest)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				ingestError(ctx, err)
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(time.Duration(s.conf.RetryInterval)):
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// run streams the log from the offset until an error happens or the stream is restarted
+func (s *Source) run(ctx api.StreamContext, ingest api.TupleIngest) error {
+	if s.getOffset() == "" {
+		pos, err := s.start(ctx, ingest)
+		if err != nil {
+			return err
+		}
+		s.setOffset(pos)
+	}
+	sctx, cancel := ctx.WithCancel()
+	defer cancel()
+	s.mu.Lock()
+	s.restart = cancel
+	offset := s.offset
+	s.mu.Unlock()
+	err := s.cap.stream(sctx, offset, func(t *transaction) {
+		// The transaction read before restarting is discarded to keep the reset offset
+		if sctx.Err() != nil {
+			return
+		}
+		for _, c := range t.changes {
+			s.ingestChange(ctx, c, t.offset, t.ts, ingest)
+		}
+		s.setOffset(t.offset)
+		if !s.ackOnCheckpoint {
+			s.cap.confirm(ctx, t.offset)
+		}
+	})
+	if err != nil && sctx.Err() == nil {
+		return fmt.Errorf("%s stream error: %v", s.name, err)
+	}
+	return nil
+}
+
+// start reads the snapshot if enabled and returns the log position to stream from. The position is read before the
+// snapshot so that the changes during the snapshot are not missed.
+func (s *Source) start(ctx api.StreamContext, ingest api.TupleIngest) (string, error) {
+	pos, err := s.cap.position(ctx)
+	if err != nil {
+		return "", fmt.Errorf("read %s log position error: %v", s.name, err)
+	}
+	if s.conf.Snapshot {
+		err = s.cap.snapshot(ctx, func(c *change) {
+			s.ingestChange(ctx, c, "", time.Now(), ingest)
+		})
+		if err != nil {
+			return "", fmt.Errorf("read %s snapshot error: %v", s.name, err)
+		}
+	}
+	return pos, nil
+}
+
+func (s *Source) ingestChange(ctx api.StreamContext, c *change, offset string, ts time.Time, ingest api.TupleIngest) {
+	img := c.image()
+	data := make(map[string]any, len(img)+1)
+	for k, v := range img {
+		data[k] = v
+	}
+	data[s.conf.RowkindField] = c.kind
+	meta := map[string]any{
+		"schema": c.schema,
+		"table":  c.table,
+		"offset": offset,
+	}
+	if c.before != nil {
+		meta["before"] = c.before
+	}
+	if c.after != nil {
+		meta["after"] = c.after
+	}
+	ingest(ctx, data, meta, ts)
+}
+
+func (s *Source) getOffset() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.offset
+}
+
+func (s *Source) setOffset(offset string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.offset = offset
+}
+
+func (s *Source) GetOffset() (any, error) {
+	return s.getOffset(), nil
+}
+
+// Rewind sets the log position to start with. It is called before subscribing when restoring from the checkpoint.
+func (s *Source) Rewind(offset any) error {
+	o, ok := offset.(string)
+	if !ok {
+		return fmt.Errorf("%s source rewind failed, invalid offset %v", s.name, offset)
+	}
+	s.setOffset(o)
+	return nil
+}
+
+// ResetOffset restarts the stream from the log position in the input like {"offset": "mysql-bin.000001:4"}.
+func (s *Source) ResetOffset(input map[string]any) error {
+	v, ok := input["offset"]
+	if !ok {
+		return fmt.Errorf("offset is required to reset the offset")
+	}
+	o, err := cast.ToString(v, cast.CONVERT_SAMEKIND)
+	if err != nil || o == "" {
+		return fmt.Errorf("invalid offset %v", v)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.offset = o
+	if s.restart != nil {
+		s.restart()
+	}
+	return nil
+}
+
+func (s *Source) Ping(ctx api.StreamContext, props map[string]any) error {
+	c, err := newSourceConf(ctx, props)
+	if err != nil {
+		return err
+	}
+	cp, err := s.newCapturer(c)
+	if err != nil {
+		return err
+	}
+	defer cp.close(ctx)
+	return cp.connect(ctx)
+}
+
+func (s *Source) Close(ctx api.StreamContext) error {
+	if s.cap != nil {
+		s.cap.close(ctx)
+	}
+	return nil
+}
+
+func GetPgSource() api.Source {
+	return &Source{name: "postgres cdc", newCapturer: newPgCapturer}
+}
+
+var (
+	_ api.TupleSource       = &Source{}
+	_ api.Rewindable        = &Source{}
+	_ model.CheckpointAware = &Source{}
+	_ util.PingableConn     = &Source{}
+)
@@ -0,0 +1,251 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdc
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/require"
+
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+// fakeCapturer streams the transactions sent to the channel
+type fakeCapturer struct {
+	pos      string
+	rows     []*change
+	txns     chan *transaction
+	started  chan string
+	failures int
+
+	mu        sync.Mutex
+	confirmed []string
+}
+
+func newFakeCapturer() *fakeCapturer {
+	return &fakeCapturer{
+		pos:     "f:4",
+		txns:    make(chan *transaction),
+		started: make(chan string, 10),
+	}
+}
+
+func (f *fakeCapturer) connect(_ api.StreamContext) error {
+	return nil
+}
+
+func (f *fakeCapturer) position(_ api.StreamContext) (string, error) {
+	return f.pos, nil
+}
+
+func (f *fakeCapturer) snapshot(_ api.StreamContext, emit func(*change)) error {
+	for _, c := range f.rows {
+		emit(c)
+	}
+	return nil
+}
+
+func (f *fakeCapturer) stream(ctx api.StreamContext, offset string, emit func(*transaction)) error {
+	f.started <- offset
+	f.mu.Lock()
+	fail := f.failures > 0
+	f.failures--
+	f.mu.Unlock()
+	if fail {
+		return errors.New("stream broken")
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case t := <-f.txns:
+			emit(t)
+		}
+	}
+}
+
+func (f *fakeCapturer) confirm(_ api.StreamContext, offset string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.confirmed = append(f.confirmed, offset)
+}
+
+func (f *fakeCapturer) lastConfirmed() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.confirmed) == 0 {
+		return ""
+	}
+	return f.confirmed[len(f.confirmed)-1]
+}
+
+func (f *fakeCapturer) close(_ api.StreamContext) {}
+
+type ingested struct {
+	data map[string]any
+	meta map[string]any
+}
+
+func subscribe(t *testing.T, ctx api.StreamContext, s *Source) (chan ingested, chan error) {
+	dataCh := make(chan ingested, 10)
+	errCh := make(chan error, 10)
+	require.NoError(t, s.Subscribe(ctx, func(_ api.StreamContext, data any, meta map[string]any, _ time.Time) {
+		dataCh <- ingested{data: data.(map[string]any), meta: meta}
+	}, func(_ api.StreamContext, err error) {
+		errCh <- err
+	}))
+	return dataCh, errCh
+}
+
+func newFakeSource(t *testing.T, ctx api.StreamContext, fc *fakeCapturer, props map[string]any) *Source {
+	s := &Source{name: "fake", newCapturer: func(*sourceConf) (capturer, error) { return fc, nil }}
+	props["dburl"] = "fake://"
+	require.NoError(t, s.Provision(ctx, props))
+	require.NoError(t, s.Connect(ctx, func(string, string) {}))
+	return s
+}
+
+func TestSourceProvisionErr(t *testing.T) {
+	ctx := mockContext.NewMockContext("1", "2")
+	require.EqualError(t, GetPgSource().Provision(ctx, map[string]any{}), "dburl is required")
+	require.EqualError(t, GetPgSource().Provision(ctx, map[string]any{"dburl": "postgres://localhost/db", "slot": ""}), "slot is required")
+	require.EqualError(t, GetPgLookupSource().Provision(ctx, map[string]any{"dburl": "postgres://localhost/db", "datasource": "a,b"}), "datasource should be a single table for lookup")
+	require.EqualError(t, GetPgSource().Provision(ctx, map[string]any{"dburl": "postgres://localhost/db", "retryInterval": "0s"}), "retryInterval should be positive")
+}
+
+func TestSourceStream(t *testing.T) {
+	ctx, cancel := mockContext.NewMockContext("rule1", "op1").WithCancel()
+	defer cancel()
+	fc := newFakeCapturer()
+	fc.rows = []*change{{kind: "insert", schema: "db", table: "t1", after: map[string]any{"id": int64(1), "name": "a"}}}
+	s := newFakeSource(t, ctx, fc, map[string]any{"snapshot": true, "rowkindField": "op"})
+	dataCh, _ := subscribe(t, ctx, s)
+
+	// The snapshot is read before streaming from the position
+	r := <-dataCh
+	require.Equal(t, map[string]any{"id": int64(1), "name": "a", "op": "insert"}, r.data)
+	require.Equal(t, map[string]any{"schema": "db", "table": "t1", "offset": "", "after": map[string]any{"id": int64(1), "name": "a"}}, r.meta)
+	require.Equal(t, "f:4", <-fc.started)
+
+	fc.txns <- &transaction{offset: "f:10", changes: []*change{
+		{kind: "update", schema: "db", table: "t1", before: map[string]any{"id": int64(1), "name": "a"}, after: map[string]any{"id": int64(1), "name": "b"}},
+		{kind: "delete", schema: "db", table: "t1", before: map[string]any{"id": int64(1), "name": "b"}},
+	}}
+	r = <-dataCh
+	require.Equal(t, map[string]any{"id": int64(1), "name": "b", "op": "update"}, r.data)
+	require.Equal(t, map[string]any{"id": int64(1), "name": "a"}, r.meta["before"])
+	require.Equal(t, "f:10", r.meta["offset"])
+	r = <-dataCh
+	require.Equal(t, map[string]any{"id": int64(1), "name": "b", "op": "delete"}, r.data)
+	require.NotContains(t, r.meta, "after")
+	// The position is confirmed after each transaction without checkpoint
+	require.Eventually(t, func() bool { return fc.lastConfirmed() == "f:10" }, time.Second, 10*time.Millisecond)
+	offset, err := s.GetOffset()
+	require.NoError(t, err)
+	require.Equal(t, "f:10", offset)
+
+	// Restart from the reset offset
+	require.NoError(t, s.ResetOffset(map[string]any{"offset": "f:5"}))
+	require.Equal(t, "f:5", <-fc.started)
+	require.EqualError(t, s.ResetOffset(map[string]any{}), "offset is required to reset the offset")
+	require.NoError(t, s.Close(ctx))
+}
+
+func TestSourceCheckpoint(t *testing.T) {
+	ctx, cancel := mockContext.NewMockContext("rule1", "op1").WithCancel()
+	defer cancel()
+	fc := newFakeCapturer()
+	s := newFakeSource(t, ctx, fc, map[string]any{})
+	require.EqualError(t, s.Rewind(10), "fake source rewind failed, invalid offset 10")
+	// Restore from the checkpoint without reading the position
+	require.NoError(t, s.Rewind("g:8"))
+	s.EnableCheckpoint(ctx)
+	dataCh, _ := subscribe(t, ctx, s)
+	require.Equal(t, "g:8", <-fc.started)
+
+	fc.txns <- &transaction{offset: "g:20", changes: []*change{{kind: "insert", after: map[string]any{"id": 1}}}}
+	<-dataCh
+	require.Eventually(t, func() bool { o, _ := s.GetOffset(); return o == "g:20" }, time.Second, 10*time.Millisecond)
+	s.OnCheckpoint(ctx, 1)
+	fc.txns <- &transaction{offset: "g:30", changes: []*change{{kind: "insert", after: map[string]any{"id": 2}}}}
+	<-dataCh
+	require.Empty(t, fc.lastConfirmed())
+	// Only the offset of the completed checkpoint is confirmed
+	s.OnCheckpointComplete(ctx, 1)
+	require.Equal(t, "g:20", fc.lastConfirmed())
+	s.OnCheckpointComplete(ctx, 2)
+	require.Equal(t, "g:20", fc.lastConfirmed())
+}
+
+func TestSourceRetry(t *testing.T) {
+	ctx, cancel := mockContext.NewMockContext("rule1", "op1").WithCancel()
+	defer cancel()
+	fc := newFakeCapturer()
+	fc.failures = 1
+	s := newFakeSource(t, ctx, fc, map[string]any{"retryInterval": "10ms"})
+	_, errCh := subscribe(t, ctx, s)
+	require.Equal(t, "f:4", <-fc.started)
+	require.EqualError(t, <-errCh, "fake stream error: stream broken")
+	require.Equal(t, "f:4", <-fc.started)
+}
+
+func TestLookupSource(t *testing.T) {
+	ctx, cancel := mockContext.NewMockContext("rule1", "op1").WithCancel()
+	defer cancel()
+	fc := newFakeCapturer()
+	fc.rows = []*change{
+		{kind: "insert", keys: []string{"id"}, after: map[string]any{"id": int64(1), "name": "a", "group": "x"}},
+		{kind: "insert", keys: []string{"id"}, after: map[string]any{"id": int64(2), "name": "b", "group": "x"}},
+	}
+	s := &LookupSource{name: "fake", newCapturer: func(*sourceConf) (capturer, error) { return fc, nil }}
+	require.NoError(t, s.Provision(ctx, map[string]any{"dburl": "fake://", "datasource": "t1"}))
+	require.NoError(t, s.Connect(ctx, func(string, string) {}))
+	require.Equal(t, "f:4", <-fc.started)
+
+	// The lookup value of a different number type matches
+	r, err := s.Lookup(ctx, nil, []string{"id"}, []any{float64(1)})
+	require.NoError(t, err)
+	require.Equal(t, []map[string]any{{"id": int64(1), "name": "a", "group": "x"}}, r)
+	r, err = s.Lookup(ctx, []string{"name"}, []string{"group"}, []any{"x"})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []map[string]any{{"name": "a"}, {"name": "b"}}, r)
+
+	fc.txns <- &transaction{offset: "f:10", changes: []*change{
+		{kind: "update", keys: []string{"id"}, before: map[string]any{"id": int64(1), "name": "a", "group": "x"}, after: map[string]any{"id": int64(3), "name": "a", "group": "y"}},
+		{kind: "delete", keys: []string{"id"}, before: map[string]any{"id": int64(2)}},
+	}}
+	require.Eventually(t, func() bool { return fc.lastConfirmed() == "f:10" }, time.Second, 10*time.Millisecond)
+	r, err = s.Lookup(ctx, nil, []string{"group"}, []any{"x"})
+	require.NoError(t, err)
+	require.Empty(t, r)
+	r, err = s.Lookup(ctx, nil, []string{"id"}, []any{3})
+	require.NoError(t, err)
+	require.Equal(t, []map[string]any{{"id": int64(3), "name": "a", "group": "y"}}, r)
+	require.NoError(t, s.Close(ctx))
+}
+
+func TestTableWithoutKey(t *testing.T) {
+	tb := newTable()
+	tb.apply(&change{kind: "insert", after: map[string]any{"a": 1, "b": 2}})
+	tb.apply(&change{kind: "insert", after: map[string]any{"a": 1, "b": 3}})
+	require.Len(t, tb.lookup(nil, []string{"a"}, []any{1}), 2)
+	tb.apply(&change{kind: "update", before: map[string]any{"a": 1, "b": 2}, after: map[string]any{"a": 2, "b": 2}})
+	tb.apply(&change{kind: "delete", before: map[string]any{"a": 1, "b": 3}})
+	require.Equal(t, []map[string]any{{"a": 2, "b": 2}}, tb.lookup(nil, nil, nil))
+}
@@ -54,6 +54,8 @@ type kafkaSourceConf struct {
 	Partition   int    `json:"partition"`
 	MaxAttempts int    `json:"maxAttempts"`
 	MaxBytes    int    `json:"maxBytes"`
+	// WatermarkKey is the stream option WATERMARK_KEY. The metadata is only set when it is configured.
+	WatermarkKey string `json:"watermarkKey"`
 }
 
 func (c *kafkaSourceConf) validate() error {
@@ -224,7 +226,21 @@ func (k *KafkaSource) Subscribe(ctx api.StreamContext, ingest api.BytesIngest, i
 		KafkaSourceCounter.WithLabelValues(LblMsg, ctx.GetRuleId(), ctx.GetOpId()).Inc()
 		KafkaSourceCounter.WithLabelValues(LblBytes, ctx.GetRuleId(), ctx.GetOpId()).Add(float64(len(msg.Value)))
 		KafkaSourceGauge.WithLabelValues(LblOffset, ctx.GetRuleId(), ctx.GetOpId()).Set(float64(msg.Offset))
-		ingest(ctx, msg.Value, nil, timex.GetNow())
+		var meta map[string]any
+		if k.sc.WatermarkKey != "" {
+			meta = msgMeta(msg)
+		}
+		ingest(ctx, msg.Value, meta, timex.GetNow())
+	}
+}
+
+// msgMeta is the metadata of the message. The partition can be used to track the watermark of each partition.
+func msgMeta(msg kafkago.Message) map[string]any {
+	return map[string]any{
+		"topic":     msg.Topic,
+		"partition": msg.Partition,
+		"offset":    msg.Offset,
+		"key":       string(msg.Key),
 	}
 }
 
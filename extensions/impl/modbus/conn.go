@@ -0,0 +1,163 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goburrow/modbus"
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
+)
+
+type connectionConf struct {
+	// Protocol is tcp or rtu
+	Protocol string `json:"protocol"`
+	// Address is the host:port for tcp or the serial device for rtu
+	Address string            `json:"address"`
+	Timeout cast.DurationConf `json:"timeout"`
+	// Serial properties for rtu
+	BaudRate int    `json:"baudRate"`
+	DataBits int    `json:"dataBits"`
+	Parity   string `json:"parity"`
+	StopBits int    `json:"stopBits"`
+}
+
+func newConnectionConf(props map[string]any) (*connectionConf, error) {
+	c := &connectionConf{
+		Protocol: "tcp",
+		Timeout:  cast.DurationConf(5 * time.Second),
+		BaudRate: 19200,
+		DataBits: 8,
+		Parity:   "E",
+		StopBits: 1,
+	}
+	if err := cast.MapToStruct(props, c); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(c.Address) == "" {
+		return nil, fmt.Errorf("address can not be empty")
+	}
+	c.Protocol = strings.ToLower(c.Protocol)
+	if c.Protocol != "tcp" && c.Protocol != "rtu" {
+		return nil, fmt.Errorf("invalid protocol %s, expect tcp or rtu", c.Protocol)
+	}
+	return c, nil
+}
+
+// connect fetches the connection of the connector. The ref id is per operator so that each operator has its own
+// status handler. The connectors with the same connectionSelector share one connection.
+func connect(ctx api.StreamContext, props map[string]any, sch api.StatusChangeHandler) (*Connection, string, error) {
+	refId := fmt.Sprintf("%s_%s_%d", ctx.GetRuleId(), ctx.GetOpId(), ctx.GetInstanceId())
+	cw, err := connection.FetchConnection(ctx, refId, "modbus", props, sch)
+	if err != nil {
+		return nil, "", err
+	}
+	conn, err := cw.Wait(ctx)
+	if conn == nil {
+		return nil, cw.ID, fmt.Errorf("modbus client not ready: %v", err)
+	}
+	return conn.(*Connection), cw.ID, err
+}
+
+// clientHandler is the tcp or rtu client handler
+type clientHandler interface {
+	modbus.ClientHandler
+	Connect() error
+	Close() error
+}
+
+// Connection is the Modbus client connection to a device. The requests are serialized because
+// the slave id is set in the handler and the serial line can only send one request at a time.
+type Connection struct {
+	sync.Mutex
+	id      string
+	conf    *connectionConf
+	handler clientHandler
+	client  modbus.Client
+	// setSlave sets the slave id of the handler
+	setSlave func(id byte)
+}
+
+func init() {
+	modules.RegisterConnection("modbus", CreateConnection)
+}
+
+func CreateConnection(_ api.StreamContext) modules.Connection {
+	return &Connection{}
+}
+
+func (c *Connection) Provision(_ api.StreamContext, conId string, props map[string]any) error {
+	cc, err := newConnectionConf(props)
+	if err != nil {
+		return err
+	}
+	c.id = conId
+	c.conf = cc
+	switch cc.Protocol {
+	case "tcp":
+		h := modbus.NewTCPClientHandler(cc.Address)
+		h.Timeout = time.Duration(cc.Timeout)
+		c.handler = h
+		c.setSlave = func(id byte) { h.SlaveId = id }
+	case "rtu":
+		h := modbus.NewRTUClientHandler(cc.Address)
+		h.Timeout = time.Duration(cc.Timeout)
+		h.BaudRate = cc.BaudRate
+		h.DataBits = cc.DataBits
+		h.Parity = cc.Parity
+		h.StopBits = cc.StopBits
+		c.handler = h
+		c.setSlave = func(id byte) { h.SlaveId = id }
+	}
+	c.client = modbus.NewClient(c.handler)
+	return nil
+}
+
+func (c *Connection) Dial(_ api.StreamContext) error {
+	if err := c.handler.Connect(); err != nil {
+		return errorx.NewIOErr(fmt.Sprintf("found error when connecting to modbus device %s: %s", c.conf.Address, err))
+	}
+	return nil
+}
+
+func (c *Connection) GetId(_ api.StreamContext) string {
+	return c.id
+}
+
+func (c *Connection) Ping(ctx api.StreamContext) error {
+	return c.Dial(ctx)
+}
+
+func (c *Connection) Close(_ api.StreamContext) error {
+	return c.handler.Close()
+}
+
+// Do runs the requests to the slave exclusively
+func (c *Connection) Do(slaveId byte, f func(cli modbus.Client) error) error {
+	c.Lock()
+	defer c.Unlock()
+	c.setSlave(slaveId)
+	return f(c.client)
+}
+
+var _ modules.Connection = &Connection{}
@@ -0,0 +1,298 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+// The register types of the Modbus data model
+const (
+	Coil            = "coil"
+	DiscreteInput   = "discrete"
+	InputRegister   = "input"
+	HoldingRegister = "holding"
+)
+
+const (
+	// maxBits is the max quantity of the coils or discrete inputs in one read
+	maxBits = 2000
+	// maxRegisters is the max quantity of the registers in one read
+	maxRegisters = 125
+)
+
+// Register maps a field to the registers or bits of the device
+type Register struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Address uint16 `json:"address"`
+	// DataType is the type of the value in the registers, one of bool, int16, uint16, int32, uint32, int64,
+	// uint64, float32 and float64. The coils and discrete inputs are always bool.
+	DataType string `json:"dataType"`
+	// Scale is multiplied to the read value and divided from the written value. Default is 1
+	Scale float64 `json:"scale"`
+	// ByteOrder is the order of the bytes in the registers, one of ABCD (big endian), DCBA (little endian),
+	// BADC (byte swap) and CDAB (word swap). Default is ABCD
+	ByteOrder string `json:"byteOrder"`
+}
+
+// quantity returns the number of the registers or bits of the register
+func (r *Register) quantity() uint16 {
+	switch r.DataType {
+	case "int32", "uint32", "float32":
+		return 2
+	case "int64", "uint64", "float64":
+		return 4
+	default:
+		return 1
+	}
+}
+
+func (r *Register) isBit() bool {
+	return r.Type == Coil || r.Type == DiscreteInput
+}
+
+func (r *Register) validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("register name can not be empty")
+	}
+	switch r.Type {
+	case Coil, DiscreteInput:
+		if r.DataType == "" {
+			r.DataType = "bool"
+		}
+		if r.DataType != "bool" {
+			return fmt.Errorf("register %s of type %s only supports bool data type", r.Name, r.Type)
+		}
+	case InputRegister, HoldingRegister:
+		if r.DataType == "" {
+			r.DataType = "uint16"
+		}
+		switch r.DataType {
+		case "int16", "uint16", "int32", "uint32", "int64", "uint64", "float32", "float64":
+		default:
+			return fmt.Errorf("invalid data type %s of register %s", r.DataType, r.Name)
+		}
+	default:
+		return fmt.Errorf("invalid type %s of register %s, expect coil, discrete, input or holding", r.Type, r.Name)
+	}
+	if r.Scale == 0 {
+		r.Scale = 1
+	}
+	r.ByteOrder = strings.ToUpper(r.ByteOrder)
+	switch r.ByteOrder {
+	case "":
+		r.ByteOrder = "ABCD"
+	case "ABCD", "DCBA", "BADC", "CDAB":
+	default:
+		return fmt.Errorf("invalid byte order %s of register %s, expect ABCD, DCBA, BADC or CDAB", r.ByteOrder, r.Name)
+	}
+	if int(r.Address)+int(r.quantity()) > math.MaxUint16+1 {
+		return fmt.Errorf("address %d of register %s is out of range", r.Address, r.Name)
+	}
+	return nil
+}
+
+func validateRegisters(registers []*Register) error {
+	if len(registers) == 0 {
+		return fmt.Errorf("registers can not be empty")
+	}
+	names := make(map[string]bool, len(registers))
+	for _, r := range registers {
+		if err := r.validate(); err != nil {
+			return err
+		}
+		if names[r.Name] {
+			return fmt.Errorf("duplicate register name %s", r.Name)
+		}
+		names[r.Name] = true
+	}
+	return nil
+}
+
+// reorder converts the bytes between the byte order and the big endian. It is symmetric.
+func reorder(b []byte, order string) []byte {
+	r := make([]byte, len(b))
+	copy(r, b)
+	if order == "BADC" || order == "DCBA" {
+		for i := 0; i+1 < len(r); i += 2 {
+			r[i], r[i+1] = r[i+1], r[i]
+		}
+	}
+	if order == "CDAB" || order == "DCBA" {
+		n := len(r) / 2
+		for i := 0; i < n/2; i++ {
+			j := n - 1 - i
+			r[2*i], r[2*i+1], r[2*j], r[2*j+1] = r[2*j], r[2*j+1], r[2*i], r[2*i+1]
+		}
+	}
+	return r
+}
+
+// decode converts the register bytes to the value of the register
+func (r *Register) decode(b []byte) any {
+	b = reorder(b, r.ByteOrder)
+	var v float64
+	switch r.DataType {
+	case "int16":
+		v = float64(int16(binary.BigEndian.Uint16(b)))
+	case "uint16":
+		v = float64(binary.BigEndian.Uint16(b))
+	case "int32":
+		v = float64(int32(binary.BigEndian.Uint32(b)))
+	case "uint32":
+		v = float64(binary.BigEndian.Uint32(b))
+	case "int64":
+		i := int64(binary.BigEndian.Uint64(b))
+		if r.Scale == 1 {
+			return i
+		}
+		v = float64(i)
+	case "uint64":
+		u := binary.BigEndian.Uint64(b)
+		if r.Scale == 1 && u <= math.MaxInt64 {
+			return int64(u)
+		}
+		v = float64(u)
+	case "float32":
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))) * r.Scale
+	case "float64":
+		return math.Float64frombits(binary.BigEndian.Uint64(b)) * r.Scale
+	}
+	if r.Scale == 1 {
+		return int64(v)
+	}
+	return v * r.Scale
+}
+
+// encode converts the value to the register bytes
+func (r *Register) encode(v any) ([]byte, error) {
+	f, err := cast.ToFloat64(v, cast.CONVERT_SAMEKIND)
+	if err != nil {
+		return nil, err
+	}
+	f = f / r.Scale
+	b := make([]byte, 2*r.quantity())
+	switch r.DataType {
+	case "int16":
+		if f < math.MinInt16 || f > math.MaxInt16 {
+			return nil, fmt.Errorf("value %v overflows int16", f)
+		}
+		binary.BigEndian.PutUint16(b, uint16(int16(math.Round(f))))
+	case "uint16":
+		if f < 0 || f > math.MaxUint16 {
+			return nil, fmt.Errorf("value %v overflows uint16", f)
+		}
+		binary.BigEndian.PutUint16(b, uint16(math.Round(f)))
+	case "int32":
+		if f < math.MinInt32 || f > math.MaxInt32 {
+			return nil, fmt.Errorf("value %v overflows int32", f)
+		}
+		binary.BigEndian.PutUint32(b, uint32(int32(math.Round(f))))
+	case "uint32":
+		if f < 0 || f > math.MaxUint32 {
+			return nil, fmt.Errorf("value %v overflows uint32", f)
+		}
+		binary.BigEndian.PutUint32(b, uint32(math.Round(f)))
+	case "int64":
+		if r.Scale == 1 {
+			if i, ok := v.(int64); ok {
+				binary.BigEndian.PutUint64(b, uint64(i))
+				break
+			}
+		}
+		binary.BigEndian.PutUint64(b, uint64(int64(math.Round(f))))
+	case "uint64":
+		if f < 0 {
+			return nil, fmt.Errorf("value %v overflows uint64", f)
+		}
+		binary.BigEndian.PutUint64(b, uint64(math.Round(f)))
+	case "float32":
+		binary.BigEndian.PutUint32(b, math.Float32bits(float32(f)))
+	case "float64":
+		binary.BigEndian.PutUint64(b, math.Float64bits(f))
+	default:
+		return nil, fmt.Errorf("can not write data type %s", r.DataType)
+	}
+	return reorder(b, r.ByteOrder), nil
+}
+
+// readBlock is a contiguous range of the same register type to read in one request
+type readBlock struct {
+	typ       string
+	address   uint16
+	quantity  uint16
+	registers []*Register
+}
+
+// planReads merges the contiguous registers into the read blocks to reduce the requests
+func planReads(registers []*Register) []*readBlock {
+	sorted := make([]*Register, len(registers))
+	copy(sorted, registers)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Type != sorted[j].Type {
+			return sorted[i].Type < sorted[j].Type
+		}
+		return sorted[i].Address < sorted[j].Address
+	})
+	var (
+		blocks []*readBlock
+		cur    *readBlock
+	)
+	for _, r := range sorted {
+		limit := maxRegisters
+		if r.isBit() {
+			limit = maxBits
+		}
+		end := int(r.Address) + int(r.quantity())
+		// Only merge the contiguous registers because reading the undefined addresses may fail in the device
+		if cur != nil && cur.typ == r.Type && int(r.Address) <= int(cur.address)+int(cur.quantity) && end-int(cur.address) <= limit {
+			if e := end - int(cur.address); e > int(cur.quantity) {
+				cur.quantity = uint16(e)
+			}
+			cur.registers = append(cur.registers, r)
+			continue
+		}
+		cur = &readBlock{typ: r.Type, address: r.Address, quantity: r.quantity(), registers: []*Register{r}}
+		blocks = append(blocks, cur)
+	}
+	return blocks
+}
+
+// decodeBlock extracts the values of the registers from the read result of the block
+func (b *readBlock) decodeBlock(data []byte, result map[string]any) error {
+	for _, r := range b.registers {
+		offset := int(r.Address - b.address)
+		if r.isBit() {
+			if offset/8 >= len(data) {
+				return fmt.Errorf("read %d bytes is not enough for register %s", len(data), r.Name)
+			}
+			result[r.Name] = data[offset/8]&(1<<(offset%8)) != 0
+			continue
+		}
+		start, end := offset*2, (offset+int(r.quantity()))*2
+		if end > len(data) {
+			return fmt.Errorf("read %d bytes is not enough for register %s", len(data), r.Name)
+		}
+		result[r.Name] = r.decode(data[start:end])
+	}
+	return nil
+}
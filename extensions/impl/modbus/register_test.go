@@ -0,0 +1,183 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReorder(t *testing.T) {
+	b := []byte{1, 2, 3, 4}
+	require.Equal(t, []byte{1, 2, 3, 4}, reorder(b, "ABCD"))
+	require.Equal(t, []byte{4, 3, 2, 1}, reorder(b, "DCBA"))
+	require.Equal(t, []byte{2, 1, 4, 3}, reorder(b, "BADC"))
+	require.Equal(t, []byte{3, 4, 1, 2}, reorder(b, "CDAB"))
+	require.Equal(t, []byte{7, 8, 5, 6, 3, 4, 1, 2}, reorder([]byte{1, 2, 3, 4, 5, 6, 7, 8}, "CDAB"))
+	require.Equal(t, []byte{2, 1}, reorder([]byte{1, 2}, "DCBA"))
+}
+
+func TestEncodeDecode(t *testing.T) {
+	tests := []struct {
+		name   string
+		reg    *Register
+		value  any
+		bytes  []byte
+		decode any
+	}{
+		{
+			name:   "int16",
+			reg:    &Register{DataType: "int16"},
+			value:  int64(-2),
+			bytes:  []byte{0xFF, 0xFE},
+			decode: int64(-2),
+		},
+		{
+			name:   "uint16 scale",
+			reg:    &Register{DataType: "uint16", Scale: 0.1},
+			value:  25.6,
+			bytes:  []byte{0x01, 0x00},
+			decode: 25.6,
+		},
+		{
+			name:   "int32 word swap",
+			reg:    &Register{DataType: "int32", ByteOrder: "CDAB"},
+			value:  int64(0x01020304),
+			bytes:  []byte{0x03, 0x04, 0x01, 0x02},
+			decode: int64(0x01020304),
+		},
+		{
+			name:   "float32 little endian",
+			reg:    &Register{DataType: "float32", ByteOrder: "DCBA"},
+			value:  1.5,
+			bytes:  []byte{0x00, 0x00, 0xC0, 0x3F},
+			decode: 1.5,
+		},
+		{
+			name:   "int64",
+			reg:    &Register{DataType: "int64"},
+			value:  int64(-1),
+			bytes:  []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF},
+			decode: int64(-1),
+		},
+		{
+			name:   "float64",
+			reg:    &Register{DataType: "float64", ByteOrder: "BADC"},
+			value:  int64(2),
+			bytes:  []byte{0x00, 0x40, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			decode: 2.0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.reg.Name = "a"
+			tt.reg.Type = HoldingRegister
+			require.NoError(t, tt.reg.validate())
+			b, err := tt.reg.encode(tt.value)
+			require.NoError(t, err)
+			require.Equal(t, tt.bytes, b)
+			require.InDelta(t, tt.decode, tt.reg.decode(b), 1e-9)
+			require.IsType(t, tt.decode, tt.reg.decode(b))
+		})
+	}
+	r := &Register{Name: "a", Type: HoldingRegister, DataType: "uint16"}
+	require.NoError(t, r.validate())
+	_, err := r.encode(-1)
+	require.EqualError(t, err, "value -1 overflows uint16")
+	_, err = r.encode("abc")
+	require.Error(t, err)
+}
+
+func TestValidateRegisters(t *testing.T) {
+	tests := []struct {
+		name string
+		regs []*Register
+		err  string
+	}{
+		{
+			name: "empty",
+			err:  "registers can not be empty",
+		},
+		{
+			name: "no name",
+			regs: []*Register{{Type: Coil}},
+			err:  "register name can not be empty",
+		},
+		{
+			name: "invalid type",
+			regs: []*Register{{Name: "a", Type: "abc"}},
+			err:  "invalid type abc of register a, expect coil, discrete, input or holding",
+		},
+		{
+			name: "coil type",
+			regs: []*Register{{Name: "a", Type: Coil, DataType: "int16"}},
+			err:  "register a of type coil only supports bool data type",
+		},
+		{
+			name: "data type",
+			regs: []*Register{{Name: "a", Type: InputRegister, DataType: "string"}},
+			err:  "invalid data type string of register a",
+		},
+		{
+			name: "byte order",
+			regs: []*Register{{Name: "a", Type: InputRegister, ByteOrder: "AB"}},
+			err:  "invalid byte order AB of register a, expect ABCD, DCBA, BADC or CDAB",
+		},
+		{
+			name: "out of range",
+			regs: []*Register{{Name: "a", Type: InputRegister, DataType: "int32", Address: 65535}},
+			err:  "address 65535 of register a is out of range",
+		},
+		{
+			name: "duplicate",
+			regs: []*Register{{Name: "a", Type: Coil}, {Name: "a", Type: Coil, Address: 1}},
+			err:  "duplicate register name a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.EqualError(t, validateRegisters(tt.regs), tt.err)
+		})
+	}
+}
+
+func TestPlanReads(t *testing.T) {
+	regs := []*Register{
+		{Name: "a", Type: HoldingRegister, Address: 10, DataType: "float32"},
+		{Name: "b", Type: HoldingRegister, Address: 0},
+		{Name: "c", Type: HoldingRegister, Address: 1, DataType: "int64"},
+		{Name: "d", Type: Coil, Address: 3},
+		{Name: "e", Type: Coil, Address: 4},
+		{Name: "f", Type: HoldingRegister, Address: 5},
+	}
+	require.NoError(t, validateRegisters(regs))
+	blocks := planReads(regs)
+	require.Len(t, blocks, 3)
+	require.Equal(t, Coil, blocks[0].typ)
+	require.Equal(t, uint16(3), blocks[0].address)
+	require.Equal(t, uint16(2), blocks[0].quantity)
+	require.Equal(t, HoldingRegister, blocks[1].typ)
+	require.Equal(t, uint16(0), blocks[1].address)
+	require.Equal(t, uint16(6), blocks[1].quantity)
+	require.Len(t, blocks[1].registers, 3)
+	require.Equal(t, uint16(10), blocks[2].address)
+	require.Equal(t, uint16(2), blocks[2].quantity)
+
+	result := make(map[string]any)
+	require.NoError(t, blocks[0].decodeBlock([]byte{0x02}, result))
+	require.Equal(t, map[string]any{"d": false, "e": true}, result)
+	require.EqualError(t, blocks[2].decodeBlock([]byte{0x01}, result), "read 1 bytes is not enough for register a")
+}
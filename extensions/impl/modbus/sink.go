@@ -0,0 +1,130 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"fmt"
+
+	"github.com/goburrow/modbus"
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/util"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+)
+
+type sinkConf struct {
+	SlaveId   byte        `json:"slaveId"`
+	Registers []*Register `json:"registers"`
+}
+
+// Sink writes the fields of the result to the coils and holding registers. The fields not in the result are skipped.
+type Sink struct {
+	conf  *sinkConf
+	props map[string]any
+	conId string
+	conn  *Connection
+}
+
+func (s *Sink) Provision(_ api.StreamContext, props map[string]any) error {
+	if _, err := newConnectionConf(props); err != nil {
+		return err
+	}
+	c := &sinkConf{SlaveId: 1}
+	if err := cast.MapToStruct(props, c); err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	if err := validateRegisters(c.Registers); err != nil {
+		return err
+	}
+	for _, r := range c.Registers {
+		if r.Type != Coil && r.Type != HoldingRegister {
+			return fmt.Errorf("register %s of type %s is not writable, expect coil or holding", r.Name, r.Type)
+		}
+	}
+	s.conf = c
+	s.props = props
+	return nil
+}
+
+func (s *Sink) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	conn, conId, err := connect(ctx, s.props, sch)
+	s.conId = conId
+	s.conn = conn
+	return err
+}
+
+func (s *Sink) Collect(_ api.StreamContext, item api.MessageTuple) error {
+	return s.write([]map[string]any{item.ToMap()})
+}
+
+func (s *Sink) CollectList(_ api.StreamContext, items api.MessageTupleList) error {
+	return s.write(items.ToMaps())
+}
+
+func (s *Sink) write(items []map[string]any) error {
+	return s.conn.Do(s.conf.SlaveId, func(cli modbus.Client) error {
+		for _, item := range items {
+			for _, r := range s.conf.Registers {
+				v, ok := item[r.Name]
+				if !ok || v == nil {
+					continue
+				}
+				if err := writeRegister(cli, r, v); err != nil {
+					return fmt.Errorf("write register %s error: %v", r.Name, err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func writeRegister(cli modbus.Client, r *Register, v any) error {
+	if r.Type == Coil {
+		b, err := cast.ToBool(v, cast.CONVERT_SAMEKIND)
+		if err != nil {
+			return err
+		}
+		var value uint16
+		if b {
+			value = 0xFF00
+		}
+		_, err = cli.WriteSingleCoil(r.Address, value)
+		return err
+	}
+	data, err := r.encode(v)
+	if err != nil {
+		return err
+	}
+	_, err = cli.WriteMultipleRegisters(r.Address, r.quantity(), data)
+	return err
+}
+
+func (s *Sink) Ping(ctx api.StreamContext, props map[string]any) error {
+	return ping(ctx, props)
+}
+
+func (s *Sink) Close(ctx api.StreamContext) error {
+	return connection.DetachConnection(ctx, s.conId)
+}
+
+func GetSink() api.Sink {
+	return &Sink{}
+}
+
+var (
+	_ api.TupleCollector = &Sink{}
+	_ util.PingableConn  = &Sink{}
+)
@@ -0,0 +1,67 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+	"github.com/lf-edge/ekuiper/v2/pkg/mock"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/model"
+)
+
+func TestSinkProvisionErr(t *testing.T) {
+	ctx := mockContext.NewMockContext("1", "2")
+	err := GetSink().Provision(ctx, map[string]any{
+		"address":   "127.0.0.1:502",
+		"registers": []any{map[string]any{"name": "a", "type": "input", "address": 0}},
+	})
+	require.EqualError(t, err, "register a of type input is not writable, expect coil or holding")
+}
+
+func TestSinkCollect(t *testing.T) {
+	require.NoError(t, connection.InitConnectionManager4Test())
+	s, addr := startSimulator(t)
+	props := map[string]any{
+		"address": addr,
+		"registers": []any{
+			map[string]any{"name": "setpoint", "type": "holding", "address": 0, "dataType": "float32", "byteOrder": "CDAB"},
+			map[string]any{"name": "speed", "type": "holding", "address": 2, "scale": 0.1},
+			map[string]any{"name": "run", "type": "coil", "address": 7},
+		},
+	}
+	data := []any{
+		model.NewDefaultSourceTuple(map[string]any{"setpoint": 1.5, "speed": 12.3, "run": true}, nil, time.Now()),
+		&xsql.WindowTuples{Content: []xsql.Row{
+			&xsql.Tuple{Message: map[string]any{"speed": int64(5)}},
+			&xsql.Tuple{Message: map[string]any{"run": false, "other": "a"}},
+		}},
+	}
+	require.NoError(t, mock.RunTupleSinkCollect(GetSink().(api.TupleCollector), data, props))
+	require.Equal(t, []uint16{0x0000, 0x3FC0, 50}, s.HoldingRegisters[:3])
+	require.Equal(t, byte(0), s.Coils[7])
+
+	props["registers"] = []any{map[string]any{"name": "speed", "type": "holding", "address": 2, "dataType": "int16"}}
+	err := mock.RunTupleSinkCollect(GetSink().(api.TupleCollector), []any{
+		model.NewDefaultSourceTuple(map[string]any{"speed": 40000}, nil, time.Now()),
+	}, props)
+	require.EqualError(t, err, "write register speed error: value 40000 overflows int16")
+}
@@ -0,0 +1,132 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/goburrow/modbus"
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/util"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+)
+
+type sourceConf struct {
+	Interval  cast.DurationConf `json:"interval"`
+	SlaveId   byte              `json:"slaveId"`
+	Registers []*Register       `json:"registers"`
+}
+
+// Source polls the registers of a device in the interval. Each poll produces a tuple with
+// the register names as the fields.
+type Source struct {
+	conf   *sourceConf
+	blocks []*readBlock
+	props  map[string]any
+	conId  string
+	conn   *Connection
+}
+
+func (s *Source) Provision(_ api.StreamContext, props map[string]any) error {
+	if _, err := newConnectionConf(props); err != nil {
+		return err
+	}
+	c := &sourceConf{SlaveId: 1}
+	if err := cast.MapToStruct(props, c); err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	if time.Duration(c.Interval) <= 0 {
+		return fmt.Errorf("interval should be defined")
+	}
+	if err := validateRegisters(c.Registers); err != nil {
+		return err
+	}
+	s.conf = c
+	s.blocks = planReads(c.Registers)
+	s.props = props
+	return nil
+}
+
+func (s *Source) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	conn, conId, err := connect(ctx, s.props, sch)
+	s.conId = conId
+	s.conn = conn
+	return err
+}
+
+func (s *Source) Pull(ctx api.StreamContext, trigger time.Time, ingest api.TupleIngest, ingestError api.ErrorIngest) {
+	result := make(map[string]any, len(s.conf.Registers))
+	err := s.conn.Do(s.conf.SlaveId, func(cli modbus.Client) error {
+		for _, b := range s.blocks {
+			data, err := readBlockData(cli, b)
+			if err != nil {
+				return fmt.Errorf("read %d %s registers from address %d error: %v", b.quantity, b.typ, b.address, err)
+			}
+			if err := b.decodeBlock(data, result); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		ingestError(ctx, err)
+		return
+	}
+	ingest(ctx, result, nil, trigger)
+}
+
+func readBlockData(cli modbus.Client, b *readBlock) ([]byte, error) {
+	switch b.typ {
+	case Coil:
+		return cli.ReadCoils(b.address, b.quantity)
+	case DiscreteInput:
+		return cli.ReadDiscreteInputs(b.address, b.quantity)
+	case InputRegister:
+		return cli.ReadInputRegisters(b.address, b.quantity)
+	default:
+		return cli.ReadHoldingRegisters(b.address, b.quantity)
+	}
+}
+
+func (s *Source) Ping(ctx api.StreamContext, props map[string]any) error {
+	return ping(ctx, props)
+}
+
+func (s *Source) Close(ctx api.StreamContext) error {
+	return connection.DetachConnection(ctx, s.conId)
+}
+
+func ping(ctx api.StreamContext, props map[string]any) error {
+	conn := &Connection{}
+	if err := conn.Provision(ctx, "test", props); err != nil {
+		return err
+	}
+	if err := conn.Dial(ctx); err != nil {
+		return err
+	}
+	return conn.Close(ctx)
+}
+
+func GetSource() api.Source {
+	return &Source{}
+}
+
+var (
+	_ api.PullTupleSource = &Source{}
+	_ util.PingableConn   = &Source{}
+)
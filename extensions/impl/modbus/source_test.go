@@ -0,0 +1,183 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modbus
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/require"
+	"github.com/tbrandon/mbserver"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+// startSimulator starts an in-process Modbus TCP simulator and returns its address
+func startSimulator(t *testing.T) (*mbserver.Server, string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+	s := mbserver.NewServer()
+	require.NoError(t, s.ListenTCP(addr))
+	t.Cleanup(s.Close)
+	return s, addr
+}
+
+func TestSourceProvisionErr(t *testing.T) {
+	ctx := mockContext.NewMockContext("1", "2")
+	tests := []struct {
+		name  string
+		props map[string]any
+		err   string
+	}{
+		{
+			name:  "no address",
+			props: map[string]any{},
+			err:   "address can not be empty",
+		},
+		{
+			name:  "invalid protocol",
+			props: map[string]any{"address": "127.0.0.1:502", "protocol": "udp"},
+			err:   "invalid protocol udp, expect tcp or rtu",
+		},
+		{
+			name:  "no interval",
+			props: map[string]any{"address": "127.0.0.1:502"},
+			err:   "interval should be defined",
+		},
+		{
+			name:  "no registers",
+			props: map[string]any{"address": "127.0.0.1:502", "interval": "1s"},
+			err:   "registers can not be empty",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.EqualError(t, GetSource().Provision(ctx, tt.props), tt.err)
+		})
+	}
+}
+
+func TestSourcePull(t *testing.T) {
+	require.NoError(t, connection.InitConnectionManager4Test())
+	s, addr := startSimulator(t)
+	s.HoldingRegisters[0] = 0xFFFE
+	s.HoldingRegisters[1] = 0x3FC0
+	s.HoldingRegisters[2] = 0x0000
+	s.HoldingRegisters[3] = 256
+	s.InputRegisters[10] = 0x0102
+	s.Coils[5] = 1
+	s.DiscreteInputs[0] = 0
+
+	props := map[string]any{
+		"address":  addr,
+		"interval": "1s",
+		"registers": []any{
+			map[string]any{"name": "a", "type": "holding", "address": 0, "dataType": "int16"},
+			map[string]any{"name": "b", "type": "holding", "address": 1, "dataType": "float32"},
+			map[string]any{"name": "c", "type": "holding", "address": 3, "scale": 0.1},
+			map[string]any{"name": "d", "type": "input", "address": 10, "byteOrder": "DCBA"},
+			map[string]any{"name": "e", "type": "coil", "address": 5},
+			map[string]any{"name": "f", "type": "discrete", "address": 0},
+		},
+	}
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	src := GetSource()
+	require.NoError(t, src.Provision(ctx, props))
+	require.NoError(t, src.Connect(ctx, func(string, string) {}))
+	// Without connectionSelector, another stream polling the same device has its own connection
+	ctx2 := mockContext.NewMockContext("rule2", "op1")
+	src2 := GetSource()
+	require.NoError(t, src2.Provision(ctx2, props))
+	require.NoError(t, src2.Connect(ctx2, func(string, string) {}))
+	require.NotSame(t, src.(*Source).conn, src2.(*Source).conn)
+
+	var result any
+	now := time.Now()
+	src.(api.PullTupleSource).Pull(ctx, now, func(_ api.StreamContext, data any, _ map[string]any, ts time.Time) {
+		require.Equal(t, now, ts)
+		result = data
+	}, func(_ api.StreamContext, err error) {
+		t.Error(err)
+	})
+	require.Equal(t, map[string]any{"a": int64(-2), "b": 1.5, "c": 25.6, "d": int64(0x0201), "e": true, "f": false}, result)
+	require.NoError(t, src.Close(ctx))
+	require.NoError(t, src2.Close(ctx2))
+
+	// Read error
+	s.RegisterFunctionHandler(3, func(*mbserver.Server, mbserver.Framer) ([]byte, *mbserver.Exception) {
+		return nil, &mbserver.IllegalDataAddress
+	})
+	require.NoError(t, src.Provision(ctx, props))
+	require.NoError(t, src.Connect(ctx, func(string, string) {}))
+	var e error
+	src.(api.PullTupleSource).Pull(ctx, now, func(_ api.StreamContext, data any, _ map[string]any, ts time.Time) {
+		t.Errorf("should not ingest %v", data)
+	}, func(_ api.StreamContext, err error) {
+		e = err
+	})
+	require.NoError(t, src.Close(ctx))
+	require.EqualError(t, e, "read 4 holding registers from address 0 error: modbus: exception '2' (illegal data address), function '131'")
+}
+
+func TestSharedConnection(t *testing.T) {
+	require.NoError(t, connection.InitConnectionManager4Test())
+	_, addr := startSimulator(t)
+	ctx1 := mockContext.NewMockContext("rule1", "op1")
+	ctx2 := mockContext.NewMockContext("rule2", "op1")
+	_, err := connection.CreateNamedConnection(ctx1, "modbusShared", "modbus", map[string]any{"address": addr})
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, connection.DropNameConnection(ctx1, "modbusShared"))
+	}()
+
+	var status1, status2 []string
+	src := GetSource()
+	require.NoError(t, src.Provision(ctx1, map[string]any{
+		"connectionSelector": "modbusShared",
+		"address":            addr,
+		"interval":           "1s",
+		"registers":          []any{map[string]any{"name": "a", "type": "holding", "address": 0}},
+	}))
+	require.NoError(t, src.Connect(ctx1, func(status string, _ string) {
+		status1 = append(status1, status)
+	}))
+	sink := GetSink()
+	require.NoError(t, sink.Provision(ctx2, map[string]any{
+		"connectionSelector": "modbusShared",
+		"address":            addr,
+		"registers":          []any{map[string]any{"name": "a", "type": "holding", "address": 0}},
+	}))
+	require.NoError(t, sink.Connect(ctx2, func(status string, _ string) {
+		status2 = append(status2, status)
+	}))
+	require.Same(t, src.(*Source).conn, sink.(*Sink).conn)
+	require.Equal(t, api.ConnectionConnected, status1[len(status1)-1])
+	require.Equal(t, api.ConnectionConnected, status2[len(status2)-1])
+
+	// Each rule has its own status handler which is removed when detached
+	meta, err := connection.GetConnectionDetail(ctx1, "modbusShared")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"rule1_op1_0", "rule2_op1_0"}, meta.GetRefNames())
+	require.NoError(t, src.Close(ctx1))
+	require.Equal(t, []string{"rule2_op1_0"}, meta.GetRefNames())
+	require.NoError(t, sink.Close(ctx2))
+	require.Empty(t, meta.GetRefNames())
+	require.Equal(t, 0, meta.GetRefCount())
+}
@@ -0,0 +1,198 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build nats
+
+package nats
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cert"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
+	"github.com/lf-edge/ekuiper/v2/pkg/syncx"
+)
+
+type connectionConf struct {
+	// Server is a comma separated list of the server urls
+	Server          string            `json:"server"`
+	Username        string            `json:"username"`
+	Password        string            `json:"password"`
+	Token           string            `json:"token"`
+	CredentialsFile string            `json:"credentialsFile"`
+	ConnectTimeout  cast.DurationConf `json:"connectTimeout"`
+}
+
+func newConnectionConf(props map[string]any) (*connectionConf, error) {
+	c := &connectionConf{
+		ConnectTimeout: cast.DurationConf(nats.DefaultTimeout),
+	}
+	if err := cast.MapToStruct(props, c); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(c.Server) == "" {
+		return nil, fmt.Errorf("server can not be empty")
+	}
+	if c.Token != "" && c.Username != "" {
+		return nil, fmt.Errorf("token and username can not be set at the same time")
+	}
+	return c, nil
+}
+
+// Connection wraps a nats connection. The nats client reconnects by itself, so the connection only reports
+// the status changes to the node.
+type Connection struct {
+	mu        syncx.Mutex
+	id        string
+	conf      *connectionConf
+	opts      []nats.Option
+	nc        *nats.Conn
+	js        jetstream.JetStream
+	status    atomic.Value
+	scHandler api.StatusChangeHandler
+}
+
+func CreateConnection(_ api.StreamContext) modules.Connection {
+	return &Connection{}
+}
+
+func (c *Connection) Provision(ctx api.StreamContext, conId string, props map[string]any) error {
+	cc, err := newConnectionConf(props)
+	if err != nil {
+		return err
+	}
+	tlsConf, err := cert.GenTLSConfig(ctx, props)
+	if err != nil {
+		return err
+	}
+	opts := []nats.Option{
+		nats.Name(fmt.Sprintf("ekuiper-%s", conId)),
+		nats.Timeout(time.Duration(cc.ConnectTimeout)),
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			msg := "connection closed"
+			if err != nil {
+				msg = err.Error()
+			}
+			c.onStatusChange(ctx, api.ConnectionDisconnected, msg)
+		}),
+		nats.ReconnectHandler(func(_ *nats.Conn) {
+			c.onStatusChange(ctx, api.ConnectionConnected, "")
+		}),
+	}
+	switch {
+	case cc.Username != "":
+		opts = append(opts, nats.UserInfo(cc.Username, cc.Password))
+	case cc.Token != "":
+		opts = append(opts, nats.Token(cc.Token))
+	}
+	if cc.CredentialsFile != "" {
+		opts = append(opts, nats.UserCredentials(cc.CredentialsFile))
+	}
+	if tlsConf != nil {
+		opts = append(opts, nats.Secure(tlsConf))
+	}
+	c.id = conId
+	c.conf = cc
+	c.opts = opts
+	c.status.Store(modules.ConnectionStatus{Status: api.ConnectionConnecting})
+	return nil
+}
+
+func (c *Connection) Dial(ctx api.StreamContext) error {
+	nc, err := nats.Connect(c.conf.Server, c.opts...)
+	if err != nil {
+		return errorx.NewIOErr(fmt.Sprintf("found error when connecting for %s: %s", c.conf.Server, err))
+	}
+	js, err := jetstream.New(nc)
+	if err != nil {
+		nc.Close()
+		return err
+	}
+	c.nc = nc
+	c.js = js
+	c.onStatusChange(ctx, api.ConnectionConnected, "")
+	ctx.GetLogger().Infof("new nats client created")
+	return nil
+}
+
+func (c *Connection) GetId(_ api.StreamContext) string {
+	return c.id
+}
+
+func (c *Connection) Status(_ api.StreamContext) modules.ConnectionStatus {
+	return c.status.Load().(modules.ConnectionStatus)
+}
+
+func (c *Connection) SetStatusChangeHandler(_ api.StreamContext, sch api.StatusChangeHandler) {
+	st := c.status.Load().(modules.ConnectionStatus)
+	sch(st.Status, st.ErrMsg)
+	c.mu.Lock()
+	c.scHandler = sch
+	c.mu.Unlock()
+}
+
+func (c *Connection) onStatusChange(ctx api.StreamContext, status, msg string) {
+	c.status.Store(modules.ConnectionStatus{Status: status, ErrMsg: msg})
+	c.mu.Lock()
+	handler := c.scHandler
+	c.mu.Unlock()
+	if handler != nil {
+		handler(status, msg)
+	}
+	ctx.GetLogger().Infof("nats connection %s status changed to %s %s", c.id, status, msg)
+}
+
+func (c *Connection) Ping(_ api.StreamContext) error {
+	if c.nc == nil || !c.nc.IsConnected() {
+		return fmt.Errorf("nats client is not connected")
+	}
+	return c.nc.FlushTimeout(time.Duration(c.conf.ConnectTimeout))
+}
+
+func (c *Connection) Close(_ api.StreamContext) error {
+	if c.nc != nil {
+		c.nc.Close()
+	}
+	return nil
+}
+
+func ping(ctx api.StreamContext, props map[string]any) error {
+	c := &Connection{}
+	if err := c.Provision(ctx, "test", props); err != nil {
+		return err
+	}
+	if err := c.Dial(ctx); err != nil {
+		return err
+	}
+	return c.Close(ctx)
+}
+
+func init() {
+	modules.RegisterConnection("nats", CreateConnection)
+}
+
+var (
+	_ modules.Connection     = &Connection{}
+	_ modules.StatefulDialer = &Connection{}
+)
@@ -0,0 +1,199 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build nats
+
+package nats
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/util"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+)
+
+type jetStreamConf struct {
+	Stream  string `json:"stream"`
+	Durable string `json:"durable"`
+	// Subject filters the subjects of the stream. It is optional.
+	Subject string `json:"datasource"`
+}
+
+// JetStreamSource consumes a JetStream stream by a durable pull consumer. Each message is acked after ingested.
+// The stream sequence of the last ingested message is the offset, so that the rule can rewind to the
+// checkpoint when restarting with qos.
+type JetStreamSource struct {
+	conf  *jetStreamConf
+	props map[string]any
+	conId string
+	conn  *Connection
+
+	mu     sync.Mutex
+	cc     jetstream.ConsumeContext
+	ctx    api.StreamContext
+	ingest api.BytesIngest
+	errIn  api.ErrorIngest
+	// offset is the stream sequence of the last ingested message
+	offset atomic.Uint64
+	// startSeq is the stream sequence to start with when rewound. 0 means continue from the durable consumer
+	startSeq uint64
+}
+
+func (s *JetStreamSource) Provision(_ api.StreamContext, props map[string]any) error {
+	if _, err := newConnectionConf(props); err != nil {
+		return err
+	}
+	c := &jetStreamConf{}
+	if err := cast.MapToStruct(props, c); err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	if c.Stream == "" {
+		return fmt.Errorf("stream is required")
+	}
+	if c.Durable == "" {
+		return fmt.Errorf("durable is required")
+	}
+	s.conf = c
+	s.props = props
+	return nil
+}
+
+func (s *JetStreamSource) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	conn, conId, err := connect(ctx, fmt.Sprintf("%s-%s-nats-source", ctx.GetRuleId(), ctx.GetOpId()), s.props, sch)
+	s.conId = conId
+	s.conn = conn
+	return err
+}
+
+func (s *JetStreamSource) Subscribe(ctx api.StreamContext, ingest api.BytesIngest, ingestError api.ErrorIngest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ctx = ctx
+	s.ingest = ingest
+	s.errIn = ingestError
+	return s.consume()
+}
+
+// consume creates the consumer and starts consuming. Must be called with the lock held.
+func (s *JetStreamSource) consume() error {
+	ctx := s.ctx
+	cfg := jetstream.ConsumerConfig{
+		Durable:       s.conf.Durable,
+		FilterSubject: s.conf.Subject,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+	}
+	if s.startSeq > 0 {
+		// The deliver policy of a durable consumer cannot be updated, so recreate it to start from the sequence
+		if err := s.conn.js.DeleteConsumer(ctx, s.conf.Stream, s.conf.Durable); err != nil && !errors.Is(err, jetstream.ErrConsumerNotFound) {
+			return fmt.Errorf("delete consumer %s error: %v", s.conf.Durable, err)
+		}
+		cfg.DeliverPolicy = jetstream.DeliverByStartSequencePolicy
+		cfg.OptStartSeq = s.startSeq
+		s.startSeq = 0
+	}
+	cons, err := s.conn.js.CreateOrUpdateConsumer(ctx, s.conf.Stream, cfg)
+	if err != nil {
+		return fmt.Errorf("create consumer %s of stream %s error: %v", s.conf.Durable, s.conf.Stream, err)
+	}
+	ingest := s.ingest
+	s.cc, err = cons.Consume(func(msg jetstream.Msg) {
+		md, err := msg.Metadata()
+		if err != nil {
+			s.errIn(ctx, err)
+			return
+		}
+		meta := msgMeta(msg.Subject(), msg.Headers())
+		meta["sequence"] = md.Sequence.Stream
+		ingest(ctx, msg.Data(), meta, md.Timestamp)
+		s.offset.Store(md.Sequence.Stream)
+		if err := msg.Ack(); err != nil {
+			ctx.GetLogger().Warnf("ack message %d error: %v", md.Sequence.Stream, err)
+		}
+	}, jetstream.ConsumeErrHandler(func(_ jetstream.ConsumeContext, err error) {
+		ctx.GetLogger().Errorf("consume stream %s error: %v", s.conf.Stream, err)
+	}))
+	if err != nil {
+		return fmt.Errorf("consume stream %s error: %v", s.conf.Stream, err)
+	}
+	return nil
+}
+
+func (s *JetStreamSource) GetOffset() (any, error) {
+	return s.offset.Load(), nil
+}
+
+// Rewind sets the sequence to restart with. It is called before subscribing when restoring from the checkpoint.
+func (s *JetStreamSource) Rewind(offset any) error {
+	seq, err := cast.ToUint64(offset, cast.CONVERT_SAMEKIND)
+	if err != nil {
+		return fmt.Errorf("%v can't be set as offset", offset)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.offset.Store(seq)
+	s.startSeq = seq + 1
+	return nil
+}
+
+// ResetOffset restarts the consumer from the stream sequence in the input like {"sequence": 100}.
+func (s *JetStreamSource) ResetOffset(input map[string]any) error {
+	v, ok := input["sequence"]
+	if !ok {
+		return fmt.Errorf("sequence is required to reset the offset")
+	}
+	seq, err := cast.ToUint64(v, cast.CONVERT_SAMEKIND)
+	if err != nil || seq == 0 {
+		return fmt.Errorf("invalid sequence %v", v)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.startSeq = seq
+	if s.cc == nil {
+		return nil
+	}
+	s.cc.Stop()
+	<-s.cc.Closed()
+	return s.consume()
+}
+
+func (s *JetStreamSource) Ping(ctx api.StreamContext, props map[string]any) error {
+	return ping(ctx, props)
+}
+
+func (s *JetStreamSource) Close(ctx api.StreamContext) error {
+	s.mu.Lock()
+	if s.cc != nil {
+		s.cc.Stop()
+		s.cc = nil
+	}
+	s.mu.Unlock()
+	return connection.DetachConnection(ctx, s.conId)
+}
+
+func GetJetStreamSource() api.Source {
+	return &JetStreamSource{}
+}
+
+var (
+	_ api.BytesSource   = &JetStreamSource{}
+	_ api.Rewindable    = &JetStreamSource{}
+	_ util.PingableConn = &JetStreamSource{}
+)
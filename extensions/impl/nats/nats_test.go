@@ -0,0 +1,243 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build nats
+
+package nats
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+	"github.com/lf-edge/ekuiper/v2/pkg/mock"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/model"
+)
+
+// startServer starts an embedded nats server with JetStream enabled and returns its url
+func startServer(t *testing.T) string {
+	s, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+	})
+	require.NoError(t, err)
+	go s.Start()
+	require.True(t, s.ReadyForConnections(5*time.Second))
+	t.Cleanup(s.Shutdown)
+	return s.ClientURL()
+}
+
+func TestProvisionErr(t *testing.T) {
+	ctx := mockContext.NewMockContext("1", "2")
+	tests := []struct {
+		name  string
+		r     api.Source
+		props map[string]any
+		err   string
+	}{
+		{
+			name:  "no server",
+			r:     GetSource(),
+			props: map[string]any{},
+			err:   "server can not be empty",
+		},
+		{
+			name:  "token and username",
+			r:     GetSource(),
+			props: map[string]any{"server": "nats://127.0.0.1:4222", "token": "a", "username": "b"},
+			err:   "token and username can not be set at the same time",
+		},
+		{
+			name:  "no subject",
+			r:     GetSource(),
+			props: map[string]any{"server": "nats://127.0.0.1:4222"},
+			err:   "subject is required",
+		},
+		{
+			name:  "no stream",
+			r:     GetJetStreamSource(),
+			props: map[string]any{"server": "nats://127.0.0.1:4222"},
+			err:   "stream is required",
+		},
+		{
+			name:  "no durable",
+			r:     GetJetStreamSource(),
+			props: map[string]any{"server": "nats://127.0.0.1:4222", "stream": "s"},
+			err:   "durable is required",
+		},
+		{
+			name:  "sink wildcard",
+			r:     GetSink(),
+			props: map[string]any{"server": "nats://127.0.0.1:4222", "subject": "a.>"},
+			err:   "subject a.> of sink should not contain wildcards",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.EqualError(t, tt.r.Provision(ctx, tt.props), tt.err)
+		})
+	}
+}
+
+func TestSource(t *testing.T) {
+	require.NoError(t, connection.InitConnectionManager4Test())
+	url := startServer(t)
+	nc, err := nats.Connect(url)
+	require.NoError(t, err)
+	defer nc.Close()
+
+	expected := []api.MessageTuple{
+		model.NewDefaultRawTupleIgnoreTs([]byte(`{"a":1}`), map[string]any{"subject": "sensor.1.temp"}),
+		model.NewDefaultRawTupleIgnoreTs([]byte(`{"a":2}`), map[string]any{"subject": "sensor.2.temp", "headers": map[string]any{"k": "v"}}),
+	}
+	mock.TestSourceConnector(t, GetSource(), map[string]any{
+		"server":     url,
+		"datasource": "sensor.*.temp",
+		"queue":      "g1",
+		"ignoreTs":   true,
+	}, expected, func() {
+		time.Sleep(100 * time.Millisecond)
+		require.NoError(t, nc.Publish("sensor.1.temp", []byte(`{"a":1}`)))
+		require.NoError(t, nc.Publish("sensor.1.humidity", []byte(`{"a":0}`)))
+		require.NoError(t, nc.PublishMsg(&nats.Msg{Subject: "sensor.2.temp", Data: []byte(`{"a":2}`), Header: nats.Header{"k": []string{"v"}}}))
+	})
+}
+
+func TestJetStreamSource(t *testing.T) {
+	require.NoError(t, connection.InitConnectionManager4Test())
+	url := startServer(t)
+	nc, err := nats.Connect(url)
+	require.NoError(t, err)
+	defer nc.Close()
+	js, err := jetstream.New(nc)
+	require.NoError(t, err)
+	_, err = js.CreateStream(context.Background(), jetstream.StreamConfig{Name: "events", Subjects: []string{"events.>"}})
+	require.NoError(t, err)
+	for _, d := range []string{"a", "b", "c"} {
+		_, err = js.Publish(context.Background(), "events."+d, []byte(d))
+		require.NoError(t, err)
+	}
+	props := map[string]any{"server": url, "stream": "events", "durable": "d1"}
+
+	consume := func(src api.Source, n int) []string {
+		ctx, cancel := mockContext.NewMockContext("rule1", "op1").WithCancel()
+		defer cancel()
+		require.NoError(t, src.Provision(ctx, props))
+		require.NoError(t, src.Connect(ctx, func(string, string) {}))
+		ch := make(chan string, 10)
+		require.NoError(t, src.(api.BytesSource).Subscribe(ctx, func(_ api.StreamContext, payload []byte, meta map[string]any, _ time.Time) {
+			ch <- string(payload)
+		}, func(_ api.StreamContext, err error) {
+			t.Error(err)
+		}))
+		var result []string
+		for i := 0; i < n; i++ {
+			select {
+			case d := <-ch:
+				result = append(result, d)
+			case <-time.After(5 * time.Second):
+				t.Fatal("timeout")
+			}
+		}
+		require.NoError(t, src.Close(ctx))
+		return result
+	}
+
+	src := GetJetStreamSource()
+	require.Equal(t, []string{"a", "b", "c"}, consume(src, 3))
+	offset, err := src.(api.Rewindable).GetOffset()
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), offset)
+
+	// The durable consumer continues after the acked messages
+	_, err = js.Publish(context.Background(), "events.d", []byte("d"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"d"}, consume(GetJetStreamSource(), 1))
+
+	// Rewind to the checkpoint after the first message
+	src = GetJetStreamSource()
+	require.NoError(t, src.(api.Rewindable).Rewind(int64(1)))
+	require.Equal(t, []string{"b", "c", "d"}, consume(src, 3))
+	require.EqualError(t, src.(api.Rewindable).Rewind("a"), "a can't be set as offset")
+}
+
+func TestSink(t *testing.T) {
+	require.NoError(t, connection.InitConnectionManager4Test())
+	url := startServer(t)
+	nc, err := nats.Connect(url)
+	require.NoError(t, err)
+	defer nc.Close()
+	sub, err := nc.SubscribeSync("out.>")
+	require.NoError(t, err)
+	require.NoError(t, nc.Flush())
+
+	require.NoError(t, mock.RunBytesSinkCollect(GetSink().(api.BytesCollector), [][]byte{[]byte("hello")}, map[string]any{
+		"server":  url,
+		"subject": "out.a",
+		"headers": map[string]any{"source": "ekuiper"},
+	}))
+	msg, err := sub.NextMsg(time.Second)
+	require.NoError(t, err)
+	require.Equal(t, "out.a", msg.Subject)
+	require.Equal(t, "hello", string(msg.Data))
+	require.Equal(t, "ekuiper", msg.Header.Get("source"))
+
+	// Dynamic subject and headers
+	s := GetSink().(api.BytesCollector)
+	ctx := mockContext.NewMockContext("ruleSink", "op1")
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"server":  url,
+		"subject": "{{.device}}",
+		"headers": map[string]any{"device": "{{.device}}"},
+	}))
+	require.NoError(t, s.Connect(ctx, func(string, string) {}))
+	tuple := &xsql.RawTuple{Rawdata: []byte("world"), Props: map[string]string{"{{.device}}": "out.dev1"}}
+	require.NoError(t, s.Collect(ctx, tuple))
+	require.NoError(t, s.Close(ctx))
+	msg, err = sub.NextMsg(time.Second)
+	require.NoError(t, err)
+	require.Equal(t, "out.dev1", msg.Subject)
+	require.Equal(t, "out.dev1", msg.Header.Get("device"))
+
+	// Publish to JetStream
+	js, err := jetstream.New(nc)
+	require.NoError(t, err)
+	stream, err := js.CreateStream(context.Background(), jetstream.StreamConfig{Name: "out", Subjects: []string{"out.>"}})
+	require.NoError(t, err)
+	require.NoError(t, mock.RunBytesSinkCollect(GetSink().(api.BytesCollector), [][]byte{[]byte("persisted")}, map[string]any{
+		"server":    url,
+		"subject":   "out.js",
+		"jetstream": true,
+	}))
+	info, err := stream.Info(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), info.State.Msgs)
+	err = mock.RunBytesSinkCollect(GetSink().(api.BytesCollector), [][]byte{[]byte("lost")}, map[string]any{
+		"server":    url,
+		"subject":   "none.js",
+		"jetstream": true,
+	})
+	require.ErrorContains(t, err, "publish to stream subject none.js error")
+}
@@ -0,0 +1,124 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build nats
+
+package nats
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/nats-io/nats.go"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/util"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+)
+
+type sinkConf struct {
+	Subject string            `json:"subject"`
+	Headers map[string]string `json:"headers"`
+	// JetStream publishes to a stream and waits for the ack
+	JetStream bool `json:"jetstream"`
+}
+
+// Sink publishes the encoded result to a subject. Both the subject and the header values support
+// the data template.
+type Sink struct {
+	conf  *sinkConf
+	props map[string]any
+	conId string
+	conn  *Connection
+}
+
+func (s *Sink) Provision(_ api.StreamContext, props map[string]any) error {
+	if _, err := newConnectionConf(props); err != nil {
+		return err
+	}
+	c := &sinkConf{}
+	if err := cast.MapToStruct(props, c); err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	if c.Subject == "" {
+		return fmt.Errorf("subject is required")
+	}
+	if strings.ContainsAny(c.Subject, "*>") {
+		return fmt.Errorf("subject %s of sink should not contain wildcards", c.Subject)
+	}
+	s.conf = c
+	s.props = props
+	return nil
+}
+
+func (s *Sink) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	conn, conId, err := connect(ctx, fmt.Sprintf("%s-%s-nats-sink", ctx.GetRuleId(), ctx.GetOpId()), s.props, sch)
+	s.conId = conId
+	s.conn = conn
+	return err
+}
+
+func (s *Sink) Collect(ctx api.StreamContext, item api.RawTuple) error {
+	msg := &nats.Msg{Subject: s.conf.Subject, Data: item.Raw()}
+	if len(s.conf.Headers) > 0 {
+		msg.Header = make(nats.Header, len(s.conf.Headers))
+		for k, v := range s.conf.Headers {
+			msg.Header.Set(k, v)
+		}
+	}
+	// If the props support dynamic props(template), planner will guarantee the result has the parsed dynamic props
+	if dp, ok := item.(api.HasDynamicProps); ok {
+		if sub, ok := dp.DynamicProps(s.conf.Subject); ok {
+			msg.Subject = sub
+		}
+		for k, v := range s.conf.Headers {
+			if nv, ok := dp.DynamicProps(v); ok {
+				msg.Header.Set(k, nv)
+			}
+		}
+	}
+	if s.conf.JetStream {
+		if _, err := s.conn.js.PublishMsg(ctx, msg); err != nil {
+			return fmt.Errorf("publish to stream subject %s error: %v", msg.Subject, err)
+		}
+		return nil
+	}
+	if err := s.conn.nc.PublishMsg(msg); err != nil {
+		return fmt.Errorf("publish to subject %s error: %v", msg.Subject, err)
+	}
+	return nil
+}
+
+func (s *Sink) Ping(ctx api.StreamContext, props map[string]any) error {
+	return ping(ctx, props)
+}
+
+func (s *Sink) Close(ctx api.StreamContext) error {
+	if s.conn != nil && s.conn.nc != nil {
+		if err := s.conn.nc.Flush(); err != nil {
+			ctx.GetLogger().Warnf("flush nats sink error: %v", err)
+		}
+	}
+	return connection.DetachConnection(ctx, s.conId)
+}
+
+func GetSink() api.Sink {
+	return &Sink{}
+}
+
+var (
+	_ api.BytesCollector = &Sink{}
+	_ util.PingableConn  = &Sink{}
+)
@@ -0,0 +1,143 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build nats
+
+package nats
+
+import (
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/nats-io/nats.go"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/util"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+	"github.com/lf-edge/ekuiper/v2/pkg/syncx"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+type sourceConf struct {
+	// Subject may contain the wildcards * and >
+	Subject string `json:"datasource"`
+	Queue   string `json:"queue"`
+}
+
+// Source subscribes to a core nats subject. Messages are delivered at most once. If a queue group is set,
+// the messages are load balanced among the subscribers of the same group.
+type Source struct {
+	conf  *sourceConf
+	props map[string]any
+	conId string
+	conn  *Connection
+	// mu protects sub which is set when subscribing and read when closing
+	mu  syncx.Mutex
+	sub *nats.Subscription
+}
+
+func (s *Source) Provision(_ api.StreamContext, props map[string]any) error {
+	if _, err := newConnectionConf(props); err != nil {
+		return err
+	}
+	c := &sourceConf{}
+	if err := cast.MapToStruct(props, c); err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	if c.Subject == "" {
+		return fmt.Errorf("subject is required")
+	}
+	s.conf = c
+	s.props = props
+	return nil
+}
+
+func (s *Source) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	conn, conId, err := connect(ctx, fmt.Sprintf("%s-%s-nats-source", ctx.GetRuleId(), ctx.GetOpId()), s.props, sch)
+	s.conId = conId
+	s.conn = conn
+	return err
+}
+
+func (s *Source) Subscribe(ctx api.StreamContext, ingest api.BytesIngest, _ api.ErrorIngest) error {
+	handler := func(msg *nats.Msg) {
+		ingest(ctx, msg.Data, msgMeta(msg.Subject, msg.Header), timex.GetNow())
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var err error
+	if s.conf.Queue != "" {
+		s.sub, err = s.conn.nc.QueueSubscribe(s.conf.Subject, s.conf.Queue, handler)
+	} else {
+		s.sub, err = s.conn.nc.Subscribe(s.conf.Subject, handler)
+	}
+	if err != nil {
+		return fmt.Errorf("subscribe to subject %s error: %v", s.conf.Subject, err)
+	}
+	return nil
+}
+
+func (s *Source) Ping(ctx api.StreamContext, props map[string]any) error {
+	return ping(ctx, props)
+}
+
+func (s *Source) Close(ctx api.StreamContext) error {
+	s.mu.Lock()
+	if s.sub != nil {
+		if err := s.sub.Unsubscribe(); err != nil {
+			ctx.GetLogger().Warnf("unsubscribe subject %s error: %v", s.conf.Subject, err)
+		}
+		s.sub = nil
+	}
+	s.mu.Unlock()
+	return connection.DetachConnection(ctx, s.conId)
+}
+
+func connect(ctx api.StreamContext, refId string, props map[string]any, sch api.StatusChangeHandler) (*Connection, string, error) {
+	cw, err := connection.FetchConnection(ctx, refId, "nats", props, sch)
+	if err != nil {
+		return nil, "", err
+	}
+	conn, err := cw.Wait(ctx)
+	if conn == nil {
+		return nil, cw.ID, fmt.Errorf("nats client not ready: %v", err)
+	}
+	return conn.(*Connection), cw.ID, err
+}
+
+// msgMeta returns the subject and the headers as the metadata. The header with a single value is flattened.
+func msgMeta(subject string, header nats.Header) map[string]any {
+	meta := map[string]any{"subject": subject}
+	if len(header) > 0 {
+		h := make(map[string]any, len(header))
+		for k, v := range header {
+			if len(v) == 1 {
+				h[k] = v[0]
+			} else {
+				h[k] = v
+			}
+		}
+		meta["headers"] = h
+	}
+	return meta
+}
+
+func GetSource() api.Source {
+	return &Source{}
+}
+
+var (
+	_ api.BytesSource   = &Source{}
+	_ util.PingableConn = &Source{}
+)
@@ -0,0 +1,219 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build opcua
+
+package opcua
+
+import (
+	"context"
+	"crypto/rsa"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gopcua/opcua"
+	"github.com/gopcua/opcua/ua"
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cert"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
+)
+
+type connectionConf struct {
+	Endpoint       string            `json:"endpoint"`
+	SecurityPolicy string            `json:"securityPolicy"`
+	SecurityMode   string            `json:"securityMode"`
+	Username       string            `json:"username"`
+	Password       string            `json:"password"`
+	RequestTimeout cast.DurationConf `json:"requestTimeout"`
+}
+
+func newConnectionConf(props map[string]any) (*connectionConf, error) {
+	c := &connectionConf{
+		SecurityPolicy: "None",
+		SecurityMode:   "None",
+		RequestTimeout: cast.DurationConf(10 * time.Second),
+	}
+	if err := cast.MapToStruct(props, c); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(c.Endpoint) == "" {
+		return nil, fmt.Errorf("endpoint can not be empty")
+	}
+	if ua.MessageSecurityModeFromString(c.SecurityMode) == ua.MessageSecurityModeInvalid {
+		return nil, fmt.Errorf("invalid securityMode %s, expect None, Sign or SignAndEncrypt", c.SecurityMode)
+	}
+	if c.isSecure() == strings.EqualFold(c.SecurityMode, "None") {
+		return nil, fmt.Errorf("securityPolicy %s does not match securityMode %s", c.SecurityPolicy, c.SecurityMode)
+	}
+	return c, nil
+}
+
+func (c *connectionConf) isSecure() bool {
+	return c.SecurityPolicy != "" && !strings.EqualFold(c.SecurityPolicy, "None")
+}
+
+func (c *connectionConf) authType() ua.UserTokenType {
+	if c.Username != "" {
+		return ua.UserTokenTypeUserName
+	}
+	return ua.UserTokenTypeAnonymous
+}
+
+// options builds the client options. The client certificate and private key are loaded by the tls properties
+// like certificationPath and privateKeyPath which are required when the security policy is not None.
+func (c *connectionConf) options(ctx api.StreamContext, props map[string]any) ([]opcua.Option, error) {
+	opts := []opcua.Option{
+		opcua.SecurityPolicy(c.SecurityPolicy),
+		opcua.SecurityModeString(c.SecurityMode),
+		opcua.RequestTimeout(time.Duration(c.RequestTimeout)),
+		opcua.AutoReconnect(true),
+	}
+	if c.authType() == ua.UserTokenTypeUserName {
+		opts = append(opts, opcua.AuthUsername(c.Username, c.Password))
+	} else {
+		opts = append(opts, opcua.AuthAnonymous())
+	}
+	if !c.isSecure() {
+		return opts, nil
+	}
+	tlsConfig, err := cert.GenTLSConfig(ctx, props)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig == nil || len(tlsConfig.Certificates) == 0 {
+		return nil, fmt.Errorf("certificate and private key are required for security policy %s", c.SecurityPolicy)
+	}
+	crt := tlsConfig.Certificates[0]
+	pk, ok := crt.PrivateKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("the private key must be a RSA key")
+	}
+	opts = append(opts, opcua.Certificate(crt.Certificate[0]), opcua.PrivateKey(pk))
+	// Get the server certificate from the matched endpoint
+	endpoints, err := opcua.GetEndpoints(ctx, c.Endpoint)
+	if err != nil {
+		return nil, errorx.NewIOErr(fmt.Sprintf("found error when getting endpoints of %s: %s", c.Endpoint, err))
+	}
+	ep, err := opcua.SelectEndpoint(endpoints, c.SecurityPolicy, ua.MessageSecurityModeFromString(c.SecurityMode))
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, opcua.SecurityFromEndpoint(ep, c.authType()))
+	return opts, nil
+}
+
+// Connection is the OPC UA client connection shared by the sources, sinks and lookup sources
+type Connection struct {
+	id     string
+	conf   *connectionConf
+	props  map[string]any
+	client *opcua.Client
+}
+
+func init() {
+	modules.RegisterConnection("opcua", CreateConnection)
+}
+
+func CreateConnection(_ api.StreamContext) modules.Connection {
+	return &Connection{}
+}
+
+func (c *Connection) Provision(_ api.StreamContext, conId string, props map[string]any) error {
+	cc, err := newConnectionConf(props)
+	if err != nil {
+		return err
+	}
+	c.id = conId
+	c.conf = cc
+	c.props = props
+	return nil
+}
+
+func (c *Connection) Dial(ctx api.StreamContext) error {
+	opts, err := c.conf.options(ctx, c.props)
+	if err != nil {
+		return err
+	}
+	cli, err := opcua.NewClient(c.conf.Endpoint, opts...)
+	if err != nil {
+		return err
+	}
+	if err := cli.Connect(ctx); err != nil {
+		return errorx.NewIOErr(fmt.Sprintf("found error when connecting to %s: %s", c.conf.Endpoint, err))
+	}
+	c.client = cli
+	ctx.GetLogger().Infof("new opcua client created for %s", c.conf.Endpoint)
+	return nil
+}
+
+func (c *Connection) GetId(_ api.StreamContext) string {
+	return c.id
+}
+
+func (c *Connection) Ping(ctx api.StreamContext) error {
+	if c.client == nil {
+		return fmt.Errorf("opcua connection is not dialed")
+	}
+	if c.client.State() != opcua.Connected {
+		return errorx.NewIOErr(fmt.Sprintf("opcua client of %s is %v", c.conf.Endpoint, c.client.State()))
+	}
+	return nil
+}
+
+func (c *Connection) Close(ctx api.StreamContext) error {
+	if c.client == nil {
+		return nil
+	}
+	return c.client.Close(ctx)
+}
+
+// Read reads the attributes of the nodes and returns the data values in the same order
+func (c *Connection) Read(ctx context.Context, ids []*ua.ReadValueID) ([]*ua.DataValue, error) {
+	resp, err := c.client.Read(ctx, &ua.ReadRequest{
+		NodesToRead:        ids,
+		TimestampsToReturn: ua.TimestampsToReturnBoth,
+	})
+	if err != nil {
+		return nil, errorx.NewIOErr(fmt.Sprintf("read opcua nodes error: %s", err))
+	}
+	if len(resp.Results) != len(ids) {
+		return nil, fmt.Errorf("read opcua nodes error: expect %d results but got %d", len(ids), len(resp.Results))
+	}
+	return resp.Results, nil
+}
+
+// Write writes the values and returns the error of the first failed node
+func (c *Connection) Write(ctx context.Context, values []*ua.WriteValue) error {
+	resp, err := c.client.Write(ctx, &ua.WriteRequest{NodesToWrite: values})
+	if err != nil {
+		return errorx.NewIOErr(fmt.Sprintf("write opcua nodes error: %s", err))
+	}
+	for i, status := range resp.Results {
+		if status != ua.StatusOK {
+			return fmt.Errorf("write opcua node %s error: %s", values[i].NodeID, status)
+		}
+	}
+	return nil
+}
+
+// Subscribe creates a subscription with the publishing interval
+func (c *Connection) Subscribe(ctx context.Context, interval time.Duration, ch chan *opcua.PublishNotificationData) (*opcua.Subscription, error) {
+	return c.client.Subscribe(ctx, &opcua.SubscriptionParameters{Interval: interval}, ch)
+}
+
+var _ modules.Connection = &Connection{}
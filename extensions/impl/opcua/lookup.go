@@ -0,0 +1,102 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build opcua
+
+package opcua
+
+import (
+	"fmt"
+
+	"github.com/gopcua/opcua/ua"
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/util"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+type lookupConf struct {
+	// Attributes are the node attributes to read, the node id is always returned
+	Attributes []string `json:"attributes"`
+}
+
+// LookupSource reads the attributes of the nodes. The lookup key must be nodeId whose values are the node ids.
+// Each node is a row with the attribute names as the columns.
+type LookupSource struct {
+	baseSource
+	attributes []string
+}
+
+func (s *LookupSource) Provision(_ api.StreamContext, props map[string]any) error {
+	c := &lookupConf{}
+	if err := cast.MapToStruct(props, c); err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	// The default is set after decoding, otherwise the decoded list only overwrites the head of the default list
+	if len(c.Attributes) == 0 {
+		c.Attributes = []string{"value", "displayName", "dataType"}
+	}
+	for _, a := range c.Attributes {
+		if _, ok := attributes[a]; !ok {
+			return fmt.Errorf("unknown attribute %s", a)
+		}
+	}
+	s.attributes = c.Attributes
+	s.props = props
+	return nil
+}
+
+func (s *LookupSource) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	return s.connect(ctx, "lookup", sch)
+}
+
+func (s *LookupSource) Lookup(ctx api.StreamContext, _ []string, keys []string, values []any) ([]map[string]any, error) {
+	if len(keys) != 1 || keys[0] != "nodeId" {
+		return nil, fmt.Errorf("opcua lookup only supports nodeId as the key but got %v", keys)
+	}
+	nid, err := parseNodeID(fmt.Sprint(values[0]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid node id %v: %v", values[0], err)
+	}
+	ids := make([]*ua.ReadValueID, len(s.attributes))
+	for i, a := range s.attributes {
+		ids[i] = &ua.ReadValueID{NodeID: nid, AttributeID: attributes[a]}
+	}
+	dvs, err := s.conn.Read(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	row := map[string]any{"nodeId": nid.String()}
+	for i, dv := range dvs {
+		if dv.Status == ua.StatusBadNodeIDUnknown {
+			// Not found
+			return nil, nil
+		}
+		if dv.Status != ua.StatusOK {
+			ctx.GetLogger().Debugf("read attribute %s of opcua node %s error: %s", s.attributes[i], nid, dv.Status)
+			continue
+		}
+		row[s.attributes[i]] = fromVariant(dv.Value)
+	}
+	return []map[string]any{row}, nil
+}
+
+func GetLookupSource() api.Source {
+	return &LookupSource{}
+}
+
+var (
+	_ api.LookupSource  = &LookupSource{}
+	_ util.PingableConn = &LookupSource{}
+)
@@ -0,0 +1,178 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build opcua
+
+package opcua
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gopcua/opcua/ua"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+// node is a field mapped to an OPC UA node
+type node struct {
+	field string
+	id    *ua.NodeID
+}
+
+// parseNodes parses the field to node id map. The nodes are sorted by the field name for a stable order.
+func parseNodes(nodes map[string]string) ([]*node, error) {
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("nodes can not be empty")
+	}
+	result := make([]*node, 0, len(nodes))
+	for field, s := range nodes {
+		nid, err := parseNodeID(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid node id %s of field %s: %v", s, field, err)
+		}
+		result = append(result, &node{field: field, id: nid})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].field < result[j].field
+	})
+	return result, nil
+}
+
+// parseNodeID parses the node id which must have the identifier type like ns=2;s=Temperature or i=2258. The opcua
+// library takes the id without the identifier type as a string id, which hides typos like a missing ns.
+func parseNodeID(s string) (*ua.NodeID, error) {
+	idPart := s
+	if i := strings.LastIndex(s, ";"); i >= 0 {
+		idPart = s[i+1:]
+	}
+	if len(idPart) < 2 || idPart[1] != '=' || !strings.ContainsRune("isgb", rune(idPart[0])) {
+		return nil, fmt.Errorf("expect the identifier type i=, s=, g= or b=")
+	}
+	return ua.ParseNodeID(s)
+}
+
+// attributes are the node attributes which can be read by the lookup source
+var attributes = map[string]ua.AttributeID{
+	"nodeId":                  ua.AttributeIDNodeID,
+	"nodeClass":               ua.AttributeIDNodeClass,
+	"browseName":              ua.AttributeIDBrowseName,
+	"displayName":             ua.AttributeIDDisplayName,
+	"description":             ua.AttributeIDDescription,
+	"value":                   ua.AttributeIDValue,
+	"dataType":                ua.AttributeIDDataType,
+	"valueRank":               ua.AttributeIDValueRank,
+	"accessLevel":             ua.AttributeIDAccessLevel,
+	"minimumSamplingInterval": ua.AttributeIDMinimumSamplingInterval,
+}
+
+// fromVariant converts the variant value to the value type of the rule
+func fromVariant(v *ua.Variant) any {
+	if v == nil {
+		return nil
+	}
+	switch val := v.Value().(type) {
+	case *ua.LocalizedText:
+		return val.Text
+	case *ua.QualifiedName:
+		return val.Name
+	case *ua.NodeID:
+		return val.String()
+	case *ua.ExpandedNodeID:
+		return val.String()
+	case int8:
+		return int64(val)
+	case int16:
+		return int64(val)
+	case int32:
+		return int64(val)
+	case uint8:
+		return int64(val)
+	case uint16:
+		return int64(val)
+	case uint32:
+		return int64(val)
+	case float32:
+		return float64(val)
+	default:
+		return val
+	}
+}
+
+// builtinType returns the built-in type of the node by the data type attribute. The data type of a built-in type is
+// the node ns=0;i=<type id>. For the other data types such as enumerations and the abstract Number, the built-in type
+// of the current value is used.
+func builtinType(dataType any, value *ua.Variant) ua.TypeID {
+	var nid *ua.NodeID
+	switch dt := dataType.(type) {
+	case *ua.NodeID:
+		nid = dt
+	case *ua.ExpandedNodeID:
+		nid = dt.NodeID
+	}
+	if nid != nil && nid.Namespace() == 0 && nid.IntID() >= uint32(ua.TypeIDBoolean) && nid.IntID() <= uint32(ua.TypeIDDiagnosticInfo) {
+		return ua.TypeID(nid.IntID())
+	}
+	if value != nil {
+		return value.Type()
+	}
+	return ua.TypeIDNull
+}
+
+// toVariant converts the value to the variant of the node built-in type.
+// The server rejects the value whose type does not match the node, so the value must be cast to the exact type.
+func toVariant(typeID ua.TypeID, v any) (*ua.Variant, error) {
+	var (
+		r   any
+		err error
+	)
+	switch typeID {
+	case ua.TypeIDBoolean:
+		r, err = cast.ToBool(v, cast.CONVERT_SAMEKIND)
+	case ua.TypeIDSByte:
+		r, err = cast.ToInt8(v, cast.CONVERT_SAMEKIND)
+	case ua.TypeIDByte:
+		r, err = cast.ToUint8(v, cast.CONVERT_SAMEKIND)
+	case ua.TypeIDInt16:
+		r, err = cast.ToInt16(v, cast.CONVERT_SAMEKIND)
+	case ua.TypeIDUint16:
+		r, err = cast.ToUint16(v, cast.CONVERT_SAMEKIND)
+	case ua.TypeIDInt32:
+		r, err = cast.ToInt32(v, cast.CONVERT_SAMEKIND)
+	case ua.TypeIDUint32:
+		r, err = cast.ToUint32(v, cast.CONVERT_SAMEKIND)
+	case ua.TypeIDInt64:
+		r, err = cast.ToInt64(v, cast.CONVERT_SAMEKIND)
+	case ua.TypeIDUint64:
+		r, err = cast.ToUint64(v, cast.CONVERT_SAMEKIND)
+	case ua.TypeIDFloat:
+		r, err = cast.ToFloat32(v, cast.CONVERT_SAMEKIND)
+	case ua.TypeIDDouble:
+		r, err = cast.ToFloat64(v, cast.CONVERT_SAMEKIND)
+	case ua.TypeIDString:
+		r, err = cast.ToString(v, cast.CONVERT_SAMEKIND)
+	case ua.TypeIDDateTime:
+		var t time.Time
+		t, err = cast.InterfaceToTime(v, "")
+		r = t
+	default:
+		r = v
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ua.NewVariant(r)
+}
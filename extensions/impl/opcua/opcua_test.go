@@ -0,0 +1,186 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build opcua
+
+package opcua
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gopcua/opcua/id"
+	"github.com/gopcua/opcua/server"
+	"github.com/gopcua/opcua/ua"
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/model"
+)
+
+// startServer starts an in-process OPC UA server with a temperature and a status node
+func startServer(t *testing.T) (string, map[string]string) {
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	port := l.Addr().(*net.TCPAddr).Port
+	require.NoError(t, l.Close())
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "ekuiper test"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	crt, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	s := server.New(
+		server.EndPoint("localhost", port),
+		server.EnableSecurity("None", ua.MessageSecurityModeNone),
+		server.EnableAuthMode(ua.UserTokenTypeAnonymous),
+		server.PrivateKey(key),
+		server.Certificate(crt),
+	)
+	ns := server.NewNodeNameSpace(s, "ekuiper")
+	temperature := ns.AddNewVariableStringNode("temperature", 21.5)
+	status := ns.AddNewVariableStringNode("status", "running")
+	ns.Objects().AddRef(temperature, id.HasComponent, true)
+	ns.Objects().AddRef(status, id.HasComponent, true)
+	require.NoError(t, s.Start(context.Background()))
+	t.Cleanup(func() {
+		_ = s.Close()
+	})
+	return fmt.Sprintf("opc.tcp://localhost:%d", port), map[string]string{
+		"temperature": temperature.ID().String(),
+		"status":      status.ID().String(),
+	}
+}
+
+func TestProvisionErr(t *testing.T) {
+	ctx := mockContext.NewMockContext("1", "2")
+	tests := []struct {
+		name  string
+		props map[string]any
+		err   string
+	}{
+		{
+			name:  "no endpoint",
+			props: map[string]any{},
+			err:   "endpoint can not be empty",
+		},
+		{
+			name:  "invalid mode",
+			props: map[string]any{"endpoint": "opc.tcp://localhost:4840", "securityMode": "abc"},
+			err:   "invalid securityMode abc, expect None, Sign or SignAndEncrypt",
+		},
+		{
+			name:  "mismatch policy",
+			props: map[string]any{"endpoint": "opc.tcp://localhost:4840", "securityPolicy": "Basic256Sha256"},
+			err:   "securityPolicy Basic256Sha256 does not match securityMode None",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&Connection{}).Provision(ctx, "test", tt.props)
+			require.EqualError(t, err, tt.err)
+		})
+	}
+	require.EqualError(t, GetSource().Provision(ctx, map[string]any{}), "nodes can not be empty")
+	require.EqualError(t, GetSink().Provision(ctx, map[string]any{"nodes": map[string]any{"a": "abc"}}), "invalid node id abc of field a: expect the identifier type i=, s=, g= or b=")
+	require.EqualError(t, GetSink().Provision(ctx, map[string]any{"nodes": map[string]any{"a": "ns=x;s=a"}}), "invalid node id ns=x;s=a of field a: opcua: invalid namespace id: ns=x;s=a")
+	require.EqualError(t, GetPullSource().Provision(ctx, map[string]any{"nodes": map[string]any{"a": "ns=1;s=a"}}), "interval should be defined")
+	require.EqualError(t, GetLookupSource().Provision(ctx, map[string]any{"attributes": []any{"abc"}}), "unknown attribute abc")
+}
+
+func TestConnector(t *testing.T) {
+	require.NoError(t, connection.InitConnectionManager4Test())
+	endpoint, nodes := startServer(t)
+	ctx, cancel := mockContext.NewMockContext("rule1", "op1").WithCancel()
+	defer cancel()
+	sch := func(string, string) {}
+	nodeProps := map[string]any{
+		"temperature": nodes["temperature"],
+		"status":      nodes["status"],
+	}
+
+	// Subscribe the changes
+	src := GetSource()
+	require.NoError(t, src.Provision(ctx, map[string]any{"endpoint": endpoint, "nodes": nodeProps, "publishInterval": "100ms"}))
+	require.NoError(t, src.Connect(ctx, sch))
+	received := make(chan map[string]any, 10)
+	require.NoError(t, src.(api.TupleSource).Subscribe(ctx, func(_ api.StreamContext, data any, meta map[string]any, _ time.Time) {
+		require.Contains(t, meta, "sourceTimestamp")
+		received <- data.(map[string]any)
+	}, func(_ api.StreamContext, err error) {
+		t.Error(err)
+	}))
+	// The initial values are notified once monitored
+	require.Equal(t, map[string]any{"temperature": 21.5, "status": "running"}, waitData(t, received))
+
+	// Write by sink
+	sink := GetSink()
+	require.NoError(t, sink.Provision(ctx, map[string]any{"endpoint": endpoint, "nodes": map[string]any{"temperature": nodes["temperature"]}}))
+	require.NoError(t, sink.Connect(ctx, sch))
+	require.NoError(t, sink.(api.TupleCollector).Collect(ctx, model.NewDefaultSourceTuple(map[string]any{"temperature": int64(25), "other": 1}, nil, time.Now())))
+	require.Equal(t, map[string]any{"temperature": 25.0}, waitData(t, received))
+
+	// Pull
+	pull := GetPullSource()
+	require.NoError(t, pull.Provision(ctx, map[string]any{"endpoint": endpoint, "nodes": nodeProps, "interval": "1s"}))
+	require.NoError(t, pull.Connect(ctx, sch))
+	var pulled map[string]any
+	pull.(api.PullTupleSource).Pull(ctx, time.Now(), func(_ api.StreamContext, data any, _ map[string]any, _ time.Time) {
+		pulled = data.(map[string]any)
+	}, func(_ api.StreamContext, err error) {
+		t.Error(err)
+	})
+	require.Equal(t, map[string]any{"temperature": 25.0, "status": "running"}, pulled)
+
+	// Lookup the attributes
+	lookup := GetLookupSource()
+	require.NoError(t, lookup.Provision(ctx, map[string]any{"endpoint": endpoint, "attributes": []any{"value", "browseName"}}))
+	require.NoError(t, lookup.Connect(ctx, sch))
+	rows, err := lookup.(api.LookupSource).Lookup(ctx, nil, []string{"nodeId"}, []any{nodes["status"]})
+	require.NoError(t, err)
+	require.Equal(t, []map[string]any{{"nodeId": nodes["status"], "value": "running", "browseName": "status"}}, rows)
+	_, err = lookup.(api.LookupSource).Lookup(ctx, nil, []string{"id"}, []any{nodes["status"]})
+	require.EqualError(t, err, "opcua lookup only supports nodeId as the key but got [id]")
+
+	for _, c := range []api.Source{src, pull, lookup} {
+		require.NoError(t, c.Close(ctx))
+	}
+	require.NoError(t, sink.Close(ctx))
+}
+
+func waitData(t *testing.T, ch chan map[string]any) map[string]any {
+	select {
+	case d := <-ch:
+		return d
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout to receive data")
+		return nil
+	}
+}
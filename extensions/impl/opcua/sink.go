@@ -0,0 +1,187 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build opcua
+
+package opcua
+
+import (
+	"fmt"
+
+	"github.com/gopcua/opcua/ua"
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/util"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+)
+
+type sinkConf struct {
+	// Nodes maps the field names of the result to the node ids to write
+	Nodes map[string]string `json:"nodes"`
+}
+
+// Sink writes the fields of the result to the value attribute of the mapped nodes. The fields
+// not in the result are not written. The values are cast to the data type of the nodes.
+type Sink struct {
+	nodes []*node
+	// types are the built-in types of the nodes resolved when connected
+	types []ua.TypeID
+	props map[string]any
+	conId string
+	conn  *Connection
+}
+
+func (s *Sink) Provision(_ api.StreamContext, props map[string]any) error {
+	c := &sinkConf{}
+	if err := cast.MapToStruct(props, c); err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	nodes, err := parseNodes(c.Nodes)
+	if err != nil {
+		return err
+	}
+	s.nodes = nodes
+	s.props = props
+	return nil
+}
+
+func (s *Sink) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	id := fmt.Sprintf("%s-%s-opcua-sink", ctx.GetRuleId(), ctx.GetOpId())
+	cw, err := connection.FetchConnection(ctx, id, "opcua", s.props, sch)
+	if err != nil {
+		return err
+	}
+	s.conId = cw.ID
+	conn, err := cw.Wait(ctx)
+	if conn == nil {
+		return fmt.Errorf("opcua client not ready: %v", err)
+	}
+	s.conn = conn.(*Connection)
+	return s.readDataTypes(ctx)
+}
+
+// readDataTypes reads the data type and the value of each node to resolve the built-in type to write
+func (s *Sink) readDataTypes(ctx api.StreamContext) error {
+	ids := make([]*ua.ReadValueID, 0, len(s.nodes)*2)
+	for _, n := range s.nodes {
+		ids = append(ids,
+			&ua.ReadValueID{NodeID: n.id, AttributeID: ua.AttributeIDDataType},
+			&ua.ReadValueID{NodeID: n.id, AttributeID: ua.AttributeIDValue},
+		)
+	}
+	dvs, err := s.conn.Read(ctx, ids)
+	if err != nil {
+		return err
+	}
+	s.types = make([]ua.TypeID, len(s.nodes))
+	for i, n := range s.nodes {
+		dt, val := dvs[2*i], dvs[2*i+1]
+		if dt.Status != ua.StatusOK {
+			return fmt.Errorf("read data type of opcua node %s error: %s", n.id, dt.Status)
+		}
+		var dataType any
+		if dt.Value != nil {
+			dataType = dt.Value.Value()
+		}
+		var value *ua.Variant
+		if val.Status == ua.StatusOK {
+			value = val.Value
+		}
+		s.types[i] = builtinType(dataType, value)
+	}
+	return nil
+}
+
+func (s *Sink) Collect(ctx api.StreamContext, item api.MessageTuple) error {
+	values, err := s.toWriteValues(item.ToMap())
+	if err != nil {
+		return err
+	}
+	return s.write(ctx, values)
+}
+
+func (s *Sink) CollectList(ctx api.StreamContext, items api.MessageTupleList) error {
+	var (
+		values []*ua.WriteValue
+		err    error
+	)
+	items.RangeOfTuples(func(_ int, tuple api.MessageTuple) bool {
+		var vs []*ua.WriteValue
+		vs, err = s.toWriteValues(tuple.ToMap())
+		if err != nil {
+			return false
+		}
+		values = append(values, vs...)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	return s.write(ctx, values)
+}
+
+func (s *Sink) toWriteValues(data map[string]any) ([]*ua.WriteValue, error) {
+	values := make([]*ua.WriteValue, 0, len(s.nodes))
+	for i, n := range s.nodes {
+		v, ok := data[n.field]
+		if !ok || v == nil {
+			continue
+		}
+		variant, err := toVariant(s.types[i], v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of field %s for opcua node %s: %v", n.field, n.id, err)
+		}
+		values = append(values, &ua.WriteValue{
+			NodeID:      n.id,
+			AttributeID: ua.AttributeIDValue,
+			Value: &ua.DataValue{
+				EncodingMask: ua.DataValueValue,
+				Value:        variant,
+			},
+		})
+	}
+	return values, nil
+}
+
+func (s *Sink) write(ctx api.StreamContext, values []*ua.WriteValue) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return s.conn.Write(ctx, values)
+}
+
+func (s *Sink) Ping(ctx api.StreamContext, props map[string]any) error {
+	conn := &Connection{}
+	if err := conn.Provision(ctx, "test", props); err != nil {
+		return err
+	}
+	if err := conn.Dial(ctx); err != nil {
+		return err
+	}
+	return conn.Close(ctx)
+}
+
+func (s *Sink) Close(ctx api.StreamContext) error {
+	return connection.DetachConnection(ctx, s.conId)
+}
+
+func GetSink() api.Sink {
+	return &Sink{}
+}
+
+var (
+	_ api.TupleCollector = &Sink{}
+	_ util.PingableConn  = &Sink{}
+)
@@ -0,0 +1,238 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build opcua
+
+package opcua
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gopcua/opcua"
+	"github.com/gopcua/opcua/ua"
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/util"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+type sourceConf struct {
+	// Nodes maps the field names to the node ids like ns=2;s=Temperature
+	Nodes map[string]string `json:"nodes"`
+	// PublishInterval is the publishing interval of the subscription
+	PublishInterval cast.DurationConf `json:"publishInterval"`
+	Interval        cast.DurationConf `json:"interval"`
+}
+
+// baseSource is the common part of the sources which read the values of the nodes
+type baseSource struct {
+	conf  *sourceConf
+	nodes []*node
+	props map[string]any
+	conId string
+	conn  *Connection
+}
+
+func (s *baseSource) provision(props map[string]any) error {
+	c := &sourceConf{PublishInterval: cast.DurationConf(time.Second)}
+	if err := cast.MapToStruct(props, c); err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	nodes, err := parseNodes(c.Nodes)
+	if err != nil {
+		return err
+	}
+	s.conf = c
+	s.nodes = nodes
+	s.props = props
+	return nil
+}
+
+func (s *baseSource) connect(ctx api.StreamContext, role string, sch api.StatusChangeHandler) error {
+	id := fmt.Sprintf("%s-%s-opcua-%s", ctx.GetRuleId(), ctx.GetOpId(), role)
+	cw, err := connection.FetchConnection(ctx, id, "opcua", s.props, sch)
+	if err != nil {
+		return err
+	}
+	s.conId = cw.ID
+	conn, err := cw.Wait(ctx)
+	if conn == nil {
+		return fmt.Errorf("opcua client not ready: %v", err)
+	}
+	s.conn = conn.(*Connection)
+	return err
+}
+
+func (s *baseSource) Ping(ctx api.StreamContext, props map[string]any) error {
+	conn := &Connection{}
+	if err := conn.Provision(ctx, "test", props); err != nil {
+		return err
+	}
+	if err := conn.Dial(ctx); err != nil {
+		return err
+	}
+	return conn.Close(ctx)
+}
+
+func (s *baseSource) Close(ctx api.StreamContext) error {
+	return connection.DetachConnection(ctx, s.conId)
+}
+
+// Source subscribes the monitored items of the nodes. Each data change notification is ingested as
+// a tuple with the changed fields. The source timestamps of the fields are in the meta.
+type Source struct {
+	baseSource
+	sub *opcua.Subscription
+}
+
+func (s *Source) Provision(_ api.StreamContext, props map[string]any) error {
+	return s.provision(props)
+}
+
+func (s *Source) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	return s.connect(ctx, "source", sch)
+}
+
+func (s *Source) Subscribe(ctx api.StreamContext, ingest api.TupleIngest, ingestError api.ErrorIngest) error {
+	ch := make(chan *opcua.PublishNotificationData, len(s.nodes))
+	sub, err := s.conn.Subscribe(ctx, time.Duration(s.conf.PublishInterval), ch)
+	if err != nil {
+		return fmt.Errorf("create opcua subscription error: %v", err)
+	}
+	s.sub = sub
+	reqs := make([]*ua.MonitoredItemCreateRequest, len(s.nodes))
+	for i, n := range s.nodes {
+		// The client handle is the index of the node to find the field of the notification
+		reqs[i] = opcua.NewMonitoredItemCreateRequestWithDefaults(n.id, ua.AttributeIDValue, uint32(i))
+	}
+	resp, err := sub.Monitor(ctx, ua.TimestampsToReturnBoth, reqs...)
+	if err != nil {
+		return fmt.Errorf("monitor opcua nodes error: %v", err)
+	}
+	for i, r := range resp.Results {
+		if r.StatusCode != ua.StatusOK {
+			return fmt.Errorf("monitor opcua node %s error: %s", s.nodes[i].id, r.StatusCode)
+		}
+	}
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case res := <-ch:
+				if res.Error != nil {
+					ingestError(ctx, res.Error)
+					continue
+				}
+				if dc, ok := res.Value.(*ua.DataChangeNotification); ok {
+					s.ingestDataChange(ctx, dc, ingest, ingestError)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (s *Source) ingestDataChange(ctx api.StreamContext, dc *ua.DataChangeNotification, ingest api.TupleIngest, ingestError api.ErrorIngest) {
+	data := make(map[string]any, len(dc.MonitoredItems))
+	timestamps := make(map[string]any, len(dc.MonitoredItems))
+	for _, item := range dc.MonitoredItems {
+		if int(item.ClientHandle) >= len(s.nodes) || item.Value == nil {
+			continue
+		}
+		n := s.nodes[item.ClientHandle]
+		if item.Value.Status != ua.StatusOK {
+			ingestError(ctx, fmt.Errorf("opcua node %s has bad status: %s", n.id, item.Value.Status))
+			continue
+		}
+		data[n.field] = fromVariant(item.Value.Value)
+		timestamps[n.field] = item.Value.SourceTimestamp.UnixMilli()
+	}
+	if len(data) == 0 {
+		return
+	}
+	ingest(ctx, data, map[string]any{"sourceTimestamp": timestamps}, timex.GetNow())
+}
+
+func (s *Source) Close(ctx api.StreamContext) error {
+	if s.sub != nil {
+		if err := s.sub.Cancel(ctx); err != nil {
+			ctx.GetLogger().Warnf("cancel opcua subscription error: %v", err)
+		}
+	}
+	return s.baseSource.Close(ctx)
+}
+
+// PullSource reads the values of all the nodes in an interval and ingests them as one tuple
+type PullSource struct {
+	baseSource
+}
+
+func (s *PullSource) Provision(_ api.StreamContext, props map[string]any) error {
+	if err := s.provision(props); err != nil {
+		return err
+	}
+	if time.Duration(s.conf.Interval) <= 0 {
+		return fmt.Errorf("interval should be defined")
+	}
+	return nil
+}
+
+func (s *PullSource) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	return s.connect(ctx, "pull", sch)
+}
+
+func (s *PullSource) Pull(ctx api.StreamContext, trigger time.Time, ingest api.TupleIngest, ingestError api.ErrorIngest) {
+	ids := make([]*ua.ReadValueID, len(s.nodes))
+	for i, n := range s.nodes {
+		ids[i] = &ua.ReadValueID{NodeID: n.id, AttributeID: ua.AttributeIDValue}
+	}
+	values, err := s.conn.Read(ctx, ids)
+	if err != nil {
+		ingestError(ctx, err)
+		return
+	}
+	data := make(map[string]any, len(s.nodes))
+	timestamps := make(map[string]any, len(s.nodes))
+	for i, dv := range values {
+		n := s.nodes[i]
+		if dv.Status != ua.StatusOK {
+			ingestError(ctx, fmt.Errorf("opcua node %s has bad status: %s", n.id, dv.Status))
+			continue
+		}
+		data[n.field] = fromVariant(dv.Value)
+		timestamps[n.field] = dv.SourceTimestamp.UnixMilli()
+	}
+	if len(data) == 0 {
+		return
+	}
+	ingest(ctx, data, map[string]any{"sourceTimestamp": timestamps}, trigger)
+}
+
+func GetSource() api.Source {
+	return &Source{}
+}
+
+func GetPullSource() api.Source {
+	return &PullSource{}
+}
+
+var (
+	_ api.TupleSource     = &Source{}
+	_ api.PullTupleSource = &PullSource{}
+	_ util.PingableConn   = &Source{}
+)
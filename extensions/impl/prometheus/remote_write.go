@@ -0,0 +1,92 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"math"
+	"sort"
+
+	"github.com/klauspost/compress/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The field numbers of the remote write protocol messages in prompb/types.proto and prompb/remote.proto
+const (
+	fieldTimeseries   = 1
+	fieldLabels       = 1
+	fieldSamples      = 2
+	fieldLabelName    = 1
+	fieldLabelValue   = 2
+	fieldSampleValue  = 1
+	fieldSampleTsMsec = 2
+)
+
+// encodeWriteRequest groups the samples by series and encodes them as the snappy compressed WriteRequest.
+// The samples of each series are sorted by time as required by the receivers.
+func encodeWriteRequest(samples []*sample) []byte {
+	var (
+		keys   []string
+		series = make(map[string][]*sample)
+	)
+	for _, s := range samples {
+		k := s.key()
+		if _, ok := series[k]; !ok {
+			keys = append(keys, k)
+		}
+		series[k] = append(series[k], s)
+	}
+	var req []byte
+	for _, k := range keys {
+		ss := series[k]
+		sort.SliceStable(ss, func(i, j int) bool {
+			return ss[i].ts < ss[j].ts
+		})
+		req = protowire.AppendTag(req, fieldTimeseries, protowire.BytesType)
+		req = protowire.AppendBytes(req, encodeTimeSeries(ss))
+	}
+	return snappy.Encode(nil, req)
+}
+
+func encodeTimeSeries(ss []*sample) []byte {
+	// The metric name is the __name__ label. All the labels must be sorted by name.
+	labels := append([]label{{name: "__name__", value: ss[0].name}}, ss[0].labels...)
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].name < labels[j].name
+	})
+	var b []byte
+	for _, l := range labels {
+		b = appendLabel(b, l.name, l.value)
+	}
+	for _, s := range ss {
+		var sb []byte
+		sb = protowire.AppendTag(sb, fieldSampleValue, protowire.Fixed64Type)
+		sb = protowire.AppendFixed64(sb, math.Float64bits(s.value))
+		sb = protowire.AppendTag(sb, fieldSampleTsMsec, protowire.VarintType)
+		sb = protowire.AppendVarint(sb, uint64(s.ts))
+		b = protowire.AppendTag(b, fieldSamples, protowire.BytesType)
+		b = protowire.AppendBytes(b, sb)
+	}
+	return b
+}
+
+func appendLabel(b []byte, name, value string) []byte {
+	var lb []byte
+	lb = protowire.AppendTag(lb, fieldLabelName, protowire.BytesType)
+	lb = protowire.AppendString(lb, name)
+	lb = protowire.AppendTag(lb, fieldLabelValue, protowire.BytesType)
+	lb = protowire.AppendString(lb, value)
+	b = protowire.AppendTag(b, fieldLabels, protowire.BytesType)
+	return protowire.AppendBytes(b, lb)
+}
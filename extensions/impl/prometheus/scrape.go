@@ -0,0 +1,88 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+type entry struct {
+	*sample
+	updated time.Time
+}
+
+// store keeps the latest sample of each series to be scraped. The series which is not updated
+// in the expiration is removed.
+type store struct {
+	sync.Mutex
+	series     map[string]*entry
+	expiration time.Duration
+	withTs     bool
+}
+
+func newStore(expiration time.Duration, withTs bool) *store {
+	return &store{
+		series:     make(map[string]*entry),
+		expiration: expiration,
+		withTs:     withTs,
+	}
+}
+
+func (s *store) update(samples []*sample) {
+	now := timex.GetNow()
+	s.Lock()
+	defer s.Unlock()
+	for _, sp := range samples {
+		s.series[sp.key()] = &entry{sample: sp, updated: now}
+	}
+}
+
+// Describe sends nothing so that the store is an unchecked collector whose series are dynamic
+func (s *store) Describe(chan<- *prometheus.Desc) {}
+
+func (s *store) Collect(ch chan<- prometheus.Metric) {
+	now := timex.GetNow()
+	s.Lock()
+	defer s.Unlock()
+	for k, e := range s.series {
+		if s.expiration > 0 && now.Sub(e.updated) > s.expiration {
+			delete(s.series, k)
+			continue
+		}
+		names := make([]string, len(e.labels))
+		values := make([]string, len(e.labels))
+		for i, l := range e.labels {
+			names[i] = l.name
+			values[i] = l.value
+		}
+		desc := prometheus.NewDesc(e.name, "The result of eKuiper rule.", names, nil)
+		m, err := prometheus.NewConstMetric(desc, prometheus.GaugeValue, e.value, values...)
+		if err != nil {
+			ch <- prometheus.NewInvalidMetric(desc, err)
+			continue
+		}
+		if s.withTs {
+			m = prometheus.NewMetricWithTimestamp(time.UnixMilli(e.ts), m)
+		}
+		ch <- m
+	}
+}
+
+var _ prometheus.Collector = &store{}
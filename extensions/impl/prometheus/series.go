@@ -0,0 +1,109 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/lf-edge/ekuiper/v2/extensions/impl/tspoint"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+var (
+	invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+	labelNameRegex   = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+type label struct {
+	name  string
+	value string
+}
+
+// sample is a value of a series. The labels are sorted by name and the timestamp is in milliseconds.
+type sample struct {
+	name   string
+	labels []label
+	value  float64
+	ts     int64
+}
+
+// key identifies the series of the sample
+func (s *sample) key() string {
+	var b strings.Builder
+	b.WriteString(s.name)
+	for _, l := range s.labels {
+		b.WriteByte(0xff)
+		b.WriteString(l.name)
+		b.WriteByte(0xff)
+		b.WriteString(l.value)
+	}
+	return b.String()
+}
+
+// metricName converts the field name to a valid metric name with the optional prefix
+func metricName(prefix, field string) string {
+	name := field
+	if prefix != "" {
+		name = prefix + "_" + field
+	}
+	name = invalidNameChars.ReplaceAllString(name, "_")
+	if name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
+
+// toSamples converts each numeric field of the points to a sample. The tags of the point are the labels.
+func toSamples(pts []*tspoint.RawPoint, prefix, tsField string) []*sample {
+	result := make([]*sample, 0, len(pts))
+	for _, pt := range pts {
+		labels := make([]label, 0, len(pt.Tags))
+		for k, v := range pt.Tags {
+			labels = append(labels, label{name: k, value: v})
+		}
+		sort.Slice(labels, func(i, j int) bool {
+			return labels[i].name < labels[j].name
+		})
+		ts := pt.Tt.UnixMilli()
+		for f, v := range pt.Fields {
+			if f == tsField {
+				continue
+			}
+			fv, ok := toFloat(v)
+			if !ok {
+				continue
+			}
+			result = append(result, &sample{name: metricName(prefix, f), labels: labels, value: fv, ts: ts})
+		}
+	}
+	return result
+}
+
+func toFloat(v any) (float64, bool) {
+	switch vt := v.(type) {
+	case bool:
+		if vt {
+			return 1, true
+		}
+		return 0, true
+	case string, nil:
+		return 0, false
+	default:
+		f, err := cast.ToFloat64(vt, cast.CONVERT_SAMEKIND)
+		return f, err == nil
+	}
+}
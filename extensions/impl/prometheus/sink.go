@@ -0,0 +1,207 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/lf-edge/ekuiper/v2/extensions/impl/tspoint"
+	"github.com/lf-edge/ekuiper/v2/internal/io/http/httpserver"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cert"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+)
+
+const (
+	modeRemoteWrite = "remoteWrite"
+	modeScrape      = "scrape"
+)
+
+type c struct {
+	Mode       string            `json:"mode"`
+	MetricName string            `json:"metricName"`
+	Url        string            `json:"url"`
+	Headers    map[string]string `json:"headers"`
+	Username   string            `json:"username"`
+	Password   string            `json:"password"`
+	Timeout    cast.DurationConf `json:"timeout"`
+	Endpoint   string            `json:"endpoint"`
+	Expiration cast.DurationConf `json:"expiration"`
+	tspoint.WriteOptions
+}
+
+// prometheusSink maps each numeric field to a Prometheus series whose labels are the tags. It either sends
+// the samples by the remote write protocol or keeps the latest samples to be scraped from the http endpoint.
+type prometheusSink struct {
+	conf  c
+	cli   *http.Client
+	store *store
+}
+
+func (p *prometheusSink) Provision(ctx api.StreamContext, props map[string]any) error {
+	p.conf = c{
+		Mode:       modeRemoteWrite,
+		Timeout:    cast.DurationConf(5 * time.Second),
+		Endpoint:   "/metrics/" + ctx.GetRuleId(),
+		Expiration: cast.DurationConf(5 * time.Minute),
+		WriteOptions: tspoint.WriteOptions{
+			PrecisionStr: "ms",
+		},
+	}
+	err := cast.MapToStruct(props, &p.conf)
+	if err != nil {
+		return fmt.Errorf("error configuring prometheus sink: %s", err)
+	}
+	err = cast.MapToStruct(props, &p.conf.WriteOptions)
+	if err != nil {
+		return fmt.Errorf("error configuring prometheus sink: %s", err)
+	}
+	if err = p.conf.WriteOptions.Validate(); err != nil {
+		return err
+	}
+	if err = p.conf.WriteOptions.ValidateTagTemplates(ctx); err != nil {
+		return err
+	}
+	for k := range p.conf.Tags {
+		if !labelNameRegex.MatchString(k) || strings.HasPrefix(k, "__") {
+			return fmt.Errorf("invalid label name %s", k)
+		}
+	}
+	switch p.conf.Mode {
+	case modeRemoteWrite:
+		if !strings.HasPrefix(p.conf.Url, "http://") && !strings.HasPrefix(p.conf.Url, "https://") {
+			return fmt.Errorf("invalid url %s, should start with http:// or https://", p.conf.Url)
+		}
+		if p.conf.Timeout <= 0 {
+			return fmt.Errorf("timeout should be positive")
+		}
+		tlsConf, err := cert.GenTLSConfig(ctx, props)
+		if err != nil {
+			return fmt.Errorf("error configuring tls: %s", err)
+		}
+		tr := http.DefaultTransport.(*http.Transport).Clone()
+		tr.TLSClientConfig = tlsConf
+		p.cli = &http.Client{Transport: tr, Timeout: time.Duration(p.conf.Timeout)}
+	case modeScrape:
+		if !strings.HasPrefix(p.conf.Endpoint, "/") {
+			return fmt.Errorf("endpoint must start with /")
+		}
+		if p.conf.Expiration < 0 {
+			return fmt.Errorf("expiration should not be negative")
+		}
+	default:
+		return fmt.Errorf("mode %s is not supported, must be %s or %s", p.conf.Mode, modeRemoteWrite, modeScrape)
+	}
+	return nil
+}
+
+func (p *prometheusSink) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	if p.conf.Mode == modeScrape {
+		p.store = newStore(time.Duration(p.conf.Expiration), p.conf.TsFieldName != "")
+		reg := prometheus.NewRegistry()
+		reg.MustRegister(p.store)
+		h := promhttp.HandlerFor(reg, promhttp.HandlerOpts{ErrorHandling: promhttp.ContinueOnError})
+		if err := httpserver.RegisterHandler(p.conf.Endpoint, http.MethodGet, h.ServeHTTP); err != nil {
+			sch(api.ConnectionDisconnected, err.Error())
+			return err
+		}
+		ctx.GetLogger().Infof("prometheus sink serves the metrics at %s", p.conf.Endpoint)
+	}
+	sch(api.ConnectionConnected, "")
+	return nil
+}
+
+func (p *prometheusSink) Collect(ctx api.StreamContext, item api.MessageTuple) error {
+	return p.collect(ctx, item.ToMap())
+}
+
+func (p *prometheusSink) CollectList(ctx api.StreamContext, items api.MessageTupleList) error {
+	return p.collect(ctx, items.ToMaps())
+}
+
+func (p *prometheusSink) collect(ctx api.StreamContext, data any) error {
+	pts, err := tspoint.SinkTransform(ctx, data, &p.conf.WriteOptions)
+	if err != nil {
+		return err
+	}
+	samples := toSamples(pts, p.conf.MetricName, p.conf.TsFieldName)
+	if len(samples) == 0 {
+		ctx.GetLogger().Debugf("no numeric field in %v, ignored", data)
+		return nil
+	}
+	if p.conf.Mode == modeScrape {
+		p.store.update(samples)
+		return nil
+	}
+	return p.remoteWrite(ctx, samples)
+}
+
+func (p *prometheusSink) remoteWrite(ctx api.StreamContext, samples []*sample) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.conf.Url, bytes.NewReader(encodeWriteRequest(samples)))
+	if err != nil {
+		return err
+	}
+	for k, v := range p.conf.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if p.conf.Username != "" {
+		req.SetBasicAuth(p.conf.Username, p.conf.Password)
+	}
+	resp, err := p.cli.Do(req)
+	if err != nil {
+		return errorx.NewIOErr(fmt.Sprintf("prometheus sink fails to send out the data: %v", err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(resp.Body)
+		msg := fmt.Sprintf("prometheus remote write error: %s %s", resp.Status, bytes.TrimSpace(body))
+		// Only the server errors and throttling are recoverable by resending
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+			return errorx.NewIOErr(msg)
+		}
+		return errors.New(msg)
+	}
+	ctx.GetLogger().Debugf("remote write %d samples success", len(samples))
+	return nil
+}
+
+func (p *prometheusSink) Close(ctx api.StreamContext) error {
+	ctx.GetLogger().Infof("prometheus sink close")
+	if p.conf.Mode == modeScrape {
+		httpserver.UnregisterHandler(p.conf.Endpoint, http.MethodGet)
+	} else if p.cli != nil {
+		p.cli.CloseIdleConnections()
+	}
+	return nil
+}
+
+func GetSink() api.Sink {
+	return &prometheusSink{}
+}
+
+var _ api.TupleCollector = &prometheusSink{}
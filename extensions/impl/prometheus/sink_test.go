@@ -0,0 +1,246 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/lf-edge/ekuiper/v2/internal/io/http/httpserver"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/topotest/mockclock"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+type series struct {
+	labels  map[string]string
+	samples [][2]float64
+}
+
+// decodeWriteRequest decodes the snappy compressed WriteRequest into readable series
+func decodeWriteRequest(t *testing.T, body []byte) []series {
+	b, err := snappy.Decode(nil, body)
+	require.NoError(t, err)
+	var result []series
+	for _, ts := range fields(t, b) {
+		s := series{labels: map[string]string{}}
+		for _, f := range fields(t, ts.value) {
+			switch f.num {
+			case fieldLabels:
+				l := fields(t, f.value)
+				s.labels[string(l[0].value)] = string(l[1].value)
+			case fieldSamples:
+				sp := fields(t, f.value)
+				s.samples = append(s.samples, [2]float64{math.Float64frombits(sp[0].num64), float64(sp[1].num64)})
+			}
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+type field struct {
+	num   protowire.Number
+	value []byte
+	num64 uint64
+}
+
+func fields(t *testing.T, b []byte) []field {
+	var result []field
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		require.True(t, n > 0)
+		b = b[n:]
+		f := field{num: num}
+		switch typ {
+		case protowire.BytesType:
+			f.value, n = protowire.ConsumeBytes(b)
+		case protowire.Fixed64Type:
+			f.num64, n = protowire.ConsumeFixed64(b)
+		case protowire.VarintType:
+			f.num64, n = protowire.ConsumeVarint(b)
+		}
+		require.True(t, n > 0)
+		b = b[n:]
+		result = append(result, f)
+	}
+	return result
+}
+
+func TestProvisionErr(t *testing.T) {
+	ctx := mockContext.NewMockContext("1", "2")
+	tests := []struct {
+		name  string
+		props map[string]any
+		err   string
+	}{
+		{
+			name:  "invalid mode",
+			props: map[string]any{"mode": "push"},
+			err:   "mode push is not supported, must be remoteWrite or scrape",
+		},
+		{
+			name:  "invalid url",
+			props: map[string]any{"url": "localhost:9090"},
+			err:   "invalid url localhost:9090, should start with http:// or https://",
+		},
+		{
+			name:  "invalid label",
+			props: map[string]any{"url": "http://localhost:9090", "tags": map[string]any{"device-id": "{{.id}}"}},
+			err:   "invalid label name device-id",
+		},
+		{
+			name:  "reserved label",
+			props: map[string]any{"url": "http://localhost:9090", "tags": map[string]any{"__name__": "a"}},
+			err:   "invalid label name __name__",
+		},
+		{
+			name:  "invalid precision",
+			props: map[string]any{"url": "http://localhost:9090", "precision": "m"},
+			err:   "precision m is not supported",
+		},
+		{
+			name:  "invalid endpoint",
+			props: map[string]any{"mode": "scrape", "endpoint": "metrics"},
+			err:   "endpoint must start with /",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.EqualError(t, GetSink().Provision(ctx, tt.props), tt.err)
+		})
+	}
+}
+
+func TestRemoteWrite(t *testing.T) {
+	var (
+		bodies  = make(chan []byte, 10)
+		headers = make(chan http.Header, 10)
+		status  = http.StatusNoContent
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies <- b
+		headers <- r.Header.Clone()
+		w.WriteHeader(status)
+		if status >= 300 {
+			_, _ = w.Write([]byte("failed\n"))
+		}
+	}))
+	defer srv.Close()
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	s := GetSink()
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"url":         srv.URL,
+		"metricName":  "device",
+		"tags":        map[string]any{"id": "{{.id}}", "Zone": "a"},
+		"fields":      []any{"temp", "on", "ts", "name"},
+		"tsFieldName": "ts",
+		"headers":     map[string]any{"X-Scope-OrgID": "edge"},
+	}))
+	require.NoError(t, s.Connect(ctx, func(string, string) {}))
+	defer s.Close(ctx)
+	sink := s.(*prometheusSink)
+	err := sink.CollectList(ctx, &xsql.TransformedTupleList{Maps: []map[string]any{
+		{"id": "d1", "temp": 21.5, "on": true, "ts": int64(2000), "name": "a"},
+		{"id": "d1", "temp": 20, "ts": int64(1000)},
+		{"id": "d2", "temp": 30, "ts": int64(1000)},
+	}})
+	require.NoError(t, err)
+	h := <-headers
+	require.Equal(t, "snappy", h.Get("Content-Encoding"))
+	require.Equal(t, "application/x-protobuf", h.Get("Content-Type"))
+	require.Equal(t, "0.1.0", h.Get("X-Prometheus-Remote-Write-Version"))
+	require.Equal(t, "edge", h.Get("X-Scope-OrgID"))
+	result := decodeWriteRequest(t, <-bodies)
+	require.ElementsMatch(t, []series{
+		{labels: map[string]string{"__name__": "device_temp", "id": "d1", "Zone": "a"}, samples: [][2]float64{{20, 1000}, {21.5, 2000}}},
+		{labels: map[string]string{"__name__": "device_on", "id": "d1", "Zone": "a"}, samples: [][2]float64{{1, 2000}}},
+		{labels: map[string]string{"__name__": "device_temp", "id": "d2", "Zone": "a"}, samples: [][2]float64{{30, 1000}}},
+	}, result)
+
+	status = http.StatusServiceUnavailable
+	err = sink.Collect(ctx, &xsql.Tuple{Message: map[string]any{"id": "d1", "temp": 1, "ts": int64(3000)}})
+	require.EqualError(t, err, "prometheus remote write error: 503 Service Unavailable failed")
+	require.True(t, errorx.IsIOError(err))
+	status = http.StatusBadRequest
+	err = sink.Collect(ctx, &xsql.Tuple{Message: map[string]any{"id": "d1", "temp": 1, "ts": int64(3000)}})
+	require.EqualError(t, err, "prometheus remote write error: 400 Bad Request failed")
+	require.False(t, errorx.IsIOError(err))
+	// No numeric field is not sent
+	require.NoError(t, sink.Collect(ctx, &xsql.Tuple{Message: map[string]any{"id": "d1", "ts": int64(3000)}}))
+	require.Len(t, bodies, 2)
+}
+
+func TestScrape(t *testing.T) {
+	ip, port := "127.0.0.1", 10093
+	httpserver.InitGlobalServerManager(ip, port, nil)
+	defer httpserver.ShutDown()
+	mc := mockclock.GetMockClock()
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	s := GetSink()
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"mode":       "scrape",
+		"tags":       map[string]any{"id": "{{.id}}"},
+		"expiration": "1m",
+	}))
+	require.NoError(t, s.Connect(ctx, func(string, string) {}))
+	// The endpoint can only be registered by one rule
+	s2 := GetSink()
+	require.NoError(t, s2.Provision(ctx, map[string]any{"mode": "scrape"}))
+	require.EqualError(t, s2.Connect(ctx, func(string, string) {}), "endpoint GET /metrics/rule1 is already registered")
+
+	sink := s.(*prometheusSink)
+	require.NoError(t, sink.Collect(ctx, &xsql.Tuple{Message: map[string]any{"id": "d1", "temp": 20}}))
+	require.NoError(t, sink.Collect(ctx, &xsql.Tuple{Message: map[string]any{"id": "d1", "temp": 21}}))
+	mc.Add(40 * time.Second)
+	require.NoError(t, sink.Collect(ctx, &xsql.Tuple{Message: map[string]any{"id": "d2", "temp": 30}}))
+	url := fmt.Sprintf("http://%s:%d/metrics/rule1", ip, port)
+	scrape := func() string {
+		resp, err := http.Get(url)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		b, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(b)
+	}
+	require.Equal(t, `# HELP temp The result of eKuiper rule.
+# TYPE temp gauge
+temp{id="d1"} 21
+temp{id="d2"} 30
+`, scrape())
+	// The series of d1 is expired
+	mc.Add(30 * time.Second)
+	require.Equal(t, `# HELP temp The result of eKuiper rule.
+# TYPE temp gauge
+temp{id="d2"} 30
+`, scrape())
+	require.NoError(t, s.Close(ctx))
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
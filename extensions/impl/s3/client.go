@@ -0,0 +1,182 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cert"
+)
+
+// minPartSize is the min size of the parts except the last one required by S3
+const minPartSize = 5 << 20
+
+type clientConf struct {
+	Endpoint        string            `json:"endpoint"`
+	Region          string            `json:"region"`
+	Bucket          string            `json:"bucket"`
+	AccessKeyId     string            `json:"accessKeyId"`
+	SecretAccessKey string            `json:"secretAccessKey"`
+	SessionToken    string            `json:"sessionToken"`
+	UsePathStyle    bool              `json:"usePathStyle"`
+	MaxRetries      int               `json:"maxRetries"`
+	Timeout         cast.DurationConf `json:"timeout"`
+}
+
+// newClient creates the client of the S3 compatible storage. The requests are retried by the sdk with
+// exponential backoff up to the max retries.
+func newClient(ctx api.StreamContext, props map[string]any) (*s3.Client, *clientConf, error) {
+	c := &clientConf{
+		Region:     "us-east-1",
+		MaxRetries: 3,
+		Timeout:    cast.DurationConf(30 * time.Second),
+	}
+	if err := cast.MapToStruct(props, c); err != nil {
+		return nil, nil, fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	if c.Bucket == "" {
+		return nil, nil, fmt.Errorf("bucket is required")
+	}
+	if c.Endpoint != "" && !strings.HasPrefix(c.Endpoint, "http://") && !strings.HasPrefix(c.Endpoint, "https://") {
+		return nil, nil, fmt.Errorf("invalid endpoint %s, should start with http:// or https://", c.Endpoint)
+	}
+	if (c.AccessKeyId == "") != (c.SecretAccessKey == "") {
+		return nil, nil, fmt.Errorf("accessKeyId and secretAccessKey must be set together")
+	}
+	if c.MaxRetries < 0 {
+		return nil, nil, fmt.Errorf("maxRetries should not be negative")
+	}
+	if c.Timeout <= 0 {
+		return nil, nil, fmt.Errorf("timeout should be positive")
+	}
+	tlsConf, err := cert.GenTLSConfig(ctx, props)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error configuring tls: %s", err)
+	}
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.TLSClientConfig = tlsConf
+	o := s3.Options{
+		Region:       c.Region,
+		UsePathStyle: c.UsePathStyle,
+		HTTPClient:   &http.Client{Transport: tr, Timeout: time.Duration(c.Timeout)},
+		Retryer: retry.NewStandard(func(so *retry.StandardOptions) {
+			so.MaxAttempts = c.MaxRetries + 1
+		}),
+		// Many S3 compatible storages do not support the flexible checksums which are enabled by default
+		RequestChecksumCalculation: aws.RequestChecksumCalculationWhenRequired,
+		ResponseChecksumValidation: aws.ResponseChecksumValidationWhenRequired,
+	}
+	if c.Endpoint != "" {
+		o.BaseEndpoint = aws.String(c.Endpoint)
+	}
+	if c.AccessKeyId != "" {
+		o.Credentials = credentials.NewStaticCredentialsProvider(c.AccessKeyId, c.SecretAccessKey, c.SessionToken)
+	} else {
+		o.Credentials = aws.AnonymousCredentials{}
+	}
+	return s3.New(o), c, nil
+}
+
+func ping(ctx context.Context, cli *s3.Client, bucket string) error {
+	_, err := cli.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		return fmt.Errorf("fail to access bucket %s: %v", bucket, err)
+	}
+	return nil
+}
+
+// upload puts the object in one request if it is small. Otherwise, it is uploaded by parts and the upload is
+// aborted if any part fails after retries so that the storage does not keep the incomplete parts.
+func upload(ctx context.Context, cli *s3.Client, bucket, key string, r io.Reader, size, partSize int64) error {
+	if size <= partSize {
+		_, err := cli.PutObject(ctx, &s3.PutObjectInput{
+			Bucket:        aws.String(bucket),
+			Key:           aws.String(key),
+			Body:          r,
+			ContentLength: aws.Int64(size),
+		})
+		return err
+	}
+	mu, err := cli.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return err
+	}
+	parts, err := uploadParts(ctx, cli, bucket, key, mu.UploadId, r, partSize)
+	if err == nil {
+		_, err = cli.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+			Bucket:          aws.String(bucket),
+			Key:             aws.String(key),
+			UploadId:        mu.UploadId,
+			MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+		})
+	}
+	if err != nil {
+		_, abortErr := cli.AbortMultipartUpload(context.Background(), &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(bucket),
+			Key:      aws.String(key),
+			UploadId: mu.UploadId,
+		})
+		if abortErr != nil {
+			return fmt.Errorf("%v, and fail to abort the upload: %v", err, abortErr)
+		}
+	}
+	return err
+}
+
+func uploadParts(ctx context.Context, cli *s3.Client, bucket, key string, uploadId *string, r io.Reader, partSize int64) ([]types.CompletedPart, error) {
+	var parts []types.CompletedPart
+	buf := make([]byte, partSize)
+	for num := int32(1); ; num++ {
+		n, err := io.ReadFull(r, buf)
+		if err == io.EOF {
+			return parts, nil
+		}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return nil, err
+		}
+		resp, err := cli.UploadPart(ctx, &s3.UploadPartInput{
+			Bucket:        aws.String(bucket),
+			Key:           aws.String(key),
+			UploadId:      uploadId,
+			PartNumber:    aws.Int32(num),
+			Body:          bytes.NewReader(buf[:n]),
+			ContentLength: aws.Int64(int64(n)),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("fail to upload part %d: %v", num, err)
+		}
+		parts = append(parts, types.CompletedPart{ETag: resp.ETag, PartNumber: aws.Int32(num)})
+		if int64(n) < partSize {
+			return parts, nil
+		}
+	}
+}
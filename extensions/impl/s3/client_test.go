@@ -0,0 +1,271 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+type fakeObject struct {
+	data     []byte
+	modified time.Time
+}
+
+// fakeServer is a minimal path style S3 server which supports the requests used by the sink and source
+type fakeServer struct {
+	*httptest.Server
+	bucket string
+
+	mu      sync.Mutex
+	objects map[string]fakeObject
+	uploads map[string]map[int][]byte
+	// the number of the next requests to fail with 500
+	failures int
+	// the number of the next part uploads to fail with 500
+	partFailures int
+	// the number of the completed multipart uploads
+	multiparts int
+	aborts     int
+	now        time.Time
+}
+
+func newFakeServer(t *testing.T, bucket string) *fakeServer {
+	s := &fakeServer{
+		bucket:  bucket,
+		objects: make(map[string]fakeObject),
+		uploads: make(map[string]map[int][]byte),
+		now:     time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *fakeServer) props() map[string]any {
+	return map[string]any{
+		"endpoint":        s.URL,
+		"bucket":          s.bucket,
+		"usePathStyle":    true,
+		"accessKeyId":     "key",
+		"secretAccessKey": "secret",
+	}
+}
+
+func (s *fakeServer) put(key string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = s.now.Add(time.Second)
+	s.objects[key] = fakeObject{data: data, modified: s.now}
+}
+
+func (s *fakeServer) get(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.objects[key]
+	return o.data, ok
+}
+
+func (s *fakeServer) keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.objects))
+	for k := range s.objects {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (s *fakeServer) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	if s.failures > 0 {
+		s.failures--
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	s.mu.Unlock()
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	bucket, key, _ := strings.Cut(path, "/")
+	if bucket != s.bucket {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = fmt.Fprint(w, `<Error><Code>NoSuchBucket</Code></Error>`)
+		return
+	}
+	q := r.URL.Query()
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case key == "" && r.Method == http.MethodHead:
+	case key == "" && r.Method == http.MethodGet:
+		type content struct {
+			Key          string
+			LastModified string
+			Size         int
+		}
+		result := struct {
+			XMLName  xml.Name `xml:"ListBucketResult"`
+			Name     string
+			Prefix   string
+			KeyCount int
+			Contents []content
+		}{Name: bucket, Prefix: q.Get("prefix")}
+		for k, o := range s.objects {
+			if strings.HasPrefix(k, q.Get("prefix")) {
+				result.Contents = append(result.Contents, content{Key: k, LastModified: o.modified.Format(time.RFC3339), Size: len(o.data)})
+			}
+		}
+		result.KeyCount = len(result.Contents)
+		_ = xml.NewEncoder(w).Encode(result)
+	case r.Method == http.MethodGet:
+		o, ok := s.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = fmt.Fprint(w, `<Error><Code>NoSuchKey</Code></Error>`)
+			return
+		}
+		w.Header().Set("Last-Modified", o.modified.Format(http.TimeFormat))
+		_, _ = w.Write(o.data)
+	case r.Method == http.MethodPost && q.Has("uploads"):
+		id := strconv.Itoa(len(s.uploads) + 1)
+		s.uploads[id] = make(map[int][]byte)
+		_, _ = fmt.Fprintf(w, `<InitiateMultipartUploadResult><Bucket>%s</Bucket><Key>%s</Key><UploadId>%s</UploadId></InitiateMultipartUploadResult>`, bucket, key, id)
+	case r.Method == http.MethodPut && q.Has("uploadId"):
+		if s.partFailures > 0 {
+			s.partFailures--
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		num, _ := strconv.Atoi(q.Get("partNumber"))
+		s.uploads[q.Get("uploadId")][num] = body
+		w.Header().Set("ETag", fmt.Sprintf(`"%d"`, num))
+	case r.Method == http.MethodPost && q.Has("uploadId"):
+		parts := s.uploads[q.Get("uploadId")]
+		var data bytes.Buffer
+		for i := 1; i <= len(parts); i++ {
+			data.Write(parts[i])
+		}
+		delete(s.uploads, q.Get("uploadId"))
+		s.now = s.now.Add(time.Second)
+		s.objects[key] = fakeObject{data: data.Bytes(), modified: s.now}
+		s.multiparts++
+		_, _ = fmt.Fprintf(w, `<CompleteMultipartUploadResult><Bucket>%s</Bucket><Key>%s</Key><ETag>"m"</ETag></CompleteMultipartUploadResult>`, bucket, key)
+	case r.Method == http.MethodDelete && q.Has("uploadId"):
+		delete(s.uploads, q.Get("uploadId"))
+		s.aborts++
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		s.now = s.now.Add(time.Second)
+		s.objects[key] = fakeObject{data: body, modified: s.now}
+		w.Header().Set("ETag", `"p"`)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestClientProvisionErr(t *testing.T) {
+	ctx := mockContext.NewMockContext("1", "2")
+	tests := []struct {
+		name  string
+		props map[string]any
+		err   string
+	}{
+		{
+			name:  "missing bucket",
+			props: map[string]any{},
+			err:   "bucket is required",
+		},
+		{
+			name:  "invalid endpoint",
+			props: map[string]any{"bucket": "b", "endpoint": "localhost:9000"},
+			err:   "invalid endpoint localhost:9000, should start with http:// or https://",
+		},
+		{
+			name:  "missing secret",
+			props: map[string]any{"bucket": "b", "accessKeyId": "key"},
+			err:   "accessKeyId and secretAccessKey must be set together",
+		},
+		{
+			name:  "negative retries",
+			props: map[string]any{"bucket": "b", "maxRetries": -1},
+			err:   "maxRetries should not be negative",
+		},
+		{
+			name:  "zero timeout",
+			props: map[string]any{"bucket": "b", "timeout": "0s"},
+			err:   "timeout should be positive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := newClient(ctx, tt.props)
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestUpload(t *testing.T) {
+	ctx := mockContext.NewMockContext("1", "2")
+	srv := newFakeServer(t, "test")
+	cli, _, err := newClient(ctx, srv.props())
+	require.NoError(t, err)
+	require.NoError(t, ping(ctx, cli, "test"))
+	require.ErrorContains(t, ping(ctx, cli, "none"), "fail to access bucket none")
+
+	// The last part is smaller than the part size
+	data := bytes.Repeat([]byte("0123456789"), 1<<20+1)
+	require.NoError(t, upload(ctx, cli, "test", "a/big", bytes.NewReader(data), int64(len(data)), minPartSize))
+	got, ok := srv.get("a/big")
+	require.True(t, ok)
+	require.Equal(t, data, got)
+	require.Equal(t, 1, srv.multiparts)
+
+	// The failed request is retried
+	srv.mu.Lock()
+	srv.failures = 2
+	srv.mu.Unlock()
+	require.NoError(t, upload(ctx, cli, "test", "a/small", strings.NewReader("hello"), 5, minPartSize))
+	got, _ = srv.get("a/small")
+	require.Equal(t, "hello", string(got))
+
+	// The multipart upload is aborted after the retries
+	srv.mu.Lock()
+	srv.partFailures = 4
+	srv.mu.Unlock()
+	err = upload(ctx, cli, "test", "a/fail", bytes.NewReader(data), int64(len(data)), minPartSize)
+	require.ErrorContains(t, err, "fail to upload part 1")
+	_, ok = srv.get("a/fail")
+	require.False(t, ok)
+	srv.mu.Lock()
+	require.Equal(t, 1, srv.aborts)
+	require.Empty(t, srv.uploads)
+	srv.mu.Unlock()
+}
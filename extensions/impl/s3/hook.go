@@ -0,0 +1,164 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+const hookName = "s3"
+
+func init() {
+	modules.RegisterFileRollHook(hookName, GetRollHook)
+}
+
+type hookConf struct {
+	// LocalDir is the directory of the rolled files. The object key is the path of the file relative to it.
+	LocalDir    string `json:"localDir"`
+	FileType    string `json:"fileType"`
+	Compression string `json:"compression"`
+	PartSize    int64  `json:"partSize"`
+}
+
+// rollHook uploads the files rolled by the file sink. The file is renamed with the rolling time and a sequence
+// first so that the file sink can create the next file of the same path. The files failed to upload are kept and
+// uploaded again when the next file is rolled or the hook is closed.
+type rollHook struct {
+	cli  *s3.Client
+	cc   *clientConf
+	conf *hookConf
+
+	mu      sync.Mutex
+	seq     int
+	pending []string
+}
+
+func (h *rollHook) Provision(ctx api.StreamContext, props map[string]any) error {
+	cli, cc, err := newClient(ctx, props)
+	if err != nil {
+		return err
+	}
+	c := &hookConf{
+		FileType: typeLines,
+		PartSize: minPartSize,
+	}
+	if err = cast.MapToStruct(props, c); err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	if c.LocalDir == "" {
+		return fmt.Errorf("localDir is required")
+	}
+	h.cli = cli
+	h.cc = cc
+	h.conf = c
+	return nil
+}
+
+func (h *rollHook) RollDone(ctx api.StreamContext, filePath string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.seq++
+	ext := fileExt(&sinkConf{FileType: h.conf.FileType, Compression: h.conf.Compression})
+	if h.conf.FileType == typeParquet {
+		ext = ".jsonl"
+	}
+	name := fmt.Sprintf("%s_%d_%d", strings.TrimSuffix(filePath, ext), timex.GetNowInMilli(), h.seq)
+	if h.conf.FileType == typeParquet {
+		err := linesToParquet(filePath, name+".parquet", parquetCodecs[h.conf.Compression])
+		if err != nil {
+			return fmt.Errorf("fail to convert %s to parquet: %v", filePath, err)
+		}
+		_ = os.Remove(filePath)
+		name += ".parquet"
+	} else {
+		name += ext
+		if err := os.Rename(filePath, name); err != nil {
+			return err
+		}
+	}
+	h.pending = append(h.pending, name)
+	return h.uploadPending(ctx)
+}
+
+func (h *rollHook) uploadPending(ctx api.StreamContext) error {
+	var (
+		errs   []error
+		failed []string
+	)
+	for _, fn := range h.pending {
+		if err := h.upload(ctx, fn); err != nil {
+			errs = append(errs, err)
+			failed = append(failed, fn)
+			continue
+		}
+		_ = os.Remove(fn)
+	}
+	h.pending = failed
+	return errors.Join(errs...)
+}
+
+func (h *rollHook) upload(ctx api.StreamContext, fn string) error {
+	rel, err := filepath.Rel(h.conf.LocalDir, fn)
+	if err != nil {
+		return err
+	}
+	key := filepath.ToSlash(rel)
+	f, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	err = upload(ctx, h.cli, h.cc.Bucket, key, f, info.Size(), h.conf.PartSize)
+	if err != nil {
+		return fmt.Errorf("fail to upload object %s: %v", key, err)
+	}
+	ctx.GetLogger().Infof("uploaded object %s", key)
+	return nil
+}
+
+func (h *rollHook) Close(ctx api.StreamContext) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	err := h.uploadPending(ctx)
+	for _, fn := range h.pending {
+		ctx.GetLogger().Errorf("file %s is not uploaded and kept", fn)
+	}
+	h.pending = nil
+	if err != nil {
+		return errorx.NewIOErr(err.Error())
+	}
+	return nil
+}
+
+func GetRollHook() modules.RollHook {
+	return &rollHook{}
+}
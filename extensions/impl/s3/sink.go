@@ -0,0 +1,172 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/io/file"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/message"
+	"github.com/lf-edge/ekuiper/v2/pkg/model"
+)
+
+type sinkConf struct {
+	KeyPrefix       string            `json:"keyPrefix"`
+	FileType        string            `json:"fileType"`
+	Format          string            `json:"format"`
+	Compression     string            `json:"compression"`
+	Delimiter       string            `json:"delimiter"`
+	HasHeader       bool              `json:"hasHeader"`
+	Fields          []string          `json:"fields"`
+	RollingInterval cast.DurationConf `json:"rollingInterval"`
+	RollingCount    int               `json:"rollingCount"`
+	RollingSize     int64             `json:"rollingSize"`
+	CheckInterval   cast.DurationConf `json:"checkInterval"`
+	PartSize        int64             `json:"partSize"`
+}
+
+// fileWriter is the file sink which encodes and rolls the files
+type fileWriter interface {
+	api.BytesCollector
+	model.StreamWriter
+}
+
+// Sink writes the encoded results into the rolling files of the file sink under a local directory. The file of
+// each key prefix is uploaded by the s3 rolling hook when rolled.
+type Sink struct {
+	fileWriter
+	cli  *s3.Client
+	cc   *clientConf
+	conf *sinkConf
+	// The file of a key prefix is written to localDir/<keyPrefix><name>
+	localDir string
+	name     string
+}
+
+func (s *Sink) Provision(ctx api.StreamContext, props map[string]any) error {
+	cli, cc, err := newClient(ctx, props)
+	if err != nil {
+		return err
+	}
+	c := &sinkConf{
+		FileType:        typeLines,
+		Delimiter:       ",",
+		RollingInterval: cast.DurationConf(5 * time.Minute),
+		RollingCount:    1000000,
+		CheckInterval:   cast.DurationConf(5 * time.Minute),
+		PartSize:        minPartSize,
+	}
+	if err = cast.MapToStruct(props, c); err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	if err = validateFileType(c); err != nil {
+		return err
+	}
+	if c.FileType == typeParquet && c.Format != "" && c.Format != message.FormatJson {
+		return fmt.Errorf("format must be json when fileType is parquet")
+	}
+	if c.PartSize < minPartSize {
+		return fmt.Errorf("partSize should be at least %d", minPartSize)
+	}
+	s.localDir = filepath.Join(os.TempDir(), "ekuiper-s3", ctx.GetRuleId(), ctx.GetOpId())
+	s.name = ctx.GetRuleId() + fileExt(c)
+	// The parquet file is converted from the json lines when rolled
+	fileType, compression := c.FileType, c.Compression
+	if c.FileType == typeParquet {
+		fileType, compression = typeLines, ""
+		s.name = ctx.GetRuleId() + ".jsonl"
+	}
+	hookProps := make(map[string]any, len(props)+1)
+	for k, v := range props {
+		hookProps[k] = v
+	}
+	hookProps["localDir"] = s.localDir
+	err = s.fileWriter.Provision(ctx, map[string]any{
+		"path":             filepath.Join(s.localDir, c.KeyPrefix+s.name),
+		"fileType":         fileType,
+		"format":           c.Format,
+		"compression":      compression,
+		"delimiter":        c.Delimiter,
+		"hasHeader":        c.HasHeader,
+		"fields":           c.Fields,
+		"rollingInterval":  time.Duration(c.RollingInterval).String(),
+		"rollingCount":     c.RollingCount,
+		"rollingSize":      c.RollingSize,
+		"checkInterval":    time.Duration(c.CheckInterval).String(),
+		"rollingHook":      hookName,
+		"rollingHookProps": hookProps,
+	})
+	if err != nil {
+		return err
+	}
+	s.cli = cli
+	s.cc = cc
+	s.conf = c
+	return nil
+}
+
+func (s *Sink) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	if err := ping(ctx, s.cli, s.cc.Bucket); err != nil {
+		sch(api.ConnectionDisconnected, err.Error())
+		return err
+	}
+	return s.fileWriter.Connect(ctx, sch)
+}
+
+// Collect writes the item into the file of the key prefix resolved by the item
+func (s *Sink) Collect(ctx api.StreamContext, item api.RawTuple) error {
+	prefix := s.conf.KeyPrefix
+	var props map[string]string
+	if dp, ok := item.(api.HasDynamicProps); ok {
+		if v, ok := dp.DynamicProps(s.conf.KeyPrefix); ok {
+			prefix = v
+		}
+		props = dp.AllProps()
+	}
+	return s.fileWriter.Collect(ctx, &objectTuple{RawTuple: item, path: filepath.Join(s.localDir, prefix+s.name), props: props})
+}
+
+// objectTuple resolves the path of the file sink to the file of the key prefix
+type objectTuple struct {
+	api.RawTuple
+	path  string
+	props map[string]string
+}
+
+// DynamicProps is only called by the file sink to resolve the path
+func (t *objectTuple) DynamicProps(_ string) (string, bool) {
+	return t.path, true
+}
+
+func (t *objectTuple) AllProps() map[string]string {
+	return t.props
+}
+
+func GetSink() api.Sink {
+	return &Sink{fileWriter: file.GetSink().(fileWriter)}
+}
+
+var (
+	_ api.BytesCollector  = &Sink{}
+	_ model.StreamWriter  = &Sink{}
+	_ api.HasDynamicProps = &objectTuple{}
+)
@@ -0,0 +1,224 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/testx"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+func TestSinkProvisionErr(t *testing.T) {
+	ctx := mockContext.NewMockContext("1", "2")
+	tests := []struct {
+		name  string
+		props map[string]any
+		err   string
+	}{
+		{
+			name:  "invalid file type",
+			props: map[string]any{"fileType": "xml"},
+			err:   "fileType must be one of json, csv, lines or parquet",
+		},
+		{
+			name:  "invalid delimiter",
+			props: map[string]any{"fileType": "csv", "delimiter": "||"},
+			err:   "delimiter must be a single character",
+		},
+		{
+			name:  "snappy for lines",
+			props: map[string]any{"compression": "snappy"},
+			err:   "compression must be one of gzip or zstd",
+		},
+		{
+			name:  "invalid parquet compression",
+			props: map[string]any{"fileType": "parquet", "compression": "lz4"},
+			err:   "compression must be one of gzip, zstd or snappy for parquet",
+		},
+		{
+			name:  "csv format",
+			props: map[string]any{"fileType": "csv"},
+			err:   "format must be delimited when fileType is csv",
+		},
+		{
+			name:  "parquet format",
+			props: map[string]any{"fileType": "parquet", "format": "delimited"},
+			err:   "format must be json when fileType is parquet",
+		},
+		{
+			name:  "no rolling",
+			props: map[string]any{"rollingInterval": "0s", "rollingCount": 0},
+			err:   "one of rollingInterval, rollingCount, or rollingSize must be set",
+		},
+		{
+			name:  "small part size",
+			props: map[string]any{"partSize": 1024},
+			err:   "partSize should be at least 5242880",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			props := map[string]any{"bucket": "b"}
+			for k, v := range tt.props {
+				props[k] = v
+			}
+			require.EqualError(t, GetSink().Provision(ctx, props), tt.err)
+		})
+	}
+}
+
+func newSink(t *testing.T, srv *fakeServer, props map[string]any) (*Sink, api.StreamContext) {
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	p := srv.props()
+	for k, v := range props {
+		p[k] = v
+	}
+	s := GetSink().(*Sink)
+	require.NoError(t, s.Provision(ctx, p))
+	require.NoError(t, s.Connect(ctx, func(string, string) {}))
+	return s, ctx
+}
+
+// The objects are rolled by count and the key prefix is resolved for each tuple
+func TestSinkRollByCount(t *testing.T) {
+	srv := newFakeServer(t, "test")
+	s, ctx := newSink(t, srv, map[string]any{
+		"keyPrefix":    "{{.dev}}/",
+		"rollingCount": 2,
+		"compression":  "gzip",
+	})
+	tuple := func(dev string, v int) *testx.MockRawTuple {
+		return &testx.MockRawTuple{Content: []byte(fmt.Sprintf(`{"dev":"%s","v":%d}`, dev, v)), Template: map[string]string{"{{.dev}}/": dev + "/"}}
+	}
+	require.NoError(t, s.Collect(ctx, tuple("a", 1)))
+	require.NoError(t, s.Collect(ctx, tuple("b", 2)))
+	require.NoError(t, s.Collect(ctx, tuple("a", 3)))
+	keys := srv.keys()
+	require.Len(t, keys, 1)
+	require.True(t, strings.HasPrefix(keys[0], "a/rule1_"))
+	require.True(t, strings.HasSuffix(keys[0], ".jsonl.gz"))
+	data, _ := srv.get(keys[0])
+	require.Equal(t, "{\"dev\":\"a\",\"v\":1}\n{\"dev\":\"a\",\"v\":3}", gunzip(t, data))
+
+	// The open objects are uploaded when closing
+	require.NoError(t, s.Close(ctx))
+	keys = srv.keys()
+	require.Len(t, keys, 2)
+	require.True(t, strings.HasPrefix(keys[1], "b/rule1_"))
+	data, _ = srv.get(keys[1])
+	require.Equal(t, "{\"dev\":\"b\",\"v\":2}", gunzip(t, data))
+}
+
+func gunzip(t *testing.T, data []byte) string {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestSinkRollByInterval(t *testing.T) {
+	timex.InitClock()
+	srv := newFakeServer(t, "test")
+	s, ctx := newSink(t, srv, map[string]any{
+		"keyPrefix":       "csv/",
+		"fileType":        "csv",
+		"format":          "delimited",
+		"hasHeader":       true,
+		"fields":          []any{"b", "a"},
+		"rollingInterval": "1m",
+	})
+	defer s.Close(ctx)
+	require.NoError(t, s.Collect(ctx, &testx.MockRawTuple{Content: []byte("x,1")}))
+	require.NoError(t, s.Collect(ctx, &testx.MockRawTuple{Content: []byte(",2")}))
+	require.Empty(t, srv.keys())
+	// The file is rolled when it is opened longer than the rolling interval
+	timex.Add(time.Minute)
+	timex.Add(time.Minute)
+	require.Eventually(t, func() bool {
+		return len(srv.keys()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	keys := srv.keys()
+	require.True(t, strings.HasPrefix(keys[0], "csv/rule1_"))
+	require.True(t, strings.HasSuffix(keys[0], ".csv"))
+	data, _ := srv.get(keys[0])
+	require.Equal(t, "b,a\nx,1\n,2", string(data))
+}
+
+// The failed upload is kept and uploaded again when the next file is rolled
+func TestSinkRetryUpload(t *testing.T) {
+	srv := newFakeServer(t, "test")
+	s, ctx := newSink(t, srv, map[string]any{
+		"fileType":     "json",
+		"rollingCount": 1,
+		"maxRetries":   0,
+	})
+	srv.mu.Lock()
+	srv.failures = 1
+	srv.mu.Unlock()
+	require.NoError(t, s.Collect(ctx, &testx.MockRawTuple{Content: []byte(`{"a":1}`)}))
+	require.Empty(t, srv.keys())
+	require.NoError(t, s.Collect(ctx, &testx.MockRawTuple{Content: []byte(`{"a":2}`)}))
+	keys := srv.keys()
+	require.Len(t, keys, 2)
+	data, _ := srv.get(keys[0])
+	require.Equal(t, `[{"a":1}]`, string(data))
+	data, _ = srv.get(keys[1])
+	require.Equal(t, `[{"a":2}]`, string(data))
+	require.NoError(t, s.Close(ctx))
+}
+
+// The json lines are converted to parquet when rolled
+func TestSinkParquet(t *testing.T) {
+	srv := newFakeServer(t, "test")
+	s, ctx := newSink(t, srv, map[string]any{
+		"fileType":    "parquet",
+		"compression": "snappy",
+	})
+	require.NoError(t, s.Collect(ctx, &testx.MockRawTuple{Content: []byte(`{"id":1,"temp":20.5,"ok":true,"name":"a","tags":["x"]}`)}))
+	require.NoError(t, s.Collect(ctx, &testx.MockRawTuple{Content: []byte(`{"id":2,"temp":21,"name":null}`)}))
+	require.NoError(t, s.Close(ctx))
+	keys := srv.keys()
+	require.Len(t, keys, 1)
+	require.True(t, strings.HasPrefix(keys[0], "rule1_"))
+	require.True(t, strings.HasSuffix(keys[0], ".parquet"))
+	data, _ := srv.get(keys[0])
+	f, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	rows := make([]parquet.Row, 2)
+	n, _ := f.RowGroups()[0].Rows().ReadRows(rows)
+	require.Equal(t, 2, n)
+	// The columns are sorted by name: id, name, ok, tags, temp
+	require.Equal(t, int64(1), rows[0][0].Int64())
+	require.Equal(t, "a", rows[0][1].String())
+	require.True(t, rows[0][2].Boolean())
+	require.Equal(t, `["x"]`, rows[0][3].String())
+	require.Equal(t, 20.5, rows[0][4].Double())
+	require.True(t, rows[1][1].IsNull())
+	require.True(t, rows[1][2].IsNull())
+	require.Equal(t, 21.0, rows[1][4].Double())
+}
@@ -0,0 +1,290 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/compressor"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/model"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+type sourceConf struct {
+	Prefix        string `json:"datasource"`
+	FileType      string `json:"fileType"`
+	Decompression string `json:"decompression"`
+	MaxObjectSize int64  `json:"maxObjectSize"`
+}
+
+// SourceOffset is the last modified time of the read objects and the keys read at that time.
+// The objects modified at the same time are distinguished by the keys.
+type SourceOffset struct {
+	LastModified time.Time `json:"lastModified"`
+	Keys         []string  `json:"keys"`
+}
+
+func init() {
+	gob.Register(&SourceOffset{})
+}
+
+// Source lists the objects under the prefix in each pull and reads the new ones in the order of the modified time.
+// If the file type has a stream reader, the object is read by the reader and decompressed in the source. Otherwise,
+// the whole object is sent out to decompress and decode.
+type Source struct {
+	cli    *s3.Client
+	cc     *clientConf
+	conf   *sourceConf
+	reader modules.FileStreamReader
+	offset *SourceOffset
+}
+
+func (s *Source) Provision(ctx api.StreamContext, props map[string]any) error {
+	cli, cc, err := newClient(ctx, props)
+	if err != nil {
+		return err
+	}
+	c := &sourceConf{
+		FileType:      typeJson,
+		MaxObjectSize: 100 << 20,
+	}
+	if err = cast.MapToStruct(props, c); err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	if c.MaxObjectSize <= 0 {
+		return fmt.Errorf("maxObjectSize should be positive")
+	}
+	if reader, ok := modules.GetFileStreamReader(ctx, c.FileType); ok {
+		if c.Decompression != "" {
+			// Validate the decompression as it is done in the source
+			if _, err = compressor.GetDecompressor(c.Decompression); err != nil {
+				return err
+			}
+		}
+		if err = reader.Provision(ctx, props); err != nil {
+			return err
+		}
+		s.reader = reader
+	} else {
+		ctx.GetLogger().Warnf("file type %s is not stream reader, will send out the whole object", c.FileType)
+	}
+	s.cli = cli
+	s.cc = cc
+	s.conf = c
+	s.offset = &SourceOffset{}
+	return nil
+}
+
+func (s *Source) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	if err := ping(ctx, s.cli, s.cc.Bucket); err != nil {
+		sch(api.ConnectionDisconnected, err.Error())
+		return err
+	}
+	sch(api.ConnectionConnected, "")
+	return nil
+}
+
+func (s *Source) Pull(ctx api.StreamContext, _ time.Time, ingest api.TupleIngest, ingestError api.ErrorIngest) {
+	objs, err := s.list(ctx)
+	if err != nil {
+		ingestError(ctx, err)
+		return
+	}
+	for _, obj := range objs {
+		if aws.ToInt64(obj.Size) > s.conf.MaxObjectSize {
+			ctx.GetLogger().Warnf("object %s is larger than maxObjectSize, ignore", aws.ToString(obj.Key))
+		} else if err = s.read(ctx, aws.ToString(obj.Key), ingest); err != nil {
+			// Do not update the offset so that the object is read again in the next pull
+			ingestError(ctx, fmt.Errorf("fail to read object %s: %v", aws.ToString(obj.Key), err))
+			return
+		}
+		s.updateOffset(aws.ToString(obj.Key), aws.ToTime(obj.LastModified))
+	}
+}
+
+// list returns the objects not read yet sorted by the modified time and key
+func (s *Source) list(ctx api.StreamContext) ([]types.Object, error) {
+	var result []types.Object
+	p := s3.NewListObjectsV2Paginator(s.cli, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.cc.Bucket),
+		Prefix: aws.String(s.conf.Prefix),
+	})
+	for p.HasMorePages() {
+		page, err := p.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("fail to list objects of %s: %v", s.conf.Prefix, err)
+		}
+		for _, obj := range page.Contents {
+			if s.isNew(aws.ToString(obj.Key), aws.ToTime(obj.LastModified)) {
+				result = append(result, obj)
+			}
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		ti, tj := aws.ToTime(result[i].LastModified), aws.ToTime(result[j].LastModified)
+		if ti.Equal(tj) {
+			return aws.ToString(result[i].Key) < aws.ToString(result[j].Key)
+		}
+		return ti.Before(tj)
+	})
+	return result, nil
+}
+
+func (s *Source) isNew(key string, modified time.Time) bool {
+	if modified.After(s.offset.LastModified) {
+		return true
+	}
+	if modified.Equal(s.offset.LastModified) {
+		for _, k := range s.offset.Keys {
+			if k == key {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func (s *Source) updateOffset(key string, modified time.Time) {
+	if modified.After(s.offset.LastModified) {
+		s.offset = &SourceOffset{LastModified: modified, Keys: []string{key}}
+	} else if modified.Equal(s.offset.LastModified) {
+		s.offset.Keys = append(s.offset.Keys, key)
+	}
+}
+
+// read downloads the object to a temporary file so that the reader like parquet can seek
+func (s *Source) read(ctx api.StreamContext, key string, ingest api.TupleIngest) error {
+	resp, err := s.cli.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.cc.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	meta := map[string]any{"bucket": s.cc.Bucket, "key": key}
+	if s.reader == nil {
+		content, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		ingest(ctx, content, meta, timex.GetNow())
+		return nil
+	}
+	f, err := os.CreateTemp("", "ekuiper-s3-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+	size, err := io.Copy(f, resp.Body)
+	if err != nil {
+		return err
+	}
+	if size == 0 {
+		ctx.GetLogger().Warnf("read empty object %s, ignore", key)
+		return nil
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	var r io.Reader = f
+	maxSize := int(size)
+	if s.conf.Decompression != "" {
+		dr, err := compressor.GetDecompressReader(s.conf.Decompression, f)
+		if err != nil {
+			return err
+		}
+		defer dr.Close()
+		r = dr
+		// The decompressed line may be larger than the object
+		maxSize = int(s.conf.MaxObjectSize)
+	}
+	if err = s.reader.Bind(ctx, r, maxSize); err != nil {
+		return err
+	}
+	defer s.reader.Close(ctx)
+	for {
+		line, err := s.reader.Read(ctx)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		ingest(ctx, line, meta, timex.GetNow())
+	}
+}
+
+func (s *Source) Close(_ api.StreamContext) error {
+	return nil
+}
+
+func (s *Source) Info() (i model.NodeInfo) {
+	if s.reader == nil {
+		i.NeedBatchDecode = true
+		i.NeedDecode = true
+	} else {
+		i.NeedDecode = s.reader.IsBytesReader()
+		i.HasCompress = true
+	}
+	return
+}
+
+func (s *Source) TransformType() api.Source {
+	return s
+}
+
+func (s *Source) GetOffset() (any, error) {
+	return s.offset, nil
+}
+
+func (s *Source) Rewind(offset any) error {
+	o, ok := offset.(*SourceOffset)
+	if !ok {
+		return fmt.Errorf("s3 source rewind failed, invalid offset %v", offset)
+	}
+	s.offset = o
+	return nil
+}
+
+func (s *Source) ResetOffset(_ map[string]any) error {
+	return fmt.Errorf("s3 source ResetOffset not supported")
+}
+
+func GetSource() api.Source {
+	return &Source{}
+}
+
+var (
+	_ api.PullTupleSource = &Source{}
+	_ model.InfoNode      = &Source{}
+	_ api.Rewindable      = &Source{}
+)
@@ -0,0 +1,125 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/lf-edge/ekuiper/v2/internal/io/file/reader"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/model"
+)
+
+type pulled struct {
+	data any
+	meta map[string]any
+}
+
+func pull(ctx api.StreamContext, s *Source) ([]pulled, []error) {
+	var (
+		result []pulled
+		errs   []error
+	)
+	s.Pull(ctx, time.Now(), func(_ api.StreamContext, data any, meta map[string]any, _ time.Time) {
+		result = append(result, pulled{data: data, meta: meta})
+	}, func(_ api.StreamContext, err error) {
+		errs = append(errs, err)
+	})
+	return result, errs
+}
+
+func TestSourceProvisionErr(t *testing.T) {
+	ctx := mockContext.NewMockContext("1", "2")
+	require.EqualError(t, GetSource().Provision(ctx, map[string]any{"bucket": "b", "maxObjectSize": 0}), "maxObjectSize should be positive")
+	require.Error(t, GetSource().Provision(ctx, map[string]any{"bucket": "b", "fileType": "lines", "decompression": "rar"}))
+}
+
+func TestSourceLines(t *testing.T) {
+	srv := newFakeServer(t, "test")
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	props := srv.props()
+	props["datasource"] = "logs/"
+	props["fileType"] = "lines"
+	props["decompression"] = "gzip"
+	s := GetSource().(*Source)
+	require.NoError(t, s.Provision(ctx, props))
+	require.NoError(t, s.Connect(ctx, func(string, string) {}))
+	require.Equal(t, model.NodeInfo{NeedDecode: true, HasCompress: true}, s.Info())
+
+	srv.put("logs/b", gzipData(t, "{\"a\":2}\n{\"a\":3}\n"))
+	srv.put("logs/a", gzipData(t, "{\"a\":1}\n"))
+	srv.put("other/c", []byte("{\"a\":4}\n"))
+	result, errs := pull(ctx, s)
+	require.Empty(t, errs)
+	// The objects are read in the order of the modified time
+	require.Equal(t, []pulled{
+		{data: []byte(`{"a":2}`), meta: map[string]any{"bucket": "test", "key": "logs/b"}},
+		{data: []byte(`{"a":3}`), meta: map[string]any{"bucket": "test", "key": "logs/b"}},
+		{data: []byte(`{"a":1}`), meta: map[string]any{"bucket": "test", "key": "logs/a"}},
+	}, result)
+
+	// Only the new objects are read
+	result, _ = pull(ctx, s)
+	require.Empty(t, result)
+	offset, err := s.GetOffset()
+	require.NoError(t, err)
+	srv.put("logs/d", gzipData(t, "{\"a\":5}\n"))
+	result, _ = pull(ctx, s)
+	require.Len(t, result, 1)
+	require.Equal(t, "logs/d", result[0].meta["key"])
+
+	// Rewind to read the object again
+	require.NoError(t, s.Rewind(offset))
+	result, _ = pull(ctx, s)
+	require.Len(t, result, 1)
+	require.Error(t, s.Rewind("invalid"))
+}
+
+// The object which can not be read is read again in the next pull
+func TestSourceRaw(t *testing.T) {
+	srv := newFakeServer(t, "test")
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	props := srv.props()
+	props["maxRetries"] = 0
+	s := GetSource().(*Source)
+	require.NoError(t, s.Provision(ctx, props))
+	require.Equal(t, model.NodeInfo{NeedDecode: true, NeedBatchDecode: true}, s.Info())
+	srv.put("a.json", []byte(`[{"a":1}]`))
+
+	srv.mu.Lock()
+	srv.failures = 1
+	srv.mu.Unlock()
+	result, errs := pull(ctx, s)
+	require.Empty(t, result)
+	require.Len(t, errs, 1)
+	result, errs = pull(ctx, s)
+	require.Empty(t, errs)
+	require.Equal(t, []pulled{{data: []byte(`[{"a":1}]`), meta: map[string]any{"bucket": "test", "key": "a.json"}}}, result)
+}
+
+func gzipData(t *testing.T, s string) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte(s))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
@@ -0,0 +1,228 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+const (
+	typeJson    = "json"
+	typeCsv     = "csv"
+	typeLines   = "lines"
+	typeParquet = "parquet"
+)
+
+var parquetCodecs = map[string]compress.Codec{
+	"":       &parquet.Uncompressed,
+	"gzip":   &parquet.Gzip,
+	"zstd":   &parquet.Zstd,
+	"snappy": &parquet.Snappy,
+}
+
+// parquetEncoder infers the schema from the first row. The int, float, bool and string fields are mapped to the
+// optional columns of the same type, and the other fields are encoded as json strings. The values which can not
+// be converted to the column type and the fields not in the schema are dropped.
+type parquetEncoder struct {
+	w       io.Writer
+	codec   compress.Codec
+	columns []parquetColumn
+	writer  *parquet.Writer
+}
+
+type parquetColumn struct {
+	name string
+	kind parquet.Kind
+}
+
+func (e *parquetEncoder) write(m map[string]any) error {
+	if e.writer == nil {
+		group := parquet.Group{}
+		for _, k := range sortedKeys(m) {
+			var node parquet.Node
+			switch m[k].(type) {
+			case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+				node = parquet.Int(64)
+			case float32, float64:
+				node = parquet.Leaf(parquet.DoubleType)
+			case bool:
+				node = parquet.Leaf(parquet.BooleanType)
+			default:
+				node = parquet.String()
+			}
+			group[k] = parquet.Optional(node)
+		}
+		schema := parquet.NewSchema("ekuiper", group)
+		// The columns of the group are sorted by name
+		for _, f := range schema.Fields() {
+			e.columns = append(e.columns, parquetColumn{name: f.Name(), kind: f.Type().Kind()})
+		}
+		e.writer = parquet.NewWriter(e.w, schema, parquet.Compression(e.codec))
+	}
+	row := make(parquet.Row, len(e.columns))
+	for i, col := range e.columns {
+		row[i] = parquetValue(m[col.name], col.kind).Level(0, 1, i)
+		if row[i].IsNull() {
+			row[i] = parquet.Value{}.Level(0, 0, i)
+		}
+	}
+	_, err := e.writer.WriteRows([]parquet.Row{row})
+	return err
+}
+
+func parquetValue(v any, kind parquet.Kind) parquet.Value {
+	if v == nil {
+		return parquet.Value{}
+	}
+	switch kind {
+	case parquet.Int64:
+		if i, err := cast.ToInt64(v, cast.CONVERT_SAMEKIND); err == nil {
+			return parquet.Int64Value(i)
+		}
+	case parquet.Double:
+		if f, err := cast.ToFloat64(v, cast.CONVERT_SAMEKIND); err == nil {
+			return parquet.DoubleValue(f)
+		}
+	case parquet.Boolean:
+		if b, ok := v.(bool); ok {
+			return parquet.BooleanValue(b)
+		}
+	default:
+		switch vt := v.(type) {
+		case string:
+			return parquet.ByteArrayValue([]byte(vt))
+		case []byte:
+			return parquet.ByteArrayValue(vt)
+		case map[string]any, []any, []map[string]any:
+			if b, err := json.Marshal(vt); err == nil {
+				return parquet.ByteArrayValue(b)
+			}
+		default:
+			return parquet.ByteArrayValue([]byte(cast.ToStringAlways(vt)))
+		}
+	}
+	return parquet.Value{}
+}
+
+func (e *parquetEncoder) close() error {
+	if e.writer == nil {
+		return nil
+	}
+	return e.writer.Close()
+}
+
+// linesToParquet converts the json lines file written by the file sink to a parquet file. The json numbers are
+// decoded as int64 if possible so that the integer fields are mapped to the integer columns.
+func linesToParquet(src, dst string, codec compress.Codec) (ge error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := out.Close(); err != nil && ge == nil {
+			ge = err
+		}
+		if ge != nil {
+			_ = os.Remove(dst)
+		}
+	}()
+	buf := bufio.NewWriter(out)
+	e := &parquetEncoder{w: buf, codec: codec}
+	dec := json.NewDecoder(in)
+	dec.UseNumber()
+	for {
+		var m map[string]any
+		if err = dec.Decode(&m); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("invalid json line: %v", err)
+		}
+		for k, v := range m {
+			if n, ok := v.(json.Number); ok {
+				if i, err := n.Int64(); err == nil {
+					m[k] = i
+				} else if f, err := n.Float64(); err == nil {
+					m[k] = f
+				}
+			}
+		}
+		if err = e.write(m); err != nil {
+			return err
+		}
+	}
+	if err = e.close(); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func fileExt(c *sinkConf) string {
+	ext := "." + c.FileType
+	if c.FileType == typeLines {
+		ext = ".jsonl"
+	}
+	if c.FileType != typeParquet {
+		switch c.Compression {
+		case "gzip":
+			ext += ".gz"
+		case "zstd":
+			ext += ".zst"
+		}
+	}
+	return ext
+}
+
+func validateFileType(c *sinkConf) error {
+	switch c.FileType {
+	case typeJson, typeLines, typeParquet:
+	case typeCsv:
+		if len([]rune(c.Delimiter)) != 1 {
+			return fmt.Errorf("delimiter must be a single character")
+		}
+	default:
+		return fmt.Errorf("fileType must be one of json, csv, lines or parquet")
+	}
+	if _, ok := parquetCodecs[c.Compression]; !ok || (c.Compression == "snappy" && c.FileType != typeParquet) {
+		if c.FileType == typeParquet {
+			return fmt.Errorf("compression must be one of gzip, zstd or snappy for parquet")
+		}
+		return fmt.Errorf("compression must be one of gzip or zstd")
+	}
+	return nil
+}
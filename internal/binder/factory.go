@@ -54,6 +54,14 @@ type FuncFactory interface {
 	FunctionPluginInfo(funcName string) (plugin.EXTENSION_TYPE, string, string)
 }
 
+// IncAggFuncFactory is implemented by the function factories whose aggregate functions may be calculated incrementally.
+// IsIncAggFunction tells the capability without creating the function.
+// IsIncAggMergeFunction tells if the partial states of the incremental aggregate function can be merged.
+type IncAggFuncFactory interface {
+	IsIncAggFunction(funcName string) bool
+	IsIncAggMergeFunction(funcName string) bool
+}
+
 type FactoryEntry struct {
 	Name    string
 	Factory interface{}
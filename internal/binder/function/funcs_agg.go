@@ -17,6 +17,7 @@ package function
 import (
 	"fmt"
 	"sort"
+	"time"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 	"github.com/montanaflynn/stats"
@@ -406,6 +407,218 @@ func registerAggFunc() {
 		},
 		check: returnNilIfHasAnyNil,
 	}
+	builtins["time_weighted_avg"] = builtinFunc{
+		fType: ast.FuncTypeAgg,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			points, err := toTimePoints(args[0], args[1])
+			if err != nil {
+				return err, false
+			}
+			if len(points) == 0 {
+				return nil, true
+			}
+			end := points[len(points)-1].ts
+			if len(args) > 2 {
+				arg2, ok := args[2].([]interface{})
+				if !ok {
+					return fmt.Errorf("the third argument to the aggregate function should be []interface but found %[1]T(%[1]v)", args[2]), false
+				}
+				if v := getFirstValidArg(arg2); v != nil {
+					e, err := toMillis(v)
+					if err != nil {
+						return fmt.Errorf("the end time requires timestamp but found %[1]T(%[1]v)", v), false
+					}
+					if e > end {
+						end = e
+					}
+				}
+			}
+			return timeWeightedAvg(points, end), true
+		},
+		val: func(_ api.FunctionContext, args []ast.Expr) error {
+			if len(args) != 2 && len(args) != 3 {
+				return fmt.Errorf("Expect 2 or 3 arguments but found %d.", len(args))
+			}
+			if ast.IsStringArg(args[0]) || ast.IsTimeArg(args[0]) || ast.IsBooleanArg(args[0]) {
+				return ProduceErrInfo(0, "number - float or int")
+			}
+			return nil
+		},
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["interpolate"] = builtinFunc{
+		fType: ast.FuncTypeAgg,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			points, err := toTimePoints(args[0], args[1])
+			if err != nil {
+				return err, false
+			}
+			if len(points) == 0 {
+				return nil, true
+			}
+			arg2, ok := args[2].([]interface{})
+			if !ok {
+				return fmt.Errorf("the third argument to the aggregate function should be []interface but found %[1]T(%[1]v)", args[2]), false
+			}
+			v := getFirstValidArg(arg2)
+			if v == nil {
+				return nil, true
+			}
+			at, err := toMillis(v)
+			if err != nil {
+				return fmt.Errorf("the time to interpolate requires timestamp but found %[1]T(%[1]v)", v), false
+			}
+			return interpolateAt(points, at), true
+		},
+		val: func(_ api.FunctionContext, args []ast.Expr) error {
+			if err := ValidateLen(3, len(args)); err != nil {
+				return err
+			}
+			if ast.IsStringArg(args[0]) || ast.IsTimeArg(args[0]) || ast.IsBooleanArg(args[0]) {
+				return ProduceErrInfo(0, "number - float or int")
+			}
+			return nil
+		},
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["resample"] = builtinFunc{
+		fType: ast.FuncTypeAgg,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			points, err := toTimePoints(args[0], args[1])
+			if err != nil {
+				return err, false
+			}
+			arg2, ok := args[2].([]interface{})
+			if !ok {
+				return fmt.Errorf("the third argument to the aggregate function should be []interface but found %[1]T(%[1]v)", args[2]), false
+			}
+			interval, err := cast.ToInt64(getFirstValidArg(arg2), cast.CONVERT_SAMEKIND)
+			if err != nil || interval <= 0 {
+				return fmt.Errorf("the interval requires positive integer but found %v", getFirstValidArg(arg2)), false
+			}
+			if len(points) == 0 {
+				return []interface{}{}, true
+			}
+			first, last := points[0].ts, points[len(points)-1].ts
+			start := first - first%interval
+			if start < first {
+				start += interval
+			}
+			if last >= start && (last-start)/interval >= maxResamplePoints {
+				return fmt.Errorf("resample with interval %d generates more than %d points", interval, maxResamplePoints), false
+			}
+			result := make([]interface{}, 0)
+			for t := start; t <= last; t += interval {
+				result = append(result, map[string]interface{}{
+					"ts":    t,
+					"value": interpolateAt(points, t),
+				})
+			}
+			return result, true
+		},
+		val: func(_ api.FunctionContext, args []ast.Expr) error {
+			if err := ValidateLen(3, len(args)); err != nil {
+				return err
+			}
+			if ast.IsStringArg(args[0]) || ast.IsTimeArg(args[0]) || ast.IsBooleanArg(args[0]) {
+				return ProduceErrInfo(0, "number - float or int")
+			}
+			if ast.IsFloatArg(args[2]) || ast.IsStringArg(args[2]) || ast.IsTimeArg(args[2]) || ast.IsBooleanArg(args[2]) {
+				return ProduceErrInfo(2, "int")
+			}
+			return nil
+		},
+		check: returnNilIfHasAnyNil,
+	}
+}
+
+// maxResamplePoints limits the size of the resample result to protect the memory
+const maxResamplePoints = 10000
+
+type timePoint struct {
+	ts    int64
+	value float64
+}
+
+// toTimePoints zips the values and the timestamps of the aggregate arguments into the points sorted by time.
+// The rows with nil value or nil timestamp are ignored.
+func toTimePoints(arg0, arg1 interface{}) ([]timePoint, error) {
+	values, ok := arg0.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("the first argument to the aggregate function should be []interface but found %[1]T(%[1]v)", arg0)
+	}
+	tss, ok := arg1.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("the second argument to the aggregate function should be []interface but found %[1]T(%[1]v)", arg1)
+	}
+	points := make([]timePoint, 0, len(values))
+	for i, v := range values {
+		if v == nil || i >= len(tss) || tss[i] == nil {
+			continue
+		}
+		f, err := cast.ToFloat64(v, cast.CONVERT_SAMEKIND)
+		if err != nil {
+			return nil, fmt.Errorf("the value requires number but found %[1]T(%[1]v)", v)
+		}
+		ts, err := toMillis(tss[i])
+		if err != nil {
+			return nil, fmt.Errorf("the timestamp requires int or datetime but found %[1]T(%[1]v)", tss[i])
+		}
+		points = append(points, timePoint{ts: ts, value: f})
+	}
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].ts < points[j].ts
+	})
+	return points, nil
+}
+
+// toMillis converts the datetime or the epoch milliseconds to the epoch milliseconds
+func toMillis(v interface{}) (int64, error) {
+	if t, ok := v.(time.Time); ok {
+		return t.UnixMilli(), nil
+	}
+	return cast.ToInt64(v, cast.CONVERT_SAMEKIND)
+}
+
+// timeWeightedAvg weights each value by the duration until the next point.
+// The last value lasts until the end time.
+func timeWeightedAvg(points []timePoint, end int64) float64 {
+	duration := end - points[0].ts
+	if duration <= 0 {
+		total := 0.0
+		for _, p := range points {
+			total += p.value
+		}
+		return total / float64(len(points))
+	}
+	total := 0.0
+	for i, p := range points {
+		next := end
+		if i < len(points)-1 {
+			next = points[i+1].ts
+		}
+		total += p.value * float64(next-p.ts)
+	}
+	return total / float64(duration)
+}
+
+// interpolateAt calculates the value at the time by the linear interpolation of the two adjacent points.
+// The time out of the range of the points gets the value of the nearest point.
+func interpolateAt(points []timePoint, at int64) float64 {
+	if at <= points[0].ts {
+		return points[0].value
+	}
+	i := sort.Search(len(points), func(i int) bool {
+		return points[i].ts >= at
+	})
+	if i == len(points) {
+		return points[len(points)-1].value
+	}
+	if points[i].ts == at {
+		return points[i].value
+	}
+	prev, next := points[i-1], points[i]
+	return prev.value + (next.value-prev.value)*float64(at-prev.ts)/float64(next.ts-prev.ts)
 }
 
 type Number interface {
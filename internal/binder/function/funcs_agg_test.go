@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -770,3 +771,143 @@ func TestMedianFunc(t *testing.T) {
 		require.Equal(t, tt.expect, got)
 	}
 }
+
+func TestTimeSeriesAggExec(t *testing.T) {
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	values := []interface{}{int64(10), 20.0, nil, int64(40)}
+	tss := []interface{}{int64(1000), int64(3000), int64(3500), time.UnixMilli(4000)}
+	tests := []struct {
+		name   string
+		args   []interface{}
+		result interface{}
+	}{
+		{
+			name:   "time_weighted_avg",
+			args:   []interface{}{values, tss},
+			result: (10.0*2000 + 20.0*1000) / 3000,
+		},
+		{
+			name:   "time_weighted_avg",
+			args:   []interface{}{values, tss, []interface{}{int64(5000), int64(5000), int64(5000), int64(5000)}},
+			result: (10.0*2000 + 20.0*1000 + 40.0*1000) / 4000,
+		},
+		{
+			name:   "time_weighted_avg",
+			args:   []interface{}{[]interface{}{int64(5)}, []interface{}{int64(1000)}},
+			result: 5.0,
+		},
+		{
+			name:   "time_weighted_avg",
+			args:   []interface{}{[]interface{}{}, []interface{}{}},
+			result: nil,
+		},
+		{
+			name:   "time_weighted_avg",
+			args:   []interface{}{[]interface{}{"a"}, []interface{}{int64(1000)}},
+			result: fmt.Errorf("the value requires number but found string(a)"),
+		},
+		{
+			name:   "interpolate",
+			args:   []interface{}{values, tss, []interface{}{int64(2500), int64(2500), int64(2500), int64(2500)}},
+			result: 17.5,
+		},
+		{
+			name:   "interpolate",
+			args:   []interface{}{values, tss, []interface{}{int64(500), int64(500), int64(500), int64(500)}},
+			result: 10.0,
+		},
+		{
+			name:   "interpolate",
+			args:   []interface{}{values, tss, []interface{}{int64(9000), int64(9000), int64(9000), int64(9000)}},
+			result: 40.0,
+		},
+		{
+			name:   "interpolate",
+			args:   []interface{}{values, []interface{}{int64(1000), "a", nil, nil}, []interface{}{int64(1000)}},
+			result: fmt.Errorf("the timestamp requires int or datetime but found string(a)"),
+		},
+		{
+			name: "resample",
+			args: []interface{}{values, tss, []interface{}{int64(1500), int64(1500), int64(1500), int64(1500)}},
+			result: []interface{}{
+				map[string]interface{}{"ts": int64(1500), "value": 12.5},
+				map[string]interface{}{"ts": int64(3000), "value": 20.0},
+			},
+		},
+		{
+			name:   "resample",
+			args:   []interface{}{[]interface{}{}, []interface{}{}, []interface{}{}},
+			result: fmt.Errorf("the interval requires positive integer but found <nil>"),
+		},
+		{
+			name:   "resample",
+			args:   []interface{}{values, tss, []interface{}{int64(0), int64(0), int64(0), int64(0)}},
+			result: fmt.Errorf("the interval requires positive integer but found 0"),
+		},
+		{
+			name:   "resample",
+			args:   []interface{}{[]interface{}{1, 2}, []interface{}{int64(0), int64(20000)}, []interface{}{int64(1), int64(1)}},
+			result: fmt.Errorf("resample with interval 1 generates more than 10000 points"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, ok := builtins[tt.name]
+			require.True(t, ok)
+			result, ok := f.exec(fctx, tt.args)
+			if err, isErr := tt.result.(error); isErr {
+				require.False(t, ok)
+				require.EqualError(t, result.(error), err.Error())
+				return
+			}
+			require.True(t, ok, result)
+			require.Equal(t, tt.result, result)
+		})
+	}
+}
+
+func TestTimeSeriesAggValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		args []ast.Expr
+		err  string
+	}{
+		{
+			name: "time_weighted_avg",
+			args: []ast.Expr{&ast.FieldRef{Name: "a"}},
+			err:  "Expect 2 or 3 arguments but found 1.",
+		},
+		{
+			name: "time_weighted_avg",
+			args: []ast.Expr{&ast.StringLiteral{Val: "a"}, &ast.FieldRef{Name: "ts"}},
+			err:  "Expect number - float or int type for parameter 1",
+		},
+		{
+			name: "interpolate",
+			args: []ast.Expr{&ast.FieldRef{Name: "a"}, &ast.FieldRef{Name: "ts"}},
+			err:  "Expect 3 arguments but found 2.",
+		},
+		{
+			name: "resample",
+			args: []ast.Expr{&ast.FieldRef{Name: "a"}, &ast.FieldRef{Name: "ts"}, &ast.NumberLiteral{Val: 1.5}},
+			err:  "Expect int type for parameter 3",
+		},
+		{
+			name: "resample",
+			args: []ast.Expr{&ast.FieldRef{Name: "a"}, &ast.FieldRef{Name: "ts"}, &ast.IntegerLiteral{Val: 1000}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := builtins[tt.name].val(nil, tt.args)
+			if tt.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
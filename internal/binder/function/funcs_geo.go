@@ -0,0 +1,222 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package function
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/geo"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+// bufferSegments is the number of segments to approximate the circle of st_buffer by default
+const bufferSegments = 32
+
+// The geometry values are passed between the geo functions as GeoJSON geometry objects.
+// The functions also accept WKT, GeoJSON text and [lon, lat] array as the geometry arguments.
+func registerGeoFunc() {
+	builtins["st_point"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			lon, err := cast.ToFloat64(args[0], cast.CONVERT_SAMEKIND)
+			if err != nil {
+				return err, false
+			}
+			lat, err := cast.ToFloat64(args[1], cast.CONVERT_SAMEKIND)
+			if err != nil {
+				return err, false
+			}
+			return geo.NewPoint(lon, lat).GeoJSON(), true
+		},
+		val:   ValidateTwoNumberArg,
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["st_geomfromtext"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			s, ok := args[0].(string)
+			if !ok {
+				return fmt.Errorf("the argument should be a WKT string but got %v", args[0]), false
+			}
+			g, err := geo.ParseWKT(s)
+			if err != nil {
+				return err, false
+			}
+			return g.GeoJSON(), true
+		},
+		val:   ValidateOneStrArg,
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["st_geomfromgeojson"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			var (
+				g   *geo.Geometry
+				err error
+			)
+			switch v := args[0].(type) {
+			case string:
+				g, err = geo.Parse(v)
+			case map[string]interface{}:
+				g, err = geo.FromGeoJSON(v)
+			default:
+				err = fmt.Errorf("the argument should be a GeoJSON string or object but got %v", args[0])
+			}
+			if err != nil {
+				return err, false
+			}
+			return g.GeoJSON(), true
+		},
+		val:   ValidateOneArg,
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["st_astext"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			g, err := toGeometry(args[0], 0)
+			if err != nil {
+				return err, false
+			}
+			return g.WKT(), true
+		},
+		val:   ValidateOneArg,
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["st_asgeojson"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			g, err := toGeometry(args[0], 0)
+			if err != nil {
+				return err, false
+			}
+			b, err := json.Marshal(g.GeoJSON())
+			if err != nil {
+				return err, false
+			}
+			return string(b), true
+		},
+		val:   ValidateOneArg,
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["st_distance"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			g1, g2, err := toTwoGeometries(args)
+			if err != nil {
+				return err, false
+			}
+			return geo.Distance(g1, g2), true
+		},
+		val:   validateTwoArgs,
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["st_within"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			g1, g2, err := toTwoGeometries(args)
+			if err != nil {
+				return err, false
+			}
+			r, err := geo.Within(g1, g2)
+			if err != nil {
+				return err, false
+			}
+			return r, true
+		},
+		val:   validateTwoArgs,
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["st_buffer"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			g, err := toGeometry(args[0], 0)
+			if err != nil {
+				return err, false
+			}
+			distance, err := cast.ToFloat64(args[1], cast.CONVERT_SAMEKIND)
+			if err != nil {
+				return err, false
+			}
+			segments := bufferSegments
+			if len(args) > 2 {
+				segments, err = cast.ToInt(args[2], cast.CONVERT_SAMEKIND)
+				if err != nil {
+					return err, false
+				}
+			}
+			r, err := geo.Buffer(g, distance, segments)
+			if err != nil {
+				return err, false
+			}
+			return r.GeoJSON(), true
+		},
+		val: func(ctx api.FunctionContext, args []ast.Expr) error {
+			if len(args) != 2 && len(args) != 3 {
+				return fmt.Errorf("Expect 2 or 3 arguments but found %d.", len(args))
+			}
+			if ast.IsStringArg(args[1]) || ast.IsTimeArg(args[1]) || ast.IsBooleanArg(args[1]) {
+				return ProduceErrInfo(1, "number - float or int")
+			}
+			if len(args) == 3 && (ast.IsFloatArg(args[2]) || ast.IsStringArg(args[2]) || ast.IsTimeArg(args[2]) || ast.IsBooleanArg(args[2])) {
+				return ProduceErrInfo(2, "int")
+			}
+			return nil
+		},
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["st_bearing"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			g1, g2, err := toTwoGeometries(args)
+			if err != nil {
+				return err, false
+			}
+			if g1.Type != geo.TypePoint || g2.Type != geo.TypePoint {
+				return fmt.Errorf("the arguments should be points but got %s and %s", g1.Type, g2.Type), false
+			}
+			return geo.Bearing(g1.Points[0], g2.Points[0]), true
+		},
+		val:   validateTwoArgs,
+		check: returnNilIfHasAnyNil,
+	}
+}
+
+func validateTwoArgs(_ api.FunctionContext, args []ast.Expr) error {
+	return ValidateLen(2, len(args))
+}
+
+func toGeometry(v interface{}, index int) (*geo.Geometry, error) {
+	g, err := geo.Parse(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid geometry for parameter %d: %v", index+1, err)
+	}
+	return g, nil
+}
+
+func toTwoGeometries(args []interface{}) (*geo.Geometry, *geo.Geometry, error) {
+	g1, err := toGeometry(args[0], 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	g2, err := toGeometry(args[1], 1)
+	if err != nil {
+		return nil, nil, err
+	}
+	return g1, g2, nil
+}
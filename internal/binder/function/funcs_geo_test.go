@@ -0,0 +1,212 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package function
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	kctx "github.com/lf-edge/ekuiper/v2/internal/topo/context"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/state"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+func TestGeoFunctions(t *testing.T) {
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	fence := "POLYGON ((116.3 39.8, 116.5 39.8, 116.5 40, 116.3 40, 116.3 39.8))"
+	point := map[string]interface{}{"type": "Point", "coordinates": []interface{}{116.4, 39.9}}
+	tests := []struct {
+		name   string
+		args   []interface{}
+		result interface{}
+		delta  float64
+	}{
+		{
+			name:   "st_point",
+			args:   []interface{}{116.4, 39.9},
+			result: point,
+		},
+		{
+			name:   "st_point",
+			args:   []interface{}{"a", 39.9},
+			result: errors.New("cannot convert string(a) to float64"),
+		},
+		{
+			name:   "st_geomfromtext",
+			args:   []interface{}{"POINT (116.4 39.9)"},
+			result: point,
+		},
+		{
+			name:   "st_geomfromtext",
+			args:   []interface{}{"POINT (116.4)"},
+			result: errors.New("invalid WKT POINT (116.4): invalid coordinate at position 12"),
+		},
+		{
+			name:   "st_geomfromgeojson",
+			args:   []interface{}{`{"type":"Point","coordinates":[116.4,39.9]}`},
+			result: point,
+		},
+		{
+			name:   "st_geomfromgeojson",
+			args:   []interface{}{map[string]interface{}{"type": "Feature", "geometry": point}},
+			result: point,
+		},
+		{
+			name:   "st_astext",
+			args:   []interface{}{point},
+			result: "POINT (116.4 39.9)",
+		},
+		{
+			name:   "st_astext",
+			args:   []interface{}{[]interface{}{116.4, 39.9}},
+			result: "POINT (116.4 39.9)",
+		},
+		{
+			name:   "st_asgeojson",
+			args:   []interface{}{"POINT (116.4 39.9)"},
+			result: `{"coordinates":[116.4,39.9],"type":"Point"}`,
+		},
+		{
+			name:   "st_asgeojson",
+			args:   []interface{}{true},
+			result: errors.New("invalid geometry for parameter 1: cannot convert true(bool) to geometry"),
+		},
+		{
+			name:   "st_distance",
+			args:   []interface{}{"POINT (116.4074 39.9042)", []interface{}{121.4737, 31.2304}},
+			result: 1067000.0,
+			delta:  1000,
+		},
+		{
+			name:   "st_distance",
+			args:   []interface{}{point, fence},
+			result: 0.0,
+		},
+		{
+			name:   "st_within",
+			args:   []interface{}{point, fence},
+			result: true,
+		},
+		{
+			name:   "st_within",
+			args:   []interface{}{"POINT (117 39.9)", fence},
+			result: false,
+		},
+		{
+			name:   "st_within",
+			args:   []interface{}{fence, point},
+			result: errors.New("within only supports point geometry as the first argument but got Polygon"),
+		},
+		{
+			name:   "st_bearing",
+			args:   []interface{}{"POINT (0 0)", "POINT (1 0)"},
+			result: 90.0,
+			delta:  1e-9,
+		},
+		{
+			name:   "st_bearing",
+			args:   []interface{}{"POINT (0 0)", fence},
+			result: errors.New("the arguments should be points but got Point and Polygon"),
+		},
+		{
+			name:   "st_buffer",
+			args:   []interface{}{fence, 100},
+			result: errors.New("buffer only supports point geometry but got Polygon"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, ok := builtins[tt.name]
+			require.True(t, ok)
+			result, ok := f.exec(fctx, tt.args)
+			if err, isErr := tt.result.(error); isErr {
+				require.False(t, ok)
+				require.EqualError(t, result.(error), err.Error())
+				return
+			}
+			require.True(t, ok, result)
+			if tt.delta > 0 {
+				assert.InDelta(t, tt.result, result, tt.delta)
+			} else {
+				assert.Equal(t, tt.result, result)
+			}
+		})
+	}
+
+	f := builtins["st_buffer"]
+	result, ok := f.exec(fctx, []interface{}{point, 500, 8})
+	require.True(t, ok)
+	ring := result.(map[string]interface{})["coordinates"].([]interface{})[0].([]interface{})
+	require.Len(t, ring, 9)
+	within, ok := builtins["st_within"].exec(fctx, []interface{}{"POINT (116.401 39.901)", result})
+	require.True(t, ok)
+	require.Equal(t, true, within)
+}
+
+func TestGeoFunctionsValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		args []ast.Expr
+		err  string
+	}{
+		{
+			name: "st_point",
+			args: []ast.Expr{&ast.NumberLiteral{Val: 1}},
+			err:  "Expect 2 arguments but found 1.",
+		},
+		{
+			name: "st_geomfromtext",
+			args: []ast.Expr{&ast.IntegerLiteral{Val: 1}},
+			err:  "Expect string type for parameter 1",
+		},
+		{
+			name: "st_within",
+			args: []ast.Expr{&ast.FieldRef{Name: "a"}},
+			err:  "Expect 2 arguments but found 1.",
+		},
+		{
+			name: "st_buffer",
+			args: []ast.Expr{&ast.FieldRef{Name: "a"}, &ast.StringLiteral{Val: "a"}},
+			err:  "Expect number - float or int type for parameter 2",
+		},
+		{
+			name: "st_buffer",
+			args: []ast.Expr{&ast.FieldRef{Name: "a"}},
+			err:  "Expect 2 or 3 arguments but found 1.",
+		},
+		{
+			name: "st_buffer",
+			args: []ast.Expr{&ast.FieldRef{Name: "a"}, &ast.IntegerLiteral{Val: 10}, &ast.IntegerLiteral{Val: 16}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := builtins[tt.name].val(nil, tt.args)
+			if tt.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
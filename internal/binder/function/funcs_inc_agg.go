@@ -16,10 +16,12 @@ package function
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 	"github.com/pingcap/failpoint"
 
+	"github.com/lf-edge/ekuiper/v2/internal/binder"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 )
@@ -35,9 +37,84 @@ var supportedIncAggFunc = map[string]struct{}{
 	"last_value": {},
 }
 
+// IncAggFunction is an extension aggregate function which can be calculated incrementally.
+// ExecIncremental folds the current row into the state saved in the function context and returns the current result.
+type IncAggFunction interface {
+	IsIncremental() bool
+	ExecIncremental(ctx api.FunctionContext, args []interface{}) (interface{}, bool)
+}
+
+// IncAggMergeFunction is an incremental aggregate function whose partial states can be merged.
+// MergeIncremental combines the states saved by ExecIncremental in the partial function contexts, saves the merged state in ctx and returns its result.
+type IncAggMergeFunction interface {
+	IsMergeable() bool
+	MergeIncremental(ctx api.FunctionContext, partials []api.FunctionContext) (interface{}, bool)
+}
+
 func IsSupportedIncAgg(name string) bool {
 	_, ok := supportedIncAggFunc[name]
-	return ok
+	if ok {
+		return true
+	}
+	for _, sf := range funcFactories {
+		if incF, ok := sf.(binder.IncAggFuncFactory); ok && incF.IsIncAggFunction(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSupportedIncAggMerge tells if the partial states of the inc_ function can be merged. Only the extension functions support it.
+func IsSupportedIncAggMerge(incName string) bool {
+	name, ok := strings.CutPrefix(incName, incAggPrefix)
+	if !ok {
+		return false
+	}
+	for _, sf := range funcFactories {
+		if incF, ok := sf.(binder.IncAggFuncFactory); ok && incF.IsIncAggMergeFunction(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func extIncAggFunc(name string) (IncAggFunction, bool) {
+	f, _ := Function(name)
+	if f == nil || !f.IsAggregate() {
+		return nil, false
+	}
+	incF, ok := f.(IncAggFunction)
+	if !ok || !incF.IsIncremental() {
+		return nil, false
+	}
+	return incF, true
+}
+
+// incAggFuncWrapper wraps the extension incremental aggregate function as the scalar inc_ function
+type incAggFuncWrapper struct {
+	api.Function
+	incF IncAggFunction
+}
+
+func (w *incAggFuncWrapper) Exec(ctx api.FunctionContext, args []any) (any, bool) {
+	return w.incF.ExecIncremental(ctx, args)
+}
+
+func (w *incAggFuncWrapper) IsMergeable() bool {
+	m, ok := w.incF.(IncAggMergeFunction)
+	return ok && m.IsMergeable()
+}
+
+func (w *incAggFuncWrapper) MergeIncremental(ctx api.FunctionContext, partials []api.FunctionContext) (any, bool) {
+	m, ok := w.incF.(IncAggMergeFunction)
+	if !ok || !m.IsMergeable() {
+		return fmt.Errorf("the incremental aggregate function does not support merging"), false
+	}
+	return m.MergeIncremental(ctx, partials)
+}
+
+func (w *incAggFuncWrapper) IsAggregate() bool {
+	return false
 }
 
 func registerIncAggFunc() {
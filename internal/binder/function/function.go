@@ -62,6 +62,7 @@ func init() {
 	registerDateTimeFunc()
 	registerGlobalAggFunc()
 	registerWindowFunc()
+	registerGeoFunc()
 }
 
 //var funcWithAsteriskSupportMap = map[string]string{
@@ -85,7 +86,10 @@ var windowFuncs = map[string]struct{}{
 	"row_number": {},
 }
 
-const AnalyticPrefix = "$$a"
+const (
+	AnalyticPrefix = "$$a"
+	incAggPrefix   = "inc_"
+)
 
 func IsWindowFunc(name string) bool {
 	_, ok := windowFuncs[name]
@@ -113,6 +117,12 @@ func (m *Manager) Function(name string) (api.Function, error) {
 	if ok {
 		return ff(), nil
 	}
+	// The incremental version of the extension aggregate function which is rewritten by the planner
+	if extName, ok := strings.CutPrefix(name, incAggPrefix); ok {
+		if incF, ok := extIncAggFunc(extName); ok {
+			return &incAggFuncWrapper{Function: incF.(api.Function), incF: incF}, nil
+		}
+	}
 	return nil, nil
 }
 
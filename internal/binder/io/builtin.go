@@ -18,6 +18,8 @@ import (
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
 	"github.com/lf-edge/ekuiper/v2/internal/binder"
+	"github.com/lf-edge/ekuiper/v2/internal/io/amqp"
+	"github.com/lf-edge/ekuiper/v2/internal/io/coap"
 	"github.com/lf-edge/ekuiper/v2/internal/io/file"
 	"github.com/lf-edge/ekuiper/v2/internal/io/http"
 	"github.com/lf-edge/ekuiper/v2/internal/io/http/httpserver"
@@ -25,9 +27,12 @@ import (
 	"github.com/lf-edge/ekuiper/v2/internal/io/mqtt"
 	"github.com/lf-edge/ekuiper/v2/internal/io/neuron"
 	"github.com/lf-edge/ekuiper/v2/internal/io/nexmark"
+	"github.com/lf-edge/ekuiper/v2/internal/io/opensearch"
+	"github.com/lf-edge/ekuiper/v2/internal/io/otlp"
 	"github.com/lf-edge/ekuiper/v2/internal/io/simulator"
 	"github.com/lf-edge/ekuiper/v2/internal/io/sink"
 	"github.com/lf-edge/ekuiper/v2/internal/io/sse"
+	"github.com/lf-edge/ekuiper/v2/internal/io/syslog"
 	"github.com/lf-edge/ekuiper/v2/internal/io/websocket"
 	plugin2 "github.com/lf-edge/ekuiper/v2/internal/plugin"
 	"github.com/lf-edge/ekuiper/v2/pkg/modules"
@@ -44,6 +49,10 @@ func init() {
 	modules.RegisterSource("websocket", func() api.Source { return websocket.GetSource() })
 	modules.RegisterSource("simulator", func() api.Source { return simulator.GetSource() })
 	modules.RegisterSource("nexmark", func() api.Source { return nexmark.GetSource() })
+	modules.RegisterSource("amqp", amqp.GetSource)
+	modules.RegisterSource("coap", coap.GetSource)
+	modules.RegisterSource("otlp", otlp.GetSource)
+	modules.RegisterSource("syslog", syslog.GetSource)
 
 	modules.RegisterSink("log", sink.NewLogSink)
 	modules.RegisterSink("logToMemory", sink.NewLogSinkToMemory)
@@ -55,16 +64,24 @@ func init() {
 	modules.RegisterSink("file", file.GetSink)
 	modules.RegisterSink("websocket", func() api.Sink { return websocket.GetSink() })
 	modules.RegisterSink("sse", func() api.Sink { return sse.GetSink() })
+	modules.RegisterSink("amqp", amqp.GetSink)
+	modules.RegisterSink("coap", coap.GetSink)
+	modules.RegisterSink("opensearch", opensearch.GetSink)
 
 	modules.RegisterLookupSource("memory", memory.GetLookupSource)
 	modules.RegisterLookupSource("httppull", http.GetLookUpSource)
 	modules.RegisterLookupSource("simulator", func() api.Source { return &simulator.SimulatorLookupSource{} })
+	modules.RegisterLookupSource("file", file.GetLookupSource)
 
 	modules.RegisterConnection("mqtt", mqtt.CreateConnection)
 	modules.RegisterConnection("nng", nng.CreateConnection)
 	modules.RegisterConnection("httppush", httpserver.CreateConnection)
 	modules.RegisterConnection("websocket", httpserver.CreateWebsocketConnection)
 	modules.RegisterConnection("sse", httpserver.CreateSSEConnection)
+	modules.RegisterConnection("amqp", amqp.CreateConnection)
+	modules.RegisterConnection("coap", coap.CreateConnection)
+	modules.RegisterConnection("coapserver", coap.CreateServerConnection)
+	modules.RegisterConnection("syslog", syslog.CreateConnection)
 }
 
 type Manager struct{}
@@ -19,10 +19,14 @@ package io
 import (
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
+	"github.com/lf-edge/ekuiper/v2/extensions/impl/cdc"
 	"github.com/lf-edge/ekuiper/v2/extensions/impl/image"
 	"github.com/lf-edge/ekuiper/v2/extensions/impl/influx"
 	"github.com/lf-edge/ekuiper/v2/extensions/impl/influx2"
 	"github.com/lf-edge/ekuiper/v2/extensions/impl/kafka"
+	"github.com/lf-edge/ekuiper/v2/extensions/impl/modbus"
+	"github.com/lf-edge/ekuiper/v2/extensions/impl/prometheus"
+	"github.com/lf-edge/ekuiper/v2/extensions/impl/s3"
 	sql2 "github.com/lf-edge/ekuiper/v2/extensions/impl/sql"
 	"github.com/lf-edge/ekuiper/v2/extensions/impl/video"
 	"github.com/lf-edge/ekuiper/v2/pkg/modules"
@@ -38,4 +42,11 @@ func init() {
 	modules.RegisterSource("sql", sql2.GetSource)
 	modules.RegisterLookupSource("sql", sql2.GetLookupSource)
 	modules.RegisterSink("sql", sql2.GetSink)
+	modules.RegisterSource("modbus", modbus.GetSource)
+	modules.RegisterSink("modbus", modbus.GetSink)
+	modules.RegisterSink("prometheus", prometheus.GetSink)
+	modules.RegisterSource("s3", s3.GetSource)
+	modules.RegisterSink("s3", s3.GetSink)
+	modules.RegisterSource("pgcdc", cdc.GetPgSource)
+	modules.RegisterLookupSource("pgcdc", cdc.GetPgLookupSource)
 }
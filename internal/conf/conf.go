@@ -284,6 +284,11 @@ func ValidateRuleOption(option *def.RuleOption) error {
 		Log.Warnf("lateTol is negative, set to 1 second")
 		errs = errors.Join(errs, errors.New("invalidLateTol:lateTol must be greater than 0"))
 	}
+	if option.StateTTL < 0 {
+		option.StateTTL = 0
+		Log.Warnf("stateTtl is negative, set to 0")
+		errs = errors.Join(errs, errors.New("invalidStateTtl:stateTtl must not be negative"))
+	}
 	if option.RestartStrategy != nil {
 		if option.RestartStrategy.Attempts < 0 {
 			option.RestartStrategy.Attempts = 0
@@ -0,0 +1,299 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package amqp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cert"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
+	"github.com/lf-edge/ekuiper/v2/pkg/syncx"
+)
+
+// channel is the subset of the amqp channel methods used by the source and sink
+type channel interface {
+	Qos(prefetchCount, prefetchSize int, global bool) error
+	Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error)
+	Ack(tag uint64, multiple bool) error
+	PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+	Close() error
+}
+
+// client is the amqp connection
+type client interface {
+	channel() (channel, error)
+	notifyClose() <-chan *amqp.Error
+	close() error
+}
+
+type amqpClient struct {
+	*amqp.Connection
+}
+
+func (c *amqpClient) channel() (channel, error) {
+	return c.Connection.Channel()
+}
+
+func (c *amqpClient) notifyClose() <-chan *amqp.Error {
+	return c.Connection.NotifyClose(make(chan *amqp.Error, 1))
+}
+
+func (c *amqpClient) close() error {
+	return c.Connection.Close()
+}
+
+// dialer is replaced in the tests
+var dialer = func(url string, config amqp.Config) (client, error) {
+	conn, err := amqp.DialConfig(url, config)
+	if err != nil {
+		return nil, err
+	}
+	return &amqpClient{Connection: conn}, nil
+}
+
+type connectionConf struct {
+	Server            string            `json:"server"`
+	Heartbeat         cast.DurationConf `json:"heartbeat"`
+	ReconnectInterval cast.DurationConf `json:"reconnectInterval"`
+}
+
+func newConnectionConf(props map[string]any) (*connectionConf, error) {
+	c := &connectionConf{
+		Heartbeat:         cast.DurationConf(10 * time.Second),
+		ReconnectInterval: cast.DurationConf(5 * time.Second),
+	}
+	if err := cast.MapToStruct(props, c); err != nil {
+		return nil, err
+	}
+	if c.Server == "" {
+		return nil, fmt.Errorf("server can not be empty")
+	}
+	if !strings.HasPrefix(c.Server, "amqp://") && !strings.HasPrefix(c.Server, "amqps://") {
+		return nil, fmt.Errorf("invalid server %s, should start with amqp:// or amqps://", c.Server)
+	}
+	if time.Duration(c.ReconnectInterval) <= 0 {
+		return nil, fmt.Errorf("reconnectInterval should be positive")
+	}
+	return c, nil
+}
+
+// Connection is a shared amqp connection. Each source and sink opens its own channel on it because the
+// deliveries are acked per channel. The amqp client does not reconnect by itself, so the connection
+// redials when it is closed by the broker.
+type Connection struct {
+	mu        syncx.Mutex
+	id        string
+	conf      *connectionConf
+	config    amqp.Config
+	cli       client
+	ready     chan struct{}
+	closed    atomic.Bool
+	status    atomic.Value
+	scHandler api.StatusChangeHandler
+}
+
+func CreateConnection(_ api.StreamContext) modules.Connection {
+	return &Connection{}
+}
+
+func (c *Connection) Provision(ctx api.StreamContext, conId string, props map[string]any) error {
+	cc, err := newConnectionConf(props)
+	if err != nil {
+		return err
+	}
+	tlsConf, err := cert.GenTLSConfig(ctx, props)
+	if err != nil {
+		return err
+	}
+	properties := amqp.NewConnectionProperties()
+	properties.SetClientConnectionName(fmt.Sprintf("ekuiper-%s", conId))
+	c.id = conId
+	c.conf = cc
+	c.config = amqp.Config{
+		Heartbeat:       time.Duration(cc.Heartbeat),
+		TLSClientConfig: tlsConf,
+		Properties:      properties,
+	}
+	c.ready = make(chan struct{})
+	c.status.Store(modules.ConnectionStatus{Status: api.ConnectionConnecting})
+	return nil
+}
+
+func (c *Connection) Dial(ctx api.StreamContext) error {
+	cli, err := dialer(c.conf.Server, c.config)
+	if err != nil {
+		return errorx.NewIOErr(fmt.Sprintf("found error when connecting for %s: %s", c.id, err))
+	}
+	c.onConnected(ctx, cli)
+	ctx.GetLogger().Infof("new amqp client created")
+	return nil
+}
+
+func (c *Connection) onConnected(ctx api.StreamContext, cli client) {
+	closeCh := cli.notifyClose()
+	c.mu.Lock()
+	c.cli = cli
+	close(c.ready)
+	c.mu.Unlock()
+	c.onStatusChange(ctx, api.ConnectionConnected, "")
+	go c.watch(ctx, closeCh)
+}
+
+// watch waits for the connection to be closed and redials until succeed or the connection is closed by user
+func (c *Connection) watch(ctx api.StreamContext, closeCh <-chan *amqp.Error) {
+	e := <-closeCh
+	if c.closed.Load() {
+		return
+	}
+	msg := "connection closed"
+	if e != nil {
+		msg = e.Error()
+	}
+	c.mu.Lock()
+	c.ready = make(chan struct{})
+	c.mu.Unlock()
+	c.onStatusChange(ctx, api.ConnectionDisconnected, msg)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Duration(c.conf.ReconnectInterval)):
+		}
+		if c.closed.Load() {
+			return
+		}
+		c.onStatusChange(ctx, api.ConnectionConnecting, "")
+		cli, err := dialer(c.conf.Server, c.config)
+		if err != nil {
+			c.onStatusChange(ctx, api.ConnectionDisconnected, err.Error())
+			continue
+		}
+		c.onConnected(ctx, cli)
+		return
+	}
+}
+
+// channel opens a channel of the current connection. It returns an io error if not connected.
+func (c *Connection) channel() (channel, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	select {
+	case <-c.ready:
+	default:
+		return nil, errorx.NewIOErr("amqp connection is not ready")
+	}
+	ch, err := c.cli.channel()
+	if err != nil {
+		return nil, errorx.NewIOErr(fmt.Sprintf("open amqp channel error: %v", err))
+	}
+	return ch, nil
+}
+
+// waitChannel waits for the connection to be ready and opens a channel
+func (c *Connection) waitChannel(ctx api.StreamContext) (channel, error) {
+	for {
+		c.mu.Lock()
+		ready := c.ready
+		c.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ready:
+		}
+		ch, err := c.channel()
+		if err == nil {
+			return ch, nil
+		}
+		ctx.GetLogger().Warnf("%v, retry later", err)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Duration(c.conf.ReconnectInterval)):
+		}
+	}
+}
+
+func (c *Connection) GetId(_ api.StreamContext) string {
+	return c.id
+}
+
+func (c *Connection) Status(_ api.StreamContext) modules.ConnectionStatus {
+	return c.status.Load().(modules.ConnectionStatus)
+}
+
+func (c *Connection) SetStatusChangeHandler(_ api.StreamContext, sch api.StatusChangeHandler) {
+	st := c.status.Load().(modules.ConnectionStatus)
+	sch(st.Status, st.ErrMsg)
+	c.mu.Lock()
+	c.scHandler = sch
+	c.mu.Unlock()
+}
+
+func (c *Connection) onStatusChange(ctx api.StreamContext, status, msg string) {
+	c.status.Store(modules.ConnectionStatus{Status: status, ErrMsg: msg})
+	c.mu.Lock()
+	handler := c.scHandler
+	c.mu.Unlock()
+	if handler != nil {
+		handler(status, msg)
+	}
+	ctx.GetLogger().Infof("amqp connection %s status changed to %s %s", c.id, status, msg)
+}
+
+func (c *Connection) Ping(_ api.StreamContext) error {
+	ch, err := c.channel()
+	if err != nil {
+		return err
+	}
+	return ch.Close()
+}
+
+func (c *Connection) Close(_ api.StreamContext) error {
+	c.closed.Store(true)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cli != nil {
+		if err := c.cli.close(); err != nil && !errors.Is(err, amqp.ErrClosed) {
+			return err
+		}
+	}
+	return nil
+}
+
+func ping(ctx api.StreamContext, props map[string]any) error {
+	c := &Connection{}
+	if err := c.Provision(ctx, "test", props); err != nil {
+		return err
+	}
+	if err := c.Dial(ctx); err != nil {
+		return err
+	}
+	return c.Close(ctx)
+}
+
+var (
+	_ modules.Connection     = &Connection{}
+	_ modules.StatefulDialer = &Connection{}
+)
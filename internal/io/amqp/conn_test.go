@@ -0,0 +1,226 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package amqp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
+)
+
+func init() {
+	modules.RegisterConnection("amqp", CreateConnection)
+}
+
+type published struct {
+	exchange string
+	key      string
+	msg      amqp.Publishing
+}
+
+type fakeChannel struct {
+	mu         sync.Mutex
+	deliveries chan amqp.Delivery
+	autoAck    bool
+	acks       []uint64
+	published  []published
+	publishErr error
+	closed     bool
+}
+
+func (f *fakeChannel) Qos(_, _ int, _ bool) error {
+	return nil
+}
+
+func (f *fakeChannel) Consume(_, _ string, autoAck, _, _, _ bool, _ amqp.Table) (<-chan amqp.Delivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.autoAck = autoAck
+	return f.deliveries, nil
+}
+
+func (f *fakeChannel) Ack(tag uint64, multiple bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !multiple {
+		return errors.New("should ack multiple")
+	}
+	f.acks = append(f.acks, tag)
+	return nil
+}
+
+func (f *fakeChannel) PublishWithContext(_ context.Context, exchange, key string, _, _ bool, msg amqp.Publishing) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.publishErr != nil {
+		return f.publishErr
+	}
+	f.published = append(f.published, published{exchange: exchange, key: key, msg: msg})
+	return nil
+}
+
+func (f *fakeChannel) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	return nil
+}
+
+func (f *fakeChannel) getAcks() []uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]uint64(nil), f.acks...)
+}
+
+// fakeClient opens a new channel each time and records them
+type fakeClient struct {
+	mu       sync.Mutex
+	channels []*fakeChannel
+	closeCh  chan *amqp.Error
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{closeCh: make(chan *amqp.Error, 1)}
+}
+
+func (f *fakeClient) channel() (channel, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ch := &fakeChannel{deliveries: make(chan amqp.Delivery, 10)}
+	f.channels = append(f.channels, ch)
+	return ch, nil
+}
+
+func (f *fakeClient) lastChannel() *fakeChannel {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.channels) == 0 {
+		return nil
+	}
+	return f.channels[len(f.channels)-1]
+}
+
+func (f *fakeClient) notifyClose() <-chan *amqp.Error {
+	return f.closeCh
+}
+
+func (f *fakeClient) close() error {
+	close(f.closeCh)
+	return nil
+}
+
+// mockDialer replaces the dialer to return the clients in order
+func mockDialer(t *testing.T, clients ...*fakeClient) {
+	old := dialer
+	var mu sync.Mutex
+	dialer = func(_ string, _ amqp.Config) (client, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(clients) == 0 {
+			return nil, errors.New("connection refused")
+		}
+		c := clients[0]
+		clients = clients[1:]
+		return c, nil
+	}
+	t.Cleanup(func() { dialer = old })
+}
+
+func TestConnectionConf(t *testing.T) {
+	tests := []struct {
+		name  string
+		props map[string]any
+		err   string
+	}{
+		{
+			name:  "no server",
+			props: map[string]any{},
+			err:   "server can not be empty",
+		},
+		{
+			name:  "invalid server",
+			props: map[string]any{"server": "tcp://localhost:5672"},
+			err:   "invalid server tcp://localhost:5672, should start with amqp:// or amqps://",
+		},
+		{
+			name:  "invalid reconnect interval",
+			props: map[string]any{"server": "amqp://localhost:5672", "reconnectInterval": "-1s"},
+			err:   "reconnectInterval should be positive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newConnectionConf(tt.props)
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	cli1, cli2 := newFakeClient(), newFakeClient()
+	mockDialer(t, cli1, cli2)
+	ctx, cancel := mockContext.NewMockContext("rule1", "op1").WithCancel()
+	defer cancel()
+	c := &Connection{}
+	require.NoError(t, c.Provision(ctx, "test", map[string]any{"server": "amqp://localhost:5672", "reconnectInterval": "10ms"}))
+	var (
+		mu       sync.Mutex
+		statuses []string
+	)
+	c.SetStatusChangeHandler(ctx, func(status string, _ string) {
+		mu.Lock()
+		statuses = append(statuses, status)
+		mu.Unlock()
+	})
+	_, err := c.channel()
+	require.EqualError(t, err, "amqp connection is not ready")
+	require.NoError(t, c.Dial(ctx))
+	require.NoError(t, c.Ping(ctx))
+
+	cli1.closeCh <- &amqp.Error{Code: amqp.ConnectionForced, Reason: "broker shutdown"}
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(statuses) >= 3
+	}, time.Second, 10*time.Millisecond)
+	ch, err := c.waitChannel(ctx)
+	require.NoError(t, err)
+	require.Same(t, cli2.lastChannel(), ch)
+	require.Eventually(t, func() bool {
+		return c.Status(ctx).Status == api.ConnectionConnected
+	}, time.Second, 10*time.Millisecond)
+	mu.Lock()
+	require.Equal(t, []string{api.ConnectionConnecting, api.ConnectionConnected, api.ConnectionDisconnected, api.ConnectionConnecting, api.ConnectionConnected}, statuses)
+	mu.Unlock()
+	require.NoError(t, c.Close(ctx))
+}
+
+func TestPingErr(t *testing.T) {
+	require.NoError(t, connection.InitConnectionManager4Test())
+	mockDialer(t)
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	err := GetSource().(*Source).Ping(ctx, map[string]any{"server": "amqp://localhost:5672"})
+	require.EqualError(t, err, "found error when connecting for test: connection refused")
+}
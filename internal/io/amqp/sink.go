@@ -0,0 +1,132 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package amqp
+
+import (
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/util"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+)
+
+type sinkConf struct {
+	Exchange    string            `json:"exchange"`
+	RoutingKey  string            `json:"routingKey"`
+	ContentType string            `json:"contentType"`
+	Persistent  bool              `json:"persistent"`
+	Mandatory   bool              `json:"mandatory"`
+	Headers     map[string]string `json:"headers"`
+}
+
+// Sink publishes the encoded result to an exchange. The routing key and the header values support the data template.
+type Sink struct {
+	conf  *sinkConf
+	props map[string]any
+	conId string
+	conn  *Connection
+	ch    channel
+}
+
+func (s *Sink) Provision(_ api.StreamContext, props map[string]any) error {
+	if _, err := newConnectionConf(props); err != nil {
+		return err
+	}
+	c := &sinkConf{}
+	if err := cast.MapToStruct(props, c); err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	// The default exchange routes by the queue name, so the routing key is required
+	if c.Exchange == "" && c.RoutingKey == "" {
+		return fmt.Errorf("routingKey is required for the default exchange")
+	}
+	s.conf = c
+	s.props = props
+	return nil
+}
+
+func (s *Sink) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	conn, conId, err := connect(ctx, fmt.Sprintf("%s-%s-amqp-sink", ctx.GetRuleId(), ctx.GetOpId()), s.props, sch)
+	s.conId = conId
+	s.conn = conn
+	return err
+}
+
+func (s *Sink) Collect(ctx api.StreamContext, item api.RawTuple) error {
+	key := s.conf.RoutingKey
+	msg := amqp.Publishing{
+		ContentType: s.conf.ContentType,
+		Body:        item.Raw(),
+	}
+	if s.conf.Persistent {
+		msg.DeliveryMode = amqp.Persistent
+	}
+	if len(s.conf.Headers) > 0 {
+		msg.Headers = make(amqp.Table, len(s.conf.Headers))
+		for k, v := range s.conf.Headers {
+			msg.Headers[k] = v
+		}
+	}
+	// If the props support dynamic props(template), planner will guarantee the result has the parsed dynamic props
+	if dp, ok := item.(api.HasDynamicProps); ok {
+		if nk, ok := dp.DynamicProps(key); ok {
+			key = nk
+		}
+		for k, v := range s.conf.Headers {
+			if nv, ok := dp.DynamicProps(v); ok {
+				msg.Headers[k] = nv
+			}
+		}
+	}
+	if s.ch == nil {
+		ch, err := s.conn.channel()
+		if err != nil {
+			return err
+		}
+		s.ch = ch
+	}
+	if err := s.ch.PublishWithContext(ctx, s.conf.Exchange, key, s.conf.Mandatory, false, msg); err != nil {
+		// The channel is closed on error, reopen it in the next collect
+		_ = s.ch.Close()
+		s.ch = nil
+		return errorx.NewIOErr(fmt.Sprintf("publish to exchange %s with routing key %s error: %v", s.conf.Exchange, key, err))
+	}
+	return nil
+}
+
+func (s *Sink) Ping(ctx api.StreamContext, props map[string]any) error {
+	return ping(ctx, props)
+}
+
+func (s *Sink) Close(ctx api.StreamContext) error {
+	if s.ch != nil {
+		_ = s.ch.Close()
+		s.ch = nil
+	}
+	return connection.DetachConnection(ctx, s.conId)
+}
+
+func GetSink() api.Sink {
+	return &Sink{}
+}
+
+var (
+	_ api.BytesCollector = &Sink{}
+	_ util.PingableConn  = &Sink{}
+)
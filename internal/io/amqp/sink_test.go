@@ -0,0 +1,93 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package amqp
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/mock"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func TestSinkProvisionErr(t *testing.T) {
+	ctx := mockContext.NewMockContext("1", "2")
+	err := GetSink().Provision(ctx, map[string]any{"server": "amqp://localhost:5672"})
+	require.EqualError(t, err, "routingKey is required for the default exchange")
+}
+
+func TestSinkCollect(t *testing.T) {
+	require.NoError(t, connection.InitConnectionManager4Test())
+	cli := newFakeClient()
+	mockDialer(t, cli)
+	require.NoError(t, mock.RunBytesSinkCollect(GetSink().(api.BytesCollector), [][]byte{[]byte("hello")}, map[string]any{
+		"server":      "amqp://localhost:5672",
+		"exchange":    "ex",
+		"routingKey":  "sensor.a",
+		"contentType": "text/plain",
+		"persistent":  true,
+		"headers":     map[string]any{"source": "ekuiper"},
+	}))
+	ch := cli.lastChannel()
+	require.Equal(t, []published{{
+		exchange: "ex",
+		key:      "sensor.a",
+		msg: amqp.Publishing{
+			ContentType:  "text/plain",
+			DeliveryMode: amqp.Persistent,
+			Headers:      amqp.Table{"source": "ekuiper"},
+			Body:         []byte("hello"),
+		},
+	}}, ch.published)
+	require.True(t, ch.closed)
+}
+
+func TestSinkDynamicProps(t *testing.T) {
+	require.NoError(t, connection.InitConnectionManager4Test())
+	cli := newFakeClient()
+	mockDialer(t, cli)
+	ctx := mockContext.NewMockContext("ruleSink", "op1")
+	s := GetSink().(api.BytesCollector)
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"server":     "amqp://localhost:5672",
+		"exchange":   "ex",
+		"routingKey": "sensor.{{.device}}",
+		"headers":    map[string]any{"device": "{{.device}}"},
+	}))
+	require.NoError(t, s.Connect(ctx, func(string, string) {}))
+	tuple := &xsql.RawTuple{Rawdata: []byte("a"), Props: map[string]string{"sensor.{{.device}}": "sensor.d1", "{{.device}}": "d1"}}
+	require.NoError(t, s.Collect(ctx, tuple))
+	ch := cli.lastChannel()
+	require.Len(t, ch.published, 1)
+	require.Equal(t, "sensor.d1", ch.published[0].key)
+	require.Equal(t, amqp.Table{"device": "d1"}, ch.published[0].msg.Headers)
+
+	// The channel is reopened after the publish error
+	ch.publishErr = errors.New("channel closed")
+	err := s.Collect(ctx, tuple)
+	require.EqualError(t, err, "publish to exchange ex with routing key sensor.d1 error: channel closed")
+	require.True(t, errorx.IsIOError(err))
+	require.NoError(t, s.Collect(ctx, tuple))
+	require.NotSame(t, ch, cli.lastChannel())
+	require.Len(t, cli.lastChannel().published, 1)
+	require.NoError(t, s.Close(ctx))
+}
@@ -0,0 +1,261 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package amqp
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/util"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+	"github.com/lf-edge/ekuiper/v2/pkg/model"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+type sourceConf struct {
+	Queue         string `json:"datasource"`
+	ConsumerTag   string `json:"consumerTag"`
+	PrefetchCount int    `json:"prefetchCount"`
+}
+
+// pendingAck is the last delivery tag of a checkpoint which is not completed yet
+type pendingAck struct {
+	checkpointId int64
+	chSeq        uint64
+	tag          uint64
+}
+
+// Source consumes a queue. When the rule qos is at least once, the deliveries are acked only after the
+// checkpoint that contains them completes. Otherwise, the deliveries are acked automatically.
+type Source struct {
+	conf  *sourceConf
+	props map[string]any
+	conId string
+	conn  *Connection
+
+	ackOnCheckpoint bool
+	mu              sync.Mutex
+	ch              channel
+	// chSeq increases when the channel is reopened. The delivery tags are only valid in their channel
+	chSeq uint64
+	// lastTag is the tag of the last ingested delivery of the current channel
+	lastTag uint64
+	// ackedTag is the tag of the last acked delivery of the current channel
+	ackedTag uint64
+	pending  []pendingAck
+}
+
+func (s *Source) Provision(_ api.StreamContext, props map[string]any) error {
+	if _, err := newConnectionConf(props); err != nil {
+		return err
+	}
+	c := &sourceConf{}
+	if err := cast.MapToStruct(props, c); err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	if c.Queue == "" {
+		return fmt.Errorf("queue is required")
+	}
+	if c.PrefetchCount < 0 {
+		return fmt.Errorf("prefetchCount should not be negative")
+	}
+	s.conf = c
+	s.props = props
+	return nil
+}
+
+func (s *Source) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	conn, conId, err := connect(ctx, fmt.Sprintf("%s-%s-amqp-source", ctx.GetRuleId(), ctx.GetOpId()), s.props, sch)
+	s.conId = conId
+	s.conn = conn
+	return err
+}
+
+func (s *Source) EnableCheckpoint(ctx api.StreamContext) {
+	ctx.GetLogger().Infof("amqp source acks the deliveries of queue %s on checkpoint", s.conf.Queue)
+	s.ackOnCheckpoint = true
+}
+
+// Subscribe consumes the queue in a goroutine. If the channel is closed, it reopens the channel when
+// the connection is ready again. The broker redelivers the unacked deliveries of the closed channel.
+func (s *Source) Subscribe(ctx api.StreamContext, ingest api.BytesIngest, ingestError api.ErrorIngest) error {
+	go func() {
+		for {
+			deliveries, err := s.consume(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				ingestError(ctx, err)
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(time.Duration(s.conn.conf.ReconnectInterval)):
+				}
+				continue
+			}
+			if !s.receive(ctx, deliveries, ingest) {
+				return
+			}
+			ctx.GetLogger().Warnf("amqp channel of queue %s is closed, reconsume", s.conf.Queue)
+		}
+	}()
+	return nil
+}
+
+func (s *Source) consume(ctx api.StreamContext) (<-chan amqp.Delivery, error) {
+	ch, err := s.conn.waitChannel(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if s.conf.PrefetchCount > 0 {
+		if err := ch.Qos(s.conf.PrefetchCount, 0, false); err != nil {
+			_ = ch.Close()
+			return nil, fmt.Errorf("set prefetch count error: %v", err)
+		}
+	}
+	deliveries, err := ch.Consume(s.conf.Queue, s.conf.ConsumerTag, !s.ackOnCheckpoint, false, false, false, nil)
+	if err != nil {
+		_ = ch.Close()
+		return nil, fmt.Errorf("consume queue %s error: %v", s.conf.Queue, err)
+	}
+	s.mu.Lock()
+	s.ch = ch
+	s.chSeq++
+	s.lastTag = 0
+	s.ackedTag = 0
+	s.mu.Unlock()
+	return deliveries, nil
+}
+
+// receive ingests the deliveries until the channel is closed. It returns false if the rule is stopped.
+func (s *Source) receive(ctx api.StreamContext, deliveries <-chan amqp.Delivery, ingest api.BytesIngest) bool {
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case d, ok := <-deliveries:
+			if !ok {
+				return true
+			}
+			ingest(ctx, d.Body, deliveryMeta(d), timex.GetNow())
+			if s.ackOnCheckpoint {
+				s.mu.Lock()
+				s.lastTag = d.DeliveryTag
+				s.mu.Unlock()
+			}
+		}
+	}
+}
+
+func deliveryMeta(d amqp.Delivery) map[string]any {
+	meta := map[string]any{
+		"exchange":    d.Exchange,
+		"routingKey":  d.RoutingKey,
+		"redelivered": d.Redelivered,
+	}
+	if d.ContentType != "" {
+		meta["contentType"] = d.ContentType
+	}
+	if d.MessageId != "" {
+		meta["messageId"] = d.MessageId
+	}
+	if d.CorrelationId != "" {
+		meta["correlationId"] = d.CorrelationId
+	}
+	if len(d.Headers) > 0 {
+		meta["headers"] = map[string]any(d.Headers)
+	}
+	return meta
+}
+
+func (s *Source) OnCheckpoint(_ api.StreamContext, checkpointId int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// No new deliveries since the last checkpoint. Acking a tag twice closes the channel
+	if s.lastTag == s.ackedTag {
+		return
+	}
+	if n := len(s.pending); n > 0 && s.pending[n-1].chSeq == s.chSeq && s.pending[n-1].tag == s.lastTag {
+		return
+	}
+	s.pending = append(s.pending, pendingAck{checkpointId: checkpointId, chSeq: s.chSeq, tag: s.lastTag})
+}
+
+// OnCheckpointComplete acks all the deliveries until the last one of the checkpoint. The pending acks of the
+// previous checkpoints are acked too because the completed checkpoint contains them.
+func (s *Source) OnCheckpointComplete(ctx api.StreamContext, checkpointId int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := 0
+	var last *pendingAck
+	for ; i < len(s.pending) && s.pending[i].checkpointId <= checkpointId; i++ {
+		last = &s.pending[i]
+	}
+	s.pending = s.pending[i:]
+	if last == nil {
+		return
+	}
+	// The deliveries of the closed channel are redelivered by the broker
+	if last.chSeq != s.chSeq || s.ch == nil {
+		return
+	}
+	if err := s.ch.Ack(last.tag, true); err != nil {
+		ctx.GetLogger().Warnf("ack deliveries until %d of queue %s error: %v", last.tag, s.conf.Queue, err)
+		return
+	}
+	s.ackedTag = last.tag
+}
+
+func (s *Source) Ping(ctx api.StreamContext, props map[string]any) error {
+	return ping(ctx, props)
+}
+
+func (s *Source) Close(ctx api.StreamContext) error {
+	s.mu.Lock()
+	if s.ch != nil {
+		_ = s.ch.Close()
+		s.ch = nil
+	}
+	s.mu.Unlock()
+	return connection.DetachConnection(ctx, s.conId)
+}
+
+func connect(ctx api.StreamContext, refId string, props map[string]any, sch api.StatusChangeHandler) (*Connection, string, error) {
+	cw, err := connection.FetchConnection(ctx, refId, "amqp", props, sch)
+	if err != nil {
+		return nil, "", err
+	}
+	conn, err := cw.Wait(ctx)
+	if conn == nil {
+		return nil, cw.ID, fmt.Errorf("amqp client not ready: %v", err)
+	}
+	return conn.(*Connection), cw.ID, err
+}
+
+func GetSource() api.Source {
+	return &Source{}
+}
+
+var (
+	_ api.BytesSource       = &Source{}
+	_ model.CheckpointAware = &Source{}
+	_ util.PingableConn     = &Source{}
+)
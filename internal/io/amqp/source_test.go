@@ -0,0 +1,165 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package amqp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func TestSourceProvisionErr(t *testing.T) {
+	ctx := mockContext.NewMockContext("1", "2")
+	tests := []struct {
+		name  string
+		props map[string]any
+		err   string
+	}{
+		{
+			name:  "no server",
+			props: map[string]any{"datasource": "q"},
+			err:   "server can not be empty",
+		},
+		{
+			name:  "no queue",
+			props: map[string]any{"server": "amqp://localhost:5672"},
+			err:   "queue is required",
+		},
+		{
+			name:  "invalid prefetch",
+			props: map[string]any{"server": "amqp://localhost:5672", "datasource": "q", "prefetchCount": -1},
+			err:   "prefetchCount should not be negative",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.EqualError(t, GetSource().Provision(ctx, tt.props), tt.err)
+		})
+	}
+}
+
+func TestSourceAutoAck(t *testing.T) {
+	require.NoError(t, connection.InitConnectionManager4Test())
+	cli := newFakeClient()
+	mockDialer(t, cli)
+	ctx, cancel := mockContext.NewMockContext("rule1", "op1").WithCancel()
+	defer cancel()
+	src := GetSource().(*Source)
+	require.NoError(t, src.Provision(ctx, map[string]any{"server": "amqp://localhost:5672", "datasource": "q"}))
+	require.NoError(t, src.Connect(ctx, func(string, string) {}))
+	result := make(chan []byte, 10)
+	metas := make(chan map[string]any, 10)
+	require.NoError(t, src.Subscribe(ctx, func(_ api.StreamContext, payload []byte, meta map[string]any, _ time.Time) {
+		result <- payload
+		metas <- meta
+	}, func(_ api.StreamContext, err error) {
+		t.Error(err)
+	}))
+	require.Eventually(t, func() bool { return cli.lastChannel() != nil }, time.Second, 10*time.Millisecond)
+	ch := cli.lastChannel()
+	ch.deliveries <- amqp.Delivery{DeliveryTag: 1, Exchange: "ex", RoutingKey: "a.b", ContentType: "application/json", Headers: amqp.Table{"k": "v"}, Body: []byte(`{"a":1}`)}
+	require.Equal(t, []byte(`{"a":1}`), <-result)
+	require.Equal(t, map[string]any{
+		"exchange":    "ex",
+		"routingKey":  "a.b",
+		"redelivered": false,
+		"contentType": "application/json",
+		"headers":     map[string]any{"k": "v"},
+	}, <-metas)
+	ch.mu.Lock()
+	require.True(t, ch.autoAck)
+	ch.mu.Unlock()
+	require.NoError(t, src.Close(ctx))
+}
+
+func TestSourceAckOnCheckpoint(t *testing.T) {
+	require.NoError(t, connection.InitConnectionManager4Test())
+	cli := newFakeClient()
+	mockDialer(t, cli)
+	ctx, cancel := mockContext.NewMockContext("rule1", "op1").WithCancel()
+	defer cancel()
+	src := GetSource().(*Source)
+	require.NoError(t, src.Provision(ctx, map[string]any{"server": "amqp://localhost:5672", "datasource": "q", "reconnectInterval": "10ms"}))
+	require.NoError(t, src.Connect(ctx, func(string, string) {}))
+	src.EnableCheckpoint(ctx)
+	result := make(chan []byte, 10)
+	require.NoError(t, src.Subscribe(ctx, func(_ api.StreamContext, payload []byte, _ map[string]any, _ time.Time) {
+		result <- payload
+	}, func(_ api.StreamContext, err error) {
+		t.Error(err)
+	}))
+	require.Eventually(t, func() bool { return cli.lastChannel() != nil }, time.Second, 10*time.Millisecond)
+	ch := cli.lastChannel()
+	ch.mu.Lock()
+	require.False(t, ch.autoAck)
+	ch.mu.Unlock()
+
+	deliver := func(ch *fakeChannel, tag uint64) {
+		ch.deliveries <- amqp.Delivery{DeliveryTag: tag, Body: []byte{byte(tag)}}
+		require.Equal(t, []byte{byte(tag)}, <-result)
+		require.Eventually(t, func() bool {
+			src.mu.Lock()
+			defer src.mu.Unlock()
+			return src.lastTag == tag
+		}, time.Second, 10*time.Millisecond)
+	}
+
+	deliver(ch, 1)
+	deliver(ch, 2)
+	src.OnCheckpoint(ctx, 100)
+	deliver(ch, 3)
+	require.Empty(t, ch.getAcks())
+	// Only the deliveries before the checkpoint barrier are acked
+	src.OnCheckpointComplete(ctx, 100)
+	require.Equal(t, []uint64{2}, ch.getAcks())
+
+	// The completed checkpoint acks the previous pending checkpoints too
+	src.OnCheckpoint(ctx, 200)
+	deliver(ch, 4)
+	src.OnCheckpoint(ctx, 300)
+	src.OnCheckpointComplete(ctx, 300)
+	require.Equal(t, []uint64{2, 4}, ch.getAcks())
+	src.OnCheckpointComplete(ctx, 200)
+	// No new deliveries, do not ack again
+	src.OnCheckpoint(ctx, 400)
+	src.OnCheckpointComplete(ctx, 400)
+	require.Equal(t, []uint64{2, 4}, ch.getAcks())
+
+	// The channel is closed, the pending acks of the old channel are dropped
+	deliver(ch, 5)
+	src.OnCheckpoint(ctx, 500)
+	close(ch.deliveries)
+	require.Eventually(t, func() bool { return cli.lastChannel() != ch }, time.Second, 10*time.Millisecond)
+	ch2 := cli.lastChannel()
+	require.Eventually(t, func() bool {
+		src.mu.Lock()
+		defer src.mu.Unlock()
+		return src.ch == ch2
+	}, time.Second, 10*time.Millisecond)
+	src.OnCheckpointComplete(ctx, 500)
+	require.Equal(t, []uint64{2, 4}, ch.getAcks())
+	require.Empty(t, ch2.getAcks())
+	deliver(ch2, 1)
+	src.OnCheckpoint(ctx, 600)
+	src.OnCheckpointComplete(ctx, 600)
+	require.Equal(t, []uint64{1}, ch2.getAcks())
+	require.NoError(t, src.Close(ctx))
+}
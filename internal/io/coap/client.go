@@ -0,0 +1,552 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coap
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/pion/dtls/v3"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
+)
+
+type clientConf struct {
+	Server            string            `json:"server"`
+	AckTimeout        cast.DurationConf `json:"ackTimeout"`
+	MaxRetransmit     int               `json:"maxRetransmit"`
+	ReconnectInterval cast.DurationConf `json:"reconnectInterval"`
+}
+
+func newClientConf(props map[string]any) (*clientConf, *pskConf, string, error) {
+	c := &clientConf{
+		AckTimeout:        cast.DurationConf(2 * time.Second),
+		MaxRetransmit:     4,
+		ReconnectInterval: cast.DurationConf(5 * time.Second),
+	}
+	if err := cast.MapToStruct(props, c); err != nil {
+		return nil, nil, "", err
+	}
+	psk := &pskConf{}
+	if err := cast.MapToStruct(props, psk); err != nil {
+		return nil, nil, "", err
+	}
+	if err := psk.validate(); err != nil {
+		return nil, nil, "", err
+	}
+	if c.Server == "" {
+		return nil, nil, "", fmt.Errorf("server can not be empty")
+	}
+	u, err := url.Parse(c.Server)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("invalid server %s: %v", c.Server, err)
+	}
+	port := u.Port()
+	switch u.Scheme {
+	case "coap":
+		if psk.enabled() {
+			return nil, nil, "", fmt.Errorf("pskIdentity and pskKey are only supported by coaps:// server")
+		}
+		if port == "" {
+			port = "5683"
+		}
+	case "coaps":
+		if !psk.enabled() {
+			return nil, nil, "", fmt.Errorf("pskIdentity and pskKey are required by coaps:// server")
+		}
+		if port == "" {
+			port = "5684"
+		}
+	default:
+		return nil, nil, "", fmt.Errorf("invalid server %s, should start with coap:// or coaps://", c.Server)
+	}
+	if time.Duration(c.AckTimeout) <= 0 {
+		return nil, nil, "", fmt.Errorf("ackTimeout should be positive")
+	}
+	if c.MaxRetransmit < 0 {
+		return nil, nil, "", fmt.Errorf("maxRetransmit should not be negative")
+	}
+	if time.Duration(c.ReconnectInterval) <= 0 {
+		return nil, nil, "", fmt.Errorf("reconnectInterval should be positive")
+	}
+	return c, psk, net.JoinHostPort(u.Hostname(), port), nil
+}
+
+// maxTransmitWait is the MAX_TRANSMIT_WAIT of RFC 7252 to wait for the separate response
+func (c *clientConf) maxTransmitWait() time.Duration {
+	return time.Duration(c.AckTimeout) * time.Duration(1<<(c.MaxRetransmit+1)-1) * 3 / 2
+}
+
+// Connection is a CoAP client endpoint. The confirmable messages are retransmitted with exponential back-off until
+// acknowledged. UDP is connectionless, so only the DTLS session is redialed when it is lost.
+type Connection struct {
+	id     string
+	conf   *clientConf
+	psk    *pskConf
+	addr   string
+	nextId atomic.Uint32
+
+	mu sync.Mutex
+	// conn is the current session. lost is closed when the session is lost
+	conn      net.Conn
+	lost      chan struct{}
+	ready     chan struct{}
+	exchanges map[uint16]chan *message
+	tokens    map[string]func(*message)
+	closed    atomic.Bool
+	status    atomic.Value
+	scHandler api.StatusChangeHandler
+}
+
+func CreateConnection(_ api.StreamContext) modules.Connection {
+	return &Connection{}
+}
+
+func (c *Connection) Provision(_ api.StreamContext, conId string, props map[string]any) error {
+	cc, psk, addr, err := newClientConf(props)
+	if err != nil {
+		return err
+	}
+	c.id = conId
+	c.conf = cc
+	c.psk = psk
+	c.addr = addr
+	c.ready = make(chan struct{})
+	c.exchanges = map[uint16]chan *message{}
+	c.tokens = map[string]func(*message){}
+	n, _ := rand.Int(rand.Reader, big.NewInt(1<<16))
+	c.nextId.Store(uint32(n.Int64()))
+	c.status.Store(modules.ConnectionStatus{Status: api.ConnectionConnecting})
+	return nil
+}
+
+func (c *Connection) Dial(ctx api.StreamContext) error {
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return errorx.NewIOErr(fmt.Sprintf("found error when connecting for %s: %s", c.id, err))
+	}
+	c.onConnected(ctx, conn)
+	ctx.GetLogger().Infof("new coap client created")
+	return nil
+}
+
+func (c *Connection) dial(ctx api.StreamContext) (net.Conn, error) {
+	if !c.psk.enabled() {
+		return net.Dial("udp", c.addr)
+	}
+	raddr, err := net.ResolveUDPAddr("udp", c.addr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := dtls.Dial("udp", raddr, c.psk.dtlsConfig(false))
+	if err != nil {
+		return nil, err
+	}
+	hctx, cancel := context.WithTimeout(ctx, c.conf.maxTransmitWait())
+	defer cancel()
+	if err := conn.HandshakeContext(hctx); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("dtls handshake error: %v", err)
+	}
+	return conn, nil
+}
+
+func (c *Connection) onConnected(ctx api.StreamContext, conn net.Conn) {
+	lost := make(chan struct{})
+	c.mu.Lock()
+	c.conn = conn
+	c.lost = lost
+	close(c.ready)
+	c.mu.Unlock()
+	c.onStatusChange(ctx, api.ConnectionConnected, "")
+	go c.readLoop(ctx, conn, lost)
+}
+
+// readLoop reads the messages of a session. If the DTLS session is lost, it redials until succeed
+// or the connection is closed by user.
+func (c *Connection) readLoop(ctx api.StreamContext, conn net.Conn, lost chan struct{}) {
+	buf := make([]byte, 65535)
+	var err error
+	for {
+		var n int
+		n, err = conn.Read(buf)
+		if err != nil {
+			// The ICMP port unreachable of a previous datagram is not fatal for UDP
+			var opErr *net.OpError
+			if !c.psk.enabled() && errors.As(err, &opErr) && !errors.Is(err, net.ErrClosed) {
+				continue
+			}
+			break
+		}
+		m, e := unmarshal(buf[:n])
+		if e != nil {
+			ctx.GetLogger().Debugf("coap client %s drops invalid message: %v", c.id, e)
+			continue
+		}
+		c.dispatch(ctx, m)
+	}
+	c.mu.Lock()
+	c.ready = make(chan struct{})
+	close(lost)
+	c.mu.Unlock()
+	if c.closed.Load() {
+		return
+	}
+	c.onStatusChange(ctx, api.ConnectionDisconnected, err.Error())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Duration(c.conf.ReconnectInterval)):
+		}
+		if c.closed.Load() {
+			return
+		}
+		c.onStatusChange(ctx, api.ConnectionConnecting, "")
+		conn, err := c.dial(ctx)
+		if err != nil {
+			c.onStatusChange(ctx, api.ConnectionDisconnected, err.Error())
+			continue
+		}
+		c.onConnected(ctx, conn)
+		return
+	}
+}
+
+func (c *Connection) dispatch(ctx api.StreamContext, m *message) {
+	switch {
+	case m.typ == typeACK || m.typ == typeRST:
+		c.mu.Lock()
+		ch, ok := c.exchanges[m.id]
+		delete(c.exchanges, m.id)
+		c.mu.Unlock()
+		if ok {
+			ch <- m
+		}
+	case m.code >= 1<<5:
+		// The separate response or the notification
+		c.mu.Lock()
+		h, ok := c.tokens[string(m.token)]
+		c.mu.Unlock()
+		if !ok {
+			// Reject the unknown token so that the server cancels the observation
+			c.reply(ctx, &message{typ: typeRST, id: m.id})
+			return
+		}
+		if m.typ == typeCON {
+			c.reply(ctx, &message{typ: typeACK, id: m.id})
+		}
+		h(m)
+	case m.typ == typeCON:
+		// The ping and the requests are not supported by the client
+		c.reply(ctx, &message{typ: typeRST, id: m.id})
+	}
+}
+
+func (c *Connection) reply(ctx api.StreamContext, m *message) {
+	if err := c.write(m); err != nil {
+		ctx.GetLogger().Warnf("coap client %s reply error: %v", c.id, err)
+	}
+}
+
+func (c *Connection) write(m *message) error {
+	b, err := m.marshal()
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	conn := c.conn
+	ready := c.ready
+	c.mu.Unlock()
+	select {
+	case <-ready:
+	default:
+		return errorx.NewIOErr("coap connection is not ready")
+	}
+	if _, err := conn.Write(b); err != nil {
+		return errorx.NewIOErr(fmt.Sprintf("coap write error: %v", err))
+	}
+	return nil
+}
+
+// send sends the message. The confirmable message is retransmitted until the acknowledgement or the reset
+// is received, which is returned.
+func (c *Connection) send(ctx api.StreamContext, m *message) (*message, error) {
+	m.id = uint16(c.nextId.Add(1))
+	if m.typ != typeCON {
+		return nil, c.write(m)
+	}
+	ch := make(chan *message, 1)
+	c.mu.Lock()
+	c.exchanges[m.id] = ch
+	lost := c.lost
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.exchanges, m.id)
+		c.mu.Unlock()
+	}()
+	// The initial timeout is a random duration between ACK_TIMEOUT and ACK_TIMEOUT * ACK_RANDOM_FACTOR
+	timeout := time.Duration(c.conf.AckTimeout)
+	if r, err := rand.Int(rand.Reader, big.NewInt(int64(timeout/2)+1)); err == nil {
+		timeout += time.Duration(r.Int64())
+	}
+	for i := 0; ; i++ {
+		if err := c.write(m); err != nil {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-lost:
+			return nil, errorx.NewIOErr("coap session is lost")
+		case r := <-ch:
+			return r, nil
+		case <-time.After(timeout):
+		}
+		if i >= c.conf.MaxRetransmit {
+			return nil, errorx.NewIOErr(fmt.Sprintf("coap %s %s timeout after %d retransmissions", m.code, m.path(), i))
+		}
+		timeout *= 2
+	}
+}
+
+// request sends the request and waits for the response which may be piggybacked in the acknowledgement or separate
+func (c *Connection) request(ctx api.StreamContext, req *message) (*message, error) {
+	req.token = newToken()
+	respCh := make(chan *message, 1)
+	c.addToken(req.token, func(m *message) {
+		select {
+		case respCh <- m:
+		default:
+		}
+	})
+	defer c.removeToken(req.token)
+	ack, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if ack != nil {
+		if ack.typ == typeRST {
+			return nil, fmt.Errorf("coap %s %s is reset by server", req.code, req.path())
+		}
+		if ack.code != codeEmpty {
+			return ack, nil
+		}
+	}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case m := <-respCh:
+		return m, nil
+	case <-time.After(c.conf.maxTransmitWait()):
+		return nil, errorx.NewIOErr(fmt.Sprintf("coap %s %s response timeout", req.code, req.path()))
+	}
+}
+
+// observe registers an observation of the path. The handler receives the current state and the fresh
+// notifications. It returns the function to cancel the observation.
+func (c *Connection) observe(ctx api.StreamContext, path string, h func(*message)) (func(), error) {
+	o := &observation{handler: h, first: make(chan *message, 1)}
+	req := &message{typ: typeCON, code: codeGET, token: newToken()}
+	req.setPath(path)
+	req.setUintOption(optObserve, 0)
+	c.addToken(req.token, o.deliver)
+	cancel := func() {
+		c.removeToken(req.token)
+		dereg := &message{typ: typeNON, code: codeGET, token: req.token}
+		dereg.setPath(path)
+		dereg.setUintOption(optObserve, 1)
+		_, _ = c.send(ctx, dereg)
+	}
+	ack, err := c.send(ctx, req)
+	if err != nil {
+		c.removeToken(req.token)
+		return nil, err
+	}
+	if ack != nil && ack.typ == typeRST {
+		c.removeToken(req.token)
+		return nil, fmt.Errorf("coap observe %s is reset by server", path)
+	}
+	if ack != nil && ack.code != codeEmpty {
+		o.deliver(ack)
+	}
+	var first *message
+	select {
+	case <-ctx.Done():
+		c.removeToken(req.token)
+		return nil, ctx.Err()
+	case first = <-o.first:
+	case <-time.After(c.conf.maxTransmitWait()):
+		c.removeToken(req.token)
+		return nil, errorx.NewIOErr(fmt.Sprintf("coap observe %s response timeout", path))
+	}
+	if !first.code.isSuccess() {
+		c.removeToken(req.token)
+		return nil, fmt.Errorf("coap observe %s error: %s %s", path, first.code, first.payload)
+	}
+	if _, ok := first.uintOption(optObserve); !ok {
+		c.removeToken(req.token)
+		return nil, fmt.Errorf("coap resource %s is not observable", path)
+	}
+	h(first)
+	return cancel, nil
+}
+
+func (c *Connection) addToken(token []byte, h func(*message)) {
+	c.mu.Lock()
+	c.tokens[string(token)] = h
+	c.mu.Unlock()
+}
+
+func (c *Connection) removeToken(token []byte) {
+	c.mu.Lock()
+	delete(c.tokens, string(token))
+	c.mu.Unlock()
+}
+
+// sessionLost returns the channel closed when the current session is lost
+func (c *Connection) sessionLost() <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lost
+}
+
+// waitReady waits for the session to be established
+func (c *Connection) waitReady(ctx api.StreamContext) error {
+	c.mu.Lock()
+	ready := c.ready
+	c.mu.Unlock()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-ready:
+		return nil
+	}
+}
+
+func (c *Connection) GetId(_ api.StreamContext) string {
+	return c.id
+}
+
+func (c *Connection) Status(_ api.StreamContext) modules.ConnectionStatus {
+	return c.status.Load().(modules.ConnectionStatus)
+}
+
+func (c *Connection) SetStatusChangeHandler(_ api.StreamContext, sch api.StatusChangeHandler) {
+	st := c.status.Load().(modules.ConnectionStatus)
+	sch(st.Status, st.ErrMsg)
+	c.mu.Lock()
+	c.scHandler = sch
+	c.mu.Unlock()
+}
+
+func (c *Connection) onStatusChange(ctx api.StreamContext, status, msg string) {
+	c.status.Store(modules.ConnectionStatus{Status: status, ErrMsg: msg})
+	c.mu.Lock()
+	handler := c.scHandler
+	c.mu.Unlock()
+	if handler != nil {
+		handler(status, msg)
+	}
+	ctx.GetLogger().Infof("coap connection %s status changed to %s %s", c.id, status, msg)
+}
+
+// Ping sends the CoAP ping which is an empty confirmable message. The server replies it with a reset.
+func (c *Connection) Ping(ctx api.StreamContext) error {
+	_, err := c.send(ctx, &message{typ: typeCON, code: codeEmpty})
+	return err
+}
+
+func (c *Connection) Close(_ api.StreamContext) error {
+	c.closed.Store(true)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn != nil {
+		return c.conn.Close()
+	}
+	return nil
+}
+
+// observation keeps the order of the notifications as RFC 7641
+type observation struct {
+	handler func(*message)
+	first   chan *message
+	mu      sync.Mutex
+	started bool
+	seq     uint32
+	ts      time.Time
+}
+
+func (o *observation) deliver(m *message) {
+	o.mu.Lock()
+	seq, hasSeq := m.uintOption(optObserve)
+	now := time.Now()
+	if !o.started {
+		o.started = true
+		o.seq, o.ts = seq, now
+		o.mu.Unlock()
+		// The registration result is checked before handling
+		o.first <- m
+		return
+	}
+	if hasSeq && !fresher(o.seq, o.ts, seq, now) {
+		o.mu.Unlock()
+		return
+	}
+	o.seq, o.ts = seq, now
+	o.mu.Unlock()
+	o.handler(m)
+}
+
+// fresher checks whether the notification v2 received at t2 is newer than v1 received at t1
+func fresher(v1 uint32, t1 time.Time, v2 uint32, t2 time.Time) bool {
+	const half = 1 << 23
+	return (v1 < v2 && v2-v1 < half) || (v1 > v2 && v1-v2 > half) || t2.After(t1.Add(128*time.Second))
+}
+
+func newToken() []byte {
+	t := make([]byte, 8)
+	_, _ = rand.Read(t)
+	return t
+}
+
+func ping(ctx api.StreamContext, props map[string]any) error {
+	c := &Connection{}
+	if err := c.Provision(ctx, "test", props); err != nil {
+		return err
+	}
+	if err := c.Dial(ctx); err != nil {
+		return err
+	}
+	defer c.Close(ctx)
+	return c.Ping(ctx)
+}
+
+var (
+	_ modules.Connection     = &Connection{}
+	_ modules.StatefulDialer = &Connection{}
+)
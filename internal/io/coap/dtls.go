@@ -0,0 +1,59 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coap
+
+import (
+	"fmt"
+
+	"github.com/pion/dtls/v3"
+)
+
+// pskConf is the DTLS pre-shared key credential
+type pskConf struct {
+	PskIdentity string `json:"pskIdentity"`
+	PskKey      string `json:"pskKey"`
+}
+
+func (c *pskConf) enabled() bool {
+	return c.PskIdentity != "" || c.PskKey != ""
+}
+
+func (c *pskConf) validate() error {
+	if c.enabled() && (c.PskIdentity == "" || c.PskKey == "") {
+		return fmt.Errorf("pskIdentity and pskKey must be set together")
+	}
+	return nil
+}
+
+// dtlsConfig returns the DTLS config with the PSK cipher suites. TLS_PSK_WITH_AES_128_CCM_8 is mandatory for CoAP.
+func (c *pskConf) dtlsConfig(server bool) *dtls.Config {
+	identity, key := c.PskIdentity, []byte(c.PskKey)
+	conf := &dtls.Config{
+		PSK: func(id []byte) ([]byte, error) {
+			// The server receives the client identity. The client receives the optional server hint.
+			if server && string(id) != identity {
+				return nil, fmt.Errorf("unknown psk identity %s", id)
+			}
+			return key, nil
+		},
+		CipherSuites:         []dtls.CipherSuiteID{dtls.TLS_PSK_WITH_AES_128_CCM_8, dtls.TLS_PSK_WITH_AES_128_GCM_SHA256, dtls.TLS_PSK_WITH_AES_128_CBC_SHA256},
+		ExtendedMasterSecret: dtls.RequestExtendedMasterSecret,
+	}
+	if !server {
+		// The client sends the identity hint as its identity
+		conf.PSKIdentityHint = []byte(identity)
+	}
+	return conf
+}
@@ -0,0 +1,311 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coap
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The CoAP message format defined in RFC 7252. Only the parts used by the connectors are implemented,
+// the block-wise transfer is not supported.
+
+type msgType uint8
+
+const (
+	typeCON msgType = iota
+	typeNON
+	typeACK
+	typeRST
+)
+
+type code uint8
+
+const (
+	codeEmpty  code = 0
+	codeGET    code = 1
+	codePOST   code = 2
+	codePUT    code = 3
+	codeDELETE code = 4
+
+	codeCreated             code = 2<<5 | 1
+	codeChanged             code = 2<<5 | 4
+	codeContent             code = 2<<5 | 5
+	codeBadRequest          code = 4 << 5
+	codeNotFound            code = 4<<5 | 4
+	codeMethodNotAllowed    code = 4<<5 | 5
+	codeInternalServerError code = 5 << 5
+)
+
+func (c code) String() string {
+	switch c {
+	case codeGET:
+		return "GET"
+	case codePOST:
+		return "POST"
+	case codePUT:
+		return "PUT"
+	case codeDELETE:
+		return "DELETE"
+	}
+	return fmt.Sprintf("%d.%02d", c>>5, c&0x1f)
+}
+
+func (c code) isSuccess() bool {
+	return c>>5 == 2
+}
+
+func parseMethod(method string) (code, error) {
+	switch strings.ToUpper(method) {
+	case "POST":
+		return codePOST, nil
+	case "PUT":
+		return codePUT, nil
+	}
+	return 0, fmt.Errorf("method %s is not supported, must be POST or PUT", method)
+}
+
+const (
+	optObserve       uint16 = 6
+	optUriPath       uint16 = 11
+	optContentFormat uint16 = 12
+	optMaxAge        uint16 = 14
+	optUriQuery      uint16 = 15
+)
+
+const payloadMarker = 0xff
+
+var contentFormats = map[string]uint32{
+	"text/plain":               0,
+	"application/link-format":  40,
+	"application/xml":          41,
+	"application/octet-stream": 42,
+	"application/exi":          47,
+	"application/json":         50,
+	"application/cbor":         60,
+}
+
+// parseContentFormat accepts the media type name or the numeric id
+func parseContentFormat(s string) (uint32, error) {
+	if f, ok := contentFormats[s]; ok {
+		return f, nil
+	}
+	f, err := strconv.ParseUint(s, 10, 16)
+	if err != nil {
+		return 0, fmt.Errorf("unknown content format %s", s)
+	}
+	return uint32(f), nil
+}
+
+func contentFormatName(f uint32) any {
+	for k, v := range contentFormats {
+		if v == f {
+			return k
+		}
+	}
+	return f
+}
+
+type option struct {
+	num   uint16
+	value []byte
+}
+
+type message struct {
+	typ     msgType
+	code    code
+	id      uint16
+	token   []byte
+	options []option
+	payload []byte
+}
+
+func (m *message) option(num uint16) ([]byte, bool) {
+	for _, o := range m.options {
+		if o.num == num {
+			return o.value, true
+		}
+	}
+	return nil, false
+}
+
+func (m *message) uintOption(num uint16) (uint32, bool) {
+	v, ok := m.option(num)
+	if !ok || len(v) > 4 {
+		return 0, false
+	}
+	var r uint32
+	for _, b := range v {
+		r = r<<8 | uint32(b)
+	}
+	return r, true
+}
+
+// setUintOption sets the option with the minimal length encoding
+func (m *message) setUintOption(num uint16, v uint32) {
+	var buf []byte
+	for ; v > 0; v >>= 8 {
+		buf = append([]byte{byte(v)}, buf...)
+	}
+	m.options = append(m.options, option{num: num, value: buf})
+}
+
+func (m *message) path() string {
+	var segs []string
+	for _, o := range m.options {
+		if o.num == optUriPath {
+			segs = append(segs, string(o.value))
+		}
+	}
+	return "/" + strings.Join(segs, "/")
+}
+
+// setPath sets the path and the query of a relative uri such as /a/b?c=d
+func (m *message) setPath(p string) {
+	p, q, _ := strings.Cut(p, "?")
+	for _, seg := range strings.Split(strings.Trim(p, "/"), "/") {
+		if seg != "" {
+			m.options = append(m.options, option{num: optUriPath, value: []byte(seg)})
+		}
+	}
+	if q != "" {
+		for _, kv := range strings.Split(q, "&") {
+			m.options = append(m.options, option{num: optUriQuery, value: []byte(kv)})
+		}
+	}
+}
+
+func (m *message) queries() []string {
+	var r []string
+	for _, o := range m.options {
+		if o.num == optUriQuery {
+			r = append(r, string(o.value))
+		}
+	}
+	return r
+}
+
+func (m *message) marshal() ([]byte, error) {
+	if len(m.token) > 8 {
+		return nil, fmt.Errorf("token length %d exceeds 8", len(m.token))
+	}
+	buf := make([]byte, 4, 4+len(m.token)+len(m.payload)+16)
+	buf[0] = 1<<6 | byte(m.typ)<<4 | byte(len(m.token))
+	buf[1] = byte(m.code)
+	binary.BigEndian.PutUint16(buf[2:], m.id)
+	buf = append(buf, m.token...)
+	opts := make([]option, len(m.options))
+	copy(opts, m.options)
+	// The repeatable options such as Uri-Path keep their order
+	sort.SliceStable(opts, func(i, j int) bool { return opts[i].num < opts[j].num })
+	prev := uint16(0)
+	for _, o := range opts {
+		delta, dext := optionNibble(int(o.num - prev))
+		length, lext := optionNibble(len(o.value))
+		buf = append(buf, delta<<4|length)
+		buf = append(buf, dext...)
+		buf = append(buf, lext...)
+		buf = append(buf, o.value...)
+		prev = o.num
+	}
+	if len(m.payload) > 0 {
+		buf = append(buf, payloadMarker)
+		buf = append(buf, m.payload...)
+	}
+	return buf, nil
+}
+
+// optionNibble encodes the option delta or length with the extended bytes
+func optionNibble(v int) (byte, []byte) {
+	switch {
+	case v < 13:
+		return byte(v), nil
+	case v < 269:
+		return 13, []byte{byte(v - 13)}
+	default:
+		ext := make([]byte, 2)
+		binary.BigEndian.PutUint16(ext, uint16(v-269))
+		return 14, ext
+	}
+}
+
+func unmarshal(data []byte) (*message, error) {
+	if len(data) < 4 {
+		return nil, fmt.Errorf("message is too short")
+	}
+	if data[0]>>6 != 1 {
+		return nil, fmt.Errorf("unsupported version %d", data[0]>>6)
+	}
+	m := &message{
+		typ:  msgType(data[0] >> 4 & 0x3),
+		code: code(data[1]),
+		id:   binary.BigEndian.Uint16(data[2:]),
+	}
+	tkl := int(data[0] & 0xf)
+	if tkl > 8 || len(data) < 4+tkl {
+		return nil, fmt.Errorf("invalid token length %d", tkl)
+	}
+	if tkl > 0 {
+		m.token = append([]byte(nil), data[4:4+tkl]...)
+	}
+	data = data[4+tkl:]
+	num := 0
+	for len(data) > 0 {
+		if data[0] == payloadMarker {
+			if len(data) == 1 {
+				return nil, fmt.Errorf("payload marker with empty payload")
+			}
+			m.payload = append([]byte(nil), data[1:]...)
+			break
+		}
+		delta, length := int(data[0]>>4), int(data[0]&0xf)
+		data = data[1:]
+		var err error
+		if delta, data, err = readNibble(delta, data); err != nil {
+			return nil, err
+		}
+		if length, data, err = readNibble(length, data); err != nil {
+			return nil, err
+		}
+		if len(data) < length {
+			return nil, fmt.Errorf("option length %d exceeds the message", length)
+		}
+		num += delta
+		m.options = append(m.options, option{num: uint16(num), value: append([]byte(nil), data[:length]...)})
+		data = data[length:]
+	}
+	return m, nil
+}
+
+func readNibble(v int, data []byte) (int, []byte, error) {
+	switch v {
+	case 13:
+		if len(data) < 1 {
+			return 0, nil, fmt.Errorf("invalid option")
+		}
+		return int(data[0]) + 13, data[1:], nil
+	case 14:
+		if len(data) < 2 {
+			return 0, nil, fmt.Errorf("invalid option")
+		}
+		return int(binary.BigEndian.Uint16(data)) + 269, data[2:], nil
+	case 15:
+		return 0, nil, fmt.Errorf("invalid option")
+	}
+	return v, data, nil
+}
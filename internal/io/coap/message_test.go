@@ -0,0 +1,121 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coap
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageMarshal(t *testing.T) {
+	m := &message{typ: typeCON, code: codePOST, id: 0x1234, token: []byte{1, 2, 3, 4}, payload: []byte(`{"a":1}`)}
+	m.setPath("/sensors/temp?unit=c&room=1")
+	m.setUintOption(optContentFormat, 50)
+	m.setUintOption(optObserve, 0)
+	// Long option value and large option delta need the extended bytes
+	m.options = append(m.options, option{num: 300, value: bytes.Repeat([]byte("x"), 300)})
+	b, err := m.marshal()
+	require.NoError(t, err)
+	// Version 1, CON, token length 4
+	require.Equal(t, byte(0x44), b[0])
+	r, err := unmarshal(b)
+	require.NoError(t, err)
+	require.Equal(t, typeCON, r.typ)
+	require.Equal(t, codePOST, r.code)
+	require.Equal(t, uint16(0x1234), r.id)
+	require.Equal(t, m.token, r.token)
+	require.Equal(t, "/sensors/temp", r.path())
+	require.Equal(t, []string{"unit=c", "room=1"}, r.queries())
+	f, ok := r.uintOption(optContentFormat)
+	require.True(t, ok)
+	require.Equal(t, uint32(50), f)
+	// Zero is encoded as an empty value
+	obs, ok := r.uintOption(optObserve)
+	require.True(t, ok)
+	require.Equal(t, uint32(0), obs)
+	v, ok := r.option(300)
+	require.True(t, ok)
+	require.Len(t, v, 300)
+	require.Equal(t, m.payload, r.payload)
+}
+
+func TestUnmarshalErr(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		err  string
+	}{
+		{
+			name: "too short",
+			data: []byte{0x40, 0x01},
+			err:  "message is too short",
+		},
+		{
+			name: "version",
+			data: []byte{0x80, 0x01, 0, 1},
+			err:  "unsupported version 2",
+		},
+		{
+			name: "token length",
+			data: []byte{0x49, 0x01, 0, 1},
+			err:  "invalid token length 9",
+		},
+		{
+			name: "empty payload",
+			data: []byte{0x40, 0x01, 0, 1, 0xff},
+			err:  "payload marker with empty payload",
+		},
+		{
+			name: "option length",
+			data: []byte{0x40, 0x01, 0, 1, 0xb5, 'a'},
+			err:  "option length 5 exceeds the message",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := unmarshal(tt.data)
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestCode(t *testing.T) {
+	require.Equal(t, "2.04", codeChanged.String())
+	require.Equal(t, "4.04", codeNotFound.String())
+	require.Equal(t, "POST", codePOST.String())
+	require.True(t, codeContent.isSuccess())
+	require.False(t, codeBadRequest.isSuccess())
+	f, err := parseContentFormat("application/json")
+	require.NoError(t, err)
+	require.Equal(t, uint32(50), f)
+	f, err = parseContentFormat("11542")
+	require.NoError(t, err)
+	require.Equal(t, uint32(11542), f)
+	_, err = parseContentFormat("json")
+	require.EqualError(t, err, "unknown content format json")
+}
+
+func TestFresher(t *testing.T) {
+	now := time.Now()
+	require.True(t, fresher(1, now, 2, now))
+	require.False(t, fresher(2, now, 1, now))
+	// Wrap around
+	require.True(t, fresher(1<<24-1, now, 1, now))
+	// Too old to compare
+	require.True(t, fresher(2, now, 1, now.Add(129*time.Second)))
+}
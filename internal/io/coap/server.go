@@ -0,0 +1,384 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coap
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/pion/dtls/v3"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
+)
+
+// exchangeLifetime is the EXCHANGE_LIFETIME of RFC 7252 to detect the duplicated confirmable messages
+const exchangeLifetime = 247 * time.Second
+
+// handler handles the payload sent to a registered path
+type handler func(payload []byte, meta map[string]any)
+
+type route struct {
+	method code
+	subs   map[string]handler
+}
+
+// server is a CoAP endpoint shared by all the sources listening to the same address. Each source
+// registers a path and the requests to the path are dispatched to all the sources of it.
+type server struct {
+	addr   string
+	psk    pskConf
+	refs   int
+	logger api.Logger
+
+	mu     sync.RWMutex
+	routes map[string]*route
+
+	pc     net.PacketConn
+	ln     net.Listener
+	nextId atomic.Uint32
+	dedup  *dedupCache
+}
+
+var (
+	serversLock sync.Mutex
+	servers     = map[string]*server{}
+)
+
+// acquireServer returns the running server of the address or starts a new one
+func acquireServer(ctx api.StreamContext, addr string, psk pskConf) (*server, error) {
+	serversLock.Lock()
+	defer serversLock.Unlock()
+	if s, ok := servers[addr]; ok {
+		if s.psk != psk {
+			return nil, fmt.Errorf("coap server %s is already started with different dtls settings", addr)
+		}
+		s.refs++
+		return s, nil
+	}
+	s := &server{
+		addr:   addr,
+		psk:    psk,
+		refs:   1,
+		logger: ctx.GetLogger(),
+		routes: map[string]*route{},
+		dedup:  newDedupCache(),
+	}
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if psk.enabled() {
+		ln, err := dtls.Listen("udp", udpAddr, psk.dtlsConfig(true))
+		if err != nil {
+			return nil, err
+		}
+		s.ln = ln
+		go s.serveDTLS()
+	} else {
+		pc, err := net.ListenUDP("udp", udpAddr)
+		if err != nil {
+			return nil, err
+		}
+		s.pc = pc
+		go s.serveUDP()
+	}
+	servers[addr] = s
+	s.logger.Infof("coap server started at %s", addr)
+	return s, nil
+}
+
+// release stops the server when it is not used by any connection
+func (s *server) release() {
+	serversLock.Lock()
+	defer serversLock.Unlock()
+	s.refs--
+	if s.refs > 0 {
+		return
+	}
+	delete(servers, s.addr)
+	if s.ln != nil {
+		_ = s.ln.Close()
+	}
+	if s.pc != nil {
+		_ = s.pc.Close()
+	}
+	s.logger.Infof("coap server at %s stopped", s.addr)
+}
+
+func (s *server) register(path string, method code, subId string, h handler) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.routes[path]
+	if !ok {
+		r = &route{method: method, subs: map[string]handler{}}
+		s.routes[path] = r
+	} else if r.method != method {
+		return fmt.Errorf("path %s is already registered with method %s", path, r.method)
+	}
+	r.subs[subId] = h
+	return nil
+}
+
+func (s *server) unregister(path, subId string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r, ok := s.routes[path]; ok {
+		delete(r.subs, subId)
+		if len(r.subs) == 0 {
+			delete(s.routes, path)
+		}
+	}
+}
+
+func (s *server) serveUDP() {
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := s.pc.ReadFrom(buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				s.logger.Errorf("coap server %s read error: %v", s.addr, err)
+			}
+			return
+		}
+		resp := s.handle(buf[:n], addr.String())
+		if resp != nil {
+			if _, err := s.pc.WriteTo(resp, addr); err != nil {
+				s.logger.Warnf("coap server %s write to %s error: %v", s.addr, addr, err)
+			}
+		}
+	}
+}
+
+func (s *server) serveDTLS() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				s.logger.Errorf("coap server %s accept error: %v", s.addr, err)
+			}
+			return
+		}
+		go func(conn net.Conn) {
+			defer conn.Close()
+			buf := make([]byte, 65535)
+			for {
+				// The handshake is done in the first read
+				n, err := conn.Read(buf)
+				if err != nil {
+					s.logger.Debugf("coap server %s closes dtls session of %s: %v", s.addr, conn.RemoteAddr(), err)
+					return
+				}
+				resp := s.handle(buf[:n], conn.RemoteAddr().String())
+				if resp != nil {
+					if _, err := conn.Write(resp); err != nil {
+						s.logger.Warnf("coap server %s write to %s error: %v", s.addr, conn.RemoteAddr(), err)
+					}
+				}
+			}
+		}(conn)
+	}
+}
+
+// handle processes a datagram and returns the response to send back if any
+func (s *server) handle(data []byte, remote string) []byte {
+	req, err := unmarshal(data)
+	if err != nil {
+		s.logger.Debugf("coap server %s drops invalid message from %s: %v", s.addr, remote, err)
+		return nil
+	}
+	switch {
+	case req.typ == typeACK || req.typ == typeRST:
+		return nil
+	case req.code == codeEmpty || req.code >= 1<<5:
+		// Reply the ping and the unexpected responses with reset
+		if req.typ == typeCON {
+			return s.marshal(&message{typ: typeRST, id: req.id})
+		}
+		return nil
+	}
+	key := fmt.Sprintf("%s#%d", remote, req.id)
+	if resp, ok := s.dedup.get(key); ok {
+		return resp
+	}
+	resp := &message{code: s.dispatch(req, remote), token: req.token}
+	if req.typ == typeCON {
+		resp.typ = typeACK
+		resp.id = req.id
+	} else {
+		resp.typ = typeNON
+		resp.id = uint16(s.nextId.Add(1))
+	}
+	b := s.marshal(resp)
+	s.dedup.put(key, b)
+	return b
+}
+
+func (s *server) dispatch(req *message, remote string) code {
+	path := req.path()
+	s.mu.RLock()
+	r, ok := s.routes[path]
+	var subs []handler
+	if ok && r.method == req.code {
+		subs = make([]handler, 0, len(r.subs))
+		for _, h := range r.subs {
+			subs = append(subs, h)
+		}
+	}
+	s.mu.RUnlock()
+	if !ok {
+		return codeNotFound
+	}
+	if subs == nil {
+		return codeMethodNotAllowed
+	}
+	for _, h := range subs {
+		h(req.payload, requestMeta(req, remote))
+	}
+	return codeChanged
+}
+
+func requestMeta(req *message, remote string) map[string]any {
+	meta := map[string]any{
+		"path":   req.path(),
+		"method": req.code.String(),
+		"remote": remote,
+	}
+	if f, ok := req.uintOption(optContentFormat); ok {
+		meta["contentFormat"] = contentFormatName(f)
+	}
+	if q := req.queries(); len(q) > 0 {
+		meta["query"] = strings.Join(q, "&")
+	}
+	return meta
+}
+
+func (s *server) marshal(m *message) []byte {
+	b, err := m.marshal()
+	if err != nil {
+		s.logger.Errorf("coap server %s marshal response error: %v", s.addr, err)
+		return nil
+	}
+	return b
+}
+
+type serverConf struct {
+	ListenAddr string `json:"listenAddr"`
+}
+
+// ServerConnection is a reference to the shared server of the listen address
+type ServerConnection struct {
+	id   string
+	addr string
+	psk  pskConf
+	srv  *server
+}
+
+func CreateServerConnection(_ api.StreamContext) modules.Connection {
+	return &ServerConnection{}
+}
+
+func (c *ServerConnection) Provision(_ api.StreamContext, conId string, props map[string]any) error {
+	sc := &serverConf{ListenAddr: ":5683"}
+	if err := cast.MapToStruct(props, sc); err != nil {
+		return err
+	}
+	psk := pskConf{}
+	if err := cast.MapToStruct(props, &psk); err != nil {
+		return err
+	}
+	if err := psk.validate(); err != nil {
+		return err
+	}
+	c.id = conId
+	c.addr = sc.ListenAddr
+	c.psk = psk
+	return nil
+}
+
+func (c *ServerConnection) Dial(ctx api.StreamContext) error {
+	srv, err := acquireServer(ctx, c.addr, c.psk)
+	if err != nil {
+		return err
+	}
+	c.srv = srv
+	return nil
+}
+
+func (c *ServerConnection) GetId(_ api.StreamContext) string {
+	return c.id
+}
+
+func (c *ServerConnection) Ping(_ api.StreamContext) error {
+	return nil
+}
+
+func (c *ServerConnection) Close(_ api.StreamContext) error {
+	if c.srv != nil {
+		c.srv.release()
+		c.srv = nil
+	}
+	return nil
+}
+
+var _ modules.Connection = &ServerConnection{}
+
+// dedupCache keeps the responses of the recent requests. A duplicated confirmable request is
+// answered with the same response without processing again.
+type dedupCache struct {
+	mu      sync.Mutex
+	entries map[string]dedupEntry
+	puts    int
+}
+
+type dedupEntry struct {
+	resp []byte
+	ts   time.Time
+}
+
+func newDedupCache() *dedupCache {
+	return &dedupCache{entries: map[string]dedupEntry{}}
+}
+
+func (d *dedupCache) get(key string) ([]byte, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	e, ok := d.entries[key]
+	if !ok || time.Since(e.ts) > exchangeLifetime {
+		return nil, false
+	}
+	return e.resp, true
+}
+
+func (d *dedupCache) put(key string, resp []byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := time.Now()
+	d.entries[key] = dedupEntry{resp: resp, ts: now}
+	d.puts++
+	if d.puts%1024 == 0 {
+		for k, e := range d.entries {
+			if now.Sub(e.ts) > exchangeLifetime {
+				delete(d.entries, k)
+			}
+		}
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coap
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/util"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+)
+
+type sinkConf struct {
+	Path          string `json:"path"`
+	Method        string `json:"method"`
+	ContentFormat string `json:"contentFormat"`
+	Confirmable   bool   `json:"confirmable"`
+}
+
+// Sink sends the encoded result to a path of the CoAP server. The confirmable request is retransmitted
+// until acknowledged and the error response is returned as error. The path supports the data template.
+type Sink struct {
+	conf          *sinkConf
+	method        code
+	contentFormat *uint32
+	props         map[string]any
+	conId         string
+	conn          *Connection
+}
+
+func (s *Sink) Provision(_ api.StreamContext, props map[string]any) error {
+	if _, _, _, err := newClientConf(props); err != nil {
+		return err
+	}
+	c := &sinkConf{Method: "POST", Confirmable: true}
+	if err := cast.MapToStruct(props, c); err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	if !strings.HasPrefix(c.Path, "/") {
+		return fmt.Errorf("path must start with /")
+	}
+	m, err := parseMethod(c.Method)
+	if err != nil {
+		return err
+	}
+	if c.ContentFormat != "" {
+		f, err := parseContentFormat(c.ContentFormat)
+		if err != nil {
+			return err
+		}
+		s.contentFormat = &f
+	}
+	s.method = m
+	s.conf = c
+	s.props = props
+	return nil
+}
+
+func (s *Sink) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	conn, conId, err := connect(ctx, fmt.Sprintf("%s-%s-coap-sink", ctx.GetRuleId(), ctx.GetOpId()), s.props, sch)
+	s.conId = conId
+	s.conn = conn
+	return err
+}
+
+func (s *Sink) Collect(ctx api.StreamContext, item api.RawTuple) error {
+	path := s.conf.Path
+	// If the props support dynamic props(template), planner will guarantee the result has the parsed dynamic props
+	if dp, ok := item.(api.HasDynamicProps); ok {
+		if np, ok := dp.DynamicProps(path); ok {
+			path = np
+		}
+	}
+	req := &message{code: s.method, payload: item.Raw()}
+	req.setPath(path)
+	if s.contentFormat != nil {
+		req.setUintOption(optContentFormat, *s.contentFormat)
+	}
+	if !s.conf.Confirmable {
+		req.typ = typeNON
+		_, err := s.conn.send(ctx, req)
+		return err
+	}
+	req.typ = typeCON
+	resp, err := s.conn.request(ctx, req)
+	if err != nil {
+		return err
+	}
+	if !resp.code.isSuccess() {
+		return fmt.Errorf("coap %s %s error: %s %s", s.method, path, resp.code, resp.payload)
+	}
+	return nil
+}
+
+func (s *Sink) Ping(ctx api.StreamContext, props map[string]any) error {
+	return ping(ctx, props)
+}
+
+func (s *Sink) Close(ctx api.StreamContext) error {
+	return connection.DetachConnection(ctx, s.conId)
+}
+
+func GetSink() api.Sink {
+	return &Sink{}
+}
+
+var (
+	_ api.BytesCollector = &Sink{}
+	_ util.PingableConn  = &Sink{}
+)
@@ -0,0 +1,271 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coap
+
+import (
+	"net"
+	"sync/atomic"
+	"testing"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func rawTuple(s string) api.RawTuple {
+	return &xsql.RawTuple{Rawdata: []byte(s)}
+}
+
+// fakeServer passes the received messages to the handler which may reply or drop them
+func fakeServer(t *testing.T, h func(req *message, reply func(*message))) string {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = pc.Close() })
+	go func() {
+		buf := make([]byte, 2048)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			req, err := unmarshal(buf[:n])
+			if err != nil {
+				continue
+			}
+			h(req, func(m *message) {
+				b, _ := m.marshal()
+				_, _ = pc.WriteTo(b, addr)
+			})
+		}
+	}()
+	return pc.LocalAddr().String()
+}
+
+func TestSinkProvisionErr(t *testing.T) {
+	ctx := mockContext.NewMockContext("1", "2")
+	tests := []struct {
+		name  string
+		props map[string]any
+		err   string
+	}{
+		{
+			name:  "invalid server",
+			props: map[string]any{"server": "http://127.0.0.1", "path": "/a"},
+			err:   "invalid server http://127.0.0.1, should start with coap:// or coaps://",
+		},
+		{
+			name:  "psk for coap",
+			props: map[string]any{"server": "coap://127.0.0.1", "path": "/a", "pskIdentity": "a", "pskKey": "b"},
+			err:   "pskIdentity and pskKey are only supported by coaps:// server",
+		},
+		{
+			name:  "invalid path",
+			props: map[string]any{"server": "coap://127.0.0.1", "path": "a"},
+			err:   "path must start with /",
+		},
+		{
+			name:  "invalid content format",
+			props: map[string]any{"server": "coap://127.0.0.1", "path": "/a", "contentFormat": "json"},
+			err:   "unknown content format json",
+		},
+		{
+			name:  "invalid ack timeout",
+			props: map[string]any{"server": "coap://127.0.0.1", "path": "/a", "ackTimeout": "0s"},
+			err:   "ackTimeout should be positive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.EqualError(t, GetSink().Provision(ctx, tt.props), tt.err)
+		})
+	}
+}
+
+func TestSinkCollect(t *testing.T) {
+	require.NoError(t, connection.InitConnectionManager4Test())
+	var (
+		reqs    = make(chan *message, 10)
+		dropped atomic.Bool
+	)
+	addr := fakeServer(t, func(req *message, reply func(*message)) {
+		switch req.path() {
+		case "/lost":
+			// Drop the first transmission to test the retransmission
+			if !dropped.Swap(true) {
+				return
+			}
+		case "/timeout":
+			return
+		case "/separate":
+			// Empty ack first and then the separate response
+			reply(&message{typ: typeACK, id: req.id})
+			reply(&message{typ: typeCON, code: codeCreated, id: 7, token: req.token})
+			reqs <- req
+			return
+		case "/bad":
+			reply(&message{typ: typeACK, code: codeBadRequest, id: req.id, token: req.token, payload: []byte("invalid")})
+			return
+		}
+		reqs <- req
+		if req.typ == typeCON {
+			reply(&message{typ: typeACK, code: codeChanged, id: req.id, token: req.token})
+		}
+	})
+	ctx := mockContext.NewMockContext("ruleSink", "op1")
+	s := GetSink().(*Sink)
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"server":        "coap://" + addr,
+		"path":          "/devices/{{.id}}",
+		"method":        "PUT",
+		"contentFormat": "application/json",
+		"ackTimeout":    "50ms",
+		"maxRetransmit": 2,
+	}))
+	require.NoError(t, s.Connect(ctx, func(string, string) {}))
+	defer s.Close(ctx)
+
+	collect := func(path string) error {
+		return s.Collect(ctx, &xsql.RawTuple{Rawdata: []byte("data"), Props: map[string]string{"/devices/{{.id}}": path}})
+	}
+
+	require.NoError(t, collect("/devices/d1"))
+	req := <-reqs
+	require.Equal(t, typeCON, req.typ)
+	require.Equal(t, codePUT, req.code)
+	require.Equal(t, "/devices/d1", req.path())
+	f, _ := req.uintOption(optContentFormat)
+	require.Equal(t, uint32(50), f)
+	require.Equal(t, []byte("data"), req.payload)
+
+	require.NoError(t, collect("/lost"))
+	require.Equal(t, "/lost", (<-reqs).path())
+
+	require.NoError(t, collect("/separate"))
+	require.Equal(t, "/separate", (<-reqs).path())
+
+	err := collect("/bad")
+	require.EqualError(t, err, "coap PUT /bad error: 4.00 invalid")
+	require.False(t, errorx.IsIOError(err))
+
+	err = collect("/timeout")
+	require.EqualError(t, err, "coap PUT /timeout timeout after 2 retransmissions")
+	require.True(t, errorx.IsIOError(err))
+}
+
+func TestSinkNonConfirmable(t *testing.T) {
+	require.NoError(t, connection.InitConnectionManager4Test())
+	reqs := make(chan *message, 10)
+	addr := fakeServer(t, func(req *message, _ func(*message)) {
+		reqs <- req
+	})
+	ctx := mockContext.NewMockContext("ruleSink", "op1")
+	s := GetSink().(*Sink)
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"server":      "coap://" + addr,
+		"path":        "/temp",
+		"confirmable": false,
+	}))
+	require.NoError(t, s.Connect(ctx, func(string, string) {}))
+	defer s.Close(ctx)
+	// No response is expected
+	require.NoError(t, s.Collect(ctx, rawTuple("a")))
+	req := <-reqs
+	require.Equal(t, typeNON, req.typ)
+	require.Equal(t, codePOST, req.code)
+}
+
+func TestObserve(t *testing.T) {
+	require.NoError(t, connection.InitConnectionManager4Test())
+	var (
+		notify = make(chan func(*message), 1)
+		acks   = make(chan *message, 10)
+		resets = make(chan *message, 10)
+		token  []byte
+	)
+	addr := fakeServer(t, func(req *message, reply func(*message)) {
+		switch {
+		case req.typ == typeACK:
+			acks <- req
+		case req.typ == typeRST:
+			resets <- req
+		case req.code == codeGET:
+			if v, _ := req.uintOption(optObserve); v != 0 {
+				return
+			}
+			token = req.token
+			resp := &message{typ: typeACK, code: codeContent, id: req.id, token: req.token, payload: []byte("20")}
+			resp.setUintOption(optObserve, 10)
+			resp.setUintOption(optContentFormat, 0)
+			reply(resp)
+			notify <- reply
+		}
+	})
+	src, ctx, r := subscribe(t, "rule1", map[string]any{
+		"server":     "coap://" + addr,
+		"datasource": "/temp",
+		"observe":    true,
+		"ackTimeout": "50ms",
+	})
+	defer src.Close(ctx)
+	v := <-r
+	require.Equal(t, []byte("20"), v.payload)
+	require.Equal(t, map[string]any{"code": "2.05", "observe": uint32(10), "contentFormat": "text/plain"}, v.meta)
+
+	reply := <-notify
+	send := func(typ msgType, id uint16, seq uint32, payload string) {
+		m := &message{typ: typ, code: codeContent, id: id, token: token, payload: []byte(payload)}
+		m.setUintOption(optObserve, seq)
+		reply(m)
+	}
+	// The confirmable notification is acknowledged
+	send(typeCON, 1, 11, "21")
+	require.Equal(t, []byte("21"), (<-r).payload)
+	ack := <-acks
+	require.Equal(t, uint16(1), ack.id)
+	// The reordered notification is dropped
+	send(typeNON, 2, 9, "19")
+	send(typeNON, 3, 12, "22")
+	require.Equal(t, []byte("22"), (<-r).payload)
+	require.Empty(t, r)
+	// The notification with unknown token is reset
+	m := &message{typ: typeCON, code: codeContent, id: 4, token: []byte("unknown")}
+	m.setUintOption(optObserve, 13)
+	reply(m)
+	rst := <-resets
+	require.Equal(t, uint16(4), rst.id)
+}
+
+func TestObserveNotObservable(t *testing.T) {
+	require.NoError(t, connection.InitConnectionManager4Test())
+	addr := fakeServer(t, func(req *message, reply func(*message)) {
+		if req.typ == typeCON {
+			reply(&message{typ: typeACK, code: codeContent, id: req.id, token: req.token, payload: []byte("20")})
+		}
+	})
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	c := &Connection{}
+	require.NoError(t, c.Provision(ctx, "test", map[string]any{"server": "coap://" + addr}))
+	require.NoError(t, c.Dial(ctx))
+	defer c.Close(ctx)
+	_, err := c.observe(ctx, "/temp", func(*message) {})
+	require.EqualError(t, err, "coap resource /temp is not observable")
+	require.Empty(t, c.tokens)
+	require.NoError(t, c.Ping(ctx))
+	require.Equal(t, api.ConnectionConnected, c.Status(ctx).Status)
+}
@@ -0,0 +1,213 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coap
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/util"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+type sourceConf struct {
+	Path    string `json:"datasource"`
+	Method  string `json:"method"`
+	Observe bool   `json:"observe"`
+}
+
+// Source receives the payload sent to a path of the shared CoAP server. In observe mode, it observes
+// the path of a remote CoAP server instead and receives the notifications.
+type Source struct {
+	conf   *sourceConf
+	method code
+	props  map[string]any
+	conId  string
+	subId  string
+	srv    *ServerConnection
+	cli    *Connection
+}
+
+func (s *Source) Provision(_ api.StreamContext, props map[string]any) error {
+	c := &sourceConf{Method: "POST"}
+	if err := cast.MapToStruct(props, c); err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	if !strings.HasPrefix(c.Path, "/") {
+		return fmt.Errorf("datasource must be a path starts with /")
+	}
+	if c.Observe {
+		if _, _, _, err := newClientConf(props); err != nil {
+			return err
+		}
+	} else {
+		m, err := parseMethod(c.Method)
+		if err != nil {
+			return err
+		}
+		s.method = m
+	}
+	s.conf = c
+	s.props = props
+	return nil
+}
+
+func (s *Source) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	refId := fmt.Sprintf("%s-%s-coap-source", ctx.GetRuleId(), ctx.GetOpId())
+	if s.conf.Observe {
+		conn, conId, err := connect(ctx, refId, s.props, sch)
+		s.conId = conId
+		s.cli = conn
+		return err
+	}
+	cw, err := connection.FetchConnection(ctx, refId, "coapserver", s.props, sch)
+	if err != nil {
+		return err
+	}
+	s.conId = cw.ID
+	conn, err := cw.Wait(ctx)
+	if conn == nil {
+		return fmt.Errorf("coap server not ready: %v", err)
+	}
+	s.srv = conn.(*ServerConnection)
+	return err
+}
+
+func (s *Source) Subscribe(ctx api.StreamContext, ingest api.BytesIngest, ingestError api.ErrorIngest) error {
+	if s.conf.Observe {
+		go s.observe(ctx, ingest, ingestError)
+		return nil
+	}
+	s.subId = fmt.Sprintf("%s_%s_%d", ctx.GetRuleId(), ctx.GetOpId(), ctx.GetInstanceId())
+	return s.srv.srv.register(s.conf.Path, s.method, s.subId, func(payload []byte, meta map[string]any) {
+		ingest(ctx, payload, meta, timex.GetNow())
+	})
+}
+
+// observe registers the observation and re-registers it when the session is lost or the notification
+// is not fresh anymore according to the Max-Age.
+func (s *Source) observe(ctx api.StreamContext, ingest api.BytesIngest, ingestError api.ErrorIngest) {
+	for {
+		if err := s.cli.waitReady(ctx); err != nil {
+			return
+		}
+		lost := s.cli.sessionLost()
+		received := make(chan time.Duration, 1)
+		cancel, err := s.cli.observe(ctx, s.conf.Path, func(m *message) {
+			ingest(ctx, m.payload, notificationMeta(m), timex.GetNow())
+			maxAge := uint32(60)
+			if v, ok := m.uintOption(optMaxAge); ok {
+				maxAge = v
+			}
+			select {
+			case received <- time.Duration(maxAge) * time.Second:
+			default:
+			}
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			ingestError(ctx, err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Duration(s.cli.conf.ReconnectInterval)):
+			}
+			continue
+		}
+		s.waitStale(ctx, lost, received)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		ctx.GetLogger().Infof("coap observation of %s is stale, re-register", s.conf.Path)
+	}
+}
+
+// waitStale waits until the session is lost or no notification is received in the Max-Age of the last one
+func (s *Source) waitStale(ctx api.StreamContext, lost <-chan struct{}, received <-chan time.Duration) {
+	// Wait for the first notification which is sent in the registration
+	maxAge := <-received
+	for {
+		// Give the server the time to send the notification after the Max-Age
+		timer := time.NewTimer(maxAge + time.Duration(s.cli.conf.AckTimeout))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-lost:
+			timer.Stop()
+			return
+		case <-timer.C:
+			return
+		case maxAge = <-received:
+			timer.Stop()
+		}
+	}
+}
+
+func notificationMeta(m *message) map[string]any {
+	meta := map[string]any{
+		"code": m.code.String(),
+	}
+	if seq, ok := m.uintOption(optObserve); ok {
+		meta["observe"] = seq
+	}
+	if f, ok := m.uintOption(optContentFormat); ok {
+		meta["contentFormat"] = contentFormatName(f)
+	}
+	return meta
+}
+
+func (s *Source) Ping(ctx api.StreamContext, props map[string]any) error {
+	if o, ok := props["observe"].(bool); ok && o {
+		return ping(ctx, props)
+	}
+	return nil
+}
+
+func (s *Source) Close(ctx api.StreamContext) error {
+	if s.srv != nil && s.srv.srv != nil && s.subId != "" {
+		s.srv.srv.unregister(s.conf.Path, s.subId)
+	}
+	return connection.DetachConnection(ctx, s.conId)
+}
+
+func connect(ctx api.StreamContext, refId string, props map[string]any, sch api.StatusChangeHandler) (*Connection, string, error) {
+	cw, err := connection.FetchConnection(ctx, refId, "coap", props, sch)
+	if err != nil {
+		return nil, "", err
+	}
+	conn, err := cw.Wait(ctx)
+	if conn == nil {
+		return nil, cw.ID, fmt.Errorf("coap client not ready: %v", err)
+	}
+	return conn.(*Connection), cw.ID, err
+}
+
+func GetSource() api.Source {
+	return &Source{}
+}
+
+var (
+	_ api.BytesSource   = &Source{}
+	_ util.PingableConn = &Source{}
+)
@@ -0,0 +1,277 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coap
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
+)
+
+func init() {
+	modules.RegisterConnection("coap", CreateConnection)
+	modules.RegisterConnection("coapserver", CreateServerConnection)
+}
+
+func freeAddr(t *testing.T) string {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := pc.LocalAddr().String()
+	require.NoError(t, pc.Close())
+	return addr
+}
+
+type received struct {
+	payload []byte
+	meta    map[string]any
+}
+
+func subscribe(t *testing.T, ruleId string, props map[string]any) (*Source, api.StreamContext, chan received) {
+	ctx, cancel := mockContext.NewMockContext(ruleId, "op1").WithCancel()
+	t.Cleanup(cancel)
+	src := GetSource().(*Source)
+	require.NoError(t, src.Provision(ctx, props))
+	require.NoError(t, src.Connect(ctx, func(string, string) {}))
+	result := make(chan received, 10)
+	require.NoError(t, src.Subscribe(ctx, func(_ api.StreamContext, payload []byte, meta map[string]any, _ time.Time) {
+		result <- received{payload: payload, meta: meta}
+	}, func(_ api.StreamContext, err error) {
+		t.Log(err)
+	}))
+	return src, ctx, result
+}
+
+func TestSourceProvisionErr(t *testing.T) {
+	ctx := mockContext.NewMockContext("1", "2")
+	tests := []struct {
+		name  string
+		props map[string]any
+		err   string
+	}{
+		{
+			name:  "invalid path",
+			props: map[string]any{"datasource": "temp"},
+			err:   "datasource must be a path starts with /",
+		},
+		{
+			name:  "invalid method",
+			props: map[string]any{"datasource": "/temp", "method": "GET"},
+			err:   "method GET is not supported, must be POST or PUT",
+		},
+		{
+			name:  "observe without server",
+			props: map[string]any{"datasource": "/temp", "observe": true},
+			err:   "server can not be empty",
+		},
+		{
+			name:  "coaps without psk",
+			props: map[string]any{"datasource": "/temp", "observe": true, "server": "coaps://127.0.0.1"},
+			err:   "pskIdentity and pskKey are required by coaps:// server",
+		},
+		{
+			name:  "psk without key",
+			props: map[string]any{"datasource": "/temp", "observe": true, "server": "coaps://127.0.0.1", "pskIdentity": "a"},
+			err:   "pskIdentity and pskKey must be set together",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.EqualError(t, GetSource().Provision(ctx, tt.props), tt.err)
+		})
+	}
+}
+
+// The sources of different rules share the server endpoint
+func TestSharedServer(t *testing.T) {
+	require.NoError(t, connection.InitConnectionManager4Test())
+	addr := freeAddr(t)
+	src1, ctx1, r1 := subscribe(t, "rule1", map[string]any{"listenAddr": addr, "datasource": "/sensors/temp"})
+	src2, ctx2, r2 := subscribe(t, "rule2", map[string]any{"listenAddr": addr, "datasource": "/sensors/temp"})
+	src3, ctx3, r3 := subscribe(t, "rule3", map[string]any{"listenAddr": addr, "datasource": "/sensors/hum", "method": "PUT"})
+	// The same path can not be registered with different methods
+	_, err := subscribeErr(t, map[string]any{"listenAddr": addr, "datasource": "/sensors/temp", "method": "PUT"})
+	require.EqualError(t, err, "path /sensors/temp is already registered with method POST")
+
+	ctx := mockContext.NewMockContext("ruleSink", "op1")
+	cli := &Connection{}
+	require.NoError(t, cli.Provision(ctx, "test", map[string]any{"server": "coap://" + addr}))
+	require.NoError(t, cli.Dial(ctx))
+	defer cli.Close(ctx)
+
+	req := &message{typ: typeCON, code: codePOST, payload: []byte(`{"t":20}`)}
+	req.setPath("/sensors/temp?device=d1")
+	req.setUintOption(optContentFormat, 50)
+	resp, err := cli.request(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, codeChanged, resp.code)
+	for _, r := range []chan received{r1, r2} {
+		v := <-r
+		require.Equal(t, []byte(`{"t":20}`), v.payload)
+		require.Equal(t, "/sensors/temp", v.meta["path"])
+		require.Equal(t, "POST", v.meta["method"])
+		require.Equal(t, "application/json", v.meta["contentFormat"])
+		require.Equal(t, "device=d1", v.meta["query"])
+		require.Equal(t, cli.conn.LocalAddr().String(), v.meta["remote"])
+	}
+
+	tests := []struct {
+		name   string
+		method code
+		path   string
+		code   code
+	}{
+		{
+			name:   "method not allowed",
+			method: codePOST,
+			path:   "/sensors/hum",
+			code:   codeMethodNotAllowed,
+		},
+		{
+			name:   "not found",
+			method: codePOST,
+			path:   "/sensors/none",
+			code:   codeNotFound,
+		},
+		{
+			name:   "non confirmable",
+			method: codePUT,
+			path:   "/sensors/hum",
+			code:   codeChanged,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &message{typ: typeCON, code: tt.method, payload: []byte("a")}
+			if tt.name == "non confirmable" {
+				req.typ = typeNON
+			}
+			req.setPath(tt.path)
+			resp, err := cli.request(ctx, req)
+			require.NoError(t, err)
+			require.Equal(t, tt.code, resp.code)
+		})
+	}
+	require.Equal(t, []byte("a"), (<-r3).payload)
+	require.Empty(t, r1)
+
+	require.NoError(t, src1.Close(ctx1))
+	require.NoError(t, src2.Close(ctx2))
+	// The server is still used by rule3
+	serversLock.Lock()
+	require.Contains(t, servers, addr)
+	serversLock.Unlock()
+	resp, err = cli.request(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, codeNotFound, resp.code)
+	require.NoError(t, src3.Close(ctx3))
+	serversLock.Lock()
+	require.NotContains(t, servers, addr)
+	serversLock.Unlock()
+}
+
+func subscribeErr(t *testing.T, props map[string]any) (*Source, error) {
+	ctx := mockContext.NewMockContext("ruleErr", "op1")
+	src := GetSource().(*Source)
+	require.NoError(t, src.Provision(ctx, props))
+	require.NoError(t, src.Connect(ctx, func(string, string) {}))
+	defer src.Close(ctx)
+	return src, src.Subscribe(ctx, func(api.StreamContext, []byte, map[string]any, time.Time) {}, func(api.StreamContext, error) {})
+}
+
+// The duplicated confirmable message is acknowledged again without ingesting
+func TestServerDedup(t *testing.T) {
+	require.NoError(t, connection.InitConnectionManager4Test())
+	addr := freeAddr(t)
+	src, ctx, r := subscribe(t, "rule1", map[string]any{"listenAddr": addr, "datasource": "/temp"})
+	defer src.Close(ctx)
+	conn, err := net.Dial("udp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	req := &message{typ: typeCON, code: codePOST, id: 100, token: []byte{1}, payload: []byte("1")}
+	req.setPath("/temp")
+	b, err := req.marshal()
+	require.NoError(t, err)
+	buf := make([]byte, 1024)
+	for i := 0; i < 2; i++ {
+		_, err = conn.Write(b)
+		require.NoError(t, err)
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+		n, err := conn.Read(buf)
+		require.NoError(t, err)
+		resp, err := unmarshal(buf[:n])
+		require.NoError(t, err)
+		require.Equal(t, typeACK, resp.typ)
+		require.Equal(t, uint16(100), resp.id)
+		require.Equal(t, []byte{1}, resp.token)
+		require.Equal(t, codeChanged, resp.code)
+	}
+	require.Equal(t, []byte("1"), (<-r).payload)
+	require.Empty(t, r)
+
+	// Ping is replied with reset
+	ping := &message{typ: typeCON, id: 101}
+	b, err = ping.marshal()
+	require.NoError(t, err)
+	_, err = conn.Write(b)
+	require.NoError(t, err)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	resp, err := unmarshal(buf[:n])
+	require.NoError(t, err)
+	require.Equal(t, typeRST, resp.typ)
+	require.Equal(t, uint16(101), resp.id)
+}
+
+func TestDTLS(t *testing.T) {
+	require.NoError(t, connection.InitConnectionManager4Test())
+	addr := freeAddr(t)
+	psk := map[string]any{"pskIdentity": "device1", "pskKey": "secret"}
+	props := map[string]any{"listenAddr": addr, "datasource": "/temp"}
+	for k, v := range psk {
+		props[k] = v
+	}
+	src, sctx, r := subscribe(t, "rule1", props)
+	defer src.Close(sctx)
+	// The plain server can not be started at the same address
+	_, err := acquireServer(sctx, addr, pskConf{})
+	require.EqualError(t, err, fmt.Sprintf("coap server %s is already started with different dtls settings", addr))
+
+	ctx := mockContext.NewMockContext("ruleSink", "op1")
+	sinkProps := map[string]any{"server": "coaps://" + addr, "path": "/temp", "ackTimeout": "100ms", "maxRetransmit": 2}
+	for k, v := range psk {
+		sinkProps[k] = v
+	}
+	s := GetSink().(*Sink)
+	require.NoError(t, s.Provision(ctx, sinkProps))
+	require.NoError(t, s.Connect(ctx, func(string, string) {}))
+	require.NoError(t, s.Collect(ctx, rawTuple("secured")))
+	require.Equal(t, []byte("secured"), (<-r).payload)
+	require.NoError(t, s.Close(ctx))
+
+	sinkProps["pskKey"] = "wrong"
+	c := &Connection{}
+	require.NoError(t, c.Provision(ctx, "test", sinkProps))
+	err = c.Dial(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "dtls handshake error")
+}
@@ -53,7 +53,7 @@ func (m *fileSink) createFileWriter(ctx api.StreamContext, fn string, ft FileTyp
 	)
 	Dir := filepath.Dir(fn)
 	if _, err = os.Stat(Dir); os.IsNotExist(err) {
-		if err := os.Mkdir(Dir, 0o777); err != nil {
+		if err := os.MkdirAll(Dir, 0o777); err != nil {
 			return nil, fmt.Errorf("fail to create file %s: %v", fn, err)
 		}
 	}
@@ -0,0 +1,282 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/geo"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+// GeometryColumn is the default column name of the feature geometry when loading a GeoJSON file
+const GeometryColumn = "geometry"
+
+// LookupSource loads the whole json file into memory once connected.
+// The file can be a JSON array of objects, or a GeoJSON FeatureCollection such as geofences.
+// For GeoJSON, each feature is a row with its properties, its id and the geometry column.
+// If the geometryColumn property is declared, the geometries are indexed so that looking up by the geometry column
+// with a point only checks the features whose bounding box contains the point.
+type LookupSource struct {
+	file string
+	// geomCol is the declared geometry column to index
+	geomCol string
+	rows    []map[string]any
+	geoms   []*geo.Geometry
+	// rowIds maps the index item to the row
+	rowIds []int
+	index  *geo.Index
+}
+
+func parseLookupConfig(props map[string]any) (*SourceConfig, error) {
+	cfg := &SourceConfig{
+		FileType: "json",
+	}
+	err := cast.MapToStruct(props, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	if cfg.FileType != "json" {
+		return nil, fmt.Errorf("file lookup table only supports json file type but got %s", cfg.FileType)
+	}
+	return cfg, nil
+}
+
+func (s *LookupSource) Provision(_ api.StreamContext, props map[string]any) error {
+	cfg, err := parseLookupConfig(props)
+	if err != nil {
+		return err
+	}
+	if cfg.Path == "" {
+		return errors.New("missing property Path")
+	}
+	if cfg.FileName == "" {
+		return errors.New("missing datasource(file name)")
+	}
+	if !filepath.IsAbs(cfg.Path) {
+		p, err := conf.GetLoc(cfg.Path)
+		if err != nil {
+			return fmt.Errorf("invalid path %s", cfg.Path)
+		}
+		cfg.Path = p
+	}
+	s.file = filepath.Join(cfg.Path, cfg.FileName)
+	s.geomCol = cfg.GeometryColumn
+	return nil
+}
+
+func (s *LookupSource) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	err := s.load()
+	if err != nil {
+		sch(api.ConnectionDisconnected, err.Error())
+		return err
+	}
+	ctx.GetLogger().Infof("file lookup source %s is loaded with %d rows and %d indexed geometries", s.file, len(s.rows), len(s.rowIds))
+	sch(api.ConnectionConnected, "")
+	return nil
+}
+
+func (s *LookupSource) load() error {
+	content, err := os.ReadFile(s.file)
+	if err != nil {
+		return fmt.Errorf("fail to read file %s: %v", s.file, err)
+	}
+	var data any
+	if err := json.Unmarshal(content, &data); err != nil {
+		return fmt.Errorf("fail to parse file %s: %v", s.file, err)
+	}
+	switch dt := data.(type) {
+	case []any:
+		if s.geomCol != "" {
+			return fmt.Errorf("file %s must be a GeoJSON FeatureCollection to index the geometry column %s", s.file, s.geomCol)
+		}
+		s.rows = make([]map[string]any, 0, len(dt))
+		for _, item := range dt {
+			m, ok := item.(map[string]any)
+			if !ok {
+				return fmt.Errorf("file %s must be an array of objects but got item %v", s.file, item)
+			}
+			s.rows = append(s.rows, m)
+		}
+		return nil
+	case map[string]any:
+		if dt["type"] == "FeatureCollection" {
+			return s.loadFeatures(dt)
+		}
+	}
+	return fmt.Errorf("file %s must be a JSON array or a GeoJSON FeatureCollection", s.file)
+}
+
+func (s *LookupSource) loadFeatures(fc map[string]any) error {
+	features, _ := fc["features"].([]any)
+	s.rows = make([]map[string]any, 0, len(features))
+	s.geoms = make([]*geo.Geometry, 0, len(features))
+	col := s.geomCol
+	if col == "" {
+		col = GeometryColumn
+	}
+	var rects []geo.Rect
+	for i, f := range features {
+		feature, ok := f.(map[string]any)
+		if !ok {
+			return fmt.Errorf("invalid feature %d in file %s", i, s.file)
+		}
+		row := make(map[string]any)
+		if props, ok := feature["properties"].(map[string]any); ok {
+			for k, v := range props {
+				row[k] = v
+			}
+		}
+		if id, ok := feature["id"]; ok {
+			if _, exist := row["id"]; !exist {
+				row["id"] = id
+			}
+		}
+		var g *geo.Geometry
+		if gm, ok := feature["geometry"].(map[string]any); ok {
+			var err error
+			g, err = geo.FromGeoJSON(gm)
+			if err != nil {
+				return fmt.Errorf("invalid geometry of feature %d in file %s: %v", i, s.file, err)
+			}
+			row[col] = g.GeoJSON()
+			if s.geomCol != "" && g.IsPolygonal() {
+				rects = append(rects, g.Bound())
+				s.rowIds = append(s.rowIds, len(s.rows))
+			}
+		}
+		s.rows = append(s.rows, row)
+		s.geoms = append(s.geoms, g)
+	}
+	if s.geomCol != "" {
+		s.index = geo.NewIndex(rects)
+	}
+	return nil
+}
+
+// GeometryColumn returns the declared geometry column of the json file. The file is not read, so whether it is a
+// GeoJSON FeatureCollection is checked when connected.
+func (s *LookupSource) GeometryColumn(props map[string]any) string {
+	cfg, err := parseLookupConfig(props)
+	if err != nil {
+		return ""
+	}
+	return cfg.GeometryColumn
+}
+
+func (s *LookupSource) Lookup(ctx api.StreamContext, _ []string, keys []string, values []any) ([]map[string]any, error) {
+	ctx.GetLogger().Debugf("file lookup source %s is looking up keys %v with values %v", s.file, keys, values)
+	var candidates []int
+	spatial := false
+	for i, k := range keys {
+		if k != s.geomCol || s.index == nil {
+			continue
+		}
+		if values[i] == nil {
+			return nil, nil
+		}
+		g, err := geo.Parse(values[i])
+		if err != nil {
+			return nil, err
+		}
+		if g.Type != geo.TypePoint {
+			return nil, fmt.Errorf("lookup by geometry only supports point but got %s", g.Type)
+		}
+		p := g.Points[0]
+		matched := make([]int, 0)
+		for _, item := range s.index.Search(p) {
+			rowId := s.rowIds[item]
+			if (!spatial || contains(candidates, rowId)) && s.geoms[rowId].Contains(p) {
+				matched = append(matched, rowId)
+			}
+		}
+		candidates = matched
+		spatial = true
+	}
+	if !spatial {
+		candidates = make([]int, len(s.rows))
+		for i := range s.rows {
+			candidates[i] = i
+		}
+	}
+	result := make([]map[string]any, 0, len(candidates))
+	for _, rowId := range candidates {
+		row := s.rows[rowId]
+		matched := true
+		for i, k := range keys {
+			if k == s.geomCol && s.index != nil {
+				continue
+			}
+			if !valueEqual(row[k], values[i]) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			r := make(map[string]any, len(row))
+			for k, v := range row {
+				r[k] = v
+			}
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+func contains(ids []int, id int) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+// valueEqual compares the values and regards the numbers of different types as equal if they have the same value
+func valueEqual(a, b any) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	fa, err := cast.ToFloat64(a, cast.CONVERT_SAMEKIND)
+	if err != nil {
+		return false
+	}
+	fb, err := cast.ToFloat64(b, cast.CONVERT_SAMEKIND)
+	return err == nil && fa == fb
+}
+
+func (s *LookupSource) Close(ctx api.StreamContext) error {
+	ctx.GetLogger().Infof("file lookup source %s is closing", s.file)
+	s.rows = nil
+	s.geoms = nil
+	s.rowIds = nil
+	s.index = nil
+	return nil
+}
+
+func GetLookupSource() api.Source {
+	return &LookupSource{}
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/require"
+
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
+)
+
+func TestLookupSource(t *testing.T) {
+	path, err := filepath.Abs("test/lookup")
+	require.NoError(t, err)
+	ctx := mockContext.NewMockContext("testLookup", "op")
+	tests := []struct {
+		name   string
+		file   string
+		keys   []string
+		values []any
+		result []string
+		err    string
+	}{
+		{
+			name:   "json equal",
+			file:   "devices.json",
+			keys:   []string{"kind"},
+			values: []any{"sensor"},
+			result: []string{"device1", "device3"},
+		},
+		{
+			name:   "json number",
+			file:   "devices.json",
+			keys:   []string{"id", "kind"},
+			values: []any{int64(3), "sensor"},
+			result: []string{"device3"},
+		},
+		{
+			name:   "spatial",
+			file:   "fences.geojson",
+			keys:   []string{"geometry"},
+			values: []any{"POINT (116.42 39.92)"},
+			result: []string{"warehouse", "yard"},
+		},
+		{
+			name:   "spatial in hole",
+			file:   "fences.geojson",
+			keys:   []string{"geometry"},
+			values: []any{map[string]any{"type": "Point", "coordinates": []any{116.5, 40.0}}},
+			result: []string{"warehouse"},
+		},
+		{
+			name:   "spatial and equal",
+			file:   "fences.geojson",
+			keys:   []string{"zone", "geometry"},
+			values: []any{"B", []any{116.42, 39.92}},
+			result: []string{"yard"},
+		},
+		{
+			name:   "spatial miss",
+			file:   "fences.geojson",
+			keys:   []string{"geometry"},
+			values: []any{"POINT (100 30)"},
+			result: []string{},
+		},
+		{
+			name:   "feature id",
+			file:   "fences.geojson",
+			keys:   []string{"id"},
+			values: []any{3},
+			result: []string{"dock"},
+		},
+		{
+			name:   "spatial not point",
+			file:   "fences.geojson",
+			keys:   []string{"geometry"},
+			values: []any{"LINESTRING (1 1, 2 2)"},
+			err:    "lookup by geometry only supports point but got LineString",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := GetLookupSource().(*LookupSource)
+			props := map[string]any{"path": path, "datasource": tt.file}
+			if filepath.Ext(tt.file) == ".geojson" {
+				props["geometryColumn"] = "geometry"
+			}
+			require.NoError(t, s.Provision(ctx, props))
+			require.NoError(t, s.Connect(ctx, func(status string, message string) {}))
+			defer s.Close(ctx)
+			r, err := s.Lookup(ctx, nil, tt.keys, tt.values)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			names := make([]string, 0, len(r))
+			for _, row := range r {
+				names = append(names, row["name"].(string))
+			}
+			sort.Strings(names)
+			require.Equal(t, tt.result, names)
+		})
+	}
+}
+
+func TestLookupSourceGeometry(t *testing.T) {
+	path, err := filepath.Abs("test/lookup")
+	require.NoError(t, err)
+	ctx := mockContext.NewMockContext("testLookup", "op")
+	var s api.Source = GetLookupSource()
+	// The geometry column is decided by the properties only
+	col, ok := modules.GetSpatialLookupColumn(s, map[string]any{"datasource": "notexist.geojson"})
+	require.True(t, ok)
+	require.Equal(t, "", col)
+	col, _ = modules.GetSpatialLookupColumn(s, map[string]any{"datasource": "notexist.geojson", "geometryColumn": "area"})
+	require.Equal(t, "area", col)
+	col, _ = modules.GetSpatialLookupColumn(s, map[string]any{"datasource": "notexist.geojson", "fileType": "csv", "geometryColumn": "area"})
+	require.Equal(t, "", col)
+
+	require.NoError(t, s.Provision(ctx, map[string]any{"path": path, "datasource": "fences.geojson", "geometryColumn": "area"}))
+	require.NoError(t, s.Connect(ctx, func(status string, message string) {}))
+	r, err := s.(api.LookupSource).Lookup(ctx, nil, []string{"area"}, []any{"POINT (116.31 39.81)"})
+	require.NoError(t, err)
+	require.Equal(t, []map[string]any{{
+		"id":   1.0,
+		"name": "warehouse",
+		"zone": "A",
+		"area": map[string]any{
+			"type":        "Polygon",
+			"coordinates": []any{[]any{[]any{116.3, 39.8}, []any{116.5, 39.8}, []any{116.5, 40.0}, []any{116.3, 40.0}, []any{116.3, 39.8}}},
+		},
+	}}, r)
+	require.NoError(t, s.Close(ctx))
+
+	// Without the declared column, the geometries are not indexed
+	require.NoError(t, s.Provision(ctx, map[string]any{"path": path, "datasource": "fences.geojson"}))
+	require.NoError(t, s.Connect(ctx, func(status string, message string) {}))
+	require.Nil(t, s.(*LookupSource).index)
+	require.NoError(t, s.Close(ctx))
+
+	// The JSON array cannot be indexed
+	require.NoError(t, s.Provision(ctx, map[string]any{"path": path, "datasource": "devices.json", "geometryColumn": "geometry"}))
+	err = s.Connect(ctx, func(status string, message string) {})
+	require.EqualError(t, err, "file "+filepath.Join(path, "devices.json")+" must be a GeoJSON FeatureCollection to index the geometry column geometry")
+
+	err = s.Provision(ctx, map[string]any{"path": path, "datasource": "fences.geojson", "fileType": "csv"})
+	require.EqualError(t, err, "file lookup table only supports json file type but got csv")
+	s = GetLookupSource()
+	require.NoError(t, s.Provision(ctx, map[string]any{"path": path, "datasource": "notexist.json"}))
+	err = s.Connect(ctx, func(status string, message string) {})
+	require.Error(t, err)
+}
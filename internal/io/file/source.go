@@ -50,6 +50,8 @@ type SourceConfig struct {
 	IgnoreEndLines   int               `json:"ignoreEndLines"`
 	// Only use for planning
 	Decompression string `json:"decompression"`
+	// Only use for the lookup table to index the geometries of a GeoJSON file
+	GeometryColumn string `json:"geometryColumn"`
 	// state
 	rewindMeta *FileDirSourceRewindMeta
 }
@@ -122,6 +122,28 @@ func UnregisterEndpoint(endpoint, method string) {
 	m.UnregisterEndpoint(endpoint, method)
 }
 
+// RegisterHandler serves the endpoint by the handler directly. It is used by the sinks which expose
+// the data to be pulled, so that the endpoint can only be registered once.
+func RegisterHandler(endpoint string, method string, h http.HandlerFunc) error {
+	managerLock.RLock()
+	m := manager
+	managerLock.RUnlock()
+	if m == nil {
+		return fmt.Errorf("http server is not running")
+	}
+	return m.RegisterHandler(endpoint, method, h)
+}
+
+func UnregisterHandler(endpoint, method string) {
+	managerLock.RLock()
+	m := manager
+	managerLock.RUnlock()
+	if m == nil {
+		return
+	}
+	m.UnregisterHandler(endpoint, method)
+}
+
 const (
 	TopicPrefix = "$$httppush/"
 )
@@ -175,6 +197,39 @@ func (m *GlobalServerManager) UnregisterEndpoint(endpoint, method string) {
 	pubsub.RemovePub(TopicPrefix + key)
 }
 
+func (m *GlobalServerManager) RegisterHandler(endpoint string, method string, h http.HandlerFunc) error {
+	key := buildKey(endpoint, method)
+	m.Lock()
+	defer m.Unlock()
+	if _, ok := m.endpoint[key]; ok {
+		return fmt.Errorf("endpoint %s %s is already registered", method, endpoint)
+	}
+	m.endpoint[key] = ""
+	m.routes[endpoint] = h
+	m.router.HandleFunc(endpoint, func(w http.ResponseWriter, r *http.Request) {
+		m.RLock()
+		h, ok := m.routes[endpoint]
+		m.RUnlock()
+		if ok {
+			h(w, r)
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}).Methods(method)
+	return nil
+}
+
+func (m *GlobalServerManager) UnregisterHandler(endpoint, method string) {
+	key := buildKey(endpoint, method)
+	m.Lock()
+	defer m.Unlock()
+	if _, ok := m.endpoint[key]; !ok {
+		return
+	}
+	delete(m.endpoint, key)
+	delete(m.routes, endpoint)
+}
+
 func (m *GlobalServerManager) Shutdown() {
 	m.server.Shutdown(context.Background())
 }
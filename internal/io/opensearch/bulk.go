@@ -0,0 +1,100 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opensearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+const (
+	actionIndex  = "index"
+	actionUpdate = "update"
+	actionDelete = "delete"
+)
+
+// bulkItem is one action of the bulk request. The encoded lines are kept to resend the failed items.
+type bulkItem struct {
+	action string
+	index  string
+	id     string
+	lines  []byte
+}
+
+// newBulkItem encodes the action line and the optional source line. The body is nil for delete.
+func newBulkItem(action, index, id string, body any) (*bulkItem, error) {
+	meta := map[string]string{"_index": index}
+	if id != "" {
+		meta["_id"] = id
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	if err := enc.Encode(map[string]any{action: meta}); err != nil {
+		return nil, err
+	}
+	if body != nil {
+		if err := enc.Encode(body); err != nil {
+			return nil, fmt.Errorf("fail to encode document: %v", err)
+		}
+	}
+	return &bulkItem{action: action, index: index, id: id, lines: buf.Bytes()}, nil
+}
+
+func encodeBulk(items []*bulkItem) []byte {
+	var buf bytes.Buffer
+	for _, item := range items {
+		buf.Write(item.lines)
+	}
+	return buf.Bytes()
+}
+
+type bulkResponse struct {
+	Errors bool                        `json:"errors"`
+	Items  []map[string]bulkItemResult `json:"items"`
+}
+
+type bulkItemResult struct {
+	Status int `json:"status"`
+	Error  *struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error"`
+}
+
+// retriable tells if the failed item or request may succeed by retrying
+func retriable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// parseBulkResponse returns the results in the same order of the request items
+func parseBulkResponse(body []byte, count int) ([]bulkItemResult, error) {
+	resp := &bulkResponse{}
+	if err := json.Unmarshal(body, resp); err != nil {
+		return nil, fmt.Errorf("invalid bulk response: %v", err)
+	}
+	if len(resp.Items) != count {
+		return nil, fmt.Errorf("invalid bulk response: expect %d items but got %d", count, len(resp.Items))
+	}
+	result := make([]bulkItemResult, count)
+	for i, item := range resp.Items {
+		// Each item has only one key which is the action name
+		for _, r := range item {
+			result[i] = r
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,295 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opensearch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/util"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cert"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+)
+
+type conf struct {
+	Url           string            `json:"url"`
+	Index         string            `json:"index"`
+	KeyField      string            `json:"keyField"`
+	RowkindField  string            `json:"rowkindField"`
+	Username      string            `json:"username"`
+	Password      string            `json:"password"`
+	ApiKey        string            `json:"apiKey"`
+	Timeout       cast.DurationConf `json:"timeout"`
+	MaxRetries    int               `json:"maxRetries"`
+	RetryInterval cast.DurationConf `json:"retryInterval"`
+}
+
+// Sink writes the results to OpenSearch or Elasticsearch by the bulk API. Set the batchSize or lingerInterval
+// of the sink to send multiple documents in one request. The index supports the data template. The failed
+// items which are retriable are resent and the others are returned as error.
+type Sink struct {
+	conf *conf
+	cli  *http.Client
+}
+
+func (s *Sink) Provision(ctx api.StreamContext, props map[string]any) error {
+	c := &conf{
+		Url:           "http://localhost:9200",
+		Timeout:       cast.DurationConf(5 * time.Second),
+		MaxRetries:    3,
+		RetryInterval: cast.DurationConf(time.Second),
+	}
+	if err := cast.MapToStruct(props, c); err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	if !strings.HasPrefix(c.Url, "http://") && !strings.HasPrefix(c.Url, "https://") {
+		return fmt.Errorf("invalid url %s, should start with http:// or https://", c.Url)
+	}
+	c.Url = strings.TrimSuffix(c.Url, "/")
+	if c.Index == "" {
+		return fmt.Errorf("index is required")
+	}
+	if c.ApiKey != "" && c.Username != "" {
+		return fmt.Errorf("apiKey and username can not be set together")
+	}
+	if c.Timeout <= 0 {
+		return fmt.Errorf("timeout should be positive")
+	}
+	if c.MaxRetries < 0 {
+		return fmt.Errorf("maxRetries should not be negative")
+	}
+	tlsConf, err := cert.GenTLSConfig(ctx, props)
+	if err != nil {
+		return fmt.Errorf("error configuring tls: %v", err)
+	}
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.TLSClientConfig = tlsConf
+	s.cli = &http.Client{Transport: tr, Timeout: time.Duration(c.Timeout)}
+	s.conf = c
+	return nil
+}
+
+func (s *Sink) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	if err := s.ping(ctx); err != nil {
+		sch(api.ConnectionDisconnected, err.Error())
+		return err
+	}
+	sch(api.ConnectionConnected, "")
+	return nil
+}
+
+func (s *Sink) Ping(ctx api.StreamContext, props map[string]any) error {
+	if err := s.Provision(ctx, props); err != nil {
+		return err
+	}
+	return s.ping(ctx)
+}
+
+func (s *Sink) ping(ctx api.StreamContext) error {
+	resp, err := s.do(ctx, http.MethodGet, s.conf.Url, nil)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("error connecting to %s: %s", s.conf.Url, resp.Status)
+	}
+	return nil
+}
+
+func (s *Sink) Collect(ctx api.StreamContext, item api.MessageTuple) error {
+	return s.collect(ctx, []api.MessageTuple{item}, nil)
+}
+
+func (s *Sink) CollectList(ctx api.StreamContext, items api.MessageTupleList) error {
+	tuples := make([]api.MessageTuple, 0, items.Len())
+	items.RangeOfTuples(func(_ int, tuple api.MessageTuple) bool {
+		tuples = append(tuples, tuple)
+		return true
+	})
+	listProps, _ := items.(api.HasDynamicProps)
+	return s.collect(ctx, tuples, listProps)
+}
+
+// collect sends the tuples in one bulk request and resends the retriable failed items. The items which
+// can not be encoded or still fail after retries are reported together.
+func (s *Sink) collect(ctx api.StreamContext, tuples []api.MessageTuple, listProps api.HasDynamicProps) error {
+	var errs []string
+	pending := make([]*bulkItem, 0, len(tuples))
+	for _, tuple := range tuples {
+		item, err := s.toBulkItem(tuple, listProps)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		pending = append(pending, item)
+	}
+	for retry := 0; len(pending) > 0; retry++ {
+		results, err := s.bulk(ctx, pending)
+		if err != nil {
+			return err
+		}
+		var failed []*bulkItem
+		for i, r := range results {
+			item := pending[i]
+			switch {
+			case r.Status < http.StatusMultipleChoices:
+			// Delete a document which does not exist is not an error
+			case r.Status == http.StatusNotFound && item.action == actionDelete:
+			case retriable(r.Status) && retry < s.conf.MaxRetries:
+				failed = append(failed, item)
+			default:
+				msg := fmt.Sprintf("%s %s/%s: %d", item.action, item.index, item.id, r.Status)
+				if r.Error != nil {
+					msg = fmt.Sprintf("%s %s %s", msg, r.Error.Type, r.Error.Reason)
+				}
+				errs = append(errs, msg)
+			}
+		}
+		if len(failed) > 0 {
+			ctx.GetLogger().Warnf("opensearch sink retry %d failed items", len(failed))
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(s.conf.RetryInterval)):
+			}
+		}
+		pending = failed
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("opensearch sink fails to write %d of %d documents: %s", len(errs), len(tuples), strings.Join(errs, "; "))
+	}
+	ctx.GetLogger().Debugf("write %d documents to opensearch success", len(tuples))
+	return nil
+}
+
+func (s *Sink) toBulkItem(tuple api.MessageTuple, listProps api.HasDynamicProps) (*bulkItem, error) {
+	data := tuple.ToMap()
+	// If the props support dynamic props(template), planner will guarantee the result has the parsed dynamic props
+	// The tuple of a batch has its own props while the transformed list only has the props of the whole list
+	index := s.conf.Index
+	for _, dp := range []any{tuple, listProps} {
+		if p, ok := dp.(api.HasDynamicProps); ok {
+			if v, ok := p.DynamicProps(s.conf.Index); ok {
+				index = v
+				break
+			}
+		}
+	}
+	var id string
+	if s.conf.KeyField != "" {
+		v, ok := data[s.conf.KeyField]
+		if !ok {
+			return nil, fmt.Errorf("key field %s does not exist in data %v", s.conf.KeyField, data)
+		}
+		var err error
+		id, err = cast.ToString(v, cast.CONVERT_ALL)
+		if err != nil {
+			return nil, fmt.Errorf("key must be string or convertible to string, but got %v", v)
+		}
+	}
+	rowkind := ast.RowkindInsert
+	if s.conf.RowkindField != "" {
+		if c, ok := data[s.conf.RowkindField]; ok {
+			rowkind, ok = c.(string)
+			if !ok {
+				return nil, fmt.Errorf("rowkind field %s is not a string in data %v", s.conf.RowkindField, data)
+			}
+		}
+	}
+	switch rowkind {
+	case ast.RowkindInsert:
+		return newBulkItem(actionIndex, index, id, data)
+	case ast.RowkindUpsert, ast.RowkindUpdate:
+		if id == "" {
+			return nil, fmt.Errorf("keyField is required for rowkind %s", rowkind)
+		}
+		return newBulkItem(actionUpdate, index, id, map[string]any{"doc": data, "doc_as_upsert": rowkind == ast.RowkindUpsert})
+	case ast.RowkindDelete:
+		if id == "" {
+			return nil, fmt.Errorf("keyField is required for rowkind %s", rowkind)
+		}
+		return newBulkItem(actionDelete, index, id, nil)
+	default:
+		return nil, fmt.Errorf("invalid rowkind %s", rowkind)
+	}
+}
+
+// bulk sends the items and returns the result of each item. The failure of the whole request is an IO error.
+func (s *Sink) bulk(ctx api.StreamContext, items []*bulkItem) ([]bulkItemResult, error) {
+	resp, err := s.do(ctx, http.MethodPost, s.conf.Url+"/_bulk", encodeBulk(items))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errorx.NewIOErr(fmt.Sprintf("opensearch sink fails to read the bulk response: %v", err))
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		msg := fmt.Sprintf("opensearch bulk request error: %s %s", resp.Status, body)
+		if retriable(resp.StatusCode) {
+			return nil, errorx.NewIOErr(msg)
+		}
+		return nil, errors.New(msg)
+	}
+	return parseBulkResponse(body, len(items))
+}
+
+func (s *Sink) do(ctx api.StreamContext, method, url string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-ndjson")
+	}
+	if s.conf.ApiKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+s.conf.ApiKey)
+	} else if s.conf.Username != "" {
+		req.SetBasicAuth(s.conf.Username, s.conf.Password)
+	}
+	resp, err := s.cli.Do(req)
+	if err != nil {
+		return nil, errorx.NewIOErr(fmt.Sprintf("opensearch sink fails to send out the data: %v", err))
+	}
+	return resp, nil
+}
+
+func (s *Sink) Close(ctx api.StreamContext) error {
+	ctx.GetLogger().Infof("Closing opensearch sink")
+	if s.cli != nil {
+		s.cli.CloseIdleConnections()
+	}
+	return nil
+}
+
+func GetSink() api.Sink {
+	return &Sink{}
+}
+
+var (
+	_ api.TupleCollector = &Sink{}
+	_ util.PingableConn  = &Sink{}
+)
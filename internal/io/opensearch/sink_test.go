@@ -0,0 +1,252 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opensearch
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+type action struct {
+	name string
+	meta map[string]string
+	body map[string]any
+}
+
+// fakeServer records the bulk actions and replies the item status by the status function
+type fakeServer struct {
+	sync.Mutex
+	*httptest.Server
+	requests [][]action
+	headers  []http.Header
+	status   func(round int, a action) int
+}
+
+func newFakeServer(t *testing.T, status func(round int, a action) int) *fakeServer {
+	fs := &fakeServer{status: status}
+	fs.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		fs.Lock()
+		defer fs.Unlock()
+		round := len(fs.requests)
+		fs.headers = append(fs.headers, r.Header.Clone())
+		var (
+			actions []action
+			items   []map[string]any
+			errs    bool
+		)
+		sc := bufio.NewScanner(r.Body)
+		for sc.Scan() {
+			m := map[string]map[string]string{}
+			require.NoError(t, json.Unmarshal(sc.Bytes(), &m))
+			var a action
+			for k, v := range m {
+				a.name, a.meta = k, v
+			}
+			if a.name != actionDelete {
+				require.True(t, sc.Scan())
+				require.NoError(t, json.Unmarshal(sc.Bytes(), &a.body))
+			}
+			actions = append(actions, a)
+			st := fs.status(round, a)
+			r := map[string]any{"_index": a.meta["_index"], "status": st}
+			if st >= 300 {
+				errs = true
+				r["error"] = map[string]any{"type": "test_exception", "reason": "failed"}
+			}
+			items = append(items, map[string]any{a.name: r})
+		}
+		fs.requests = append(fs.requests, actions)
+		_ = json.NewEncoder(w).Encode(map[string]any{"took": 1, "errors": errs, "items": items})
+	}))
+	t.Cleanup(fs.Close)
+	return fs
+}
+
+func tupleList(props map[string]string, maps ...map[string]any) api.MessageTupleList {
+	content := make([]api.MessageTuple, 0, len(maps))
+	for _, m := range maps {
+		content = append(content, &xsql.Tuple{Message: m})
+	}
+	return &xsql.TransformedTupleList{Content: content, Maps: maps, Props: props}
+}
+
+func TestProvisionErr(t *testing.T) {
+	ctx := mockContext.NewMockContext("1", "2")
+	tests := []struct {
+		name  string
+		props map[string]any
+		err   string
+	}{
+		{
+			name:  "invalid url",
+			props: map[string]any{"url": "localhost:9200", "index": "a"},
+			err:   "invalid url localhost:9200, should start with http:// or https://",
+		},
+		{
+			name:  "no index",
+			props: map[string]any{},
+			err:   "index is required",
+		},
+		{
+			name:  "auth conflict",
+			props: map[string]any{"index": "a", "apiKey": "k", "username": "u"},
+			err:   "apiKey and username can not be set together",
+		},
+		{
+			name:  "invalid timeout",
+			props: map[string]any{"index": "a", "timeout": "0s"},
+			err:   "timeout should be positive",
+		},
+		{
+			name:  "invalid retries",
+			props: map[string]any{"index": "a", "maxRetries": -1},
+			err:   "maxRetries should not be negative",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.EqualError(t, GetSink().Provision(ctx, tt.props), tt.err)
+		})
+	}
+}
+
+func TestCollectList(t *testing.T) {
+	fs := newFakeServer(t, func(int, action) int { return http.StatusOK })
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	s := GetSink().(*Sink)
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"url":          fs.URL + "/",
+		"index":        "logs-{{.day}}",
+		"keyField":     "id",
+		"rowkindField": "action",
+		"apiKey":       "secret",
+	}))
+	require.NoError(t, s.Connect(ctx, func(string, string) {}))
+	defer s.Close(ctx)
+	err := s.CollectList(ctx, tupleList(map[string]string{"logs-{{.day}}": "logs-2026.10.18"},
+		map[string]any{"id": 1, "v": 10},
+		map[string]any{"id": 2, "v": 20, "action": "upsert"},
+		map[string]any{"id": 3, "v": 30, "action": "update"},
+		map[string]any{"id": 4, "action": "delete"},
+	))
+	require.NoError(t, err)
+	require.Equal(t, "ApiKey secret", fs.headers[0].Get("Authorization"))
+	require.Equal(t, "application/x-ndjson", fs.headers[0].Get("Content-Type"))
+	meta := func(id string) map[string]string {
+		return map[string]string{"_index": "logs-2026.10.18", "_id": id}
+	}
+	require.Equal(t, [][]action{{
+		{name: "index", meta: meta("1"), body: map[string]any{"id": float64(1), "v": float64(10)}},
+		{name: "update", meta: meta("2"), body: map[string]any{"doc": map[string]any{"id": float64(2), "v": float64(20), "action": "upsert"}, "doc_as_upsert": true}},
+		{name: "update", meta: meta("3"), body: map[string]any{"doc": map[string]any{"id": float64(3), "v": float64(30), "action": "update"}, "doc_as_upsert": false}},
+		{name: "delete", meta: meta("4")},
+	}}, fs.requests)
+
+	// Single tuple without key field gets the generated id
+	s.conf.KeyField = ""
+	require.NoError(t, s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"v": 1}, Props: map[string]string{"logs-{{.day}}": "logs-2026.10.19"}}))
+	require.Equal(t, []action{{name: "index", meta: map[string]string{"_index": "logs-2026.10.19"}, body: map[string]any{"v": float64(1)}}}, fs.requests[1])
+}
+
+func TestCollectItemErr(t *testing.T) {
+	fs := newFakeServer(t, func(round int, a action) int {
+		switch a.meta["_id"] {
+		case "1":
+			// Succeed after retry
+			if round == 0 {
+				return http.StatusTooManyRequests
+			}
+		case "2":
+			return http.StatusBadRequest
+		case "3":
+			return http.StatusServiceUnavailable
+		case "4":
+			return http.StatusNotFound
+		}
+		return http.StatusCreated
+	})
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	s := GetSink().(*Sink)
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"url":           fs.URL,
+		"index":         "test",
+		"keyField":      "id",
+		"rowkindField":  "action",
+		"username":      "admin",
+		"password":      "pass",
+		"maxRetries":    2,
+		"retryInterval": "10ms",
+	}))
+	err := s.CollectList(ctx, tupleList(nil,
+		map[string]any{"id": "1"},
+		map[string]any{"id": "2"},
+		map[string]any{"id": "3"},
+		map[string]any{"id": "4", "action": "delete"},
+		map[string]any{"id": "5", "action": "unknown"},
+		map[string]any{"action": "delete"},
+	))
+	require.EqualError(t, err, "opensearch sink fails to write 4 of 6 documents: invalid rowkind unknown; key field id does not exist in data map[action:delete]; index test/2: 400 test_exception failed; index test/3: 503 test_exception failed")
+	require.False(t, errorx.IsIOError(err))
+	u, p, ok := (&http.Request{Header: fs.headers[0]}).BasicAuth()
+	require.True(t, ok)
+	require.Equal(t, "admin", u)
+	require.Equal(t, "pass", p)
+	// The retriable items are resent until the max retries
+	require.Len(t, fs.requests, 3)
+	require.Len(t, fs.requests[0], 4)
+	require.Len(t, fs.requests[1], 2)
+	require.Equal(t, "3", fs.requests[2][0].meta["_id"])
+}
+
+func TestBulkRequestErr(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("unavailable"))
+	}))
+	defer srv.Close()
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	s := GetSink().(*Sink)
+	require.NoError(t, s.Provision(ctx, map[string]any{"url": srv.URL, "index": "test"}))
+	err := s.Connect(ctx, func(string, string) {})
+	require.EqualError(t, err, "error connecting to "+srv.URL+": 503 Service Unavailable")
+
+	item := &xsql.Tuple{Message: map[string]any{"v": 1}}
+	err = s.Collect(ctx, item)
+	require.EqualError(t, err, "opensearch bulk request error: 503 Service Unavailable unavailable")
+	require.True(t, errorx.IsIOError(err))
+	status = http.StatusUnauthorized
+	err = s.Collect(ctx, item)
+	require.EqualError(t, err, "opensearch bulk request error: 401 Unauthorized unavailable")
+	require.False(t, errorx.IsIOError(err))
+	srv.Close()
+	err = s.Collect(ctx, item)
+	require.True(t, errorx.IsIOError(err))
+}
@@ -0,0 +1,281 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"time"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+)
+
+// record is a log record, a metric data point or a span converted to a tuple with its event time
+type record struct {
+	data map[string]any
+	ts   time.Time
+}
+
+var invalidFieldChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// converter flattens the resource attributes into the fields with the prefix. The dots in the attribute
+// names are replaced by underscores so that they can be referred in SQL directly.
+type converter struct {
+	resourcePrefix string
+}
+
+func (c *converter) newRecord(res *resourcepb.Resource, scope *commonpb.InstrumentationScope) map[string]any {
+	m := make(map[string]any)
+	for _, kv := range res.GetAttributes() {
+		m[c.resourcePrefix+invalidFieldChars.ReplaceAllString(kv.Key, "_")] = anyValue(kv.Value)
+	}
+	m["scopeName"] = scope.GetName()
+	m["scopeVersion"] = scope.GetVersion()
+	return m
+}
+
+func (c *converter) logs(req *collogspb.ExportLogsServiceRequest) []record {
+	var result []record
+	for _, rl := range req.ResourceLogs {
+		for _, sl := range rl.ScopeLogs {
+			for _, lr := range sl.LogRecords {
+				m := c.newRecord(rl.Resource, sl.Scope)
+				m["timeUnixNano"] = int64(lr.TimeUnixNano)
+				m["observedTimeUnixNano"] = int64(lr.ObservedTimeUnixNano)
+				m["severityNumber"] = int64(lr.SeverityNumber)
+				m["severityText"] = lr.SeverityText
+				m["body"] = anyValue(lr.Body)
+				m["attributes"] = attributes(lr.Attributes)
+				m["traceId"] = hex.EncodeToString(lr.TraceId)
+				m["spanId"] = hex.EncodeToString(lr.SpanId)
+				m["flags"] = int64(lr.Flags)
+				m["eventName"] = lr.EventName
+				// The observed time is set by the collector when the time is unknown
+				t := lr.TimeUnixNano
+				if t == 0 {
+					t = lr.ObservedTimeUnixNano
+				}
+				result = append(result, record{data: m, ts: eventTime(t)})
+			}
+		}
+	}
+	return result
+}
+
+func (c *converter) metrics(req *colmetricspb.ExportMetricsServiceRequest) []record {
+	var result []record
+	for _, rm := range req.ResourceMetrics {
+		for _, sm := range rm.ScopeMetrics {
+			for _, metric := range sm.Metrics {
+				newPoint := func(typ string, attrs []*commonpb.KeyValue, start, t uint64) map[string]any {
+					m := c.newRecord(rm.Resource, sm.Scope)
+					m["name"] = metric.Name
+					m["description"] = metric.Description
+					m["unit"] = metric.Unit
+					m["type"] = typ
+					m["attributes"] = attributes(attrs)
+					m["startTimeUnixNano"] = int64(start)
+					m["timeUnixNano"] = int64(t)
+					result = append(result, record{data: m, ts: eventTime(t)})
+					return m
+				}
+				switch data := metric.Data.(type) {
+				case *metricspb.Metric_Gauge:
+					for _, dp := range data.Gauge.DataPoints {
+						m := newPoint("gauge", dp.Attributes, dp.StartTimeUnixNano, dp.TimeUnixNano)
+						m["value"] = numberValue(dp)
+					}
+				case *metricspb.Metric_Sum:
+					for _, dp := range data.Sum.DataPoints {
+						m := newPoint("sum", dp.Attributes, dp.StartTimeUnixNano, dp.TimeUnixNano)
+						m["value"] = numberValue(dp)
+						m["isMonotonic"] = data.Sum.IsMonotonic
+						m["aggregationTemporality"] = temporality(data.Sum.AggregationTemporality)
+					}
+				case *metricspb.Metric_Histogram:
+					for _, dp := range data.Histogram.DataPoints {
+						m := newPoint("histogram", dp.Attributes, dp.StartTimeUnixNano, dp.TimeUnixNano)
+						m["aggregationTemporality"] = temporality(data.Histogram.AggregationTemporality)
+						m["count"] = int64(dp.Count)
+						m["sum"] = optional(dp.Sum)
+						m["min"] = optional(dp.Min)
+						m["max"] = optional(dp.Max)
+						m["bucketCounts"] = counts(dp.BucketCounts)
+						m["explicitBounds"] = floats(dp.ExplicitBounds)
+					}
+				case *metricspb.Metric_ExponentialHistogram:
+					for _, dp := range data.ExponentialHistogram.DataPoints {
+						m := newPoint("exponentialHistogram", dp.Attributes, dp.StartTimeUnixNano, dp.TimeUnixNano)
+						m["aggregationTemporality"] = temporality(data.ExponentialHistogram.AggregationTemporality)
+						m["count"] = int64(dp.Count)
+						m["sum"] = optional(dp.Sum)
+						m["min"] = optional(dp.Min)
+						m["max"] = optional(dp.Max)
+						m["scale"] = int64(dp.Scale)
+						m["zeroCount"] = int64(dp.ZeroCount)
+						m["positive"] = buckets(dp.Positive)
+						m["negative"] = buckets(dp.Negative)
+					}
+				case *metricspb.Metric_Summary:
+					for _, dp := range data.Summary.DataPoints {
+						m := newPoint("summary", dp.Attributes, dp.StartTimeUnixNano, dp.TimeUnixNano)
+						m["count"] = int64(dp.Count)
+						m["sum"] = dp.Sum
+						quantiles := make([]any, 0, len(dp.QuantileValues))
+						for _, q := range dp.QuantileValues {
+							quantiles = append(quantiles, map[string]any{"quantile": q.Quantile, "value": q.Value})
+						}
+						m["quantiles"] = quantiles
+					}
+				}
+			}
+		}
+	}
+	return result
+}
+
+func (c *converter) traces(req *coltracepb.ExportTraceServiceRequest) []record {
+	var result []record
+	for _, rs := range req.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			for _, span := range ss.Spans {
+				m := c.newRecord(rs.Resource, ss.Scope)
+				m["traceId"] = hex.EncodeToString(span.TraceId)
+				m["spanId"] = hex.EncodeToString(span.SpanId)
+				m["parentSpanId"] = hex.EncodeToString(span.ParentSpanId)
+				m["traceState"] = span.TraceState
+				m["name"] = span.Name
+				m["kind"] = strings.TrimPrefix(span.Kind.String(), "SPAN_KIND_")
+				m["startTimeUnixNano"] = int64(span.StartTimeUnixNano)
+				m["endTimeUnixNano"] = int64(span.EndTimeUnixNano)
+				m["durationNano"] = int64(span.EndTimeUnixNano - span.StartTimeUnixNano)
+				m["attributes"] = attributes(span.Attributes)
+				m["statusCode"] = strings.TrimPrefix(span.GetStatus().GetCode().String(), "STATUS_CODE_")
+				m["statusMessage"] = span.GetStatus().GetMessage()
+				events := make([]any, 0, len(span.Events))
+				for _, e := range span.Events {
+					events = append(events, map[string]any{
+						"name":         e.Name,
+						"timeUnixNano": int64(e.TimeUnixNano),
+						"attributes":   attributes(e.Attributes),
+					})
+				}
+				m["events"] = events
+				links := make([]any, 0, len(span.Links))
+				for _, l := range span.Links {
+					links = append(links, map[string]any{
+						"traceId":    hex.EncodeToString(l.TraceId),
+						"spanId":     hex.EncodeToString(l.SpanId),
+						"attributes": attributes(l.Attributes),
+					})
+				}
+				m["links"] = links
+				result = append(result, record{data: m, ts: eventTime(span.StartTimeUnixNano)})
+			}
+		}
+	}
+	return result
+}
+
+func eventTime(nano uint64) time.Time {
+	if nano == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(nano))
+}
+
+func attributes(kvs []*commonpb.KeyValue) map[string]any {
+	m := make(map[string]any, len(kvs))
+	for _, kv := range kvs {
+		m[kv.Key] = anyValue(kv.Value)
+	}
+	return m
+}
+
+func anyValue(v *commonpb.AnyValue) any {
+	switch vt := v.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return vt.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return vt.BoolValue
+	case *commonpb.AnyValue_IntValue:
+		return vt.IntValue
+	case *commonpb.AnyValue_DoubleValue:
+		return vt.DoubleValue
+	case *commonpb.AnyValue_BytesValue:
+		return vt.BytesValue
+	case *commonpb.AnyValue_ArrayValue:
+		arr := make([]any, 0, len(vt.ArrayValue.GetValues()))
+		for _, e := range vt.ArrayValue.GetValues() {
+			arr = append(arr, anyValue(e))
+		}
+		return arr
+	case *commonpb.AnyValue_KvlistValue:
+		return attributes(vt.KvlistValue.GetValues())
+	default:
+		return nil
+	}
+}
+
+func numberValue(dp *metricspb.NumberDataPoint) any {
+	switch v := dp.Value.(type) {
+	case *metricspb.NumberDataPoint_AsDouble:
+		return v.AsDouble
+	case *metricspb.NumberDataPoint_AsInt:
+		return v.AsInt
+	default:
+		return nil
+	}
+}
+
+func temporality(t metricspb.AggregationTemporality) string {
+	return strings.TrimPrefix(t.String(), "AGGREGATION_TEMPORALITY_")
+}
+
+func optional(v *float64) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func counts(cs []uint64) []any {
+	result := make([]any, len(cs))
+	for i, c := range cs {
+		result[i] = int64(c)
+	}
+	return result
+}
+
+func floats(fs []float64) []any {
+	result := make([]any, len(fs))
+	for i, f := range fs {
+		result[i] = f
+	}
+	return result
+}
+
+func buckets(b *metricspb.ExponentialHistogramDataPoint_Buckets) map[string]any {
+	return map[string]any{
+		"offset":       int64(b.GetOffset()),
+		"bucketCounts": counts(b.GetBucketCounts()),
+	}
+}
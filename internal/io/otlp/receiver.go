@@ -0,0 +1,322 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"sync"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/internal/io/http/httpserver"
+)
+
+const (
+	signalLogs    = "logs"
+	signalMetrics = "metrics"
+	signalTraces  = "traces"
+)
+
+// consumer receives the decoded request of the signal. The request is shared by all consumers and must not be modified.
+type consumer func(req proto.Message)
+
+// hub dispatches the requests of an endpoint to the subscribed sources by signal
+type hub struct {
+	sync.RWMutex
+	subs map[string]map[string]consumer
+}
+
+func newHub() *hub {
+	return &hub{subs: make(map[string]map[string]consumer)}
+}
+
+// subscribe returns true if it is the first subscription of the signal
+func (h *hub) subscribe(signal, id string, c consumer) bool {
+	h.Lock()
+	defer h.Unlock()
+	subs, ok := h.subs[signal]
+	if !ok {
+		subs = make(map[string]consumer)
+		h.subs[signal] = subs
+	}
+	subs[id] = c
+	return !ok
+}
+
+// unsubscribe returns true if there is no subscription of the signal anymore
+func (h *hub) unsubscribe(signal, id string) bool {
+	h.Lock()
+	defer h.Unlock()
+	subs, ok := h.subs[signal]
+	if !ok {
+		return false
+	}
+	delete(subs, id)
+	if len(subs) == 0 {
+		delete(h.subs, signal)
+		return true
+	}
+	return false
+}
+
+func (h *hub) dispatch(signal string, req proto.Message) {
+	h.RLock()
+	defer h.RUnlock()
+	for _, c := range h.subs[signal] {
+		c(req)
+	}
+}
+
+func newRequest(signal string) proto.Message {
+	switch signal {
+	case signalLogs:
+		return &collogspb.ExportLogsServiceRequest{}
+	case signalMetrics:
+		return &colmetricspb.ExportMetricsServiceRequest{}
+	default:
+		return &coltracepb.ExportTraceServiceRequest{}
+	}
+}
+
+func newResponse(signal string) proto.Message {
+	switch signal {
+	case signalLogs:
+		return &collogspb.ExportLogsServiceResponse{}
+	case signalMetrics:
+		return &colmetricspb.ExportMetricsServiceResponse{}
+	default:
+		return &coltracepb.ExportTraceServiceResponse{}
+	}
+}
+
+// The OTLP/HTTP endpoints are served by the global http data server
+var httpHub = newHub()
+
+func httpPath(signal string) string {
+	return "/v1/" + signal
+}
+
+func subscribeHttp(signal, id string, c consumer) error {
+	if httpHub.subscribe(signal, id, c) {
+		err := httpserver.RegisterHandler(httpPath(signal), http.MethodPost, func(w http.ResponseWriter, r *http.Request) {
+			handleHttp(w, r, signal)
+		})
+		if err != nil {
+			httpHub.unsubscribe(signal, id)
+			return err
+		}
+		conf.Log.Infof("otlp http receiver serves %s", httpPath(signal))
+	}
+	return nil
+}
+
+func unsubscribeHttp(signal, id string) {
+	if httpHub.unsubscribe(signal, id) {
+		httpserver.UnregisterHandler(httpPath(signal), http.MethodPost)
+	}
+}
+
+func handleHttp(w http.ResponseWriter, r *http.Request, signal string) {
+	defer r.Body.Close()
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid gzip body: %v", err), http.StatusBadRequest)
+			return
+		}
+		defer gr.Close()
+		body = gr
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("fail to read body: %v", err), http.StatusBadRequest)
+		return
+	}
+	req := newRequest(signal)
+	contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	var isJson bool
+	switch contentType {
+	case "application/x-protobuf":
+		err = proto.Unmarshal(data, req)
+	case "application/json":
+		isJson = true
+		err = unmarshalJson(data, req)
+	default:
+		http.Error(w, fmt.Sprintf("unsupported content type %s", contentType), http.StatusUnsupportedMediaType)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid %s request: %v", signal, err), http.StatusBadRequest)
+		return
+	}
+	httpHub.dispatch(signal, req)
+	var resp []byte
+	if isJson {
+		resp, err = protojson.Marshal(newResponse(signal))
+	} else {
+		resp, err = proto.Marshal(newResponse(signal))
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(resp)
+}
+
+// unmarshalJson decodes the OTLP JSON whose trace and span ids are hex strings instead of base64 of the protobuf JSON mapping
+func unmarshalJson(data []byte, req proto.Message) error {
+	var v any
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return err
+	}
+	if err := hexToBase64(v); err != nil {
+		return err
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(b, req)
+}
+
+func hexToBase64(v any) error {
+	switch vt := v.(type) {
+	case map[string]any:
+		for k, e := range vt {
+			switch k {
+			case "traceId", "spanId", "parentSpanId", "trace_id", "span_id", "parent_span_id":
+				if s, ok := e.(string); ok {
+					b, err := hex.DecodeString(s)
+					if err != nil {
+						return fmt.Errorf("invalid %s %s: %v", k, s, err)
+					}
+					vt[k] = base64.StdEncoding.EncodeToString(b)
+					continue
+				}
+			}
+			if err := hexToBase64(e); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, e := range vt {
+			if err := hexToBase64(e); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// grpcServer is the OTLP/gRPC server shared by the sources with the same address
+type grpcServer struct {
+	*hub
+	addr string
+	srv  *grpc.Server
+	refs int
+}
+
+var (
+	grpcServers     = make(map[string]*grpcServer)
+	grpcServersLock sync.Mutex
+)
+
+func acquireGrpcServer(addr string) (*grpcServer, error) {
+	grpcServersLock.Lock()
+	defer grpcServersLock.Unlock()
+	if s, ok := grpcServers[addr]; ok {
+		s.refs++
+		return s, nil
+	}
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("otlp grpc receiver fails to listen on %s: %v", addr, err)
+	}
+	s := &grpcServer{hub: newHub(), addr: addr, srv: grpc.NewServer(), refs: 1}
+	collogspb.RegisterLogsServiceServer(s.srv, &logsService{hub: s.hub})
+	colmetricspb.RegisterMetricsServiceServer(s.srv, &metricsService{hub: s.hub})
+	coltracepb.RegisterTraceServiceServer(s.srv, &traceService{hub: s.hub})
+	go func() {
+		if err := s.srv.Serve(lis); err != nil {
+			conf.Log.Errorf("otlp grpc receiver %s stopped: %v", addr, err)
+		}
+	}()
+	grpcServers[addr] = s
+	conf.Log.Infof("otlp grpc receiver serves %s", addr)
+	return s, nil
+}
+
+func (s *grpcServer) release() {
+	grpcServersLock.Lock()
+	defer grpcServersLock.Unlock()
+	s.refs--
+	if s.refs > 0 {
+		return
+	}
+	delete(grpcServers, s.addr)
+	s.srv.Stop()
+	conf.Log.Infof("otlp grpc receiver %s is closed", s.addr)
+}
+
+type logsService struct {
+	collogspb.UnimplementedLogsServiceServer
+	hub *hub
+}
+
+func (s *logsService) Export(_ context.Context, req *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error) {
+	s.hub.dispatch(signalLogs, req)
+	return &collogspb.ExportLogsServiceResponse{}, nil
+}
+
+type metricsService struct {
+	colmetricspb.UnimplementedMetricsServiceServer
+	hub *hub
+}
+
+func (s *metricsService) Export(_ context.Context, req *colmetricspb.ExportMetricsServiceRequest) (*colmetricspb.ExportMetricsServiceResponse, error) {
+	s.hub.dispatch(signalMetrics, req)
+	return &colmetricspb.ExportMetricsServiceResponse{}, nil
+}
+
+type traceService struct {
+	coltracepb.UnimplementedTraceServiceServer
+	hub *hub
+}
+
+func (s *traceService) Export(_ context.Context, req *coltracepb.ExportTraceServiceRequest) (*coltracepb.ExportTraceServiceResponse, error) {
+	s.hub.dispatch(signalTraces, req)
+	return &coltracepb.ExportTraceServiceResponse{}, nil
+}
@@ -0,0 +1,133 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+const (
+	protocolHttp = "http"
+	protocolGrpc = "grpc"
+)
+
+type sourceConf struct {
+	Signal         string  `json:"datasource"`
+	Protocol       string  `json:"protocol"`
+	GrpcAddr       string  `json:"grpcAddr"`
+	ResourcePrefix *string `json:"resourcePrefix"`
+}
+
+// Source receives the OTLP logs, metrics or traces exported by the OpenTelemetry SDKs or collectors. Each log
+// record, metric data point or span is a tuple. The OTLP/HTTP endpoint is served by the global http data server
+// and the OTLP/gRPC server is shared by the sources with the same address.
+type Source struct {
+	conf  *sourceConf
+	conv  *converter
+	subId string
+	grpc  *grpcServer
+}
+
+func (s *Source) Provision(_ api.StreamContext, props map[string]any) error {
+	c := &sourceConf{Protocol: protocolHttp, GrpcAddr: ":4317"}
+	if err := cast.MapToStruct(props, c); err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	switch c.Signal {
+	case signalLogs, signalMetrics, signalTraces:
+	default:
+		return fmt.Errorf("datasource %s is not supported, must be logs, metrics or traces", c.Signal)
+	}
+	switch c.Protocol {
+	case protocolHttp, protocolGrpc:
+	default:
+		return fmt.Errorf("protocol %s is not supported, must be http or grpc", c.Protocol)
+	}
+	prefix := "resource_"
+	if c.ResourcePrefix != nil {
+		prefix = *c.ResourcePrefix
+	}
+	s.conf = c
+	s.conv = &converter{resourcePrefix: prefix}
+	return nil
+}
+
+func (s *Source) Connect(_ api.StreamContext, sch api.StatusChangeHandler) error {
+	if s.conf.Protocol == protocolGrpc {
+		srv, err := acquireGrpcServer(s.conf.GrpcAddr)
+		if err != nil {
+			sch(api.ConnectionDisconnected, err.Error())
+			return err
+		}
+		s.grpc = srv
+	}
+	sch(api.ConnectionConnected, "")
+	return nil
+}
+
+func (s *Source) Subscribe(ctx api.StreamContext, ingest api.TupleIngest, _ api.ErrorIngest) error {
+	s.subId = fmt.Sprintf("%s_%s_%d", ctx.GetRuleId(), ctx.GetOpId(), ctx.GetInstanceId())
+	c := func(req proto.Message) {
+		var records []record
+		switch r := req.(type) {
+		case *collogspb.ExportLogsServiceRequest:
+			records = s.conv.logs(r)
+		case *colmetricspb.ExportMetricsServiceRequest:
+			records = s.conv.metrics(r)
+		case *coltracepb.ExportTraceServiceRequest:
+			records = s.conv.traces(r)
+		}
+		for _, rec := range records {
+			ts := rec.ts
+			if ts.IsZero() {
+				ts = timex.GetNow()
+			}
+			ingest(ctx, rec.data, nil, ts)
+		}
+	}
+	if s.grpc != nil {
+		s.grpc.subscribe(s.conf.Signal, s.subId, c)
+		return nil
+	}
+	return subscribeHttp(s.conf.Signal, s.subId, c)
+}
+
+func (s *Source) Close(_ api.StreamContext) error {
+	if s.subId == "" {
+		return nil
+	}
+	if s.grpc != nil {
+		s.grpc.unsubscribe(s.conf.Signal, s.subId)
+		s.grpc.release()
+	} else {
+		unsubscribeHttp(s.conf.Signal, s.subId)
+	}
+	return nil
+}
+
+func GetSource() api.Source {
+	return &Source{}
+}
+
+var _ api.TupleSource = &Source{}
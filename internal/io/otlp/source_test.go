@@ -0,0 +1,213 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/require"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/lf-edge/ekuiper/v2/internal/io/http/httpserver"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+type received struct {
+	data map[string]any
+	ts   time.Time
+}
+
+func subscribe(t *testing.T, ctx api.StreamContext, props map[string]any) (api.TupleSource, chan received) {
+	s := GetSource().(api.TupleSource)
+	require.NoError(t, s.Provision(ctx, props))
+	require.NoError(t, s.Connect(ctx, func(string, string) {}))
+	ch := make(chan received, 10)
+	require.NoError(t, s.Subscribe(ctx, func(_ api.StreamContext, data any, _ map[string]any, ts time.Time) {
+		ch <- received{data: data.(map[string]any), ts: ts}
+	}, func(api.StreamContext, error) {}))
+	return s, ch
+}
+
+func strAttr(k, v string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: k, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}}}
+}
+
+func TestProvisionErr(t *testing.T) {
+	ctx := mockContext.NewMockContext("1", "2")
+	require.EqualError(t, GetSource().Provision(ctx, map[string]any{"datasource": "profiles"}), "datasource profiles is not supported, must be logs, metrics or traces")
+	require.EqualError(t, GetSource().Provision(ctx, map[string]any{"datasource": "logs", "protocol": "udp"}), "protocol udp is not supported, must be http or grpc")
+}
+
+func TestHttp(t *testing.T) {
+	ip, port := "127.0.0.1", 10094
+	httpserver.InitGlobalServerManager(ip, port, nil)
+	defer httpserver.ShutDown()
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	logs, logCh := subscribe(t, ctx, map[string]any{"datasource": "logs"})
+	metrics, metricCh := subscribe(t, ctx, map[string]any{"datasource": "metrics", "resourcePrefix": "res."})
+	url := fmt.Sprintf("http://%s:%d", ip, port)
+
+	// OTLP JSON with hex encoded ids
+	body := `{"resourceLogs":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"checkout"}}]},
+"scopeLogs":[{"scope":{"name":"app","version":"1.0"},"logRecords":[{"timeUnixNano":"1700000000000000000","severityNumber":17,
+"severityText":"ERROR","body":{"stringValue":"payment failed"},"attributes":[{"key":"retry","value":{"intValue":"3"}}],
+"traceId":"5b8efff798038103d269b633813fc60c","spanId":"eee19b7ec3c1b174","unknownField":1}]}]}]}`
+	resp, err := http.Post(url+"/v1/logs", "application/json", bytes.NewBufferString(body))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	r := <-logCh
+	require.Equal(t, time.Unix(0, 1700000000000000000), r.ts)
+	require.Equal(t, map[string]any{
+		"resource_service_name": "checkout",
+		"scopeName":             "app",
+		"scopeVersion":          "1.0",
+		"timeUnixNano":          int64(1700000000000000000),
+		"observedTimeUnixNano":  int64(0),
+		"severityNumber":        int64(17),
+		"severityText":          "ERROR",
+		"body":                  "payment failed",
+		"attributes":            map[string]any{"retry": int64(3)},
+		"traceId":               "5b8efff798038103d269b633813fc60c",
+		"spanId":                "eee19b7ec3c1b174",
+		"flags":                 int64(0),
+		"eventName":             "",
+	}, r.data)
+
+	// Gzipped protobuf
+	req := &colmetricspb.ExportMetricsServiceRequest{ResourceMetrics: []*metricspb.ResourceMetrics{{
+		Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{strAttr("host.name", "edge1")}},
+		ScopeMetrics: []*metricspb.ScopeMetrics{{Metrics: []*metricspb.Metric{
+			{Name: "temperature", Unit: "Cel", Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: []*metricspb.NumberDataPoint{
+				{Attributes: []*commonpb.KeyValue{strAttr("room", "a")}, TimeUnixNano: 2000, Value: &metricspb.NumberDataPoint_AsDouble{AsDouble: 21.5}},
+			}}}},
+			{Name: "requests", Data: &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+				IsMonotonic:            true,
+				AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+				DataPoints:             []*metricspb.NumberDataPoint{{StartTimeUnixNano: 1000, TimeUnixNano: 3000, Value: &metricspb.NumberDataPoint_AsInt{AsInt: 42}}},
+			}}},
+		}}},
+	}}}
+	b, err := proto.Marshal(req)
+	require.NoError(t, err)
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	_, _ = w.Write(b)
+	require.NoError(t, w.Close())
+	hr, err := http.NewRequest(http.MethodPost, url+"/v1/metrics", &gz)
+	require.NoError(t, err)
+	hr.Header.Set("Content-Type", "application/x-protobuf")
+	hr.Header.Set("Content-Encoding", "gzip")
+	resp, err = http.DefaultClient.Do(hr)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	r = <-metricCh
+	require.Equal(t, time.Unix(0, 2000), r.ts)
+	require.Equal(t, map[string]any{
+		"res.host_name":     "edge1",
+		"scopeName":         "",
+		"scopeVersion":      "",
+		"name":              "temperature",
+		"description":       "",
+		"unit":              "Cel",
+		"type":              "gauge",
+		"attributes":        map[string]any{"room": "a"},
+		"startTimeUnixNano": int64(0),
+		"timeUnixNano":      int64(2000),
+		"value":             21.5,
+	}, r.data)
+	r = <-metricCh
+	require.Equal(t, "sum", r.data["type"])
+	require.Equal(t, int64(42), r.data["value"])
+	require.Equal(t, true, r.data["isMonotonic"])
+	require.Equal(t, "CUMULATIVE", r.data["aggregationTemporality"])
+
+	resp, err = http.Post(url+"/v1/logs", "text/plain", bytes.NewBufferString("a"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+	resp, err = http.Post(url+"/v1/logs", "application/json", bytes.NewBufferString(`{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"traceId":"xyz"}]}]}]}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	require.NoError(t, logs.Close(ctx))
+	require.NoError(t, metrics.Close(ctx))
+	resp, err = http.Post(url+"/v1/logs", "application/json", bytes.NewBufferString(body))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestGrpc(t *testing.T) {
+	addr := "127.0.0.1:14317"
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	props := map[string]any{"datasource": "traces", "protocol": "grpc", "grpcAddr": addr}
+	s1, ch1 := subscribe(t, ctx, props)
+	// The server is shared by the sources with the same address
+	s2, ch2 := subscribe(t, mockContext.NewMockContext("rule2", "op1"), props)
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	cli := coltracepb.NewTraceServiceClient(conn)
+	_, err = cli.Export(context.Background(), &coltracepb.ExportTraceServiceRequest{ResourceSpans: []*tracepb.ResourceSpans{{
+		ScopeSpans: []*tracepb.ScopeSpans{{Spans: []*tracepb.Span{{
+			TraceId:           []byte{1, 2, 3, 4},
+			SpanId:            []byte{5, 6},
+			Name:              "GET /orders",
+			Kind:              tracepb.Span_SPAN_KIND_SERVER,
+			StartTimeUnixNano: 1000,
+			EndTimeUnixNano:   4000,
+			Status:            &tracepb.Status{Code: tracepb.Status_STATUS_CODE_ERROR, Message: "timeout"},
+			Events:            []*tracepb.Span_Event{{Name: "exception", TimeUnixNano: 3000}},
+		}}}},
+	}}})
+	require.NoError(t, err)
+	for _, ch := range []chan received{ch1, ch2} {
+		r := <-ch
+		require.Equal(t, time.Unix(0, 1000), r.ts)
+		require.Equal(t, "01020304", r.data["traceId"])
+		require.Equal(t, "0506", r.data["spanId"])
+		require.Equal(t, "", r.data["parentSpanId"])
+		require.Equal(t, "SERVER", r.data["kind"])
+		require.Equal(t, int64(3000), r.data["durationNano"])
+		require.Equal(t, "ERROR", r.data["statusCode"])
+		require.Equal(t, "timeout", r.data["statusMessage"])
+		require.Equal(t, []any{map[string]any{"name": "exception", "timeUnixNano": int64(3000), "attributes": map[string]any{}}}, r.data["events"])
+	}
+
+	require.NoError(t, s1.Close(ctx))
+	require.Len(t, grpcServers, 1)
+	require.NoError(t, s2.Close(ctx))
+	require.Len(t, grpcServers, 0)
+}
@@ -0,0 +1,254 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syslog
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+const (
+	rfc5424  = "rfc5424"
+	rfc3164  = "rfc3164"
+	nilValue = "-"
+)
+
+var bom = []byte{0xEF, 0xBB, 0xBF}
+
+// message is a parsed syslog message. The time is zero if the message has no valid timestamp.
+type message struct {
+	rfc            string
+	facility       int
+	severity       int
+	version        int
+	ts             time.Time
+	host           string
+	app            string
+	procId         string
+	msgId          string
+	structuredData map[string]any
+	msg            string
+}
+
+func (m *message) toMap() map[string]any {
+	result := map[string]any{
+		"facility":       int64(m.facility),
+		"severity":       int64(m.severity),
+		"version":        int64(m.version),
+		"timestamp":      nil,
+		"host":           m.host,
+		"app":            m.app,
+		"procId":         m.procId,
+		"msgId":          m.msgId,
+		"structuredData": m.structuredData,
+		"message":        m.msg,
+	}
+	if !m.ts.IsZero() {
+		result["timestamp"] = m.ts.UnixMilli()
+	}
+	return result
+}
+
+// parse detects the format by the version after the priority and parses the message as RFC 5424 or RFC 3164
+func parse(data []byte, now time.Time) (*message, error) {
+	data = bytes.TrimRight(data, "\r\n\x00")
+	pri, rest, err := parsePriority(data)
+	if err != nil {
+		return nil, err
+	}
+	m := &message{facility: pri / 8, severity: pri % 8}
+	if len(rest) > 1 && rest[0] >= '1' && rest[0] <= '9' {
+		if i := bytes.IndexByte(rest, ' '); i > 0 && i <= 3 {
+			if v, err := strconv.Atoi(string(rest[:i])); err == nil {
+				m.rfc = rfc5424
+				m.version = v
+				return m, parse5424(m, rest[i+1:])
+			}
+		}
+	}
+	m.rfc = rfc3164
+	parse3164(m, rest, now)
+	return m, nil
+}
+
+func parsePriority(data []byte) (int, []byte, error) {
+	if len(data) < 3 || data[0] != '<' {
+		return 0, nil, fmt.Errorf("invalid syslog message: missing priority")
+	}
+	end := bytes.IndexByte(data[:min(len(data), 5)], '>')
+	if end < 2 {
+		return 0, nil, fmt.Errorf("invalid syslog message: invalid priority")
+	}
+	pri, err := strconv.Atoi(string(data[1:end]))
+	if err != nil || pri < 0 || pri > 191 {
+		return 0, nil, fmt.Errorf("invalid syslog message: invalid priority %s", data[1:end])
+	}
+	return pri, data[end+1:], nil
+}
+
+// parse5424 parses TIMESTAMP SP HOSTNAME SP APP-NAME SP PROCID SP MSGID SP STRUCTURED-DATA [SP MSG]
+func parse5424(m *message, data []byte) error {
+	var fields [5]string
+	for i := range fields {
+		j := bytes.IndexByte(data, ' ')
+		if j < 0 {
+			return fmt.Errorf("invalid rfc5424 message: missing header fields")
+		}
+		fields[i] = string(data[:j])
+		data = data[j+1:]
+	}
+	if fields[0] != nilValue {
+		ts, err := time.Parse(time.RFC3339Nano, fields[0])
+		if err != nil {
+			return fmt.Errorf("invalid rfc5424 message: invalid timestamp %s", fields[0])
+		}
+		m.ts = ts
+	}
+	m.host = nilToEmpty(fields[1])
+	m.app = nilToEmpty(fields[2])
+	m.procId = nilToEmpty(fields[3])
+	m.msgId = nilToEmpty(fields[4])
+	sd, rest, err := parseStructuredData(data)
+	if err != nil {
+		return err
+	}
+	m.structuredData = sd
+	if len(rest) > 0 {
+		if rest[0] != ' ' {
+			return fmt.Errorf("invalid rfc5424 message: missing space before message")
+		}
+		m.msg = string(bytes.TrimPrefix(rest[1:], bom))
+	}
+	return nil
+}
+
+// parseStructuredData parses the SD-ELEMENTs into a map of SD-ID to the map of the params
+func parseStructuredData(data []byte) (map[string]any, []byte, error) {
+	sd := map[string]any{}
+	if len(data) > 0 && data[0] == '-' {
+		return sd, data[1:], nil
+	}
+	if len(data) == 0 || data[0] != '[' {
+		return nil, nil, fmt.Errorf("invalid rfc5424 message: invalid structured data")
+	}
+	for len(data) > 0 && data[0] == '[' {
+		data = data[1:]
+		end := bytes.IndexAny(data, " ]")
+		if end <= 0 {
+			return nil, nil, fmt.Errorf("invalid rfc5424 message: invalid sd-id")
+		}
+		params := map[string]any{}
+		sd[string(data[:end])] = params
+		data = data[end:]
+		for len(data) > 0 && data[0] == ' ' {
+			data = data[1:]
+			eq := bytes.IndexByte(data, '=')
+			if eq <= 0 || len(data) < eq+2 || data[eq+1] != '"' {
+				return nil, nil, fmt.Errorf("invalid rfc5424 message: invalid sd-param")
+			}
+			name := string(data[:eq])
+			data = data[eq+2:]
+			var (
+				value strings.Builder
+				i     int
+			)
+			for ; i < len(data) && data[i] != '"'; i++ {
+				// Only ", \ and ] are escaped, the other backslashes are kept
+				if data[i] == '\\' && i+1 < len(data) && (data[i+1] == '"' || data[i+1] == '\\' || data[i+1] == ']') {
+					i++
+				}
+				value.WriteByte(data[i])
+			}
+			if i == len(data) {
+				return nil, nil, fmt.Errorf("invalid rfc5424 message: unterminated sd-param value")
+			}
+			params[name] = value.String()
+			data = data[i+1:]
+		}
+		if len(data) == 0 || data[0] != ']' {
+			return nil, nil, fmt.Errorf("invalid rfc5424 message: unterminated sd-element")
+		}
+		data = data[1:]
+	}
+	return sd, data, nil
+}
+
+// parse3164 parses TIMESTAMP SP HOSTNAME SP TAG MSG leniently as the BSD syslog has no strict format.
+// If there is no valid timestamp, the whole content is the message.
+func parse3164(m *message, data []byte, now time.Time) {
+	rest, ok := parse3164Timestamp(m, data, now)
+	if !ok {
+		m.msg = string(data)
+		return
+	}
+	if i := bytes.IndexByte(rest, ' '); i > 0 {
+		m.host = string(rest[:i])
+		rest = rest[i+1:]
+	}
+	// The TAG is the app name with the optional process id such as sshd[123]: and ends with the colon
+	end := bytes.IndexAny(rest, ":[ ")
+	if end > 0 && end <= 48 && (rest[end] == ':' || rest[end] == '[') {
+		m.app = string(rest[:end])
+		rest = rest[end:]
+		if rest[0] == '[' {
+			if j := bytes.IndexByte(rest, ']'); j > 0 {
+				m.procId = string(rest[1:j])
+				rest = rest[j+1:]
+			}
+		}
+		rest = bytes.TrimPrefix(rest, []byte(":"))
+		rest = bytes.TrimPrefix(rest, []byte(" "))
+	}
+	m.msg = string(rest)
+}
+
+// parse3164Timestamp parses the Mmm dd hh:mm:ss timestamp without year in the configured time zone. The
+// RFC 3339 timestamp which is sent by some implementations is also accepted.
+func parse3164Timestamp(m *message, data []byte, now time.Time) ([]byte, bool) {
+	const stampLen = len(time.Stamp)
+	if len(data) > stampLen && data[stampLen] == ' ' {
+		loc := cast.GetConfiguredTimeZone()
+		ts, err := time.ParseInLocation(time.Stamp, string(data[:stampLen]), loc)
+		if err == nil {
+			now = now.In(loc)
+			ts = ts.AddDate(now.Year(), 0, 0)
+			// The message of the last year received in the beginning of a year
+			if ts.Sub(now) > 24*time.Hour {
+				ts = ts.AddDate(-1, 0, 0)
+			}
+			m.ts = ts
+			return data[stampLen+1:], true
+		}
+	}
+	if i := bytes.IndexByte(data, ' '); i > 0 {
+		if ts, err := time.Parse(time.RFC3339Nano, string(data[:i])); err == nil {
+			m.ts = ts
+			return data[i+1:], true
+		}
+	}
+	return nil, false
+}
+
+func nilToEmpty(s string) string {
+	if s == nilValue {
+		return ""
+	}
+	return s
+}
@@ -0,0 +1,161 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syslog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+func TestParse(t *testing.T) {
+	loc := cast.GetConfiguredTimeZone()
+	now := time.Date(2026, time.March, 10, 8, 0, 0, 0, loc)
+	tests := []struct {
+		name string
+		data string
+		rfc  string
+		exp  map[string]any
+		ts   time.Time
+	}{
+		{
+			name: "rfc5424 with structured data",
+			data: `<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3" eventSource="Application" eventID="1011"][examplePriority@32473 class="high"] ` + "\xEF\xBB\xBF" + `An application event log entry...`,
+			rfc:  rfc5424,
+			exp: map[string]any{
+				"facility":  int64(20),
+				"severity":  int64(5),
+				"version":   int64(1),
+				"timestamp": int64(1065910455003),
+				"host":      "mymachine.example.com",
+				"app":       "evntslog",
+				"procId":    "",
+				"msgId":     "ID47",
+				"structuredData": map[string]any{
+					"exampleSDID@32473":     map[string]any{"iut": "3", "eventSource": "Application", "eventID": "1011"},
+					"examplePriority@32473": map[string]any{"class": "high"},
+				},
+				"message": "An application event log entry...",
+			},
+			ts: time.Date(2003, time.October, 11, 22, 14, 15, 3000000, time.UTC),
+		},
+		{
+			name: "rfc5424 nil values and escapes",
+			data: `<34>1 - - su 123 - [origin ip="10.0.0.1" path="a\"b\\c\]d\e"]`,
+			rfc:  rfc5424,
+			exp: map[string]any{
+				"facility":       int64(4),
+				"severity":       int64(2),
+				"version":        int64(1),
+				"timestamp":      nil,
+				"host":           "",
+				"app":            "su",
+				"procId":         "123",
+				"msgId":          "",
+				"structuredData": map[string]any{"origin": map[string]any{"ip": "10.0.0.1", "path": `a"b\c]d\e`}},
+				"message":        "",
+			},
+		},
+		{
+			name: "rfc3164",
+			data: "<34>Oct  5 22:14:15 mymachine su[230]: 'su root' failed for lonvick on /dev/pts/8\n",
+			rfc:  rfc3164,
+			exp: map[string]any{
+				"facility":       int64(4),
+				"severity":       int64(2),
+				"version":        int64(0),
+				"timestamp":      time.Date(2025, time.October, 5, 22, 14, 15, 0, loc).UnixMilli(),
+				"host":           "mymachine",
+				"app":            "su",
+				"procId":         "230",
+				"msgId":          "",
+				"structuredData": map[string]any(nil),
+				"message":        "'su root' failed for lonvick on /dev/pts/8",
+			},
+			ts: time.Date(2025, time.October, 5, 22, 14, 15, 0, loc),
+		},
+		{
+			name: "rfc3164 with rfc3339 timestamp",
+			data: "<13>2026-03-10T07:59:00Z router1 link down on ge-0/0/1",
+			rfc:  rfc3164,
+			exp: map[string]any{
+				"facility":       int64(1),
+				"severity":       int64(5),
+				"version":        int64(0),
+				"timestamp":      time.Date(2026, time.March, 10, 7, 59, 0, 0, time.UTC).UnixMilli(),
+				"host":           "router1",
+				"app":            "",
+				"procId":         "",
+				"msgId":          "",
+				"structuredData": map[string]any(nil),
+				"message":        "link down on ge-0/0/1",
+			},
+			ts: time.Date(2026, time.March, 10, 7, 59, 0, 0, time.UTC),
+		},
+		{
+			name: "rfc3164 without timestamp",
+			data: "<13>hello world",
+			rfc:  rfc3164,
+			exp: map[string]any{
+				"facility":       int64(1),
+				"severity":       int64(5),
+				"version":        int64(0),
+				"timestamp":      nil,
+				"host":           "",
+				"app":            "",
+				"procId":         "",
+				"msgId":          "",
+				"structuredData": map[string]any(nil),
+				"message":        "hello world",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := parse([]byte(tt.data), now)
+			require.NoError(t, err)
+			require.Equal(t, tt.rfc, m.rfc)
+			require.Equal(t, tt.exp, m.toMap())
+			require.True(t, tt.ts.Equal(m.ts), "expect %v but got %v", tt.ts, m.ts)
+		})
+	}
+}
+
+func TestParseErr(t *testing.T) {
+	tests := []struct {
+		data string
+		err  string
+	}{
+		{data: "hello", err: "invalid syslog message: missing priority"},
+		{data: "<1234>1 - - - - - -", err: "invalid syslog message: invalid priority"},
+		{data: "<192>a", err: "invalid syslog message: invalid priority 192"},
+		{data: "<13>1 - host", err: "invalid rfc5424 message: missing header fields"},
+		{data: "<13>1 2003-10-11 h a p m -", err: "invalid rfc5424 message: invalid timestamp 2003-10-11"},
+		{data: "<13>1 - h a p m msg", err: "invalid rfc5424 message: invalid structured data"},
+		{data: `<13>1 - h a p m [id a="1"`, err: "invalid rfc5424 message: unterminated sd-element"},
+		{data: `<13>1 - h a p m [id a="1]`, err: "invalid rfc5424 message: unterminated sd-param value"},
+		{data: `<13>1 - h a p m [id a=1]`, err: "invalid rfc5424 message: invalid sd-param"},
+		{data: `<13>1 - h a p m -msg`, err: "invalid rfc5424 message: missing space before message"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			_, err := parse([]byte(tt.data), time.Now())
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,331 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syslog
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cert"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
+)
+
+const (
+	networkUdp = "udp"
+	networkTcp = "tcp"
+	networkTls = "tls"
+)
+
+// handler handles a syslog message received from the remote address
+type handler func(data []byte, remote string)
+
+// server is a syslog listener shared by all the sources listening to the same network and address.
+// Each message is dispatched to all the subscribed sources.
+type server struct {
+	key     string
+	network string
+	addr    string
+	maxSize int
+	refs    int
+	logger  api.Logger
+
+	mu    sync.RWMutex
+	subs  map[string]handler
+	conns map[net.Conn]struct{}
+
+	pc net.PacketConn
+	ln net.Listener
+}
+
+var (
+	serversLock sync.Mutex
+	servers     = map[string]*server{}
+)
+
+// acquireServer returns the running server of the network and address or starts a new one. The tls config
+// and max message size of the first source take effect.
+func acquireServer(ctx api.StreamContext, network, addr string, maxSize int, tlsConf *tls.Config) (*server, error) {
+	serversLock.Lock()
+	defer serversLock.Unlock()
+	key := network + "://" + addr
+	if s, ok := servers[key]; ok {
+		s.refs++
+		return s, nil
+	}
+	s := &server{
+		key:     key,
+		network: network,
+		addr:    addr,
+		maxSize: maxSize,
+		refs:    1,
+		logger:  ctx.GetLogger(),
+		subs:    map[string]handler{},
+		conns:   map[net.Conn]struct{}{},
+	}
+	switch network {
+	case networkUdp:
+		pc, err := net.ListenPacket("udp", addr)
+		if err != nil {
+			return nil, err
+		}
+		s.pc = pc
+		go s.serveUDP()
+	case networkTcp:
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			return nil, err
+		}
+		s.ln = ln
+		go s.serveStream()
+	case networkTls:
+		ln, err := tls.Listen("tcp", addr, tlsConf)
+		if err != nil {
+			return nil, err
+		}
+		s.ln = ln
+		go s.serveStream()
+	}
+	servers[key] = s
+	s.logger.Infof("syslog server started at %s", key)
+	return s, nil
+}
+
+// release stops the server when it is not used by any connection
+func (s *server) release() {
+	serversLock.Lock()
+	defer serversLock.Unlock()
+	s.refs--
+	if s.refs > 0 {
+		return
+	}
+	delete(servers, s.key)
+	if s.pc != nil {
+		_ = s.pc.Close()
+	}
+	if s.ln != nil {
+		_ = s.ln.Close()
+	}
+	s.mu.Lock()
+	for c := range s.conns {
+		_ = c.Close()
+	}
+	s.mu.Unlock()
+	s.logger.Infof("syslog server at %s stopped", s.key)
+}
+
+func (s *server) register(subId string, h handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subs[subId] = h
+}
+
+func (s *server) unregister(subId string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subs, subId)
+}
+
+func (s *server) dispatch(data []byte, remote string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, h := range s.subs {
+		h(data, remote)
+	}
+}
+
+func (s *server) serveUDP() {
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := s.pc.ReadFrom(buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				s.logger.Errorf("syslog server %s read error: %v", s.key, err)
+			}
+			return
+		}
+		// Each datagram is a message
+		data := make([]byte, n)
+		copy(data, buf[:n])
+		s.dispatch(data, addr.String())
+	}
+}
+
+func (s *server) serveStream() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				s.logger.Errorf("syslog server %s accept error: %v", s.key, err)
+			}
+			return
+		}
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+		go func(conn net.Conn) {
+			defer func() {
+				_ = conn.Close()
+				s.mu.Lock()
+				delete(s.conns, conn)
+				s.mu.Unlock()
+			}()
+			remote := conn.RemoteAddr().String()
+			r := bufio.NewReader(conn)
+			for {
+				data, err := readFrame(r, s.maxSize)
+				if err != nil {
+					if err != io.EOF && !errors.Is(err, net.ErrClosed) {
+						s.logger.Warnf("syslog server %s closes the connection of %s: %v", s.key, remote, err)
+					}
+					return
+				}
+				if len(data) > 0 {
+					s.dispatch(data, remote)
+				}
+			}
+		}(conn)
+	}
+}
+
+// readFrame reads a message from the stream as RFC 6587. The message is octet counted if it starts with
+// a digit, such as "65 <13>1 ...". Otherwise, it is terminated by a line feed.
+func readFrame(r *bufio.Reader, maxSize int) ([]byte, error) {
+	b, err := r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+	if b[0] >= '1' && b[0] <= '9' {
+		l, err := r.ReadString(' ')
+		if err != nil {
+			return nil, err
+		}
+		n, err := strconv.Atoi(l[:len(l)-1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid octet count %s", l)
+		}
+		if n > maxSize {
+			return nil, fmt.Errorf("message size %d exceeds the max size %d", n, maxSize)
+		}
+		data := make([]byte, n)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		return data, nil
+	}
+	var data []byte
+	for {
+		line, isPrefix, err := r.ReadLine()
+		if err != nil {
+			if err == io.EOF && len(data) > 0 {
+				return data, nil
+			}
+			return nil, err
+		}
+		data = append(data, line...)
+		if len(data) > maxSize {
+			return nil, fmt.Errorf("message size exceeds the max size %d", maxSize)
+		}
+		if !isPrefix {
+			return bytes.TrimRight(data, "\r"), nil
+		}
+	}
+}
+
+type serverConf struct {
+	Network        string `json:"network"`
+	ListenAddr     string `json:"listenAddr"`
+	MaxMessageSize int    `json:"maxMessageSize"`
+}
+
+// ServerConnection is a reference to the shared server of the network and listen address
+type ServerConnection struct {
+	id      string
+	conf    *serverConf
+	tlsConf *tls.Config
+	srv     *server
+}
+
+func CreateConnection(_ api.StreamContext) modules.Connection {
+	return &ServerConnection{}
+}
+
+func (c *ServerConnection) Provision(ctx api.StreamContext, conId string, props map[string]any) error {
+	sc := &serverConf{Network: networkUdp, ListenAddr: ":514", MaxMessageSize: 65536}
+	if err := cast.MapToStruct(props, sc); err != nil {
+		return err
+	}
+	switch sc.Network {
+	case networkUdp, networkTcp:
+	case networkTls:
+		tc, err := cert.GenTLSConfig(ctx, props)
+		if err != nil {
+			return fmt.Errorf("error configuring tls: %s", err)
+		}
+		if tc == nil || len(tc.Certificates) == 0 {
+			return fmt.Errorf("certificationPath and privateKeyPath are required for tls")
+		}
+		// Verify the client certificates if the root ca is set
+		if tc.RootCAs != nil {
+			tc.ClientCAs = tc.RootCAs
+			tc.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+		c.tlsConf = tc
+	default:
+		return fmt.Errorf("network %s is not supported, must be udp, tcp or tls", sc.Network)
+	}
+	if sc.MaxMessageSize <= 0 {
+		return fmt.Errorf("maxMessageSize should be positive")
+	}
+	c.id = conId
+	c.conf = sc
+	return nil
+}
+
+func (c *ServerConnection) Dial(ctx api.StreamContext) error {
+	srv, err := acquireServer(ctx, c.conf.Network, c.conf.ListenAddr, c.conf.MaxMessageSize, c.tlsConf)
+	if err != nil {
+		return err
+	}
+	c.srv = srv
+	return nil
+}
+
+func (c *ServerConnection) GetId(_ api.StreamContext) string {
+	return c.id
+}
+
+func (c *ServerConnection) Ping(_ api.StreamContext) error {
+	return nil
+}
+
+func (c *ServerConnection) Close(_ api.StreamContext) error {
+	if c.srv != nil {
+		c.srv.release()
+		c.srv = nil
+	}
+	return nil
+}
+
+var _ modules.Connection = &ServerConnection{}
@@ -0,0 +1,87 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syslog
+
+import (
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+// Source receives the syslog messages from the shared server and parses them into tuples
+type Source struct {
+	props map[string]any
+	conId string
+	subId string
+	srv   *ServerConnection
+}
+
+func (s *Source) Provision(ctx api.StreamContext, props map[string]any) error {
+	// Validate the server configuration early
+	if err := (&ServerConnection{}).Provision(ctx, "", props); err != nil {
+		return err
+	}
+	s.props = props
+	return nil
+}
+
+func (s *Source) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	refId := fmt.Sprintf("%s-%s-syslog-source", ctx.GetRuleId(), ctx.GetOpId())
+	cw, err := connection.FetchConnection(ctx, refId, "syslog", s.props, sch)
+	if err != nil {
+		return err
+	}
+	s.conId = cw.ID
+	conn, err := cw.Wait(ctx)
+	if conn == nil {
+		return fmt.Errorf("syslog server not ready: %v", err)
+	}
+	s.srv = conn.(*ServerConnection)
+	return err
+}
+
+func (s *Source) Subscribe(ctx api.StreamContext, ingest api.TupleIngest, ingestError api.ErrorIngest) error {
+	s.subId = fmt.Sprintf("%s_%s_%d", ctx.GetRuleId(), ctx.GetOpId(), ctx.GetInstanceId())
+	s.srv.srv.register(s.subId, func(data []byte, remote string) {
+		now := timex.GetNow()
+		m, err := parse(data, now)
+		if err != nil {
+			ingestError(ctx, fmt.Errorf("%v from %s", err, remote))
+			return
+		}
+		ts := m.ts
+		if ts.IsZero() {
+			ts = now
+		}
+		ingest(ctx, m.toMap(), map[string]any{"remote": remote, "rfc": m.rfc}, ts)
+	})
+	return nil
+}
+
+func (s *Source) Close(ctx api.StreamContext) error {
+	if s.srv != nil && s.srv.srv != nil && s.subId != "" {
+		s.srv.srv.unregister(s.subId)
+	}
+	return connection.DetachConnection(ctx, s.conId)
+}
+
+func GetSource() api.Source {
+	return &Source{}
+}
+
+var _ api.TupleSource = &Source{}
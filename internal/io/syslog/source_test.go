@@ -0,0 +1,220 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syslog
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
+)
+
+func init() {
+	modules.RegisterConnection("syslog", CreateConnection)
+}
+
+func freeAddr(t *testing.T, network string) string {
+	if network == networkUdp {
+		pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer pc.Close()
+		return pc.LocalAddr().String()
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	return ln.Addr().String()
+}
+
+type received struct {
+	data map[string]any
+	meta map[string]any
+	ts   time.Time
+	err  error
+}
+
+func subscribe(t *testing.T, ruleId string, props map[string]any) (*Source, api.StreamContext, chan received) {
+	ctx, cancel := mockContext.NewMockContext(ruleId, "op1").WithCancel()
+	t.Cleanup(cancel)
+	src := GetSource().(*Source)
+	require.NoError(t, src.Provision(ctx, props))
+	require.NoError(t, src.Connect(ctx, func(string, string) {}))
+	result := make(chan received, 10)
+	require.NoError(t, src.Subscribe(ctx, func(_ api.StreamContext, data any, meta map[string]any, ts time.Time) {
+		result <- received{data: data.(map[string]any), meta: meta, ts: ts}
+	}, func(_ api.StreamContext, err error) {
+		result <- received{err: err}
+	}))
+	return src, ctx, result
+}
+
+func TestSourceProvisionErr(t *testing.T) {
+	ctx := mockContext.NewMockContext("1", "2")
+	tests := []struct {
+		name  string
+		props map[string]any
+		err   string
+	}{
+		{
+			name:  "invalid network",
+			props: map[string]any{"network": "unix"},
+			err:   "network unix is not supported, must be udp, tcp or tls",
+		},
+		{
+			name:  "tls without cert",
+			props: map[string]any{"network": "tls"},
+			err:   "certificationPath and privateKeyPath are required for tls",
+		},
+		{
+			name:  "invalid max size",
+			props: map[string]any{"maxMessageSize": -1},
+			err:   "maxMessageSize should be positive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.EqualError(t, GetSource().Provision(ctx, tt.props), tt.err)
+		})
+	}
+}
+
+// The sources of different rules share the udp server
+func TestUDP(t *testing.T) {
+	require.NoError(t, connection.InitConnectionManager4Test())
+	addr := freeAddr(t, networkUdp)
+	props := map[string]any{"listenAddr": addr}
+	src1, ctx1, r1 := subscribe(t, "rule1", props)
+	src2, ctx2, r2 := subscribe(t, "rule2", props)
+
+	conn, err := net.Dial("udp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte(`<165>1 2003-10-11T22:14:15.003Z host1 app1 - ID47 [a@1 k="v"] hello`))
+	require.NoError(t, err)
+	for _, r := range []chan received{r1, r2} {
+		v := <-r
+		require.NoError(t, v.err)
+		require.Equal(t, "hello", v.data["message"])
+		require.Equal(t, int64(20), v.data["facility"])
+		require.Equal(t, map[string]any{"a@1": map[string]any{"k": "v"}}, v.data["structuredData"])
+		require.Equal(t, map[string]any{"remote": conn.LocalAddr().String(), "rfc": rfc5424}, v.meta)
+		require.Equal(t, time.Date(2003, time.October, 11, 22, 14, 15, 3000000, time.UTC).UnixMilli(), v.ts.UnixMilli())
+	}
+	_, err = conn.Write([]byte("hello"))
+	require.NoError(t, err)
+	v := <-r1
+	require.EqualError(t, v.err, "invalid syslog message: missing priority from "+conn.LocalAddr().String())
+
+	require.NoError(t, src1.Close(ctx1))
+	serversLock.Lock()
+	require.Contains(t, servers, "udp://"+addr)
+	serversLock.Unlock()
+	require.NoError(t, src2.Close(ctx2))
+	serversLock.Lock()
+	require.NotContains(t, servers, "udp://"+addr)
+	serversLock.Unlock()
+}
+
+func TestTCPFraming(t *testing.T) {
+	require.NoError(t, connection.InitConnectionManager4Test())
+	addr := freeAddr(t, networkTcp)
+	src, ctx, r := subscribe(t, "rule1", map[string]any{"network": "tcp", "listenAddr": addr, "maxMessageSize": 100})
+	defer src.Close(ctx)
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	// Octet counted messages can contain new lines and are mixed with the non-transparent framing
+	msg := "<13>1 - h a - - - line1\nline2"
+	_, err = conn.Write([]byte("29 " + msg + "<13>Oct  5 22:14:15 h sshd: second\r\n30 " + msg + "3"))
+	require.NoError(t, err)
+	require.Equal(t, "line1\nline2", (<-r).data["message"])
+	v := <-r
+	require.Equal(t, "second", v.data["message"])
+	require.Equal(t, "sshd", v.data["app"])
+	require.Equal(t, "line1\nline23", (<-r).data["message"])
+
+	// The connection is closed if the message is too large
+	_, err = conn.Write([]byte("101 <13>"))
+	require.NoError(t, err)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, err = conn.Read(make([]byte, 1))
+	require.Error(t, err)
+	require.False(t, isTimeout(err))
+}
+
+func isTimeout(err error) bool {
+	ne, ok := err.(net.Error)
+	return ok && ne.Timeout()
+}
+
+func TestTLS(t *testing.T) {
+	require.NoError(t, connection.InitConnectionManager4Test())
+	certFile, keyFile := genCert(t)
+	addr := freeAddr(t, networkTcp)
+	src, ctx, r := subscribe(t, "rule1", map[string]any{
+		"network":           "tls",
+		"listenAddr":        addr,
+		"certificationPath": certFile,
+		"privateKeyPath":    keyFile,
+	})
+	defer src.Close(ctx)
+
+	conn, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true})
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("<13>Oct  5 22:14:15 h app[1]: secure\n"))
+	require.NoError(t, err)
+	v := <-r
+	require.Equal(t, "secure", v.data["message"])
+	require.Equal(t, "1", v.data["procId"])
+}
+
+func genCert(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600))
+	return certFile, keyFile
+}
@@ -34,8 +34,11 @@ type RuleOption struct {
 	SendMetaToSink            bool                     `json:"sendMetaToSink" yaml:"sendMetaToSink"`
 	SendNil                   bool                     `json:"sendNilField" yaml:"sendNilField"`
 	SendError                 bool                     `json:"sendError" yaml:"sendError"`
+	RowkindField              string                   `json:"rowkindField,omitempty" yaml:"rowkindField,omitempty"`
+	SendUpdateBefore          bool                     `json:"sendUpdateBefore,omitempty" yaml:"sendUpdateBefore,omitempty"`
 	Qos                       Qos                      `json:"qos,omitempty" yaml:"qos,omitempty"`
 	CheckpointInterval        cast.DurationConf        `json:"checkpointInterval,omitempty" yaml:"checkpointInterval,omitempty"`
+	StateTTL                  cast.DurationConf        `json:"stateTtl,omitempty" yaml:"stateTtl,omitempty"`
 	RestartStrategy           *RestartStrategy         `json:"restartStrategy,omitempty" yaml:"restartStrategy,omitempty"`
 	Cron                      string                   `json:"cron,omitempty" yaml:"cron,omitempty"`
 	Duration                  string                   `json:"duration,omitempty" yaml:"duration,omitempty"`
@@ -46,6 +49,8 @@ type RuleOption struct {
 	EnableSaveStateBeforeStop bool                     `json:"enableSaveStateBeforeStop,omitempty" yaml:"enableSaveStateBeforeStop,omitempty"`
 	ForceExitTimeout          cast.DurationConf        `json:"forceExitTimeout,omitempty" yaml:"forceExitTimeout,omitempty"`
 	Experiment                *ExpOpts                 `json:"experiment,omitempty" yaml:"experiment,omitempty"`
+	// SchemaVersions pins the schema version of the streams by name. The rule uses the latest schema if not pinned.
+	SchemaVersions map[string]int `json:"schemaVersions,omitempty" yaml:"schemaVersions,omitempty"`
 }
 
 type ExpOpts struct {
@@ -55,6 +60,7 @@ type ExpOpts struct {
 type PlanOptimizeStrategy struct {
 	EnableIncrementalWindow bool             `json:"enableIncrementalWindow" yaml:"enableIncrementalWindow"`
 	EnableAliasPushdown     bool             `json:"enableAliasPushdown,omitempty" yaml:"enableAliasPushdown,omitempty"`
+	EnableFilterReorder     bool             `json:"enableFilterReorder,omitempty" yaml:"enableFilterReorder,omitempty"`
 	DisableAliasRefCal      bool             `json:"disableAliasRefCal,omitempty" yaml:"disableAliasRefCal,omitempty"`
 	OptimizeControl         *OptimizeControl `json:"optimizeControl,omitempty" yaml:"optimizeControl,omitempty"`
 	WindowOption            *WindowOption    `json:"windowOption,omitempty" yaml:"windowOption,omitempty"`
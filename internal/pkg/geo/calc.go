@@ -0,0 +1,277 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geo
+
+import (
+	"fmt"
+	"math"
+)
+
+// EarthRadius is the mean earth radius in meters
+const EarthRadius = 6371008.8
+
+func toRad(d float64) float64 { return d * math.Pi / 180 }
+func toDeg(r float64) float64 { return r * 180 / math.Pi }
+
+// Haversine returns the great-circle distance of two points in meters
+func Haversine(p1, p2 Point) float64 {
+	lat1, lat2 := toRad(p1.Lat()), toRad(p2.Lat())
+	dLat := lat2 - lat1
+	dLon := toRad(p2.Lon() - p1.Lon())
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * EarthRadius * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// Bearing returns the initial bearing from p1 to p2 in degrees, clockwise from the north in [0, 360)
+func Bearing(p1, p2 Point) float64 {
+	lat1, lat2 := toRad(p1.Lat()), toRad(p2.Lat())
+	dLon := toRad(p2.Lon() - p1.Lon())
+	y := math.Sin(dLon) * math.Cos(lat2)
+	x := math.Cos(lat1)*math.Sin(lat2) - math.Sin(lat1)*math.Cos(lat2)*math.Cos(dLon)
+	return math.Mod(toDeg(math.Atan2(y, x))+360, 360)
+}
+
+// Destination returns the point at the distance in meters and the bearing in degrees from the start point
+func Destination(p Point, distance float64, bearing float64) Point {
+	lat1, lon1 := toRad(p.Lat()), toRad(p.Lon())
+	d := distance / EarthRadius
+	b := toRad(bearing)
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(d) + math.Cos(lat1)*math.Sin(d)*math.Cos(b))
+	lon2 := lon1 + math.Atan2(math.Sin(b)*math.Sin(d)*math.Cos(lat1), math.Cos(d)-math.Sin(lat1)*math.Sin(lat2))
+	return Point{math.Mod(toDeg(lon2)+540, 360) - 180, toDeg(lat2)}
+}
+
+// Buffer returns the polygon of the points within the distance in meters to a point geometry.
+// The circle is approximated by the segments.
+func Buffer(g *Geometry, distance float64, segments int) (*Geometry, error) {
+	if g.Type != TypePoint {
+		return nil, fmt.Errorf("buffer only supports point geometry but got %s", g.Type)
+	}
+	if distance <= 0 {
+		return nil, fmt.Errorf("buffer distance must be positive")
+	}
+	if segments < 4 {
+		segments = 4
+	}
+	center := g.Points[0]
+	ring := make([]Point, segments+1)
+	for i := 0; i < segments; i++ {
+		ring[i] = Destination(center, distance, float64(i)*360/float64(segments))
+	}
+	ring[segments] = ring[0]
+	return &Geometry{Type: TypePolygon, Polygons: [][][]Point{{ring}}}, nil
+}
+
+// Contains returns whether the polygonal geometry contains the point. The point on the boundary is regarded as inside.
+func (g *Geometry) Contains(p Point) bool {
+	for _, poly := range g.Polygons {
+		if polygonContains(poly, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func polygonContains(poly [][]Point, p Point) bool {
+	in, onEdge := ringContains(poly[0], p)
+	if !in {
+		return onEdge
+	}
+	for _, hole := range poly[1:] {
+		if inHole, onHoleEdge := ringContains(hole, p); inHole && !onHoleEdge {
+			return false
+		}
+	}
+	return true
+}
+
+// ringContains checks by ray casting. It returns whether the point is inside or on the edge and whether it is on the edge.
+func ringContains(ring []Point, p Point) (bool, bool) {
+	in := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if onSegment(a, b, p) {
+			return true, true
+		}
+		if (a[1] > p[1]) != (b[1] > p[1]) && p[0] < (b[0]-a[0])*(p[1]-a[1])/(b[1]-a[1])+a[0] {
+			in = !in
+		}
+	}
+	return in, false
+}
+
+func onSegment(a, b, p Point) bool {
+	cross := (b[0]-a[0])*(p[1]-a[1]) - (b[1]-a[1])*(p[0]-a[0])
+	if math.Abs(cross) > 1e-12 {
+		return false
+	}
+	return p[0] >= math.Min(a[0], b[0]) && p[0] <= math.Max(a[0], b[0]) && p[1] >= math.Min(a[1], b[1]) && p[1] <= math.Max(a[1], b[1])
+}
+
+// Within returns whether all the points of the point geometry are within the polygonal geometry
+func Within(g, other *Geometry) (bool, error) {
+	if g.Type != TypePoint && g.Type != TypeMultiPoint {
+		return false, fmt.Errorf("within only supports point geometry as the first argument but got %s", g.Type)
+	}
+	if !other.IsPolygonal() {
+		return false, fmt.Errorf("within only supports polygon geometry as the second argument but got %s", other.Type)
+	}
+	for _, p := range g.Points {
+		if !other.Contains(p) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Distance returns the minimum distance of two geometries in meters. It is 0 if they intersect.
+// The distance to the lines are calculated in the local plane of each position, so it is only accurate for short distances.
+func Distance(g1, g2 *Geometry) float64 {
+	if intersects(g1, g2) {
+		return 0
+	}
+	minDist := math.Inf(1)
+	for _, p := range g1.vertices() {
+		if d := pointDistance(p, g2); d < minDist {
+			minDist = d
+		}
+	}
+	for _, p := range g2.vertices() {
+		if d := pointDistance(p, g1); d < minDist {
+			minDist = d
+		}
+	}
+	return minDist
+}
+
+func intersects(g1, g2 *Geometry) bool {
+	if g2.IsPolygonal() {
+		for _, p := range g1.vertices() {
+			if g2.Contains(p) {
+				return true
+			}
+		}
+	}
+	if g1.IsPolygonal() {
+		for _, p := range g2.vertices() {
+			if g1.Contains(p) {
+				return true
+			}
+		}
+	}
+	for _, s1 := range g1.segments() {
+		for _, s2 := range g2.segments() {
+			if segmentsIntersect(s1[0], s1[1], s2[0], s2[1]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (g *Geometry) vertices() []Point {
+	r := append([]Point{}, g.Points...)
+	for _, l := range g.Lines {
+		r = append(r, l...)
+	}
+	for _, poly := range g.Polygons {
+		for _, ring := range poly {
+			r = append(r, ring...)
+		}
+	}
+	return r
+}
+
+func (g *Geometry) segments() [][2]Point {
+	var r [][2]Point
+	add := func(ps []Point) {
+		for i := 1; i < len(ps); i++ {
+			r = append(r, [2]Point{ps[i-1], ps[i]})
+		}
+	}
+	for _, l := range g.Lines {
+		add(l)
+	}
+	for _, poly := range g.Polygons {
+		for _, ring := range poly {
+			add(ring)
+		}
+	}
+	return r
+}
+
+// pointDistance returns the minimum distance from the point to the points and segments of the geometry
+func pointDistance(p Point, g *Geometry) float64 {
+	minDist := math.Inf(1)
+	for _, q := range g.Points {
+		if d := Haversine(p, q); d < minDist {
+			minDist = d
+		}
+	}
+	for _, s := range g.segments() {
+		if d := segmentDistance(p, s[0], s[1]); d < minDist {
+			minDist = d
+		}
+	}
+	return minDist
+}
+
+// segmentDistance projects the segment to the local plane of the point by equirectangular projection
+func segmentDistance(p, a, b Point) float64 {
+	k := math.Cos(toRad(p.Lat()))
+	ax, ay := toRad(a.Lon()-p.Lon())*k, toRad(a.Lat()-p.Lat())
+	bx, by := toRad(b.Lon()-p.Lon())*k, toRad(b.Lat()-p.Lat())
+	dx, dy := bx-ax, by-ay
+	l := dx*dx + dy*dy
+	if l == 0 {
+		return Haversine(p, a)
+	}
+	t := -(ax*dx + ay*dy) / l
+	switch {
+	case t <= 0:
+		return Haversine(p, a)
+	case t >= 1:
+		return Haversine(p, b)
+	default:
+		return math.Hypot(ax+t*dx, ay+t*dy) * EarthRadius
+	}
+}
+
+func segmentsIntersect(p1, p2, q1, q2 Point) bool {
+	d1 := orientation(q1, q2, p1)
+	d2 := orientation(q1, q2, p2)
+	d3 := orientation(p1, p2, q1)
+	d4 := orientation(p1, p2, q2)
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+	return onSegment(q1, q2, p1) || onSegment(q1, q2, p2) || onSegment(p1, p2, q1) || onSegment(p1, p2, q2)
+}
+
+func orientation(a, b, c Point) float64 {
+	return (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])
+}
+
+// Bound returns the bounding box of the geometry
+func (g *Geometry) Bound() Rect {
+	r := Rect{MinX: math.Inf(1), MinY: math.Inf(1), MaxX: math.Inf(-1), MaxY: math.Inf(-1)}
+	for _, p := range g.vertices() {
+		r.MinX = math.Min(r.MinX, p[0])
+		r.MinY = math.Min(r.MinY, p[1])
+		r.MaxX = math.Max(r.MaxX, p[0])
+		r.MaxY = math.Max(r.MaxY, p[1])
+	}
+	return r
+}
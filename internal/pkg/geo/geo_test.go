@@ -0,0 +1,194 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geo
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	square := [][]Point{{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}, {{4, 4}, {6, 4}, {6, 6}, {4, 6}, {4, 4}}}
+	tests := []struct {
+		name string
+		v    any
+		g    *Geometry
+		wkt  string
+		err  string
+	}{
+		{
+			name: "wkt point",
+			v:    "POINT (116.4 39.9)",
+			g:    NewPoint(116.4, 39.9),
+			wkt:  "POINT (116.4 39.9)",
+		},
+		{
+			name: "wkt point z",
+			v:    "point z(1 2 3)",
+			g:    NewPoint(1, 2),
+			wkt:  "POINT (1 2)",
+		},
+		{
+			name: "wkt polygon with hole",
+			v:    "POLYGON ((0 0, 10 0, 10 10, 0 10, 0 0), (4 4, 6 4, 6 6, 4 6, 4 4))",
+			g:    &Geometry{Type: TypePolygon, Polygons: [][][]Point{square}},
+			wkt:  "POLYGON ((0 0, 10 0, 10 10, 0 10, 0 0), (4 4, 6 4, 6 6, 4 6, 4 4))",
+		},
+		{
+			name: "wkt multipoint",
+			v:    "MULTIPOINT ((1 2), (3 4))",
+			g:    &Geometry{Type: TypeMultiPoint, Points: []Point{{1, 2}, {3, 4}}},
+			wkt:  "MULTIPOINT (1 2, 3 4)",
+		},
+		{
+			name: "wkt multipolygon",
+			v:    "MULTIPOLYGON (((0 0, 1 0, 1 1, 0 0)), ((5 5, 6 5, 6 6, 5 5)))",
+			g:    &Geometry{Type: TypeMultiPolygon, Polygons: [][][]Point{{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}, {{{5, 5}, {6, 5}, {6, 6}, {5, 5}}}}},
+			wkt:  "MULTIPOLYGON (((0 0, 1 0, 1 1, 0 0)), ((5 5, 6 5, 6 6, 5 5)))",
+		},
+		{
+			name: "geojson text",
+			v:    `{"type":"LineString","coordinates":[[1,2],[3,4.5]]}`,
+			g:    &Geometry{Type: TypeLineString, Lines: [][]Point{{{1, 2}, {3, 4.5}}}},
+			wkt:  "LINESTRING (1 2, 3 4.5)",
+		},
+		{
+			name: "geojson feature",
+			v: map[string]any{
+				"type":       "Feature",
+				"properties": map[string]any{"name": "a"},
+				"geometry":   map[string]any{"type": "Point", "coordinates": []any{1, 2.5}},
+			},
+			g:   NewPoint(1, 2.5),
+			wkt: "POINT (1 2.5)",
+		},
+		{
+			name: "array",
+			v:    []any{int64(1), 2.0},
+			g:    NewPoint(1, 2),
+			wkt:  "POINT (1 2)",
+		},
+		{
+			name: "unknown wkt",
+			v:    "CIRCLE (1 2)",
+			err:  "invalid WKT CIRCLE (1 2): unsupported geometry type CIRCLE",
+		},
+		{
+			name: "unclosed",
+			v:    "POLYGON ((0 0, 1 0, 1 1, 0 1))",
+			err:  "polygon ring must be closed and have at least 4 positions",
+		},
+		{
+			name: "trailing",
+			v:    "POINT (1 2) abc",
+			err:  "invalid WKT POINT (1 2) abc: unexpected abc",
+		},
+		{
+			name: "invalid type",
+			v:    12,
+			err:  "cannot convert 12(int) to geometry",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := Parse(tt.v)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.g, g)
+			require.Equal(t, tt.wkt, g.WKT())
+			back, err := FromGeoJSON(g.GeoJSON())
+			require.NoError(t, err)
+			require.Equal(t, g, back)
+		})
+	}
+}
+
+func TestCalc(t *testing.T) {
+	beijing := Point{116.4074, 39.9042}
+	shanghai := Point{121.4737, 31.2304}
+	assert.InDelta(t, 1067000, Haversine(beijing, shanghai), 1000)
+	assert.InDelta(t, 0, Bearing(Point{0, 0}, Point{0, 1}), 1e-9)
+	assert.InDelta(t, 90, Bearing(Point{0, 0}, Point{1, 0}), 1e-9)
+	assert.InDelta(t, 270, Bearing(Point{0, 0}, Point{-1, 0}), 1e-9)
+
+	dest := Destination(beijing, 1000, 45)
+	assert.InDelta(t, 1000, Haversine(beijing, dest), 1e-6)
+	assert.InDelta(t, 45, Bearing(beijing, dest), 0.01)
+
+	square, err := ParseWKT("POLYGON ((0 0, 10 0, 10 10, 0 10, 0 0), (4 4, 6 4, 6 6, 4 6, 4 4))")
+	require.NoError(t, err)
+	assert.True(t, square.Contains(Point{1, 1}))
+	assert.True(t, square.Contains(Point{0, 5}))
+	assert.False(t, square.Contains(Point{5, 5}))
+	assert.True(t, square.Contains(Point{4, 5}))
+	assert.False(t, square.Contains(Point{11, 5}))
+
+	in, err := Within(NewPoint(1, 1), square)
+	require.NoError(t, err)
+	assert.True(t, in)
+	_, err = Within(square, square)
+	require.EqualError(t, err, "within only supports point geometry as the first argument but got Polygon")
+
+	assert.Equal(t, 0.0, Distance(NewPoint(1, 1), square))
+	// 1 degree of longitude at latitude 5
+	assert.InDelta(t, 110772, Distance(NewPoint(11, 5), square), 10)
+	assert.InDelta(t, 110772, Distance(NewPoint(5, 5), square), 10)
+	line, err := ParseWKT("LINESTRING (-1 -1, -1 1)")
+	require.NoError(t, err)
+	assert.InDelta(t, 111195, Distance(NewPoint(0, 0), line), 10)
+	cross, err := ParseWKT("LINESTRING (-1 5, 1 5)")
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, Distance(cross, square))
+
+	buf, err := Buffer(NewPoint(116.4, 39.9), 500, 32)
+	require.NoError(t, err)
+	require.Len(t, buf.Polygons[0][0], 33)
+	for _, p := range buf.Polygons[0][0] {
+		assert.InDelta(t, 500, Haversine(Point{116.4, 39.9}, p), 1e-6)
+	}
+	assert.True(t, buf.Contains(Point{116.401, 39.901}))
+	assert.False(t, buf.Contains(Point{116.41, 39.9}))
+	_, err = Buffer(square, 10, 32)
+	require.EqualError(t, err, "buffer only supports point geometry but got Polygon")
+}
+
+func TestIndex(t *testing.T) {
+	idx := NewIndex(nil)
+	require.Nil(t, idx.Search(Point{0, 0}))
+
+	r := rand.New(rand.NewSource(1))
+	rects := make([]Rect, 1000)
+	for i := range rects {
+		x, y := r.Float64()*100, r.Float64()*100
+		rects[i] = Rect{MinX: x, MinY: y, MaxX: x + r.Float64()*5, MaxY: y + r.Float64()*5}
+	}
+	idx = NewIndex(rects)
+	for i := 0; i < 100; i++ {
+		p := Point{r.Float64() * 100, r.Float64() * 100}
+		var expected []int
+		for j, rect := range rects {
+			if rect.ContainsPoint(p) {
+				expected = append(expected, j)
+			}
+		}
+		require.Equal(t, expected, idx.Search(p))
+	}
+}
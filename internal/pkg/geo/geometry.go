@@ -0,0 +1,288 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package geo provides the geometry model, the WKT/GeoJSON codec and the spherical calculations
+// for the geospatial functions and the geofence lookup table.
+package geo
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+const (
+	TypePoint           = "Point"
+	TypeMultiPoint      = "MultiPoint"
+	TypeLineString      = "LineString"
+	TypeMultiLineString = "MultiLineString"
+	TypePolygon         = "Polygon"
+	TypeMultiPolygon    = "MultiPolygon"
+)
+
+// Point is a position of longitude and latitude in degrees
+type Point [2]float64
+
+func (p Point) Lon() float64 { return p[0] }
+func (p Point) Lat() float64 { return p[1] }
+
+// Geometry is a simple feature geometry. Only the field of its type is set.
+type Geometry struct {
+	Type string
+	// Points for Point and MultiPoint
+	Points []Point
+	// Lines for LineString and MultiLineString
+	Lines [][]Point
+	// Polygons for Polygon and MultiPolygon. Each polygon is a list of rings, the first is the exterior ring and the rest are holes.
+	Polygons [][][]Point
+}
+
+// NewPoint creates a point geometry
+func NewPoint(lon, lat float64) *Geometry {
+	return &Geometry{Type: TypePoint, Points: []Point{{lon, lat}}}
+}
+
+// IsPolygonal returns whether the geometry is a polygon or multi polygon
+func (g *Geometry) IsPolygonal() bool {
+	return g.Type == TypePolygon || g.Type == TypeMultiPolygon
+}
+
+// Parse converts a value to geometry. The value can be a GeoJSON geometry or feature object, a GeoJSON text,
+// a WKT text or a [lon, lat] array.
+func Parse(v any) (*Geometry, error) {
+	switch vt := v.(type) {
+	case *Geometry:
+		return vt, nil
+	case map[string]any:
+		return FromGeoJSON(vt)
+	case []byte:
+		return Parse(string(vt))
+	case string:
+		s := strings.TrimSpace(vt)
+		if strings.HasPrefix(s, "{") {
+			m := make(map[string]any)
+			if err := json.Unmarshal([]byte(s), &m); err != nil {
+				return nil, fmt.Errorf("invalid GeoJSON %s: %v", s, err)
+			}
+			return FromGeoJSON(m)
+		}
+		return ParseWKT(s)
+	case []any, []float64:
+		p, err := toPoint(vt)
+		if err != nil {
+			return nil, err
+		}
+		return &Geometry{Type: TypePoint, Points: []Point{p}}, nil
+	default:
+		return nil, fmt.Errorf("cannot convert %v(%T) to geometry", v, v)
+	}
+}
+
+// FromGeoJSON converts a GeoJSON geometry or feature object to geometry
+func FromGeoJSON(m map[string]any) (*Geometry, error) {
+	t, _ := m["type"].(string)
+	if t == "Feature" {
+		gm, ok := m["geometry"].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("GeoJSON feature has no geometry")
+		}
+		return FromGeoJSON(gm)
+	}
+	coords, ok := m["coordinates"]
+	if !ok {
+		return nil, fmt.Errorf("GeoJSON %s has no coordinates", t)
+	}
+	g := &Geometry{Type: t}
+	var err error
+	switch t {
+	case TypePoint:
+		var p Point
+		p, err = toPoint(coords)
+		g.Points = []Point{p}
+	case TypeMultiPoint, TypeLineString:
+		var ps []Point
+		ps, err = toPoints(coords)
+		if t == TypeMultiPoint {
+			g.Points = ps
+		} else {
+			g.Lines = [][]Point{ps}
+		}
+	case TypeMultiLineString, TypePolygon:
+		var rings [][]Point
+		rings, err = toRings(coords)
+		if t == TypeMultiLineString {
+			g.Lines = rings
+		} else {
+			g.Polygons = [][][]Point{rings}
+		}
+	case TypeMultiPolygon:
+		arr, ok := coords.([]any)
+		if !ok {
+			return nil, fmt.Errorf("invalid coordinates of %s: %v", t, coords)
+		}
+		g.Polygons = make([][][]Point, 0, len(arr))
+		for _, c := range arr {
+			rings, err := toRings(c)
+			if err != nil {
+				return nil, err
+			}
+			g.Polygons = append(g.Polygons, rings)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported geometry type %s", t)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return g, g.validate()
+}
+
+func toPoint(v any) (Point, error) {
+	var p Point
+	switch vt := v.(type) {
+	case []float64:
+		if len(vt) < 2 {
+			return p, fmt.Errorf("invalid position %v", v)
+		}
+		return Point{vt[0], vt[1]}, nil
+	case []any:
+		if len(vt) < 2 {
+			return p, fmt.Errorf("invalid position %v", v)
+		}
+		for i := 0; i < 2; i++ {
+			f, err := cast.ToFloat64(vt[i], cast.CONVERT_SAMEKIND)
+			if err != nil {
+				return p, fmt.Errorf("invalid position %v", v)
+			}
+			p[i] = f
+		}
+		return p, nil
+	default:
+		return p, fmt.Errorf("invalid position %v", v)
+	}
+}
+
+func toPoints(v any) ([]Point, error) {
+	arr, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("invalid positions %v", v)
+	}
+	r := make([]Point, 0, len(arr))
+	for _, c := range arr {
+		p, err := toPoint(c)
+		if err != nil {
+			return nil, err
+		}
+		r = append(r, p)
+	}
+	return r, nil
+}
+
+func toRings(v any) ([][]Point, error) {
+	arr, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("invalid positions %v", v)
+	}
+	r := make([][]Point, 0, len(arr))
+	for _, c := range arr {
+		ps, err := toPoints(c)
+		if err != nil {
+			return nil, err
+		}
+		r = append(r, ps)
+	}
+	return r, nil
+}
+
+func (g *Geometry) validate() error {
+	switch g.Type {
+	case TypePoint, TypeMultiPoint:
+		if len(g.Points) == 0 {
+			return fmt.Errorf("%s has no position", g.Type)
+		}
+	case TypeLineString, TypeMultiLineString:
+		if len(g.Lines) == 0 {
+			return fmt.Errorf("%s has no line", g.Type)
+		}
+		for _, l := range g.Lines {
+			if len(l) < 2 {
+				return fmt.Errorf("line must have at least 2 positions")
+			}
+		}
+	case TypePolygon, TypeMultiPolygon:
+		if len(g.Polygons) == 0 {
+			return fmt.Errorf("%s has no polygon", g.Type)
+		}
+		for _, poly := range g.Polygons {
+			if len(poly) == 0 {
+				return fmt.Errorf("polygon must have an exterior ring")
+			}
+			for _, ring := range poly {
+				if len(ring) < 4 || ring[0] != ring[len(ring)-1] {
+					return fmt.Errorf("polygon ring must be closed and have at least 4 positions")
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// GeoJSON converts the geometry to GeoJSON geometry object
+func (g *Geometry) GeoJSON() map[string]any {
+	var coords any
+	switch g.Type {
+	case TypePoint:
+		coords = pointJSON(g.Points[0])
+	case TypeMultiPoint:
+		coords = pointsJSON(g.Points)
+	case TypeLineString:
+		coords = pointsJSON(g.Lines[0])
+	case TypeMultiLineString:
+		coords = ringsJSON(g.Lines)
+	case TypePolygon:
+		coords = ringsJSON(g.Polygons[0])
+	case TypeMultiPolygon:
+		polys := make([]any, len(g.Polygons))
+		for i, poly := range g.Polygons {
+			polys[i] = ringsJSON(poly)
+		}
+		coords = polys
+	}
+	return map[string]any{
+		"type":        g.Type,
+		"coordinates": coords,
+	}
+}
+
+func pointJSON(p Point) []any {
+	return []any{p[0], p[1]}
+}
+
+func pointsJSON(ps []Point) []any {
+	r := make([]any, len(ps))
+	for i, p := range ps {
+		r[i] = pointJSON(p)
+	}
+	return r
+}
+
+func ringsJSON(rings [][]Point) []any {
+	r := make([]any, len(rings))
+	for i, ring := range rings {
+		r[i] = pointsJSON(ring)
+	}
+	return r
+}
@@ -0,0 +1,126 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geo
+
+import (
+	"math"
+	"sort"
+)
+
+// Rect is a bounding box
+type Rect struct {
+	MinX, MinY, MaxX, MaxY float64
+}
+
+func (r Rect) ContainsPoint(p Point) bool {
+	return p[0] >= r.MinX && p[0] <= r.MaxX && p[1] >= r.MinY && p[1] <= r.MaxY
+}
+
+func (r Rect) union(o Rect) Rect {
+	return Rect{
+		MinX: math.Min(r.MinX, o.MinX),
+		MinY: math.Min(r.MinY, o.MinY),
+		MaxX: math.Max(r.MaxX, o.MaxX),
+		MaxY: math.Max(r.MaxY, o.MaxY),
+	}
+}
+
+func (r Rect) center() (float64, float64) {
+	return (r.MinX + r.MaxX) / 2, (r.MinY + r.MaxY) / 2
+}
+
+const nodeCapacity = 16
+
+type indexNode struct {
+	rect     Rect
+	children []*indexNode
+	// item is the index of the item for leaf entries, -1 for the inner nodes
+	item int
+}
+
+// Index is a static R-tree of bounding boxes packed by the Sort-Tile-Recursive algorithm.
+// It is built once and is read-only, so it is safe for concurrent searches.
+type Index struct {
+	root *indexNode
+}
+
+// NewIndex builds the index of the bounding boxes. The search result is the position of the boxes.
+func NewIndex(rects []Rect) *Index {
+	if len(rects) == 0 {
+		return &Index{}
+	}
+	level := make([]*indexNode, len(rects))
+	for i, r := range rects {
+		level[i] = &indexNode{rect: r, item: i}
+	}
+	for len(level) > 1 {
+		level = pack(level)
+	}
+	return &Index{root: level[0]}
+}
+
+// pack groups the nodes of a level into the parent nodes by sorting them into vertical slices and then tiles
+func pack(nodes []*indexNode) []*indexNode {
+	parentCount := int(math.Ceil(float64(len(nodes)) / nodeCapacity))
+	sliceCount := int(math.Ceil(math.Sqrt(float64(parentCount))))
+	sliceSize := sliceCount * nodeCapacity
+	sort.Slice(nodes, func(i, j int) bool {
+		xi, _ := nodes[i].rect.center()
+		xj, _ := nodes[j].rect.center()
+		return xi < xj
+	})
+	parents := make([]*indexNode, 0, parentCount)
+	for start := 0; start < len(nodes); start += sliceSize {
+		slice := nodes[start:min(start+sliceSize, len(nodes))]
+		sort.Slice(slice, func(i, j int) bool {
+			_, yi := slice[i].rect.center()
+			_, yj := slice[j].rect.center()
+			return yi < yj
+		})
+		for s := 0; s < len(slice); s += nodeCapacity {
+			children := slice[s:min(s+nodeCapacity, len(slice))]
+			parent := &indexNode{rect: children[0].rect, item: -1, children: append([]*indexNode{}, children...)}
+			for _, c := range children[1:] {
+				parent.rect = parent.rect.union(c.rect)
+			}
+			parents = append(parents, parent)
+		}
+	}
+	return parents
+}
+
+// Search returns the positions of the boxes which contain the point in ascending order
+func (idx *Index) Search(p Point) []int {
+	if idx.root == nil {
+		return nil
+	}
+	var result []int
+	var visit func(n *indexNode)
+	visit = func(n *indexNode) {
+		if !n.rect.ContainsPoint(p) {
+			return
+		}
+		if n.item >= 0 {
+			result = append(result, n.item)
+			return
+		}
+		for _, c := range n.children {
+			visit(c)
+		}
+	}
+	visit(idx.root)
+	sort.Ints(result)
+	return result
+}
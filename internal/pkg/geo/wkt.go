@@ -0,0 +1,307 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var wktTypes = map[string]string{
+	"POINT":           TypePoint,
+	"MULTIPOINT":      TypeMultiPoint,
+	"LINESTRING":      TypeLineString,
+	"MULTILINESTRING": TypeMultiLineString,
+	"POLYGON":         TypePolygon,
+	"MULTIPOLYGON":    TypeMultiPolygon,
+}
+
+// ParseWKT parses the well-known text of a geometry. The Z and M values are ignored.
+func ParseWKT(s string) (*Geometry, error) {
+	p := &wktParser{s: s}
+	name := strings.ToUpper(p.word())
+	t, ok := wktTypes[name]
+	if !ok {
+		return nil, fmt.Errorf("invalid WKT %s: unsupported geometry type %s", s, name)
+	}
+	// Skip the dimension suffix like POINT Z
+	if w := strings.ToUpper(p.peekWord()); w == "Z" || w == "M" || w == "ZM" {
+		p.word()
+	}
+	g := &Geometry{Type: t}
+	var err error
+	switch t {
+	case TypePoint:
+		var ps []Point
+		ps, err = p.points()
+		if err == nil && len(ps) != 1 {
+			err = fmt.Errorf("point must have exactly one position")
+		}
+		g.Points = ps
+	case TypeMultiPoint:
+		g.Points, err = p.multiPoints()
+	case TypeLineString:
+		var ps []Point
+		ps, err = p.points()
+		g.Lines = [][]Point{ps}
+	case TypeMultiLineString:
+		g.Lines, err = p.rings()
+	case TypePolygon:
+		var rings [][]Point
+		rings, err = p.rings()
+		g.Polygons = [][][]Point{rings}
+	case TypeMultiPolygon:
+		g.Polygons, err = p.polygons()
+	}
+	if err == nil {
+		p.skipSpace()
+		if p.pos < len(p.s) {
+			err = fmt.Errorf("unexpected %s", p.s[p.pos:])
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid WKT %s: %v", s, err)
+	}
+	return g, g.validate()
+}
+
+type wktParser struct {
+	s   string
+	pos int
+}
+
+func (p *wktParser) skipSpace() {
+	for p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t' || p.s[p.pos] == '\n' || p.s[p.pos] == '\r') {
+		p.pos++
+	}
+}
+
+func (p *wktParser) peekWord() string {
+	pos := p.pos
+	w := p.word()
+	p.pos = pos
+	return w
+}
+
+func (p *wktParser) word() string {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+			p.pos++
+		} else {
+			break
+		}
+	}
+	return p.s[start:p.pos]
+}
+
+func (p *wktParser) expect(c byte) error {
+	p.skipSpace()
+	if p.pos >= len(p.s) || p.s[p.pos] != c {
+		return fmt.Errorf("expect %c at position %d", c, p.pos)
+	}
+	p.pos++
+	return nil
+}
+
+// next returns true if the next char is c and consumes it
+func (p *wktParser) next(c byte) bool {
+	p.skipSpace()
+	if p.pos < len(p.s) && p.s[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *wktParser) number() (float64, error) {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		if (c >= '0' && c <= '9') || c == '.' || c == '-' || c == '+' || c == 'e' || c == 'E' {
+			p.pos++
+		} else {
+			break
+		}
+	}
+	return strconv.ParseFloat(p.s[start:p.pos], 64)
+}
+
+// position parses x y [z [m]]
+func (p *wktParser) position() (Point, error) {
+	var pt Point
+	for i := 0; i < 2; i++ {
+		f, err := p.number()
+		if err != nil {
+			return pt, fmt.Errorf("invalid coordinate at position %d", p.pos)
+		}
+		pt[i] = f
+	}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.s) || p.s[p.pos] == ',' || p.s[p.pos] == ')' {
+			break
+		}
+		if _, err := p.number(); err != nil {
+			return pt, fmt.Errorf("invalid coordinate at position %d", p.pos)
+		}
+	}
+	return pt, nil
+}
+
+// points parses (x y, x y, ...)
+func (p *wktParser) points() ([]Point, error) {
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	var r []Point
+	for {
+		pt, err := p.position()
+		if err != nil {
+			return nil, err
+		}
+		r = append(r, pt)
+		if !p.next(',') {
+			break
+		}
+	}
+	return r, p.expect(')')
+}
+
+// multiPoints parses both ((x y), (x y)) and (x y, x y)
+func (p *wktParser) multiPoints() ([]Point, error) {
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	var r []Point
+	for {
+		if p.next('(') {
+			pt, err := p.position()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(')'); err != nil {
+				return nil, err
+			}
+			r = append(r, pt)
+		} else {
+			pt, err := p.position()
+			if err != nil {
+				return nil, err
+			}
+			r = append(r, pt)
+		}
+		if !p.next(',') {
+			break
+		}
+	}
+	return r, p.expect(')')
+}
+
+func (p *wktParser) rings() ([][]Point, error) {
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	var r [][]Point
+	for {
+		ps, err := p.points()
+		if err != nil {
+			return nil, err
+		}
+		r = append(r, ps)
+		if !p.next(',') {
+			break
+		}
+	}
+	return r, p.expect(')')
+}
+
+func (p *wktParser) polygons() ([][][]Point, error) {
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	var r [][][]Point
+	for {
+		rings, err := p.rings()
+		if err != nil {
+			return nil, err
+		}
+		r = append(r, rings)
+		if !p.next(',') {
+			break
+		}
+	}
+	return r, p.expect(')')
+}
+
+// WKT converts the geometry to well-known text
+func (g *Geometry) WKT() string {
+	b := &strings.Builder{}
+	switch g.Type {
+	case TypePoint:
+		b.WriteString("POINT ")
+		writePoints(b, g.Points)
+	case TypeMultiPoint:
+		b.WriteString("MULTIPOINT ")
+		writePoints(b, g.Points)
+	case TypeLineString:
+		b.WriteString("LINESTRING ")
+		writePoints(b, g.Lines[0])
+	case TypeMultiLineString:
+		b.WriteString("MULTILINESTRING ")
+		writeRings(b, g.Lines)
+	case TypePolygon:
+		b.WriteString("POLYGON ")
+		writeRings(b, g.Polygons[0])
+	case TypeMultiPolygon:
+		b.WriteString("MULTIPOLYGON (")
+		for i, poly := range g.Polygons {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			writeRings(b, poly)
+		}
+		b.WriteString(")")
+	}
+	return b.String()
+}
+
+func writePoints(b *strings.Builder, ps []Point) {
+	b.WriteString("(")
+	for i, p := range ps {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(strconv.FormatFloat(p[0], 'f', -1, 64))
+		b.WriteString(" ")
+		b.WriteString(strconv.FormatFloat(p[1], 'f', -1, 64))
+	}
+	b.WriteString(")")
+}
+
+func writeRings(b *strings.Builder, rings [][]Point) {
+	b.WriteString("(")
+	for i, ring := range rings {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		writePoints(b, ring)
+	}
+	b.WriteString(")")
+}
@@ -28,6 +28,18 @@ func (m *Manager) Function(name string) (api.Function, error) {
 	return f, nil
 }
 
+// IsIncAggFunction reads the capability saved when installing to avoid creating the vm
+func (m *Manager) IsIncAggFunction(funcName string) bool {
+	s, err := m.GetScript(funcName)
+	return err == nil && s.IsAgg && s.Incremental
+}
+
+// IsIncAggMergeFunction reads the merge capability saved when installing
+func (m *Manager) IsIncAggMergeFunction(funcName string) bool {
+	s, err := m.GetScript(funcName)
+	return err == nil && s.IsAgg && s.Mergeable
+}
+
 func (m *Manager) HasFunctionSet(_ string) bool {
 	return false
 }
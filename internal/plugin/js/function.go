@@ -28,10 +28,23 @@ type JSFunc struct {
 	vm     *goja.Runtime
 	jsfunc goja.Callable
 	isAgg  bool
+	// The callbacks of the aggregate function defined as an object. Nil for the function definition
+	callbacks *aggCallbacks
 	// state, use this to avoid creating new array each time
 	args []goja.Value
 }
 
+// aggCallbacks are the callbacks of the aggregate function which can be calculated incrementally.
+// The optional merge callback combines the partial states so that the windows can share the states of their panes.
+type aggCallbacks struct {
+	init       goja.Callable
+	accumulate goja.Callable
+	merge      goja.Callable
+	result     goja.Callable
+	stringify  goja.Callable
+	parse      goja.Callable
+}
+
 func NewJSFunc(symbolName string) (*JSFunc, error) {
 	s, err := GetManager().GetScript(symbolName)
 	if err != nil {
@@ -39,21 +52,58 @@ func NewJSFunc(symbolName string) (*JSFunc, error) {
 	}
 	vm := goja.New()
 	// Get the text from the symbol table
-	_, _ = vm.RunString(s.Script)
-	// Should not happen, already verify when install
-	//if err != nil {
-	//	return nil, fmt.Errorf("failed to interpret script: %v", err)
-	//}
-	exec, _ := goja.AssertFunction(vm.Get(symbolName))
-	// Should not happen, already verify when install
-	//if !ok {
-	//	return nil, fmt.Errorf("cannot find function \"%s\" in script", symbolName)
-	//}
-	return &JSFunc{
-		vm:     vm,
-		jsfunc: exec,
-		isAgg:  s.IsAgg,
-	}, nil
+	_, err = vm.RunString(s.Script)
+	if err != nil {
+		return nil, fmt.Errorf("failed to interpret script: %v", err)
+	}
+	result := &JSFunc{
+		vm:    vm,
+		isAgg: s.IsAgg,
+	}
+	if exec, ok := goja.AssertFunction(vm.Get(symbolName)); ok {
+		result.jsfunc = exec
+	} else {
+		// The aggregate function defined as an object with callbacks
+		result.callbacks, err = getAggCallbacks(vm, symbolName)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// getAggCallbacks gets the callbacks from the aggregate object. The init, accumulate and result callbacks are required.
+// The merge callback is optional.
+func getAggCallbacks(vm *goja.Runtime, symbolName string) (*aggCallbacks, error) {
+	v := vm.Get(symbolName)
+	if v == nil || goja.IsUndefined(v) || goja.IsNull(v) {
+		return nil, fmt.Errorf("cannot find function \"%s\" in script", symbolName)
+	}
+	obj := v.ToObject(vm)
+	r := &aggCallbacks{}
+	for _, cb := range []struct {
+		name     string
+		f        *goja.Callable
+		required bool
+	}{
+		{"init", &r.init, true},
+		{"accumulate", &r.accumulate, true},
+		{"merge", &r.merge, false},
+		{"result", &r.result, true},
+	} {
+		f, ok := goja.AssertFunction(obj.Get(cb.name))
+		if !ok {
+			if cb.required {
+				return nil, fmt.Errorf("cannot find callback \"%s\" of aggregate \"%s\" in script", cb.name, symbolName)
+			}
+			continue
+		}
+		*cb.f = f
+	}
+	jsonObj := vm.Get("JSON").ToObject(vm)
+	r.stringify, _ = goja.AssertFunction(jsonObj.Get("stringify"))
+	r.parse, _ = goja.AssertFunction(jsonObj.Get("parse"))
+	return r, nil
 }
 
 func (f *JSFunc) Validate(_ []interface{}) error {
@@ -62,6 +112,9 @@ func (f *JSFunc) Validate(_ []interface{}) error {
 
 func (f *JSFunc) Exec(ctx api.FunctionContext, args []any) (interface{}, bool) {
 	ctx.GetLogger().Debugf("running js func with args %+v", args)
+	if f.callbacks != nil {
+		return f.execAgg(args)
+	}
 	if len(args) != len(f.args) {
 		f.args = make([]goja.Value, len(args))
 	}
@@ -73,18 +126,161 @@ func (f *JSFunc) Exec(ctx api.FunctionContext, args []any) (interface{}, bool) {
 		ctx.GetLogger().Errorf("failed to execute script: %v", err)
 		return err, false
 	} else {
-		result := val.Export()
-		switch t := result.(type) {
-		case float64:
-			if math.IsNaN(t) {
-				return fmt.Errorf("result is NaN"), false
-			}
-			if math.IsInf(t, 0) {
-				return fmt.Errorf("result is Inf"), false
+		return exportResult(val)
+	}
+}
+
+// execAgg folds all the rows in the window with the callbacks. Each arg is the list of the values of all rows.
+func (f *JSFunc) execAgg(args []any) (interface{}, bool) {
+	acc, err := f.callbacks.init(goja.Undefined())
+	if err != nil {
+		return fmt.Errorf("failed to init aggregate state: %v", err), false
+	}
+	rows := 0
+	if len(args) > 0 {
+		arg0, ok := args[0].([]interface{})
+		if !ok {
+			return fmt.Errorf("the argument of aggregate function must be a list but got %v", args[0]), false
+		}
+		rows = len(arg0)
+	}
+	rowArgs := make([]interface{}, len(args))
+	for i := 0; i < rows; i++ {
+		for j, arg := range args {
+			col, ok := arg.([]interface{})
+			if !ok || len(col) != rows {
+				return fmt.Errorf("the arguments of aggregate function must be lists of the same length"), false
 			}
+			rowArgs[j] = col[i]
+		}
+		acc, err = f.accumulate(acc, rowArgs)
+		if err != nil {
+			return err, false
+		}
+	}
+	val, err := f.callbacks.result(goja.Undefined(), acc)
+	if err != nil {
+		return fmt.Errorf("failed to get aggregate result: %v", err), false
+	}
+	return exportResult(val)
+}
+
+func (f *JSFunc) accumulate(acc goja.Value, args []interface{}) (goja.Value, error) {
+	if len(f.args) != len(args)+1 {
+		f.args = make([]goja.Value, len(args)+1)
+	}
+	f.args[0] = acc
+	for i, arg := range args {
+		f.args[i+1] = f.vm.ToValue(arg)
+	}
+	r, err := f.callbacks.accumulate(goja.Undefined(), f.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to accumulate aggregate state: %v", err)
+	}
+	return r, nil
+}
+
+// IsIncremental returns true if the aggregate is defined by callbacks so that it can be calculated incrementally
+func (f *JSFunc) IsIncremental() bool {
+	return f.callbacks != nil
+}
+
+// ExecIncremental folds the current row into the state saved in the function context and returns the current result.
+// The state is saved as a json string so that it can be serialized for checkpoints.
+func (f *JSFunc) ExecIncremental(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+	key := fmt.Sprintf("%v_inc_js", ctx.GetFuncId())
+	v, err := ctx.GetState(key)
+	if err != nil {
+		return err, false
+	}
+	var acc goja.Value
+	if s, ok := v.(string); ok {
+		acc, err = f.callbacks.parse(goja.Undefined(), f.vm.ToValue(s))
+		if err != nil {
+			return fmt.Errorf("failed to restore aggregate state: %v", err), false
+		}
+	} else {
+		acc, err = f.callbacks.init(goja.Undefined())
+		if err != nil {
+			return fmt.Errorf("failed to init aggregate state: %v", err), false
+		}
+	}
+	acc, err = f.accumulate(acc, args)
+	if err != nil {
+		return err, false
+	}
+	s, err := f.callbacks.stringify(goja.Undefined(), acc)
+	if err != nil {
+		return fmt.Errorf("failed to serialize aggregate state: %v", err), false
+	}
+	err = ctx.PutState(key, s.String())
+	if err != nil {
+		return err, false
+	}
+	val, err := f.callbacks.result(goja.Undefined(), acc)
+	if err != nil {
+		return fmt.Errorf("failed to get aggregate result: %v", err), false
+	}
+	return exportResult(val)
+}
+
+// IsMergeable returns true if the partial states of the incremental aggregate can be merged by the merge callback
+func (f *JSFunc) IsMergeable() bool {
+	return f.callbacks != nil && f.callbacks.merge != nil
+}
+
+// MergeIncremental combines the states saved by ExecIncremental in the partial function contexts.
+// The merged state is saved in ctx and the result of the merged state is returned.
+func (f *JSFunc) MergeIncremental(ctx api.FunctionContext, partials []api.FunctionContext) (interface{}, bool) {
+	acc, err := f.callbacks.init(goja.Undefined())
+	if err != nil {
+		return fmt.Errorf("failed to init aggregate state: %v", err), false
+	}
+	for _, p := range partials {
+		v, err := p.GetState(fmt.Sprintf("%v_inc_js", p.GetFuncId()))
+		if err != nil {
+			return err, false
+		}
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		state, err := f.callbacks.parse(goja.Undefined(), f.vm.ToValue(s))
+		if err != nil {
+			return fmt.Errorf("failed to restore aggregate state: %v", err), false
+		}
+		acc, err = f.callbacks.merge(goja.Undefined(), acc, state)
+		if err != nil {
+			return fmt.Errorf("failed to merge aggregate state: %v", err), false
+		}
+	}
+	s, err := f.callbacks.stringify(goja.Undefined(), acc)
+	if err != nil {
+		return fmt.Errorf("failed to serialize aggregate state: %v", err), false
+	}
+	err = ctx.PutState(fmt.Sprintf("%v_inc_js", ctx.GetFuncId()), s.String())
+	if err != nil {
+		return err, false
+	}
+	val, err := f.callbacks.result(goja.Undefined(), acc)
+	if err != nil {
+		return fmt.Errorf("failed to get aggregate result: %v", err), false
+	}
+	return exportResult(val)
+}
+
+func exportResult(val goja.Value) (interface{}, bool) {
+	result := val.Export()
+	switch t := result.(type) {
+	case float64:
+		if math.IsNaN(t) {
+			return fmt.Errorf("result is NaN"), false
+		}
+		if math.IsInf(t, 0) {
+			return fmt.Errorf("result is Inf"), false
 		}
-		return result, true
 	}
+	return result, true
 }
 
 func (f *JSFunc) IsAggregate() bool {
@@ -18,8 +18,12 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/lf-edge/ekuiper/contract/v2/api"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/lf-edge/ekuiper/v2/internal/binder"
+	"github.com/lf-edge/ekuiper/v2/internal/binder/function"
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	kctx "github.com/lf-edge/ekuiper/v2/internal/topo/context"
@@ -229,3 +233,155 @@ func TestScalarFuncComplexType(t *testing.T) {
 	err = ff.Close()
 	assert.NoError(t, err)
 }
+
+func TestAggCallbacks(t *testing.T) {
+	script := &Script{
+		Id:   "myavg",
+		Desc: "Test aggregate with callbacks",
+		Script: `var myavg = {
+  init: function() { return {sum: 0, count: 0}; },
+  accumulate: function(acc, v) { acc.sum += v; acc.count++; return acc; },
+  result: function(acc) { return acc.count === 0 ? null : acc.sum / acc.count; }
+};`,
+		IsAgg: true,
+	}
+	err := GetManager().Create(script)
+	assert.NoError(t, err)
+	defer func() {
+		err := GetManager().Delete("myavg")
+		assert.NoError(t, err)
+	}()
+	// the capability is saved when installing
+	s, err := GetManager().GetScript("myavg")
+	require.NoError(t, err)
+	assert.True(t, s.Incremental)
+	assert.True(t, GetManager().IsIncAggFunction("myavg"))
+	ff, err := NewJSFunc("myavg")
+	assert.NoError(t, err)
+	assert.True(t, ff.IsAggregate())
+	assert.True(t, ff.IsIncremental())
+
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+
+	// batch mode
+	result, ok := ff.Exec(fctx, []any{[]any{1, 2, 6}})
+	assert.True(t, ok)
+	assert.Equal(t, int64(3), result)
+	result, ok = ff.Exec(fctx, []any{[]any{}})
+	assert.True(t, ok)
+	assert.Nil(t, result)
+	result, ok = ff.Exec(fctx, []any{1})
+	assert.False(t, ok)
+	assert.EqualError(t, result.(error), "the argument of aggregate function must be a list but got 1")
+
+	// incremental mode, the state is kept in the function context
+	for i, exp := range []any{int64(1), 1.5, int64(3)} {
+		result, ok = ff.ExecIncremental(fctx, []any{[]any{1, 2, 6}[i]})
+		assert.True(t, ok)
+		assert.Equal(t, exp, result)
+	}
+	st, err := fctx.GetState("2_inc_js")
+	assert.NoError(t, err)
+	assert.Equal(t, `{"sum":9,"count":3}`, st)
+	// a new instance continues from the state
+	ff2, err := NewJSFunc("myavg")
+	assert.NoError(t, err)
+	result, ok = ff2.ExecIncremental(fctx, []any{11})
+	assert.True(t, ok)
+	assert.Equal(t, int64(5), result)
+
+	// the planner rewrites the aggregate to the inc_ version
+	require.NoError(t, function.Initialize([]binder.FactoryEntry{{Name: "js", Factory: GetManager()}}))
+	assert.True(t, function.IsSupportedIncAgg("myavg"))
+	assert.False(t, function.IsSupportedIncAgg("areas"))
+	incF, err := function.Function("inc_myavg")
+	require.NoError(t, err)
+	require.NotNil(t, incF)
+	assert.False(t, incF.IsAggregate())
+	fctx2 := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 3)
+	result, ok = incF.Exec(fctx2, []any{4})
+	assert.True(t, ok)
+	assert.Equal(t, int64(4), result)
+}
+
+func TestAggCallbacksMerge(t *testing.T) {
+	script := &Script{
+		Id: "mergeavg",
+		Script: `var mergeavg = {
+  init: function() { return {sum: 0, count: 0}; },
+  accumulate: function(acc, v) { acc.sum += v; acc.count++; return acc; },
+  merge: function(a, b) { return {sum: a.sum + b.sum, count: a.count + b.count}; },
+  result: function(acc) { return acc.count === 0 ? null : acc.sum / acc.count; }
+};`,
+		IsAgg: true,
+	}
+	require.NoError(t, GetManager().Create(script))
+	defer func() {
+		err := GetManager().Delete("mergeavg")
+		assert.NoError(t, err)
+	}()
+	s, err := GetManager().GetScript("mergeavg")
+	require.NoError(t, err)
+	assert.True(t, s.Mergeable)
+	assert.True(t, GetManager().IsIncAggMergeFunction("mergeavg"))
+	assert.False(t, GetManager().IsIncAggMergeFunction("myavg"))
+	ff, err := NewJSFunc("mergeavg")
+	require.NoError(t, err)
+	assert.True(t, ff.IsMergeable())
+
+	contextLogger := conf.Log.WithField("rule", "testMerge")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	// each partial state is saved in its own store
+	partials := make([]api.FunctionContext, 3)
+	for i, args := range [][]any{{1, 2}, {6}, {}} {
+		tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+		partials[i] = kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+		for _, arg := range args {
+			_, ok := ff.ExecIncremental(partials[i], []any{arg})
+			require.True(t, ok)
+		}
+	}
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	result, ok := ff.MergeIncremental(fctx, partials)
+	assert.True(t, ok)
+	assert.Equal(t, int64(3), result)
+	st, err := fctx.GetState("2_inc_js")
+	require.NoError(t, err)
+	assert.Equal(t, `{"sum":9,"count":3}`, st)
+	// the merged state can be accumulated further
+	result, ok = ff.ExecIncremental(fctx, []any{11})
+	assert.True(t, ok)
+	assert.Equal(t, int64(5), result)
+
+	// the inc_ wrapper delegates the merge
+	require.NoError(t, function.Initialize([]binder.FactoryEntry{{Name: "js", Factory: GetManager()}}))
+	assert.True(t, function.IsSupportedIncAggMerge("inc_mergeavg"))
+	assert.False(t, function.IsSupportedIncAggMerge("mergeavg"))
+	assert.False(t, function.IsSupportedIncAggMerge("inc_count"))
+	incF, err := function.Function("inc_mergeavg")
+	require.NoError(t, err)
+	mf, ok := incF.(function.IncAggMergeFunction)
+	require.True(t, ok)
+	assert.True(t, mf.IsMergeable())
+	result, ok = mf.MergeIncremental(fctx, partials[:1])
+	assert.True(t, ok)
+	assert.Equal(t, 1.5, result)
+}
+
+func TestAggCallbacksInvalid(t *testing.T) {
+	script := &Script{
+		Id:     "badagg",
+		Script: `var badagg = { init: function() { return 0; }, result: function(acc) { return acc; } };`,
+		IsAgg:  true,
+	}
+	err := GetManager().Create(script)
+	assert.EqualError(t, err, `cannot find callback "accumulate" of aggregate "badagg" in script`)
+	script.Script = `var badagg = { init: function() { return 0; }, accumulate: function(acc, v) { return acc + v; }, result: function(acc) { return acc; } };`
+	script.IsAgg = false
+	err = GetManager().Create(script)
+	assert.EqualError(t, err, `cannot find function "badagg" in script`)
+}
@@ -44,6 +44,10 @@ type Script struct {
 	Desc   string `json:"description"`
 	Script string `json:"script"`
 	IsAgg  bool   `json:"isAgg"`
+	// Incremental is set when installing if the aggregate is defined by callbacks
+	Incremental bool `json:"incremental,omitempty"`
+	// Mergeable is set when installing if the aggregate also defines the merge callback
+	Mergeable bool `json:"mergeable,omitempty"`
 }
 
 // InitManager initialize the manager, only called once by the server
@@ -91,9 +95,21 @@ func validate(script *Script) error {
 		return fmt.Errorf("failed to interprete script: %v", err)
 	}
 	_, ok := goja.AssertFunction(vm.Get(script.Id))
-	if !ok {
+	script.Incremental = false
+	script.Mergeable = false
+	if ok {
+		return nil
+	}
+	// An aggregate function can also be defined as an object with callbacks
+	if !script.IsAgg {
 		return fmt.Errorf("cannot find function \"%s\" in script", script.Id)
 	}
+	cbs, err := getAggCallbacks(vm, script.Id)
+	if err != nil {
+		return err
+	}
+	script.Incremental = true
+	script.Mergeable = cbs.merge != nil
 	return nil
 }
 
@@ -23,7 +23,6 @@ import (
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
-	"github.com/lf-edge/ekuiper/v2/internal/xsql"
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
 	"github.com/lf-edge/ekuiper/v2/pkg/kv"
@@ -193,7 +192,7 @@ func (p *RuleProcessor) GetRuleByJson(id, ruleJson string) (*def.Rule, error) {
 		if rule.Graph != nil {
 			return nil, fmt.Errorf("Rule %s has both sql and graph.", rule.Id)
 		}
-		if _, err := xsql.GetStatementFromSql(rule.Sql); err != nil {
+		if _, err := GetStreamProcessorStatement(rule.Sql); err != nil {
 			return nil, err
 		}
 		if len(rule.Actions) == 0 {
@@ -30,9 +30,11 @@ type RulesetProcessor struct {
 }
 
 type Ruleset struct {
-	Streams map[string]string `json:"streams"`
-	Tables  map[string]string `json:"tables"`
-	Rules   map[string]string `json:"rules"`
+	Streams      map[string]string `json:"streams"`
+	Tables       map[string]string `json:"tables"`
+	Views        map[string]string `json:"views,omitempty"`
+	SqlFunctions map[string]string `json:"sqlFunctions,omitempty"`
+	Rules        map[string]string `json:"rules"`
 }
 
 func NewRulesetProcessor(r *RuleProcessor, s *StreamProcessor) *RulesetProcessor {
@@ -50,6 +52,12 @@ func (rs *RulesetProcessor) Export() (io.ReadSeeker, []int, error) {
 	}
 	all.Streams = allStreams["streams"]
 	all.Tables = allStreams["tables"]
+	if all.Views, err = rs.s.GetAllViews(); err != nil {
+		return nil, nil, fmt.Errorf("fail to get all views: %v", err)
+	}
+	if all.SqlFunctions, err = rs.s.GetAllFunctions(); err != nil {
+		return nil, nil, fmt.Errorf("fail to get all functions: %v", err)
+	}
 	rules, err := rs.r.GetAllRulesJson()
 	if err != nil {
 		return nil, nil, fmt.Errorf("fail to get all rules: %v", err)
@@ -72,6 +80,14 @@ func (rs *RulesetProcessor) ExportRuleSet() *Ruleset {
 	}
 	all.Streams = allStreams["streams"]
 	all.Tables = allStreams["tables"]
+	if all.Views, err = rs.s.GetAllViews(); err != nil {
+		conf.Log.Errorf("fail to get all views: %v", err)
+		return nil
+	}
+	if all.SqlFunctions, err = rs.s.GetAllFunctions(); err != nil {
+		conf.Log.Errorf("fail to get all functions: %v", err)
+		return nil
+	}
 	rules, err := rs.r.GetAllRulesJson()
 	if err != nil {
 		conf.Log.Errorf("fail to get all rules: %v", err)
@@ -129,6 +145,13 @@ func (rs *RulesetProcessor) Import(content []byte) ([]string, []int, error) {
 			counts[1]++
 		}
 	}
+	// restore SQL functions and views before the rules which refer to them
+	for k, e := range rs.s.ImportFunctions(all.SqlFunctions) {
+		conf.Log.Warnf("Fail to import function %s with error: %v", k, e)
+	}
+	for k, e := range rs.s.ImportViews(all.Views) {
+		conf.Log.Warnf("Fail to import view %s with error: %v", k, e)
+	}
 	var rules []string
 	// restore rules
 	for k, v := range all.Rules {
@@ -145,9 +168,11 @@ func (rs *RulesetProcessor) Import(content []byte) ([]string, []int, error) {
 
 func (rs *RulesetProcessor) ImportRuleSet(all Ruleset) Ruleset {
 	ruleSetRsp := Ruleset{
-		Rules:   map[string]string{},
-		Streams: map[string]string{},
-		Tables:  map[string]string{},
+		Rules:        map[string]string{},
+		Streams:      map[string]string{},
+		Tables:       map[string]string{},
+		Views:        map[string]string{},
+		SqlFunctions: map[string]string{},
 	}
 
 	_ = rs.s.streamStatusDb.Clean()
@@ -177,6 +202,15 @@ func (rs *RulesetProcessor) ImportRuleSet(all Ruleset) Ruleset {
 		}
 		counts[1]++
 	}
+	// restore SQL functions and views before the rules which refer to them
+	for k, e := range rs.s.ImportFunctions(all.SqlFunctions) {
+		conf.Log.Errorf("Fail to import function %s with error: %v", k, e)
+		ruleSetRsp.SqlFunctions[k] = e.Error()
+	}
+	for k, e := range rs.s.ImportViews(all.Views) {
+		conf.Log.Errorf("Fail to import view %s with error: %v", k, e)
+		ruleSetRsp.Views[k] = e.Error()
+	}
 	// restore rules
 	for k, v := range all.Rules {
 		_, e := rs.r.ExecCreateWithValidation(k, v)
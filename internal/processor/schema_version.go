@@ -0,0 +1,299 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+// SchemaVersion is a version of the stream schema. A new version is added when the fields of the stream change.
+type SchemaVersion struct {
+	Version   int    `json:"version"`
+	Statement string `json:"statement"`
+	CreatedAt int64  `json:"createdAt"`
+}
+
+// GetSchemaVersions returns all the schema versions of the stream. The stream created before the versioning has the
+// current definition as version 1.
+func (p *StreamProcessor) GetSchemaVersions(name string, st ast.StreamType) ([]*SchemaVersion, error) {
+	statement, err := p.GetStream(name, st)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := p.loadSchemaVersions(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		versions = []*SchemaVersion{{Version: 1, Statement: statement}}
+	}
+	return versions, nil
+}
+
+// GetSchemaVersion returns the stream definition with the fields of the schema version. The options are always the
+// current ones.
+func (p *StreamProcessor) GetSchemaVersion(name string, version int) (*ast.StreamStmt, error) {
+	stmt, err := p.GetDataSource(name)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := p.GetSchemaVersions(name, stmt.StreamType)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range versions {
+		if v.Version == version {
+			vs, err := parseStreamStmt(v.Statement)
+			if err != nil {
+				return nil, err
+			}
+			newStmt := *stmt
+			newStmt.StreamFields = vs.StreamFields
+			return &newStmt, nil
+		}
+	}
+	return nil, errorx.NewWithCode(errorx.NOT_FOUND, fmt.Sprintf("schema version %d of %s %s is not found", version, ast.StreamTypeMap[stmt.StreamType], name))
+}
+
+// GetStreamProcessorSchemaVersion is a global function that uses the global StreamProcessor instance to retrieve the
+// stream definition of a schema version.
+func GetStreamProcessorSchemaVersion(name string, version int) (*ast.StreamStmt, error) {
+	if globalStreamProcessor == nil {
+		return nil, fmt.Errorf("stream processor not initialized")
+	}
+	return globalStreamProcessor.GetSchemaVersion(name, version)
+}
+
+// SchemaChange is the change of the stream definition to check before replacing
+type SchemaChange struct {
+	// Version is the current schema version and NewVersion is the version the new definition will be saved as. They
+	// are the same if the fields do not change.
+	Version           int
+	NewVersion        int
+	Incompatibilities []string
+}
+
+// GetSchemaChange parses the new definition of the stream and checks the schema compatibility
+func (p *StreamProcessor) GetSchemaChange(name string, statement string, st ast.StreamType) (*SchemaChange, error) {
+	newStmt, err := parseStreamStmt(statement)
+	if err != nil {
+		return nil, err
+	}
+	if newStmt.StreamType != st {
+		return nil, fmt.Errorf("the sql statement must define a %s", ast.StreamTypeMap[st])
+	}
+	if string(newStmt.Name) != name {
+		return nil, fmt.Errorf("the sql statement must update the %s source", name)
+	}
+	versions, err := p.GetSchemaVersions(name, st)
+	if err != nil {
+		return nil, err
+	}
+	oldStmt, err := p.GetDataSource(name)
+	if err != nil {
+		return nil, err
+	}
+	latest := versions[len(versions)-1]
+	c := &SchemaChange{Version: latest.Version, NewVersion: latest.Version}
+	changed, err := schemaChanged(latest, newStmt)
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		c.NewVersion++
+	}
+	c.Incompatibilities = CheckSchemaCompatibility(oldStmt, newStmt)
+	return c, nil
+}
+
+func (p *StreamProcessor) loadSchemaVersions(name string) ([]*SchemaVersion, error) {
+	var v string
+	found, err := p.schemaDb.Get(name, &v)
+	if err != nil || !found {
+		return nil, err
+	}
+	var versions []*SchemaVersion
+	if err := json.Unmarshal(cast.StringToBytes(v), &versions); err != nil {
+		return nil, fmt.Errorf("error when loading schema versions of %s: %v", name, err)
+	}
+	return versions, nil
+}
+
+// newSchemaVersions returns the schema versions to save with the stream statement. It must be called before saving
+// the statement so that the replaced stream created before the versioning is kept as version 1.
+func (p *StreamProcessor) newSchemaVersions(stmt *ast.StreamStmt, statement string, replace bool) ([]*SchemaVersion, error) {
+	now := timex.GetNowInMilli()
+	if !replace {
+		return []*SchemaVersion{{Version: 1, Statement: statement, CreatedAt: now}}, nil
+	}
+	name := string(stmt.Name)
+	versions, err := p.loadSchemaVersions(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		old, err := xsql.GetDataSourceStatement(p.db, name)
+		if err != nil {
+			return []*SchemaVersion{{Version: 1, Statement: statement, CreatedAt: now}}, nil
+		}
+		versions = []*SchemaVersion{{Version: 1, Statement: old.Statement}}
+	}
+	latest := versions[len(versions)-1]
+	changed, err := schemaChanged(latest, stmt)
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		versions = append(versions, &SchemaVersion{Version: latest.Version + 1, Statement: statement, CreatedAt: now})
+	}
+	return versions, nil
+}
+
+func (p *StreamProcessor) saveSchemaVersions(name string, versions []*SchemaVersion) error {
+	b, err := json.Marshal(versions)
+	if err != nil {
+		return fmt.Errorf("error when saving schema versions of %s: %v", name, err)
+	}
+	return p.schemaDb.Set(name, string(b))
+}
+
+func schemaChanged(latest *SchemaVersion, stmt *ast.StreamStmt) (bool, error) {
+	ls, err := parseStreamStmt(latest.Statement)
+	if err != nil {
+		return false, err
+	}
+	if len(ls.StreamFields) == 0 && len(stmt.StreamFields) == 0 {
+		return false, nil
+	}
+	return !reflect.DeepEqual(ls.StreamFields, stmt.StreamFields), nil
+}
+
+func parseStreamStmt(statement string) (*ast.StreamStmt, error) {
+	stmt, err := xsql.Language.Parse(xsql.NewParser(strings.NewReader(statement)))
+	if err != nil {
+		return nil, err
+	}
+	s, ok := stmt.(*ast.StreamStmt)
+	if !ok {
+		return nil, fmt.Errorf("invalid stream statement: %s", statement)
+	}
+	return s, nil
+}
+
+// CheckSchemaCompatibility checks the schema change of the stream by the SCHEMA_COMPATIBILITY option of the new
+// definition which is BACKWARD by default. It returns the incompatible changes. A field missing in the data is read
+// as nil unless the reader validates strictly, so adding or removing a field breaks the compatibility only if the
+// reader schema has STRICT_VALIDATION and the field has no DEFAULT. The changes of the schemaless stream are always
+// compatible.
+func CheckSchemaCompatibility(old, new *ast.StreamStmt) []string {
+	mode := new.Options.SCHEMA_COMPATIBILITY
+	if mode == "" {
+		mode = ast.SchemaCompatibilityBackward
+	}
+	if mode == ast.SchemaCompatibilityNone || len(old.StreamFields) == 0 || len(new.StreamFields) == 0 {
+		return nil
+	}
+	var result []string
+	if mode == ast.SchemaCompatibilityBackward || mode == ast.SchemaCompatibilityFull {
+		c := &compatChecker{direction: "backward", missing: "added", strict: new.Options.STRICT_VALIDATION}
+		c.check("", old.StreamFields, new.StreamFields)
+		result = append(result, c.errs...)
+	}
+	if mode == ast.SchemaCompatibilityForward || mode == ast.SchemaCompatibilityFull {
+		c := &compatChecker{direction: "forward", missing: "removed", strict: old.Options.STRICT_VALIDATION}
+		c.check("", new.StreamFields, old.StreamFields)
+		result = append(result, c.errs...)
+	}
+	return result
+}
+
+// compatChecker checks whether the reader schema can read the data of the writer schema
+type compatChecker struct {
+	direction string
+	// missing is the change of the field in the reader schema but not in the writer schema
+	missing string
+	strict  bool
+	errs    []string
+}
+
+func (c *compatChecker) check(prefix string, writer, reader ast.StreamFields) {
+	for _, rf := range reader {
+		name := prefix + rf.Name
+		var wf *ast.StreamField
+		for i := range writer {
+			if writer[i].Name == rf.Name {
+				wf = &writer[i]
+				break
+			}
+		}
+		if wf == nil {
+			if c.strict && rf.Default == nil {
+				c.errs = append(c.errs, fmt.Sprintf("%s: field %s is %s without default value", c.direction, name, c.missing))
+			}
+			continue
+		}
+		c.checkType(name, wf.FieldType, rf.FieldType)
+	}
+}
+
+func (c *compatChecker) checkType(name string, wt, rt ast.FieldType) {
+	switch r := rt.(type) {
+	case *ast.RecType:
+		if w, ok := wt.(*ast.RecType); ok {
+			c.check(name+".", w.StreamFields, r.StreamFields)
+			return
+		}
+	case *ast.ArrayType:
+		if w, ok := wt.(*ast.ArrayType); ok {
+			we, re := elementType(w), elementType(r)
+			wr, ok1 := we.(*ast.RecType)
+			rr, ok2 := re.(*ast.RecType)
+			if ok1 && ok2 {
+				c.check(name+"[].", wr.StreamFields, rr.StreamFields)
+				return
+			}
+			ec := &compatChecker{}
+			if ec.checkType(name, we, re); len(ec.errs) == 0 {
+				return
+			}
+		}
+	case *ast.BasicType:
+		if w, ok := wt.(*ast.BasicType); ok && canRead(w.Type, r.Type) {
+			return
+		}
+	}
+	c.errs = append(c.errs, fmt.Sprintf("%s: field %s changes type from %s to %s", c.direction, name, printFieldType(wt), printFieldType(rt)))
+}
+
+func elementType(t *ast.ArrayType) ast.FieldType {
+	if t.FieldType != nil {
+		return t.FieldType
+	}
+	return &ast.BasicType{Type: t.Type}
+}
+
+// canRead returns whether the value of the writer type can be converted to the reader type without loss
+func canRead(writer, reader ast.DataType) bool {
+	return writer == reader || (writer == ast.BIGINT && reader == ast.FLOAT)
+}
@@ -0,0 +1,152 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+func TestSchemaVersions(t *testing.T) {
+	p := NewStreamProcessor()
+	p.db.Clean()
+	p.schemaDb.Clean()
+	defer p.db.Clean()
+	defer p.schemaDb.Clean()
+
+	_, err := p.ExecStmt(`CREATE STREAM sv (a bigint, b string) WITH (DATASOURCE="sv")`)
+	require.NoError(t, err)
+	// options change does not add a version
+	_, err = p.ExecReplaceStream("sv", `CREATE STREAM sv (a bigint, b string) WITH (DATASOURCE="sv2")`, ast.TypeStream)
+	require.NoError(t, err)
+	c, err := p.GetSchemaChange("sv", `CREATE STREAM sv (a float, b string) WITH (DATASOURCE="sv2")`, ast.TypeStream)
+	require.NoError(t, err)
+	require.Equal(t, 1, c.Version)
+	require.Equal(t, 2, c.NewVersion)
+	require.Empty(t, c.Incompatibilities)
+	_, err = p.ExecReplaceStream("sv", `CREATE STREAM sv (a float, b string) WITH (DATASOURCE="sv2")`, ast.TypeStream)
+	require.NoError(t, err)
+
+	versions, err := p.GetSchemaVersions("sv", ast.TypeStream)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	require.Equal(t, `CREATE STREAM sv (a bigint, b string) WITH (DATASOURCE="sv")`, versions[0].Statement)
+	require.Equal(t, 2, versions[1].Version)
+
+	// the pinned version has the fields of the version and the current options
+	stmt, err := p.GetSchemaVersion("sv", 1)
+	require.NoError(t, err)
+	require.Equal(t, ast.StreamFields{
+		{Name: "a", FieldType: &ast.BasicType{Type: ast.BIGINT}},
+		{Name: "b", FieldType: &ast.BasicType{Type: ast.STRINGS}},
+	}, stmt.StreamFields)
+	require.Equal(t, "sv2", stmt.Options.DATASOURCE)
+	_, err = p.GetSchemaVersion("sv", 3)
+	require.EqualError(t, err, "schema version 3 of stream sv is not found")
+
+	_, err = p.GetSchemaChange("sv", `CREATE STREAM sv2 (a float) WITH (DATASOURCE="sv2")`, ast.TypeStream)
+	require.EqualError(t, err, "the sql statement must update the sv source")
+
+	// the stream created before the versioning is version 1
+	_, err = p.DropStream("sv", ast.TypeStream)
+	require.NoError(t, err)
+	_, err = p.ExecStmt(`CREATE STREAM sv (a bigint) WITH (DATASOURCE="sv")`)
+	require.NoError(t, err)
+	require.NoError(t, p.schemaDb.Delete("sv"))
+	versions, err = p.GetSchemaVersions("sv", ast.TypeStream)
+	require.NoError(t, err)
+	require.Equal(t, []*SchemaVersion{{Version: 1, Statement: `CREATE STREAM sv (a bigint) WITH (DATASOURCE="sv")`}}, versions)
+	_, err = p.ExecReplaceStream("sv", `CREATE STREAM sv (a bigint, b bigint) WITH (DATASOURCE="sv")`, ast.TypeStream)
+	require.NoError(t, err)
+	versions, err = p.GetSchemaVersions("sv", ast.TypeStream)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	require.Equal(t, `CREATE STREAM sv (a bigint) WITH (DATASOURCE="sv")`, versions[0].Statement)
+}
+
+func TestCheckSchemaCompatibility(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		errs []string
+	}{
+		{
+			name: "add and remove",
+			old:  `CREATE STREAM s (a bigint, b string) WITH (DATASOURCE="s")`,
+			new:  `CREATE STREAM s (a bigint, c string) WITH (DATASOURCE="s", SCHEMA_COMPATIBILITY="FULL")`,
+		},
+		{
+			name: "widen",
+			old:  `CREATE STREAM s (a bigint, b array(bigint)) WITH (DATASOURCE="s")`,
+			new:  `CREATE STREAM s (a float, b array(float)) WITH (DATASOURCE="s")`,
+		},
+		{
+			name: "widen forward",
+			old:  `CREATE STREAM s (a bigint, b array(bigint)) WITH (DATASOURCE="s")`,
+			new:  `CREATE STREAM s (a float, b array(float)) WITH (DATASOURCE="s", SCHEMA_COMPATIBILITY="FORWARD")`,
+			errs: []string{
+				"forward: field a changes type from float to bigint",
+				"forward: field b changes type from array(float) to array(bigint)",
+			},
+		},
+		{
+			name: "none",
+			old:  `CREATE STREAM s (a bigint) WITH (DATASOURCE="s")`,
+			new:  `CREATE STREAM s (a string) WITH (DATASOURCE="s", SCHEMA_COMPATIBILITY="NONE")`,
+		},
+		{
+			name: "schemaless",
+			old:  `CREATE STREAM s () WITH (DATASOURCE="s")`,
+			new:  `CREATE STREAM s (a string) WITH (DATASOURCE="s")`,
+		},
+		{
+			name: "strict",
+			old:  `CREATE STREAM s (a bigint, b string) WITH (DATASOURCE="s", STRICT_VALIDATION="true")`,
+			new:  `CREATE STREAM s (a bigint, c string, d bigint DEFAULT 1) WITH (DATASOURCE="s", STRICT_VALIDATION="true", SCHEMA_COMPATIBILITY="FULL")`,
+			errs: []string{
+				"backward: field c is added without default value",
+				"forward: field b is removed without default value",
+			},
+		},
+		{
+			name: "nested",
+			old:  `CREATE STREAM s (a struct(x bigint, y string), b array(struct(z bigint))) WITH (DATASOURCE="s")`,
+			new:  `CREATE STREAM s (a struct(x boolean, y string), b array(struct(z string)), c bigint) WITH (DATASOURCE="s")`,
+			errs: []string{
+				"backward: field a.x changes type from bigint to boolean",
+				"backward: field b[].z changes type from bigint to string",
+			},
+		},
+		{
+			name: "kind",
+			old:  `CREATE STREAM s (a struct(x bigint)) WITH (DATASOURCE="s")`,
+			new:  `CREATE STREAM s (a array(bigint)) WITH (DATASOURCE="s")`,
+			errs: []string{"backward: field a changes type from struct(x bigint) to array(bigint)"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old, err := parseStreamStmt(tt.old)
+			require.NoError(t, err)
+			n, err := parseStreamStmt(tt.new)
+			require.NoError(t, err)
+			require.Equal(t, tt.errs, CheckSchemaCompatibility(old, n))
+		})
+	}
+}
@@ -44,6 +44,12 @@ type StreamProcessor struct {
 	streamStatusDb kv.KeyValue
 	tableStatusDb  kv.KeyValue
 	tempDb         kv.KeyValue
+	viewDb         kv.KeyValue
+	funcDb         kv.KeyValue
+	schemaDb       kv.KeyValue
+	// ruleRefersView and ruleRefersFunction are set by the rule manager to check the rules before dropping
+	ruleRefersView     func(name string) bool
+	ruleRefersFunction func(name string) bool
 }
 
 type StreamDetail struct {
@@ -68,11 +74,26 @@ func NewStreamProcessor() *StreamProcessor {
 	if err != nil {
 		panic(fmt.Sprintf("Can not initialize store for the stream processor at path 'stream': %v", err))
 	}
+	viewDb, err := store.GetKV("view")
+	if err != nil {
+		panic(fmt.Sprintf("Can not initialize store for the stream processor at path 'view': %v", err))
+	}
+	funcDb, err := store.GetKV("sqlfunc")
+	if err != nil {
+		panic(fmt.Sprintf("Can not initialize store for the stream processor at path 'sqlfunc': %v", err))
+	}
+	schemaDb, err := store.GetKV("streamSchema")
+	if err != nil {
+		panic(fmt.Sprintf("Can not initialize store for the stream processor at path 'streamSchema': %v", err))
+	}
 	processor := &StreamProcessor{
 		db:             db,
 		streamStatusDb: streamDb,
 		tableStatusDb:  tableDb,
 		tempDb:         memory.NewMemoryKV(),
+		viewDb:         viewDb,
+		funcDb:         funcDb,
+		schemaDb:       schemaDb,
 	}
 	globalStreamProcessor = processor
 	return processor
@@ -108,7 +129,7 @@ func (p *StreamProcessor) ExecStmt(statement string) (result []string, err error
 		}
 	}()
 
-	parser := xsql.NewParser(strings.NewReader(statement))
+	parser := xsql.NewParserWithSqlFunctions(strings.NewReader(statement), p.LookupFunction)
 	stmt, err := xsql.Language.Parse(parser)
 	if err != nil {
 		return nil, err
@@ -119,8 +140,12 @@ func (p *StreamProcessor) ExecStmt(statement string) (result []string, err error
 			return nil, err
 		}
 		var r string
-		err = p.execSave(s, statement, false)
 		stt := ast.StreamTypeMap[s.StreamType]
+		if v, _ := p.LookupView(string(s.Name)); v != nil {
+			err = fmt.Errorf("%s is already defined as a view", s.Name)
+		} else {
+			err = p.execSave(s, statement, false)
+		}
 		if err != nil {
 			err = fmt.Errorf("Create %s fails: %v.", stt, err)
 		} else {
@@ -156,6 +181,9 @@ func (p *StreamProcessor) ExecStmt(statement string) (result []string, err error
 		var r string
 		r, err = p.execDrop(s, ast.TypeTable)
 		result = append(result, r)
+	case *ast.ViewStmt, *ast.ShowViewsStatement, *ast.DescribeViewStatement, *ast.DropViewStatement,
+		*ast.FunctionStmt, *ast.ShowFunctionsStatement, *ast.DescribeFunctionStatement, *ast.DropFunctionStatement:
+		result, err = p.execView(s, statement)
 	default:
 		return nil, fmt.Errorf("Invalid stream statement: %s", statement)
 	}
@@ -215,20 +243,25 @@ func (p *StreamProcessor) execSave(stmt *ast.StreamStmt, statement string, repla
 	if err != nil {
 		return fmt.Errorf("error when saving to db: %v.", err)
 	}
-	if !stmt.Options.Temp {
+	if stmt.Options.Temp {
 		if replace {
-			err = p.db.Set(string(stmt.Name), string(s))
-		} else {
-			err = p.db.Setnx(string(stmt.Name), string(s))
+			return p.tempDb.Set(string(stmt.Name), string(s))
 		}
+		return p.tempDb.Setnx(string(stmt.Name), string(s))
+	}
+	versions, err := p.newSchemaVersions(stmt, statement, replace)
+	if err != nil {
+		return err
+	}
+	if replace {
+		err = p.db.Set(string(stmt.Name), string(s))
 	} else {
-		if replace {
-			err = p.tempDb.Set(string(stmt.Name), string(s))
-		} else {
-			err = p.tempDb.Setnx(string(stmt.Name), string(s))
-		}
+		err = p.db.Setnx(string(stmt.Name), string(s))
+	}
+	if err != nil {
+		return err
 	}
-	return err
+	return p.saveSchemaVersions(string(stmt.Name), versions)
 }
 
 func (p *StreamProcessor) ExecReplaceStream(name string, statement string, st ast.StreamType) (info string, err error) {
@@ -508,6 +541,9 @@ func printOptions(opts *ast.Options, buff *bytes.Buffer) {
 	if opts.VERSION != "" {
 		buff.WriteString(fmt.Sprintf("VERSION: %s\n", opts.VERSION))
 	}
+	if opts.SCHEMA_COMPATIBILITY != "" {
+		buff.WriteString(fmt.Sprintf("SCHEMA_COMPATIBILITY: %s\n", opts.SCHEMA_COMPATIBILITY))
+	}
 }
 
 func (p *StreamProcessor) DescStream(name string, st ast.StreamType) (r ast.Statement, err error) {
@@ -603,6 +639,9 @@ func (p *StreamProcessor) execExplain(stmt ast.NameNode, st ast.StreamType) (str
 }
 
 func (p *StreamProcessor) execDrop(stmt ast.NameNode, st ast.StreamType) (string, error) {
+	if err := p.CheckStreamBeforeDrop(stmt.GetName()); err != nil {
+		return "", fmt.Errorf("Drop %s fails: %s.", ast.StreamTypeMap[st], err)
+	}
 	s, err := p.DropStream(stmt.GetName(), st)
 	if err != nil {
 		return s, fmt.Errorf("Drop %s fails: %s.", ast.StreamTypeMap[st], err)
@@ -644,6 +683,7 @@ func (p *StreamProcessor) DropStream(name string, st ast.StreamType) (r string,
 		if err != nil {
 			return "", err
 		}
+		_ = p.schemaDb.Delete(name)
 	}
 	streamSchema.RemoveStreamSchema(name)
 	return fmt.Sprintf("%s %s is dropped.", cases.Title(language.Und).String(ast.StreamTypeMap[st]), name), nil
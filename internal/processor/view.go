@@ -0,0 +1,470 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/validate"
+)
+
+// LookupView returns the view definition by name. It returns nil without error if the view does not exist.
+func (p *StreamProcessor) LookupView(name string) (*ast.ViewStmt, error) {
+	var statement string
+	if ok, _ := p.viewDb.Get(name, &statement); !ok {
+		return nil, nil
+	}
+	stmt, err := xsql.Language.Parse(xsql.NewParserWithSqlFunctions(strings.NewReader(statement), p.LookupFunction))
+	if err != nil {
+		return nil, fmt.Errorf("fail to parse view %s: %v", name, err)
+	}
+	v, ok := stmt.(*ast.ViewStmt)
+	if !ok {
+		return nil, fmt.Errorf("Error resolving the view %s, the data in db may be corrupted.", name)
+	}
+	return v, nil
+}
+
+// GetStreamProcessorStatement parses the select statement which may call the SQL functions of the global StreamProcessor
+func GetStreamProcessorStatement(sql string) (*ast.SelectStatement, error) {
+	var getFunc xsql.SqlFuncGetter
+	if globalStreamProcessor != nil {
+		getFunc = globalStreamProcessor.LookupFunction
+	}
+	return xsql.GetStatementWithSqlFunctions(sql, getFunc)
+}
+
+// ExpandStreamProcessorStatement expands the views and SQL functions in the select statement with the global StreamProcessor
+func ExpandStreamProcessorStatement(stmt *ast.SelectStatement) (*xsql.Expansion, error) {
+	if globalStreamProcessor == nil {
+		return xsql.ExpandStatement(stmt, func(string) (*ast.ViewStmt, error) { return nil, nil }, nil)
+	}
+	return xsql.ExpandStatement(stmt, globalStreamProcessor.LookupView, globalStreamProcessor.LookupFunction)
+}
+
+// ExpandStreamProcessorStatementWithSharedViews expands the select statement with the global StreamProcessor and
+// keeps its only view source as a derived table whose computation is shared among the rules
+func ExpandStreamProcessorStatementWithSharedViews(stmt *ast.SelectStatement) (*xsql.Expansion, error) {
+	if globalStreamProcessor == nil {
+		return ExpandStreamProcessorStatement(stmt)
+	}
+	return xsql.ExpandStatementWithSharedViews(stmt, globalStreamProcessor.LookupView, globalStreamProcessor.LookupFunction)
+}
+
+func (p *StreamProcessor) saveView(stmt *ast.ViewStmt, statement string, replace bool) error {
+	if err := validate.ValidateID(stmt.Name); err != nil {
+		return err
+	}
+	if _, err := p.GetDataSource(stmt.Name); err == nil {
+		return fmt.Errorf("%s is already defined as a stream or table", stmt.Name)
+	}
+	// Validate the view with its sources by expanding it
+	if _, err := xsql.ExpandStatement(stmt.Select, func(name string) (*ast.ViewStmt, error) {
+		if name == stmt.Name {
+			return nil, fmt.Errorf("view %s cannot refer to itself", name)
+		}
+		return p.LookupView(name)
+	}, p.LookupFunction); err != nil {
+		return err
+	}
+	if err := xsql.ValidateView(stmt); err != nil {
+		return err
+	}
+	source := stmt.Select.Sources[0].(*ast.Table).Name
+	if _, err := p.GetDataSource(source); err != nil {
+		return fmt.Errorf("the source %s of view %s is not found", source, stmt.Name)
+	}
+	if replace {
+		return p.viewDb.Set(stmt.Name, statement)
+	}
+	return p.viewDb.Setnx(stmt.Name, statement)
+}
+
+func (p *StreamProcessor) ExecReplaceView(name string, statement string) (info string, err error) {
+	defer func() {
+		if err != nil {
+			if _, ok := err.(errorx.ErrorWithCode); !ok {
+				err = errorx.NewWithCode(errorx.StreamTableError, err.Error())
+			}
+		}
+	}()
+	stmt, err := xsql.Language.Parse(xsql.NewParserWithSqlFunctions(strings.NewReader(statement), p.LookupFunction))
+	if err != nil {
+		return "", err
+	}
+	s, ok := stmt.(*ast.ViewStmt)
+	if !ok {
+		return "", fmt.Errorf("Invalid view statement: %s", statement)
+	}
+	if s.Name != name {
+		return "", fmt.Errorf("Replace %s fails: the sql statement must update the %s view.", name, name)
+	}
+	if err := p.saveView(s, statement, true); err != nil {
+		return "", fmt.Errorf("Replace view fails: %v.", err)
+	}
+	info = fmt.Sprintf("View %s is replaced.", name)
+	log.Printf("%s", info)
+	return info, nil
+}
+
+func (p *StreamProcessor) ShowViews() ([]string, error) {
+	keys, err := p.viewDb.Keys()
+	if err != nil {
+		return nil, errorx.NewWithCode(errorx.StreamTableError, fmt.Sprintf("Show views fails, error when loading data from db: %v.", err))
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (p *StreamProcessor) GetView(name string) (string, error) {
+	var statement string
+	if ok, _ := p.viewDb.Get(name, &statement); !ok {
+		return "", errorx.NewWithCode(errorx.NOT_FOUND, fmt.Sprintf("view %s is not found", name))
+	}
+	return statement, nil
+}
+
+// SetRuleRefCheckers sets the functions to check if any rule refers to the view or the SQL function
+func (p *StreamProcessor) SetRuleRefCheckers(refersView func(name string) bool, refersFunction func(name string) bool) {
+	p.ruleRefersView = refersView
+	p.ruleRefersFunction = refersFunction
+}
+
+// viewsSelectFrom returns the views which select from the stream or view
+func (p *StreamProcessor) viewsSelectFrom(name string) []string {
+	keys, _ := p.viewDb.Keys()
+	var result []string
+	for _, k := range keys {
+		v, err := p.LookupView(k)
+		if err != nil || v == nil {
+			continue
+		}
+		if t, ok := v.Select.Sources[0].(*ast.Table); ok && t.Name == name {
+			result = append(result, k)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// CheckStreamBeforeDrop returns error if any view selects from the stream. The rules are checked by the rule manager.
+func (p *StreamProcessor) CheckStreamBeforeDrop(name string) error {
+	if views := p.viewsSelectFrom(name); len(views) > 0 {
+		return fmt.Errorf("%s has been referenced by views %s", name, strings.Join(views, ","))
+	}
+	return nil
+}
+
+// CheckViewBeforeDrop returns error if any rule or other view selects from the view
+func (p *StreamProcessor) CheckViewBeforeDrop(name string) error {
+	if p.ruleRefersView != nil && p.ruleRefersView(name) {
+		return fmt.Errorf("view %s has been referenced by other rules", name)
+	}
+	if views := p.viewsSelectFrom(name); len(views) > 0 {
+		return fmt.Errorf("view %s has been referenced by views %s", name, strings.Join(views, ","))
+	}
+	return nil
+}
+
+// CheckFunctionBeforeDrop returns error if any rule, view or other SQL function calls the SQL function
+func (p *StreamProcessor) CheckFunctionBeforeDrop(name string) error {
+	if p.ruleRefersFunction != nil && p.ruleRefersFunction(name) {
+		return fmt.Errorf("function %s has been referenced by other rules", name)
+	}
+	calls := func(node ast.Node) bool {
+		found := false
+		ast.WalkFunc(node, func(n ast.Node) bool {
+			if c, ok := n.(*ast.Call); ok && strings.EqualFold(c.Name, name) {
+				found = true
+			}
+			return !found
+		})
+		return found
+	}
+	var refs []string
+	keys, _ := p.viewDb.Keys()
+	for _, k := range keys {
+		if v, err := p.LookupView(k); err == nil && v != nil && calls(v.Select) {
+			refs = append(refs, "view "+k)
+		}
+	}
+	keys, _ = p.funcDb.Keys()
+	for _, k := range keys {
+		if strings.EqualFold(k, name) {
+			continue
+		}
+		statement, _ := p.LookupFunction(k)
+		stmt, err := xsql.Language.Parse(xsql.NewParserWithSqlFunctions(strings.NewReader(statement), p.LookupFunction))
+		if err != nil {
+			continue
+		}
+		if f, ok := stmt.(*ast.FunctionStmt); ok && calls(f.Body) {
+			refs = append(refs, "function "+k)
+		}
+	}
+	if len(refs) > 0 {
+		sort.Strings(refs)
+		return fmt.Errorf("function %s has been referenced by %s", name, strings.Join(refs, ","))
+	}
+	return nil
+}
+
+func (p *StreamProcessor) DropView(name string) (string, error) {
+	if _, err := p.GetView(name); err != nil {
+		return "", err
+	}
+	if err := p.viewDb.Delete(name); err != nil {
+		return "", errorx.NewWithCode(errorx.StreamTableError, err.Error())
+	}
+	return fmt.Sprintf("View %s is dropped.", name), nil
+}
+
+// GetAllViews return all views defined to export.
+func (p *StreamProcessor) GetAllViews() (map[string]string, error) {
+	return p.viewDb.All()
+}
+
+// LookupFunction returns the statement of the SQL function by case-insensitive name.
+// The definitions are always read from the db so that all the processors see the same functions.
+func (p *StreamProcessor) LookupFunction(name string) (string, bool) {
+	var statement string
+	if ok, _ := p.funcDb.Get(name, &statement); ok {
+		return statement, true
+	}
+	keys, err := p.funcDb.Keys()
+	if err != nil {
+		return "", false
+	}
+	for _, k := range keys {
+		if strings.EqualFold(k, name) {
+			if ok, _ := p.funcDb.Get(k, &statement); ok {
+				return statement, true
+			}
+		}
+	}
+	return "", false
+}
+
+func (p *StreamProcessor) saveFunction(stmt *ast.FunctionStmt, statement string, replace bool) error {
+	if err := validate.ValidateID(stmt.Name); err != nil {
+		return err
+	}
+	var err error
+	if replace {
+		err = p.funcDb.Set(stmt.Name, statement)
+	} else {
+		err = p.funcDb.Setnx(stmt.Name, statement)
+	}
+	return err
+}
+
+func (p *StreamProcessor) ExecReplaceFunction(name string, statement string) (info string, err error) {
+	defer func() {
+		if err != nil {
+			if _, ok := err.(errorx.ErrorWithCode); !ok {
+				err = errorx.NewWithCode(errorx.StreamTableError, err.Error())
+			}
+		}
+	}()
+	stmt, err := xsql.Language.Parse(xsql.NewParserWithSqlFunctions(strings.NewReader(statement), p.LookupFunction))
+	if err != nil {
+		return "", err
+	}
+	s, ok := stmt.(*ast.FunctionStmt)
+	if !ok {
+		return "", fmt.Errorf("Invalid function statement: %s", statement)
+	}
+	if s.Name != name {
+		return "", fmt.Errorf("Replace %s fails: the sql statement must update the %s function.", name, name)
+	}
+	if err := p.saveFunction(s, statement, true); err != nil {
+		return "", fmt.Errorf("Replace function fails: %v.", err)
+	}
+	info = fmt.Sprintf("Function %s is replaced.", name)
+	log.Printf("%s", info)
+	return info, nil
+}
+
+func (p *StreamProcessor) ShowFunctions() ([]string, error) {
+	keys, err := p.funcDb.Keys()
+	if err != nil {
+		return nil, errorx.NewWithCode(errorx.StreamTableError, fmt.Sprintf("Show functions fails, error when loading data from db: %v.", err))
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (p *StreamProcessor) GetFunction(name string) (string, error) {
+	var statement string
+	if ok, _ := p.funcDb.Get(name, &statement); !ok {
+		return "", errorx.NewWithCode(errorx.NOT_FOUND, fmt.Sprintf("function %s is not found", name))
+	}
+	return statement, nil
+}
+
+func (p *StreamProcessor) DropFunction(name string) (string, error) {
+	if _, err := p.GetFunction(name); err != nil {
+		return "", err
+	}
+	if err := p.funcDb.Delete(name); err != nil {
+		return "", errorx.NewWithCode(errorx.StreamTableError, err.Error())
+	}
+	return fmt.Sprintf("Function %s is dropped.", name), nil
+}
+
+// GetAllFunctions return all SQL functions defined to export.
+func (p *StreamProcessor) GetAllFunctions() (map[string]string, error) {
+	return p.funcDb.All()
+}
+
+// ImportFunctions replaces the SQL functions and returns the errors of the failed ones.
+// The functions may call each other, so the parsing is retried until no more function can be accepted.
+func (p *StreamProcessor) ImportFunctions(defs map[string]string) map[string]error {
+	pending := make(map[string]string, len(defs))
+	for k, v := range defs {
+		pending[k] = v
+	}
+	accepted := make(map[string]string, len(defs))
+	getFunc := func(name string) (string, bool) {
+		for k, v := range accepted {
+			if strings.EqualFold(k, name) {
+				return v, true
+			}
+		}
+		return p.LookupFunction(name)
+	}
+	errs := make(map[string]error)
+	for len(pending) > 0 {
+		progress := false
+		for k, v := range pending {
+			stmt, err := xsql.Language.Parse(xsql.NewParserWithSqlFunctions(strings.NewReader(v), getFunc))
+			if err != nil {
+				errs[k] = err
+				continue
+			}
+			if s, ok := stmt.(*ast.FunctionStmt); !ok || s.Name != k {
+				errs[k] = fmt.Errorf("Error resolving the function %s, the data in db may be corrupted.", k)
+			} else {
+				accepted[k] = v
+				delete(errs, k)
+				progress = true
+			}
+			delete(pending, k)
+		}
+		if !progress {
+			break
+		}
+	}
+	for k, v := range accepted {
+		if err := p.funcDb.Set(k, v); err != nil {
+			errs[k] = err
+		}
+	}
+	return errs
+}
+
+// ImportViews replaces the views and returns the errors of the failed ones.
+// The views may select from each other, so the import is retried until no more view can be saved.
+func (p *StreamProcessor) ImportViews(defs map[string]string) map[string]error {
+	pending := make(map[string]string, len(defs))
+	for k, v := range defs {
+		pending[k] = v
+	}
+	errs := make(map[string]error)
+	for len(pending) > 0 {
+		progress := false
+		for k, v := range pending {
+			if _, err := p.ExecReplaceView(k, v); err != nil {
+				errs[k] = err
+				continue
+			}
+			delete(pending, k)
+			delete(errs, k)
+			progress = true
+		}
+		if !progress {
+			break
+		}
+	}
+	return errs
+}
+
+func (p *StreamProcessor) execView(stmt ast.Statement, statement string) ([]string, error) {
+	switch s := stmt.(type) {
+	case *ast.ViewStmt:
+		if err := p.saveView(s, statement, false); err != nil {
+			return nil, fmt.Errorf("Create view fails: %v.", err)
+		}
+		r := fmt.Sprintf("View %s is created.", s.Name)
+		log.Printf("%s", r)
+		return []string{r}, nil
+	case *ast.ShowViewsStatement:
+		keys, err := p.ShowViews()
+		if err == nil && len(keys) == 0 {
+			keys = append(keys, "No view definitions are found.")
+		}
+		return keys, err
+	case *ast.DescribeViewStatement:
+		r, err := p.GetView(s.Name)
+		if err != nil {
+			return nil, fmt.Errorf("Describe view fails, %s.", err)
+		}
+		return []string{r}, nil
+	case *ast.DropViewStatement:
+		if err := p.CheckViewBeforeDrop(s.Name); err != nil {
+			return nil, fmt.Errorf("Drop view fails: %s.", err)
+		}
+		r, err := p.DropView(s.Name)
+		if err != nil {
+			return nil, fmt.Errorf("Drop view fails: %s.", err)
+		}
+		return []string{r}, nil
+	case *ast.FunctionStmt:
+		if err := p.saveFunction(s, statement, false); err != nil {
+			return nil, fmt.Errorf("Create function fails: %v.", err)
+		}
+		r := fmt.Sprintf("Function %s is created.", s.Name)
+		log.Printf("%s", r)
+		return []string{r}, nil
+	case *ast.ShowFunctionsStatement:
+		keys, err := p.ShowFunctions()
+		if err == nil && len(keys) == 0 {
+			keys = append(keys, "No function definitions are found.")
+		}
+		return keys, err
+	case *ast.DescribeFunctionStatement:
+		r, err := p.GetFunction(s.Name)
+		if err != nil {
+			return nil, fmt.Errorf("Describe function fails, %s.", err)
+		}
+		return []string{r}, nil
+	case *ast.DropFunctionStatement:
+		if err := p.CheckFunctionBeforeDrop(s.Name); err != nil {
+			return nil, fmt.Errorf("Drop function fails: %s.", err)
+		}
+		r, err := p.DropFunction(s.Name)
+		if err != nil {
+			return nil, fmt.Errorf("Drop function fails: %s.", err)
+		}
+		return []string{r}, nil
+	default:
+		return nil, fmt.Errorf("Invalid stream statement: %s", statement)
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+func TestViewAndFunction(t *testing.T) {
+	p := NewStreamProcessor()
+	p.db.Clean()
+	p.viewDb.Clean()
+	p.funcDb.Clean()
+	defer func() {
+		p.db.Clean()
+		p.viewDb.Clean()
+		p.funcDb.Clean()
+	}()
+
+	tests := []struct {
+		sql string
+		r   []string
+		err string
+	}{
+		{
+			sql: `CREATE VIEW v1 AS SELECT temperature FROM demo WHERE humidity > 10`,
+			err: "Create view fails: the source demo of view v1 is not found.",
+		},
+		{
+			sql: `CREATE STREAM demo (temperature FLOAT, humidity BIGINT) WITH (DATASOURCE="demo", FORMAT="JSON")`,
+			r:   []string{"Stream demo is created."},
+		},
+		{
+			sql: `CREATE VIEW v1 AS SELECT temperature FROM demo WHERE humidity > 10`,
+			r:   []string{"View v1 is created."},
+		},
+		{
+			sql: `CREATE VIEW v1 AS SELECT humidity FROM demo`,
+			err: "Create view fails: Item v1 already exists.",
+		},
+		{
+			sql: `CREATE VIEW v2 AS SELECT t FROM v1`,
+			err: "Create view fails: column t is not found in view v1.",
+		},
+		{
+			sql: `CREATE VIEW v2 AS SELECT temperature AS t FROM v1`,
+			r:   []string{"View v2 is created."},
+		},
+		{
+			sql: `CREATE VIEW demo AS SELECT temperature FROM v1`,
+			err: "Create view fails: demo is already defined as a stream or table.",
+		},
+		{
+			sql: `CREATE STREAM v1 () WITH (DATASOURCE="demo", FORMAT="JSON")`,
+			err: "Create stream fails: v1 is already defined as a view.",
+		},
+		{
+			sql: `SHOW VIEWS`,
+			r:   []string{"v1", "v2"},
+		},
+		{
+			sql: `DESCRIBE VIEW v1`,
+			r:   []string{`CREATE VIEW v1 AS SELECT temperature FROM demo WHERE humidity > 10`},
+		},
+		{
+			sql: `CREATE FUNCTION c2f(c) AS c * 1.8 + 32`,
+			r:   []string{"Function c2f is created."},
+		},
+		{
+			sql: `CREATE FUNCTION c2f(c) AS c * 2`,
+			err: "Create function fails: Item c2f already exists.",
+		},
+		{
+			sql: `SHOW FUNCTIONS`,
+			r:   []string{"c2f"},
+		},
+		{
+			sql: `DROP VIEW v3`,
+			err: "Drop view fails: view v3 is not found.",
+		},
+		{
+			sql: `DROP VIEW v1`,
+			err: "Drop view fails: view v1 has been referenced by views v2.",
+		},
+		{
+			sql: `DROP STREAM demo`,
+			err: "Drop stream fails: demo has been referenced by views v1.",
+		},
+		{
+			sql: `DROP VIEW v2`,
+			r:   []string{"View v2 is dropped."},
+		},
+		{
+			sql: `CREATE FUNCTION c2k(c) AS (c2f(c) - 32) / 1.8 + 273.15`,
+			r:   []string{"Function c2k is created."},
+		},
+		{
+			sql: `DROP FUNCTION c2f`,
+			err: "Drop function fails: function c2f has been referenced by function c2k.",
+		},
+		{
+			sql: `DROP FUNCTION c2k`,
+			r:   []string{"Function c2k is dropped."},
+		},
+		{
+			sql: `CREATE VIEW v3 AS SELECT C2F(temperature) AS f FROM demo`,
+			r:   []string{"View v3 is created."},
+		},
+		{
+			sql: `DROP FUNCTION c2f`,
+			err: "Drop function fails: function c2f has been referenced by view v3.",
+		},
+		{
+			sql: `DROP VIEW v3`,
+			r:   []string{"View v3 is dropped."},
+		},
+		{
+			sql: `DROP FUNCTION c2f`,
+			r:   []string{"Function c2f is dropped."},
+		},
+		{
+			sql: `SHOW FUNCTIONS`,
+			r:   []string{"No function definitions are found."},
+		},
+	}
+	for _, tt := range tests {
+		r, err := p.ExecStmt(tt.sql)
+		if tt.err != "" {
+			require.EqualError(t, err, tt.err, tt.sql)
+			continue
+		}
+		require.NoError(t, err, tt.sql)
+		require.Equal(t, tt.r, r, tt.sql)
+	}
+	_, ok := p.LookupFunction("c2f")
+	require.False(t, ok)
+
+	v, err := p.LookupView("v1")
+	require.NoError(t, err)
+	require.Equal(t, "v1", v.Name)
+	v, err = p.LookupView("demo")
+	require.NoError(t, err)
+	require.Nil(t, v)
+
+	stmt, err := GetStreamProcessorStatement(`SELECT temperature FROM v1`)
+	require.NoError(t, err)
+	exp, err := ExpandStreamProcessorStatement(stmt)
+	require.NoError(t, err)
+	require.Equal(t, []string{"v1"}, exp.Views)
+	require.Equal(t, []string{"demo"}, xsql.GetStreams(stmt))
+	require.Equal(t, &ast.FieldRef{StreamName: "demo", Name: "temperature"}, stmt.Fields[0].Expr)
+}
+
+func TestImportFunctions(t *testing.T) {
+	p := NewStreamProcessor()
+	p.funcDb.Clean()
+	defer p.funcDb.Clean()
+
+	errs := p.ImportFunctions(map[string]string{
+		"f2": `CREATE FUNCTION f2(a) AS f1(a) + 1`,
+		"f1": `CREATE FUNCTION f1(a) AS a * 2`,
+		"f3": `CREATE FUNCTION f3(a) AS nonexist(a)`,
+	})
+	require.Len(t, errs, 1)
+	require.EqualError(t, errs["f3"], "function nonexist not found")
+	_, ok := p.LookupFunction("F1")
+	require.True(t, ok)
+	_, ok = p.LookupFunction("f2")
+	require.True(t, ok)
+	all, err := p.GetAllFunctions()
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	_, _ = p.DropFunction("f1")
+	_, _ = p.DropFunction("f2")
+}
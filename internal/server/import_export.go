@@ -60,6 +60,8 @@ const ProcessErr = "process error"
 type Configuration struct {
 	Streams          map[string]string `json:"streams"`
 	Tables           map[string]string `json:"tables"`
+	Views            map[string]string `json:"views"`
+	SqlFunctions     map[string]string `json:"sqlFunctions"`
 	Rules            map[string]string `json:"rules"`
 	NativePlugins    map[string]string `json:"nativePlugins"`
 	PortablePlugins  map[string]string `json:"portablePlugins"`
@@ -76,6 +78,8 @@ func configurationExport() ([]byte, error) {
 	conf := &Configuration{
 		Streams:          make(map[string]string),
 		Tables:           make(map[string]string),
+		Views:            make(map[string]string),
+		SqlFunctions:     make(map[string]string),
 		Rules:            make(map[string]string),
 		NativePlugins:    make(map[string]string),
 		PortablePlugins:  make(map[string]string),
@@ -91,6 +95,8 @@ func configurationExport() ([]byte, error) {
 	if ruleSet != nil {
 		conf.Streams = ruleSet.Streams
 		conf.Tables = ruleSet.Tables
+		conf.Views = ruleSet.Views
+		conf.SqlFunctions = ruleSet.SqlFunctions
 		conf.Rules = ruleSet.Rules
 	}
 
@@ -155,6 +161,8 @@ func configurationImport(ctx context.Context, data []byte, reboot bool) ImportCo
 	conf := &Configuration{
 		Streams:          make(map[string]string),
 		Tables:           make(map[string]string),
+		Views:            make(map[string]string),
+		SqlFunctions:     make(map[string]string),
 		Rules:            make(map[string]string),
 		NativePlugins:    make(map[string]string),
 		PortablePlugins:  make(map[string]string),
@@ -172,6 +180,8 @@ func configurationImport(ctx context.Context, data []byte, reboot bool) ImportCo
 	configResponse := Configuration{
 		Streams:          make(map[string]string),
 		Tables:           make(map[string]string),
+		Views:            make(map[string]string),
+		SqlFunctions:     make(map[string]string),
 		Rules:            make(map[string]string),
 		NativePlugins:    make(map[string]string),
 		PortablePlugins:  make(map[string]string),
@@ -187,6 +197,8 @@ func configurationImport(ctx context.Context, data []byte, reboot bool) ImportCo
 	ResponseNil := Configuration{
 		Streams:          make(map[string]string),
 		Tables:           make(map[string]string),
+		Views:            make(map[string]string),
+		SqlFunctions:     make(map[string]string),
 		Rules:            make(map[string]string),
 		NativePlugins:    make(map[string]string),
 		PortablePlugins:  make(map[string]string),
@@ -244,14 +256,18 @@ func configurationImport(ctx context.Context, data []byte, reboot bool) ImportCo
 	configResponse.ConnectionConfig = confRsp.Connections
 
 	ruleSet := processor.Ruleset{
-		Streams: conf.Streams,
-		Tables:  conf.Tables,
-		Rules:   conf.Rules,
+		Streams:      conf.Streams,
+		Tables:       conf.Tables,
+		Views:        conf.Views,
+		SqlFunctions: conf.SqlFunctions,
+		Rules:        conf.Rules,
 	}
 
 	result := rulesetProcessor.ImportRuleSet(ruleSet)
 	configResponse.Streams = result.Streams
 	configResponse.Tables = result.Tables
+	configResponse.Views = result.Views
+	configResponse.SqlFunctions = result.SqlFunctions
 	configResponse.Rules = result.Rules
 
 	if !reboot {
@@ -285,6 +301,8 @@ func configurationPartialImport(ctx context.Context, data []byte) ImportConfigur
 	conf := &Configuration{
 		Streams:          make(map[string]string),
 		Tables:           make(map[string]string),
+		Views:            make(map[string]string),
+		SqlFunctions:     make(map[string]string),
 		Rules:            make(map[string]string),
 		NativePlugins:    make(map[string]string),
 		PortablePlugins:  make(map[string]string),
@@ -302,6 +320,8 @@ func configurationPartialImport(ctx context.Context, data []byte) ImportConfigur
 	configResponse := Configuration{
 		Streams:          make(map[string]string),
 		Tables:           make(map[string]string),
+		Views:            make(map[string]string),
+		SqlFunctions:     make(map[string]string),
 		Rules:            make(map[string]string),
 		NativePlugins:    make(map[string]string),
 		PortablePlugins:  make(map[string]string),
@@ -317,6 +337,8 @@ func configurationPartialImport(ctx context.Context, data []byte) ImportConfigur
 	ResponseNil := Configuration{
 		Streams:          make(map[string]string),
 		Tables:           make(map[string]string),
+		Views:            make(map[string]string),
+		SqlFunctions:     make(map[string]string),
 		Rules:            make(map[string]string),
 		NativePlugins:    make(map[string]string),
 		PortablePlugins:  make(map[string]string),
@@ -365,14 +387,18 @@ func configurationPartialImport(ctx context.Context, data []byte) ImportConfigur
 	configResponse.ConnectionConfig = confRsp.Connections
 
 	ruleSet := processor.Ruleset{
-		Streams: conf.Streams,
-		Tables:  conf.Tables,
-		Rules:   conf.Rules,
+		Streams:      conf.Streams,
+		Tables:       conf.Tables,
+		Views:        conf.Views,
+		SqlFunctions: conf.SqlFunctions,
+		Rules:        conf.Rules,
 	}
 
 	result := importRuleSetPartial(ruleSet)
 	configResponse.Streams = result.Streams
 	configResponse.Tables = result.Tables
+	configResponse.Views = result.Views
+	configResponse.SqlFunctions = result.SqlFunctions
 	configResponse.Rules = result.Rules
 
 	if reflect.DeepEqual(ResponseNil, configResponse) {
@@ -463,6 +489,8 @@ func configurationStatusExport() Configuration {
 	conf := Configuration{
 		Streams:          make(map[string]string),
 		Tables:           make(map[string]string),
+		Views:            make(map[string]string),
+		SqlFunctions:     make(map[string]string),
 		Rules:            make(map[string]string),
 		NativePlugins:    make(map[string]string),
 		PortablePlugins:  make(map[string]string),
@@ -585,9 +613,11 @@ func configurationStatusHandler(w http.ResponseWriter, r *http.Request) {
 
 func importRuleSetPartial(all processor.Ruleset) processor.Ruleset {
 	ruleSetRsp := processor.Ruleset{
-		Rules:   map[string]string{},
-		Streams: map[string]string{},
-		Tables:  map[string]string{},
+		Rules:        map[string]string{},
+		Streams:      map[string]string{},
+		Tables:       map[string]string{},
+		Views:        map[string]string{},
+		SqlFunctions: map[string]string{},
 	}
 	// replace streams
 	for k, v := range all.Streams {
@@ -605,6 +635,14 @@ func importRuleSetPartial(all processor.Ruleset) processor.Ruleset {
 			continue
 		}
 	}
+	// replace SQL functions before the views and rules which call them
+	for k, e := range streamProcessor.ImportFunctions(all.SqlFunctions) {
+		ruleSetRsp.SqlFunctions[k] = e.Error()
+	}
+	// replace views
+	for k, e := range streamProcessor.ImportViews(all.Views) {
+		ruleSetRsp.Views[k] = e.Error()
+	}
 
 	for k, v := range all.Rules {
 		err := registry.UpsertRule(k, v)
@@ -28,6 +28,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -199,10 +200,18 @@ func createRestServer(ip string, port int, needToken bool) *http.Server {
 	r.HandleFunc("/streamdetails", streamDetailsHandler).Methods(http.MethodGet)
 	r.HandleFunc("/streams/{name}", streamHandler).Methods(http.MethodGet, http.MethodDelete, http.MethodPut)
 	r.HandleFunc("/streams/{name}/schema", streamSchemaHandler).Methods(http.MethodGet)
+	r.HandleFunc("/streams/{name}/schema/versions", streamSchemaVersionsHandler).Methods(http.MethodGet)
+	r.HandleFunc("/streams/{name}/schema/check", streamSchemaCheckHandler).Methods(http.MethodPost)
 	r.HandleFunc("/tables", tablesHandler).Methods(http.MethodGet, http.MethodPost)
 	r.HandleFunc("/tabledetails", tableDetailsHandler).Methods(http.MethodGet)
 	r.HandleFunc("/tables/{name}", tableHandler).Methods(http.MethodGet, http.MethodDelete, http.MethodPut)
 	r.HandleFunc("/tables/{name}/schema", tableSchemaHandler).Methods(http.MethodGet)
+	r.HandleFunc("/tables/{name}/schema/versions", tableSchemaVersionsHandler).Methods(http.MethodGet)
+	r.HandleFunc("/tables/{name}/schema/check", tableSchemaCheckHandler).Methods(http.MethodPost)
+	r.HandleFunc("/views", viewsHandler).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/views/{name}", viewHandler).Methods(http.MethodGet, http.MethodDelete, http.MethodPut)
+	r.HandleFunc("/sqlfunctions", sqlFunctionsHandler).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/sqlfunctions/{name}", sqlFunctionHandler).Methods(http.MethodGet, http.MethodDelete, http.MethodPut)
 	r.HandleFunc("/rules", rulesHandler).Methods(http.MethodGet, http.MethodPost)
 	r.HandleFunc("/rules/{name}", ruleHandler).Methods(http.MethodDelete, http.MethodGet, http.MethodPut)
 	r.HandleFunc("/rules/status/all", getAllRuleStatusHandler).Methods(http.MethodGet)
@@ -235,6 +244,7 @@ func createRestServer(ip string, port int, needToken bool) *http.Server {
 	r.HandleFunc("/connections", connectionsHandler).Methods(http.MethodGet, http.MethodPost)
 	r.HandleFunc("/connections/{id}", connectionHandler).Methods(http.MethodGet, http.MethodDelete, http.MethodPut)
 	r.HandleFunc("/ruletest", testRuleHandler).Methods(http.MethodPost)
+	r.HandleFunc("/ruletest/run", testRuleCaseHandler).Methods(http.MethodPost)
 	r.HandleFunc("/ruletest/{name}/start", testRuleStartHandler).Methods(http.MethodPost)
 	r.HandleFunc("/ruletest/{name}", testRuleStopHandler).Methods(http.MethodDelete)
 	r.HandleFunc("/v2/data/export", yamlConfigurationExportHandler).Methods(http.MethodGet)
@@ -403,6 +413,20 @@ func explainRuleHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	var explainInfo string
+	if analyze, _ := strconv.ParseBool(r.URL.Query().Get("analyze")); analyze {
+		tp, err := registry.GetRulePlainTopo(name)
+		if err != nil {
+			handleError(w, err, "explain rules error", logger)
+			return
+		}
+		explainInfo, err = planner.ExplainAnalyze(rule, tp)
+		if err != nil {
+			handleError(w, err, "explain rules error", logger)
+			return
+		}
+		w.Write([]byte(explainInfo))
+		return
+	}
 	explainInfo, err = planner.GetExplainInfoFromLogicalPlan(rule)
 	if err != nil {
 		handleError(w, err, "explain rules error", logger)
@@ -634,6 +658,34 @@ func checkStreamBeforeDrop(name string) (bool, error) {
 	return false, nil
 }
 
+// checkExpansionBeforeDrop checks if any rule refers to the view or the SQL function after expanding the rule sql
+func checkExpansionBeforeDrop(refers func(de *dependencies) bool) bool {
+	for _, r := range registry.keys() {
+		rs, ok := registry.load(r)
+		if !ok {
+			continue
+		}
+		de := newDependencies()
+		ruleTraverse(rs.GetRule(), de)
+		if refers(de) {
+			return true
+		}
+	}
+	return false
+}
+
+func checkViewBeforeDrop(name string) bool {
+	return checkExpansionBeforeDrop(func(de *dependencies) bool {
+		return slices.Contains(de.views, name)
+	})
+}
+
+func checkFunctionBeforeDrop(name string) bool {
+	return checkExpansionBeforeDrop(func(de *dependencies) bool {
+		return slices.ContainsFunc(de.sqlFunctions, func(f string) bool { return strings.EqualFold(f, name) })
+	})
+}
+
 func sourceManageHandler(w http.ResponseWriter, r *http.Request, st ast.StreamType) {
 	defer r.Body.Close()
 	vars := mux.Vars(r)
@@ -664,6 +716,10 @@ func sourceManageHandler(w http.ResponseWriter, r *http.Request, st ast.StreamTy
 				handleError(w, fmt.Errorf("stream %v has been referenced by other rules", name), "", logger)
 				return
 			}
+			if err := streamProcessor.CheckStreamBeforeDrop(name); err != nil {
+				handleError(w, err, "", logger)
+				return
+			}
 		}
 		content, err := streamProcessor.DropStream(name, st)
 		if err != nil {
@@ -678,6 +734,20 @@ func sourceManageHandler(w http.ResponseWriter, r *http.Request, st ast.StreamTy
 			handleError(w, err, "Invalid body", logger)
 			return
 		}
+		// Check the schema change unless forced. The lock keeps the rules unchanged until the change is applied.
+		force, _ := strconv.ParseBool(r.URL.Query().Get("force"))
+		if !force {
+			schemaChangeLock.Lock()
+			defer schemaChangeLock.Unlock()
+			result, err := checkSchemaChange(name, v.Sql, st)
+			if err == nil {
+				err = result.err()
+			}
+			if err != nil {
+				handleError(w, err, fmt.Sprintf("%s command error", cases.Title(language.Und).String(ast.StreamTypeMap[st])), logger)
+				return
+			}
+		}
 		content, err := streamProcessor.ExecReplaceStream(name, v.Sql, st)
 		if err != nil {
 			handleError(w, err, fmt.Sprintf("%s command error", cases.Title(language.Und).String(ast.StreamTypeMap[st])), logger)
@@ -736,6 +806,146 @@ func sourceSchemaHandler(w http.ResponseWriter, r *http.Request, st ast.StreamTy
 	jsonResponse(content, w, logger)
 }
 
+// list or create views
+func viewsHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	switch r.Method {
+	case http.MethodGet:
+		content, err := streamProcessor.ShowViews()
+		if err != nil {
+			handleError(w, err, "View command error", logger)
+			return
+		}
+		jsonResponse(content, w, logger)
+	case http.MethodPost:
+		v, err := decodeStatementDescriptor(r.Body)
+		if err != nil {
+			handleError(w, err, "Invalid body", logger)
+			return
+		}
+		content, err := streamProcessor.ExecStreamSql(v.Sql)
+		if err != nil {
+			handleError(w, err, "View command error", logger)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(template.HTMLEscapeString(content)))
+	}
+}
+
+// describe, replace or delete a view
+func viewHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	name := mux.Vars(r)["name"]
+	switch r.Method {
+	case http.MethodGet:
+		content, err := streamProcessor.GetView(name)
+		if err != nil {
+			handleError(w, err, "describe view error", logger)
+			return
+		}
+		jsonResponse(map[string]string{"sql": content}, w, logger)
+	case http.MethodDelete:
+		force, err := strconv.ParseBool(r.URL.Query().Get("force"))
+		if err != nil || !force {
+			if err := streamProcessor.CheckViewBeforeDrop(name); err != nil {
+				handleError(w, err, "", logger)
+				return
+			}
+		}
+		content, err := streamProcessor.DropView(name)
+		if err != nil {
+			handleError(w, err, "delete view error", logger)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(content))
+	case http.MethodPut:
+		v, err := decodeStatementDescriptor(r.Body)
+		if err != nil {
+			handleError(w, err, "Invalid body", logger)
+			return
+		}
+		content, err := streamProcessor.ExecReplaceView(name, v.Sql)
+		if err != nil {
+			handleError(w, err, "View command error", logger)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(content))
+	}
+}
+
+// list or create SQL functions
+func sqlFunctionsHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	switch r.Method {
+	case http.MethodGet:
+		content, err := streamProcessor.ShowFunctions()
+		if err != nil {
+			handleError(w, err, "Function command error", logger)
+			return
+		}
+		jsonResponse(content, w, logger)
+	case http.MethodPost:
+		v, err := decodeStatementDescriptor(r.Body)
+		if err != nil {
+			handleError(w, err, "Invalid body", logger)
+			return
+		}
+		content, err := streamProcessor.ExecStreamSql(v.Sql)
+		if err != nil {
+			handleError(w, err, "Function command error", logger)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(template.HTMLEscapeString(content)))
+	}
+}
+
+// describe, replace or delete a SQL function
+func sqlFunctionHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	name := mux.Vars(r)["name"]
+	switch r.Method {
+	case http.MethodGet:
+		content, err := streamProcessor.GetFunction(name)
+		if err != nil {
+			handleError(w, err, "describe function error", logger)
+			return
+		}
+		jsonResponse(map[string]string{"sql": content}, w, logger)
+	case http.MethodDelete:
+		force, err := strconv.ParseBool(r.URL.Query().Get("force"))
+		if err != nil || !force {
+			if err := streamProcessor.CheckFunctionBeforeDrop(name); err != nil {
+				handleError(w, err, "", logger)
+				return
+			}
+		}
+		content, err := streamProcessor.DropFunction(name)
+		if err != nil {
+			handleError(w, err, "delete function error", logger)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(content))
+	case http.MethodPut:
+		v, err := decodeStatementDescriptor(r.Body)
+		if err != nil {
+			handleError(w, err, "Invalid body", logger)
+			return
+		}
+		content, err := streamProcessor.ExecReplaceFunction(name, v.Sql)
+		if err != nil {
+			handleError(w, err, "Function command error", logger)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(content))
+	}
+}
+
 // list or create rules
 func rulesHandler(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
@@ -1082,6 +1292,23 @@ func testRuleHandler(w http.ResponseWriter, r *http.Request) {
 	jsonResponse(result, w, logger)
 }
 
+// testRuleCaseHandler runs a test case and returns the result with the diffs
+func testRuleCaseHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		handleError(w, err, "Invalid body", logger)
+		return
+	}
+	result, err := trial.RunCase(string(body))
+	if err != nil {
+		handleError(w, err, "rule test error", logger)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	jsonResponse(result, w, logger)
+}
+
 func testRuleStartHandler(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 	vars := mux.Vars(r)
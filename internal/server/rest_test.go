@@ -47,6 +47,7 @@ import (
 func init() {
 	testx.InitEnv("server")
 	streamProcessor = processor.NewStreamProcessor()
+	streamProcessor.SetRuleRefCheckers(checkViewBeforeDrop, checkFunctionBeforeDrop)
 	ruleProcessor = processor.NewRuleProcessor()
 	rulesetProcessor = processor.NewRulesetProcessor(ruleProcessor, streamProcessor)
 	registry = &RuleRegistry{internal: make(map[string]*rule.State)}
@@ -77,10 +78,16 @@ func (suite *RestTestSuite) SetupTest() {
 	r.HandleFunc("/streamdetails", streamDetailsHandler).Methods(http.MethodGet)
 	r.HandleFunc("/streams/{name}", streamHandler).Methods(http.MethodGet, http.MethodDelete, http.MethodPut)
 	r.HandleFunc("/streams/{name}/schema", streamSchemaHandler).Methods(http.MethodGet)
+	r.HandleFunc("/streams/{name}/schema/versions", streamSchemaVersionsHandler).Methods(http.MethodGet)
+	r.HandleFunc("/streams/{name}/schema/check", streamSchemaCheckHandler).Methods(http.MethodPost)
 	r.HandleFunc("/tables", tablesHandler).Methods(http.MethodGet, http.MethodPost)
 	r.HandleFunc("/tabledetails", tableDetailsHandler).Methods(http.MethodGet)
 	r.HandleFunc("/tables/{name}", tableHandler).Methods(http.MethodGet, http.MethodDelete, http.MethodPut)
 	r.HandleFunc("/tables/{name}/schema", tableSchemaHandler).Methods(http.MethodGet)
+	r.HandleFunc("/views", viewsHandler).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/views/{name}", viewHandler).Methods(http.MethodGet, http.MethodDelete, http.MethodPut)
+	r.HandleFunc("/sqlfunctions", sqlFunctionsHandler).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/sqlfunctions/{name}", sqlFunctionHandler).Methods(http.MethodGet, http.MethodDelete, http.MethodPut)
 	r.HandleFunc("/rules", rulesHandler).Methods(http.MethodGet, http.MethodPost)
 	r.HandleFunc("/rules/{name}", ruleHandler).Methods(http.MethodDelete, http.MethodGet, http.MethodPut)
 	r.HandleFunc("/rules/{name}/status", getStatusRuleHandler).Methods(http.MethodGet)
@@ -108,6 +115,7 @@ func (suite *RestTestSuite) SetupTest() {
 	r.HandleFunc("/connections", connectionsHandler).Methods(http.MethodGet, http.MethodPost)
 	r.HandleFunc("/connections/{id}", connectionHandler).Methods(http.MethodGet, http.MethodDelete, http.MethodPut)
 	r.HandleFunc("/ruletest", testRuleHandler).Methods(http.MethodPost)
+	r.HandleFunc("/ruletest/run", testRuleCaseHandler).Methods(http.MethodPost)
 	r.HandleFunc("/ruletest/{name}/start", testRuleStartHandler).Methods(http.MethodPost)
 	r.HandleFunc("/ruletest/{name}", testRuleStopHandler).Methods(http.MethodDelete)
 	// r.HandleFunc("/connection/websocket", connectionHandler).Methods(http.MethodGet, http.MethodPost, http.MethodDelete)
@@ -594,6 +602,53 @@ func (suite *RestTestSuite) Test_ruleTestHandler() {
 	suite.r.ServeHTTP(w, req)
 }
 
+func (suite *RestTestSuite) Test_ruleTestCaseHandler() {
+	buf1 := bytes.NewBuffer([]byte(`{"sql":"CREATE stream casealert(color string, size bigint) WITH (DATASOURCE=\"0\", TYPE=\"mqtt\")"}`))
+	req1, _ := http.NewRequest(http.MethodPost, "http://localhost:8080/streams", buf1)
+	w1 := httptest.NewRecorder()
+	suite.r.ServeHTTP(w1, req1)
+	require.Equal(suite.T(), http.StatusCreated, w1.Code)
+	defer func() {
+		req, _ := http.NewRequest(http.MethodDelete, "http://localhost:8080/streams/casealert", bytes.NewBufferString("any"))
+		suite.r.ServeHTTP(httptest.NewRecorder(), req)
+	}()
+
+	tests := []struct {
+		name string
+		body string
+		code int
+		resp string
+	}{
+		{
+			name: "pass",
+			body: `{"name":"pass","rule":{"sql":"SELECT color FROM casealert WHERE size > 2"},"inputs":{"casealert":[{"timestamp":1,"data":{"color":"red","size":3}},{"timestamp":2,"data":{"color":"blue","size":1}}]},"expected":{"memory_0":[[{"color":"red"}]]}}`,
+			code: http.StatusOK,
+			resp: `{"name":"pass","pass":true,"sinks":[{"name":"memory_0","pass":true,"expected":[[{"color":"red"}]],"actual":[[{"color":"red"}]]}]}`,
+		},
+		{
+			name: "fail",
+			body: `{"name":"fail","rule":{"sql":"SELECT color FROM casealert WHERE size > 2"},"inputs":{"casealert":[{"timestamp":1,"data":{"color":"red","size":3}}]},"expected":{"memory_0":[[{"color":"blue"}]]}}`,
+			code: http.StatusOK,
+			resp: `{"name":"fail","pass":false,"sinks":[{"name":"memory_0","pass":false,"expected":[[{"color":"blue"}]],"actual":[[{"color":"red"}]],"diff":["output #1: expected [{\"color\":\"blue\"}], got [{\"color\":\"red\"}]"]}]}`,
+		},
+		{
+			name: "invalid",
+			body: `{"name":"invalid"}`,
+			code: http.StatusBadRequest,
+			resp: `{"error":1000,"message":"rule test error: rule is required in the test case"}`,
+		},
+	}
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			req, _ := http.NewRequest(http.MethodPost, "http://localhost:8080/ruletest/run", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+			suite.r.ServeHTTP(w, req)
+			require.Equal(suite.T(), tt.code, w.Code)
+			require.JSONEq(suite.T(), tt.resp, w.Body.String())
+		})
+	}
+}
+
 func (suite *RestTestSuite) Test_configUpdate() {
 	req, _ := http.NewRequest(http.MethodPatch, "http://localhost:8080/configs", bytes.NewBufferString(""))
 	w := httptest.NewRecorder()
@@ -915,6 +970,172 @@ func (suite *RestTestSuite) TestUpdateRuleOffset() {
 	require.Equal(suite.T(), `success`, returnStr)
 }
 
+func (suite *RestTestSuite) Test_streamSchemaChange() {
+	_, err := streamProcessor.ExecStreamSql(`CREATE STREAM schemaDemo (a bigint, b string) WITH (DATASOURCE="0", TYPE="memory")`)
+	require.NoError(suite.T(), err)
+	defer streamProcessor.DropStream("schemaDemo", ast.TypeStream)
+	rules := map[string]string{
+		"schemaRule1": `{"sql":"SELECT a, b FROM schemaDemo","actions":[{"log":{}}]}`,
+		"schemaRule2": `{"sql":"SELECT b FROM schemaDemo","actions":[{"log":{}}],"options":{"schemaVersions":{"schemaDemo":1}}}`,
+		"schemaRule3": `{"sql":"SELECT a FROM schemaDemo","actions":[{"log":{}}]}`,
+	}
+	for id, r := range rules {
+		require.NoError(suite.T(), ruleProcessor.ExecCreate(id, r))
+		defer ruleProcessor.ExecDrop(id)
+	}
+
+	// remove field b breaks the rule using it
+	buf := bytes.NewBufferString(`{"sql":"CREATE STREAM schemaDemo (a bigint, c string) WITH (DATASOURCE=\"0\", TYPE=\"memory\")"}`)
+	req, _ := http.NewRequest(http.MethodPost, "http://localhost:8080/streams/schemaDemo/schema/check", buf)
+	w := httptest.NewRecorder()
+	suite.r.ServeHTTP(w, req)
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	returnVal, _ := io.ReadAll(w.Result().Body)
+	require.JSONEq(suite.T(), `{"name":"schemaDemo","version":1,"newVersion":2,"compatible":true,"rules":[{"id":"schemaRule1","valid":false,"error":"unknown field b"},{"id":"schemaRule2","pinnedVersion":1,"valid":true},{"id":"schemaRule3","valid":true}]}`, string(returnVal))
+
+	buf = bytes.NewBufferString(`{"sql":"CREATE STREAM schemaDemo (a bigint, c string) WITH (DATASOURCE=\"0\", TYPE=\"memory\")"}`)
+	req, _ = http.NewRequest(http.MethodPut, "http://localhost:8080/streams/schemaDemo", buf)
+	w = httptest.NewRecorder()
+	suite.r.ServeHTTP(w, req)
+	require.Equal(suite.T(), http.StatusBadRequest, w.Code)
+	returnVal, _ = io.ReadAll(w.Result().Body)
+	require.Equal(suite.T(), `{"error":1000,"message":"Stream command error: Replace schemaDemo fails: the schema change breaks rules schemaRule1 (unknown field b)."}`+"\n", string(returnVal))
+
+	// incompatible type change
+	buf = bytes.NewBufferString(`{"sql":"CREATE STREAM schemaDemo (a string, b string) WITH (DATASOURCE=\"0\", TYPE=\"memory\", SCHEMA_COMPATIBILITY=\"full\")"}`)
+	req, _ = http.NewRequest(http.MethodPut, "http://localhost:8080/streams/schemaDemo", buf)
+	w = httptest.NewRecorder()
+	suite.r.ServeHTTP(w, req)
+	require.Equal(suite.T(), http.StatusBadRequest, w.Code)
+	returnVal, _ = io.ReadAll(w.Result().Body)
+	require.Equal(suite.T(), `{"error":1000,"message":"Stream command error: Replace schemaDemo fails: the schema change is incompatible (backward: field a changes type from bigint to string, forward: field a changes type from string to bigint)."}`+"\n", string(returnVal))
+
+	// the check error is returned instead of skipping the check
+	buf = bytes.NewBufferString(`{"sql":"CREATE STREAM schemaDemo (a float, b string"}`)
+	req, _ = http.NewRequest(http.MethodPut, "http://localhost:8080/streams/schemaDemo", buf)
+	w = httptest.NewRecorder()
+	suite.r.ServeHTTP(w, req)
+	require.Equal(suite.T(), http.StatusBadRequest, w.Code)
+	returnVal, _ = io.ReadAll(w.Result().Body)
+	require.Equal(suite.T(), "{\"error\":1000,\"message\":\"Stream command error: found \\\"EOF\\\", expect comma or rparen.\"}\n", string(returnVal))
+
+	// the rule creation waits for the schema change being applied
+	schemaChangeLock.Lock()
+	created := make(chan error, 1)
+	go func() {
+		_, err := registry.CreateRule("schemaRule4", `{"sql":"SELECT a FROM schemaDemo","actions":[{"log":{}}],"triggered":false}`)
+		created <- err
+	}()
+	select {
+	case <-created:
+		suite.T().Fatal("rule is created during the schema change")
+	case <-time.After(50 * time.Millisecond):
+	}
+	schemaChangeLock.Unlock()
+	require.NoError(suite.T(), <-created)
+	defer registry.DeleteRule("schemaRule4")
+
+	// compatible change is applied as a new version
+	buf = bytes.NewBufferString(`{"sql":"CREATE STREAM schemaDemo (a float, b string, c string) WITH (DATASOURCE=\"0\", TYPE=\"memory\")"}`)
+	req, _ = http.NewRequest(http.MethodPut, "http://localhost:8080/streams/schemaDemo", buf)
+	w = httptest.NewRecorder()
+	suite.r.ServeHTTP(w, req)
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+
+	// force to apply the change breaking the rule
+	buf = bytes.NewBufferString(`{"sql":"CREATE STREAM schemaDemo (a float, c string) WITH (DATASOURCE=\"0\", TYPE=\"memory\")"}`)
+	req, _ = http.NewRequest(http.MethodPut, "http://localhost:8080/streams/schemaDemo?force=true", buf)
+	w = httptest.NewRecorder()
+	suite.r.ServeHTTP(w, req)
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest(http.MethodGet, "http://localhost:8080/streams/schemaDemo/schema/versions", nil)
+	w = httptest.NewRecorder()
+	suite.r.ServeHTTP(w, req)
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	var versions []*processor.SchemaVersion
+	require.NoError(suite.T(), json.NewDecoder(w.Result().Body).Decode(&versions))
+	require.Len(suite.T(), versions, 3)
+	require.Equal(suite.T(), 3, versions[2].Version)
+	require.Equal(suite.T(), `CREATE STREAM schemaDemo (a float, c string) WITH (DATASOURCE="0", TYPE="memory")`, versions[2].Statement)
+}
+
+func (suite *RestTestSuite) Test_viewsAndSqlFunctions() {
+	_, err := streamProcessor.ExecStreamSql(`CREATE STREAM viewDemo (temperature float, humidity bigint) WITH (DATASOURCE="0", TYPE="memory")`)
+	require.NoError(suite.T(), err)
+	defer streamProcessor.DropStream("viewDemo", ast.TypeStream)
+	// clean up the leftovers of the failed runs
+	_ = ruleProcessor.ExecDrop("viewRule")
+	_, _ = streamProcessor.DropView("viewValid")
+	_, _ = streamProcessor.DropFunction("viewC2f")
+	request := func(method, url, body string) (int, string) {
+		req, _ := http.NewRequest(method, "http://localhost:8080"+url, bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		suite.r.ServeHTTP(w, req)
+		returnVal, _ := io.ReadAll(w.Result().Body)
+		return w.Code, string(returnVal)
+	}
+
+	code, body := request(http.MethodPost, "/sqlfunctions", `{"sql":"CREATE FUNCTION viewC2f(c) AS c * 1.8 + 32"}`)
+	require.Equal(suite.T(), http.StatusCreated, code)
+	require.Equal(suite.T(), "Function viewC2f is created.", body)
+	code, body = request(http.MethodPut, "/sqlfunctions/viewC2f", `{"sql":"CREATE FUNCTION viewC2f(c) AS c * 9 / 5 + 32"}`)
+	require.Equal(suite.T(), http.StatusOK, code)
+	require.Equal(suite.T(), "Function viewC2f is replaced.", body)
+	code, body = request(http.MethodGet, "/sqlfunctions", "")
+	require.Equal(suite.T(), http.StatusOK, code)
+	require.JSONEq(suite.T(), `["viewC2f"]`, body)
+	code, body = request(http.MethodGet, "/sqlfunctions/viewC2f", "")
+	require.Equal(suite.T(), http.StatusOK, code)
+	require.JSONEq(suite.T(), `{"sql":"CREATE FUNCTION viewC2f(c) AS c * 9 / 5 + 32"}`, body)
+
+	code, body = request(http.MethodPost, "/views", `{"sql":"CREATE VIEW viewValid AS SELECT viewC2f(temperature) AS f FROM viewDemo WHERE humidity > 10"}`)
+	require.Equal(suite.T(), http.StatusCreated, code)
+	require.Equal(suite.T(), "View viewValid is created.", body)
+	code, _ = request(http.MethodPut, "/views/viewValid", `{"sql":"CREATE VIEW other AS SELECT temperature FROM viewDemo"}`)
+	require.Equal(suite.T(), http.StatusBadRequest, code)
+	code, body = request(http.MethodPut, "/views/viewValid", `{"sql":"CREATE VIEW viewValid AS SELECT viewC2f(temperature) AS f FROM viewDemo WHERE humidity > 20"}`)
+	require.Equal(suite.T(), http.StatusOK, code)
+	require.Equal(suite.T(), "View viewValid is replaced.", body)
+	code, body = request(http.MethodGet, "/views", "")
+	require.Equal(suite.T(), http.StatusOK, code)
+	require.JSONEq(suite.T(), `["viewValid"]`, body)
+	code, body = request(http.MethodGet, "/views/viewValid", "")
+	require.Equal(suite.T(), http.StatusOK, code)
+	require.JSONEq(suite.T(), `{"sql":"CREATE VIEW viewValid AS SELECT viewC2f(temperature) AS f FROM viewDemo WHERE humidity > 20"}`, body)
+
+	// the view and the function cannot be dropped when a rule refers to them
+	_, err = registry.CreateRule("viewRule", `{"sql":"SELECT f FROM viewValid","actions":[{"log":{}}],"triggered":false}`)
+	require.NoError(suite.T(), err)
+	code, body = request(http.MethodDelete, "/views/viewValid", "")
+	require.Equal(suite.T(), http.StatusBadRequest, code)
+	require.Equal(suite.T(), `{"error":1000,"message":"view viewValid has been referenced by other rules"}`+"\n", body)
+	code, body = request(http.MethodDelete, "/sqlfunctions/viewc2f", "")
+	require.Equal(suite.T(), http.StatusBadRequest, code)
+	require.Equal(suite.T(), `{"error":1000,"message":"function viewc2f has been referenced by other rules"}`+"\n", body)
+	_, err = streamProcessor.ExecStreamSql("DROP VIEW viewValid")
+	require.EqualError(suite.T(), err, "Drop view fails: view viewValid has been referenced by other rules.")
+	require.NoError(suite.T(), registry.DeleteRule("viewRule"))
+	// the stream and the function cannot be dropped when a view refers to them
+	code, body = request(http.MethodDelete, "/streams/viewDemo", "")
+	require.Equal(suite.T(), http.StatusBadRequest, code)
+	require.Equal(suite.T(), `{"error":1000,"message":"viewDemo has been referenced by views viewValid"}`+"\n", body)
+	code, body = request(http.MethodDelete, "/sqlfunctions/viewC2f", "")
+	require.Equal(suite.T(), http.StatusBadRequest, code)
+	require.Equal(suite.T(), `{"error":1000,"message":"function viewC2f has been referenced by view viewValid"}`+"\n", body)
+
+	code, body = request(http.MethodDelete, "/views/viewValid", "")
+	require.Equal(suite.T(), http.StatusOK, code)
+	require.Equal(suite.T(), "View viewValid is dropped.", body)
+	code, _ = request(http.MethodGet, "/views/viewValid", "")
+	require.Equal(suite.T(), http.StatusNotFound, code)
+	code, body = request(http.MethodDelete, "/sqlfunctions/viewC2f", "")
+	require.Equal(suite.T(), http.StatusOK, code)
+	require.Equal(suite.T(), "Function viewC2f is dropped.", body)
+	code, _ = request(http.MethodGet, "/sqlfunctions/viewC2f", "")
+	require.Equal(suite.T(), http.StatusNotFound, code)
+}
+
 func (suite *RestTestSuite) TestCreateDuplicateRule() {
 	buf1 := bytes.NewBuffer([]byte(`{"sql":"CREATE stream demo123() WITH (DATASOURCE=\"0\", TYPE=\"mqtt\")"}`))
 	req1, _ := http.NewRequest(http.MethodPost, "http://localhost:8080/streams", buf1)
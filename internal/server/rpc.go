@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -33,6 +34,7 @@ import (
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/model"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/rule/machine"
+	"github.com/lf-edge/ekuiper/v2/internal/trial"
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 	"github.com/lf-edge/ekuiper/v2/pkg/infra"
 	"github.com/lf-edge/ekuiper/v2/pkg/validate"
@@ -277,6 +279,18 @@ func (t *Server) ValidateRule(rule *model.RPCArgDesc, reply *string) error {
 	return nil
 }
 
+func (t *Server) TestRule(content string, reply *string) error {
+	r, err := trial.RunCaseYaml(content)
+	if err != nil {
+		return fmt.Errorf("Run rule test error : %s.", err)
+	}
+	if !r.Pass {
+		return errors.New(r.String())
+	}
+	*reply = r.String()
+	return nil
+}
+
 func (t *Server) Import(file string, reply *string) error {
 	f, err := os.Open(file)
 	if err != nil {
@@ -221,6 +221,45 @@ func (suite *ServerTestSuite) TestRule() {
 	assert.Equal(suite.T(), "Rule myRule is dropped.", reply)
 }
 
+func (suite *ServerTestSuite) TestTestRule() {
+	var reply string
+	err := suite.s.Stream(`CREATE STREAM rpccase (color string, size bigint) WITH (DATASOURCE="rpccase", TYPE="mqtt")`, &reply)
+	assert.Nil(suite.T(), err)
+	defer func() {
+		_ = suite.s.Stream("DROP STREAM rpccase", &reply)
+	}()
+
+	// The test case file of the cli is in yaml
+	reply = ""
+	caseYaml := `name: rpc
+rule:
+  sql: SELECT color FROM rpccase WHERE size > 2
+inputs:
+  rpccase:
+    - timestamp: 1
+      data: { color: red, size: 3 }
+    - timestamp: 2
+      data: { color: blue, size: 1 }
+expected:
+  memory_0:
+    - [{ color: red }]
+`
+	err = suite.s.TestRule(caseYaml, &reply)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "PASS: rule test rpc", reply)
+
+	reply = ""
+	caseJson := `{"name":"rpc","rule":{"sql":"SELECT color FROM rpccase WHERE size > 2"},"inputs":{"rpccase":[{"timestamp":1,"data":{"color":"red","size":3}}]},"expected":{"memory_0":[[{"color":"blue"}]]}}`
+	err = suite.s.TestRule(caseJson, &reply)
+	assert.EqualError(suite.T(), err, "FAIL: rule test rpc\n  sink memory_0:\n    output #1: expected [{\"color\":\"blue\"}], got [{\"color\":\"red\"}]")
+
+	err = suite.s.TestRule(`{"name":"rpc"}`, &reply)
+	assert.EqualError(suite.T(), err, "Run rule test error : rule is required in the test case.")
+
+	err = suite.s.TestRule("name: [rpc", &reply)
+	assert.ErrorContains(suite.T(), err, "Run rule test error : fail to parse test case")
+}
+
 func (suite *ServerTestSuite) TestImportAndExport() {
 	file := "rpc_test_data/import.json"
 	var reply string
@@ -244,12 +283,12 @@ func (suite *ServerTestSuite) TestConfiguration() {
 	var reply string
 	err := suite.s.ImportConfiguration(&importArg, &reply)
 	assert.Nil(suite.T(), err)
-	assert.Equal(suite.T(), "{\n  \"ErrorMsg\": \"\",\n  \"ConfigResponse\": {\n    \"streams\": {},\n    \"tables\": {},\n    \"rules\": {},\n    \"nativePlugins\": {},\n    \"portablePlugins\": {},\n    \"sourceConfig\": {},\n    \"sinkConfig\": {},\n    \"connectionConfig\": {},\n    \"Service\": {},\n    \"Schema\": {},\n    \"uploads\": {},\n    \"scripts\": {}\n  }\n}", reply)
+	assert.Equal(suite.T(), "{\n  \"ErrorMsg\": \"\",\n  \"ConfigResponse\": {\n    \"streams\": {},\n    \"tables\": {},\n    \"views\": {},\n    \"sqlFunctions\": {},\n    \"rules\": {},\n    \"nativePlugins\": {},\n    \"portablePlugins\": {},\n    \"sourceConfig\": {},\n    \"sinkConfig\": {},\n    \"connectionConfig\": {},\n    \"Service\": {},\n    \"Schema\": {},\n    \"uploads\": {},\n    \"scripts\": {}\n  }\n}", reply)
 
 	reply = ""
 	err = suite.s.GetStatusImport(1, &reply)
 	assert.Nil(suite.T(), err)
-	assert.Equal(suite.T(), "{\n  \"streams\": {},\n  \"tables\": {},\n  \"rules\": {},\n  \"nativePlugins\": {},\n  \"portablePlugins\": {},\n  \"sourceConfig\": {},\n  \"sinkConfig\": {},\n  \"connectionConfig\": {},\n  \"Service\": {},\n  \"Schema\": {},\n  \"uploads\": {},\n  \"scripts\": {}\n}", reply)
+	assert.Equal(suite.T(), "{\n  \"streams\": {},\n  \"tables\": {},\n  \"views\": {},\n  \"sqlFunctions\": {},\n  \"rules\": {},\n  \"nativePlugins\": {},\n  \"portablePlugins\": {},\n  \"sourceConfig\": {},\n  \"sinkConfig\": {},\n  \"connectionConfig\": {},\n  \"Service\": {},\n  \"Schema\": {},\n  \"uploads\": {},\n  \"scripts\": {}\n}", reply)
 
 	reply = ""
 	exportArg := model.ExportDataDesc{
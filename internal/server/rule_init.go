@@ -147,6 +147,26 @@ func resetAllStreams() error {
 	Streams := allStreams["streams"]
 	Tables := allStreams["tables"]
 
+	// Views and functions are dropped first as they are defined on the streams
+	views, err := streamProcessor.ShowViews()
+	if err != nil {
+		return err
+	}
+	for _, name := range views {
+		if _, err2 := streamProcessor.DropView(name); err2 != nil {
+			logger.Warnf("streamProcessor DropView %s error: %v", name, err2)
+		}
+	}
+	functions, err := streamProcessor.ShowFunctions()
+	if err != nil {
+		return err
+	}
+	for _, name := range functions {
+		if _, err2 := streamProcessor.DropFunction(name); err2 != nil {
+			logger.Warnf("streamProcessor DropFunction %s error: %v", name, err2)
+		}
+	}
+
 	for name := range Streams {
 		_, err2 := streamProcessor.DropStream(name, ast.TypeStream)
 		if err2 != nil {
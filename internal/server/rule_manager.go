@@ -124,6 +124,9 @@ func (rr *RuleRegistry) delete(key string) (*rule.State, error) {
 //// Keep consistent by DB. Rollback when db errors happen
 
 func (rr *RuleRegistry) CreateRule(name, ruleJson string) (id string, err error) {
+	// No stream schema change is applied until the rule is saved
+	schemaChangeLock.RLock()
+	defer schemaChangeLock.RUnlock()
 	// Validate the rule json
 	r, err := ruleProcessor.GetRuleByJson(name, ruleJson)
 	if err != nil {
@@ -189,6 +192,9 @@ func (rr *RuleRegistry) UpsertRule(ruleId, ruleJson string) error {
 		return fmt.Errorf("Invalid rule json: %v", err)
 	}
 
+	// No stream schema change is applied until the rule is saved
+	schemaChangeLock.RLock()
+	defer schemaChangeLock.RUnlock()
 	// Hold lock for entire operation to ensure atomic version check
 	rr.Lock()
 	defer rr.Unlock()
@@ -437,7 +443,12 @@ func (rr *RuleRegistry) ValidateRule(name, ruleJson string) ([]string, bool, err
 	}
 	var sources []string
 	if len(ruleDef.Sql) > 0 {
-		stmt, _ := xsql.GetStatementFromSql(ruleDef.Sql)
+		stmt, _ := processor.GetStreamProcessorStatement(ruleDef.Sql)
+		if stmt != nil {
+			if _, err := processor.ExpandStreamProcessorStatement(stmt); err != nil {
+				return nil, false, err
+			}
+		}
 		s, err := store.GetKV("stream")
 		if err != nil {
 			return nil, false, err
@@ -68,16 +68,25 @@ type dependencies struct {
 	sinkConfigKeys   map[string][]string
 	functions        []string
 	schemas          []string
+	views            []string
+	sqlFunctions     []string
 }
 
 func ruleTraverse(rule *def.Rule, de *dependencies) {
 	sql := rule.Sql
 	ruleGraph := rule.Graph
 	if sql != "" {
-		stmt, err := xsql.GetStatementFromSql(sql)
+		stmt, err := processor.GetStreamProcessorStatement(sql)
 		if err != nil {
 			return
 		}
+		// views and SQL functions are expanded to find out the streams and functions they depend on
+		expansion, err := processor.ExpandStreamProcessorStatement(stmt)
+		if err != nil {
+			return
+		}
+		de.views = append(de.views, expansion.Views...)
+		de.sqlFunctions = append(de.sqlFunctions, expansion.Functions...)
 		store, err := store2.GetKV("stream")
 		if err != nil {
 			return
@@ -158,6 +167,15 @@ func ruleTraverse(rule *def.Rule, de *dependencies) {
 		for _, gn := range ruleGraph.Nodes {
 			switch gn.Type {
 			case "source":
+				// the source node may refer to a created stream or table
+				sourceMeta := &def.SourceMeta{SourceType: "stream"}
+				if err := cast.MapToStruct(gn.Props, sourceMeta); err == nil && sourceMeta.SourceName != "" {
+					if sourceMeta.SourceType == "table" {
+						de.tables = append(de.tables, sourceMeta.SourceName)
+					} else {
+						de.streams = append(de.streams, sourceMeta.SourceName)
+					}
+				}
 				sourceOption := &ast.Options{}
 				err := cast.MapToStruct(gn.Props, sourceOption)
 				if err != nil {
@@ -317,6 +335,8 @@ func (p *RuleMigrationProcessor) ConfigurationPartialExport(rules []string) ([]b
 	config := &Configuration{
 		Streams:          make(map[string]string),
 		Tables:           make(map[string]string),
+		Views:            make(map[string]string),
+		SqlFunctions:     make(map[string]string),
 		Rules:            make(map[string]string),
 		NativePlugins:    make(map[string]string),
 		PortablePlugins:  make(map[string]string),
@@ -373,10 +393,31 @@ func (p *RuleMigrationProcessor) exportTables(tables []string) map[string]string
 	return tableSet
 }
 
+func (p *RuleMigrationProcessor) exportViews(views []string) map[string]string {
+	viewSet := make(map[string]string)
+
+	for _, v := range views {
+		viewSet[v], _ = p.s.GetView(v)
+	}
+	return viewSet
+}
+
+func (p *RuleMigrationProcessor) exportSqlFunctions(functions []string) map[string]string {
+	funcSet := make(map[string]string)
+
+	for _, v := range functions {
+		funcSet[v], _ = p.s.GetFunction(v)
+	}
+	return funcSet
+}
+
 func (p *RuleMigrationProcessor) exportSelected(de *dependencies, config *Configuration) {
 	// get the stream and table
 	config.Streams = p.exportStreams(de.streams)
 	config.Tables = p.exportTables(de.tables)
+	// get the views and SQL functions
+	config.Views = p.exportViews(de.views)
+	config.SqlFunctions = p.exportSqlFunctions(de.sqlFunctions)
 	// get the sources
 	for _, v := range de.sources {
 		t, srcName, srcInfo := io.GetSourcePlugin(v)
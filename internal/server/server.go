@@ -231,6 +231,7 @@ func StartUp(Version string) {
 	httpserver.InitGlobalServerManager(conf.Config.Source.HttpServerIp, conf.Config.Source.HttpServerPort, conf.Config.Source.HttpServerTls)
 	ruleProcessor = processor.NewRuleProcessor()
 	streamProcessor = processor.NewStreamProcessor()
+	streamProcessor.SetRuleRefCheckers(checkViewBeforeDrop, checkFunctionBeforeDrop)
 	rulesetProcessor = processor.NewRulesetProcessor(ruleProcessor, streamProcessor)
 	ruleMigrationProcessor = NewRuleMigrationProcessor(ruleProcessor, streamProcessor)
 	sysMetrics = NewMetrics()
@@ -0,0 +1,163 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/lf-edge/ekuiper/v2/internal/topo/planner"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/syncx"
+)
+
+// schemaCheckResult is the result of checking a schema change of a stream against the compatibility mode and the
+// rules depending on the stream
+type schemaCheckResult struct {
+	Name string `json:"name"`
+	// Version is the current schema version and NewVersion is the version the change will be saved as
+	Version           int                `json:"version"`
+	NewVersion        int                `json:"newVersion"`
+	Compatible        bool               `json:"compatible"`
+	Incompatibilities []string           `json:"incompatibilities,omitempty"`
+	Rules             []*ruleCheckResult `json:"rules"`
+}
+
+type ruleCheckResult struct {
+	Id string `json:"id"`
+	// PinnedVersion is the schema version pinned by the rule. The pinned rule is not affected by the change.
+	PinnedVersion int    `json:"pinnedVersion,omitempty"`
+	Valid         bool   `json:"valid"`
+	Error         string `json:"error,omitempty"`
+}
+
+// err returns the error if the change is incompatible or breaks any rule
+func (r *schemaCheckResult) err() error {
+	var msgs []string
+	if !r.Compatible {
+		msgs = append(msgs, fmt.Sprintf("the schema change is incompatible (%s)", strings.Join(r.Incompatibilities, ", ")))
+	}
+	var broken []string
+	for _, rr := range r.Rules {
+		if !rr.Valid {
+			broken = append(broken, fmt.Sprintf("%s (%s)", rr.Id, rr.Error))
+		}
+	}
+	if len(broken) > 0 {
+		msgs = append(msgs, fmt.Sprintf("the schema change breaks rules %s", strings.Join(broken, ", ")))
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("Replace %s fails: %s.", r.Name, strings.Join(msgs, "; "))
+}
+
+// schemaChangeLock makes checking and applying a schema change atomic. The change holds the write lock, and the rule
+// creation and update hold the read lock, so that no rule escapes the check by being saved between the two steps.
+var schemaChangeLock syncx.RWMutex
+
+// checkSchemaChange checks the new definition of the stream by the compatibility mode and re-validates each rule
+// depending on the stream with the new definition. The rules pinning a schema version of the stream are skipped.
+func checkSchemaChange(name string, statement string, st ast.StreamType) (*schemaCheckResult, error) {
+	c, err := streamProcessor.GetSchemaChange(name, statement, st)
+	if err != nil {
+		return nil, err
+	}
+	result := &schemaCheckResult{
+		Name:              name,
+		Version:           c.Version,
+		NewVersion:        c.NewVersion,
+		Compatible:        len(c.Incompatibilities) == 0,
+		Incompatibilities: c.Incompatibilities,
+		Rules:             make([]*ruleCheckResult, 0),
+	}
+	ids, err := ruleProcessor.GetAllRules()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		rule, err := ruleProcessor.GetRuleById(id)
+		if err != nil {
+			continue
+		}
+		de := newDependencies()
+		ruleTraverse(rule, de)
+		if !slices.Contains(de.streams, name) && !slices.Contains(de.tables, name) {
+			continue
+		}
+		rr := &ruleCheckResult{Id: id, Valid: true}
+		if v, ok := rule.Options.SchemaVersions[name]; ok {
+			rr.PinnedVersion = v
+		} else {
+			// The rule invalid with the current definition is not broken by the change
+			if err := planner.ValidateRule(rule, map[string]string{name: statement}); err != nil && planner.ValidateRule(rule, nil) == nil {
+				rr.Valid = false
+				rr.Error = err.Error()
+			}
+		}
+		result.Rules = append(result.Rules, rr)
+	}
+	return result, nil
+}
+
+func streamSchemaVersionsHandler(w http.ResponseWriter, r *http.Request) {
+	sourceSchemaVersionsHandler(w, r, ast.TypeStream)
+}
+
+func tableSchemaVersionsHandler(w http.ResponseWriter, r *http.Request) {
+	sourceSchemaVersionsHandler(w, r, ast.TypeTable)
+}
+
+// list the schema versions of a stream or table
+func sourceSchemaVersionsHandler(w http.ResponseWriter, r *http.Request, st ast.StreamType) {
+	name := mux.Vars(r)["name"]
+	content, err := streamProcessor.GetSchemaVersions(name, st)
+	if err != nil {
+		handleError(w, err, fmt.Sprintf("get schema versions of %s error", ast.StreamTypeMap[st]), logger)
+		return
+	}
+	jsonResponse(content, w, logger)
+}
+
+func streamSchemaCheckHandler(w http.ResponseWriter, r *http.Request) {
+	sourceSchemaCheckHandler(w, r, ast.TypeStream)
+}
+
+func tableSchemaCheckHandler(w http.ResponseWriter, r *http.Request) {
+	sourceSchemaCheckHandler(w, r, ast.TypeTable)
+}
+
+// check the schema change of a stream or table without applying it
+func sourceSchemaCheckHandler(w http.ResponseWriter, r *http.Request, st ast.StreamType) {
+	defer r.Body.Close()
+	name := mux.Vars(r)["name"]
+	v, err := decodeStatementDescriptor(r.Body)
+	if err != nil {
+		handleError(w, err, "Invalid body", logger)
+		return
+	}
+	content, err := checkSchemaChange(name, v.Sql, st)
+	if err != nil {
+		handleError(w, err, fmt.Sprintf("check schema of %s error", ast.StreamTypeMap[st]), logger)
+		return
+	}
+	jsonResponse(content, w, logger)
+}
@@ -87,7 +87,7 @@ func newGrpcExecutor(desc descriptor, opt *interfaceOpt, _ *interfaceInfo) (exec
 // Each interface definition maps to one executor instance. It is supposed to have only one thread running.
 func NewExecutor(i *interfaceInfo) (executor, error) {
 	// No validation here, suppose the validation has been done in json parsing
-	descriptor, err := parse(i.Schema.SchemaType, i.Schema.SchemaFile, i.Schema.Schemaless)
+	descriptor, err := parse(i.Schema.SchemaType, i.Schema.SchemaFile, i.Schema.Schemaless, i.Addr)
 	if err != nil {
 		return nil, err
 	}
@@ -255,7 +255,17 @@ func (h *httpExecutor) invokeFunction(ctx api.FunctionContext, name string, para
 	if err != nil {
 		return nil, err
 	}
-	resp, err := httpx.Send(ctx.GetLogger(), h.conn, "json", hm.Method, u, h.restOpt.Headers, hm.Body)
+	headers := h.restOpt.Headers
+	if len(hm.Headers) > 0 {
+		headers = make(map[string]string, len(h.restOpt.Headers)+len(hm.Headers))
+		for k, v := range h.restOpt.Headers {
+			headers[k] = v
+		}
+		for k, v := range hm.Headers {
+			headers[k] = v
+		}
+	}
+	resp, err := httpx.Send(ctx.GetLogger(), h.conn, "json", hm.Method, u, headers, hm.Body)
 	if err != nil {
 		return nil, err
 	}
@@ -152,7 +152,10 @@ func (m *Manager) initFile(baseName string) error {
 		Interfaces: make(map[string]*interfaceInfo),
 	}
 	for name, binding := range serviceConf.Interfaces {
-		desc, err := parse(binding.SchemaType, binding.SchemaFile, binding.Schemaless)
+		if err := validateProtocol(binding); err != nil {
+			return fmt.Errorf("invalid interface %s: %v", name, err)
+		}
+		desc, err := parse(binding.SchemaType, binding.SchemaFile, binding.Schemaless, binding.Address)
 		if err != nil {
 			return fmt.Errorf("Fail to parse schema file %s: %v", binding.SchemaFile, err)
 		}
@@ -163,7 +166,10 @@ func (m *Manager) initFile(baseName string) error {
 			aliasMap[finfo.ServiceName] = finfo.Name
 		}
 
-		methods := desc.GetFunctions()
+		methods, err := interfaceMethods(desc, binding)
+		if err != nil {
+			return fmt.Errorf("Fail to get the functions of interface %s: %v", name, err)
+		}
 		functions := make([]string, len(methods))
 		for i, f := range methods {
 			fname := f
@@ -218,6 +224,21 @@ func (m *Manager) initFile(baseName string) error {
 	return nil
 }
 
+// validateProtocol checks the schema types which can only be invoked by one protocol
+func validateProtocol(b *binding) error {
+	switch b.SchemaType {
+	case OPENAPI:
+		if b.Protocol != REST {
+			return fmt.Errorf("schema type %s only supports protocol %s but got %s", OPENAPI, REST, b.Protocol)
+		}
+	case REFLECTION:
+		if b.Protocol != GRPC {
+			return fmt.Errorf("schema type %s only supports protocol %s but got %s", REFLECTION, GRPC, b.Protocol)
+		}
+	}
+	return nil
+}
+
 // Start Implement FunctionFactory
 
 func (m *Manager) HasFunctionSet(_ string) bool {
@@ -320,9 +341,33 @@ func (m *Manager) getExecutor(name string, info *interfaceInfo) (executor, error
 	return e.(executor), nil
 }
 
+// interfaceMethods returns the method names of an interface. For reflection, the methods declared in the functions
+// mapping are used directly so that the server is only contacted when the functions are not declared.
+func interfaceMethods(d descriptor, b *binding) ([]string, error) {
+	rd, ok := d.(*reflectionDescriptor)
+	if !ok {
+		return d.GetFunctions(), nil
+	}
+	if len(b.Functions) > 0 {
+		methods := make([]string, 0, len(b.Functions))
+		for _, f := range b.Functions {
+			methods = append(methods, f.ServiceName)
+		}
+		return methods, nil
+	}
+	wd, err := rd.resolve()
+	if err != nil {
+		return nil, err
+	}
+	return wd.GetFunctions(), nil
+}
+
 func (m *Manager) deleteServiceFuncs(service string) error {
 	if s, ok := m.getService(service); ok {
 		for _, i := range s.Interfaces {
+			if i.Schema != nil && i.Schema.SchemaType == REFLECTION {
+				dropReflectionDescriptor(i.Addr)
+			}
 			for _, f := range i.Functions {
 				_ = m.deleteFunc(service, f)
 			}
@@ -29,6 +29,8 @@ const (
 
 const (
 	PROTOBUFF  schema = "protobuf"
+	OPENAPI    schema = "openapi"
+	REFLECTION schema = "reflection"
 	SCHEMALESS schema = ""
 )
 
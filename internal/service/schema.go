@@ -72,17 +72,23 @@ var ( // Do not call these directly, use the get methods
 
 func ProtoParser() *protoparse.Parser {
 	once.Do(func() {
-		dir := "data/services/schemas/"
-		if kconf.IsTesting {
-			dir = "service/test/schemas/"
-		}
-		schemaDir, _ := kconf.GetLoc(dir)
-		protoParser = &protoparse.Parser{ImportPaths: []string{schemaDir}}
+		dir, _ := schemaDir()
+		protoParser = &protoparse.Parser{ImportPaths: []string{dir}}
 	})
 	return protoParser
 }
 
-func parse(schema schema, file string, schemaless bool) (descriptor, error) {
+// schemaDir returns the folder of the schema files like *.proto and openapi documents
+func schemaDir() (string, error) {
+	dir := "data/services/schemas/"
+	if kconf.IsTesting {
+		dir = "service/test/schemas/"
+	}
+	return kconf.GetLoc(dir)
+}
+
+// parse the schema to descriptor. The addr is only used by the reflection schema to fetch the schema from the server
+func parse(schema schema, file string, schemaless bool, addr string) (descriptor, error) {
 	info := &schemaInfo{
 		SchemaType: schema,
 		SchemaFile: file,
@@ -108,9 +114,9 @@ func parse(schema schema, file string, schemaless bool) (descriptor, error) {
 			return nil, err
 		} else {
 			result := &wrappedProtoDescriptor{
-				FileDescriptor: fds[0],
-				mf:             dynamic.NewMessageFactoryWithDefaults(),
-				fc:             protobuf.GetFieldConverter(),
+				serviceSource: fds[0],
+				mf:            dynamic.NewMessageFactoryWithDefaults(),
+				fc:            protobuf.GetFieldConverter(),
 			}
 			err := result.parseHttpOptions()
 			if err != nil {
@@ -119,6 +125,25 @@ func parse(schema schema, file string, schemaless bool) (descriptor, error) {
 			reg.Store(info, result)
 			return result, nil
 		}
+	case OPENAPI:
+		if schemaless {
+			return nil, fmt.Errorf("unsupported schema %s for schemaless type", schema)
+		}
+		if v, ok := reg.Load(info); ok {
+			return v.(descriptor), nil
+		}
+		result, err := parseOpenapi(file)
+		if err != nil {
+			return nil, err
+		}
+		reg.Store(info, result)
+		return result, nil
+	case REFLECTION:
+		if schemaless || file != "" {
+			return nil, fmt.Errorf("reflection schema type does not support schema files")
+		}
+		// Do not connect to the server here. The services are fetched on the first use and cached by address
+		return getReflectionDescriptor(addr), nil
 	default:
 		return nil, fmt.Errorf("unsupported schema %s", schema)
 	}
@@ -202,8 +227,13 @@ func (d *wrappedSchemalessDescriptor) ConvertReturn(_ string, params interface{}
 	return params, nil
 }
 
+// serviceSource provides the services defined in a proto file or fetched by grpc reflection
+type serviceSource interface {
+	GetServices() []*desc.ServiceDescriptor
+}
+
 type wrappedProtoDescriptor struct {
-	*desc.FileDescriptor
+	serviceSource
 	methodOptions map[string]*httpOptions
 	mf            *dynamic.MessageFactory
 	fc            *protobuf.FieldConverter
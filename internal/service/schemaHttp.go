@@ -29,9 +29,10 @@ import (
 )
 
 type httpConnMeta struct {
-	Method string
-	Uri    string // The Uri is a relative path which must start with /
-	Body   []byte
+	Method  string
+	Uri     string // The Uri is a relative path which must start with /
+	Body    []byte
+	Headers map[string]string // The request specific headers which override the headers in options
 }
 
 type httpMapping interface {
@@ -64,7 +64,7 @@ func TestBookstoreConvertHttpMapping(t *testing.T) {
 			},
 		},
 	}
-	d, err := parse(PROTOBUFF, "http_bookstore.proto", false)
+	d, err := parse(PROTOBUFF, "http_bookstore.proto", false, "")
 	if err != nil {
 		panic(err)
 	}
@@ -140,7 +140,7 @@ func TestMessagingConvertHttpMapping(t *testing.T) {
 			},
 		},
 	}
-	d, err := parse(PROTOBUFF, "http_messaging.proto", false)
+	d, err := parse(PROTOBUFF, "http_messaging.proto", false, "")
 	if err != nil {
 		panic(err)
 	}
@@ -217,7 +217,7 @@ func TestSchemalessConvertHttpMapping(t *testing.T) {
 			},
 		},
 	}
-	d, err := parse(SCHEMALESS, "", true)
+	d, err := parse(SCHEMALESS, "", true, "")
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,626 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+// openapiDoc is the subset of an OpenAPI 3 document that is used to map the operations to functions
+type openapiDoc struct {
+	OpenAPI    string                      `yaml:"openapi"`
+	Paths      map[string]*openapiPathItem `yaml:"paths"`
+	Components *openapiComponents          `yaml:"components"`
+}
+
+type openapiComponents struct {
+	Schemas    map[string]*jsonSchema       `yaml:"schemas"`
+	Parameters map[string]*openapiParameter `yaml:"parameters"`
+}
+
+type openapiPathItem struct {
+	Get        *openapiOperation   `yaml:"get"`
+	Put        *openapiOperation   `yaml:"put"`
+	Post       *openapiOperation   `yaml:"post"`
+	Delete     *openapiOperation   `yaml:"delete"`
+	Patch      *openapiOperation   `yaml:"patch"`
+	Parameters []*openapiParameter `yaml:"parameters"`
+}
+
+type openapiOperation struct {
+	OperationId string                      `yaml:"operationId"`
+	Parameters  []*openapiParameter         `yaml:"parameters"`
+	RequestBody *openapiBody                `yaml:"requestBody"`
+	Responses   map[string]*openapiResponse `yaml:"responses"`
+}
+
+type openapiParameter struct {
+	Ref      string      `yaml:"$ref"`
+	Name     string      `yaml:"name"`
+	In       string      `yaml:"in"`
+	Required bool        `yaml:"required"`
+	Schema   *jsonSchema `yaml:"schema"`
+}
+
+type openapiBody struct {
+	Required bool                         `yaml:"required"`
+	Content  map[string]*openapiMediaType `yaml:"content"`
+}
+
+type openapiResponse struct {
+	Content map[string]*openapiMediaType `yaml:"content"`
+}
+
+type openapiMediaType struct {
+	Schema *jsonSchema `yaml:"schema"`
+}
+
+// jsonSchema is the subset of json schema supported to validate the request and response bodies
+type jsonSchema struct {
+	Ref                  string                 `yaml:"$ref"`
+	Type                 string                 `yaml:"type"`
+	Nullable             bool                   `yaml:"nullable"`
+	Properties           map[string]*jsonSchema `yaml:"properties"`
+	Required             []string               `yaml:"required"`
+	AdditionalProperties *bool                  `yaml:"additionalProperties"`
+	Items                *jsonSchema            `yaml:"items"`
+	Enum                 []interface{}          `yaml:"enum"`
+	Minimum              *float64               `yaml:"minimum"`
+	Maximum              *float64               `yaml:"maximum"`
+	MinLength            *int                   `yaml:"minLength"`
+	MaxLength            *int                   `yaml:"maxLength"`
+	MinItems             *int                   `yaml:"minItems"`
+	MaxItems             *int                   `yaml:"maxItems"`
+	Pattern              string                 `yaml:"pattern"`
+}
+
+// openapiMethod is the parsed function definition of an operation
+type openapiMethod struct {
+	HttpMethod string
+	Path       string
+	Params     []*openapiParameter
+	Body       *jsonSchema
+	// If the body is required. Only valid when Body is not nil
+	BodyRequired bool
+	Response     *jsonSchema
+}
+
+type wrappedOpenapiDescriptor struct {
+	methods map[string]*openapiMethod
+	// keep the order of the operations as they are defined in the document
+	names []string
+}
+
+var pathParamRe = regexp.MustCompile(`\{([^{}]+)\}`)
+
+func parseOpenapi(file string) (*wrappedOpenapiDescriptor, error) {
+	dir, err := schemaDir()
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(filepath.Join(dir, file))
+	if err != nil {
+		return nil, fmt.Errorf("fail to read openapi file %s: %v", file, err)
+	}
+	doc := &openapiDoc{}
+	// yaml is a superset of json, so both formats can be parsed
+	if err := yaml.Unmarshal(content, doc); err != nil {
+		return nil, fmt.Errorf("fail to parse openapi file %s: %v", file, err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("unsupported openapi version %s, only 3.x is supported", doc.OpenAPI)
+	}
+	return doc.toDescriptor()
+}
+
+func (doc *openapiDoc) toDescriptor() (*wrappedOpenapiDescriptor, error) {
+	result := &wrappedOpenapiDescriptor{methods: make(map[string]*openapiMethod)}
+	paths := make([]string, 0, len(doc.Paths))
+	for p := range doc.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		item := doc.Paths[p]
+		ops := []struct {
+			method string
+			op     *openapiOperation
+		}{
+			{http.MethodGet, item.Get},
+			{http.MethodPut, item.Put},
+			{http.MethodPost, item.Post},
+			{http.MethodDelete, item.Delete},
+			{http.MethodPatch, item.Patch},
+		}
+		for _, o := range ops {
+			if o.op == nil {
+				continue
+			}
+			if o.op.OperationId == "" {
+				return nil, fmt.Errorf("operationId is required for %s %s", o.method, p)
+			}
+			if _, ok := result.methods[o.op.OperationId]; ok {
+				return nil, fmt.Errorf("duplicate operationId %s", o.op.OperationId)
+			}
+			m, err := doc.toMethod(o.method, p, item.Parameters, o.op)
+			if err != nil {
+				return nil, fmt.Errorf("invalid operation %s: %v", o.op.OperationId, err)
+			}
+			result.methods[o.op.OperationId] = m
+			result.names = append(result.names, o.op.OperationId)
+		}
+	}
+	return result, nil
+}
+
+func (doc *openapiDoc) toMethod(method string, path string, common []*openapiParameter, op *openapiOperation) (*openapiMethod, error) {
+	m := &openapiMethod{HttpMethod: method, Path: path}
+	// Operation parameters override the path level parameters with the same name and location
+	var params []*openapiParameter
+	index := make(map[string]int)
+	for _, ps := range [][]*openapiParameter{common, op.Parameters} {
+		for _, pp := range ps {
+			p, err := doc.resolveParameter(pp)
+			if err != nil {
+				return nil, err
+			}
+			switch p.In {
+			case "path", "query", "header":
+			default:
+				return nil, fmt.Errorf("unsupported parameter location %s for %s", p.In, p.Name)
+			}
+			if p.Schema != nil {
+				s, err := doc.resolveSchema(p.Schema, 0)
+				if err != nil {
+					return nil, err
+				}
+				p = &openapiParameter{Name: p.Name, In: p.In, Required: p.Required, Schema: s}
+			}
+			key := p.In + ":" + p.Name
+			if i, ok := index[key]; ok {
+				params[i] = p
+			} else {
+				index[key] = len(params)
+				params = append(params, p)
+			}
+		}
+	}
+	for _, e := range pathParamRe.FindAllStringSubmatch(path, -1) {
+		if _, ok := index["path:"+e[1]]; !ok {
+			return nil, fmt.Errorf("path parameter %s is not defined", e[1])
+		}
+	}
+	m.Params = params
+	if op.RequestBody != nil {
+		s, err := doc.jsonContentSchema(op.RequestBody.Content)
+		if err != nil {
+			return nil, err
+		}
+		if s == nil {
+			return nil, fmt.Errorf("only application/json request body is supported")
+		}
+		m.Body = s
+		m.BodyRequired = op.RequestBody.Required
+	}
+	for _, code := range []string{"200", "201", "202", "2XX", "default"} {
+		if r, ok := op.Responses[code]; ok && r != nil {
+			s, err := doc.jsonContentSchema(r.Content)
+			if err != nil {
+				return nil, err
+			}
+			m.Response = s
+			break
+		}
+	}
+	return m, nil
+}
+
+// jsonContentSchema picks the schema of application/json. If absent, the first json media type like
+// application/json;charset=utf-8 in alphabetical order is used so that the choice is stable.
+func (doc *openapiDoc) jsonContentSchema(content map[string]*openapiMediaType) (*jsonSchema, error) {
+	mt, ok := content["application/json"]
+	if !ok {
+		keys := make([]string, 0, len(content))
+		for ct := range content {
+			if strings.HasPrefix(ct, "application/json") {
+				keys = append(keys, ct)
+			}
+		}
+		if len(keys) == 0 {
+			return nil, nil
+		}
+		sort.Strings(keys)
+		mt = content[keys[0]]
+	}
+	if mt == nil || mt.Schema == nil {
+		return &jsonSchema{}, nil
+	}
+	return doc.resolveSchema(mt.Schema, 0)
+}
+
+func (doc *openapiDoc) resolveParameter(p *openapiParameter) (*openapiParameter, error) {
+	if p.Ref == "" {
+		return p, nil
+	}
+	name, ok := strings.CutPrefix(p.Ref, "#/components/parameters/")
+	if !ok || doc.Components == nil || doc.Components.Parameters[name] == nil {
+		return nil, fmt.Errorf("cannot resolve parameter reference %s", p.Ref)
+	}
+	return doc.Components.Parameters[name], nil
+}
+
+// resolveSchema replaces all the references with the component schemas. Recursive schemas are not supported.
+func (doc *openapiDoc) resolveSchema(s *jsonSchema, depth int) (*jsonSchema, error) {
+	if depth > 32 {
+		return nil, fmt.Errorf("schema is nested too deep, recursive reference is not supported")
+	}
+	if s.Ref != "" {
+		name, ok := strings.CutPrefix(s.Ref, "#/components/schemas/")
+		if !ok || doc.Components == nil || doc.Components.Schemas[name] == nil {
+			return nil, fmt.Errorf("cannot resolve schema reference %s", s.Ref)
+		}
+		return doc.resolveSchema(doc.Components.Schemas[name], depth+1)
+	}
+	r := *s
+	if s.Properties != nil {
+		r.Properties = make(map[string]*jsonSchema, len(s.Properties))
+		for k, v := range s.Properties {
+			rv, err := doc.resolveSchema(v, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			r.Properties[k] = rv
+		}
+	}
+	if s.Items != nil {
+		ri, err := doc.resolveSchema(s.Items, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		r.Items = ri
+	}
+	return &r, nil
+}
+
+func (d *wrappedOpenapiDescriptor) GetFunctions() []string {
+	return d.names
+}
+
+func (d *wrappedOpenapiDescriptor) method(name string) (*openapiMethod, error) {
+	m, ok := d.methods[name]
+	if !ok {
+		return nil, fmt.Errorf("can't find operation %s in openapi", name)
+	}
+	return m, nil
+}
+
+// mapParams maps the function arguments to the operation parameters and the body
+// 1. If there is only one map argument and it has a field named as a parameter, or the arguments count does not match,
+// the parameters are picked by name and the left fields compose the body.
+// 2. If the arguments count equals to the parameter count (body included), they are mapped in order.
+func (m *openapiMethod) mapParams(params []interface{}) (map[*openapiParameter]interface{}, interface{}, error) {
+	values := make(map[*openapiParameter]interface{}, len(m.Params))
+	var body interface{}
+	total := len(m.Params)
+	if m.Body != nil {
+		total++
+	}
+	var (
+		mm     map[string]interface{}
+		byName bool
+	)
+	if len(params) == 1 {
+		mm, byName = xsql.ToMessage(params[0])
+		byName = byName && (total != 1 || m.hasParamField(mm))
+	}
+	switch {
+	case byName:
+		rest := make(map[string]interface{}, len(mm))
+		for k, v := range mm {
+			rest[k] = v
+		}
+		for _, p := range m.Params {
+			if v, ok := mm[p.Name]; ok {
+				values[p] = v
+				delete(rest, p.Name)
+			}
+		}
+		if m.Body != nil && len(rest) > 0 {
+			body = rest
+		}
+	case len(params) == total:
+		for i, p := range m.Params {
+			values[p] = params[i]
+		}
+		if m.Body != nil {
+			body = params[total-1]
+		}
+	case len(params) == 1:
+		return nil, nil, fmt.Errorf("require %d parameters but only got 1", total)
+	default:
+		return nil, nil, fmt.Errorf("require %d parameters but got %d", total, len(params))
+	}
+	for _, p := range m.Params {
+		v, ok := values[p]
+		if !ok || v == nil {
+			if p.Required || p.In == "path" {
+				return nil, nil, fmt.Errorf("required parameter %s is missing", p.Name)
+			}
+			delete(values, p)
+			continue
+		}
+		if p.Schema != nil {
+			if err := p.Schema.validate(v, p.Name); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	if m.Body != nil {
+		if body == nil {
+			if m.BodyRequired {
+				return nil, nil, fmt.Errorf("request body is required")
+			}
+		} else if err := m.Body.validate(body, "body"); err != nil {
+			return nil, nil, err
+		}
+	}
+	return values, body, nil
+}
+
+// hasParamField returns whether the map argument has a field named as a parameter
+func (m *openapiMethod) hasParamField(mm map[string]interface{}) bool {
+	for _, p := range m.Params {
+		if _, ok := mm[p.Name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *wrappedOpenapiDescriptor) ConvertHttpMapping(method string, params []interface{}) (*httpConnMeta, error) {
+	m, err := d.method(method)
+	if err != nil {
+		return nil, err
+	}
+	values, body, err := m.mapParams(params)
+	if err != nil {
+		return nil, err
+	}
+	hcm := &httpConnMeta{Method: m.HttpMethod}
+	query := url.Values{}
+	uri := m.Path
+	for _, p := range m.Params {
+		v, ok := values[p]
+		if !ok {
+			continue
+		}
+		s, err := cast.ToString(v, cast.CONVERT_ALL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parameter %s(%v), must be a primitive value", p.Name, v)
+		}
+		switch p.In {
+		case "path":
+			uri = strings.ReplaceAll(uri, "{"+p.Name+"}", url.PathEscape(s))
+		case "query":
+			query.Set(p.Name, s)
+		case "header":
+			if hcm.Headers == nil {
+				hcm.Headers = make(map[string]string)
+			}
+			hcm.Headers[p.Name] = s
+		}
+	}
+	if len(query) > 0 {
+		uri = uri + "?" + query.Encode()
+	}
+	hcm.Uri = uri
+	if body != nil {
+		hcm.Body, err = json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return hcm, nil
+}
+
+func (d *wrappedOpenapiDescriptor) ConvertParamsToJson(method string, params []interface{}) ([]byte, error) {
+	m, err := d.method(method)
+	if err != nil {
+		return nil, err
+	}
+	_, body, err := m.mapParams(params)
+	if err != nil || body == nil {
+		return nil, err
+	}
+	return json.Marshal(body)
+}
+
+func (d *wrappedOpenapiDescriptor) ConvertReturnJson(method string, returnVal []byte) (interface{}, error) {
+	m, err := d.method(method)
+	if err != nil {
+		return nil, err
+	}
+	var r interface{}
+	if err := json.Unmarshal(returnVal, &r); err != nil {
+		return nil, err
+	}
+	if m.Response != nil {
+		if err := m.Response.validate(r, "response"); err != nil {
+			return nil, fmt.Errorf("invalid response of %s: %v", method, err)
+		}
+	}
+	return r, nil
+}
+
+func (d *wrappedOpenapiDescriptor) ConvertParamsToText(method string, params []interface{}) ([]byte, error) {
+	return d.ConvertParamsToJson(method, params)
+}
+
+func (d *wrappedOpenapiDescriptor) ConvertReturnText(method string, returnVal []byte) (interface{}, error) {
+	m, err := d.method(method)
+	if err != nil {
+		return nil, err
+	}
+	// The text is returned as is only if the operation does not declare a json response
+	if m.Response == nil && !json.Valid(returnVal) {
+		return string(returnVal), nil
+	}
+	return d.ConvertReturnJson(method, returnVal)
+}
+
+func (d *wrappedOpenapiDescriptor) ConvertParams(method string, params []interface{}) ([]interface{}, error) {
+	m, err := d.method(method)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := m.mapParams(params); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+func (d *wrappedOpenapiDescriptor) ConvertReturn(_ string, returnVal interface{}) (interface{}, error) {
+	return returnVal, nil
+}
+
+// validate checks the value against the schema. The path is used to locate the invalid field in the error message.
+func (s *jsonSchema) validate(v interface{}, path string) error {
+	if v == nil {
+		if s.Nullable || s.Type == "" {
+			return nil
+		}
+		return fmt.Errorf("%s must not be null", path)
+	}
+	if len(s.Enum) > 0 {
+		found := false
+		for _, e := range s.Enum {
+			if enumEqual(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s must be one of %v but got %v", path, s.Enum, v)
+		}
+	}
+	switch s.Type {
+	case "":
+		return nil
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("%s must be a string but got %v", path, v)
+		}
+		if s.MinLength != nil && len([]rune(str)) < *s.MinLength {
+			return fmt.Errorf("%s length must be at least %d", path, *s.MinLength)
+		}
+		if s.MaxLength != nil && len([]rune(str)) > *s.MaxLength {
+			return fmt.Errorf("%s length must be at most %d", path, *s.MaxLength)
+		}
+		if s.Pattern != "" {
+			matched, err := regexp.MatchString(s.Pattern, str)
+			if err != nil {
+				return fmt.Errorf("invalid pattern %s for %s: %v", s.Pattern, path, err)
+			}
+			if !matched {
+				return fmt.Errorf("%s must match pattern %s", path, s.Pattern)
+			}
+		}
+	case "integer", "number":
+		f, err := cast.ToFloat64(v, cast.CONVERT_SAMEKIND)
+		if err != nil {
+			return fmt.Errorf("%s must be of type %s but got %v", path, s.Type, v)
+		}
+		if s.Type == "integer" && f != math.Trunc(f) {
+			return fmt.Errorf("%s must be an integer but got %v", path, v)
+		}
+		if s.Minimum != nil && f < *s.Minimum {
+			return fmt.Errorf("%s must be >= %v", path, *s.Minimum)
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			return fmt.Errorf("%s must be <= %v", path, *s.Maximum)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("%s must be a boolean but got %v", path, v)
+		}
+	case "array":
+		arr, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s must be an array but got %v", path, v)
+		}
+		if s.MinItems != nil && len(arr) < *s.MinItems {
+			return fmt.Errorf("%s must have at least %d items", path, *s.MinItems)
+		}
+		if s.MaxItems != nil && len(arr) > *s.MaxItems {
+			return fmt.Errorf("%s must have at most %d items", path, *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range arr {
+				if err := s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case "object":
+		obj, ok := xsql.ToMessage(v)
+		if !ok {
+			return fmt.Errorf("%s must be an object but got %v", path, v)
+		}
+		for _, r := range s.Required {
+			if _, ok := obj[r]; !ok {
+				return fmt.Errorf("%s.%s is required", path, r)
+			}
+		}
+		for k, fv := range obj {
+			ps, ok := s.Properties[k]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					return fmt.Errorf("%s.%s is not allowed", path, k)
+				}
+				continue
+			}
+			if err := ps.validate(fv, path+"."+k); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported schema type %s for %s", s.Type, path)
+	}
+	return nil
+}
+
+func enumEqual(e interface{}, v interface{}) bool {
+	if e == v {
+		return true
+	}
+	ef, err1 := cast.ToFloat64(e, cast.CONVERT_SAMEKIND)
+	vf, err2 := cast.ToFloat64(v, cast.CONVERT_SAMEKIND)
+	return err1 == nil && err2 == nil && ef == vf
+}
@@ -0,0 +1,264 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"net"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	grpcReflection "google.golang.org/grpc/reflection"
+
+	"github.com/lf-edge/ekuiper/v2/internal/testx"
+)
+
+func TestOpenapiConvertHttpMapping(t *testing.T) {
+	tests := []struct {
+		method string
+		params []interface{}
+		result *httpConnMeta
+		err    string
+	}{
+		{
+			method: "listPets",
+			params: []interface{}{10, "t1"},
+			result: &httpConnMeta{
+				Method:  http.MethodGet,
+				Uri:     "/pets?limit=10",
+				Headers: map[string]string{"X-Tenant": "t1"},
+			},
+		}, {
+			method: "listPets",
+			params: []interface{}{map[string]interface{}{}},
+			result: &httpConnMeta{
+				Method: http.MethodGet,
+				Uri:    "/pets",
+			},
+		}, {
+			method: "listPets",
+			params: []interface{}{200, "t1"},
+			err:    "limit must be <= 100",
+		}, {
+			method: "createPet",
+			params: []interface{}{map[string]interface{}{"name": "kitty", "tag": "cat"}},
+			result: &httpConnMeta{
+				Method: http.MethodPost,
+				Uri:    "/pets",
+				Body:   []byte(`{"name":"kitty","tag":"cat"}`),
+			},
+		}, {
+			method: "createPet",
+			params: []interface{}{map[string]interface{}{"tag": "cat"}},
+			err:    "body.name is required",
+		}, {
+			method: "createPet",
+			params: []interface{}{map[string]interface{}{"name": "kitty", "tag": "bird"}},
+			err:    "body.tag must be one of [dog cat] but got bird",
+		}, {
+			method: "getPet",
+			params: []interface{}{int64(12)},
+			result: &httpConnMeta{
+				Method: http.MethodGet,
+				Uri:    "/pets/12",
+			},
+		}, {
+			method: "getPet",
+			params: []interface{}{map[string]interface{}{"petId": 12}},
+			result: &httpConnMeta{
+				Method: http.MethodGet,
+				Uri:    "/pets/12",
+			},
+		}, {
+			method: "getPet",
+			params: []interface{}{"abc"},
+			err:    "petId must be of type integer but got abc",
+		}, {
+			method: "updatePet",
+			params: []interface{}{map[string]interface{}{"petId": 12, "name": "kitty"}},
+			result: &httpConnMeta{
+				Method: http.MethodPut,
+				Uri:    "/pets/12",
+				Body:   []byte(`{"name":"kitty"}`),
+			},
+		}, {
+			method: "updatePet",
+			params: []interface{}{map[string]interface{}{"name": "kitty"}},
+			err:    "required parameter petId is missing",
+		}, {
+			method: "deletePet",
+			params: []interface{}{12},
+			err:    "can't find operation deletePet in openapi",
+		},
+	}
+	d, err := parse(OPENAPI, "petstore.yaml", false, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"listPets", "createPet", "getPet", "updatePet"}, d.GetFunctions())
+	for i, tt := range tests {
+		r, err := d.(httpMapping).ConvertHttpMapping(tt.method, tt.params)
+		if !reflect.DeepEqual(tt.err, testx.Errstring(err)) {
+			t.Errorf("%d : interface error mismatch:\n  exp=%s\n  got=%s\n\n", i, tt.err, err)
+		} else if tt.err == "" && !reflect.DeepEqual(tt.result, r) {
+			t.Errorf("%d \n\ninterface result mismatch:\n\nexp=%#v\n\ngot=%#v\n\n", i, tt.result, r)
+		}
+	}
+}
+
+func TestOpenapiConvertReturn(t *testing.T) {
+	tests := []struct {
+		method string
+		ret    string
+		result interface{}
+		err    string
+	}{
+		{
+			method: "getPet",
+			ret:    `{"id":1,"name":"kitty"}`,
+			result: map[string]interface{}{"id": 1.0, "name": "kitty"},
+		}, {
+			method: "getPet",
+			ret:    `{"id":1.5,"name":"kitty"}`,
+			err:    "invalid response of getPet: response.id must be an integer but got 1.5",
+		}, {
+			method: "listPets",
+			ret:    `[{"id":1,"name":"kitty"},{"name":"doggy"}]`,
+			err:    "invalid response of listPets: response[1].id is required",
+		}, {
+			method: "updatePet",
+			ret:    `{"any":"thing"}`,
+			result: map[string]interface{}{"any": "thing"},
+		},
+	}
+	d, err := parse(OPENAPI, "petstore.yaml", false, "")
+	require.NoError(t, err)
+	for i, tt := range tests {
+		r, err := d.(jsonDescriptor).ConvertReturnJson(tt.method, []byte(tt.ret))
+		if !reflect.DeepEqual(tt.err, testx.Errstring(err)) {
+			t.Errorf("%d : interface error mismatch:\n  exp=%s\n  got=%s\n\n", i, tt.err, err)
+		} else if tt.err == "" && !reflect.DeepEqual(tt.result, r) {
+			t.Errorf("%d \n\ninterface result mismatch:\n\nexp=%#v\n\ngot=%#v\n\n", i, tt.result, r)
+		}
+	}
+}
+
+func TestOpenapiConvertReturnText(t *testing.T) {
+	d, err := parse(OPENAPI, "petstore.yaml", false, "")
+	require.NoError(t, err)
+	td := d.(textDescriptor)
+	r, err := td.ConvertReturnText("getPet", []byte(`{"id":1,"name":"kitty"}`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"id": 1.0, "name": "kitty"}, r)
+	_, err = td.ConvertReturnText("getPet", []byte(`{"id":1.5,"name":"kitty"}`))
+	assert.EqualError(t, err, "invalid response of getPet: response.id must be an integer but got 1.5")
+	_, err = td.ConvertReturnText("getPet", []byte(`not found`))
+	assert.Error(t, err)
+	// The operation without a json response returns the text as is
+	r, err = td.ConvertReturnText("updatePet", []byte(`updated`))
+	require.NoError(t, err)
+	assert.Equal(t, "updated", r)
+}
+
+func TestValidateProtocol(t *testing.T) {
+	tests := []struct {
+		b   *binding
+		err string
+	}{
+		{b: &binding{Protocol: REST, SchemaType: OPENAPI}},
+		{b: &binding{Protocol: GRPC, SchemaType: OPENAPI}, err: "schema type openapi only supports protocol rest but got grpc"},
+		{b: &binding{Protocol: GRPC, SchemaType: REFLECTION}},
+		{b: &binding{Protocol: REST, SchemaType: REFLECTION}, err: "schema type reflection only supports protocol grpc but got rest"},
+		{b: &binding{Protocol: MSGPACK, SchemaType: PROTOBUFF}},
+	}
+	for i, tt := range tests {
+		assert.Equal(t, tt.err, testx.Errstring(validateProtocol(tt.b)), i)
+	}
+}
+
+func TestReflectionService(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := grpc.NewServer()
+	RegisterGreeterServer(s, &server{})
+	grpcReflection.Register(s)
+	go func() {
+		_ = s.Serve(lis)
+	}()
+	defer s.Stop()
+
+	// The server is not contacted when parsing
+	down, err := parse(REFLECTION, "", false, "tcp://127.0.0.1:1")
+	require.NoError(t, err)
+	_, err = down.(*reflectionDescriptor).resolve()
+	assert.Error(t, err)
+	// Declared functions are used without fetching the services
+	methods, err := interfaceMethods(down, &binding{Functions: []*mapping{{Name: "hello", ServiceName: "SayHello"}}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"SayHello"}, methods)
+	_, err = interfaceMethods(down, &binding{})
+	assert.Error(t, err)
+
+	addr := "tcp://" + lis.Addr().String()
+	d, err := parse(REFLECTION, "", false, addr)
+	require.NoError(t, err)
+	assert.Contains(t, d.GetFunctions(), "SayHello")
+	// The descriptor is cached by address
+	d2, err := parse(REFLECTION, "", false, addr)
+	require.NoError(t, err)
+	assert.Same(t, d, d2)
+	dropReflectionDescriptor(addr)
+	d3, err := parse(REFLECTION, "", false, addr)
+	require.NoError(t, err)
+	assert.NotSame(t, d, d3)
+
+	exe, err := NewExecutor(&interfaceInfo{
+		Addr:     addr,
+		Protocol: GRPC,
+		Schema:   &schemaInfo{SchemaType: REFLECTION},
+	})
+	require.NoError(t, err)
+	r, err := exe.InvokeFunction(nil, "SayHello", []interface{}{"world"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"message": "world"}, r)
+
+	_, err = parse(REFLECTION, "hw.proto", false, addr)
+	assert.EqualError(t, err, "reflection schema type does not support schema files")
+}
+
+func TestJsonContentSchema(t *testing.T) {
+	doc := &openapiDoc{}
+	content := map[string]*openapiMediaType{
+		"application/json;charset=utf-8": {Schema: &jsonSchema{Type: "string"}},
+		"application/json":               {Schema: &jsonSchema{Type: "object"}},
+		"application/json;v=2":           {Schema: &jsonSchema{Type: "array"}},
+		"text/plain":                     {Schema: &jsonSchema{Type: "integer"}},
+	}
+	for i := 0; i < 10; i++ {
+		s, err := doc.jsonContentSchema(content)
+		require.NoError(t, err)
+		assert.Equal(t, "object", s.Type)
+	}
+	delete(content, "application/json")
+	for i := 0; i < 10; i++ {
+		s, err := doc.jsonContentSchema(content)
+		require.NoError(t, err)
+		assert.Equal(t, "string", s.Type)
+	}
+	s, err := doc.jsonContentSchema(map[string]*openapiMediaType{"text/plain": {}})
+	require.NoError(t, err)
+	assert.Nil(t, s)
+}
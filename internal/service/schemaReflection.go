@@ -0,0 +1,165 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jhump/protoreflect/desc"        //nolint:staticcheck
+	"github.com/jhump/protoreflect/dynamic"     //nolint:staticcheck
+	"github.com/jhump/protoreflect/grpcreflect" //nolint:staticcheck
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/lf-edge/ekuiper/v2/internal/converter/protobuf"
+)
+
+const reflectionTimeout = 5 * time.Second
+
+// reflectedServices is the service list fetched from a grpc server by server reflection
+type reflectedServices []*desc.ServiceDescriptor
+
+func (r reflectedServices) GetServices() []*desc.ServiceDescriptor {
+	return r
+}
+
+// reflectionDescriptor is the descriptor of a grpc server with reflection. The services are fetched lazily on the first use
+// and cached until the service is deleted, so that registering a service never requires the server to be up.
+type reflectionDescriptor struct {
+	addr string
+	mf   *dynamic.MessageFactory
+
+	mu sync.Mutex
+	d  *wrappedProtoDescriptor
+}
+
+// reflectionKey is the key of the reflection descriptors in the descriptor buffer
+type reflectionKey string
+
+func getReflectionDescriptor(addr string) *reflectionDescriptor {
+	v, _ := reg.LoadOrStore(reflectionKey(addr), &reflectionDescriptor{
+		addr: addr,
+		mf:   dynamic.NewMessageFactoryWithDefaults(),
+	})
+	return v.(*reflectionDescriptor)
+}
+
+// dropReflectionDescriptor removes the cached descriptor so that the next use fetches the services again
+func dropReflectionDescriptor(addr string) {
+	reg.Delete(reflectionKey(addr))
+}
+
+// resolve fetches the services from the server once. A failed fetch is not cached and will be retried on the next use.
+func (r *reflectionDescriptor) resolve() (*wrappedProtoDescriptor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.d != nil {
+		return r.d, nil
+	}
+	services, err := reflectServices(r.addr)
+	if err != nil {
+		return nil, err
+	}
+	d := &wrappedProtoDescriptor{
+		serviceSource: services,
+		mf:            r.mf,
+		fc:            protobuf.GetFieldConverter(),
+	}
+	if err := d.parseHttpOptions(); err != nil {
+		return nil, err
+	}
+	r.d = d
+	return d, nil
+}
+
+// GetFunctions returns nil if the services cannot be fetched. Use resolve to get the error.
+func (r *reflectionDescriptor) GetFunctions() []string {
+	d, err := r.resolve()
+	if err != nil {
+		return nil
+	}
+	return d.GetFunctions()
+}
+
+func (r *reflectionDescriptor) ConvertParamsToMessage(method string, params []interface{}) (*dynamic.Message, error) {
+	d, err := r.resolve()
+	if err != nil {
+		return nil, err
+	}
+	return d.ConvertParamsToMessage(method, params)
+}
+
+func (r *reflectionDescriptor) ConvertReturnMessage(method string, returnVal *dynamic.Message) (interface{}, error) {
+	d, err := r.resolve()
+	if err != nil {
+		return nil, err
+	}
+	return d.ConvertReturnMessage(method, returnVal)
+}
+
+func (r *reflectionDescriptor) MethodDescriptor(method string) *desc.MethodDescriptor {
+	d, err := r.resolve()
+	if err != nil {
+		return nil
+	}
+	return d.MethodDescriptor(method)
+}
+
+func (r *reflectionDescriptor) MessageFactory() *dynamic.MessageFactory {
+	return r.mf
+}
+
+// reflectServices fetches all the service descriptors except the reflection service itself from the grpc server
+func reflectServices(addr string) (reflectedServices, error) {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url %s", addr)
+	}
+	conn, err := grpc.NewClient(u.Host, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("connect to %s error: %v", addr, err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), reflectionTimeout)
+	defer cancel()
+	client := grpcreflect.NewClientAuto(ctx, conn)
+	defer client.Reset()
+	names, err := client.ListServices()
+	if err != nil {
+		return nil, fmt.Errorf("fail to list services by reflection from %s: %v", addr, err)
+	}
+	sort.Strings(names)
+	var result reflectedServices
+	for _, name := range names {
+		if strings.HasPrefix(name, "grpc.reflection.") {
+			continue
+		}
+		sd, err := client.ResolveService(name)
+		if err != nil {
+			return nil, fmt.Errorf("fail to resolve service %s by reflection: %v", name, err)
+		}
+		result = append(result, sd)
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no service found by reflection from %s", addr)
+	}
+	return result, nil
+}
@@ -32,7 +32,7 @@ func init() {
 	}
 	descriptors = make([]descriptor, len(schemas))
 	for i, sch := range schemas {
-		d, err := parse(sch.SchemaType, sch.SchemaFile, sch.Schemaless)
+		d, err := parse(sch.SchemaType, sch.SchemaFile, sch.Schemaless, "")
 		if err != nil {
 			panic(err)
 		}
@@ -343,7 +343,7 @@ func TestSchemalessConvertParams(t *testing.T) {
 		},
 	}
 
-	d, err := parse(SCHEMALESS, "", true)
+	d, err := parse(SCHEMALESS, "", true, "")
 	if err != nil {
 		panic(err)
 	}
@@ -409,7 +409,7 @@ func TestSchemalessConvertReturns(t *testing.T) {
 		},
 	}
 
-	d, err := parse(SCHEMALESS, "", true)
+	d, err := parse(SCHEMALESS, "", true, "")
 	if err != nil {
 		panic(err)
 	}
@@ -106,6 +106,7 @@ type Coordinator struct {
 	toBeClean               int
 	tasksToTrigger          []Responder
 	tasksToWaitFor          []Responder
+	sourceTasks             []StreamTask
 	sinkTasks               []SinkTask
 	pendingCheckpoints      *sync.Map
 	completedCheckpoints    *checkpointStore
@@ -155,6 +156,7 @@ func NewCoordinator(ruleId string, sources []StreamTask, operators []NonSourceTa
 	return &Coordinator{
 		tasksToTrigger:     sourceResponders,
 		tasksToWaitFor:     allResponders,
+		sourceTasks:        sources,
 		sinkTasks:          sinks,
 		pendingCheckpoints: new(sync.Map),
 		completedCheckpoints: &checkpointStore{
@@ -327,6 +329,11 @@ func (c *Coordinator) complete(checkpointId int64) {
 			}
 			return true
 		})
+		for _, t := range c.sourceTasks {
+			if l, ok := t.(CheckpointListener); ok {
+				l.CheckpointCompleted(checkpointId)
+			}
+		}
 		logger.Debugf("Totally complete checkpoint %d", checkpointId)
 	} else {
 		logger.Infof("Cannot find checkpoint %d to complete", checkpointId)
@@ -26,6 +26,13 @@ type StreamTask interface {
 	SetQos(qos def.Qos)
 }
 
+// CheckpointListener is a source task which needs to know the checkpoint lifecycle
+type CheckpointListener interface {
+	// CheckpointTriggered is called before broadcasting the barrier
+	CheckpointTriggered(checkpointId int64)
+	CheckpointCompleted(checkpointId int64)
+}
+
 type NonSinkTask interface {
 	Broadcast(data any)
 }
@@ -55,6 +55,9 @@ func (re *ResponderExecutor) TriggerCheckpoint(checkpointId int64) error {
 		CheckpointId: checkpointId,
 		OpId:         name,
 	}
+	if l, ok := re.task.(CheckpointListener); ok {
+		l.CheckpointTriggered(checkpointId)
+	}
 	// broadcast barrier
 	if nonSink, ok := re.task.(NonSinkTask); ok {
 		nonSink.Broadcast(barrier)
@@ -93,6 +93,10 @@ func GetSourceConf(sourceType string, options *ast.Options) map[string]interface
 	props["strictValidation"] = options.STRICT_VALIDATION
 	props["timestamp"] = options.TIMESTAMP
 	props["timestampFormat"] = options.TIMESTAMP_FORMAT
+	// The source may provide the metadata for the keyed watermark only when it is needed
+	if options.WATERMARK_KEY != "" {
+		props["watermarkKey"] = options.WATERMARK_KEY
+	}
 	conf.Log.Infof("get conf for %s with conf key %s: %v", sourceType, confkey, printable(props))
 	return props
 }
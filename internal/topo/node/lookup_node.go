@@ -258,6 +258,15 @@ func (n *LookupNode) lookup(ctx api.StreamContext, d xsql.Row, fv *xsql.Function
 	if e != nil {
 		return e
 	} else {
+		switch n.joinType {
+		case ast.SEMI_JOIN, ast.ANTI_JOIN:
+			if (len(r) > 0) == (n.joinType == ast.SEMI_JOIN) {
+				merged := &xsql.JoinTuple{}
+				merged.AddTuple(d)
+				tuples.Content = append(tuples.Content, merged)
+			}
+			return nil
+		}
 		if len(r) == 0 {
 			if n.joinType == ast.LEFT_JOIN {
 				merged := &xsql.JoinTuple{}
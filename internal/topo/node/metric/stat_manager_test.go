@@ -36,3 +36,21 @@ func TestGetMetrics(t *testing.T) {
 	assert.NotEqual(t, "", a[5])
 	assert.Equal(t, e[6:], a[6:])
 }
+
+func TestLatencyPercentiles(t *testing.T) {
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	sm := NewStatManager(ctx, "op")
+	lp, ok := sm.(LatencyPercentiles)
+	assert.True(t, ok)
+	assert.Equal(t, []int64{0, 0}, lp.GetLatencyPercentiles(50, 99))
+	dsm := sm.(*DefaultStatManager)
+	for i := 1; i <= 100; i++ {
+		dsm.recordLatency(int64(i))
+	}
+	assert.Equal(t, []int64{1, 50, 90, 99, 100}, lp.GetLatencyPercentiles(0, 50, 90, 99, 100))
+	// Only the recent latencies are kept
+	for i := 0; i < LatencySamples; i++ {
+		dsm.recordLatency(1000)
+	}
+	assert.Equal(t, []int64{1000}, lp.GetLatencyPercentiles(50))
+}
@@ -15,6 +15,8 @@
 package metric
 
 import (
+	"math"
+	"slices"
 	"time"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
@@ -42,6 +44,15 @@ const (
 
 var MetricNames = []string{RecordsInTotal, RecordsOutTotal, MessagesProcessedTotal, ProcessLatencyUs, BufferLength, LastInvocation, ExceptionsTotal, LastException, LastExceptionTime, ConnectionStatus, ConnectionLastConnectedTime, ConnectionLastDisconnectedTime, ConnectionLastDisconnectedMessage, ConnectionLastTryTime}
 
+// LatencySamples is the number of the recent process latencies kept to calculate the percentiles
+const LatencySamples = 256
+
+// LatencyPercentiles is implemented by the stat managers which keep the recent process latencies
+type LatencyPercentiles interface {
+	// GetLatencyPercentiles returns the percentiles (0-100) of the recent process latencies in microseconds
+	GetLatencyPercentiles(ps ...float64) []int64
+}
+
 type StatManager interface {
 	IncTotalRecordsIn()
 	IncTotalRecordsOut()
@@ -71,6 +82,9 @@ type DefaultStatManager struct {
 	totalExceptions   int64
 	lastException     string
 	lastExceptionTime time.Time
+	// latencies is a ring of the recent process latencies
+	latencies    [LatencySamples]int64
+	latencyCount int
 
 	connectionState *ConnectionStatManager
 	// configs
@@ -168,9 +182,34 @@ func (sm *DefaultStatManager) ProcessTimeEnd() {
 	defer sm.Unlock()
 	if !sm.processTimeStart.IsZero() {
 		sm.processLatency = int64(time.Since(sm.processTimeStart) / time.Microsecond)
+		sm.recordLatency(sm.processLatency)
 	}
 }
 
+func (sm *DefaultStatManager) recordLatency(l int64) {
+	sm.latencies[sm.latencyCount%LatencySamples] = l
+	sm.latencyCount++
+}
+
+func (sm *DefaultStatManager) GetLatencyPercentiles(ps ...float64) []int64 {
+	sm.RLock()
+	n := min(sm.latencyCount, LatencySamples)
+	samples := make([]int64, n)
+	copy(samples, sm.latencies[:n])
+	sm.RUnlock()
+	result := make([]int64, len(ps))
+	if n == 0 {
+		return result
+	}
+	slices.Sort(samples)
+	for i, p := range ps {
+		// nearest rank
+		r := int(math.Ceil(p/100*float64(n))) - 1
+		result[i] = samples[max(0, min(r, n-1))]
+	}
+	return result
+}
+
 func (sm *DefaultStatManager) SetBufferLength(l int64) {
 	sm.Lock()
 	defer sm.Unlock()
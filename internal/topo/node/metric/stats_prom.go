@@ -108,6 +108,7 @@ func (sm *PrometheusStatManager) ProcessTimeEnd() {
 	defer sm.Unlock()
 	if !sm.processTimeStart.IsZero() {
 		sm.processLatency = int64(time.Since(sm.processTimeStart) / time.Microsecond)
+		sm.recordLatency(sm.processLatency)
 		sm.pProcessLatency.Set(float64(sm.processLatency))
 		sm.pProcessLatencyHist.Observe(float64(sm.processLatency))
 	}
@@ -127,6 +127,17 @@ func (o *defaultNode) GetMetrics() []any {
 	return nil
 }
 
+// GetLatencyPercentiles returns the percentiles of the recent process latencies in microseconds.
+// It returns nil if the stat manager does not keep the latencies.
+func (o *defaultNode) GetLatencyPercentiles(ps ...float64) []int64 {
+	o.metricMu.RLock()
+	defer o.metricMu.RUnlock()
+	if lp, ok := o.statManager.(metric.LatencyPercentiles); ok {
+		return lp.GetLatencyPercentiles(ps...)
+	}
+	return nil
+}
+
 func (o *defaultNode) RemoveMetrics(ruleId string) {
 	o.metricMu.RLock()
 	defer o.metricMu.RUnlock()
@@ -253,6 +264,11 @@ func (o *defaultSinkNode) GetInputCount() int {
 	return o.inputCount
 }
 
+// GetBufferCapacity returns the capacity of the input buffer
+func (o *defaultSinkNode) GetBufferCapacity() int {
+	return cap(o.input)
+}
+
 func (o *defaultSinkNode) AddInputCount() {
 	o.inputCount++
 }
@@ -21,9 +21,12 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/lf-edge/ekuiper/v2/internal/binder"
+	"github.com/lf-edge/ekuiper/v2/internal/binder/function"
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
+	"github.com/lf-edge/ekuiper/v2/internal/plugin/js"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/node"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/planner"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
@@ -195,6 +198,88 @@ func TestIncEventHoppingWindow(t *testing.T) {
 	op.Close()
 }
 
+func TestIncEventHoppingWindowMergePanes(t *testing.T) {
+	conf.IsTesting = true
+	require.NoError(t, js.InitManager())
+	require.NoError(t, function.Initialize([]binder.FactoryEntry{{Name: "js", Factory: js.GetManager()}}))
+	require.NoError(t, js.GetManager().Create(&js.Script{
+		Id: "pane_avg",
+		Script: `var pane_avg = {
+  init: function() { return {sum: 0, count: 0, panes: 0}; },
+  accumulate: function(acc, v) { acc.sum += v; acc.count++; return acc; },
+  merge: function(a, b) { return {sum: a.sum + b.sum, count: a.count + b.count, panes: a.panes + 1}; },
+  result: function(acc) { return {avg: acc.sum / acc.count, panes: acc.panes}; }
+};`,
+		IsAgg: true,
+	}))
+	defer func() {
+		require.NoError(t, js.GetManager().Delete("pane_avg"))
+	}()
+	o := &def.RuleOption{
+		PlanOptimizeStrategy: &def.PlanOptimizeStrategy{
+			EnableIncrementalWindow: true,
+		},
+		IsEventTime:  true,
+		Qos:          0,
+		BufferLength: 10,
+	}
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	require.NoError(t, prepareStream())
+	sql := "select pane_avg(a) from stream group by hoppingWindow(ss,2,1)"
+	stmt, err := xsql.NewParser(strings.NewReader(sql)).Parse()
+	require.NoError(t, err)
+	p, err := planner.CreateLogicalPlan(stmt, &def.RuleOption{
+		PlanOptimizeStrategy: &def.PlanOptimizeStrategy{
+			EnableIncrementalWindow: true,
+		},
+		Qos: 0,
+	}, kv)
+	require.NoError(t, err)
+	require.NotNil(t, p)
+	incPlan := extractIncWindowPlan(p)
+	require.NotNil(t, incPlan)
+	op, err := node.NewWindowIncAggOp("1", &node.WindowConfig{
+		Type:        incPlan.WType,
+		Length:      2 * time.Second,
+		Interval:    time.Second,
+		RawInterval: 1,
+		TimeUnit:    ast.SS,
+	}, incPlan.Dimensions, incPlan.IncAggFuncs, o)
+	require.NoError(t, err)
+	require.NotNil(t, op)
+	input, _ := op.GetInput()
+	output := make(chan any, 10)
+	op.AddOutput(output, "output")
+	errCh := make(chan error, 10)
+	ctx, cancel := mockContext.NewMockContext("1", "2").WithCancel()
+	op.Exec(ctx, errCh)
+	time.Sleep(10 * time.Millisecond)
+	// each row is only accumulated in the pane of its interval and the windows merge the panes
+	now := time.Time{}.Add(3100 * time.Millisecond)
+	input <- &xsql.Tuple{Message: map[string]any{"a": int64(1)}, Timestamp: now}
+	input <- &xsql.Tuple{Message: map[string]any{"a": int64(2)}, Timestamp: now.Add(time.Second)}
+	input <- &xsql.Tuple{Message: map[string]any{"a": int64(6)}, Timestamp: now.Add(1400 * time.Millisecond)}
+	input <- &xsql.WatermarkTuple{Timestamp: now.Add(5 * time.Second)}
+	for _, exp := range []map[string]any{
+		{"avg": int64(3), "panes": int64(2)},
+		{"avg": int64(4), "panes": int64(1)},
+	} {
+		got := <-output
+		wt, ok := got.(*xsql.WindowTuples)
+		require.True(t, ok)
+		require.Equal(t, []map[string]any{
+			{
+				"a":             int64(6),
+				"inc_agg_col_1": exp,
+			},
+		}, wt.ToMaps())
+	}
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	op.Close()
+}
+
 func TestIncEventSlidingWindow(t *testing.T) {
 	conf.IsTesting = true
 	o := &def.RuleOption{
@@ -30,6 +30,11 @@ type UnOperation interface {
 	Apply(ctx api.StreamContext, data interface{}, fv *xsql.FunctionValuer, afv *xsql.AggregateFunctionValuer) interface{}
 }
 
+// RuntimeDetail is implemented by the operations which expose their runtime state to explain the running rule
+type RuntimeDetail interface {
+	GetRuntimeDetail() map[string]any
+}
+
 // UnFunc implements UnOperation as type func (context.Context, interface{})
 type UnFunc func(api.StreamContext, interface{}) interface{}
 
@@ -56,6 +61,14 @@ func (o *UnaryOperator) SetOperation(op UnOperation) {
 	o.op = op
 }
 
+// GetRuntimeDetail returns the runtime state of the operation if it is supported
+func (o *UnaryOperator) GetRuntimeDetail() map[string]any {
+	if rd, ok := o.op.(RuntimeDetail); ok {
+		return rd.GetRuntimeDetail()
+	}
+	return nil
+}
+
 // Exec is the entry point for the executor
 func (o *UnaryOperator) Exec(ctx api.StreamContext, errCh chan<- error) {
 	o.prepareExec(ctx, errCh, "op")
@@ -25,6 +25,7 @@ import (
 	"github.com/lf-edge/ekuiper/v2/internal/io/memory/pubsub"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/sig"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/checkpoint"
 	topoContext "github.com/lf-edge/ekuiper/v2/internal/topo/context"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/node/tracenode"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
@@ -253,6 +254,18 @@ func (m *SourceNode) updateState(ctx api.StreamContext) error {
 	return nil
 }
 
+func (m *SourceNode) CheckpointTriggered(checkpointId int64) {
+	if ca, ok := m.s.(model.CheckpointAware); ok {
+		ca.OnCheckpoint(m.ctx, checkpointId)
+	}
+}
+
+func (m *SourceNode) CheckpointCompleted(checkpointId int64) {
+	if ca, ok := m.s.(model.CheckpointAware); ok {
+		ca.OnCheckpointComplete(m.ctx, checkpointId)
+	}
+}
+
 // Run Subscribe could be a long-running function
 func (m *SourceNode) Run(ctx api.StreamContext, ctrlCh chan<- error) {
 	defer func() {
@@ -271,6 +284,9 @@ func (m *SourceNode) Run(ctx api.StreamContext, ctrlCh chan<- error) {
 		if err := m.Rewind(ctx); err != nil {
 			return err
 		}
+		if ca, ok := m.s.(model.CheckpointAware); ok && m.qos >= def.AtLeastOnce {
+			ca.EnableCheckpoint(ctx)
+		}
 		switch ss := m.s.(type) {
 		case api.BytesSource:
 			err = ss.Subscribe(ctx, m.ingestBytes, m.ingestError)
@@ -330,3 +346,5 @@ func (m *SourceNode) doPull(ctx api.StreamContext, tc time.Time) error {
 		return nil
 	})
 }
+
+var _ checkpoint.CheckpointListener = &SourceNode{}
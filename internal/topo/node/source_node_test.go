@@ -434,3 +434,63 @@ func TestMockRewind(t *testing.T) {
 	v, _ := ctx.GetState(OffsetKey)
 	require.Equal(t, 11, v)
 }
+
+type MockCheckpointSource struct {
+	MockRewindSource
+	enabled   atomic.Bool
+	triggered atomic.Int64
+	completed atomic.Int64
+}
+
+func (m *MockCheckpointSource) EnableCheckpoint(_ api.StreamContext) {
+	m.enabled.Store(true)
+}
+
+func (m *MockCheckpointSource) OnCheckpoint(_ api.StreamContext, checkpointId int64) {
+	m.triggered.Store(checkpointId)
+}
+
+func (m *MockCheckpointSource) OnCheckpointComplete(_ api.StreamContext, checkpointId int64) {
+	m.completed.Store(checkpointId)
+}
+
+func TestCheckpointAware(t *testing.T) {
+	tests := []struct {
+		name    string
+		qos     def.Qos
+		enabled bool
+	}{
+		{
+			name:    "at most once",
+			qos:     def.AtMostOnce,
+			enabled: false,
+		},
+		{
+			name:    "at least once",
+			qos:     def.AtLeastOnce,
+			enabled: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MockCheckpointSource{MockRewindSource: MockRewindSource{notify: make(chan struct{})}}
+			ctx, cancel := mockContext.NewMockContext("rule1", "src1").WithCancel()
+			defer cancel()
+			scn, err := NewSourceNode(ctx, "mock_connector", m, map[string]any{"datasource": "demo"}, &def.RuleOption{
+				BufferLength: 1024,
+				SendError:    true,
+			})
+			require.NoError(t, err)
+			scn.SetQos(tt.qos)
+			require.NoError(t, scn.AddOutput(make(chan any, 10), "testResult"))
+			scn.Open(ctx, make(chan error, 1))
+			// The checkpoint is enabled before subscribe
+			m.notify <- struct{}{}
+			require.Equal(t, tt.enabled, m.enabled.Load())
+			scn.CheckpointTriggered(1)
+			scn.CheckpointCompleted(1)
+			require.Equal(t, int64(1), m.triggered.Load())
+			require.Equal(t, int64(1), m.completed.Load())
+		})
+	}
+}
@@ -17,6 +17,8 @@ package node
 import (
 	"fmt"
 	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
@@ -28,6 +30,19 @@ import (
 	"github.com/lf-edge/ekuiper/v2/pkg/timex"
 )
 
+// WatermarkStrategy is how to generate the watermark of an input stream
+type WatermarkStrategy struct {
+	// Delay is the max out-of-orderness of the events
+	Delay time.Duration
+	// IdleTimeout is the processing time without events after which the stream or the key is idle.
+	// The idle inputs are excluded from the watermark until they receive events again. The idle keys are removed
+	// and tracked as new keys if they receive events again. 0 means never idle.
+	IdleTimeout time.Duration
+	// Key is the field or metadata name to track the watermark of each key separately, such as the Kafka partition.
+	// The watermark of the stream is the minimum of its keys.
+	Key string
+}
+
 // WatermarkOp is used when event time is enabled.
 // It is used to align the event time of the input streams
 // It sends out the data in time order with watermark.
@@ -36,11 +51,19 @@ type WatermarkOp struct {
 	// config
 	lateTolerance time.Duration
 	sendWatermark bool
+	emitters      []string
+	strategies    map[string]*WatermarkStrategy
+	idleCheck     time.Duration
 	// state
 	events          []*xsql.Tuple // All the cached events in order
 	rowHandle       map[any]trace.Span
-	streamWMs       map[string]time.Time
+	streamWMs       map[string]time.Time // The max event time of each input, which is a stream or a key of a stream
+	lastActive      map[string]time.Time // The processing time of the last event of each input
+	startTime       time.Time
 	lastWatermarkTs time.Time
+	// the watermarks of the inputs for the rule status
+	statusLock sync.RWMutex
+	status     map[string]time.Time
 }
 
 var _ OperatorNode = &WatermarkOp{}
@@ -51,19 +74,38 @@ const (
 	StreamWMKey   = "$$streamwms"
 )
 
-func NewWatermarkOp(name string, sendWatermark bool, streams []string, options *def.RuleOption) *WatermarkOp {
-	wms := make(map[string]time.Time, len(streams))
-	for _, s := range streams {
-		wms[s] = time.Time{}.Add(time.Duration(options.LateTol))
-	}
-	return &WatermarkOp{
+// NewWatermarkOp creates the watermark operator of the streams. The strategies are the watermark strategy of each stream.
+// The streams without strategy use the rule lateTolerance as the delay.
+func NewWatermarkOp(name string, sendWatermark bool, streams []string, strategies map[string]*WatermarkStrategy, options *def.RuleOption) *WatermarkOp {
+	w := &WatermarkOp{
 		defaultSinkNode: newDefaultSinkNode(name, options),
 		lateTolerance:   time.Duration(options.LateTol),
 		sendWatermark:   sendWatermark,
-		streamWMs:       wms,
+		emitters:        streams,
+		strategies:      strategies,
+		streamWMs:       make(map[string]time.Time, len(streams)),
+		lastActive:      make(map[string]time.Time, len(streams)),
 		lastWatermarkTs: time.Time{},
 		rowHandle:       make(map[any]trace.Span),
 	}
+	for _, s := range streams {
+		st := w.strategy(s)
+		// The keyed streams have no watermark until the first event of any key
+		if st.Key == "" {
+			w.streamWMs[s] = time.Time{}.Add(st.Delay)
+		}
+		if st.IdleTimeout > 0 && (w.idleCheck == 0 || st.IdleTimeout < w.idleCheck) {
+			w.idleCheck = st.IdleTimeout
+		}
+	}
+	return w
+}
+
+func (w *WatermarkOp) strategy(emitter string) *WatermarkStrategy {
+	if st, ok := w.strategies[emitter]; ok && st != nil {
+		return st
+	}
+	return &WatermarkStrategy{Delay: w.lateTolerance}
 }
 
 func (w *WatermarkOp) Exec(ctx api.StreamContext, errCh chan<- error) {
@@ -100,6 +142,24 @@ func (w *WatermarkOp) Exec(ctx api.StreamContext, errCh chan<- error) {
 		}
 	}
 
+	// The restored inputs are active since the start
+	w.startTime = timex.GetNow()
+	for id := range w.streamWMs {
+		w.lastActive[id] = w.startTime
+	}
+	w.updateStatus()
+
+	// Check the idle inputs periodically, so that the cached events are sent out when the other inputs go quiet
+	var idleCh <-chan time.Time
+	if w.idleCheck > 0 {
+		ticker := timex.GetTicker(w.idleCheck)
+		idleCh = ticker.C
+		go func() {
+			<-ctx.Done()
+			ticker.Stop()
+		}()
+	}
+
 	ctx.GetLogger().Infof("Start with state lastWatermarkTs: %d", w.lastWatermarkTs.UnixMilli())
 	go func() {
 		defer func() {
@@ -111,6 +171,9 @@ func (w *WatermarkOp) Exec(ctx api.StreamContext, errCh chan<- error) {
 				case <-ctx.Done():
 					ctx.GetLogger().Infof("watermark node %s is finished", w.name)
 					return nil
+				case <-idleCh:
+					w.evictIdleKeys(ctx)
+					w.trigger(ctx)
 				case item := <-w.input:
 					data, processed := w.commonIngest(ctx, item)
 					if processed {
@@ -123,7 +186,7 @@ func (w *WatermarkOp) Exec(ctx api.StreamContext, errCh chan<- error) {
 					switch d := data.(type) {
 					case *xsql.Tuple:
 						// whether to drop the late event
-						if w.track(ctx, d.Emitter, d.Timestamp) {
+						if w.track(ctx, d) {
 							// If not drop, check if it can be sent out
 							w.addAndTrigger(ctx, d)
 						}
@@ -141,17 +204,60 @@ func (w *WatermarkOp) Exec(ctx api.StreamContext, errCh chan<- error) {
 	}()
 }
 
-func (w *WatermarkOp) track(ctx api.StreamContext, emitter string, ts time.Time) bool {
-	ctx.GetLogger().Debugf("watermark generator track event from topic %s at %d", emitter, ts.UnixMilli())
-	watermark, ok := w.streamWMs[emitter]
+func (w *WatermarkOp) track(ctx api.StreamContext, d *xsql.Tuple) bool {
+	ts := d.Timestamp
+	id := w.inputID(d)
+	ctx.GetLogger().Debugf("watermark generator track event from input %s at %d", id, ts.UnixMilli())
+	w.lastActive[id] = timex.GetNow()
+	watermark, ok := w.streamWMs[id]
 	if !ok || ts.After(watermark) {
-		w.streamWMs[emitter] = ts
+		w.streamWMs[id] = ts
+	}
+	// The state refers to the map, so it is only put when a new input is added
+	if !ok {
 		_ = ctx.PutState(StreamWMKey, w.streamWMs)
 	}
 	r := ts.After(w.lastWatermarkTs) || ts.Equal(w.lastWatermarkTs)
 	return r
 }
 
+// inputID is the stream name, or the stream name and the key value like `demo:1` if the stream is tracked by key
+func (w *WatermarkOp) inputID(d *xsql.Tuple) string {
+	st := w.strategy(d.Emitter)
+	if st.Key == "" {
+		return d.Emitter
+	}
+	v, ok := d.Value(st.Key, "")
+	if !ok {
+		v, ok = d.Meta(st.Key, "")
+	}
+	if !ok || v == nil {
+		return d.Emitter
+	}
+	return fmt.Sprintf("%s:%v", d.Emitter, v)
+}
+
+// evictIdleKeys removes the keys of the keyed streams which are idle, so that the state does not grow with
+// the keys which never come back, such as the reassigned partitions
+func (w *WatermarkOp) evictIdleKeys(ctx api.StreamContext) {
+	now := timex.GetNow()
+	evicted := false
+	for id := range w.streamWMs {
+		emitter, _, keyed := strings.Cut(id, ":")
+		st := w.strategy(emitter)
+		if !keyed || st.Key == "" || st.IdleTimeout <= 0 || now.Sub(w.lastActive[id]) < st.IdleTimeout {
+			continue
+		}
+		ctx.GetLogger().Debugf("watermark generator evicts the idle input %s", id)
+		delete(w.streamWMs, id)
+		delete(w.lastActive, id)
+		evicted = true
+	}
+	if evicted {
+		_ = ctx.PutState(StreamWMKey, w.streamWMs)
+	}
+}
+
 // Add an event and check if watermark proceeds
 // If yes, send out all events before the watermark
 func (w *WatermarkOp) addAndTrigger(ctx api.StreamContext, d *xsql.Tuple) {
@@ -166,13 +272,18 @@ func (w *WatermarkOp) addAndTrigger(ctx api.StreamContext, d *xsql.Tuple) {
 		copy(w.events[index+1:], w.events[index:])
 		w.events[index] = d
 	}
+	w.trigger(ctx)
+}
 
+// trigger checks if watermark proceeds and sends out all events before the watermark
+func (w *WatermarkOp) trigger(ctx api.StreamContext) {
 	watermark := w.computeWatermarkTs()
+	w.updateStatus()
 	ctx.GetLogger().Debugf("compute watermark event at %d with last %d", watermark.UnixMilli(), w.lastWatermarkTs.UnixMilli())
 	// Make sure watermark time proceeds
 	if watermark.After(w.lastWatermarkTs) {
 		// Send out all events before the watermark
-		if watermark.After(w.events[0].Timestamp) || watermark.Equal(w.events[0].Timestamp) {
+		if len(w.events) > 0 && (watermark.After(w.events[0].Timestamp) || watermark.Equal(w.events[0].Timestamp)) {
 			// Find out the last event to send in this watermark change
 			c := len(w.events)
 			for i, e := range w.events {
@@ -213,13 +324,62 @@ func (w *WatermarkOp) addAndTrigger(ctx api.StreamContext, d *xsql.Tuple) {
 	}
 }
 
-// watermark is the minimum timestamp of all input topics
+// computeWatermarkTs returns the minimum watermark of all active inputs. The watermark of an input is its max event time minus its delay.
+// If all inputs are idle, the watermark does not proceed.
 func (w *WatermarkOp) computeWatermarkTs() time.Time {
+	now := timex.GetNow()
 	ts := timex.Maxtime
-	for _, wm := range w.streamWMs {
-		if ts.After(wm) {
+	hasActive := false
+	tracked := make(map[string]bool, len(w.emitters))
+	for id, maxTs := range w.streamWMs {
+		emitter, _, _ := strings.Cut(id, ":")
+		tracked[emitter] = true
+		st := w.strategy(emitter)
+		if st.IdleTimeout > 0 && now.Sub(w.lastActive[id]) >= st.IdleTimeout {
+			continue
+		}
+		hasActive = true
+		if wm := maxTs.Add(-st.Delay); ts.After(wm) {
 			ts = wm
 		}
 	}
-	return ts.Add(-w.lateTolerance)
+	// The keyed streams without any event block the watermark until they are idle
+	for _, emitter := range w.emitters {
+		if tracked[emitter] {
+			continue
+		}
+		st := w.strategy(emitter)
+		if st.IdleTimeout > 0 && now.Sub(w.startTime) >= st.IdleTimeout {
+			continue
+		}
+		hasActive = true
+		ts = time.Time{}
+	}
+	if !hasActive {
+		return w.lastWatermarkTs
+	}
+	return ts
+}
+
+// updateStatus saves the current watermark of each input for the rule status
+func (w *WatermarkOp) updateStatus() {
+	status := make(map[string]time.Time, len(w.streamWMs))
+	for id, maxTs := range w.streamWMs {
+		emitter, _, _ := strings.Cut(id, ":")
+		status[id] = maxTs.Add(-w.strategy(emitter).Delay)
+	}
+	w.statusLock.Lock()
+	w.status = status
+	w.statusLock.Unlock()
+}
+
+// GetWatermarks returns the current watermark in milliseconds of each input, which is a stream or a key of a stream
+func (w *WatermarkOp) GetWatermarks() map[string]int64 {
+	w.statusLock.RLock()
+	defer w.statusLock.RUnlock()
+	result := make(map[string]int64, len(w.status))
+	for id, wm := range w.status {
+		result[id] = wm.UnixMilli()
+	}
+	return result
 }
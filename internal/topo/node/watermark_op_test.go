@@ -27,6 +27,7 @@ import (
 	"github.com/lf-edge/ekuiper/v2/internal/topo/state"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
 )
 
 func TestSingleStreamWatermark(t *testing.T) {
@@ -202,7 +203,7 @@ func TestSingleStreamWatermark(t *testing.T) {
 			ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
 			tempStore, _ := state.CreateStore("TestWatermark", def.AtMostOnce)
 			nctx := ctx.WithMeta("TestWatermark", "test", tempStore)
-			w := NewWatermarkOp("mock", false, []string{"demo"}, &def.RuleOption{
+			w := NewWatermarkOp("mock", false, []string{"demo"}, nil, &def.RuleOption{
 				IsEventTime:    true,
 				LateTol:        cast.DurationConf(tt.latetol),
 				Concurrency:    0,
@@ -453,7 +454,7 @@ func TestMultiStreamWatermark(t *testing.T) {
 			ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
 			tempStore, _ := state.CreateStore("TestWatermark", def.AtMostOnce)
 			nctx := ctx.WithMeta("TestWatermark", "test", tempStore)
-			w := NewWatermarkOp("mock", true, []string{"demo1", "demo2"}, &def.RuleOption{
+			w := NewWatermarkOp("mock", true, []string{"demo1", "demo2"}, nil, &def.RuleOption{
 				IsEventTime:        true,
 				LateTol:            cast.DurationConf(tt.latetol),
 				Concurrency:        0,
@@ -507,3 +508,117 @@ func TestMultiStreamWatermark(t *testing.T) {
 		})
 	}
 }
+
+func TestWatermarkStrategy(t *testing.T) {
+	type step struct {
+		advance time.Duration
+		input   *xsql.Tuple
+		outputs []any
+	}
+	tuple := func(emitter string, partition int, ts int64) *xsql.Tuple {
+		return &xsql.Tuple{
+			Emitter:   emitter,
+			Message:   map[string]interface{}{"a": ts},
+			Metadata:  xsql.Metadata{"partition": partition},
+			Timestamp: time.UnixMilli(ts),
+		}
+	}
+	tests := []struct {
+		name       string
+		streams    []string
+		strategies map[string]*WatermarkStrategy
+		steps      []step
+		watermarks map[string]int64
+	}{
+		{
+			name:    "idle stream",
+			streams: []string{"demo1", "demo2"},
+			strategies: map[string]*WatermarkStrategy{
+				"demo2": {IdleTimeout: time.Second},
+			},
+			steps: []step{
+				{input: tuple("demo1", 0, 10)},
+				{advance: time.Second, outputs: []any{tuple("demo1", 0, 10), &xsql.WatermarkTuple{Timestamp: time.UnixMilli(10)}}},
+				// late event of the idle stream is dropped
+				{input: tuple("demo2", 0, 5)},
+				{input: tuple("demo2", 0, 20)},
+				{input: tuple("demo1", 0, 30), outputs: []any{tuple("demo2", 0, 20), &xsql.WatermarkTuple{Timestamp: time.UnixMilli(20)}}},
+			},
+			watermarks: map[string]int64{"demo1": 30, "demo2": 20},
+		},
+		{
+			name:    "partition watermark with delay",
+			streams: []string{"demo"},
+			strategies: map[string]*WatermarkStrategy{
+				"demo": {Delay: 2 * time.Millisecond, Key: "partition"},
+			},
+			steps: []step{
+				{input: tuple("demo", 0, 10), outputs: []any{&xsql.WatermarkTuple{Timestamp: time.UnixMilli(8)}}},
+				{input: tuple("demo", 1, 12)},
+				{input: tuple("demo", 0, 20), outputs: []any{tuple("demo", 0, 10), &xsql.WatermarkTuple{Timestamp: time.UnixMilli(10)}}},
+				{input: tuple("demo", 1, 30), outputs: []any{tuple("demo", 1, 12), &xsql.WatermarkTuple{Timestamp: time.UnixMilli(18)}}},
+			},
+			watermarks: map[string]int64{"demo:0": 18, "demo:1": 28},
+		},
+		{
+			name:    "evict idle partition",
+			streams: []string{"demo"},
+			strategies: map[string]*WatermarkStrategy{
+				"demo": {IdleTimeout: time.Second, Key: "partition"},
+			},
+			steps: []step{
+				{input: tuple("demo", 0, 10), outputs: []any{tuple("demo", 0, 10), &xsql.WatermarkTuple{Timestamp: time.UnixMilli(10)}}},
+				// the idle partition is removed
+				{advance: time.Second},
+				{input: tuple("demo", 1, 20), outputs: []any{tuple("demo", 1, 20), &xsql.WatermarkTuple{Timestamp: time.UnixMilli(20)}}},
+			},
+			watermarks: map[string]int64{"demo:1": 20},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timex.Set(0)
+			contextLogger := conf.Log.WithField("rule", "TestWatermarkStrategy")
+			ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+			tempStore, _ := state.CreateStore("TestWatermarkStrategy", def.AtMostOnce)
+			nctx, cancel := ctx.WithMeta("TestWatermarkStrategy", "test", tempStore).WithCancel()
+			defer cancel()
+			w := NewWatermarkOp("mock", true, tt.streams, tt.strategies, &def.RuleOption{
+				IsEventTime: true,
+			})
+			errCh := make(chan error)
+			outputCh := make(chan interface{}, 50)
+			require.NoError(t, w.AddOutput(outputCh, "mock"))
+			w.Exec(nctx, errCh)
+			for i, s := range tt.steps {
+				if s.advance > 0 {
+					timex.Add(s.advance)
+				}
+				if s.input != nil {
+					w.input <- s.input
+				}
+				var result []any
+				for len(result) < len(s.outputs) {
+					select {
+					case err := <-errCh:
+						t.Fatal(err)
+					case outval := <-outputCh:
+						result = append(result, outval)
+					case <-time.After(5 * time.Second):
+						t.Fatalf("step %d receive timeout", i)
+					}
+				}
+				assert.Equal(t, s.outputs, result, "step %d", i)
+			}
+			// wait for the last input to be processed
+			require.Eventually(t, func() bool {
+				return assert.ObjectsAreEqual(tt.watermarks, w.GetWatermarks())
+			}, 5*time.Second, 10*time.Millisecond)
+			select {
+			case outval := <-outputCh:
+				t.Fatalf("unexpected output %v", outval)
+			default:
+			}
+		})
+	}
+}
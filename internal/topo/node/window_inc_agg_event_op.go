@@ -108,6 +108,12 @@ func (ho *HoppingWindowIncAggEventOp) exec(ctx api.StreamContext, errCh chan<- e
 
 func (ho *HoppingWindowIncAggEventOp) calIncAggWindow(ctx api.StreamContext, fv *xsql.FunctionValuer, row *xsql.Tuple, now time.Time) {
 	name := calDimension(fv, ho.op.Dimensions, row)
+	if ho.op.paneMergeFuncs != nil {
+		if pane := latestPane(ho.CurrWindowList, ho.op.Length, now); pane != nil {
+			incAggCal(ctx, name, row, pane, ho.op.aggFields)
+		}
+		return
+	}
 	for _, incWindow := range ho.CurrWindowList {
 		if incWindow.StartTime.Compare(now) <= 0 && incWindow.StartTime.Add(ho.op.Length).After(now) {
 			incAggCal(ctx, name, row, incWindow, ho.op.aggFields)
@@ -118,7 +124,7 @@ func (ho *HoppingWindowIncAggEventOp) calIncAggWindow(ctx api.StreamContext, fv
 func (ho *HoppingWindowIncAggEventOp) emitWindow(ctx api.StreamContext, errCh chan<- error, now time.Time) {
 	for _, incWindow := range ho.CurrWindowList {
 		if incWindow.StartTime.Add(ho.op.Length).Compare(now) <= 0 {
-			ho.op.emit(ctx, errCh, incWindow, incWindow.StartTime.Add(ho.op.Length))
+			ho.op.emit(ctx, errCh, ho.op.mergePanes(ctx, incWindow, ho.CurrWindowList), incWindow.StartTime.Add(ho.op.Length))
 		}
 	}
 }
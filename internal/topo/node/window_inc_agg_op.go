@@ -24,6 +24,7 @@ import (
 	"github.com/benbjohnson/clock"
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
+	"github.com/lf-edge/ekuiper/v2/internal/binder/function"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	topoContext "github.com/lf-edge/ekuiper/v2/internal/topo/context"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/state"
@@ -696,9 +697,18 @@ type HoppingWindowIncAggOp struct {
 	Length     time.Duration
 	Interval   time.Duration
 	taskCh     chan *IncAggOpTask
+	// paneMergeFuncs are set if the partial states of all the aggregate functions can be merged.
+	// Then each row is only calculated in the latest window as a pane and the window merges the states of its panes when emitting.
+	paneMergeFuncs []*paneMergeFunc
 	HoppingWindowIncAggOpState
 }
 
+type paneMergeFunc struct {
+	funcId  int
+	colName string
+	f       function.IncAggMergeFunction
+}
+
 type HoppingWindowIncAggOpState struct {
 	CurrWindowList []*IncAggWindow
 }
@@ -711,9 +721,37 @@ func NewHoppingWindowIncAggOp(o *WindowIncAggOperator) *HoppingWindowIncAggOp {
 		taskCh:               make(chan *IncAggOpTask, 1024),
 	}
 	op.HoppingWindowIncAggOpState.CurrWindowList = make([]*IncAggWindow, 0)
+	if o.windowConfig.Type == ast.HOPPING_WINDOW && op.Length > op.Interval && op.Length%op.Interval == 0 {
+		op.paneMergeFuncs = newPaneMergeFuncs(o.aggFields)
+	}
 	return op
 }
 
+// newPaneMergeFuncs returns nil if any aggregate function cannot merge the partial states
+func newPaneMergeFuncs(aggFields []*ast.Field) []*paneMergeFunc {
+	if len(aggFields) == 0 {
+		return nil
+	}
+	result := make([]*paneMergeFunc, 0, len(aggFields))
+	for _, aggField := range aggFields {
+		c, ok := aggField.Expr.(*ast.Call)
+		if !ok || !function.IsSupportedIncAggMerge(c.Name) {
+			return nil
+		}
+		nf, _ := function.Function(c.Name)
+		mf, ok := nf.(function.IncAggMergeFunction)
+		if !ok || !mf.IsMergeable() {
+			return nil
+		}
+		colName := aggField.Name
+		if len(aggField.AName) > 0 {
+			colName = aggField.AName
+		}
+		result = append(result, &paneMergeFunc{funcId: c.FuncId, colName: colName, f: mf})
+	}
+	return result
+}
+
 func (ho *HoppingWindowIncAggOp) PutState(ctx api.StreamContext) {
 	for index, window := range ho.CurrWindowList {
 		window.GenerateAllFunctionState()
@@ -788,7 +826,7 @@ func (ho *HoppingWindowIncAggOp) exec(ctx api.StreamContext, errCh chan<- error)
 			done <- ho.RestoreFromState(ctx)
 		case task := <-ho.taskCh:
 			now := timex.GetNow()
-			ho.emit(ctx, errCh, task.window, now)
+			ho.emit(ctx, errCh, ho.mergePanes(ctx, task.window, ho.CurrWindowList), now)
 			ho.CurrWindowList = gcIncAggWindow(ho.CurrWindowList, ho.Length, now)
 			ho.PutState(ctx)
 		case input := <-ho.input:
@@ -864,8 +902,61 @@ func (ho *HoppingWindowIncAggOp) emit(ctx api.StreamContext, errCh chan<- error,
 	ho.onSend(ctx, results)
 }
 
+// mergePanes calculates the window by merging the states of the panes which start in the window.
+// The window itself is the first pane. It may have been removed from the list when emitting.
+func (ho *HoppingWindowIncAggOp) mergePanes(ctx api.StreamContext, window *IncAggWindow, panes []*IncAggWindow) *IncAggWindow {
+	if ho.paneMergeFuncs == nil {
+		return window
+	}
+	end := window.StartTime.Add(ho.Length)
+	dimRanges := make(map[string][]*IncAggRange)
+	for dim, r := range window.DimensionsIncAggRange {
+		dimRanges[dim] = append(dimRanges[dim], r)
+	}
+	for _, pane := range panes {
+		if pane.StartTime.After(window.StartTime) && pane.StartTime.Before(end) {
+			for dim, r := range pane.DimensionsIncAggRange {
+				dimRanges[dim] = append(dimRanges[dim], r)
+			}
+		}
+	}
+	result := newIncAggWindow(ctx, window.StartTime)
+	for dim, ranges := range dimRanges {
+		merged := newIncAggRange(ctx)
+		merged.LastRow = ranges[len(ranges)-1].LastRow.Clone().(*xsql.Tuple)
+		for _, mf := range ho.paneMergeFuncs {
+			partials := make([]api.FunctionContext, 0, len(ranges))
+			for _, r := range ranges {
+				partials = append(partials, topoContext.NewDefaultFuncContext(r.fctx, mf.funcId))
+			}
+			merged.Fields[mf.colName], _ = mf.f.MergeIncremental(topoContext.NewDefaultFuncContext(merged.fctx, mf.funcId), partials)
+		}
+		result.DimensionsIncAggRange[dim] = merged
+	}
+	return result
+}
+
+// latestPane returns the latest window which covers now
+func latestPane(windows []*IncAggWindow, length time.Duration, now time.Time) *IncAggWindow {
+	for i := len(windows) - 1; i >= 0; i-- {
+		if windows[i].StartTime.Compare(now) <= 0 {
+			if windows[i].StartTime.Add(length).After(now) {
+				return windows[i]
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
 func (ho *HoppingWindowIncAggOp) calIncAggWindow(ctx api.StreamContext, fv *xsql.FunctionValuer, row *xsql.Tuple, now time.Time) {
 	name := calDimension(fv, ho.Dimensions, row)
+	if ho.paneMergeFuncs != nil {
+		if pane := latestPane(ho.CurrWindowList, ho.Length, now); pane != nil {
+			incAggCal(ctx, name, row, pane, ho.aggFields)
+		}
+		return
+	}
 	for _, incWindow := range ho.CurrWindowList {
 		if incWindow.StartTime.Compare(now) <= 0 && incWindow.StartTime.Add(ho.Length).After(now) {
 			incAggCal(ctx, name, row, incWindow, ho.aggFields)
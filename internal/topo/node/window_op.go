@@ -408,8 +408,9 @@ func (o *WindowOperator) execProcessingWindow(ctx api.StreamContext, inputs []xs
 				case ast.COUNT_WINDOW:
 					o.msgCount++
 					log.Debugf(fmt.Sprintf("msgCount: %d", o.msgCount))
+					// Not triggered yet. Break instead of continue so that the input state is saved and the event is marked as processed
 					if o.msgCount%o.window.CountInterval != 0 {
-						continue
+						break
 					}
 					o.msgCount = 0
 
@@ -22,9 +22,12 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/context"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
 )
 
 var fivet = []xsql.EventRow{
@@ -379,3 +382,40 @@ func TestGCInputsForConditionNotMatch(t *testing.T) {
 		},
 	}, inputs)
 }
+
+// TestCountWindowProgress checks the events which do not trigger the count window are marked as processed and saved
+// in the state
+func TestCountWindowProgress(t *testing.T) {
+	timex.InitClock()
+	op, err := NewWindowOp("w", WindowConfig{Type: ast.COUNT_WINDOW, CountLength: 3}, &def.RuleOption{BufferLength: 10})
+	require.NoError(t, err)
+	ctx, cancel := mockContext.NewMockContext("testCountWindow", "w").WithCancel()
+	defer cancel()
+	out := make(chan any, 10)
+	require.NoError(t, op.AddOutput(out, "output"))
+	op.Exec(ctx, make(chan error, 10))
+	for i := 0; i < 2; i++ {
+		op.input <- &xsql.Tuple{Emitter: "test", Message: map[string]any{"a": i}, Timestamp: time.UnixMilli(int64(i))}
+	}
+	require.Eventually(t, func() bool {
+		mm := op.statManager.GetMetrics()
+		return mm[0] == int64(2) && mm[2] == int64(2)
+	}, time.Second, 10*time.Millisecond)
+	s, err := ctx.GetState(WindowInputsKey)
+	require.NoError(t, err)
+	require.Len(t, s, 2)
+	s, err = ctx.GetState(MsgCountKey)
+	require.NoError(t, err)
+	require.Equal(t, 2, s)
+
+	op.input <- &xsql.Tuple{Emitter: "test", Message: map[string]any{"a": 2}, Timestamp: time.UnixMilli(2)}
+	select {
+	case r := <-out:
+		require.Len(t, r.(*xsql.WindowTuples).Content, 3)
+	case <-time.After(time.Second):
+		require.Fail(t, "timeout")
+	}
+	require.Eventually(t, func() bool {
+		return op.statManager.GetMetrics()[2] == int64(3)
+	}, time.Second, 10*time.Millisecond)
+}
@@ -25,6 +25,9 @@ import (
 
 type AggregateOp struct {
 	Dimensions ast.Dimensions
+	// KeepEmpty emits an empty set with the window range for the empty window so that the
+	// following FILL operator can fill the missing groups
+	KeepEmpty bool
 }
 
 // Apply
@@ -69,7 +72,9 @@ func (p *AggregateOp) Apply(ctx api.StreamContext, data interface{}, fv *xsql.Fu
 				for _, v := range result {
 					g = append(g, v)
 				}
-				grouped = &xsql.GroupedTuplesSet{Groups: g}
+				grouped = &xsql.GroupedTuplesSet{Groups: g, WindowRange: wr}
+			} else if p.KeepEmpty {
+				grouped = &xsql.GroupedTuplesSet{Groups: []*xsql.GroupedTuples{}, WindowRange: wr}
 			} else {
 				grouped = nil
 			}
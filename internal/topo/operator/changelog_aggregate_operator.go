@@ -0,0 +1,310 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"encoding/gob"
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+const changelogAggregateStateKey = "changelogAggregate"
+
+func init() {
+	gob.Register(&xsql.Tuple{})
+	gob.Register(&xsql.JoinTuple{})
+	gob.Register(&lockedState[changelogAggregateState]{})
+}
+
+// ChangelogAggregateOp groups the changes without window. It keeps the retractable accumulators of the aggregates of
+// each group instead of the rows, so that a change adds its values to the accumulators or retracts them in constant
+// time. After each change, the group is sent with the aggregate results set as the fields of AggFields. An update of a
+// group is sent as the update before group with the old results and the update after group with the new results.
+type ChangelogAggregateOp struct {
+	// Dimensions are the group by expressions. If it is empty, all rows are in one group.
+	Dimensions ast.Dimensions
+	// AggFields are the aggregate calls which are replaced by the references of the field names in the select fields
+	AggFields []*ast.Field
+
+	state operatorState[changelogAggregateState]
+}
+
+// IsChangelogAggFunc tells if the aggregate function can be retracted in changelog mode
+func IsChangelogAggFunc(name string) bool {
+	switch name {
+	case "count", "sum", "avg", "min", "max":
+		return true
+	}
+	return false
+}
+
+// changelogGroup is the accumulators of a group. Accs[i] is the accumulator of AggFields[i].
+type changelogGroup struct {
+	// Row is the latest added row of the group to read the other fields like the group by keys
+	Row xsql.Row
+	// Count is the number of the current rows
+	Count int64
+	Accs  []*changelogAcc
+}
+
+// changelogAcc is the retractable accumulator of an aggregate call. The nil values are not accumulated.
+type changelogAcc struct {
+	// Count is the number of the values
+	Count    int64
+	IntSum   int64
+	FloatSum float64
+	// Floats is the number of the float values. The sum and avg are integers if it is 0.
+	Floats int64
+	// Values are the distinct values with their numbers for min and max
+	Values map[string]*changelogValue
+	// best is the cached result of min or max. It is not saved and is recalculated after restore.
+	best  any
+	valid bool
+}
+
+type changelogValue struct {
+	Value any
+	Count int64
+}
+
+// update adds the value if n is 1 or retracts it if n is -1. The value must be checked by checkChangelogAggValue.
+func (a *changelogAcc) update(name string, v any, n int64) {
+	if v == nil {
+		return
+	}
+	a.Count += n
+	switch name {
+	case "sum", "avg":
+		switch t := v.(type) {
+		case int:
+			a.IntSum += int64(t) * n
+		case int64:
+			a.IntSum += t * n
+		case float64:
+			a.FloatSum += t * float64(n)
+			a.Floats += n
+		}
+	case "min", "max":
+		if a.Values == nil {
+			a.Values = make(map[string]*changelogValue)
+		}
+		k := fmt.Sprintf("%v", v)
+		e, ok := a.Values[k]
+		if !ok {
+			e = &changelogValue{Value: v}
+			a.Values[k] = e
+		}
+		e.Count += n
+		switch {
+		case e.Count <= 0:
+			// Only the removal of a distinct value recalculates the result
+			delete(a.Values, k)
+			a.valid = false
+		case n > 0 && a.valid && better(name, v, a.best):
+			a.best = v
+		}
+	}
+}
+
+func (a *changelogAcc) result(name string) any {
+	switch name {
+	case "count":
+		return int(a.Count)
+	case "sum":
+		if a.Count == 0 {
+			return nil
+		}
+		if a.Floats == 0 {
+			return a.IntSum
+		}
+		return float64(a.IntSum) + a.FloatSum
+	case "avg":
+		if a.Count == 0 {
+			return nil
+		}
+		if a.Floats == 0 {
+			return a.IntSum / a.Count
+		}
+		return (float64(a.IntSum) + a.FloatSum) / float64(a.Count)
+	default:
+		if !a.valid {
+			a.best = nil
+			for _, e := range a.Values {
+				if a.best == nil || better(name, e.Value, a.best) {
+					a.best = e.Value
+				}
+			}
+			a.valid = true
+		}
+		return a.best
+	}
+}
+
+// better tells if v is less than the current min or greater than the current max
+func better(name string, v, current any) bool {
+	ve := &xsql.ValuerEval{}
+	if name == "min" {
+		return ve.SimpleDataEval(v, current, ast.LT) == true
+	}
+	return ve.SimpleDataEval(v, current, ast.GT) == true
+}
+
+// checkChangelogAggValue returns the error if the value cannot be accumulated by the aggregate function
+func checkChangelogAggValue(name string, v any) error {
+	switch v.(type) {
+	case nil:
+		return nil
+	case int, int64, float64:
+		return nil
+	case string:
+		if name == "min" || name == "max" || name == "count" {
+			return nil
+		}
+	default:
+		if name == "count" {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s of invalid value %[2]T(%[2]v)", name, v)
+}
+
+// changelogAggregateState is the accumulators of each group
+type changelogAggregateState struct {
+	Groups map[string]*changelogGroup
+}
+
+func newChangelogAggregateState() changelogAggregateState {
+	return changelogAggregateState{Groups: make(map[string]*changelogGroup)}
+}
+
+func (s *changelogAggregateState) restore() {
+	if s.Groups == nil {
+		s.Groups = make(map[string]*changelogGroup)
+	}
+}
+
+func (p *ChangelogAggregateOp) Apply(ctx api.StreamContext, data interface{}, fv *xsql.FunctionValuer, _ *xsql.AggregateFunctionValuer) interface{} {
+	ctx.GetLogger().Debugf("changelog aggregate plan receive %v", data)
+	switch input := data.(type) {
+	case error:
+		return input
+	case xsql.Row:
+		cr, ok := input.(xsql.ChangelogRow)
+		if !ok || cr.GetRowkind() == "" {
+			return fmt.Errorf("run changelog Group By error: row %v has no row kind", input)
+		}
+		var name string
+		ve := &xsql.ValuerEval{Valuer: xsql.MultiValuer(input, fv)}
+		for _, d := range p.Dimensions {
+			r := ve.Eval(d.Expr)
+			if _, ok := r.(error); ok {
+				return fmt.Errorf("run changelog Group By error: %v", r)
+			}
+			name += fmt.Sprintf("%v,", r)
+		}
+		values, err := p.evalArgs(ve)
+		if err != nil {
+			return fmt.Errorf("run changelog Group By error: %v", err)
+		}
+		ls := p.state.get(ctx, changelogAggregateStateKey, newChangelogAggregateState)
+		ls.mu.Lock()
+		defer ls.mu.Unlock()
+		s := &ls.data
+		retract := xsql.IsRetract(cr.GetRowkind())
+		g, exists := s.Groups[name]
+		if !exists {
+			if retract {
+				ctx.GetLogger().Debugf("changelog Group By retract row %s of non-existing group %s", cr.ChangelogKey(), name)
+				return nil
+			}
+			g = &changelogGroup{Accs: make([]*changelogAcc, len(p.AggFields))}
+			for i := range g.Accs {
+				g.Accs[i] = &changelogAcc{}
+			}
+		}
+		var old *xsql.GroupedTuples
+		if exists {
+			old = p.groupRow(g, xsql.RowkindUpdateBefore, name)
+		}
+		n := int64(1)
+		if retract {
+			n = -1
+		} else {
+			g.Row = input.Clone()
+		}
+		g.Count += n
+		for i, f := range p.AggFields {
+			g.Accs[i].update(f.Expr.(*ast.Call).Name, values[i], n)
+		}
+		switch {
+		case g.Count <= 0:
+			delete(s.Groups, name)
+			old.Rowkind = xsql.RowkindDelete
+			return old
+		case !exists:
+			s.Groups[name] = g
+			return p.groupRow(g, xsql.RowkindInsert, name)
+		default:
+			return []xsql.Row{old, p.groupRow(g, xsql.RowkindUpdateAfter, name)}
+		}
+	default:
+		return fmt.Errorf("run changelog Group By error: invalid input %[1]T(%[1]v)", input)
+	}
+}
+
+// evalArgs evaluates the argument of each aggregate call. The argument of count(*) counts every row.
+func (p *ChangelogAggregateOp) evalArgs(ve *xsql.ValuerEval) ([]any, error) {
+	values := make([]any, len(p.AggFields))
+	for i, f := range p.AggFields {
+		c := f.Expr.(*ast.Call)
+		if _, ok := c.Args[0].(*ast.Wildcard); ok {
+			values[i] = true
+			continue
+		}
+		v := ve.Eval(c.Args[0])
+		if e, ok := v.(error); ok {
+			return nil, e
+		}
+		if err := checkChangelogAggValue(c.Name, v); err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// groupRow is the row of the group with the aggregate results
+func (p *ChangelogAggregateOp) groupRow(g *changelogGroup, rowkind string, key string) *xsql.GroupedTuples {
+	r := &xsql.GroupedTuples{Content: []xsql.Row{g.Row}, Changelog: xsql.Changelog{Rowkind: rowkind, Key: key}}
+	for i, f := range p.AggFields {
+		r.Set(f.Name, g.Accs[i].result(f.Expr.(*ast.Call).Name))
+	}
+	return r
+}
+
+// GetRuntimeDetail returns the number of the groups and the current rows
+func (p *ChangelogAggregateOp) GetRuntimeDetail() map[string]any {
+	return p.state.detail(func(s *changelogAggregateState) map[string]any {
+		rows := int64(0)
+		for _, g := range s.Groups {
+			rows += g.Count
+		}
+		return map[string]any{"groups": len(s.Groups), "rows": rows}
+	})
+}
@@ -0,0 +1,224 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"encoding/gob"
+	"fmt"
+	"sort"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+const changelogJoinStateKey = "changelogJoin"
+
+func init() {
+	gob.Register(&lockedState[changelogJoinState]{})
+}
+
+// ChangelogJoinOp joins the changes of two changelog streams without window. It keeps the current rows of both sides,
+// so that each change is joined with the current rows of the other side. The changes of the joined rows are sent as
+// inserts and deletes because an update of a row may change the rows it joins.
+type ChangelogJoinOp struct {
+	From *ast.Table
+	// Join is the inner or left join of the other stream
+	Join ast.Join
+
+	state operatorState[changelogJoinState]
+}
+
+// changelogJoinState is the current rows of both sides by their keys
+type changelogJoinState struct {
+	Left  map[string]xsql.Row
+	Right map[string]xsql.Row
+}
+
+func newChangelogJoinState() changelogJoinState {
+	return changelogJoinState{Left: make(map[string]xsql.Row), Right: make(map[string]xsql.Row)}
+}
+
+func (s *changelogJoinState) restore() {
+	if s.Left == nil {
+		s.Left = make(map[string]xsql.Row)
+	}
+	if s.Right == nil {
+		s.Right = make(map[string]xsql.Row)
+	}
+}
+
+func (p *ChangelogJoinOp) Apply(ctx api.StreamContext, data interface{}, fv *xsql.FunctionValuer, _ *xsql.AggregateFunctionValuer) interface{} {
+	ctx.GetLogger().Debugf("changelog join plan receive %v", data)
+	switch input := data.(type) {
+	case error:
+		return input
+	case *xsql.Tuple:
+		if input.Rowkind == "" {
+			return fmt.Errorf("run changelog Join error: row %v has no row kind", input.Message)
+		}
+		leftName, rightName := p.From.Name, p.Join.Name
+		if p.From.Alias != "" {
+			leftName = p.From.Alias
+		}
+		if p.Join.Alias != "" {
+			rightName = p.Join.Alias
+		}
+		ls := p.state.get(ctx, changelogJoinStateKey, newChangelogJoinState)
+		ls.mu.Lock()
+		defer ls.mu.Unlock()
+		s := &ls.data
+		var (
+			result []xsql.Row
+			err    error
+		)
+		switch input.Emitter {
+		case leftName:
+			result, err = p.applyLeft(s, input, fv)
+		case rightName:
+			result, err = p.applyRight(s, input, fv)
+		default:
+			return fmt.Errorf("run changelog Join error: row of stream %s is not joined", input.Emitter)
+		}
+		if err != nil {
+			return fmt.Errorf("run changelog Join error: %v", err)
+		}
+		if len(result) == 0 {
+			return nil
+		}
+		return result
+	default:
+		return fmt.Errorf("run changelog Join error: invalid input %[1]T(%[1]v)", input)
+	}
+}
+
+// applyLeft joins the change of a left row with all the matched right rows
+func (p *ChangelogJoinOp) applyLeft(s *changelogJoinState, left *xsql.Tuple, fv *xsql.FunctionValuer) ([]xsql.Row, error) {
+	kind := xsql.RowkindInsert
+	if xsql.IsRetract(left.Rowkind) {
+		kind = xsql.RowkindDelete
+		if _, ok := s.Left[left.Key]; !ok {
+			return nil, nil
+		}
+		delete(s.Left, left.Key)
+	} else {
+		s.Left[left.Key] = left.Clone()
+	}
+	var result []xsql.Row
+	for _, k := range sortedKeys(s.Right) {
+		right := s.Right[k]
+		ok, err := p.match(left, right, fv)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			result = append(result, changelogJoinRow(kind, left, right))
+		}
+	}
+	if len(result) == 0 && p.Join.JoinType == ast.LEFT_JOIN {
+		result = append(result, changelogJoinRow(kind, left))
+	}
+	return result, nil
+}
+
+// applyRight joins the change of a right row with all the matched left rows. For left join, the null padded
+// left row is retracted once it is matched and sent again once it is unmatched.
+func (p *ChangelogJoinOp) applyRight(s *changelogJoinState, right *xsql.Tuple, fv *xsql.FunctionValuer) ([]xsql.Row, error) {
+	retract := xsql.IsRetract(right.Rowkind)
+	if retract {
+		if _, ok := s.Right[right.Key]; !ok {
+			return nil, nil
+		}
+		delete(s.Right, right.Key)
+	}
+	var result []xsql.Row
+	for _, k := range sortedKeys(s.Left) {
+		left := s.Left[k]
+		ok, err := p.match(left, right, fv)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		padded := false
+		if p.Join.JoinType == ast.LEFT_JOIN {
+			padded, err = p.unmatched(s, left, fv)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if retract {
+			result = append(result, changelogJoinRow(xsql.RowkindDelete, left, right))
+			if padded {
+				result = append(result, changelogJoinRow(xsql.RowkindInsert, left))
+			}
+		} else {
+			if padded {
+				result = append(result, changelogJoinRow(xsql.RowkindDelete, left))
+			}
+			result = append(result, changelogJoinRow(xsql.RowkindInsert, left, right))
+		}
+	}
+	if !retract {
+		s.Right[right.Key] = right.Clone()
+	}
+	return result, nil
+}
+
+// unmatched returns whether the left row matches none of the current right rows
+func (p *ChangelogJoinOp) unmatched(s *changelogJoinState, left xsql.Row, fv *xsql.FunctionValuer) (bool, error) {
+	for _, right := range s.Right {
+		ok, err := p.match(left, right, fv)
+		if err != nil || ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (p *ChangelogJoinOp) match(left, right xsql.Row, fv *xsql.FunctionValuer) (bool, error) {
+	temp := &xsql.JoinTuple{Tuples: []xsql.Row{left, right}}
+	ve := &xsql.ValuerEval{Valuer: xsql.MultiValuer(temp, fv)}
+	switch val := evalOn(p.Join, ve, left, right).(type) {
+	case error:
+		return false, val
+	case bool:
+		return val, nil
+	default:
+		return false, fmt.Errorf("invalid join condition that returns non-bool value %[1]T(%[1]v)", val)
+	}
+}
+
+func changelogJoinRow(rowkind string, tuples ...xsql.Row) *xsql.JoinTuple {
+	return &xsql.JoinTuple{Tuples: tuples, Changelog: xsql.Changelog{Rowkind: rowkind}}
+}
+
+func sortedKeys(m map[string]xsql.Row) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// GetRuntimeDetail returns the number of the current rows of both sides
+func (p *ChangelogJoinOp) GetRuntimeDetail() map[string]any {
+	return p.state.detail(func(s *changelogJoinState) map[string]any {
+		return map[string]any{"leftRows": len(s.Left), "rightRows": len(s.Right)}
+	})
+}
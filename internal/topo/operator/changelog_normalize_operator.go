@@ -0,0 +1,133 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"encoding/gob"
+	"fmt"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+const changelogNormalizeStateKey = "changelogNormalize"
+
+func init() {
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+	gob.Register(&lockedState[changelogNormalizeState]{})
+}
+
+// ChangelogNormalizeOp converts the rows of a changelog stream to the changes with row kind. It keeps the latest row of each key,
+// so that an update or delete retracts the previous row of the key.
+type ChangelogNormalizeOp struct {
+	// Key is the primary key field of the stream
+	Key string
+	// RowkindField is the field of the row kind which is insert, update, upsert or delete. The row without it is an upsert.
+	RowkindField string
+
+	state operatorState[changelogNormalizeState]
+}
+
+type changelogEntry struct {
+	Message   map[string]any
+	Metadata  map[string]any
+	Timestamp int64
+}
+
+// changelogNormalizeState is the latest row of each key
+type changelogNormalizeState struct {
+	Rows map[string]changelogEntry
+}
+
+func newChangelogNormalizeState() changelogNormalizeState {
+	return changelogNormalizeState{Rows: make(map[string]changelogEntry)}
+}
+
+func (s *changelogNormalizeState) restore() {
+	if s.Rows == nil {
+		s.Rows = make(map[string]changelogEntry)
+	}
+}
+
+func (p *ChangelogNormalizeOp) Apply(ctx api.StreamContext, data interface{}, _ *xsql.FunctionValuer, _ *xsql.AggregateFunctionValuer) interface{} {
+	ctx.GetLogger().Debugf("changelog normalize plan receive %v", data)
+	switch input := data.(type) {
+	case error:
+		return input
+	case *xsql.Tuple:
+		kv, ok := input.Value(p.Key, "")
+		if !ok || kv == nil {
+			return fmt.Errorf("run changelog error: key field %s not found in %v", p.Key, input.Message)
+		}
+		key := fmt.Sprintf("%v", kv)
+		rowkind := ast.RowkindUpsert
+		if p.RowkindField != "" {
+			if c, ok := input.Value(p.RowkindField, ""); ok && c != nil {
+				rowkind, ok = c.(string)
+				if !ok {
+					return fmt.Errorf("run changelog error: rowkind field %s is not a string in %v", p.RowkindField, input.Message)
+				}
+			}
+		}
+		ls := p.state.get(ctx, changelogNormalizeStateKey, newChangelogNormalizeState)
+		ls.mu.Lock()
+		defer ls.mu.Unlock()
+		s := &ls.data
+		prev, exists := s.Rows[key]
+		switch rowkind {
+		case ast.RowkindInsert, ast.RowkindUpdate, ast.RowkindUpsert:
+			s.Rows[key] = changelogEntry{Message: input.Message, Metadata: input.Metadata, Timestamp: input.Timestamp.UnixMilli()}
+			if !exists {
+				input.Changelog = xsql.Changelog{Rowkind: xsql.RowkindInsert, Key: key}
+				return input
+			}
+			input.Changelog = xsql.Changelog{Rowkind: xsql.RowkindUpdateAfter, Key: key}
+			return []xsql.Row{prev.toTuple(input.Emitter, xsql.RowkindUpdateBefore, key), input}
+		case ast.RowkindDelete:
+			if !exists {
+				ctx.GetLogger().Debugf("changelog delete non-existing key %s", key)
+				return nil
+			}
+			delete(s.Rows, key)
+			return prev.toTuple(input.Emitter, xsql.RowkindDelete, key)
+		default:
+			return fmt.Errorf("run changelog error: invalid rowkind %s", rowkind)
+		}
+	default:
+		return fmt.Errorf("run changelog error: invalid input %[1]T(%[1]v)", input)
+	}
+}
+
+// toTuple recovers the previous row to retract it
+func (e changelogEntry) toTuple(emitter string, rowkind string, key string) *xsql.Tuple {
+	return &xsql.Tuple{
+		Emitter:   emitter,
+		Message:   e.Message,
+		Metadata:  e.Metadata,
+		Timestamp: time.UnixMilli(e.Timestamp),
+		Changelog: xsql.Changelog{Rowkind: rowkind, Key: key},
+	}
+}
+
+// GetRuntimeDetail returns the number of the retained keys
+func (p *ChangelogNormalizeOp) GetRuntimeDetail() map[string]any {
+	return p.state.detail(func(s *changelogNormalizeState) map[string]any {
+		return map[string]any{"keys": len(s.Rows)}
+	})
+}
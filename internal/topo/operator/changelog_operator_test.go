@@ -0,0 +1,352 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+// change is the row kind and the content of an emitted changelog row. The content is the message of a tuple,
+// the key of a joined row or the map of a group with the aggregate results.
+type change struct {
+	kind  string
+	value any
+}
+
+func toChanges(t *testing.T, result any) []change {
+	var rows []xsql.Row
+	switch r := result.(type) {
+	case nil:
+		return nil
+	case error:
+		require.NoError(t, r)
+	case []xsql.Row:
+		rows = r
+	case xsql.Row:
+		rows = []xsql.Row{r}
+	}
+	changes := make([]change, 0, len(rows))
+	for _, row := range rows {
+		switch r := row.(type) {
+		case *xsql.Tuple:
+			changes = append(changes, change{kind: r.Rowkind, value: r.ToMap()})
+		case *xsql.JoinTuple:
+			changes = append(changes, change{kind: r.Rowkind, value: r.ChangelogKey()})
+		case *xsql.GroupedTuples:
+			changes = append(changes, change{kind: r.Rowkind, value: r.ToMap()})
+		}
+	}
+	return changes
+}
+
+func TestChangelogNormalize(t *testing.T) {
+	ctx := mockContext.NewMockContext("TestChangelogNormalize", "op1")
+	fv, afv := xsql.NewFunctionValuersForOp(nil)
+	op := &ChangelogNormalizeOp{Key: "id", RowkindField: "op"}
+	tests := []struct {
+		msg     map[string]any
+		changes []change
+		err     string
+	}{
+		{
+			msg:     map[string]any{"id": 1, "v": 1},
+			changes: []change{{kind: xsql.RowkindInsert, value: map[string]any{"id": 1, "v": 1}}},
+		},
+		{
+			msg: map[string]any{"id": 1, "v": 2, "op": "update"},
+			changes: []change{
+				{kind: xsql.RowkindUpdateBefore, value: map[string]any{"id": 1, "v": 1}},
+				{kind: xsql.RowkindUpdateAfter, value: map[string]any{"id": 1, "v": 2, "op": "update"}},
+			},
+		},
+		{
+			msg:     map[string]any{"id": 2, "v": 3, "op": "insert"},
+			changes: []change{{kind: xsql.RowkindInsert, value: map[string]any{"id": 2, "v": 3, "op": "insert"}}},
+		},
+		{
+			msg:     map[string]any{"id": 1, "op": "delete"},
+			changes: []change{{kind: xsql.RowkindDelete, value: map[string]any{"id": 1, "v": 2, "op": "update"}}},
+		},
+		{
+			msg: map[string]any{"id": 3, "op": "delete"},
+		},
+		{
+			msg: map[string]any{"id": 3, "op": "merge"},
+			err: "run changelog error: invalid rowkind merge",
+		},
+		{
+			msg: map[string]any{"v": 3},
+			err: "run changelog error: key field id not found in map[v:3]",
+		},
+	}
+	for i, tt := range tests {
+		result := op.Apply(ctx, &xsql.Tuple{Emitter: "demo", Message: tt.msg}, fv, afv)
+		if tt.err != "" {
+			require.EqualError(t, result.(error), tt.err, i)
+			continue
+		}
+		require.Equal(t, tt.changes, toChanges(t, result), i)
+	}
+	require.Equal(t, map[string]any{"keys": 1}, op.GetRuntimeDetail())
+
+	ctx = restoreCheckpoint(t, ctx, "TestChangelogNormalize", changelogNormalizeStateKey)
+	op = &ChangelogNormalizeOp{Key: "id", RowkindField: "op"}
+	result := op.Apply(ctx, &xsql.Tuple{Emitter: "demo", Message: map[string]any{"id": 2, "op": "delete"}}, fv, afv)
+	require.Equal(t, []change{{kind: xsql.RowkindDelete, value: map[string]any{"id": 2, "v": 3, "op": "insert"}}}, toChanges(t, result))
+}
+
+func changelogTuple(kind string, key string, msg map[string]any) *xsql.Tuple {
+	return &xsql.Tuple{Emitter: "demo", Message: msg, Changelog: xsql.Changelog{Rowkind: kind, Key: key}}
+}
+
+func TestChangelogAggregate(t *testing.T) {
+	ctx := mockContext.NewMockContext("TestChangelogAggregate", "op1")
+	fv, afv := xsql.NewFunctionValuersForOp(nil)
+	dims := ast.Dimensions{{Expr: &ast.FieldRef{Name: "g", StreamName: "demo"}}}
+	v := &ast.FieldRef{Name: "v", StreamName: "demo"}
+	aggFields := []*ast.Field{
+		{Name: "total", Expr: &ast.Call{Name: "sum", FuncType: ast.FuncTypeAgg, Args: []ast.Expr{v}}},
+		{Name: "n", Expr: &ast.Call{Name: "count", FuncType: ast.FuncTypeAgg, Args: []ast.Expr{&ast.Wildcard{Token: ast.ASTERISK}}}},
+		{Name: "hi", Expr: &ast.Call{Name: "max", FuncType: ast.FuncTypeAgg, Args: []ast.Expr{v}}},
+	}
+	op := &ChangelogAggregateOp{Dimensions: dims, AggFields: aggFields}
+	group := func(g string, v any, total any, n int, hi any) map[string]any {
+		return map[string]any{"g": g, "v": v, "total": total, "n": n, "hi": hi}
+	}
+	tests := []struct {
+		row     *xsql.Tuple
+		changes []change
+		err     string
+	}{
+		{
+			row:     changelogTuple(xsql.RowkindInsert, "a", map[string]any{"g": "x", "v": 1}),
+			changes: []change{{kind: xsql.RowkindInsert, value: group("x", 1, int64(1), 1, 1)}},
+		},
+		{
+			row: changelogTuple(xsql.RowkindInsert, "b", map[string]any{"g": "x", "v": 2}),
+			changes: []change{
+				{kind: xsql.RowkindUpdateBefore, value: group("x", 1, int64(1), 1, 1)},
+				{kind: xsql.RowkindUpdateAfter, value: group("x", 2, int64(3), 2, 2)},
+			},
+		},
+		{
+			row: changelogTuple(xsql.RowkindUpdateBefore, "a", map[string]any{"g": "x", "v": 1}),
+			changes: []change{
+				{kind: xsql.RowkindUpdateBefore, value: group("x", 2, int64(3), 2, 2)},
+				{kind: xsql.RowkindUpdateAfter, value: group("x", 2, int64(2), 1, 2)},
+			},
+		},
+		{
+			row:     changelogTuple(xsql.RowkindUpdateAfter, "a", map[string]any{"g": "y", "v": 3}),
+			changes: []change{{kind: xsql.RowkindInsert, value: group("y", 3, int64(3), 1, 3)}},
+		},
+		{
+			row:     changelogTuple(xsql.RowkindDelete, "b", map[string]any{"g": "x", "v": 2}),
+			changes: []change{{kind: xsql.RowkindDelete, value: group("x", 2, int64(2), 1, 2)}},
+		},
+		{
+			row: changelogTuple(xsql.RowkindDelete, "c", map[string]any{"g": "z", "v": 4}),
+		},
+		{
+			row: changelogTuple(xsql.RowkindInsert, "c", map[string]any{"g": "y", "v": "s"}),
+			err: "run changelog Group By error: sum of invalid value string(s)",
+		},
+	}
+	for i, tt := range tests {
+		result := op.Apply(ctx, tt.row, fv, afv)
+		if tt.err != "" {
+			require.EqualError(t, result.(error), tt.err, i)
+			continue
+		}
+		require.Equal(t, tt.changes, toChanges(t, result), i)
+	}
+	require.Equal(t, map[string]any{"groups": 1, "rows": int64(1)}, op.GetRuntimeDetail())
+
+	ctx = restoreCheckpoint(t, ctx, "TestChangelogAggregate", changelogAggregateStateKey)
+	op = &ChangelogAggregateOp{Dimensions: dims, AggFields: aggFields}
+	result := op.Apply(ctx, changelogTuple(xsql.RowkindInsert, "d", map[string]any{"g": "y", "v": 5}), fv, afv)
+	require.Equal(t, []change{
+		{kind: xsql.RowkindUpdateBefore, value: group("y", 3, int64(3), 1, 3)},
+		{kind: xsql.RowkindUpdateAfter, value: group("y", 5, int64(8), 2, 5)},
+	}, toChanges(t, result))
+	// The max is recalculated after its value is retracted
+	result = op.Apply(ctx, changelogTuple(xsql.RowkindDelete, "d", map[string]any{"g": "y", "v": 5}), fv, afv)
+	require.Equal(t, []change{
+		{kind: xsql.RowkindUpdateBefore, value: group("y", 5, int64(8), 2, 5)},
+		{kind: xsql.RowkindUpdateAfter, value: group("y", 5, int64(3), 1, 3)},
+	}, toChanges(t, result))
+	// The planner replaces the aggregate calls with the references of the results
+	stmt, err := xsql.NewParser(strings.NewReader("SELECT g, bypass(total) AS total FROM demo GROUP BY g")).Parse()
+	require.NoError(t, err)
+	pp := &ProjectOp{RowkindField: "rowkind"}
+	parseStmt(pp, stmt.Fields)
+	result = op.Apply(ctx, changelogTuple(xsql.RowkindInsert, "e", map[string]any{"g": "y", "v": 4}), fv, afv)
+	require.Nil(t, pp.Apply(ctx, result.([]xsql.Row)[0], fv, afv))
+	require.Equal(t, map[string]any{"g": "y", "total": int64(7), "rowkind": "update"}, pp.Apply(ctx, result.([]xsql.Row)[1], fv, afv).(xsql.Row).ToMap())
+}
+
+func TestChangelogAcc(t *testing.T) {
+	tests := []struct {
+		name    string
+		adds    []any
+		retract []any
+		result  any
+	}{
+		{name: "count", adds: []any{1, nil, "a"}, retract: []any{1}, result: 1},
+		{name: "sum", adds: []any{nil}, result: nil},
+		{name: "sum", adds: []any{1, int64(2)}, retract: []any{1}, result: int64(2)},
+		{name: "sum", adds: []any{1, 2.5}, retract: []any{1}, result: 2.5},
+		{name: "avg", adds: []any{1, 2, 6}, retract: []any{6}, result: int64(1)},
+		{name: "avg", adds: []any{1, 2.0}, result: 1.5},
+		{name: "avg", adds: []any{1}, retract: []any{1}, result: nil},
+		{name: "min", adds: []any{3, 1, 1, 2}, retract: []any{1}, result: 1},
+		{name: "min", adds: []any{3, 1, 2}, retract: []any{1}, result: 2},
+		{name: "max", adds: []any{"a", "c", "b"}, retract: []any{"c"}, result: "b"},
+		{name: "max", adds: []any{1}, retract: []any{1}, result: nil},
+	}
+	for i, tt := range tests {
+		a := &changelogAcc{}
+		for _, v := range tt.adds {
+			require.NoError(t, checkChangelogAggValue(tt.name, v))
+			a.update(tt.name, v, 1)
+			a.result(tt.name)
+		}
+		for _, v := range tt.retract {
+			a.update(tt.name, v, -1)
+		}
+		require.Equal(t, tt.result, a.result(tt.name), "%d %s", i, tt.name)
+	}
+	require.EqualError(t, checkChangelogAggValue("avg", true), "avg of invalid value bool(true)")
+}
+
+func TestChangelogJoin(t *testing.T) {
+	ctx := mockContext.NewMockContext("TestChangelogJoin", "op1")
+	fv, afv := xsql.NewFunctionValuersForOp(nil)
+	join := ast.Join{Name: "customers", JoinType: ast.LEFT_JOIN, Expr: &ast.BinaryExpr{
+		OP:  ast.EQ,
+		LHS: &ast.FieldRef{Name: "cid", StreamName: "orders"},
+		RHS: &ast.FieldRef{Name: "cid", StreamName: "customers"},
+	}}
+	op := &ChangelogJoinOp{From: &ast.Table{Name: "orders"}, Join: join}
+	order := func(kind string, id string, cid int) *xsql.Tuple {
+		return &xsql.Tuple{Emitter: "orders", Message: map[string]any{"id": id, "cid": cid}, Changelog: xsql.Changelog{Rowkind: kind, Key: id}}
+	}
+	customer := func(kind string, cid int, region string) *xsql.Tuple {
+		return &xsql.Tuple{Emitter: "customers", Message: map[string]any{"cid": cid, "region": region}, Changelog: xsql.Changelog{Rowkind: kind, Key: fmt.Sprintf("c%d", cid)}}
+	}
+	tests := []struct {
+		row     *xsql.Tuple
+		changes []change
+	}{
+		{
+			row:     order(xsql.RowkindInsert, "o1", 1),
+			changes: []change{{kind: xsql.RowkindInsert, value: "o1"}},
+		},
+		{
+			row:     customer(xsql.RowkindInsert, 1, "east"),
+			changes: []change{{kind: xsql.RowkindDelete, value: "o1"}, {kind: xsql.RowkindInsert, value: "o1|c1"}},
+		},
+		{
+			row:     order(xsql.RowkindInsert, "o2", 1),
+			changes: []change{{kind: xsql.RowkindInsert, value: "o2|c1"}},
+		},
+		{
+			row: customer(xsql.RowkindUpdateBefore, 1, "east"),
+			changes: []change{
+				{kind: xsql.RowkindDelete, value: "o1|c1"}, {kind: xsql.RowkindInsert, value: "o1"},
+				{kind: xsql.RowkindDelete, value: "o2|c1"}, {kind: xsql.RowkindInsert, value: "o2"},
+			},
+		},
+		{
+			row: customer(xsql.RowkindUpdateAfter, 1, "west"),
+			changes: []change{
+				{kind: xsql.RowkindDelete, value: "o1"}, {kind: xsql.RowkindInsert, value: "o1|c1"},
+				{kind: xsql.RowkindDelete, value: "o2"}, {kind: xsql.RowkindInsert, value: "o2|c1"},
+			},
+		},
+		{
+			row:     order(xsql.RowkindUpdateBefore, "o1", 1),
+			changes: []change{{kind: xsql.RowkindDelete, value: "o1|c1"}},
+		},
+		{
+			row:     order(xsql.RowkindUpdateAfter, "o1", 2),
+			changes: []change{{kind: xsql.RowkindInsert, value: "o1"}},
+		},
+		{
+			row: customer(xsql.RowkindDelete, 3, "north"),
+		},
+	}
+	for i, tt := range tests {
+		result := op.Apply(ctx, tt.row, fv, afv)
+		require.Equal(t, tt.changes, toChanges(t, result), i)
+	}
+	require.Equal(t, map[string]any{"leftRows": 2, "rightRows": 1}, op.GetRuntimeDetail())
+
+	ctx = restoreCheckpoint(t, ctx, "TestChangelogJoin", changelogJoinStateKey)
+	op = &ChangelogJoinOp{From: &ast.Table{Name: "orders"}, Join: join}
+	result := op.Apply(ctx, customer(xsql.RowkindInsert, 2, "south"), fv, afv)
+	require.Equal(t, []change{{kind: xsql.RowkindDelete, value: "o1"}, {kind: xsql.RowkindInsert, value: "o1|c2"}}, toChanges(t, result))
+
+	join.JoinType = ast.INNER_JOIN
+	op = &ChangelogJoinOp{From: &ast.Table{Name: "orders"}, Join: join}
+	ctx = mockContext.NewMockContext("TestChangelogJoin", "op2")
+	require.Nil(t, op.Apply(ctx, order(xsql.RowkindInsert, "o1", 1), fv, afv))
+	result = op.Apply(ctx, customer(xsql.RowkindInsert, 1, "east"), fv, afv)
+	require.Equal(t, []change{{kind: xsql.RowkindInsert, value: "o1|c1"}}, toChanges(t, result))
+}
+
+func TestSinkRowkind(t *testing.T) {
+	tests := []struct {
+		rowkind          string
+		sendUpdateBefore bool
+		result           string
+	}{
+		{rowkind: xsql.RowkindInsert, result: ast.RowkindInsert},
+		{rowkind: xsql.RowkindUpdateBefore, result: ""},
+		{rowkind: xsql.RowkindUpdateAfter, result: ast.RowkindUpdate},
+		{rowkind: xsql.RowkindDelete, result: ast.RowkindDelete},
+		{rowkind: xsql.RowkindInsert, sendUpdateBefore: true, result: ast.RowkindInsert},
+		{rowkind: xsql.RowkindUpdateBefore, sendUpdateBefore: true, result: ast.RowkindDelete},
+		{rowkind: xsql.RowkindUpdateAfter, sendUpdateBefore: true, result: ast.RowkindInsert},
+		{rowkind: xsql.RowkindDelete, sendUpdateBefore: true, result: ast.RowkindDelete},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.result, sinkRowkind(tt.rowkind, tt.sendUpdateBefore), tt)
+	}
+}
+
+// restoreCheckpoint encodes the state of the operator and restores it to a new context like the checkpoint recovery
+func restoreCheckpoint(t *testing.T, ctx api.StreamContext, name string, key string) api.StreamContext {
+	st, err := ctx.GetState(key)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(&st))
+	var restored any
+	require.NoError(t, gob.NewDecoder(&buf).Decode(&restored))
+	ctx = mockContext.NewMockContext(name, "op1")
+	require.NoError(t, ctx.PutState(key, restored))
+	return ctx
+}
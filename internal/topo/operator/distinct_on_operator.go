@@ -0,0 +1,157 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"encoding/gob"
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+const distinctOnStateKey = "distinctOn"
+
+func init() {
+	gob.Register(&lockedState[distinctOnState]{})
+}
+
+// DistinctOnOp keeps the first row of each key. If Within is set, the following rows of the key are dropped
+// until Within milliseconds since the first row pass, and the keys are retained across the windows.
+// Otherwise, the rows are only deduplicated inside each window.
+type DistinctOnOp struct {
+	Keys   []ast.Expr
+	Within int64
+
+	state operatorState[distinctOnState]
+}
+
+// distinctOnState is the time when the deduplication of each key ends
+type distinctOnState struct {
+	Keys   map[string]int64
+	expiry expiryQueue
+}
+
+func newDistinctOnState() distinctOnState {
+	return distinctOnState{Keys: make(map[string]int64)}
+}
+
+func (s *distinctOnState) restore() {
+	if s.Keys == nil {
+		s.Keys = make(map[string]int64)
+	}
+	for key, end := range s.Keys {
+		s.expiry.add(key, end)
+	}
+}
+
+// admit checks if the row of the key at the time is the first one, and starts the deduplication of the key if so
+func (s *distinctOnState) admit(key string, now, within int64) bool {
+	s.expiry.expire(now, func(item expiryItem) {
+		// The key may be restarted after the item is pushed
+		if s.Keys[item.key] == item.expire {
+			delete(s.Keys, item.key)
+		}
+	})
+	if end, ok := s.Keys[key]; ok && now < end {
+		return false
+	}
+	s.Keys[key] = now + within
+	s.expiry.add(key, now+within)
+	return true
+}
+
+func (p *DistinctOnOp) Apply(ctx api.StreamContext, data interface{}, fv *xsql.FunctionValuer, _ *xsql.AggregateFunctionValuer) interface{} {
+	ctx.GetLogger().Debugf("distinct on plan receive %v", data)
+	switch input := data.(type) {
+	case error:
+		return input
+	case xsql.Row:
+		if p.Within == 0 {
+			return input
+		}
+		key, err := p.key(input, fv)
+		if err != nil {
+			return err
+		}
+		s := p.state.get(ctx, distinctOnStateKey, newDistinctOnState)
+		s.mu.Lock()
+		ok := s.data.admit(key, rowTime(input), p.Within)
+		s.mu.Unlock()
+		if ok {
+			return input
+		}
+	case xsql.Collection:
+		var (
+			sel  []int
+			seen map[string]struct{}
+			s    *distinctOnState
+		)
+		if p.Within == 0 {
+			seen = make(map[string]struct{})
+		} else {
+			ls := p.state.get(ctx, distinctOnStateKey, newDistinctOnState)
+			ls.mu.Lock()
+			defer ls.mu.Unlock()
+			s = &ls.data
+		}
+		err := input.Range(func(i int, r xsql.ReadonlyRow) (bool, error) {
+			key, err := p.key(r, fv)
+			if err != nil {
+				return false, err
+			}
+			if s != nil {
+				if s.admit(key, rowTime(r), p.Within) {
+					sel = append(sel, i)
+				}
+			} else if _, ok := seen[key]; !ok {
+				seen[key] = struct{}{}
+				sel = append(sel, i)
+			}
+			return true, nil
+		})
+		if err != nil {
+			return err
+		}
+		if len(sel) > 0 {
+			return input.Filter(sel)
+		}
+	default:
+		return fmt.Errorf("run DISTINCT ON error: invalid input %[1]T(%[1]v)", input)
+	}
+	return nil
+}
+
+func (p *DistinctOnOp) key(row xsql.ReadonlyRow, fv *xsql.FunctionValuer) (string, error) {
+	ve := &xsql.ValuerEval{Valuer: xsql.MultiValuer(row, fv)}
+	var key string
+	for _, expr := range p.Keys {
+		r := ve.Eval(expr)
+		if err, ok := r.(error); ok {
+			return "", fmt.Errorf("run DISTINCT ON error: %v", err)
+		}
+		key += fmt.Sprintf("%v,", r)
+	}
+	return key, nil
+}
+
+// GetRuntimeDetail returns the number of the retained keys
+func (p *DistinctOnOp) GetRuntimeDetail() map[string]any {
+	return p.state.detail(func(s *distinctOnState) map[string]any {
+		return map[string]any{"keys": len(s.Keys)}
+	})
+}
@@ -0,0 +1,92 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func dedupRow(id string, v int, ts int64) *xsql.Tuple {
+	return &xsql.Tuple{Emitter: "demo", Message: map[string]any{"id": id, "v": v}, Timestamp: time.UnixMilli(ts)}
+}
+
+func TestDistinctOnStream(t *testing.T) {
+	ctx := mockContext.NewMockContext("TestDistinctOnStream", "op1")
+	fv, afv := xsql.NewFunctionValuersForOp(nil)
+	keys := []ast.Expr{&ast.FieldRef{Name: "id", StreamName: "demo"}}
+	op := &DistinctOnOp{Keys: keys, Within: 100}
+	tests := []struct {
+		row  *xsql.Tuple
+		sent bool
+	}{
+		{row: dedupRow("a", 1, 0), sent: true},
+		{row: dedupRow("b", 2, 10), sent: true},
+		{row: dedupRow("a", 3, 50)},
+		{row: dedupRow("a", 4, 99)},
+		{row: dedupRow("a", 5, 100), sent: true},
+		{row: dedupRow("b", 6, 120), sent: true},
+		{row: dedupRow("a", 7, 150)},
+	}
+	for i, tt := range tests {
+		result := op.Apply(ctx, tt.row, fv, afv)
+		if tt.sent {
+			require.Equal(t, tt.row, result, i)
+		} else {
+			require.Nil(t, result, i)
+		}
+	}
+	require.Equal(t, map[string]any{"keys": 2}, op.GetRuntimeDetail())
+
+	// The keys are retained across windows
+	w := &xsql.WindowTuples{Content: []xsql.Row{dedupRow("a", 8, 160), dedupRow("c", 9, 170), dedupRow("c", 10, 180), dedupRow("b", 11, 230)}}
+	result := op.Apply(ctx, w, fv, afv)
+	require.Equal(t, []map[string]any{{"id": "c", "v": 9}, {"id": "b", "v": 11}}, result.(xsql.Collection).ToMaps())
+	// b of 120 and a of 100 expire
+	require.Equal(t, map[string]any{"keys": 2}, op.GetRuntimeDetail())
+
+	// Restore the state from the checkpoint
+	st, err := ctx.GetState(distinctOnStateKey)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(&st))
+	var restored any
+	require.NoError(t, gob.NewDecoder(&buf).Decode(&restored))
+	ctx = mockContext.NewMockContext("TestDistinctOnStream", "op1")
+	require.NoError(t, ctx.PutState(distinctOnStateKey, restored))
+	op = &DistinctOnOp{Keys: keys, Within: 100}
+	require.Nil(t, op.Apply(ctx, dedupRow("c", 12, 240), fv, afv))
+	require.NotNil(t, op.Apply(ctx, dedupRow("a", 13, 240), fv, afv))
+}
+
+func TestDistinctOnWindow(t *testing.T) {
+	ctx := mockContext.NewMockContext("TestDistinctOnWindow", "op1")
+	fv, afv := xsql.NewFunctionValuersForOp(nil)
+	op := &DistinctOnOp{Keys: []ast.Expr{&ast.FieldRef{Name: "id", StreamName: "demo"}}}
+	for i := 0; i < 2; i++ {
+		w := &xsql.WindowTuples{Content: []xsql.Row{dedupRow("a", 1, 0), dedupRow("b", 2, 10), dedupRow("a", 3, 20)}}
+		result := op.Apply(ctx, w, fv, afv)
+		require.Equal(t, []map[string]any{{"id": "a", "v": 1}, {"id": "b", "v": 2}}, result.(xsql.Collection).ToMaps())
+	}
+	require.Nil(t, op.GetRuntimeDetail())
+}
@@ -0,0 +1,319 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"encoding/gob"
+	"fmt"
+	"strings"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+const (
+	fillStateKey = "fill"
+	// defaultFillTTL is the number of the consecutive windows to fill a series without data if the TTL is not set
+	defaultFillTTL = 100
+)
+
+func init() {
+	gob.Register(&lockedState[fillState]{})
+}
+
+// FillOp fills the results of the series which have no data in a window of the FILL clause.
+// It runs after the project, so the results are identified by the selected fields:
+// a series is the selected non-aggregate fields like the group by keys.
+// The filled result copies the keys from the previous result of the series, calculates the
+// window fields like window_end() by the current window and fills the aggregate fields by the fill type.
+// A series is evicted after it has no data for TTL windows.
+type FillOp struct {
+	Fill *ast.Fill
+	// KeyFields are the names of the selected fields to identify a series
+	KeyFields []string
+	// ValueFields are the names of the selected fields with aggregate functions
+	ValueFields []string
+	// WindowFields are the selected fields calculated by the window range
+	WindowFields ast.Fields
+	SendNil      bool
+
+	state operatorState[fillState]
+}
+
+type fillSeries struct {
+	Last map[string]interface{}
+	// Pending are the missing windows of the series waiting for the next result to interpolate linearly
+	Pending []fillWindow
+	// Idle is the number of the consecutive windows without data
+	Idle int
+}
+
+// fillWindow is the window range of a pending window which can be encoded in the state
+type fillWindow struct {
+	Start   int64
+	End     int64
+	Trigger int64
+}
+
+func newFillWindow(wr *xsql.WindowRange) fillWindow {
+	start, _ := wr.FuncValue("window_start")
+	end, _ := wr.FuncValue("window_end")
+	trigger, _ := wr.FuncValue("window_trigger")
+	return fillWindow{Start: start.(int64), End: end.(int64), Trigger: trigger.(int64)}
+}
+
+func (w fillWindow) windowRange() *xsql.WindowRange {
+	return xsql.NewWindowRange(w.Start, w.End, w.Trigger)
+}
+
+// fillState is the known series and their order for the stable output
+type fillState struct {
+	Series map[string]*fillSeries
+	Keys   []string
+}
+
+func newFillState() fillState {
+	return fillState{Series: make(map[string]*fillSeries)}
+}
+
+func (s *fillState) restore() {
+	if s.Series == nil {
+		s.Series = make(map[string]*fillSeries)
+	}
+}
+
+// Apply
+//
+//	input: *xsql.WindowTuples|*xsql.GroupedTuplesSet after the project
+//
+// output: the input collection or *xsql.GroupedTuplesSet with the filled results
+func (p *FillOp) Apply(ctx api.StreamContext, data interface{}, fv *xsql.FunctionValuer, _ *xsql.AggregateFunctionValuer) interface{} {
+	ctx.GetLogger().Debugf("fill plan receive %v", data)
+	switch input := data.(type) {
+	case error:
+		return input
+	case *xsql.WindowTuples:
+		wr := input.GetWindowRange()
+		if wr == nil {
+			return input
+		}
+		ls := p.state.get(ctx, fillStateKey, newFillState)
+		ls.mu.Lock()
+		defer ls.mu.Unlock()
+		s := &ls.data
+		seen := make(map[string]bool, 1)
+		if len(input.Content) > 0 {
+			m := input.ToMap()
+			key := p.seriesKey(m)
+			seen[key] = true
+			gaps, err := p.receive(s, key, m, fv)
+			if err != nil {
+				return err
+			}
+			if len(gaps) == 0 {
+				return input
+			}
+			return &xsql.GroupedTuplesSet{Groups: append(gaps, newFillGroup(m, wr)), WindowRange: wr}
+		}
+		filled, err := p.fillMissing(s, seen, wr, fv)
+		if err != nil {
+			return err
+		}
+		if len(filled) == 0 {
+			return nil
+		}
+		return &xsql.GroupedTuplesSet{Groups: filled, WindowRange: wr}
+	case *xsql.GroupedTuplesSet:
+		wr := input.GetWindowRange()
+		if wr == nil {
+			return input
+		}
+		ls := p.state.get(ctx, fillStateKey, newFillState)
+		ls.mu.Lock()
+		defer ls.mu.Unlock()
+		s := &ls.data
+		seen := make(map[string]bool, len(input.Groups))
+		var result []*xsql.GroupedTuples
+		for _, g := range input.Groups {
+			m := g.ToMap()
+			key := p.seriesKey(m)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			gaps, err := p.receive(s, key, m, fv)
+			if err != nil {
+				return err
+			}
+			result = append(result, gaps...)
+		}
+		result = append(result, input.Groups...)
+		filled, err := p.fillMissing(s, seen, wr, fv)
+		if err != nil {
+			return err
+		}
+		result = append(result, filled...)
+		if len(result) == 0 {
+			return nil
+		}
+		input.Groups = result
+		return input
+	default:
+		return fmt.Errorf("run fill error: invalid input %[1]T(%[1]v)", input)
+	}
+}
+
+func (p *FillOp) seriesKey(m map[string]interface{}) string {
+	var b strings.Builder
+	for _, k := range p.KeyFields {
+		b.WriteString(fmt.Sprintf("%v,", m[k]))
+	}
+	return b.String()
+}
+
+// receive records the result of the series and returns the interpolated results of its pending windows
+func (p *FillOp) receive(s *fillState, key string, m map[string]interface{}, fv *xsql.FunctionValuer) ([]*xsql.GroupedTuples, error) {
+	se, ok := s.Series[key]
+	if !ok {
+		s.Series[key] = &fillSeries{Last: copyMap(m)}
+		s.Keys = append(s.Keys, key)
+		return nil, nil
+	}
+	var result []*xsql.GroupedTuples
+	n := len(se.Pending)
+	for i, pw := range se.Pending {
+		pwr := pw.windowRange()
+		r, err := p.newRow(se.Last, pwr, fv)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range p.ValueFields {
+			p.setValue(r, f, interpolate(se.Last[f], m[f], float64(i+1)/float64(n+1)))
+		}
+		result = append(result, newFillGroup(r, pwr))
+	}
+	se.Pending = nil
+	se.Idle = 0
+	se.Last = copyMap(m)
+	return result, nil
+}
+
+// fillMissing generates the results of the known series which are not seen in the window.
+// The series which have been filled for TTL windows are evicted.
+func (p *FillOp) fillMissing(s *fillState, seen map[string]bool, wr *xsql.WindowRange, fv *xsql.FunctionValuer) ([]*xsql.GroupedTuples, error) {
+	ttl := p.Fill.TTL
+	if ttl <= 0 {
+		ttl = defaultFillTTL
+	}
+	var result []*xsql.GroupedTuples
+	keys := s.Keys[:0]
+	for _, key := range s.Keys {
+		if seen[key] {
+			keys = append(keys, key)
+			continue
+		}
+		se := s.Series[key]
+		if se.Idle >= ttl {
+			delete(s.Series, key)
+			continue
+		}
+		se.Idle++
+		keys = append(keys, key)
+		if p.Fill.FillType == ast.FILL_LINEAR {
+			se.Pending = append(se.Pending, newFillWindow(wr))
+			continue
+		}
+		r, err := p.newRow(se.Last, wr, fv)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range p.ValueFields {
+			switch p.Fill.FillType {
+			case ast.FILL_NULL:
+				p.setValue(r, f, nil)
+			case ast.FILL_VALUE:
+				p.setValue(r, f, p.Fill.Value)
+			}
+		}
+		result = append(result, newFillGroup(r, wr))
+	}
+	s.Keys = keys
+	return result, nil
+}
+
+// GetRuntimeDetail returns the number of the known series
+func (p *FillOp) GetRuntimeDetail() map[string]any {
+	return p.state.detail(func(s *fillState) map[string]any {
+		return map[string]any{"series": len(s.Series)}
+	})
+}
+
+// newRow copies the previous result and calculates the window fields by the window range
+func (p *FillOp) newRow(last map[string]interface{}, wr *xsql.WindowRange, fv *xsql.FunctionValuer) (map[string]interface{}, error) {
+	r := copyMap(last)
+	if len(p.WindowFields) > 0 {
+		ve := &xsql.ValuerEval{Valuer: xsql.MultiValuer(&xsql.WindowRangeValuer{WindowRange: wr}, fv)}
+		for _, f := range p.WindowFields {
+			v := ve.Eval(f.Expr)
+			if e, ok := v.(error); ok {
+				return nil, fmt.Errorf("run fill error: expr %s meet error: %v", f.Expr, e)
+			}
+			p.setValue(r, f.GetName(), v)
+		}
+	}
+	return r, nil
+}
+
+func (p *FillOp) setValue(r map[string]interface{}, name string, v interface{}) {
+	if v == nil && !p.SendNil {
+		delete(r, name)
+		return
+	}
+	if v == nil {
+		v = cast.TNil
+	}
+	r[name] = v
+}
+
+// interpolate returns the linear interpolation of the numbers or the previous value if any is not a number
+func interpolate(prev, next interface{}, ratio float64) interface{} {
+	p, err := cast.ToFloat64(prev, cast.CONVERT_SAMEKIND)
+	if err != nil {
+		return prev
+	}
+	n, err := cast.ToFloat64(next, cast.CONVERT_SAMEKIND)
+	if err != nil {
+		return prev
+	}
+	return p + (n-p)*ratio
+}
+
+func newFillGroup(m map[string]interface{}, wr *xsql.WindowRange) *xsql.GroupedTuples {
+	return &xsql.GroupedTuples{
+		Content:     []xsql.Row{&xsql.Tuple{Message: m}},
+		WindowRange: wr,
+	}
+}
+
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	r := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		r[k] = v
+	}
+	return r
+}
@@ -0,0 +1,169 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func groupedWindow(start int64, rows ...map[string]interface{}) *xsql.GroupedTuplesSet {
+	wr := xsql.NewWindowRange(start, start+10, start+10)
+	groups := make([]*xsql.GroupedTuples, 0, len(rows))
+	for _, r := range rows {
+		groups = append(groups, &xsql.GroupedTuples{Content: []xsql.Row{&xsql.Tuple{Message: r}}, WindowRange: wr})
+	}
+	return &xsql.GroupedTuplesSet{Groups: groups, WindowRange: wr}
+}
+
+func TestFillApply(t *testing.T) {
+	windowFields := ast.Fields{{Name: "window_end", AName: "we", Expr: &ast.Call{Name: "window_end"}}}
+	tests := []struct {
+		name   string
+		fill   *ast.Fill
+		inputs []interface{}
+		expect [][]map[string]interface{}
+	}{
+		{
+			name: "previous",
+			fill: &ast.Fill{FillType: ast.FILL_PREVIOUS},
+			inputs: []interface{}{
+				groupedWindow(0, map[string]interface{}{"id": "a", "avg": 1.0, "we": int64(10)}, map[string]interface{}{"id": "b", "avg": 2.0, "we": int64(10)}),
+				groupedWindow(10, map[string]interface{}{"id": "b", "avg": 3.0, "we": int64(20)}),
+				groupedWindow(20),
+			},
+			expect: [][]map[string]interface{}{
+				{{"id": "a", "avg": 1.0, "we": int64(10)}, {"id": "b", "avg": 2.0, "we": int64(10)}},
+				{{"id": "b", "avg": 3.0, "we": int64(20)}, {"id": "a", "avg": 1.0, "we": int64(20)}},
+				{{"id": "a", "avg": 1.0, "we": int64(30)}, {"id": "b", "avg": 3.0, "we": int64(30)}},
+			},
+		},
+		{
+			name: "null",
+			fill: &ast.Fill{FillType: ast.FILL_NULL},
+			inputs: []interface{}{
+				groupedWindow(0),
+				groupedWindow(10, map[string]interface{}{"id": "a", "avg": 1.0, "we": int64(20)}),
+				groupedWindow(20),
+			},
+			expect: [][]map[string]interface{}{
+				nil,
+				{{"id": "a", "avg": 1.0, "we": int64(20)}},
+				{{"id": "a", "we": int64(30)}},
+			},
+		},
+		{
+			name: "value",
+			fill: &ast.Fill{FillType: ast.FILL_VALUE, Value: int64(0)},
+			inputs: []interface{}{
+				groupedWindow(0, map[string]interface{}{"id": "a", "avg": 1.0, "we": int64(10)}),
+				groupedWindow(10),
+			},
+			expect: [][]map[string]interface{}{
+				{{"id": "a", "avg": 1.0, "we": int64(10)}},
+				{{"id": "a", "avg": int64(0), "we": int64(20)}},
+			},
+		},
+		{
+			name: "linear",
+			fill: &ast.Fill{FillType: ast.FILL_LINEAR},
+			inputs: []interface{}{
+				groupedWindow(0, map[string]interface{}{"id": "a", "avg": 1.0, "we": int64(10)}),
+				groupedWindow(10),
+				groupedWindow(20),
+				groupedWindow(30, map[string]interface{}{"id": "a", "avg": int64(4), "we": int64(40)}),
+			},
+			expect: [][]map[string]interface{}{
+				{{"id": "a", "avg": 1.0, "we": int64(10)}},
+				nil,
+				nil,
+				{{"id": "a", "avg": 2.0, "we": int64(20)}, {"id": "a", "avg": 3.0, "we": int64(30)}, {"id": "a", "avg": int64(4), "we": int64(40)}},
+			},
+		},
+		{
+			name: "window tuples",
+			fill: &ast.Fill{FillType: ast.FILL_LINEAR},
+			inputs: []interface{}{
+				&xsql.WindowTuples{Content: []xsql.Row{&xsql.Tuple{Message: map[string]interface{}{"avg": 1.0, "we": int64(10)}}}, WindowRange: xsql.NewWindowRange(0, 10, 10)},
+				&xsql.WindowTuples{Content: []xsql.Row{}, WindowRange: xsql.NewWindowRange(10, 20, 20)},
+				&xsql.WindowTuples{Content: []xsql.Row{&xsql.Tuple{Message: map[string]interface{}{"avg": 3.0, "we": int64(30)}}}, WindowRange: xsql.NewWindowRange(20, 30, 30)},
+			},
+			expect: [][]map[string]interface{}{
+				{{"avg": 1.0, "we": int64(10)}},
+				nil,
+				{{"avg": 2.0, "we": int64(20)}, {"avg": 3.0, "we": int64(30)}},
+			},
+		},
+	}
+	fv, afv := xsql.NewFunctionValuersForOp(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := mockContext.NewMockContext("TestFillApply", "op1")
+			op := &FillOp{Fill: tt.fill, KeyFields: []string{"id"}, ValueFields: []string{"avg"}, WindowFields: windowFields}
+			for i, input := range tt.inputs {
+				if wt, ok := input.(*xsql.WindowTuples); ok {
+					wt.SetIsAgg(true)
+				}
+				result := op.Apply(ctx, input, fv, afv)
+				if tt.expect[i] == nil {
+					require.Nil(t, result, "window %d", i)
+					continue
+				}
+				c, ok := result.(xsql.Collection)
+				require.True(t, ok, "window %d", i)
+				require.Equal(t, tt.expect[i], c.ToMaps(), "window %d", i)
+			}
+		})
+	}
+}
+
+func TestFillEvict(t *testing.T) {
+	ctx := mockContext.NewMockContext("TestFillEvict", "op1")
+	fv, afv := xsql.NewFunctionValuersForOp(nil)
+	fill := &ast.Fill{FillType: ast.FILL_LINEAR, TTL: 2}
+	op := &FillOp{Fill: fill, KeyFields: []string{"id"}, ValueFields: []string{"avg"}}
+	result := op.Apply(ctx, groupedWindow(0, map[string]interface{}{"id": "a", "avg": 1.0}, map[string]interface{}{"id": "b", "avg": 1.0}), fv, afv)
+	require.Equal(t, []map[string]interface{}{{"id": "a", "avg": 1.0}, {"id": "b", "avg": 1.0}}, result.(xsql.Collection).ToMaps())
+	require.Nil(t, op.Apply(ctx, groupedWindow(10), fv, afv))
+	require.Equal(t, map[string]any{"series": 2}, op.GetRuntimeDetail())
+
+	// Restore the state from the checkpoint
+	st, err := ctx.GetState(fillStateKey)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(&st))
+	var restored any
+	require.NoError(t, gob.NewDecoder(&buf).Decode(&restored))
+	ctx = mockContext.NewMockContext("TestFillEvict", "op1")
+	require.NoError(t, ctx.PutState(fillStateKey, restored))
+	op = &FillOp{Fill: fill, KeyFields: []string{"id"}, ValueFields: []string{"avg"}}
+
+	// b fills the pending window from the restored state
+	result = op.Apply(ctx, groupedWindow(20, map[string]interface{}{"id": "b", "avg": 3.0}), fv, afv)
+	require.Equal(t, []map[string]interface{}{{"id": "b", "avg": 2.0}, {"id": "b", "avg": 3.0}}, result.(xsql.Collection).ToMaps())
+	// a is evicted after 2 windows without data and its pending windows are dropped
+	result = op.Apply(ctx, groupedWindow(30, map[string]interface{}{"id": "b", "avg": 4.0}), fv, afv)
+	require.Equal(t, []map[string]interface{}{{"id": "b", "avg": 4.0}}, result.(xsql.Collection).ToMaps())
+	require.Equal(t, map[string]any{"series": 1}, op.GetRuntimeDetail())
+	result = op.Apply(ctx, groupedWindow(40, map[string]interface{}{"id": "a", "avg": 5.0}), fv, afv)
+	require.Equal(t, []map[string]interface{}{{"id": "a", "avg": 5.0}}, result.(xsql.Collection).ToMaps())
+}
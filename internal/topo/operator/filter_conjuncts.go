@@ -0,0 +1,149 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"math"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+// ReorderInterval is the number of evaluations between two reorders of the conjuncts
+const ReorderInterval = 1000
+
+// costSampleInterval is the number of evaluations of a conjunct between two timings of its cost.
+// The first evaluation is always timed.
+const costSampleInterval = 16
+
+// conjunct keeps the statistics without lock. They may be slightly off when the operator runs concurrently,
+// which is acceptable to decide the order.
+type conjunct struct {
+	expr      ast.Expr
+	evaluated atomic.Int64
+	passed    atomic.Int64
+	// costNs is the total cost of the sampled evaluations
+	costNs  atomic.Int64
+	sampled atomic.Int64
+}
+
+// rank is the expected cost to filter out a row by the conjunct. The conjuncts with lower rank run first.
+// The conjunct which never filters out a row or is never evaluated runs last.
+func (c *conjunct) rank() float64 {
+	evaluated, passed := c.evaluated.Load(), c.passed.Load()
+	if evaluated == 0 || passed >= evaluated {
+		return math.Inf(1)
+	}
+	return c.avgCost() / (1 - float64(passed)/float64(evaluated))
+}
+
+func (c *conjunct) avgCost() float64 {
+	return float64(c.costNs.Load()) / float64(max(c.sampled.Load(), 1))
+}
+
+// conjunctEvaluator evaluates the conjuncts of an AND condition one by one, and reorders them periodically
+// by the observed selectivity and cost. The reorder replaces the slice so that the evaluation needs no lock.
+type conjunctEvaluator struct {
+	conjuncts atomic.Pointer[[]*conjunct]
+	count     atomic.Int64
+}
+
+func newConjunctEvaluator(exprs []ast.Expr) *conjunctEvaluator {
+	cs := make([]*conjunct, len(exprs))
+	for i, e := range exprs {
+		cs[i] = &conjunct{expr: e}
+	}
+	c := &conjunctEvaluator{}
+	c.conjuncts.Store(&cs)
+	return c
+}
+
+// eval returns false if any conjunct is false or nil. Otherwise, if any conjunct returns an error or a non-bool
+// value, it evaluates the original condition to return its result. Unlike the original condition, the error of a
+// conjunct is not returned if a conjunct which runs before it returns false.
+func (c *conjunctEvaluator) eval(ve *xsql.ValuerEval, condition ast.Expr) any {
+	var result any = true
+loop:
+	for _, cj := range *c.conjuncts.Load() {
+		var r any
+		if cj.evaluated.Add(1)%costSampleInterval == 1 {
+			start := time.Now()
+			r = ve.Eval(cj.expr)
+			cj.costNs.Add(int64(time.Since(start)))
+			cj.sampled.Add(1)
+		} else {
+			r = ve.Eval(cj.expr)
+		}
+		switch v := r.(type) {
+		case bool:
+			if !v {
+				result = false
+				break loop
+			}
+			cj.passed.Add(1)
+		case nil:
+			result = false
+			break loop
+		default:
+			result = ve.Eval(condition)
+			break loop
+		}
+	}
+	if c.count.Add(1)%ReorderInterval == 0 {
+		c.reorder()
+	}
+	return result
+}
+
+func (c *conjunctEvaluator) reorder() {
+	cs := append([]*conjunct(nil), *c.conjuncts.Load()...)
+	ranks := make(map[*conjunct]float64, len(cs))
+	for _, cj := range cs {
+		ranks[cj] = cj.rank()
+	}
+	sort.SliceStable(cs, func(i, j int) bool {
+		return ranks[cs[i]] < ranks[cs[j]]
+	})
+	c.conjuncts.Store(&cs)
+	// Decay the statistics so that the order follows the change of the data
+	for _, cj := range cs {
+		cj.evaluated.Add(-cj.evaluated.Load() / 2)
+		cj.passed.Add(-cj.passed.Load() / 2)
+		cj.costNs.Add(-cj.costNs.Load() / 2)
+		cj.sampled.Add(-cj.sampled.Load() / 2)
+	}
+}
+
+func (c *conjunctEvaluator) detail() []map[string]any {
+	cs := *c.conjuncts.Load()
+	result := make([]map[string]any, len(cs))
+	for i, cj := range cs {
+		evaluated, passed := cj.evaluated.Load(), cj.passed.Load()
+		d := map[string]any{
+			"condition": cj.expr.String(),
+			"evaluated": evaluated,
+			"passed":    passed,
+		}
+		if evaluated > 0 {
+			d["selectivity"] = float64(passed) / float64(evaluated)
+			d["avgCostNs"] = int64(cj.avgCost())
+		}
+		result[i] = d
+	}
+	return result
+}
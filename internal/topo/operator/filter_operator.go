@@ -16,6 +16,7 @@ package operator
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
@@ -26,6 +27,11 @@ import (
 type FilterOp struct {
 	Condition  ast.Expr
 	StateFuncs []*ast.Call
+	// Conjuncts are the parts of the AND condition. If set, they are evaluated in the order of the observed
+	// selectivity and cost instead of evaluating the condition
+	Conjuncts []ast.Expr
+	evaluator *conjunctEvaluator
+	once      sync.Once
 }
 
 // Apply the filter operator to each message in the stream
@@ -41,7 +47,7 @@ func (p *FilterOp) Apply(ctx api.StreamContext, data interface{}, fv *xsql.Funct
 		return input
 	case xsql.Row:
 		ve := &xsql.ValuerEval{Valuer: xsql.MultiValuer(input, fv)}
-		result := ve.Eval(p.Condition)
+		result := p.eval(ve)
 		switch r := result.(type) {
 		case error:
 			return fmt.Errorf("run Where error: %s", r)
@@ -61,7 +67,7 @@ func (p *FilterOp) Apply(ctx api.StreamContext, data interface{}, fv *xsql.Funct
 		var sel []int
 		err := input.Range(func(i int, r xsql.ReadonlyRow) (bool, error) {
 			ve := &xsql.ValuerEval{Valuer: xsql.MultiValuer(r, fv)}
-			result := ve.Eval(p.Condition)
+			result := p.eval(ve)
 			switch val := result.(type) {
 			case error:
 				return false, fmt.Errorf("run Where error: %s", val)
@@ -89,3 +95,28 @@ func (p *FilterOp) Apply(ctx api.StreamContext, data interface{}, fv *xsql.Funct
 	}
 	return nil
 }
+
+func (p *FilterOp) eval(ve *xsql.ValuerEval) any {
+	if e := p.getEvaluator(); e != nil {
+		return e.eval(ve, p.Condition)
+	}
+	return ve.Eval(p.Condition)
+}
+
+func (p *FilterOp) getEvaluator() *conjunctEvaluator {
+	p.once.Do(func() {
+		if len(p.Conjuncts) > 1 {
+			p.evaluator = newConjunctEvaluator(p.Conjuncts)
+		}
+	})
+	return p.evaluator
+}
+
+// GetRuntimeDetail returns the observed statistics of the conjuncts in the current evaluation order
+func (p *FilterOp) GetRuntimeDetail() map[string]any {
+	e := p.getEvaluator()
+	if e == nil {
+		return nil
+	}
+	return map[string]any{"conjuncts": e.detail()}
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -26,6 +27,7 @@ import (
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/context"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 )
 
@@ -754,3 +756,62 @@ func TestFilterPlanError(t *testing.T) {
 		}
 	}
 }
+
+func TestFilterConjunctsReorder(t *testing.T) {
+	stmt, err := xsql.NewParser(strings.NewReader("SELECT * FROM tbl WHERE a > 0 AND b > 0")).Parse()
+	assert.NoError(t, err)
+	cond := stmt.Condition.(*ast.BinaryExpr)
+	contextLogger := conf.Log.WithField("rule", "TestFilterConjunctsReorder")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	fv, afv := xsql.NewFunctionValuersForOp(ctx)
+	pp := &FilterOp{Condition: cond, Conjuncts: []ast.Expr{cond.LHS, cond.RHS}}
+	passed := 0
+	for i := 0; i < ReorderInterval; i++ {
+		// a > 0 always passes and b > 0 passes one in ten
+		data := &xsql.Tuple{Emitter: "tbl", Message: xsql.Message{"a": int64(1), "b": int64(i % 10)}}
+		if pp.Apply(ctx, data, fv, afv) != nil {
+			passed++
+		}
+	}
+	assert.Equal(t, ReorderInterval/10*9, passed)
+	detail := pp.GetRuntimeDetail()["conjuncts"].([]map[string]any)
+	assert.Equal(t, "binaryExpr:{ $$default.b > 0 }", detail[0]["condition"])
+	assert.Equal(t, "binaryExpr:{ $$default.a > 0 }", detail[1]["condition"])
+	// The original condition decides the result if a conjunct does not return bool
+	data := &xsql.Tuple{Emitter: "tbl", Message: xsql.Message{"a": "x", "b": int64(1)}}
+	_, ok := pp.Apply(ctx, data, fv, afv).(error)
+	assert.True(t, ok)
+	// The error of a > 0 is skipped because b > 0 runs first and filters out the row
+	data = &xsql.Tuple{Emitter: "tbl", Message: xsql.Message{"a": "x", "b": int64(0)}}
+	assert.Nil(t, pp.Apply(ctx, data, fv, afv))
+	_, ok = (&FilterOp{Condition: cond}).Apply(ctx, data, fv, afv).(error)
+	assert.True(t, ok)
+	// No reorder without conjuncts
+	assert.Nil(t, (&FilterOp{Condition: cond}).GetRuntimeDetail())
+}
+
+func TestFilterConjunctsConcurrent(t *testing.T) {
+	stmt, err := xsql.NewParser(strings.NewReader("SELECT * FROM tbl WHERE a > 0 AND b > 0")).Parse()
+	assert.NoError(t, err)
+	cond := stmt.Condition.(*ast.BinaryExpr)
+	contextLogger := conf.Log.WithField("rule", "TestFilterConjunctsConcurrent")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	pp := &FilterOp{Condition: cond, Conjuncts: []ast.Expr{cond.LHS, cond.RHS}}
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fv, afv := xsql.NewFunctionValuersForOp(ctx)
+			for i := 0; i < ReorderInterval; i++ {
+				data := &xsql.Tuple{Emitter: "tbl", Message: xsql.Message{"a": int64(1), "b": int64(i % 10)}}
+				pp.Apply(ctx, data, fv, afv)
+			}
+		}()
+	}
+	wg.Wait()
+	detail := pp.GetRuntimeDetail()["conjuncts"].([]map[string]any)
+	assert.Equal(t, "binaryExpr:{ $$default.b > 0 }", detail[0]["condition"])
+	// the cost is sampled but reported for every evaluated conjunct
+	assert.Contains(t, detail[0], "avgCostNs")
+}
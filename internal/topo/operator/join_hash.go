@@ -0,0 +1,181 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+// hashIndex indexes the rows of one side of a join by the values of the equi-join conditions, so that the rows of
+// the other side only evaluate the join condition with the rows of the same key instead of all the rows.
+// To return the same result and errors as comparing all the rows, a row whose key is of different types with the
+// indexed keys is compared with all the rows.
+type hashIndex[T xsql.Row] struct {
+	probeKeys []ast.Expr
+	rows      []T
+	table     map[string][]T
+	// kinds are the types of the indexed keys
+	kinds map[string]struct{}
+}
+
+// newHashIndex builds the index of the rows from the buildStreams. It returns nil if the conditions have no
+// equi-join condition between the probeStreams and the buildStreams, or any key is not a number, string or bool.
+func newHashIndex[T xsql.Row](conditions []ast.Expr, probeStreams, buildStreams []string, rows []T, fv *xsql.FunctionValuer) *hashIndex[T] {
+	probeKeys, buildKeys := extractEquiKeys(conditions, probeStreams, buildStreams)
+	if len(probeKeys) == 0 {
+		return nil
+	}
+	h := &hashIndex[T]{probeKeys: probeKeys, rows: rows, table: make(map[string][]T), kinds: make(map[string]struct{})}
+	for _, r := range rows {
+		k, kinds, status := hashKey(&xsql.ValuerEval{Valuer: xsql.MultiValuer(r, fv)}, buildKeys)
+		switch status {
+		case keyInvalid:
+			return nil
+		case keyOk:
+			h.table[k] = append(h.table[k], r)
+			h.kinds[kinds] = struct{}{}
+		}
+		// The row with nil key never matches as nil is not equal to anything
+	}
+	return h
+}
+
+// probe returns the rows which may match the row in the original order
+func (h *hashIndex[T]) probe(row xsql.Row, fv *xsql.FunctionValuer) []T {
+	k, kinds, status := hashKey(&xsql.ValuerEval{Valuer: xsql.MultiValuer(row, fv)}, h.probeKeys)
+	switch status {
+	case keyOk:
+		if _, ok := h.kinds[kinds]; ok && len(h.kinds) == 1 {
+			return h.table[k]
+		}
+	case keyNil:
+		if len(h.probeKeys) == 1 {
+			return nil
+		}
+	}
+	return h.rows
+}
+
+// extractEquiKeys finds the conditions like `a.id = b.id` whose sides refer to the probe and the build streams
+// respectively, and returns the expressions of both sides
+func extractEquiKeys(conditions []ast.Expr, probeStreams, buildStreams []string) (probeKeys, buildKeys []ast.Expr) {
+	for _, c := range conditions {
+		be, ok := c.(*ast.BinaryExpr)
+		if !ok || be.OP != ast.EQ {
+			continue
+		}
+		switch {
+		case refersOnly(be.LHS, probeStreams) && refersOnly(be.RHS, buildStreams):
+			probeKeys = append(probeKeys, be.LHS)
+			buildKeys = append(buildKeys, be.RHS)
+		case refersOnly(be.LHS, buildStreams) && refersOnly(be.RHS, probeStreams):
+			probeKeys = append(probeKeys, be.RHS)
+			buildKeys = append(buildKeys, be.LHS)
+		}
+	}
+	return
+}
+
+// refersOnly checks if the expression refers to some of the streams and nothing else
+func refersOnly(expr ast.Expr, streams []string) bool {
+	refs := refStreams(expr)
+	if len(refs) == 0 {
+		return false
+	}
+	for _, r := range refs {
+		found := false
+		for _, s := range streams {
+			if r == s {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// refStreams returns the streams referred by the expression. The default stream is returned if the stream of a
+// field is unknown.
+func refStreams(expr ast.Expr) []string {
+	var refs []string
+	ast.WalkFunc(expr, func(n ast.Node) bool {
+		if f, ok := n.(*ast.FieldRef); ok {
+			for _, s := range f.RefSources() {
+				refs = append(refs, string(s))
+			}
+		}
+		return true
+	})
+	return refs
+}
+
+// splitConjuncts flattens the AND expressions
+func splitConjuncts(expr ast.Expr) []ast.Expr {
+	switch e := expr.(type) {
+	case nil:
+		return nil
+	case *ast.BinaryExpr:
+		if e.OP == ast.AND {
+			return append(splitConjuncts(e.LHS), splitConjuncts(e.RHS)...)
+		}
+	case *ast.ParenExpr:
+		if be, ok := e.Expr.(*ast.BinaryExpr); ok && be.OP == ast.AND {
+			return splitConjuncts(be)
+		}
+	}
+	return []ast.Expr{expr}
+}
+
+const (
+	keyOk = iota
+	keyNil
+	keyInvalid
+)
+
+// hashKey evaluates the key expressions and returns the key and the types of the values. The numbers of different
+// types are converted so that 1 and 1.0 have the same key.
+func hashKey(ve *xsql.ValuerEval, keys []ast.Expr) (string, string, int) {
+	var sb strings.Builder
+	kinds := make([]byte, 0, len(keys))
+	status := keyOk
+	for _, k := range keys {
+		switch v := ve.Eval(k).(type) {
+		case nil:
+			status = keyNil
+		case string:
+			kinds = append(kinds, 's')
+			sb.WriteString(v)
+		case bool:
+			kinds = append(kinds, 'b')
+			sb.WriteString(strconv.FormatBool(v))
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+			kinds = append(kinds, 'n')
+			f, _ := cast.ToFloat64(v, cast.CONVERT_ALL)
+			sb.WriteString(strconv.FormatFloat(f, 'g', -1, 64))
+		default:
+			return "", "", keyInvalid
+		}
+		sb.WriteByte(0)
+	}
+	return sb.String(), string(kinds), status
+}
@@ -20,6 +20,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/require"
+
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/context"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
@@ -1124,3 +1126,67 @@ func TestMultiJoinPlan_Apply(t *testing.T) {
 		}
 	}
 }
+
+func TestMultiInnerJoinReorder(t *testing.T) {
+	r := func(emitter string, id int, f string) *xsql.Tuple {
+		return &xsql.Tuple{Emitter: emitter, Message: xsql.Message{"id": id, "f": f}}
+	}
+	data := &xsql.WindowTuples{
+		Content: []xsql.Row{
+			r("src1", 1, "v1"), r("src1", 2, "v2"), r("src1", 1, "v3"), r("src1", 3, "v4"),
+			r("src2", 2, "w1"), r("src2", 1, "w2"), r("src2", 1, "w3"),
+			r("src3", 1, "x1"),
+		},
+	}
+	tests := []struct {
+		sql    string
+		order  []string
+		result [][]xsql.Row
+	}{
+		{
+			sql:   "SELECT * FROM src1 INNER JOIN src2 ON src1.id = src2.id INNER JOIN src3 ON src2.id = src3.id AND src3.f = \"x1\"",
+			order: []string{"src3", "src2", "src1"},
+			result: [][]xsql.Row{
+				{r("src1", 1, "v1"), r("src2", 1, "w2"), r("src3", 1, "x1")},
+				{r("src1", 1, "v1"), r("src2", 1, "w3"), r("src3", 1, "x1")},
+				{r("src1", 1, "v3"), r("src2", 1, "w2"), r("src3", 1, "x1")},
+				{r("src1", 1, "v3"), r("src2", 1, "w3"), r("src3", 1, "x1")},
+			},
+		},
+		{
+			sql:   "SELECT * FROM src1 AS a INNER JOIN src2 AS b ON a.id = b.id INNER JOIN src3 AS c ON c.f = \"x2\"",
+			order: []string{"c"},
+		},
+	}
+	contextLogger := conf.Log.WithField("rule", "TestMultiInnerJoinReorder")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
+			require.NoError(t, err)
+			fv, afv := xsql.NewFunctionValuersForOp(nil)
+			pp := &JoinOp{Joins: stmt.Joins, From: stmt.Sources[0].(*ast.Table)}
+			input := data
+			if stmt.Joins[0].Alias != "" {
+				input = &xsql.WindowTuples{}
+				for _, row := range data.Content {
+					tp := row.(*xsql.Tuple)
+					input.Content = append(input.Content, &xsql.Tuple{Emitter: map[string]string{"src1": "a", "src2": "b", "src3": "c"}[tp.Emitter], Message: tp.Message})
+				}
+			}
+			result := pp.Apply(ctx, input, fv, afv)
+			if tt.result == nil {
+				require.Nil(t, result)
+			} else {
+				jts := result.(*xsql.JoinTuples)
+				require.Len(t, jts.Content, len(tt.result))
+				for i, jt := range jts.Content {
+					require.Equal(t, tt.result[i], jt.Tuples)
+				}
+			}
+			require.Equal(t, map[string]any{"joinOrder": tt.order}, pp.GetRuntimeDetail())
+		})
+	}
+	// Outer joins are not reordered
+	require.Nil(t, newJoinGraph(&ast.Table{Name: "src1"}, ast.Joins{{Name: "src2", JoinType: ast.INNER_JOIN}, {Name: "src3", JoinType: ast.LEFT_JOIN}}))
+}
@@ -16,6 +16,8 @@ package operator
 
 import (
 	"fmt"
+	"sync"
+	"sync/atomic"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
@@ -27,6 +29,11 @@ import (
 type JoinOp struct {
 	From  *ast.Table
 	Joins ast.Joins
+
+	once  sync.Once
+	graph *joinGraph
+	// joinOrder is the order of the streams joined for the last window if the joins are reordered
+	joinOrder atomic.Value
 }
 
 // Apply JoinOp to join two streams. If running in continuous query, the inner join will always return empty result because there is only one stream data.
@@ -46,6 +53,25 @@ func (jp *JoinOp) Apply(ctx api.StreamContext, data interface{}, fv *xsql.Functi
 	default:
 		return fmt.Errorf("run Join error: join is only supported in window")
 	}
+	jp.once.Do(func() {
+		jp.graph = newJoinGraph(jp.From, jp.Joins)
+	})
+	if jp.graph != nil {
+		result, order, err := jp.graph.eval(ctx, input, fv)
+		if err != nil {
+			return fmt.Errorf("run Join error: %s", err)
+		}
+		if result == nil {
+			return nil
+		}
+		jp.joinOrder.Store(order)
+		if result.Len() <= 0 {
+			log.Debugf("join plan yields nothing")
+			return nil
+		}
+		result.WindowRange = input.GetWindowRange()
+		return result
+	}
 	result := &xsql.JoinTuples{Content: make([]*xsql.JoinTuple, 0)}
 	for i, join := range jp.Joins {
 		select {
@@ -60,7 +86,7 @@ func (jp *JoinOp) Apply(ctx api.StreamContext, data interface{}, fv *xsql.Functi
 			}
 			result = v
 		} else {
-			r1, err := jp.evalJoinSets(result, input, join, fv)
+			r1, err := jp.evalJoinSets(result, input, join, jp.leftStreams(i), fv)
 			if err != nil {
 				return fmt.Errorf("run Join error: %s", err)
 			}
@@ -77,6 +103,14 @@ func (jp *JoinOp) Apply(ctx api.StreamContext, data interface{}, fv *xsql.Functi
 	return result
 }
 
+// GetRuntimeDetail returns the order of the streams joined for the last window if the joins are reordered
+func (jp *JoinOp) GetRuntimeDetail() map[string]any {
+	if order, ok := jp.joinOrder.Load().([]string); ok {
+		return map[string]any{"joinOrder": order}
+	}
+	return nil
+}
+
 func (jp *JoinOp) getStreamNames(join *ast.Join) ([]string, error) {
 	var srcs []string
 	keys := make(map[ast.StreamName]bool)
@@ -114,7 +148,7 @@ func (jp *JoinOp) getStreamNames(join *ast.Join) ([]string, error) {
 func (jp *JoinOp) evalSet(ctx api.StreamContext, input xsql.Collection, join ast.Join, fv *xsql.FunctionValuer) (*xsql.JoinTuples, error) {
 	var leftStream, rightStream string
 
-	if join.JoinType != ast.CROSS_JOIN {
+	if join.JoinType != ast.CROSS_JOIN && join.JoinType != ast.SEMI_JOIN && join.JoinType != ast.ANTI_JOIN {
 		streams, err := jp.getStreamNames(&join)
 		if err != nil {
 			return nil, err
@@ -145,14 +179,34 @@ func (jp *JoinOp) evalSet(ctx api.StreamContext, input xsql.Collection, join ast
 	if join.JoinType == ast.RIGHT_JOIN {
 		return jp.evalSetWithRightJoin(input, join, false, fv)
 	}
+	if join.JoinType == ast.SEMI_JOIN || join.JoinType == ast.ANTI_JOIN {
+		lts := make([]*xsql.JoinTuple, len(lefts))
+		for i, left := range lefts {
+			lts[i] = &xsql.JoinTuple{Tuples: []xsql.Row{left}}
+		}
+		content, err := evalSemiJoin(lts, rights, join, []string{leftStream}, rightStream, fv)
+		if err != nil {
+			return nil, err
+		}
+		sets.Content = content
+		return sets, nil
+	}
+	var hi *hashIndex[xsql.Row]
+	if join.JoinType != ast.CROSS_JOIN {
+		hi = newHashIndex(splitConjuncts(join.Expr), []string{leftStream}, []string{rightStream}, rights, fv)
+	}
 	for _, left := range lefts {
 		select {
 		case <-ctx.Done():
 			return nil, nil
 		default:
 		}
+		candidates := rights
+		if hi != nil {
+			candidates = hi.probe(left, fv)
+		}
 		leftJoined := false
-		for index, right := range rights {
+		for index, right := range candidates {
 			tupleJoined := false
 			merged := &xsql.JoinTuple{}
 			if join.JoinType == ast.LEFT_JOIN || join.JoinType == ast.FULL_JOIN || join.JoinType == ast.CROSS_JOIN {
@@ -186,7 +240,7 @@ func (jp *JoinOp) evalSet(ctx api.StreamContext, input xsql.Collection, join ast
 					return nil, fmt.Errorf("invalid join condition that returns non-bool value %[1]T(%[1]v)", val)
 				}
 			}
-			if tupleJoined || (!leftJoined && index == len(rights)-1 && len(merged.Tuples) > 0) {
+			if tupleJoined || (!leftJoined && index == len(candidates)-1 && len(merged.Tuples) > 0) {
 				leftJoined = true
 				sets.Content = append(sets.Content, merged)
 			}
@@ -236,10 +290,14 @@ func (jp *JoinOp) evalSetWithRightJoin(input xsql.Collection, join ast.Join, exc
 	rights = input.GetBySrc(rightStream)
 
 	sets := &xsql.JoinTuples{Content: make([]*xsql.JoinTuple, 0)}
-
+	hi := newHashIndex(splitConjuncts(join.Expr), []string{rightStream}, []string{leftStream}, lefts, fv)
 	for _, right := range rights {
+		candidates := lefts
+		if hi != nil {
+			candidates = hi.probe(right, fv)
+		}
 		isJoint := false
-		for index, left := range lefts {
+		for index, left := range candidates {
 			tupleJoined := false
 			merged := &xsql.JoinTuple{}
 			merged.AddTuple(right)
@@ -261,7 +319,7 @@ func (jp *JoinOp) evalSetWithRightJoin(input xsql.Collection, join ast.Join, exc
 			default:
 				return nil, fmt.Errorf("invalid join condition that returns non-bool value %[1]T(%[1]v)", val)
 			}
-			if !excludeJoint && (tupleJoined || (!isJoint && index == len(lefts)-1 && len(merged.Tuples) > 0)) {
+			if !excludeJoint && (tupleJoined || (!isJoint && index == len(candidates)-1 && len(merged.Tuples) > 0)) {
 				isJoint = true
 				sets.Content = append(sets.Content, merged)
 			}
@@ -275,7 +333,7 @@ func (jp *JoinOp) evalSetWithRightJoin(input xsql.Collection, join ast.Join, exc
 	return sets, nil
 }
 
-func (jp *JoinOp) evalJoinSets(set *xsql.JoinTuples, input xsql.Collection, join ast.Join, fv *xsql.FunctionValuer) (interface{}, error) {
+func (jp *JoinOp) evalJoinSets(set *xsql.JoinTuples, input xsql.Collection, join ast.Join, leftStreams []string, fv *xsql.FunctionValuer) (interface{}, error) {
 	var rightStream string
 	if join.Alias == "" {
 		rightStream = join.Name
@@ -287,11 +345,27 @@ func (jp *JoinOp) evalJoinSets(set *xsql.JoinTuples, input xsql.Collection, join
 
 	newSets := &xsql.JoinTuples{Content: make([]*xsql.JoinTuple, 0)}
 	if join.JoinType == ast.RIGHT_JOIN {
-		return jp.evalRightJoinSets(set, input, join, false, fv)
+		return jp.evalRightJoinSets(set, input, join, leftStreams, false, fv)
+	}
+	if join.JoinType == ast.SEMI_JOIN || join.JoinType == ast.ANTI_JOIN {
+		content, err := evalSemiJoin(set.Content, rights, join, leftStreams, rightStream, fv)
+		if err != nil {
+			return nil, err
+		}
+		newSets.Content = content
+		return newSets, nil
+	}
+	var hi *hashIndex[xsql.Row]
+	if join.JoinType != ast.CROSS_JOIN {
+		hi = newHashIndex(splitConjuncts(join.Expr), leftStreams, []string{rightStream}, rights, fv)
 	}
 	for _, left := range set.Content {
+		candidates := rights
+		if hi != nil {
+			candidates = hi.probe(left, fv)
+		}
 		leftJoined := false
-		for index, right := range rights {
+		for index, right := range candidates {
 			tupleJoined := false
 			merged := &xsql.JoinTuple{}
 			if join.JoinType == ast.LEFT_JOIN || join.JoinType == ast.FULL_JOIN || join.JoinType == ast.CROSS_JOIN {
@@ -324,7 +398,7 @@ func (jp *JoinOp) evalJoinSets(set *xsql.JoinTuples, input xsql.Collection, join
 					return nil, fmt.Errorf("invalid join condition that returns non-bool value %[1]T(%[1]v)", val)
 				}
 			}
-			if tupleJoined || (!leftJoined && index == len(rights)-1 && len(merged.Tuples) > 0) {
+			if tupleJoined || (!leftJoined && index == len(candidates)-1 && len(merged.Tuples) > 0) {
 				leftJoined = true
 				newSets.Content = append(newSets.Content, merged)
 			}
@@ -338,7 +412,7 @@ func (jp *JoinOp) evalJoinSets(set *xsql.JoinTuples, input xsql.Collection, join
 	}
 
 	if join.JoinType == ast.FULL_JOIN {
-		if rightJoinSet, err := jp.evalRightJoinSets(set, input, join, true, fv); err == nil && len(rightJoinSet.Content) > 0 {
+		if rightJoinSet, err := jp.evalRightJoinSets(set, input, join, leftStreams, true, fv); err == nil && len(rightJoinSet.Content) > 0 {
 			newSets.Content = append(newSets.Content, rightJoinSet.Content...)
 		}
 	}
@@ -346,7 +420,7 @@ func (jp *JoinOp) evalJoinSets(set *xsql.JoinTuples, input xsql.Collection, join
 	return newSets, nil
 }
 
-func (jp *JoinOp) evalRightJoinSets(set *xsql.JoinTuples, input xsql.Collection, join ast.Join, excludeJoint bool, fv *xsql.FunctionValuer) (*xsql.JoinTuples, error) {
+func (jp *JoinOp) evalRightJoinSets(set *xsql.JoinTuples, input xsql.Collection, join ast.Join, leftStreams []string, excludeJoint bool, fv *xsql.FunctionValuer) (*xsql.JoinTuples, error) {
 	var rightStream string
 	if join.Alias == "" {
 		rightStream = join.Name
@@ -356,10 +430,14 @@ func (jp *JoinOp) evalRightJoinSets(set *xsql.JoinTuples, input xsql.Collection,
 	rights := input.GetBySrc(rightStream)
 
 	newSets := &xsql.JoinTuples{Content: make([]*xsql.JoinTuple, 0)}
-
+	hi := newHashIndex(splitConjuncts(join.Expr), []string{rightStream}, leftStreams, set.Content, fv)
 	for _, right := range rights {
+		candidates := set.Content
+		if hi != nil {
+			candidates = hi.probe(right, fv)
+		}
 		isJoint := false
-		for index, left := range set.Content {
+		for index, left := range candidates {
 			tupleJoined := false
 			merged := &xsql.JoinTuple{}
 			merged.AddTuple(right)
@@ -382,7 +460,7 @@ func (jp *JoinOp) evalRightJoinSets(set *xsql.JoinTuples, input xsql.Collection,
 			default:
 				return nil, fmt.Errorf("invalid join condition that returns non-bool value %[1]T(%[1]v)", val)
 			}
-			if !excludeJoint && (tupleJoined || (!isJoint && index == len(set.Content)-1 && len(merged.Tuples) > 0)) {
+			if !excludeJoint && (tupleJoined || (!isJoint && index == len(candidates)-1 && len(merged.Tuples) > 0)) {
 				isJoint = true
 				newSets.Content = append(newSets.Content, merged)
 			}
@@ -396,3 +474,60 @@ func (jp *JoinOp) evalRightJoinSets(set *xsql.JoinTuples, input xsql.Collection,
 	}
 	return newSets, nil
 }
+
+// evalSemiJoin outputs each left row once if it matches any right row for the semi join, or if it matches no right
+// row for the anti join. The right rows are not added to the output.
+func evalSemiJoin(lefts []*xsql.JoinTuple, rights []xsql.Row, join ast.Join, leftStreams []string, rightStream string, fv *xsql.FunctionValuer) ([]*xsql.JoinTuple, error) {
+	hi := newHashIndex(splitConjuncts(join.Expr), leftStreams, []string{rightStream}, rights, fv)
+	result := make([]*xsql.JoinTuple, 0)
+	for _, left := range lefts {
+		candidates := rights
+		if hi != nil {
+			candidates = hi.probe(left, fv)
+		}
+		matched := false
+		for _, right := range candidates {
+			temp := &xsql.JoinTuple{}
+			temp.AddTuples(left.Tuples)
+			temp.AddTuple(right)
+			ve := &xsql.ValuerEval{Valuer: xsql.MultiValuer(temp, fv)}
+			switch val := evalOn(join, ve, left, right).(type) {
+			case error:
+				return nil, val
+			case bool:
+				matched = val
+			default:
+				return nil, fmt.Errorf("invalid join condition that returns non-bool value %[1]T(%[1]v)", val)
+			}
+			if matched {
+				break
+			}
+		}
+		if matched == (join.JoinType == ast.SEMI_JOIN) {
+			merged := &xsql.JoinTuple{}
+			merged.AddTuples(left.Tuples)
+			merged.AliasMap = left.AliasMap
+			result = append(result, merged)
+		}
+	}
+	return result, nil
+}
+
+// leftStreams returns the streams in the joined rows before the ith join. The streams of the semi and anti joins
+// are not in the joined rows.
+func (jp *JoinOp) leftStreams(i int) []string {
+	streams := []string{tableName(jp.From.Name, jp.From.Alias)}
+	for _, j := range jp.Joins[:i] {
+		if j.JoinType != ast.SEMI_JOIN && j.JoinType != ast.ANTI_JOIN {
+			streams = append(streams, tableName(j.Name, j.Alias))
+		}
+	}
+	return streams
+}
+
+func tableName(name, alias string) string {
+	if alias != "" {
+		return alias
+	}
+	return name
+}
@@ -0,0 +1,226 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+// joinGraph is the streams and the conditions of the inner joins. As the inner joins are commutative and
+// associative, the streams can be joined in any order as long as each condition is evaluated once all the streams
+// it refers are joined.
+type joinGraph struct {
+	streams    []string
+	conditions []joinCondition
+}
+
+type joinCondition struct {
+	expr ast.Expr
+	refs []string
+}
+
+// newJoinGraph returns nil if the joins cannot be reordered. Only multiple inner joins whose conditions refer to
+// the joined streams can be reordered.
+func newJoinGraph(from *ast.Table, joins ast.Joins) *joinGraph {
+	if len(joins) < 2 {
+		return nil
+	}
+	g := &joinGraph{streams: []string{tableName(from.Name, from.Alias)}}
+	for _, j := range joins {
+		if j.JoinType != ast.INNER_JOIN {
+			return nil
+		}
+		g.streams = append(g.streams, tableName(j.Name, j.Alias))
+	}
+	for _, j := range joins {
+		for _, c := range splitConjuncts(j.Expr) {
+			if !refersOnly(c, g.streams) {
+				return nil
+			}
+			g.conditions = append(g.conditions, joinCondition{expr: c, refs: refStreams(c)})
+		}
+	}
+	return g
+}
+
+// eval joins the streams one by one. It starts from the stream with the fewest rows in the window, and then joins
+// the smallest stream which has an equi-join condition with the joined streams. The result is the same as joining
+// the streams in the order of the statement.
+func (g *joinGraph) eval(ctx api.StreamContext, input xsql.Collection, fv *xsql.FunctionValuer) (*xsql.JoinTuples, []string, error) {
+	rows := make([][]xsql.Row, len(g.streams))
+	for i, s := range g.streams {
+		rows[i] = input.GetBySrc(s)
+	}
+	joined := make([]bool, len(g.streams))
+	applied := make([]bool, len(g.conditions))
+	var (
+		joinedStreams []string
+		current       []*xsql.JoinTuple
+	)
+	for len(joinedStreams) < len(g.streams) {
+		select {
+		case <-ctx.Done():
+			return nil, nil, nil
+		default:
+		}
+		next := g.pickNext(rows, joined, applied, joinedStreams)
+		joined[next] = true
+		joinedStreams = append(joinedStreams, g.streams[next])
+		var conditions []ast.Expr
+		for i, c := range g.conditions {
+			if !applied[i] && refersOnly(c.expr, joinedStreams) {
+				applied[i] = true
+				conditions = append(conditions, c.expr)
+			}
+		}
+		var err error
+		if current == nil {
+			current = make([]*xsql.JoinTuple, 0, len(rows[next]))
+			for _, r := range rows[next] {
+				current = append(current, &xsql.JoinTuple{Tuples: []xsql.Row{r}})
+			}
+			current, err = filterJoined(current, conditions, fv)
+		} else {
+			current, err = joinNext(current, rows[next], conditions, joinedStreams[:len(joinedStreams)-1], g.streams[next], fv)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(current) == 0 {
+			break
+		}
+	}
+	// Sort the result in the same order as joining the streams in the statement order
+	rank := make(map[string]int, len(g.streams))
+	pos := make(map[xsql.Row]int)
+	for i, s := range g.streams {
+		rank[s] = i
+		for j, r := range rows[i] {
+			pos[r] = j
+		}
+	}
+	emitterRank := func(r xsql.Row) int {
+		if e, ok := r.(xsql.EmittedData); ok {
+			return rank[e.GetEmitter()]
+		}
+		return 0
+	}
+	for _, jt := range current {
+		sort.SliceStable(jt.Tuples, func(i, j int) bool {
+			return emitterRank(jt.Tuples[i]) < emitterRank(jt.Tuples[j])
+		})
+	}
+	sort.SliceStable(current, func(i, j int) bool {
+		ti, tj := current[i].Tuples, current[j].Tuples
+		for k := 0; k < len(ti) && k < len(tj); k++ {
+			if pi, pj := pos[ti[k]], pos[tj[k]]; pi != pj {
+				return pi < pj
+			}
+		}
+		return false
+	})
+	return &xsql.JoinTuples{Content: current}, joinedStreams, nil
+}
+
+// pickNext returns the stream to join next
+func (g *joinGraph) pickNext(rows [][]xsql.Row, joined, applied []bool, joinedStreams []string) int {
+	next, connected := -1, false
+	for i, s := range g.streams {
+		if joined[i] {
+			continue
+		}
+		c := false
+		if len(joinedStreams) > 0 {
+			var conditions []ast.Expr
+			for k, jc := range g.conditions {
+				if !applied[k] {
+					conditions = append(conditions, jc.expr)
+				}
+			}
+			probe, _ := extractEquiKeys(conditions, joinedStreams, []string{s})
+			c = len(probe) > 0
+		}
+		if next < 0 || (c && !connected) || (c == connected && len(rows[i]) < len(rows[next])) {
+			next, connected = i, c
+		}
+	}
+	return next
+}
+
+func joinNext(lefts []*xsql.JoinTuple, rights []xsql.Row, conditions []ast.Expr, leftStreams []string, rightStream string, fv *xsql.FunctionValuer) ([]*xsql.JoinTuple, error) {
+	hi := newHashIndex(conditions, leftStreams, []string{rightStream}, rights, fv)
+	result := make([]*xsql.JoinTuple, 0)
+	for _, left := range lefts {
+		candidates := rights
+		if hi != nil {
+			candidates = hi.probe(left, fv)
+		}
+		for _, right := range candidates {
+			merged := &xsql.JoinTuple{Tuples: make([]xsql.Row, 0, len(left.Tuples)+1)}
+			merged.AddTuples(left.Tuples)
+			merged.AddTuple(right)
+			ok, err := evalConditions(merged, conditions, fv)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				result = append(result, merged)
+			}
+		}
+	}
+	return result, nil
+}
+
+func filterJoined(tuples []*xsql.JoinTuple, conditions []ast.Expr, fv *xsql.FunctionValuer) ([]*xsql.JoinTuple, error) {
+	if len(conditions) == 0 {
+		return tuples, nil
+	}
+	result := make([]*xsql.JoinTuple, 0, len(tuples))
+	for _, t := range tuples {
+		ok, err := evalConditions(t, conditions, fv)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			result = append(result, t)
+		}
+	}
+	return result, nil
+}
+
+func evalConditions(t *xsql.JoinTuple, conditions []ast.Expr, fv *xsql.FunctionValuer) (bool, error) {
+	ve := &xsql.ValuerEval{Valuer: xsql.MultiValuer(t, fv)}
+	for _, c := range conditions {
+		switch val := ve.Eval(c).(type) {
+		case error:
+			return false, val
+		case bool:
+			if !val {
+				return false, nil
+			}
+		case nil:
+			return false, nil
+		default:
+			return false, fmt.Errorf("invalid join condition that returns non-bool value %[1]T(%[1]v)", val)
+		}
+	}
+	return true, nil
+}
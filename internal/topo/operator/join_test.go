@@ -2038,3 +2038,65 @@ func TestCrossJoinPlanError(t *testing.T) {
 		}
 	}
 }
+
+func TestSemiJoinPlan_Apply(t *testing.T) {
+	r := func(emitter, key string, id int) *xsql.Tuple {
+		return &xsql.Tuple{Emitter: emitter, Message: xsql.Message{key: id}}
+	}
+	data := &xsql.WindowTuples{
+		Content: []xsql.Row{
+			r("src1", "id1", 1), r("src1", "id1", 2), r("src1", "id1", 3),
+			r("src2", "id2", 1), r("src2", "id2", 1), r("src2", "id2", 3),
+			r("src3", "id3", 3),
+		},
+	}
+	tests := []struct {
+		sql    string
+		result [][]xsql.Row
+	}{
+		{
+			sql:    "SELECT * FROM src1 SEMI JOIN src2 ON src1.id1 = src2.id2",
+			result: [][]xsql.Row{{r("src1", "id1", 1)}, {r("src1", "id1", 3)}},
+		},
+		{
+			sql:    "SELECT * FROM src1 LEFT ANTI JOIN src2 ON src2.id2 = src1.id1",
+			result: [][]xsql.Row{{r("src1", "id1", 2)}},
+		},
+		{
+			sql:    "SELECT * FROM src1 LEFT SEMI JOIN src2 ON src1.id1 > src2.id2",
+			result: [][]xsql.Row{{r("src1", "id1", 2)}, {r("src1", "id1", 3)}},
+		},
+		{
+			sql:    "SELECT * FROM src1 ANTI JOIN src4",
+			result: [][]xsql.Row{{r("src1", "id1", 1)}, {r("src1", "id1", 2)}, {r("src1", "id1", 3)}},
+		},
+		{
+			sql: "SELECT * FROM src1 INNER JOIN src2 ON src1.id1 = src2.id2 ANTI JOIN src3 ON src3.id3 = src2.id2",
+			result: [][]xsql.Row{
+				{r("src1", "id1", 1), r("src2", "id2", 1)},
+				{r("src1", "id1", 1), r("src2", "id2", 1)},
+			},
+		},
+		{
+			sql:    "SELECT * FROM src1 SEMI JOIN src3 ON src1.id1 = src3.id3 INNER JOIN src2 ON src1.id1 = src2.id2",
+			result: [][]xsql.Row{{r("src1", "id1", 3), r("src2", "id2", 3)}},
+		},
+	}
+	contextLogger := conf.Log.WithField("rule", "TestSemiJoinPlan_Apply")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
+			assert.NoError(t, err)
+			fv, afv := xsql.NewFunctionValuersForOp(nil)
+			pp := &JoinOp{Joins: stmt.Joins, From: stmt.Sources[0].(*ast.Table)}
+			result, ok := pp.Apply(ctx, data, fv, afv).(*xsql.JoinTuples)
+			assert.True(t, ok)
+			var tuples [][]xsql.Row
+			for _, jt := range result.Content {
+				tuples = append(tuples, jt.Tuples)
+			}
+			assert.Equal(t, tt.result, tuples)
+		})
+	}
+}
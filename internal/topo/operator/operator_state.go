@@ -0,0 +1,87 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"bytes"
+	"encoding/gob"
+	"sync"
+	"sync/atomic"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+)
+
+// lockedState is the state data of an operator saved in the checkpoint. The data is guarded by the lock
+// because the checkpoint encodes it in another goroutine. The exported fields of the data are saved.
+type lockedState[T any] struct {
+	mu   sync.Mutex
+	data T
+}
+
+// stateRestorer is implemented by the state data to rebuild the fields which are not saved after decoding
+type stateRestorer interface {
+	restore()
+}
+
+func (s *lockedState[T]) GobEncode() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(&s.data)
+	return buf.Bytes(), err
+}
+
+func (s *lockedState[T]) GobDecode(data []byte) error {
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&s.data); err != nil {
+		return err
+	}
+	if r, ok := any(&s.data).(stateRestorer); ok {
+		r.restore()
+	}
+	return nil
+}
+
+// operatorState creates the state of an operator on the first use. The state is restored from the checkpoint
+// if any and put into the context once, so that the checkpoint saves the state which the operator updates.
+type operatorState[T any] struct {
+	p atomic.Pointer[lockedState[T]]
+}
+
+func (o *operatorState[T]) get(ctx api.StreamContext, key string, newData func() T) *lockedState[T] {
+	if s := o.p.Load(); s != nil {
+		return s
+	}
+	s := &lockedState[T]{data: newData()}
+	if st, err := ctx.GetState(key); err == nil {
+		if rs, ok := st.(*lockedState[T]); ok {
+			s = rs
+			ctx.GetLogger().Infof("restore %s state", key)
+		}
+	}
+	_ = ctx.PutState(key, s)
+	o.p.Store(s)
+	return s
+}
+
+// detail returns the runtime detail calculated by the state data, or nil if the state is not created yet
+func (o *operatorState[T]) detail(f func(d *T) map[string]any) map[string]any {
+	s := o.p.Load()
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return f(&s.data)
+}
@@ -47,6 +47,10 @@ type ProjectOp struct {
 
 	SendMeta bool
 	SendNil  bool
+	// RowkindField is the field to send the row kind of the changelog rows to the sink. An update is sent as an update
+	// of the new row, or as a delete of the old row and an insert of the new row if SendUpdateBefore is set.
+	RowkindField     string
+	SendUpdateBefore bool
 
 	kvs   []interface{}
 	alias []interface{}
@@ -73,6 +77,15 @@ func (pp *ProjectOp) Apply(ctx api.StreamContext, data interface{}, fv *xsql.Fun
 	case error:
 		return input
 	case xsql.Row:
+		var rowkind string
+		if pp.RowkindField != "" {
+			if cr, ok := input.(xsql.ChangelogRow); ok && cr.GetRowkind() != "" {
+				rowkind = sinkRowkind(cr.GetRowkind(), pp.SendUpdateBefore)
+				if rowkind == "" {
+					return nil
+				}
+			}
+		}
 		ve := pp.getRowVE(input, nil, fv, afv)
 		if err := pp.project(input, ve); err != nil {
 			return fmt.Errorf("run Select error: %s", err)
@@ -85,6 +98,9 @@ func (pp *ProjectOp) Apply(ctx api.StreamContext, data interface{}, fv *xsql.Fun
 					}
 				}
 			}
+			if rowkind != "" {
+				input.Set(pp.RowkindField, rowkind)
+			}
 		}
 	case xsql.Collection:
 		var err error
@@ -125,6 +141,27 @@ func (pp *ProjectOp) Apply(ctx api.StreamContext, data interface{}, fv *xsql.Fun
 	return data
 }
 
+// sinkRowkind converts the row kind of the changelog row to the rowkind of the table sinks. The update before row
+// is not needed by the sinks which update by key, so it returns empty to drop it unless sendUpdateBefore is set.
+func sinkRowkind(rowkind string, sendUpdateBefore bool) string {
+	switch rowkind {
+	case xsql.RowkindInsert:
+		return ast.RowkindInsert
+	case xsql.RowkindUpdateAfter:
+		if sendUpdateBefore {
+			return ast.RowkindInsert
+		}
+		return ast.RowkindUpdate
+	case xsql.RowkindDelete:
+		return ast.RowkindDelete
+	case xsql.RowkindUpdateBefore:
+		if sendUpdateBefore {
+			return ast.RowkindDelete
+		}
+	}
+	return ""
+}
+
 func (pp *ProjectOp) getVE(tuple xsql.RawRow, agg xsql.AggregateData, wr *xsql.WindowRange, fv *xsql.FunctionValuer, afv *xsql.AggregateFunctionValuer) *xsql.ValuerEval {
 	afv.SetData(agg)
 	if pp.ve == nil {
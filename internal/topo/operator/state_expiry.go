@@ -0,0 +1,69 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"container/heap"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+// expiryItem is a state key which expires at the time in milliseconds
+type expiryItem struct {
+	key    string
+	expire int64
+}
+
+// expiryQueue is the min heap of the state keys by the expiry time. The state is cleaned up by popping the expired keys.
+// A key may be pushed several times, so the state must check if the popped item is stale.
+type expiryQueue []expiryItem
+
+func (q expiryQueue) Len() int           { return len(q) }
+func (q expiryQueue) Less(i, j int) bool { return q[i].expire < q[j].expire }
+func (q expiryQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+
+func (q *expiryQueue) Push(x any) {
+	*q = append(*q, x.(expiryItem))
+}
+
+func (q *expiryQueue) Pop() any {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	*q = old[:n-1]
+	return item
+}
+
+func (q *expiryQueue) add(key string, expire int64) {
+	heap.Push(q, expiryItem{key: key, expire: expire})
+}
+
+// expire pops the items which expire no later than now
+func (q *expiryQueue) expire(now int64, f func(item expiryItem)) {
+	for q.Len() > 0 && (*q)[0].expire <= now {
+		f(heap.Pop(q).(expiryItem))
+	}
+}
+
+// rowTime is the time of the row to calculate the state expiry. It is the event time for event time rules.
+func rowTime(row any) int64 {
+	if e, ok := row.(xsql.Event); ok {
+		if ts := e.GetTimestamp(); !ts.IsZero() {
+			return ts.UnixMilli()
+		}
+	}
+	return timex.GetNow().UnixMilli()
+}
@@ -0,0 +1,82 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+// SubqueryOp converts the results of a subquery to the tuples of the derived table named Emitter
+type SubqueryOp struct {
+	Emitter string
+	// KeepWindow sends the results of a window as a collection instead of single tuples
+	KeepWindow bool
+}
+
+func (p *SubqueryOp) Apply(ctx api.StreamContext, data interface{}, _ *xsql.FunctionValuer, _ *xsql.AggregateFunctionValuer) interface{} {
+	ctx.GetLogger().Debugf("subquery plan receive %v", data)
+	switch input := data.(type) {
+	case error:
+		return input
+	case xsql.Row:
+		t := &xsql.Tuple{
+			Ctx:     input.GetTracerCtx(),
+			Emitter: p.Emitter,
+			Message: input.ToMap(),
+		}
+		if e, ok := input.(xsql.Event); ok {
+			t.Timestamp = e.GetTimestamp()
+		} else {
+			t.Timestamp = timex.GetNow()
+		}
+		if md, ok := input.(xsql.MetaData); ok {
+			t.Metadata = md.MetaData()
+		}
+		return t
+	case xsql.Collection:
+		// The results of a window happen at the end of the window
+		ts := timex.GetNow()
+		wr := input.GetWindowRange()
+		if wr != nil {
+			if end, ok := wr.FuncValue("window_end"); ok {
+				if v, ok := end.(int64); ok && v > 0 {
+					ts = time.UnixMilli(v)
+				}
+			}
+		}
+		maps := input.ToMaps()
+		rows := make([]xsql.Row, 0, len(maps))
+		for _, m := range maps {
+			rows = append(rows, &xsql.Tuple{
+				Ctx:       input.GetTracerCtx(),
+				Emitter:   p.Emitter,
+				Message:   m,
+				Timestamp: ts,
+			})
+		}
+		if p.KeepWindow {
+			return &xsql.WindowTuples{Ctx: input.GetTracerCtx(), Content: rows, WindowRange: wr}
+		}
+		return rows
+	default:
+		return fmt.Errorf("run subquery error: invalid input %[1]T(%[1]v)", input)
+	}
+}
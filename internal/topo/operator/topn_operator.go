@@ -0,0 +1,272 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"encoding/gob"
+	"fmt"
+	"sort"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+const topNStateKey = "topN"
+
+func init() {
+	gob.Register(&lockedState[topNState]{})
+}
+
+// TopNOp keeps the first N rows of each partition in the order of the sort fields.
+// For a window, the rows are ranked inside the window. For a stream, each row is ranked with the retained
+// top rows of its partition and only the rows which enter the top N are sent. The retained rows expire after TTL.
+type TopNOp struct {
+	Partition  *ast.PartitionExpr
+	SortFields ast.SortFields
+	N          int
+	// RankName is the name of the row number set to the sent rows. It is empty if the row number is not selected.
+	RankName string
+	// TTL is the milliseconds to retain the top rows of a stream, 0 means they never expire.
+	// The planner always sets it so that the partitions do not grow without bound.
+	TTL int64
+
+	state operatorState[topNState]
+}
+
+type topNEntry struct {
+	Values []any
+	Expire int64
+}
+
+// topNState is the retained top rows of each partition in rank order
+type topNState struct {
+	Partitions map[string][]topNEntry
+	expiry     expiryQueue
+}
+
+func newTopNState() topNState {
+	return topNState{Partitions: make(map[string][]topNEntry)}
+}
+
+func (s *topNState) restore() {
+	if s.Partitions == nil {
+		s.Partitions = make(map[string][]topNEntry)
+	}
+	for key, entries := range s.Partitions {
+		for _, e := range entries {
+			if e.Expire > 0 {
+				s.expiry.add(key, e.Expire)
+			}
+		}
+	}
+}
+
+// cleanup drops the expired rows and the partitions without rows
+func (s *topNState) cleanup(now int64) {
+	s.expiry.expire(now, func(item expiryItem) {
+		entries, ok := s.Partitions[item.key]
+		if !ok {
+			return
+		}
+		kept := entries[:0]
+		for _, e := range entries {
+			if e.Expire == 0 || e.Expire > now {
+				kept = append(kept, e)
+			}
+		}
+		if len(kept) == 0 {
+			delete(s.Partitions, item.key)
+		} else {
+			s.Partitions[item.key] = kept
+		}
+	})
+}
+
+func (p *TopNOp) Apply(ctx api.StreamContext, data interface{}, fv *xsql.FunctionValuer, afv *xsql.AggregateFunctionValuer) interface{} {
+	ctx.GetLogger().Debugf("topN plan receive %v", data)
+	switch input := data.(type) {
+	case error:
+		return input
+	case xsql.Row:
+		key, values, err := p.eval(input, nil, fv, afv)
+		if err != nil {
+			return err
+		}
+		rank := p.rankRow(ctx, key, values, rowTime(input))
+		if rank == 0 {
+			return nil
+		}
+		if p.RankName != "" {
+			input.Set(p.RankName, rank)
+		}
+		return input
+	case xsql.Collection:
+		return p.rankCollection(input, fv, afv)
+	default:
+		return fmt.Errorf("run Top-N error: invalid input %[1]T(%[1]v)", input)
+	}
+}
+
+// rankRow returns the rank of the row in its partition, or 0 if it is not in the top N
+func (p *TopNOp) rankRow(ctx api.StreamContext, key string, values []any, now int64) int {
+	ls := p.state.get(ctx, topNStateKey, newTopNState)
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	s := &ls.data
+	s.cleanup(now)
+	entries := s.Partitions[key]
+	// The row is ranked after the rows with the same values
+	i := sort.Search(len(entries), func(i int) bool {
+		return p.less(values, entries[i].Values)
+	})
+	if i >= p.N {
+		return 0
+	}
+	e := topNEntry{Values: values}
+	if p.TTL > 0 {
+		e.Expire = now + p.TTL
+		s.expiry.add(key, e.Expire)
+	}
+	entries = append(entries, topNEntry{})
+	copy(entries[i+1:], entries[i:])
+	entries[i] = e
+	if len(entries) > p.N {
+		entries = entries[:p.N]
+	}
+	s.Partitions[key] = entries
+	return i + 1
+}
+
+func (p *TopNOp) rankCollection(input xsql.Collection, fv *xsql.FunctionValuer, afv *xsql.AggregateFunctionValuer) interface{} {
+	type rankedRow struct {
+		index     int
+		partition int
+		values    []any
+	}
+	var rows []rankedRow
+	partitions := make(map[string]int)
+	wr := input.GetWindowRange()
+	err := input.Range(func(i int, r xsql.ReadonlyRow) (bool, error) {
+		key, values, err := p.eval(r.(xsql.Row), wr, fv, afv)
+		if err != nil {
+			return false, err
+		}
+		pi, ok := partitions[key]
+		if !ok {
+			pi = len(partitions)
+			partitions[key] = pi
+		}
+		rows = append(rows, rankedRow{index: i, partition: pi, values: values})
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+	// The partitions are sent in the order of their first rows
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].partition != rows[j].partition {
+			return rows[i].partition < rows[j].partition
+		}
+		return p.less(rows[i].values, rows[j].values)
+	})
+	indexes := make([]int, 0, len(rows))
+	ranks := make([]int, 0, len(rows))
+	rank := 0
+	for i, r := range rows {
+		if i == 0 || r.partition != rows[i-1].partition {
+			rank = 0
+		}
+		rank++
+		if rank <= p.N {
+			indexes = append(indexes, r.index)
+			ranks = append(ranks, rank)
+		}
+	}
+	if len(indexes) == 0 {
+		return nil
+	}
+	result := input.Filter(indexes)
+	if p.RankName != "" {
+		_ = result.RangeSet(func(i int, r xsql.Row) (bool, error) {
+			r.Set(p.RankName, ranks[i])
+			return true, nil
+		})
+	}
+	return result
+}
+
+// eval returns the partition key and the sort values of the row
+func (p *TopNOp) eval(row xsql.Row, wr *xsql.WindowRange, fv *xsql.FunctionValuer, afv *xsql.AggregateFunctionValuer) (string, []any, error) {
+	var ve *xsql.ValuerEval
+	if aggRow, ok := row.(xsql.AggregateData); ok {
+		afv.SetData(aggRow)
+		ve = &xsql.ValuerEval{Valuer: xsql.MultiAggregateValuer(aggRow, fv, row, afv, &xsql.WildcardValuer{Data: row})}
+	} else {
+		ve = &xsql.ValuerEval{Valuer: xsql.MultiValuer(row, &xsql.WindowRangeValuer{WindowRange: wr}, fv)}
+	}
+	var key string
+	if p.Partition != nil {
+		for _, expr := range p.Partition.Exprs {
+			r := ve.Eval(expr)
+			if err, ok := r.(error); ok {
+				return "", nil, fmt.Errorf("run Top-N partition error: %v", err)
+			}
+			key += fmt.Sprintf("%v,", r)
+		}
+	}
+	values := make([]any, len(p.SortFields))
+	for i, field := range p.SortFields {
+		r := ve.Eval(field.FieldExpr)
+		if err, ok := r.(error); ok {
+			return "", nil, fmt.Errorf("run Top-N order error: %v", err)
+		}
+		values[i] = r
+	}
+	return key, values, nil
+}
+
+// less compares the sort values in the same way as ORDER BY. The nil values are the last.
+func (p *TopNOp) less(a, b []any) bool {
+	v := &xsql.ValuerEval{}
+	for i, field := range p.SortFields {
+		va, vb := a[i], b[i]
+		if va == nil || vb == nil {
+			if va == nil && vb == nil {
+				continue
+			}
+			return vb == nil
+		}
+		switch {
+		case v.SimpleDataEval(va, vb, ast.LT) == true:
+			return field.Ascending
+		case v.SimpleDataEval(vb, va, ast.LT) == true:
+			return !field.Ascending
+		}
+	}
+	return false
+}
+
+// GetRuntimeDetail returns the size of the retained top rows
+func (p *TopNOp) GetRuntimeDetail() map[string]any {
+	return p.state.detail(func(s *topNState) map[string]any {
+		rows := 0
+		for _, entries := range s.Partitions {
+			rows += len(entries)
+		}
+		return map[string]any{"partitions": len(s.Partitions), "retainedRows": rows}
+	})
+}
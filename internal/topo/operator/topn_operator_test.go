@@ -0,0 +1,121 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func topNRow(line string, temp any, ts int64) *xsql.Tuple {
+	return &xsql.Tuple{Emitter: "demo", Message: map[string]any{"line": line, "temp": temp}, Timestamp: time.UnixMilli(ts)}
+}
+
+func newTopNOp(n int, ttl int64) *TopNOp {
+	return &TopNOp{
+		Partition:  &ast.PartitionExpr{Exprs: []ast.Expr{&ast.FieldRef{Name: "line", StreamName: "demo"}}},
+		SortFields: ast.SortFields{{Name: "temp", Uname: "temp", FieldExpr: &ast.FieldRef{Name: "temp", StreamName: "demo"}}},
+		N:          n,
+		RankName:   "rn",
+		TTL:        ttl,
+	}
+}
+
+func TestTopNWindow(t *testing.T) {
+	ctx := mockContext.NewMockContext("TestTopNWindow", "op1")
+	fv, afv := xsql.NewFunctionValuersForOp(nil)
+	op := newTopNOp(2, 0)
+	op.SortFields[0].Ascending = false
+	input := &xsql.WindowTuples{Content: []xsql.Row{
+		topNRow("b", 30, 1),
+		topNRow("a", 10, 2),
+		topNRow("a", 50, 3),
+		topNRow("b", nil, 4),
+		topNRow("a", 30, 5),
+		topNRow("a", 50, 6),
+		topNRow("b", 20, 7),
+	}}
+	result := op.Apply(ctx, input, fv, afv)
+	c, ok := result.(xsql.Collection)
+	require.True(t, ok, result)
+	require.Equal(t, []map[string]any{
+		{"line": "b", "temp": 30, "rn": 1},
+		{"line": "b", "temp": 20, "rn": 2},
+		{"line": "a", "temp": 50, "rn": 1},
+		{"line": "a", "temp": 50, "rn": 2},
+	}, c.ToMaps())
+	// Window rows have no state
+	require.Nil(t, op.GetRuntimeDetail())
+
+	result = op.Apply(ctx, &xsql.WindowTuples{Content: []xsql.Row{}}, fv, afv)
+	require.Nil(t, result)
+}
+
+func TestTopNStream(t *testing.T) {
+	ctx := mockContext.NewMockContext("TestTopNStream", "op1")
+	fv, afv := xsql.NewFunctionValuersForOp(nil)
+	op := newTopNOp(2, 100)
+	op.SortFields[0].Ascending = false
+	tests := []struct {
+		row  *xsql.Tuple
+		rank any
+	}{
+		{row: topNRow("a", 10, 0), rank: 1},
+		{row: topNRow("a", 20, 10), rank: 1},
+		{row: topNRow("a", 5, 20)},
+		{row: topNRow("a", 15, 30), rank: 2},
+		{row: topNRow("b", 1, 40), rank: 1},
+		{row: topNRow("a", 15, 50)},
+		// The rows of a at 10 and 30 expire
+		{row: topNRow("a", 5, 135), rank: 1},
+		{row: topNRow("a", 6, 140), rank: 1},
+	}
+	for i, tt := range tests {
+		result := op.Apply(ctx, tt.row, fv, afv)
+		if tt.rank == nil {
+			require.Nil(t, result, i)
+			continue
+		}
+		r, ok := result.(xsql.Row)
+		require.True(t, ok, "%d: %v", i, result)
+		rank, _ := r.Value("rn", "")
+		require.Equal(t, tt.rank, rank, i)
+	}
+	require.Equal(t, map[string]any{"partitions": 1, "retainedRows": 2}, op.GetRuntimeDetail())
+
+	// Restore the state from the checkpoint
+	st, err := ctx.GetState(topNStateKey)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(&st))
+	var restored any
+	require.NoError(t, gob.NewDecoder(&buf).Decode(&restored))
+	ctx = mockContext.NewMockContext("TestTopNStream", "op1")
+	require.NoError(t, ctx.PutState(topNStateKey, restored))
+	op = newTopNOp(2, 100)
+	op.SortFields[0].Ascending = false
+	require.Nil(t, op.Apply(ctx, topNRow("a", 4, 150), fv, afv))
+	result := op.Apply(ctx, topNRow("a", 5, 260), fv, afv)
+	rank, _ := result.(xsql.Row).Value("rn", "")
+	require.Equal(t, 1, rank)
+}
@@ -0,0 +1,38 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+)
+
+// UnionOp merges the results of the select statements combined by UNION ALL. The results are sent as they arrive.
+type UnionOp struct{}
+
+func (p *UnionOp) Apply(ctx api.StreamContext, data interface{}, _ *xsql.FunctionValuer, _ *xsql.AggregateFunctionValuer) interface{} {
+	ctx.GetLogger().Debugf("union plan receive %v", data)
+	switch input := data.(type) {
+	case error:
+		return input
+	case xsql.Row, xsql.Collection:
+		return input
+	default:
+		return fmt.Errorf("run union error: invalid input %[1]T(%[1]v)", input)
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import "github.com/lf-edge/ekuiper/v2/internal/topo/node"
+
+// PlanNode is a node of the logical plan which the topo is built from, with the nodes built for it.
+// It is used to analyze the running rule along with the plan.
+type PlanNode struct {
+	Op       string
+	Info     string
+	Nodes    []node.TopNode
+	Children []*PlanNode
+}
+
+func (s *Topo) SetPlan(plan *PlanNode) {
+	s.plan = plan
+}
+
+// GetPlan returns the plan of the topo. It is nil if the topo is not planned from SQL.
+func (s *Topo) GetPlan() *PlanNode {
+	if s == nil {
+		return nil
+	}
+	return s.plan
+}
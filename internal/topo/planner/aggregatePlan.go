@@ -19,6 +19,7 @@ import "github.com/lf-edge/ekuiper/v2/pkg/ast"
 type AggregatePlan struct {
 	baseLogicalPlan
 	dimensions ast.Dimensions
+	keepEmpty  bool
 }
 
 func (p AggregatePlan) Init() *AggregatePlan {
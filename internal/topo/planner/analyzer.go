@@ -16,6 +16,7 @@ package planner
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 
@@ -30,18 +31,35 @@ import (
 type streamInfo struct {
 	stmt   *ast.StreamStmt
 	schema ast.StreamFields
+	// subquery is the select statement of a derived table which has no schema
+	subquery *ast.SelectStatement
+	// view is the name of the view which the derived table is expanded from
+	view string
 }
 
 // Analyze the select statement by decorating the info from stream statement.
 // Typically, set the correct stream name for fieldRefs
-func decorateStmt(s *ast.SelectStatement, opt *def.RuleOption, isTemp bool) ([]*streamInfo, []*ast.Call, []*ast.Call, error) {
-	streamsFromStmt := xsql.GetStreams(s)
+func decorateStmt(s *ast.SelectStatement, opt *def.RuleOption, overrides streamOverrides, isTemp bool) ([]*streamInfo, []*ast.Call, []*ast.Call, error) {
+	streamsFromStmt := xsql.GetSources(s)
 	streamStmts := make([]*streamInfo, len(streamsFromStmt))
 	isSchemaless := false
-	for i, s := range streamsFromStmt {
-		streamStmt, err := processor.GetStreamProcessorDataSource(s)
+	subqueries, err := getSubqueries(s)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for i, name := range streamsFromStmt {
+		if sub, ok := subqueries[name]; ok {
+			streamStmts[i] = &streamInfo{
+				stmt:     &ast.StreamStmt{Name: ast.StreamName(name), StreamType: ast.TypeStream, Options: &ast.Options{}},
+				subquery: sub,
+				view:     getSubqueryView(s, name),
+			}
+			isSchemaless = true
+			continue
+		}
+		streamStmt, err := getStreamStmt(name, opt, overrides)
 		if err != nil {
-			return nil, nil, nil, fmt.Errorf("fail to get stream %s, please check if stream is created", s)
+			return nil, nil, nil, err
 		}
 		si, err := convertStreamInfo(streamStmt)
 		if err != nil {
@@ -201,7 +219,7 @@ func decorateStmt(s *ast.SelectStatement, opt *def.RuleOption, isTemp bool) ([]*
 	if walkErr != nil {
 		return nil, nil, nil, walkErr
 	}
-	walkErr = validate(s)
+	walkErr = validate(s, hasChangelogStream(streamStmts))
 	// Collect all analytic function calls so that we can let them run firstly
 	ast.WalkFunc(s, func(n ast.Node) bool {
 		switch f := n.(type) {
@@ -246,6 +264,232 @@ func decorateStmt(s *ast.SelectStatement, opt *def.RuleOption, isTemp bool) ([]*
 	return streamStmts, analyticFuncs, analyticFieldFuncs, walkErr
 }
 
+// getSubqueries returns the derived tables of the statement by name. As the sources of a rule are created by the
+// stream names, a stream can only be read once by the statement and its subqueries.
+func getSubqueries(s *ast.SelectStatement) (map[string]*ast.SelectStatement, error) {
+	subqueries := make(map[string]*ast.SelectStatement)
+	for _, src := range s.Sources {
+		if t, ok := src.(*ast.Table); ok && t.Subquery != nil {
+			subqueries[t.Name] = t.Subquery
+		}
+	}
+	for _, j := range s.Joins {
+		if j.Subquery != nil {
+			if _, ok := subqueries[j.Name]; ok {
+				return nil, fmt.Errorf("derived table %s is defined more than once", j.Name)
+			}
+			subqueries[j.Name] = j.Subquery
+		}
+	}
+	if len(subqueries) > 0 {
+		streams := xsql.GetStreams(s)
+		for i, name := range streams {
+			for _, other := range streams[i+1:] {
+				if name == other {
+					return nil, fmt.Errorf("stream %s is referred more than once by the subqueries", name)
+				}
+			}
+		}
+	}
+	return subqueries, nil
+}
+
+// getSubqueryView returns the name of the view which the derived table of the FROM clause is expanded from
+func getSubqueryView(s *ast.SelectStatement, name string) string {
+	for _, src := range s.Sources {
+		if t, ok := src.(*ast.Table); ok && t.Name == name {
+			return t.View
+		}
+	}
+	return ""
+}
+
+// unionColumn is an output column of a select statement combined by UNION ALL
+type unionColumn struct {
+	name     string
+	dataType ast.DataType
+}
+
+// validateUnion checks the select statements combined by UNION ALL output compatible rows. As the rows are maps,
+// the columns are matched by name. The types are checked only if they can be inferred from the stream schemas
+// and the literals. Like the subqueries, a stream can only be read once by all the select statements.
+func validateUnion(s *ast.SelectStatement, opt *def.RuleOption, overrides streamOverrides) error {
+	streams := xsql.GetStreams(s)
+	for i, name := range streams {
+		for _, other := range streams[i+1:] {
+			if name == other {
+				return fmt.Errorf("stream %s is referred more than once by UNION ALL", name)
+			}
+		}
+	}
+	selects := append([]*ast.SelectStatement{s}, s.Unions...)
+	var (
+		expected []unionColumn
+		index    int
+	)
+	for i, sel := range selects {
+		cols, known, err := getUnionColumns(sel, opt, overrides)
+		if err != nil {
+			return err
+		}
+		// The columns of a statement selecting all fields of a schemaless source are unknown until runtime
+		if !known {
+			continue
+		}
+		if expected == nil {
+			expected, index = cols, i
+			continue
+		}
+		if len(cols) != len(expected) {
+			return fmt.Errorf("select statement %d of UNION ALL has %d columns but select statement %d has %d", i+1, len(cols), index+1, len(expected))
+		}
+		for _, c := range cols {
+			found := false
+			for _, e := range expected {
+				if c.name == e.name {
+					found = true
+					if !isUnionTypeCompatible(c.dataType, e.dataType) {
+						return fmt.Errorf("column %s of select statement %d of UNION ALL is %s but it is %s in select statement %d", c.name, i+1, c.dataType, e.dataType, index+1)
+					}
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("column %s of select statement %d of UNION ALL is not found in select statement %d", c.name, i+1, index+1)
+			}
+		}
+	}
+	return nil
+}
+
+// getUnionColumns infers the output columns of the select statement before it is decorated. It returns false if
+// the columns are unknown.
+func getUnionColumns(s *ast.SelectStatement, opt *def.RuleOption, overrides streamOverrides) ([]unionColumn, bool, error) {
+	// The schemas by the stream names and aliases. A nil schema means schemaless
+	schemas := make(map[string]ast.StreamFields)
+	var names []string
+	addSchema := func(name, alias string, sub *ast.SelectStatement) error {
+		var ss ast.StreamFields
+		if sub == nil {
+			streamStmt, err := getStreamStmt(name, opt, overrides)
+			if err != nil {
+				return err
+			}
+			si, err := convertStreamInfo(streamStmt)
+			if err != nil {
+				return err
+			}
+			ss = si.schema
+		}
+		if alias != "" {
+			name = alias
+		}
+		schemas[name] = ss
+		names = append(names, name)
+		return nil
+	}
+	for _, src := range s.Sources {
+		if t, ok := src.(*ast.Table); ok {
+			if err := addSchema(t.Name, t.Alias, t.Subquery); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+	for _, j := range s.Joins {
+		if err := addSchema(j.Name, j.Alias, j.Subquery); err != nil {
+			return nil, false, err
+		}
+	}
+	var cols []unionColumn
+	for _, f := range s.Fields {
+		if f.Invisible {
+			continue
+		}
+		switch e := f.Expr.(type) {
+		case *ast.Wildcard:
+			for _, name := range names {
+				ss := schemas[name]
+				if ss == nil {
+					return nil, false, nil
+				}
+				for _, sf := range ss {
+					if !slices.Contains(e.Except, sf.Name) {
+						cols = append(cols, unionColumn{name: sf.Name, dataType: getUnionFieldType(sf.FieldType)})
+					}
+				}
+			}
+			for _, rf := range e.Replace {
+				for i := range cols {
+					if cols[i].name == rf.AName {
+						cols[i].dataType = ast.UNKNOWN
+					}
+				}
+			}
+			continue
+		case *ast.FieldRef:
+			if e.Name == "*" {
+				ss := schemas[string(e.StreamName)]
+				if ss == nil {
+					return nil, false, nil
+				}
+				for _, sf := range ss {
+					cols = append(cols, unionColumn{name: sf.Name, dataType: getUnionFieldType(sf.FieldType)})
+				}
+				continue
+			}
+		}
+		cols = append(cols, unionColumn{name: f.GetName(), dataType: getUnionExprType(f.Expr, schemas)})
+	}
+	return cols, true, nil
+}
+
+func getUnionExprType(expr ast.Expr, schemas map[string]ast.StreamFields) ast.DataType {
+	switch e := expr.(type) {
+	case *ast.IntegerLiteral:
+		return ast.BIGINT
+	case *ast.NumberLiteral:
+		return ast.FLOAT
+	case *ast.StringLiteral:
+		return ast.STRINGS
+	case *ast.BooleanLiteral:
+		return ast.BOOLEAN
+	case *ast.ParenExpr:
+		return getUnionExprType(e.Expr, schemas)
+	case *ast.FieldRef:
+		for name, ss := range schemas {
+			if e.StreamName != ast.DefaultStream && string(e.StreamName) != name {
+				continue
+			}
+			for _, sf := range ss {
+				if strings.EqualFold(sf.Name, e.Name) {
+					return getUnionFieldType(sf.FieldType)
+				}
+			}
+		}
+	}
+	return ast.UNKNOWN
+}
+
+func getUnionFieldType(ft ast.FieldType) ast.DataType {
+	switch t := ft.(type) {
+	case *ast.BasicType:
+		return t.Type
+	case *ast.ArrayType:
+		return ast.ARRAY
+	case *ast.RecType:
+		return ast.STRUCT
+	}
+	return ast.UNKNOWN
+}
+
+// isUnionTypeCompatible the numbers are compatible because the integers and floats are both valid in a float column
+func isUnionTypeCompatible(a, b ast.DataType) bool {
+	if a == ast.UNKNOWN || b == ast.UNKNOWN || a == b {
+		return true
+	}
+	return (a == ast.BIGINT || a == ast.FLOAT) && (b == ast.BIGINT || b == ast.FLOAT)
+}
+
 type aliasTopoDegree struct {
 	alias  string
 	degree int
@@ -424,8 +668,12 @@ type validateOptStmt interface {
 	validate(statement *ast.SelectStatement) error
 }
 
-func validate(stmt *ast.SelectStatement) error {
+func validate(stmt *ast.SelectStatement, changelog bool) error {
 	for _, checker := range stmtCheckers {
+		// group by without window emits the updates of the groups in changelog mode
+		if _, ok := checker.(*groupChecker); ok && changelog {
+			continue
+		}
 		if err := checker.validate(stmt); err != nil {
 			return err
 		}
@@ -436,6 +684,7 @@ func validate(stmt *ast.SelectStatement) error {
 var stmtCheckers = []validateOptStmt{
 	&aggFuncChecker{},
 	&groupChecker{},
+	&semiJoinChecker{},
 }
 
 type aggFuncChecker struct{}
@@ -492,6 +741,41 @@ func (c *groupChecker) validate(s *ast.SelectStatement) error {
 	return nil
 }
 
+// semiJoinChecker checks the streams of the semi and anti joins are only referred in their ON clauses,
+// because their rows are not in the join result
+type semiJoinChecker struct{}
+
+func (c *semiJoinChecker) validate(s *ast.SelectStatement) (err error) {
+	semiJoins := make(map[ast.StreamName]ast.JoinType)
+	for _, j := range s.Joins {
+		if j.JoinType == ast.SEMI_JOIN || j.JoinType == ast.ANTI_JOIN {
+			name := j.Name
+			if j.Alias != "" {
+				name = j.Alias
+			}
+			semiJoins[ast.StreamName(name)] = j.JoinType
+		}
+	}
+	if len(semiJoins) == 0 {
+		return nil
+	}
+	ast.WalkFunc(s, func(n ast.Node) bool {
+		switch f := n.(type) {
+		case *ast.Join:
+			return f.JoinType != ast.SEMI_JOIN && f.JoinType != ast.ANTI_JOIN
+		case *ast.FieldRef:
+			for _, sn := range f.RefSources() {
+				if jt, ok := semiJoins[sn]; ok {
+					err = fmt.Errorf("stream %s of %s can only be referred in its ON clause", sn, jt)
+					return false
+				}
+			}
+		}
+		return err == nil
+	})
+	return err
+}
+
 // file-private functions below
 // allAggregate checks if all expressions of binary expression are aggregate
 func allAggregate(expr ast.Expr) (r bool) {
@@ -517,6 +801,28 @@ func allAggregate(expr ast.Expr) (r bool) {
 	return
 }
 
+// streamOverrides are the stream definitions to use instead of the saved ones by the stream names. It is only set when
+// validating the rules against a stream change before saving it.
+type streamOverrides map[string]*ast.StreamStmt
+
+// getStreamStmt gets the definition of the stream used by the rule. The definition is overridden when validating a
+// stream change and the schema is the pinned version if set in the rule option.
+func getStreamStmt(name string, opt *def.RuleOption, overrides streamOverrides) (*ast.StreamStmt, error) {
+	if s, ok := overrides[name]; ok {
+		return s, nil
+	}
+	streamStmt, err := processor.GetStreamProcessorDataSource(name)
+	if err != nil {
+		return nil, fmt.Errorf("fail to get stream %s, please check if stream is created", name)
+	}
+	if opt != nil {
+		if v, ok := opt.SchemaVersions[name]; ok {
+			return processor.GetStreamProcessorSchemaVersion(name, v)
+		}
+	}
+	return streamStmt, nil
+}
+
 func convertStreamInfo(streamStmt *ast.StreamStmt) (*streamInfo, error) {
 	ss := streamStmt.StreamFields
 	var err error
@@ -507,6 +507,29 @@ func TestValidateStmt(t *testing.T) {
 	sql := "select a from src1 group by b"
 	stmt, err := xsql.NewParser(strings.NewReader(sql)).Parse()
 	require.NoError(t, err)
-	err = validate(stmt)
+	err = validate(stmt, false)
 	require.Error(t, err)
 }
+
+func TestSchemaVersionPin(t *testing.T) {
+	p := processor.NewStreamProcessor()
+	_, err := p.ExecStmt(`CREATE STREAM pinDemo (a bigint, b string) WITH (DATASOURCE="pinDemo")`)
+	require.NoError(t, err)
+	defer p.DropStream("pinDemo", ast.TypeStream)
+	_, err = p.ExecReplaceStream("pinDemo", `CREATE STREAM pinDemo (a bigint) WITH (DATASOURCE="pinDemo")`, ast.TypeStream)
+	require.NoError(t, err)
+
+	rule := &def.Rule{Id: "pinRule", Sql: "SELECT b FROM pinDemo", Options: &def.RuleOption{}}
+	require.EqualError(t, ValidateRule(rule, nil), "unknown field b")
+	rule.Options.SchemaVersions = map[string]int{"pinDemo": 1}
+	require.NoError(t, ValidateRule(rule, nil))
+	rule.Options.SchemaVersions = map[string]int{"pinDemo": 3}
+	require.EqualError(t, ValidateRule(rule, nil), "schema version 3 of stream pinDemo is not found")
+
+	// validate against a change before saving
+	rule.Options = &def.RuleOption{}
+	require.NoError(t, ValidateRule(rule, map[string]string{"pinDemo": `CREATE STREAM pinDemo (a bigint, b string) WITH (DATASOURCE="pinDemo")`}))
+	require.EqualError(t, ValidateRule(rule, map[string]string{"pinDemo": `SELECT * FROM pinDemo`}), "invalid stream statement: SELECT * FROM pinDemo")
+	// the overrides are not kept in the rule
+	require.EqualError(t, ValidateRule(rule, nil), "unknown field b")
+}
@@ -0,0 +1,95 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/v2/internal/topo/operator"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+// ChangelogAggregatePlan groups the changes without window in changelog mode
+type ChangelogAggregatePlan struct {
+	baseLogicalPlan
+	dimensions ast.Dimensions
+	aggFields  []*ast.Field
+}
+
+func (p ChangelogAggregatePlan) Init() *ChangelogAggregatePlan {
+	p.baseLogicalPlan.self = &p
+	p.baseLogicalPlan.setPlanType(CHANGELOGAGG)
+	return &p
+}
+
+func (p *ChangelogAggregatePlan) BuildExplainInfo() {
+	info := ""
+	if len(p.dimensions) != 0 {
+		info += "Dimension:{ "
+		for i, dimension := range p.dimensions {
+			if dimension.Expr != nil {
+				info += dimension.Expr.String()
+				if i != len(p.dimensions)-1 {
+					info += ", "
+				}
+			}
+		}
+		info += " }, "
+	}
+	info += "funcs:["
+	for i, aggFunc := range p.aggFields {
+		if i > 0 {
+			info += ","
+		}
+		info += aggFunc.Expr.String() + "->" + aggFunc.Name
+	}
+	info += "]"
+	p.baseLogicalPlan.ExplainInfo.Info = info
+}
+
+func (p *ChangelogAggregatePlan) PruneColumns(fields []ast.Expr) error {
+	f := getFields(p.dimensions)
+	for _, aggFunc := range p.aggFields {
+		f = append(f, getFields(aggFunc)...)
+	}
+	return p.baseLogicalPlan.PruneColumns(append(fields, f...))
+}
+
+// extractChangelogAgg replaces the aggregate calls of the select fields with the references of the results calculated
+// by the changelog aggregate plan. Only the aggregate functions which can be retracted are supported.
+func extractChangelogAgg(stmt *ast.SelectStatement) ([]*ast.Field, error) {
+	var (
+		aggFields []*ast.Field
+		err       error
+	)
+	ast.WalkFunc(stmt.Fields, func(n ast.Node) bool {
+		c, ok := n.(*ast.Call)
+		if !ok || c.FuncType != ast.FuncTypeAgg || err != nil {
+			return err == nil
+		}
+		if !operator.IsChangelogAggFunc(c.Name) || len(c.Args) != 1 {
+			err = fmt.Errorf("aggregate function %s is not supported in changelog mode", c.Name)
+			return false
+		}
+		name := fmt.Sprintf("changelog_agg_col_%d", len(aggFields)+1)
+		aggFields = append(aggFields, &ast.Field{
+			Name: name,
+			Expr: &ast.Call{Name: c.Name, FuncType: ast.FuncTypeAgg, Args: c.Args, FuncId: c.FuncId},
+		})
+		rewriteIntoBypass(&ast.FieldRef{StreamName: ast.DefaultStream, Name: name}, c)
+		return true
+	})
+	return aggFields, err
+}
@@ -0,0 +1,44 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import "github.com/lf-edge/ekuiper/v2/pkg/ast"
+
+// ChangelogJoinPlan joins the changes of two changelog streams without window
+type ChangelogJoinPlan struct {
+	baseLogicalPlan
+	from *ast.Table
+	join ast.Join
+}
+
+func (p ChangelogJoinPlan) Init() *ChangelogJoinPlan {
+	p.baseLogicalPlan.self = &p
+	p.baseLogicalPlan.setPlanType(CHANGELOGJOIN)
+	return &p
+}
+
+func (p *ChangelogJoinPlan) BuildExplainInfo() {
+	info := "Join:{ joinType:" + p.join.JoinType.String() + ", "
+	if p.join.Expr != nil {
+		info += p.join.Expr.String()
+	}
+	info += " }"
+	p.baseLogicalPlan.ExplainInfo.Info = info
+}
+
+func (p *ChangelogJoinPlan) PruneColumns(fields []ast.Expr) error {
+	f := getFields(&p.join)
+	return p.baseLogicalPlan.PruneColumns(append(fields, f...))
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+// DefaultRowkindField is the field to send the row kind to the sinks in changelog mode if the rule does not set it
+const DefaultRowkindField = "rowkind"
+
+// ChangelogPlan converts the rows of a changelog stream to the changes with row kind
+type ChangelogPlan struct {
+	baseLogicalPlan
+	name         ast.StreamName
+	key          string
+	rowkindField string
+}
+
+func (p ChangelogPlan) Init() *ChangelogPlan {
+	p.baseLogicalPlan.self = &p
+	p.baseLogicalPlan.setPlanType(CHANGELOG)
+	return &p
+}
+
+func (p *ChangelogPlan) BuildExplainInfo() {
+	p.baseLogicalPlan.ExplainInfo.Info = "Key:" + p.key + ", RowkindField:" + p.rowkindField
+}
+
+// PushDownPredicate the filtered rows must still update the state, so that the conditions cannot be evaluated before it
+func (p *ChangelogPlan) PushDownPredicate(condition ast.Expr) (ast.Expr, LogicalPlan) {
+	_, _ = p.baseLogicalPlan.PushDownPredicate(nil)
+	return condition, p.self
+}
+
+func (p *ChangelogPlan) PruneColumns(fields []ast.Expr) error {
+	fields = append(fields, &ast.FieldRef{StreamName: p.name, Name: p.key})
+	if p.rowkindField != "" {
+		fields = append(fields, &ast.FieldRef{StreamName: p.name, Name: p.rowkindField})
+	}
+	return p.baseLogicalPlan.PruneColumns(fields)
+}
+
+// hasChangelogStream returns whether any of the streams is a changelog stream with ROWKIND_FIELD
+func hasChangelogStream(streamStmts []*streamInfo) bool {
+	for _, sInfo := range streamStmts {
+		if sInfo.stmt != nil && sInfo.stmt.Options != nil && sInfo.stmt.Options.ROWKIND_FIELD != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// validateChangelog returns whether the rule runs in changelog mode in which all the streams are changelog streams.
+// Only the joins, filters, group by without window and projections are supported in changelog mode.
+func validateChangelog(stmt *ast.SelectStatement, streamStmts []*streamInfo, opt *def.RuleOption) (bool, error) {
+	count := 0
+	for _, sInfo := range streamStmts {
+		if sInfo.stmt == nil || sInfo.stmt.Options == nil || sInfo.stmt.Options.ROWKIND_FIELD == "" {
+			continue
+		}
+		if sInfo.stmt.StreamType != ast.TypeStream {
+			return false, fmt.Errorf("ROWKIND_FIELD is only supported by stream, but %s is a table", sInfo.stmt.Name)
+		}
+		if sInfo.stmt.Options.KEY == "" {
+			return false, fmt.Errorf("changelog stream %s requires the KEY option", sInfo.stmt.Name)
+		}
+		count++
+	}
+	if count == 0 {
+		return false, nil
+	}
+	if count != len(streamStmts) {
+		return false, errors.New("all the streams and tables must be changelog streams in changelog mode")
+	}
+	switch {
+	case stmt.Dimensions != nil && stmt.Dimensions.GetWindow() != nil:
+		return false, errors.New("window is not supported in changelog mode")
+	case opt.IsEventTime:
+		return false, errors.New("event time is not supported in changelog mode")
+	case opt.Experiment != nil && opt.Experiment.UseSliceTuple:
+		return false, errors.New("slice tuple mode is not supported in changelog mode")
+	case stmt.Having != nil:
+		return false, errors.New("HAVING is not supported in changelog mode")
+	case stmt.SortFields != nil:
+		return false, errors.New("ORDER BY is not supported in changelog mode")
+	case stmt.DistinctOn != nil:
+		return false, errors.New("DISTINCT ON is not supported in changelog mode")
+	case stmt.Fill != nil:
+		return false, errors.New("FILL is not supported in changelog mode")
+	case len(stmt.Joins) > 1:
+		return false, errors.New("only one join is supported in changelog mode")
+	}
+	for _, join := range stmt.Joins {
+		if join.JoinType != ast.INNER_JOIN && join.JoinType != ast.LEFT_JOIN {
+			return false, fmt.Errorf("%s is not supported in changelog mode", join.JoinType)
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,46 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+// DistinctOnPlan keeps the first row of each key for the `DISTINCT ON (keys) [WITHIN duration]` clause
+type DistinctOnPlan struct {
+	baseLogicalPlan
+	distinctOn *ast.DistinctOn
+}
+
+func (p DistinctOnPlan) Init() *DistinctOnPlan {
+	p.baseLogicalPlan.self = &p
+	p.baseLogicalPlan.setPlanType(DISTINCTON)
+	return &p
+}
+
+func (p *DistinctOnPlan) BuildExplainInfo() {
+	p.baseLogicalPlan.ExplainInfo.Info = p.distinctOn.String()
+}
+
+// PushDownPredicate the conditions after the deduplication cannot be evaluated before it
+func (p *DistinctOnPlan) PushDownPredicate(condition ast.Expr) (ast.Expr, LogicalPlan) {
+	_, _ = p.baseLogicalPlan.PushDownPredicate(nil)
+	return condition, p.self
+}
+
+func (p *DistinctOnPlan) PruneColumns(fields []ast.Expr) error {
+	f := getFields(p.distinctOn)
+	return p.baseLogicalPlan.PruneColumns(append(fields, f...))
+}
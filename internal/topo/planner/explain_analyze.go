@@ -0,0 +1,190 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/topo"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/node"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+// The positions of the metrics in metric.MetricNames
+const (
+	metricRecordsIn = iota
+	metricRecordsOut
+	_
+	metricLatency
+	metricBufferLength
+	_
+	metricExceptions
+)
+
+type analyzedPlan struct {
+	Op            string          `json:"op"`
+	Info          string          `json:"info"`
+	OptimizeRules []string        `json:"optimizeRules,omitempty"`
+	Nodes         []*analyzedNode `json:"nodes"`
+}
+
+type analyzedNode struct {
+	Name           string         `json:"name"`
+	RecordsIn      int64          `json:"recordsIn"`
+	RecordsOut     int64          `json:"recordsOut"`
+	Selectivity    *float64       `json:"selectivity,omitempty"`
+	Exceptions     int64          `json:"exceptions"`
+	LatencyUs      map[string]any `json:"latencyUs"`
+	BufferLength   int64          `json:"bufferLength"`
+	BufferCapacity int            `json:"bufferCapacity,omitempty"`
+	BufferFill     *float64       `json:"bufferFill,omitempty"`
+	Detail         map[string]any `json:"detail,omitempty"`
+}
+
+// buildPlanNode builds the plan tree of the topo to analyze the running rule
+func buildPlanNode(lp LogicalPlan, ruleID string) *topo.PlanNode {
+	setPlanId(lp, 0)
+	var build func(p LogicalPlan) *topo.PlanNode
+	build = func(p LogicalPlan) *topo.PlanNode {
+		p.BuildExplainInfo()
+		if info, ok := p.(RuleRuntimeInfo); ok {
+			info.BuildSchemaInfo(ruleID)
+		}
+		ei := &PlanExplainInfo{}
+		_ = json.Unmarshal([]byte(p.Explain()), ei)
+		pn := &topo.PlanNode{Op: ei.Op, Info: ei.Info, Nodes: p.nodes()}
+		for _, c := range p.Children() {
+			pn.Children = append(pn.Children, build(c))
+		}
+		return pn
+	}
+	return build(lp)
+}
+
+// ExplainAnalyze explains the running rule. Each plan of the explain result is combined with the runtime
+// metrics of the nodes built for it, and the first line lists the optimize rules which changed the plan.
+func ExplainAnalyze(rule *def.Rule, tp *topo.Topo) (string, error) {
+	plan := tp.GetPlan()
+	if plan == nil {
+		return "", fmt.Errorf("rule %s is not planned from sql", rule.Id)
+	}
+	rules, err := firedOptimizeRules(rule)
+	if err != nil {
+		return "", err
+	}
+	var lines []string
+	var analyze func(pn *topo.PlanNode, level int, first bool)
+	analyze = func(pn *topo.PlanNode, level int, first bool) {
+		ap := &analyzedPlan{Op: pn.Op, Info: pn.Info, Nodes: make([]*analyzedNode, 0, len(pn.Nodes))}
+		if first {
+			ap.OptimizeRules = rules
+		}
+		for _, n := range pn.Nodes {
+			ap.Nodes = append(ap.Nodes, analyzeNode(n))
+		}
+		bf := bytes.NewBuffer([]byte{})
+		jsonEncoder := json.NewEncoder(bf)
+		jsonEncoder.SetEscapeHTML(false)
+		_ = jsonEncoder.Encode(ap)
+		lines = append(lines, strings.Repeat("\t", level)+strings.TrimSuffix(bf.String(), "\n"))
+		for _, c := range pn.Children {
+			analyze(c, level+1, false)
+		}
+	}
+	analyze(plan, 0, true)
+	return strings.Join(lines, "\n"), nil
+}
+
+func analyzeNode(n node.TopNode) *analyzedNode {
+	an := &analyzedNode{Name: "op_" + n.GetName(), LatencyUs: map[string]any{}}
+	if _, ok := n.(node.DataSourceNode); ok {
+		an.Name = "source_" + n.GetName()
+	}
+	if mn, ok := n.(node.MetricNode); ok {
+		metrics := mn.GetMetrics()
+		get := func(i int) int64 {
+			if i < len(metrics) {
+				v, _ := cast.ToInt64(metrics[i], cast.CONVERT_ALL)
+				return v
+			}
+			return 0
+		}
+		an.RecordsIn = get(metricRecordsIn)
+		an.RecordsOut = get(metricRecordsOut)
+		an.Exceptions = get(metricExceptions)
+		an.BufferLength = get(metricBufferLength)
+		an.LatencyUs["last"] = get(metricLatency)
+		if an.RecordsIn > 0 {
+			s := float64(an.RecordsOut) / float64(an.RecordsIn)
+			an.Selectivity = &s
+		}
+	}
+	if ln, ok := n.(interface {
+		GetLatencyPercentiles(ps ...float64) []int64
+	}); ok {
+		if ps := ln.GetLatencyPercentiles(50, 90, 99); len(ps) == 3 {
+			an.LatencyUs["p50"], an.LatencyUs["p90"], an.LatencyUs["p99"] = ps[0], ps[1], ps[2]
+		}
+	}
+	if bn, ok := n.(interface{ GetBufferCapacity() int }); ok {
+		an.BufferCapacity = bn.GetBufferCapacity()
+		if an.BufferCapacity > 0 {
+			f := float64(an.BufferLength) / float64(an.BufferCapacity)
+			an.BufferFill = &f
+		}
+	}
+	if rd, ok := n.(node.RuntimeDetail); ok {
+		an.Detail = rd.GetRuntimeDetail()
+	}
+	return an
+}
+
+// firedOptimizeRules finds the optimize rules which change the plan by planning the rule again without each of them
+func firedOptimizeRules(rule *def.Rule) ([]string, error) {
+	full, err := explainWithOptions(rule, rule.Options)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]string, 0)
+	for _, r := range optRuleList {
+		if !rule.Options.PlanOptimizeStrategy.IsOptimizeEnabled(r.name()) {
+			continue
+		}
+		options := *rule.Options
+		strategy := &def.PlanOptimizeStrategy{}
+		if rule.Options.PlanOptimizeStrategy != nil {
+			*strategy = *rule.Options.PlanOptimizeStrategy
+		}
+		control := &def.OptimizeControl{}
+		if strategy.OptimizeControl != nil {
+			control.DisableOptimizeRules = append(control.DisableOptimizeRules, strategy.OptimizeControl.DisableOptimizeRules...)
+		}
+		control.DisableOptimizeRules = append(control.DisableOptimizeRules, r.name())
+		strategy.OptimizeControl = control
+		options.PlanOptimizeStrategy = strategy
+		without, err := explainWithOptions(rule, &options)
+		if err != nil {
+			return nil, err
+		}
+		if without != full {
+			rules = append(rules, r.name())
+		}
+	}
+	return rules, nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+func TestExplainAnalyze(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	s, err := json.Marshal(&xsql.StreamInfo{StreamType: ast.TypeStream, Statement: `CREATE STREAM analyze1 (a BIGINT, b STRING) WITH (DATASOURCE="analyze1", FORMAT="json");`})
+	require.NoError(t, err)
+	require.NoError(t, kv.Set("analyze1", string(s)))
+
+	r := def.GetDefaultRule("analyze1", `SELECT a FROM analyze1 WHERE upper(b) = "X" AND a > 1`)
+	r.Options.PlanOptimizeStrategy.EnableFilterReorder = true
+	tp, _, err := PlanSQLWithSourcesAndSinks(r, nil)
+	require.NoError(t, err)
+	result, err := ExplainAnalyze(r, tp)
+	require.NoError(t, err)
+	lines := strings.Split(result, "\n")
+	require.Len(t, lines, 3)
+	plans := make([]*analyzedPlan, len(lines))
+	for i, line := range lines {
+		require.True(t, strings.HasPrefix(line, strings.Repeat("\t", i)))
+		plans[i] = &analyzedPlan{}
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimLeft(line, "\t")), plans[i]))
+	}
+	require.Equal(t, "ProjectPlan_0", plans[0].Op)
+	require.Equal(t, []string{"filterReorder"}, plans[0].OptimizeRules)
+	names := func(p *analyzedPlan) []string {
+		var result []string
+		for _, n := range p.Nodes {
+			result = append(result, n.Name)
+		}
+		return result
+	}
+	require.Equal(t, []string{"op_4_project"}, names(plans[0]))
+	require.Equal(t, "FilterPlan_1", plans[1].Op)
+	require.Equal(t, []string{"op_3_filter"}, names(plans[1]))
+	// The cheap conjunct runs first
+	conjuncts := plans[1].Nodes[0].Detail["conjuncts"].([]any)
+	require.Len(t, conjuncts, 2)
+	require.Equal(t, "binaryExpr:{ analyze1.a > 1 }", conjuncts[0].(map[string]any)["condition"])
+	require.Equal(t, "DataSourcePlan_2", plans[2].Op)
+	require.Equal(t, []string{"source_analyze1", "op_2_decoder"}, names(plans[2]))
+	require.Equal(t, 1024, plans[2].Nodes[1].BufferCapacity)
+
+	// Without the filter reorder, no optimize rule changes the plan
+	r.Options.PlanOptimizeStrategy.EnableFilterReorder = false
+	rules, err := firedOptimizeRules(r)
+	require.NoError(t, err)
+	require.Empty(t, rules)
+
+	_, err = ExplainAnalyze(r, nil)
+	require.EqualError(t, err, "rule analyze1 is not planned from sql")
+}
@@ -0,0 +1,92 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"strings"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+// windowRangeFuncs are the functions whose value is decided by the window range
+var windowRangeFuncs = map[string]bool{
+	"window_start":   true,
+	"window_end":     true,
+	"event_time":     true,
+	"window_trigger": true,
+}
+
+type FillPlan struct {
+	baseLogicalPlan
+	fill         *ast.Fill
+	keyFields    []string
+	valueFields  []string
+	windowFields ast.Fields
+	sendNil      bool
+}
+
+func (p FillPlan) Init() *FillPlan {
+	p.baseLogicalPlan.self = &p
+	p.baseLogicalPlan.setPlanType(FILL)
+	return &p
+}
+
+// extractFields splits the selected fields into the aggregate value fields, the window fields and the series key fields
+func (p *FillPlan) extractFields(fields ast.Fields) {
+	for i := range fields {
+		f := &fields[i]
+		if f.Invisible {
+			continue
+		}
+		if _, ok := f.Expr.(*ast.Wildcard); ok {
+			continue
+		}
+		switch {
+		case xsql.HasAggFuncs(f):
+			p.valueFields = append(p.valueFields, f.GetName())
+		case hasWindowRangeFunc(f):
+			expr := f.Expr
+			if fr, ok := expr.(*ast.FieldRef); ok && fr.IsAlias() {
+				expr = fr.AliasRef.Expression
+			}
+			p.windowFields = append(p.windowFields, ast.Field{Name: f.Name, AName: f.AName, Expr: expr})
+		default:
+			p.keyFields = append(p.keyFields, f.GetName())
+		}
+	}
+}
+
+func hasWindowRangeFunc(node ast.Node) bool {
+	r := false
+	ast.WalkFunc(node, func(n ast.Node) bool {
+		if c, ok := n.(*ast.Call); ok && windowRangeFuncs[c.Name] {
+			r = true
+		}
+		return !r
+	})
+	return r
+}
+
+func (p *FillPlan) BuildExplainInfo() {
+	info := p.fill.String()
+	if len(p.keyFields) > 0 {
+		info += ", keys:[" + strings.Join(p.keyFields, ", ") + "]"
+	}
+	if len(p.valueFields) > 0 {
+		info += ", values:[" + strings.Join(p.valueFields, ", ") + "]"
+	}
+	p.baseLogicalPlan.ExplainInfo.Info = info
+}
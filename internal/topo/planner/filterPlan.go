@@ -23,6 +23,8 @@ type FilterPlan struct {
 	baseLogicalPlan
 	condition  ast.Expr
 	stateFuncs []*ast.Call
+	// conjuncts are set if the conjuncts of the condition can be reordered at runtime
+	conjuncts []ast.Expr
 }
 
 func (p FilterPlan) Init() *FilterPlan {
@@ -0,0 +1,137 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"sort"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+// The relative costs to evaluate the expressions
+const (
+	opCost   = 1
+	jsonCost = 2
+	inCost   = 4
+	callCost = 8
+	likeCost = 16
+)
+
+// filterReorder sorts the conjuncts of the filters by the estimated cost so that the cheap ones run first.
+// At runtime, the filter operator reorders the conjuncts again by the observed selectivity and cost.
+type filterReorder struct{}
+
+func (r *filterReorder) optimize(lp LogicalPlan, option *def.RuleOption) (LogicalPlan, error) {
+	if option.PlanOptimizeStrategy == nil || !option.PlanOptimizeStrategy.EnableFilterReorder {
+		return lp, nil
+	}
+	reorderFilters(lp)
+	return lp, nil
+}
+
+func (r *filterReorder) name() string {
+	return "filterReorder"
+}
+
+func reorderFilters(lp LogicalPlan) {
+	if f, ok := lp.(*FilterPlan); ok {
+		f.reorderConjuncts()
+	}
+	for _, c := range lp.Children() {
+		reorderFilters(c)
+	}
+}
+
+func (p *FilterPlan) reorderConjuncts() {
+	conjuncts := splitConjuncts(p.condition, nil)
+	if len(conjuncts) < 2 {
+		return
+	}
+	// The implicit state functions count the hit rows, so the evaluation order must not change
+	hasStateFunc := false
+	ast.WalkFunc(p.condition, func(n ast.Node) bool {
+		if c, ok := n.(*ast.Call); ok && xsql.ImplicitStateFuncs[c.Name] {
+			hasStateFunc = true
+		}
+		return !hasStateFunc
+	})
+	if hasStateFunc {
+		return
+	}
+	costs := make(map[ast.Expr]int, len(conjuncts))
+	for _, c := range conjuncts {
+		costs[c] = exprCost(c)
+	}
+	sort.SliceStable(conjuncts, func(i, j int) bool {
+		return costs[conjuncts[i]] < costs[conjuncts[j]]
+	})
+	var cond ast.Expr
+	for _, c := range conjuncts {
+		cond = combine(cond, c)
+	}
+	p.condition = cond
+	p.conjuncts = conjuncts
+}
+
+// splitConjuncts flattens the AND expressions including the ones in the parentheses
+func splitConjuncts(expr ast.Expr, result []ast.Expr) []ast.Expr {
+	switch e := expr.(type) {
+	case *ast.BinaryExpr:
+		if e.OP == ast.AND {
+			result = splitConjuncts(e.LHS, result)
+			return splitConjuncts(e.RHS, result)
+		}
+	case *ast.ParenExpr:
+		if be, ok := e.Expr.(*ast.BinaryExpr); ok && be.OP == ast.AND {
+			return splitConjuncts(be, result)
+		}
+	case nil:
+		return result
+	}
+	return append(result, expr)
+}
+
+// exprCost estimates the relative cost to evaluate the expression
+func exprCost(expr ast.Expr) int {
+	cost := 0
+	ast.WalkFunc(expr, func(n ast.Node) bool {
+		switch e := n.(type) {
+		case *ast.Call:
+			cost += callCost
+		case *ast.BinaryExpr:
+			switch e.OP {
+			case ast.LIKE, ast.NOTLIKE:
+				cost += likeCost
+			case ast.IN, ast.NOTIN:
+				cost += inCost
+			case ast.ARROW, ast.SUBSET:
+				cost += jsonCost
+			default:
+				cost += opCost
+			}
+		case *ast.CaseExpr:
+			cost += opCost
+		case *ast.FieldRef:
+			// The alias is calculated when it is referred
+			if e.AliasRef != nil && e.AliasRef.Expression != nil {
+				cost += exprCost(e.AliasRef.Expression)
+			}
+		}
+		return true
+	})
+	return cost
+}
@@ -0,0 +1,108 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+func TestSemiJoinPlan(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	streamSqls := map[string]string{
+		"order1":    `CREATE STREAM order1 (id BIGINT, amount FLOAT) WITH (DATASOURCE="order1");`,
+		"payment1":  `CREATE STREAM payment1 (orderId BIGINT, paid BOOLEAN) WITH (DATASOURCE="payment1");`,
+		"customer1": `CREATE TABLE customer1 (orderId BIGINT, name STRING) WITH (DATASOURCE="customer1", TYPE="memory", KIND="lookup", KEY="orderId");`,
+	}
+	for name, sql := range streamSqls {
+		st := ast.TypeStream
+		if name == "customer1" {
+			st = ast.TypeTable
+		}
+		s, err := json.Marshal(&xsql.StreamInfo{StreamType: st, Statement: sql})
+		require.NoError(t, err)
+		require.NoError(t, kv.Set(name, string(s)))
+	}
+
+	testcases := []struct {
+		sql  string
+		info string
+		err  string
+	}{
+		{
+			sql:  `SELECT id, amount FROM order1 SEMI JOIN payment1 ON order1.id = payment1.orderId AND payment1.paid GROUP BY TumblingWindow(ss, 10)`,
+			info: "Joins:[ { joinType:SEMI_JOIN, binaryExpr:{ binaryExpr:{ order1.id = payment1.orderId } AND payment1.paid } } ]",
+		},
+		{
+			sql:  `SELECT * FROM order1 LEFT ANTI JOIN payment1 ON order1.id = payment1.orderId WHERE order1.amount > 100 GROUP BY TumblingWindow(ss, 10)`,
+			info: "Joins:[ { joinType:ANTI_JOIN, binaryExpr:{ order1.id = payment1.orderId } } ]",
+		},
+		{
+			sql: `SELECT id, paid FROM order1 SEMI JOIN payment1 ON order1.id = payment1.orderId GROUP BY TumblingWindow(ss, 10)`,
+			err: "stream payment1 of SEMI_JOIN can only be referred in its ON clause",
+		},
+		{
+			sql: `SELECT id FROM order1 ANTI JOIN payment1 ON order1.id = payment1.orderId WHERE payment1.paid GROUP BY TumblingWindow(ss, 10)`,
+			err: "stream payment1 of ANTI_JOIN can only be referred in its ON clause",
+		},
+		{
+			sql: `SELECT id FROM order1 SEMI JOIN customer1 ON order1.id = customer1.orderId AND customer1.name > "a"`,
+			err: `join condition binaryExpr:{ binaryExpr:{ order1.id = customer1.orderId } AND binaryExpr:{ customer1.name > a } } is invalid, only equi-join predicates are supported by SEMI_JOIN with lookup table`,
+		},
+		{
+			sql:  `SELECT id FROM order1 ANTI JOIN customer1 ON order1.id = customer1.orderId`,
+			info: "",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.sql, func(t *testing.T) {
+			stmt, err := xsql.GetStatementFromSql(tc.sql)
+			require.NoError(t, err)
+			p, err := CreateLogicalPlan(stmt, &def.RuleOption{
+				PlanOptimizeStrategy: &def.PlanOptimizeStrategy{},
+				Qos:                  0,
+			}, kv)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			var join LogicalPlan
+			for lp := p; lp != nil; {
+				if lp.Type() == string(JOIN) || lp.Type() == string(LOOKUP) {
+					join = lp
+					break
+				}
+				if len(lp.Children()) == 0 {
+					break
+				}
+				lp = lp.Children()[0]
+			}
+			require.NotNil(t, join)
+			if tc.info != "" {
+				join.BuildExplainInfo()
+				require.Equal(t, tc.info, join.(*JoinPlan).ExplainInfo.Info)
+			}
+		})
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/lf-edge/ekuiper/v2/internal/topo/node"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
 )
 
@@ -32,6 +33,9 @@ type LogicalPlan interface {
 	PushDownPredicate(ast.Expr) (ast.Expr, LogicalPlan)
 	// PruneColumns Prune the unused columns in the data source level, by pushing all needed columns down
 	PruneColumns(fields []ast.Expr) error
+	// nodes returns the topo nodes built for the plan
+	nodes() []node.TopNode
+	setNodes(nodes []node.TopNode)
 }
 
 type baseLogicalPlan struct {
@@ -40,6 +44,8 @@ type baseLogicalPlan struct {
 	self LogicalPlan
 	// Interface for explaining
 	ExplainInfo *PlanExplainInfo
+	// The topo nodes built for the plan
+	topoNodes []node.TopNode
 }
 
 type ExplainInfo interface {
@@ -114,6 +120,14 @@ func (p *baseLogicalPlan) SetChildren(children []LogicalPlan) {
 	p.children = children
 }
 
+func (p *baseLogicalPlan) nodes() []node.TopNode {
+	return p.topoNodes
+}
+
+func (p *baseLogicalPlan) setNodes(nodes []node.TopNode) {
+	p.topoNodes = nodes
+}
+
 // PushDownPredicate By default, push down the predicate to the first child instead of the children
 // as most plan cannot have multiple children
 func (p *baseLogicalPlan) PushDownPredicate(condition ast.Expr) (ast.Expr, LogicalPlan) {
@@ -146,6 +160,7 @@ const (
 	ANALYTICFUNCS PlanType = "AnalyticFuncsPlan"
 	DATASOURCE    PlanType = "DataSourcePlan"
 	FILTER        PlanType = "FilterPlan"
+	FILL          PlanType = "FillPlan"
 	HAVING        PlanType = "HavingPlan"
 	JOINALIGN     PlanType = "JoinAlignPlan"
 	JOIN          PlanType = "JoinPlan"
@@ -153,9 +168,16 @@ const (
 	ORDER         PlanType = "OrderPlan"
 	PROJECT       PlanType = "ProjectPlan"
 	PROJECTSET    PlanType = "ProjectSetPlan"
+	SUBQUERY      PlanType = "SubqueryPlan"
+	UNION         PlanType = "UnionPlan"
 	WINDOW        PlanType = "WindowPlan"
 	WINDOWFUNC    PlanType = "WindowFuncPlan"
 	WATERMARK     PlanType = "WatermarkPlan"
 	IncAggWindow  PlanType = "IncAggWindowPlan"
 	AggFunc       PlanType = "AggFunc"
+	TOPN          PlanType = "TopNPlan"
+	DISTINCTON    PlanType = "DistinctOnPlan"
+	CHANGELOG     PlanType = "ChangelogPlan"
+	CHANGELOGJOIN PlanType = "ChangelogJoinPlan"
+	CHANGELOGAGG  PlanType = "ChangelogAggregatePlan"
 )
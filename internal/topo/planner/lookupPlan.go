@@ -19,7 +19,10 @@ import (
 
 	"github.com/modern-go/reflect2"
 
+	"github.com/lf-edge/ekuiper/v2/internal/binder/io"
+	nodeConf "github.com/lf-edge/ekuiper/v2/internal/topo/node/conf"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
 )
 
 // LookupPlan is the plan for table lookup and then merged/joined
@@ -109,8 +112,12 @@ func extractLookupCondition(condition ast.Expr, tableName string) (unpushable as
 // validateAndExtractCondition Make sure the join condition is equi-join and extreact other conditions
 func (p *LookupPlan) validateAndExtractCondition() bool {
 	equi, conditions := flatConditions(p.joinExpr.Expr)
+	strName := p.joinExpr.Name
+	kset := make(map[string]struct{})
+	// The st_within predicate on the geometry column of a spatial lookup source is used as the lookup key
+	conditions = p.extractSpatialCondition(conditions, kset)
 	// No equal predict condition found
-	if len(equi) == 0 {
+	if len(equi) == 0 && len(kset) == 0 {
 		return false
 	}
 	if len(conditions) > 0 {
@@ -120,8 +127,6 @@ func (p *LookupPlan) validateAndExtractCondition() bool {
 		}
 	}
 
-	strName := p.joinExpr.Name
-	kset := make(map[string]struct{})
 	// Extract equi-join condition
 	for _, c := range equi {
 		lref, lok := c.LHS.(*ast.FieldRef)
@@ -176,6 +181,67 @@ func (p *LookupPlan) validateAndExtractCondition() bool {
 	return len(kset) > 0
 }
 
+// extractSpatialCondition extracts st_within(expr, table.geometryColumn) as the lookup key if the lookup source
+// supports spatial lookup. The source looks up the rows whose geometry contains the point exactly, so the
+// predicate is removed from the conditions. The other conditions are returned.
+func (p *LookupPlan) extractSpatialCondition(conditions []ast.Expr, kset map[string]struct{}) []ast.Expr {
+	strName := p.joinExpr.Name
+	var (
+		col     string
+		checked bool
+	)
+	rest := make([]ast.Expr, 0, len(conditions))
+	for _, c := range conditions {
+		call, ok := c.(*ast.Call)
+		if !ok || call.Name != "st_within" || len(call.Args) != 2 {
+			rest = append(rest, c)
+			continue
+		}
+		ref, ok := call.Args[1].(*ast.FieldRef)
+		if !ok || string(ref.StreamName) != strName {
+			rest = append(rest, c)
+			continue
+		}
+		if !checked {
+			col = p.spatialColumn()
+			checked = true
+		}
+		_, dup := kset[ref.Name]
+		if col == "" || ref.Name != col || dup || refersTo(call.Args[0], strName) {
+			rest = append(rest, c)
+			continue
+		}
+		kset[ref.Name] = struct{}{}
+		p.keys = append(p.keys, ref.Name)
+		p.valvars = append(p.valvars, call.Args[0])
+	}
+	return rest
+}
+
+// spatialColumn returns the geometry column of the lookup source if it supports spatial lookup. The column is
+// declared in the table properties, so the source is neither provisioned nor loaded to check it.
+func (p *LookupPlan) spatialColumn() string {
+	if p.options == nil {
+		return ""
+	}
+	s, err := io.LookupSource(p.options.TYPE)
+	if s == nil || err != nil {
+		return ""
+	}
+	col, _ := modules.GetSpatialLookupColumn(s, nodeConf.GetSourceConf(p.options.TYPE, p.options))
+	return col
+}
+
+func refersTo(expr ast.Expr, name string) bool {
+	sources, _ := getRefSources(expr)
+	for _, s := range sources {
+		if string(s) == name {
+			return true
+		}
+	}
+	return false
+}
+
 // flatConditions flat the join condition. Only binary condition of EQ and AND are allowed
 func flatConditions(condition ast.Expr) ([]*ast.BinaryExpr, []ast.Expr) {
 	if be, ok := condition.(*ast.BinaryExpr); ok {
@@ -20,6 +20,10 @@ import (
 	"sort"
 	"testing"
 
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
 )
 
@@ -320,3 +324,109 @@ func TestValidate(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateSpatial(t *testing.T) {
+	// Only the file table with the declared geometry column has the spatial index
+	require.NoError(t, conf.WriteCfgIntoKVStorage("sources", "file", "plannerFences", map[string]interface{}{"geometryColumn": "geometry"}))
+	require.NoError(t, conf.WriteCfgIntoKVStorage("sources", "file", "plannerFencesCsv", map[string]interface{}{"geometryColumn": "geometry", "fileType": "csv"}))
+	defer func() {
+		_ = conf.DropCfgKeyFromStorage("sources", "file", "plannerFences")
+		_ = conf.DropCfgKeyFromStorage("sources", "file", "plannerFencesCsv")
+	}()
+	tests := []struct {
+		sql     string
+		tType   string
+		confKey string
+		v       bool
+		c       string
+		k       []string
+		vv      []string
+	}{
+		{
+			sql:     `SELECT * FROM s INNER JOIN fences ON st_within(st_point(s.lon, s.lat), fences.geometry)`,
+			tType:   "file",
+			confKey: "plannerFences",
+			v:       true,
+			k:       []string{"geometry"},
+			vv:      []string{"Call:{ name:st_point, args:[s.lon, s.lat] }"},
+		},
+		{
+			sql:     `SELECT * FROM s INNER JOIN fences ON s.zone = fences.zone AND st_within(s.pos, fences.geometry) AND s.speed > fences.speedLimit`,
+			tType:   "file",
+			confKey: "plannerFences",
+			v:       true,
+			c:       "binaryExpr:{ s.speed > fences.speedLimit }",
+			k:       []string{"geometry", "zone"},
+			vv:      []string{"s.pos", "s.zone"},
+		},
+		{
+			sql:     `SELECT * FROM s INNER JOIN fences ON st_within(s.pos, fences.other)`,
+			tType:   "file",
+			confKey: "plannerFences",
+			v:       false,
+		},
+		{
+			sql:     `SELECT * FROM s INNER JOIN fences ON st_within(fences.center, fences.geometry)`,
+			tType:   "file",
+			confKey: "plannerFences",
+			v:       false,
+		},
+		{
+			sql:   `SELECT * FROM s INNER JOIN fences ON st_within(s.pos, fences.geometry)`,
+			tType: "memory",
+			v:     false,
+		},
+		{
+			sql:   `SELECT * FROM s INNER JOIN fences ON s.id = fences.id AND st_within(s.pos, fences.geometry)`,
+			tType: "memory",
+			v:     true,
+			c:     "Call:{ name:st_within, args:[s.pos, fences.geometry] }",
+			k:     []string{"id"},
+			vv:    []string{"s.id"},
+		},
+		{
+			sql:   `SELECT * FROM s INNER JOIN fences ON st_within(s.pos, fences.geometry)`,
+			tType: "file",
+			v:     false,
+		},
+		{
+			sql:     `SELECT * FROM s INNER JOIN fences ON st_within(s.pos, fences.geometry)`,
+			tType:   "file",
+			confKey: "plannerFencesCsv",
+			v:       false,
+		},
+		{
+			sql:   `SELECT * FROM s INNER JOIN fences ON s.id = fences.id AND st_within(s.pos, fences.geometry)`,
+			tType: "file",
+			v:     true,
+			c:     "Call:{ name:st_within, args:[s.pos, fences.geometry] }",
+			k:     []string{"id"},
+			vv:    []string{"s.id"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt, err := xsql.GetStatementFromSql(tt.sql)
+			require.NoError(t, err)
+			p := &LookupPlan{
+				joinExpr: stmt.Joins[0],
+				options:  &ast.Options{TYPE: tt.tType, DATASOURCE: "fences.geojson", CONF_KEY: tt.confKey},
+			}
+			require.Equal(t, tt.v, p.validateAndExtractCondition())
+			if !tt.v {
+				return
+			}
+			if tt.c == "" {
+				require.Nil(t, p.conditions)
+			} else {
+				require.Equal(t, tt.c, p.conditions.String())
+			}
+			require.Equal(t, tt.k, p.keys)
+			vv := make([]string, len(p.valvars))
+			for i, v := range p.valvars {
+				vv[i] = v.String()
+			}
+			require.Equal(t, tt.vv, vv)
+		})
+	}
+}
@@ -21,6 +21,7 @@ var optRuleList = []logicalOptRule{
 	&predicatePushDown{},
 	//&pushProjectionPlan{},
 	&pushAliasDecode{},
+	&filterReorder{},
 }
 
 func optimize(p LogicalPlan, options *def.RuleOption) (LogicalPlan, error) {
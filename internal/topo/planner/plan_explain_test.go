@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -26,6 +27,7 @@ import (
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 )
 
 func TestExplainPlan(t *testing.T) {
@@ -124,6 +126,34 @@ func TestExplainPlan(t *testing.T) {
 	}
 }
 
+func TestExplainFillPlan(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	require.NoError(t, prepareStream())
+	sql := `select b, avg(a) as aa, window_end() as we from stream group by b, tumblingwindow(ss, 10) fill(linear)`
+	stmt, err := xsql.NewParser(strings.NewReader(sql)).Parse()
+	require.NoError(t, err)
+	p, err := CreateLogicalPlan(stmt, &def.RuleOption{Qos: 0}, kv)
+	require.NoError(t, err)
+	explain, err := ExplainFromLogicalPlan(p, "")
+	require.NoError(t, err)
+	require.Equal(t, `{"op":"FillPlan_0","info":"fill:{ linear }, keys:[b], values:[aa]"}
+	{"op":"ProjectPlan_1","info":"Fields:[ $$alias.aa,aliasRef:Call:{ name:avg, args:[stream.a] }, $$alias.we,aliasRef:Call:{ name:window_end }, stream.b ]"}
+			{"op":"AggregatePlan_2","info":"Dimension:{ stream.b }"}
+					{"op":"WindowPlan_3","info":"{ length:10, windowType:TUMBLING_WINDOW, limit: 0 }"}
+							{"op":"DataSourcePlan_4","info":"StreamName: stream, StreamFields:[ a, b ]"}`, explain)
+
+	sql = `select count(a) from stream group by tumblingwindow(ss, 10) fill(0)`
+	stmt, err = xsql.NewParser(strings.NewReader(sql)).Parse()
+	require.NoError(t, err)
+	_, err = CreateLogicalPlan(stmt, &def.RuleOption{
+		PlanOptimizeStrategy: &def.PlanOptimizeStrategy{
+			EnableIncrementalWindow: true,
+		},
+	}, kv)
+	require.EqualError(t, err, "FILL clause does not support incremental window yet")
+}
+
 func prepareStream() error {
 	kv, err := store.GetKV("stream")
 	if err != nil {
@@ -143,6 +173,25 @@ func prepareStream() error {
 					b BIGINT,
 				) WITH (DATASOURCE="src1");`,
 		"memlookup": `CREATE TABLE memlookup() WITH (DATASOURCE="topicB", KEY="key" TYPE="memory", KIND="lookup")`,
+		"tsStream": `CREATE STREAM tsStream (
+					a BIGINT,
+					b BIGINT,
+				) WITH (DATASOURCE="src2", TIMESTAMP="b");`,
+		"wmStream": `CREATE STREAM wmStream (
+					a BIGINT,
+					b BIGINT,
+				) WITH (DATASOURCE="src2", TIMESTAMP="b", WATERMARK_DELAY="5s", IDLE_TIMEOUT="1m", WATERMARK_KEY="partition");`,
+		"orders": `CREATE STREAM orders (
+					id BIGINT,
+					cid BIGINT,
+					amount FLOAT,
+					op STRING,
+				) WITH (DATASOURCE="orders", KEY="id", ROWKIND_FIELD="op");`,
+		"customers": `CREATE STREAM customers (
+					cid BIGINT,
+					region STRING,
+					op STRING,
+				) WITH (DATASOURCE="customers", KEY="cid", ROWKIND_FIELD="op");`,
 	}
 
 	types := map[string]ast.StreamType{
@@ -150,6 +199,10 @@ func prepareStream() error {
 		"stream":       ast.TypeStream,
 		"stream2":      ast.TypeStream,
 		"memlookup":    ast.TypeTable,
+		"tsStream":     ast.TypeStream,
+		"wmStream":     ast.TypeStream,
+		"orders":       ast.TypeStream,
+		"customers":    ast.TypeStream,
 	}
 	for name, sql := range streamSqls {
 		s, err := json.Marshal(&xsql.StreamInfo{
@@ -350,3 +403,159 @@ func TestStreamJoinTableLookup(t *testing.T) {
 		require.Equal(t, tc.explain, explain, tc.sql)
 	}
 }
+
+func TestExplainTopNPlan(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	require.NoError(t, prepareStream())
+	testcases := []struct {
+		sql     string
+		explain string
+	}{
+		{
+			sql: `select a, row_number() over (partition by b order by a desc) as rn from stream where row_number() over (partition by b order by a desc) <= 3 and a > 1`,
+			explain: `{"op":"ProjectPlan_0","info":"Fields:[ $$alias.rn,aliasRef:Call:{ name:bypass, args:[$$default.$$topn_rank_0] }, stream.a ]"}
+	{"op":"TopNPlan_1","info":"N:3, PartitionExpr:[ stream.b ], SortFields:[ sortField:{ name:a, ascending:false, fieldExpr:{ stream.a } } ], TTL:3600000"}
+			{"op":"FilterPlan_2","info":"Condition:{ binaryExpr:{ stream.a > 1 } }, "}
+					{"op":"DataSourcePlan_3","info":"StreamName: stream, StreamFields:[ a, b ]"}`,
+		},
+		{
+			sql: `select b, max(a) from stream group by b, tumblingwindow(ss, 10) having row_number() over (order by max(a) desc) = 1`,
+			explain: `{"op":"ProjectPlan_0","info":"Fields:[ stream.b, Call:{ name:max, args:[stream.a] } ]"}
+	{"op":"TopNPlan_1","info":"N:1, SortFields:[ sortField:{ name:max, ascending:false, fieldExpr:{ Call:{ name:max, args:[stream.a] } } } ], TTL:3600000"}
+			{"op":"AggregatePlan_2","info":"Dimension:{ stream.b }"}
+					{"op":"WindowPlan_3","info":"{ length:10, windowType:TUMBLING_WINDOW, limit: 0 }"}
+							{"op":"DataSourcePlan_4","info":"StreamName: stream, StreamFields:[ a, b ]"}`,
+		},
+		{
+			sql: `select distinct on (a) within 10m * from stream`,
+			explain: `{"op":"ProjectPlan_0","info":"Fields:[ * ]"}
+	{"op":"DistinctOnPlan_1","info":"distinctOn:{ keys:[stream.a], within:600000 }"}
+			{"op":"DataSourcePlan_2","info":"StreamName: stream, StreamFields:[ a, b ]"}`,
+		},
+	}
+	for _, tc := range testcases {
+		stmt, err := xsql.NewParser(strings.NewReader(tc.sql)).Parse()
+		require.NoError(t, err)
+		p, err := CreateLogicalPlan(stmt, &def.RuleOption{
+			Qos:      0,
+			StateTTL: cast.DurationConf(time.Hour),
+		}, kv)
+		require.NoError(t, err)
+		explain, err := ExplainFromLogicalPlan(p, "")
+		require.NoError(t, err)
+		require.Equal(t, tc.explain, explain, tc.sql)
+	}
+}
+
+func TestExplainTopNDefaultTTL(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	require.NoError(t, prepareStream())
+	stmt, err := xsql.NewParser(strings.NewReader(`select a from stream where row_number() over (partition by b order by a desc) <= 2`)).Parse()
+	require.NoError(t, err)
+	p, err := CreateLogicalPlan(stmt, &def.RuleOption{}, kv)
+	require.NoError(t, err)
+	explain, err := ExplainFromLogicalPlan(p, "")
+	require.NoError(t, err)
+	require.Contains(t, explain, "TTL:86400000")
+}
+
+func TestExplainWatermarkStrategy(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	require.NoError(t, prepareStream())
+	stmt, err := xsql.NewParser(strings.NewReader(`select count(*) from tsStream inner join wmStream on tsStream.a = wmStream.a group by tumblingwindow(ss, 10)`)).Parse()
+	require.NoError(t, err)
+	p, err := CreateLogicalPlan(stmt, &def.RuleOption{
+		IsEventTime: true,
+		LateTol:     cast.DurationConf(time.Second),
+	}, kv)
+	require.NoError(t, err)
+	explain, err := ExplainFromLogicalPlan(p, "")
+	require.NoError(t, err)
+	require.Equal(t, `{"op":"ProjectPlan_0","info":"Fields:[ Call:{ name:count, args:[*] } ]"}
+	{"op":"JoinPlan_1","info":"Joins:[ { joinType:INNER_JOIN, binaryExpr:{ tsStream.a = wmStream.a } } ]"}
+			{"op":"WindowPlan_2","info":"{ length:10, windowType:TUMBLING_WINDOW, limit: 0 }"}
+					{"op":"WatermarkPlan_3","info":"Emitters:[ tsStream, wmStream ], SendWatermark:true, Strategies:[ wmStream:{ delay:5s, idleTimeout:1m0s, key:partition } ]"}
+							{"op":"DataSourcePlan_4","info":"StreamName: tsStream, StreamFields:[ a, b ]"}
+							{"op":"DataSourcePlan_5","info":"StreamName: wmStream, StreamFields:[ a, b ]"}`, explain)
+}
+
+func TestExplainChangelog(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	require.NoError(t, prepareStream())
+	stmt, err := xsql.NewParser(strings.NewReader(`select region, sum(amount) as total from orders left join customers on orders.cid = customers.cid where amount > 10 group by region`)).Parse()
+	require.NoError(t, err)
+	p, err := CreateLogicalPlan(stmt, &def.RuleOption{}, kv)
+	require.NoError(t, err)
+	explain, err := ExplainFromLogicalPlan(p, "")
+	require.NoError(t, err)
+	require.Equal(t, `{"op":"ProjectPlan_0","info":"Fields:[ $$alias.total,aliasRef:Call:{ name:bypass, args:[$$default.changelog_agg_col_1] }, customers.region ]"}
+	{"op":"ChangelogAggregatePlan_1","info":"Dimension:{ customers.region }, funcs:[Call:{ name:sum, args:[orders.amount] }->changelog_agg_col_1]"}
+			{"op":"FilterPlan_2","info":"Condition:{ binaryExpr:{ orders.amount > 10 } }, "}
+					{"op":"ChangelogJoinPlan_3","info":"Join:{ joinType:LEFT_JOIN, binaryExpr:{ orders.cid = customers.cid } }"}
+							{"op":"ChangelogPlan_4","info":"Key:id, RowkindField:op"}
+									{"op":"DataSourcePlan_5","info":"StreamName: orders, StreamFields:[ amount, cid, id, op ]"}
+
+							{"op":"ChangelogPlan_5","info":"Key:cid, RowkindField:op"}
+									{"op":"DataSourcePlan_6","info":"StreamName: customers, StreamFields:[ cid, op, region ]"}`, explain)
+}
+
+func TestChangelogValidation(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	require.NoError(t, prepareStream())
+	tests := []struct {
+		sql string
+		opt *def.RuleOption
+		err string
+	}{
+		{
+			sql: `select count(*) from orders group by cid, tumblingwindow(ss, 10)`,
+			err: "window is not supported in changelog mode",
+		},
+		{
+			sql: `select count(*) from orders group by cid having count(*) > 1`,
+			err: "HAVING is not supported in changelog mode",
+		},
+		{
+			sql: `select * from orders order by amount`,
+			err: "ORDER BY is not supported in changelog mode",
+		},
+		{
+			sql: `select * from orders inner join stream on orders.cid = stream.a`,
+			err: "all the streams and tables must be changelog streams in changelog mode",
+		},
+		{
+			sql: `select * from orders full join customers on orders.cid = customers.cid`,
+			err: "FULL_JOIN is not supported in changelog mode",
+		},
+		{
+			sql: `select id, lag(amount) as last from orders`,
+			err: "analytic function is not supported in changelog mode",
+		},
+		{
+			sql: `select cid, collect(amount) as amounts from orders group by cid`,
+			err: "aggregate function collect is not supported in changelog mode",
+		},
+		{
+			sql: `select * from orders`,
+			opt: &def.RuleOption{IsEventTime: true},
+			err: "event time is not supported in changelog mode",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
+			require.NoError(t, err)
+			opt := tt.opt
+			if opt == nil {
+				opt = &def.RuleOption{}
+			}
+			_, err = CreateLogicalPlan(stmt, opt, kv)
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
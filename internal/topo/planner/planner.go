@@ -26,6 +26,7 @@ import (
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	store2 "github.com/lf-edge/ekuiper/v2/internal/pkg/store"
+	"github.com/lf-edge/ekuiper/v2/internal/processor"
 	"github.com/lf-edge/ekuiper/v2/internal/topo"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/node"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/operator"
@@ -56,10 +57,13 @@ func PlanSQLWithSourcesAndSinks(rule *def.Rule, mockSourcesProp map[string]map[s
 		}
 	}
 	conf.Log.Infof("Init rule with options %+v", rule.Options)
-	stmt, err := xsql.GetStatementFromSql(sql)
+	stmt, err := processor.GetStreamProcessorStatement(sql)
 	if err != nil {
 		return nil, stmt, err
 	}
+	if err := expandStatement(stmt, rule.Options, len(mockSourcesProp) > 0); err != nil {
+		return nil, stmt, err
+	}
 	// validation
 	streamsFromStmt := xsql.GetStreams(stmt)
 	// validate stmt
@@ -77,7 +81,7 @@ func PlanSQLWithSourcesAndSinks(rule *def.Rule, mockSourcesProp map[string]map[s
 		return nil, stmt, err
 	}
 	// Create the logical plan and optimize. Logical plans are a linked list
-	lp, af, aff, err := createLogicalPlanFull(stmt, rule.Options, store, rule.Temp)
+	lp, af, aff, err := createLogicalPlanFull(stmt, rule.Options, nil, store, rule.Temp)
 	if err != nil {
 		return nil, stmt, err
 	}
@@ -95,6 +99,56 @@ func PlanSQLWithSourcesAndSinks(rule *def.Rule, mockSourcesProp map[string]map[s
 	return tp, stmt, nil
 }
 
+// expandStatement expands the views and the SQL functions of the rule statement. The view source of the statement is
+// planned as a derived table shared by the rules unless the rule runs in event time, slice mode or rule test.
+func expandStatement(stmt *ast.SelectStatement, opt *def.RuleOption, inRuleTest bool) error {
+	var err error
+	if inRuleTest || opt.IsEventTime || (opt.Experiment != nil && opt.Experiment.UseSliceTuple) {
+		_, err = processor.ExpandStreamProcessorStatement(stmt)
+	} else {
+		_, err = processor.ExpandStreamProcessorStatementWithSharedViews(stmt)
+	}
+	return err
+}
+
+// ValidateRule validates the rule against the definitions of its streams without running it. The sql rule is
+// validated by creating the logical plan and the graph rule is validated by planning the graph. The overrides are the
+// stream statements by name to use instead of the saved ones, so that a stream change can be checked before saving.
+func ValidateRule(rule *def.Rule, overrides map[string]string) error {
+	so := make(streamOverrides, len(overrides))
+	for name, statement := range overrides {
+		stmt, err := xsql.Language.Parse(xsql.NewParser(strings.NewReader(statement)))
+		if err != nil {
+			return err
+		}
+		s, ok := stmt.(*ast.StreamStmt)
+		if !ok {
+			return fmt.Errorf("invalid stream statement: %s", statement)
+		}
+		so[name] = s
+	}
+	if rule.Sql == "" {
+		_, err := planByGraph(rule, so)
+		return err
+	}
+	stmt, err := processor.GetStreamProcessorStatement(rule.Sql)
+	if err != nil {
+		return err
+	}
+	if err := expandStatement(stmt, rule.Options, false); err != nil {
+		return err
+	}
+	if err := validateStmt(stmt); err != nil {
+		return err
+	}
+	store, err := store2.GetKV("stream")
+	if err != nil {
+		return err
+	}
+	_, _, _, err = createLogicalPlanFull(stmt, rule.Options, so, store, rule.Temp)
+	return err
+}
+
 func updateFieldIndex(ctx api.StreamContext, stmt *ast.SelectStatement, af []*ast.Call, aff []*ast.Call) {
 	var (
 		fieldExprs      []ast.Node
@@ -272,7 +326,15 @@ func validateStmt(stmt *ast.SelectStatement) error {
 		}
 		return true
 	})
-	return vErr
+	if vErr != nil {
+		return vErr
+	}
+	for _, u := range stmt.Unions {
+		if err := validateStmt(u); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func createTopo(rule *def.Rule, lp LogicalPlan, mockSourcesProp map[string]map[string]any, streamsFromStmt []string, schema map[string]*ast.JsonStreamField) (t *topo.Topo, err error) {
@@ -305,6 +367,7 @@ func createTopo(rule *def.Rule, lp LogicalPlan, mockSourcesProp map[string]map[s
 	if err != nil {
 		return nil, err
 	}
+	tp.SetPlan(buildPlanNode(lp, rule.Id))
 	inputs := []node.Emitter{input}
 	// Add actions
 	err = buildActions(tp, rule, inputs, len(streamsFromStmt), schema)
@@ -316,17 +379,24 @@ func createTopo(rule *def.Rule, lp LogicalPlan, mockSourcesProp map[string]map[s
 }
 
 func GetExplainInfoFromLogicalPlan(rule *def.Rule) (string, error) {
+	return explainWithOptions(rule, rule.Options)
+}
+
+func explainWithOptions(rule *def.Rule, options *def.RuleOption) (string, error) {
 	sql := rule.Sql
 
-	conf.Log.Infof("Init rule with options %+v", rule.Options)
-	stmt, err := xsql.GetStatementFromSql(sql)
+	conf.Log.Infof("Init rule with options %+v", options)
+	stmt, err := processor.GetStreamProcessorStatement(sql)
 	if err != nil {
 		return "", err
 	}
+	if err := expandStatement(stmt, options, false); err != nil {
+		return "", err
+	}
 	// validation
 	streamsFromStmt := xsql.GetStreams(stmt)
 
-	if rule.Options.SendMetaToSink && (len(streamsFromStmt) > 1 || stmt.Dimensions != nil) {
+	if options.SendMetaToSink && (len(streamsFromStmt) > 1 || stmt.Dimensions != nil) {
 		return "", fmt.Errorf("invalid option sendMetaToSink, it can not be applied to window")
 	}
 	store, err := store2.GetKV("stream")
@@ -334,7 +404,7 @@ func GetExplainInfoFromLogicalPlan(rule *def.Rule) (string, error) {
 		return "", err
 	}
 	// Create logical plan and optimize. Logical plans are a linked list
-	lp, err := CreateLogicalPlan(stmt, rule.Options, store)
+	lp, err := CreateLogicalPlan(stmt, options, store)
 	if err != nil {
 		return "", err
 	}
@@ -342,16 +412,7 @@ func GetExplainInfoFromLogicalPlan(rule *def.Rule) (string, error) {
 }
 
 func ExplainFromLogicalPlan(lp LogicalPlan, ruleID string) (string, error) {
-	var setId func(p LogicalPlan, id int64)
-	setId = func(p LogicalPlan, id int64) {
-		p.SetID(id)
-		children := p.Children()
-		for i := 0; i < len(children); i++ {
-			id++
-			setId(children[i], id)
-		}
-	}
-	setId(lp, 0)
+	setPlanId(lp, 0)
 	var getExplainInfo func(p LogicalPlan, level int) string
 	getExplainInfo = func(p LogicalPlan, level int) string {
 		tmp := ""
@@ -378,8 +439,26 @@ func ExplainFromLogicalPlan(lp LogicalPlan, ruleID string) (string, error) {
 	return strings.Trim(res, "\n"), nil
 }
 
+func setPlanId(p LogicalPlan, id int64) {
+	p.SetID(id)
+	children := p.Children()
+	for i := 0; i < len(children); i++ {
+		id++
+		setPlanId(children[i], id)
+	}
+}
+
 // return the last schema if there are multiple sources
 func buildOps(lp LogicalPlan, tp *topo.Topo, options *def.RuleOption, sources map[string]map[string]any, streamsFromStmt []string, index int) (node.Emitter, int, error) {
+	if sp, ok := lp.(*SubqueryPlan); ok && sp.view != "" {
+		input, ni, shared, err := buildSharedView(tp, sp, options, index)
+		if err != nil {
+			return nil, 0, err
+		}
+		if shared {
+			return input, ni, nil
+		}
+	}
 	var inputs []node.Emitter
 	newIndex := index
 	for _, c := range lp.Children() {
@@ -391,6 +470,8 @@ func buildOps(lp LogicalPlan, tp *topo.Topo, options *def.RuleOption, sources ma
 		inputs = append(inputs, input)
 	}
 	newIndex++
+	// The nodes added from now on are built for this plan
+	srcCount, opCount := len(tp.GetSourceNodes()), len(tp.GetOperators())
 	var (
 		op  node.Emitter
 		err error
@@ -417,9 +498,9 @@ func buildOps(lp LogicalPlan, tp *topo.Topo, options *def.RuleOption, sources ma
 			newIndex += indexInc
 		}
 	case *WatermarkPlan:
-		op = node.NewWatermarkOp(fmt.Sprintf("%d_watermark", newIndex), t.SendWatermark, t.Emitters, options)
+		op = node.NewWatermarkOp(fmt.Sprintf("%d_watermark", newIndex), t.SendWatermark, t.Emitters, t.Strategies, options)
 	case *AnalyticFuncsPlan:
-		op = Transform(&operator.AnalyticFuncsOp{Funcs: t.funcs, FieldFuncs: t.fieldFuncs}, fmt.Sprintf("%d_analytic", newIndex), options)
+		op = newAnalyticFuncsOp(t, newIndex, options)
 	case *IncWindowPlan:
 		if t.Condition != nil {
 			wfilterOp := Transform(&operator.FilterOp{Condition: t.Condition}, fmt.Sprintf("%d_windowFilter", newIndex), options)
@@ -508,21 +589,36 @@ func buildOps(lp LogicalPlan, tp *topo.Topo, options *def.RuleOption, sources ma
 	case *AggFuncPlan:
 		op = Transform(&operator.AggFuncOp{AggFields: t.aggFields}, fmt.Sprintf("%d_agg_func", newIndex), options)
 	case *FilterPlan:
-		t.ExtractStateFunc()
-		op = Transform(&operator.FilterOp{Condition: t.condition, StateFuncs: t.stateFuncs}, fmt.Sprintf("%d_filter", newIndex), options)
+		op = newFilterOp(t, newIndex, options)
 	case *AggregatePlan:
-		op = Transform(&operator.AggregateOp{Dimensions: t.dimensions}, fmt.Sprintf("%d_aggregate", newIndex), options)
+		op = Transform(&operator.AggregateOp{Dimensions: t.dimensions, KeepEmpty: t.keepEmpty}, fmt.Sprintf("%d_aggregate", newIndex), options)
 	case *HavingPlan:
 		t.ExtractStateFunc()
 		op = Transform((&operator.HavingOp{Condition: t.condition, StateFuncs: t.stateFuncs, IsIncAgg: t.IsIncAgg}), fmt.Sprintf("%d_having", newIndex), options)
 	case *OrderPlan:
 		op = Transform(&operator.OrderOp{SortFields: t.SortFields}, fmt.Sprintf("%d_order", newIndex), options)
 	case *ProjectPlan:
-		op = Transform(&operator.ProjectOp{Fields: t.fields, FieldLen: t.fieldLen, ColNames: t.colNames, AliasFields: t.aliasFields, ExprFields: t.exprFields, ExprIndices: t.exprIndices, ExceptNames: t.exceptNames, IsAggregate: t.isAggregate, AllWildcard: t.allWildcard, WildcardEmitters: t.wildcardEmitters, SendMeta: t.sendMeta, SendNil: t.sendNil, LimitCount: t.limitCount, EnableLimit: t.enableLimit}, fmt.Sprintf("%d_project", newIndex), options)
+		op = newProjectOp(t, newIndex, options)
+	case *FillPlan:
+		op = Transform(&operator.FillOp{Fill: t.fill, KeyFields: t.keyFields, ValueFields: t.valueFields, WindowFields: t.windowFields, SendNil: t.sendNil}, fmt.Sprintf("%d_fill", newIndex), options)
 	case *ProjectSetPlan:
 		op = Transform(&operator.ProjectSetOperator{SrfMapping: t.SrfMapping, LimitCount: t.limitCount, EnableLimit: t.enableLimit}, fmt.Sprintf("%d_projectset", newIndex), options)
+	case *ChangelogPlan:
+		op = Transform(&operator.ChangelogNormalizeOp{Key: t.key, RowkindField: t.rowkindField}, fmt.Sprintf("%d_changelog", newIndex), options)
+	case *ChangelogJoinPlan:
+		op = Transform(&operator.ChangelogJoinOp{From: t.from, Join: t.join}, fmt.Sprintf("%d_changelog_join", newIndex), options)
+	case *ChangelogAggregatePlan:
+		op = Transform(&operator.ChangelogAggregateOp{Dimensions: t.dimensions, AggFields: t.aggFields}, fmt.Sprintf("%d_changelog_aggregate", newIndex), options)
+	case *DistinctOnPlan:
+		op = Transform(&operator.DistinctOnOp{Keys: t.distinctOn.Keys, Within: t.distinctOn.Within}, fmt.Sprintf("%d_distinct_on", newIndex), options)
+	case *TopNPlan:
+		op = Transform(&operator.TopNOp{Partition: t.partition, SortFields: t.sortFields, N: t.n, RankName: t.rankName, TTL: t.ttl}, fmt.Sprintf("%d_topn", newIndex), options)
 	case *WindowFuncPlan:
 		op = Transform(&operator.WindowFuncOperator{WindowFuncField: t.windowFuncField}, fmt.Sprintf("%d_windowFunc", newIndex), options)
+	case *SubqueryPlan:
+		op = Transform(&operator.SubqueryOp{Emitter: string(t.name), KeepWindow: t.keepWindow}, fmt.Sprintf("%d_subquery_%s", newIndex, t.name), options)
+	case *UnionPlan:
+		op = Transform(&operator.UnionOp{}, fmt.Sprintf("%d_union", newIndex), options)
 	default:
 		err = fmt.Errorf("unknown logical plan %v", t)
 	}
@@ -532,9 +628,30 @@ func buildOps(lp LogicalPlan, tp *topo.Topo, options *def.RuleOption, sources ma
 	if onode, ok := op.(node.OperatorNode); ok {
 		tp.AddOperator(inputs, onode)
 	}
+	var nodes []node.TopNode
+	for _, n := range tp.GetSourceNodes()[srcCount:] {
+		nodes = append(nodes, n)
+	}
+	for _, n := range tp.GetOperators()[opCount:] {
+		nodes = append(nodes, n)
+	}
+	lp.setNodes(nodes)
 	return op, newIndex, nil
 }
 
+func newAnalyticFuncsOp(t *AnalyticFuncsPlan, index int, options *def.RuleOption) *node.UnaryOperator {
+	return Transform(&operator.AnalyticFuncsOp{Funcs: t.funcs, FieldFuncs: t.fieldFuncs}, fmt.Sprintf("%d_analytic", index), options)
+}
+
+func newFilterOp(t *FilterPlan, index int, options *def.RuleOption) *node.UnaryOperator {
+	t.ExtractStateFunc()
+	return Transform(&operator.FilterOp{Condition: t.condition, StateFuncs: t.stateFuncs, Conjuncts: t.conjuncts}, fmt.Sprintf("%d_filter", index), options)
+}
+
+func newProjectOp(t *ProjectPlan, index int, options *def.RuleOption) *node.UnaryOperator {
+	return Transform(&operator.ProjectOp{Fields: t.fields, FieldLen: t.fieldLen, ColNames: t.colNames, AliasFields: t.aliasFields, ExprFields: t.exprFields, ExprIndices: t.exprIndices, ExceptNames: t.exceptNames, IsAggregate: t.isAggregate, AllWildcard: t.allWildcard, WildcardEmitters: t.wildcardEmitters, SendMeta: t.sendMeta, SendNil: t.sendNil, RowkindField: t.rowkindField, SendUpdateBefore: t.sendUpdateBefore, LimitCount: t.limitCount, EnableLimit: t.enableLimit}, fmt.Sprintf("%d_project", index), options)
+}
+
 func convertFromDuration(timeUnit ast.Token, length, interval int, delay int64) (time.Duration, time.Duration, time.Duration) {
 	var unit time.Duration
 	switch timeUnit {
@@ -553,7 +670,7 @@ func convertFromDuration(timeUnit ast.Token, length, interval int, delay int64)
 }
 
 func CreateLogicalPlan(stmt *ast.SelectStatement, opt *def.RuleOption, store kv.KeyValue) (LogicalPlan, error) {
-	lp, _, _, err := createLogicalPlanFull(stmt, opt, store, false)
+	lp, _, _, err := createLogicalPlanFull(stmt, opt, nil, store, false)
 	return lp, err
 }
 
@@ -569,7 +686,10 @@ func checkSharedSourceOption(streams []*streamInfo, opt *def.RuleOption) error {
 	return nil
 }
 
-func createLogicalPlanFull(stmt *ast.SelectStatement, opt *def.RuleOption, store kv.KeyValue, isTemp bool) (LogicalPlan, []*ast.Call, []*ast.Call, error) {
+func createLogicalPlanFull(stmt *ast.SelectStatement, opt *def.RuleOption, overrides streamOverrides, store kv.KeyValue, isTemp bool) (LogicalPlan, []*ast.Call, []*ast.Call, error) {
+	if len(stmt.Unions) > 0 {
+		return createUnionPlan(stmt, opt, overrides, store, isTemp)
+	}
 	dimensions := stmt.Dimensions
 	var (
 		p        LogicalPlan
@@ -580,11 +700,13 @@ func createLogicalPlanFull(stmt *ast.SelectStatement, opt *def.RuleOption, store
 		scanTableEmitters   []string
 		scanTableSizes      []int
 		streamEmitters      []string
+		wmStrategies        map[string]*node.WatermarkStrategy
 		w                   *ast.Window
 		ds                  ast.Dimensions
+		subqueryChildren    []*SubqueryPlan
 	)
 
-	streamStmts, analyticFuncs, analyticFieldFuncs, err := decorateStmt(stmt, opt, isTemp)
+	streamStmts, analyticFuncs, analyticFieldFuncs, err := decorateStmt(stmt, opt, overrides, isTemp)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -592,9 +714,44 @@ func createLogicalPlanFull(stmt *ast.SelectStatement, opt *def.RuleOption, store
 		return nil, nil, nil, err
 	}
 
+	whereTopN, havingTopN, err := extractTopN(stmt, opt)
+	if err != nil {
+		return nil, nil, nil, err
+	}
 	rewriteRes := rewriteStmt(stmt, opt)
+	changelog, err := validateChangelog(stmt, streamStmts, opt)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var changelogAggFields []*ast.Field
+	if changelog {
+		if len(analyticFuncs) > 0 || len(analyticFieldFuncs) > 0 {
+			return nil, nil, nil, errors.New("analytic function is not supported in changelog mode")
+		}
+		if whereTopN != nil || havingTopN != nil || len(rewriteRes.windowFuncFields) > 0 {
+			return nil, nil, nil, errors.New("window function is not supported in changelog mode")
+		}
+		changelogAggFields, err = extractChangelogAgg(stmt)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
 
 	for _, sInfo := range streamStmts {
+		if sInfo.subquery != nil {
+			if opt.Experiment != nil && opt.Experiment.UseSliceTuple {
+				return nil, nil, nil, fmt.Errorf("slice tuple mode do not support subquery yet %s", sInfo.stmt.Name)
+			}
+			sp, err := createSubqueryPlan(sInfo, opt, overrides, store, isTemp)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			p = sp
+			children = append(children, p)
+			streamEmitters = append(streamEmitters, string(sInfo.stmt.Name))
+			subqueryChildren = append(subqueryChildren, sp)
+			continue
+		}
 		if sInfo.stmt.StreamType == ast.TypeTable && sInfo.stmt.Options.KIND == ast.StreamKindLookup {
 			if opt.Experiment != nil && opt.Experiment.UseSliceTuple {
 				return nil, nil, nil, fmt.Errorf("slice tuple mode do not support table yet %s", sInfo.stmt.Name)
@@ -615,8 +772,29 @@ func createLogicalPlanFull(stmt *ast.SelectStatement, opt *def.RuleOption, store
 				useSliceTuple:   opt.Experiment != nil && opt.Experiment.UseSliceTuple,
 			}.Init()
 			if sInfo.stmt.StreamType == ast.TypeStream {
+				if changelog {
+					cp := ChangelogPlan{
+						name:         sInfo.stmt.Name,
+						key:          sInfo.stmt.Options.KEY,
+						rowkindField: sInfo.stmt.Options.ROWKIND_FIELD,
+					}.Init()
+					cp.SetChildren([]LogicalPlan{p})
+					p = cp
+				}
 				children = append(children, p)
 				streamEmitters = append(streamEmitters, string(sInfo.stmt.Name))
+				if opt.IsEventTime {
+					st, err := watermarkStrategy(sInfo.stmt.Options, time.Duration(opt.LateTol))
+					if err != nil {
+						return nil, nil, nil, err
+					}
+					if st != nil {
+						if wmStrategies == nil {
+							wmStrategies = make(map[string]*node.WatermarkStrategy)
+						}
+						wmStrategies[string(sInfo.stmt.Name)] = st
+					}
+				}
 			} else {
 				scanTableChildren = append(scanTableChildren, p)
 				scanTableEmitters = append(scanTableEmitters, string(sInfo.stmt.Name))
@@ -636,13 +814,21 @@ func createLogicalPlanFull(stmt *ast.SelectStatement, opt *def.RuleOption, store
 		}
 	}
 	hasWindow := dimensions != nil && dimensions.GetWindow() != nil
-	if opt.IsEventTime {
+	// The query without window over a single windowed subquery runs on the results of each window of the subquery,
+	// so that it can aggregate them
+	inheritWindow := !hasWindow && len(streamStmts) == 1 && len(subqueryChildren) == 1 && subqueryChildren[0].windowed
+	if inheritWindow {
+		subqueryChildren[0].keepWindow = true
+	}
+	// The subquery has processed the event time, and the window results are not single tuples to track watermark
+	if opt.IsEventTime && !inheritWindow {
 		if opt.Experiment != nil && opt.Experiment.UseSliceTuple {
 			return nil, nil, nil, errors.New("slice tuple mode do not support event time yet")
 		}
 		p = WatermarkPlan{
 			SendWatermark: hasWindow,
 			Emitters:      streamEmitters,
+			Strategies:    wmStrategies,
 		}.Init()
 		p.SetChildren(children)
 		children = []LogicalPlan{p}
@@ -727,7 +913,14 @@ func createLogicalPlanFull(stmt *ast.SelectStatement, opt *def.RuleOption, store
 			}
 		}
 	}
-	if stmt.Joins != nil {
+	if stmt.Joins != nil && changelog {
+		p = ChangelogJoinPlan{
+			from: stmt.Sources[0].(*ast.Table),
+			join: stmt.Joins[0],
+		}.Init()
+		p.SetChildren(children)
+		children = []LogicalPlan{p}
+	} else if stmt.Joins != nil {
 		if opt.Experiment != nil && opt.Experiment.UseSliceTuple {
 			return nil, nil, nil, errors.New("slice tuple mode do not support join yet")
 		}
@@ -774,6 +967,10 @@ func createLogicalPlanFull(stmt *ast.SelectStatement, opt *def.RuleOption, store
 					if !lookupPlan.validateAndExtractCondition() {
 						return nil, nil, nil, fmt.Errorf("join condition %s is invalid, at least one equi-join predicate is required", join.Expr)
 					}
+					// The other conditions are evaluated after the lookup, which cannot decide the rows of semi and anti joins
+					if (join.JoinType == ast.SEMI_JOIN || join.JoinType == ast.ANTI_JOIN) && lookupPlan.conditions != nil {
+						return nil, nil, nil, fmt.Errorf("join condition %s is invalid, only equi-join predicates are supported by %s with lookup table", join.Expr, join.JoinType)
+					}
 					p = lookupPlan.Init()
 					p.SetChildren(children)
 					children = []LogicalPlan{p}
@@ -800,12 +997,42 @@ func createLogicalPlanFull(stmt *ast.SelectStatement, opt *def.RuleOption, store
 		p.SetChildren(children)
 		children = []LogicalPlan{p}
 	}
+	if stmt.DistinctOn != nil {
+		if opt.Experiment != nil && opt.Experiment.UseSliceTuple {
+			return nil, nil, nil, errors.New("slice tuple mode do not support DISTINCT ON yet")
+		}
+		if stmt.DistinctOn.Within == 0 && w == nil && !inheritWindow {
+			return nil, nil, nil, errors.New("DISTINCT ON requires WITHIN duration if there is no window")
+		}
+		p = DistinctOnPlan{
+			distinctOn: stmt.DistinctOn,
+		}.Init()
+		p.SetChildren(children)
+		children = []LogicalPlan{p}
+	}
+	if whereTopN != nil {
+		if opt.Experiment != nil && opt.Experiment.UseSliceTuple {
+			return nil, nil, nil, errors.New("slice tuple mode do not support Top-N yet")
+		}
+		p = whereTopN
+		p.SetChildren(children)
+		children = []LogicalPlan{p}
+	}
+	if changelog && (dimensions != nil || len(changelogAggFields) > 0) {
+		p = ChangelogAggregatePlan{
+			dimensions: dimensions.GetGroups(),
+			aggFields:  changelogAggFields,
+		}.Init()
+		p.SetChildren(children)
+		children = []LogicalPlan{p}
+	}
 	if dimensions != nil && len(rewriteRes.incAggFields) < 1 {
-		if w != nil && w.WindowType != ast.STATE_WINDOW {
+		if (w != nil && w.WindowType != ast.STATE_WINDOW) || inheritWindow {
 			ds = dimensions.GetGroups()
 			if len(ds) > 0 {
 				p = AggregatePlan{
 					dimensions: ds,
+					keepEmpty:  stmt.Fill != nil,
 				}.Init()
 				p.SetChildren(children)
 				children = []LogicalPlan{p}
@@ -823,6 +1050,14 @@ func createLogicalPlanFull(stmt *ast.SelectStatement, opt *def.RuleOption, store
 		p.SetChildren(children)
 		children = []LogicalPlan{p}
 	}
+	if havingTopN != nil {
+		if opt.Experiment != nil && opt.Experiment.UseSliceTuple {
+			return nil, nil, nil, errors.New("slice tuple mode do not support Top-N yet")
+		}
+		p = havingTopN
+		p.SetChildren(children)
+		children = []LogicalPlan{p}
+	}
 	if len(rewriteRes.windowFuncFields) > 0 {
 		for _, wf := range rewriteRes.windowFuncFields {
 			p = WindowFuncPlan{
@@ -907,6 +1142,14 @@ func createLogicalPlanFull(stmt *ast.SelectStatement, opt *def.RuleOption, store
 			enableLimit: enableLimit,
 			limitCount:  limitCount,
 		}.Init()
+		if changelog {
+			proj := p.(*ProjectPlan)
+			proj.rowkindField = opt.RowkindField
+			if proj.rowkindField == "" {
+				proj.rowkindField = DefaultRowkindField
+			}
+			proj.sendUpdateBefore = opt.SendUpdateBefore
+		}
 		// In slice-tuple mode, assign a dedicated SinkContent slot to each
 		// ExprField (a non-alias, non-FieldRef visible expression such as a
 		// bare CASE WHEN or arithmetic expression).  The slot sequence must
@@ -935,6 +1178,22 @@ func createLogicalPlanFull(stmt *ast.SelectStatement, opt *def.RuleOption, store
 		p.SetChildren(children)
 		children = []LogicalPlan{p}
 	}
+	if stmt.Fill != nil {
+		if len(rewriteRes.incAggFields) > 0 {
+			return nil, nil, nil, errors.New("FILL clause does not support incremental window yet")
+		}
+		if opt.Experiment != nil && opt.Experiment.UseSliceTuple {
+			return nil, nil, nil, errors.New("slice tuple mode do not support fill yet")
+		}
+		fp := FillPlan{
+			fill:    stmt.Fill,
+			sendNil: opt.SendNil,
+		}.Init()
+		fp.extractFields(stmt.Fields)
+		p = fp
+		p.SetChildren(children)
+		children = []LogicalPlan{p}
+	}
 
 	if len(srfMapping) > 0 {
 		if opt.Experiment != nil && opt.Experiment.UseSliceTuple {
@@ -958,6 +1217,54 @@ func createLogicalPlanFull(stmt *ast.SelectStatement, opt *def.RuleOption, store
 	return lp, analyticFuncs, analyticFieldFuncs, err
 }
 
+// createSubqueryPlan plans the subquery of the derived table as a chain of logical plans in the same topology
+func createSubqueryPlan(sInfo *streamInfo, opt *def.RuleOption, overrides streamOverrides, store kv.KeyValue, isTemp bool) (*SubqueryPlan, error) {
+	// The metadata is sent to the sink by the outer query only
+	subOpt := *opt
+	subOpt.SendMetaToSink = false
+	lp, _, _, err := createLogicalPlanFull(sInfo.subquery, &subOpt, overrides, store, isTemp)
+	if err != nil {
+		return nil, fmt.Errorf("subquery %s: %v", sInfo.stmt.Name, err)
+	}
+	sp := SubqueryPlan{
+		name: sInfo.stmt.Name,
+		// The results of the windows of a union come from different windows
+		windowed: sInfo.subquery.Dimensions.GetWindow() != nil && len(sInfo.subquery.Unions) == 0,
+		view:     sInfo.view,
+	}.Init()
+	sp.SetChildren([]LogicalPlan{lp})
+	return sp, nil
+}
+
+// createUnionPlan plans each select statement combined by UNION ALL separately in the same topology and merges
+// their results
+func createUnionPlan(stmt *ast.SelectStatement, opt *def.RuleOption, overrides streamOverrides, store kv.KeyValue, isTemp bool) (LogicalPlan, []*ast.Call, []*ast.Call, error) {
+	if opt.Experiment != nil && opt.Experiment.UseSliceTuple {
+		return nil, nil, nil, errors.New("slice tuple mode do not support union yet")
+	}
+	if err := validateUnion(stmt, opt, overrides); err != nil {
+		return nil, nil, nil, err
+	}
+	// The first select statement is planned without the unions
+	unions := stmt.Unions
+	stmt.Unions = nil
+	defer func() {
+		stmt.Unions = unions
+	}()
+	selects := append([]*ast.SelectStatement{stmt}, unions...)
+	children := make([]LogicalPlan, 0, len(selects))
+	for i, sel := range selects {
+		lp, _, _, err := createLogicalPlanFull(sel, opt, overrides, store, isTemp)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("select statement %d of UNION ALL: %v", i+1, err)
+		}
+		children = append(children, lp)
+	}
+	p := UnionPlan{}.Init()
+	p.SetChildren(children)
+	return p, nil, nil, nil
+}
+
 // extractSRFMapping extracts the set-returning-function in the field
 func extractSRFMapping(stmt *ast.SelectStatement) (map[string]struct{}, error) {
 	srfFuncCnt := 0
@@ -22,7 +22,6 @@ import (
 
 	"github.com/lf-edge/ekuiper/v2/internal/binder/function"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
-	"github.com/lf-edge/ekuiper/v2/internal/processor"
 	"github.com/lf-edge/ekuiper/v2/internal/topo"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/graph"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/node"
@@ -48,6 +47,10 @@ const (
 
 // PlanByGraph returns a topo.Topo object by a graph
 func PlanByGraph(rule *def.Rule) (*topo.Topo, error) {
+	return planByGraph(rule, nil)
+}
+
+func planByGraph(rule *def.Rule, overrides streamOverrides) (*topo.Topo, error) {
 	ruleGraph := rule.Graph
 	if ruleGraph == nil {
 		return nil, errors.New("no graph")
@@ -77,7 +80,7 @@ func PlanByGraph(rule *def.Rule) (*topo.Topo, error) {
 		if _, ok := ruleGraph.Topo.Edges[srcName]; !ok {
 			return nil, fmt.Errorf("no edge defined for source node %s", srcName)
 		}
-		srcNode, srcType, name, ops, err := parseSource(srcName, gn, rule, tp, store, lookupTableChildren, rule.Temp)
+		srcNode, srcType, name, ops, err := parseSource(srcName, gn, rule, overrides, tp, store, lookupTableChildren, rule.Temp)
 		if err != nil {
 			return nil, fmt.Errorf("parse source %s with %v error: %w", srcName, gn.Props, err)
 		}
@@ -129,7 +132,7 @@ func PlanByGraph(rule *def.Rule) (*topo.Topo, error) {
 				if err != nil {
 					return nil, fmt.Errorf("parse watermark %s with %v error: %w", nodeName, gn.Props, err)
 				}
-				op := node.NewWatermarkOp(nodeName, n.SendWatermark, n.Emitters, rule.Options)
+				op := node.NewWatermarkOp(nodeName, n.SendWatermark, n.Emitters, nil, rule.Options)
 				nodeMap[nodeName] = op
 			case "function":
 				fop, err := parseFunc(gn.Props, sourceNames)
@@ -443,7 +446,7 @@ func genNodesInOrder(toNodes []string, edges map[string][]interface{}, flatRever
 	return i
 }
 
-func parseSource(nodeName string, gn *def.GraphNode, rule *def.Rule, tp *topo.Topo, store kv.KeyValue, lookupTableChildren map[string]*ast.Options, isTemp bool) (node.DataSourceNode, sourceType, string, []node.OperatorNode, error) {
+func parseSource(nodeName string, gn *def.GraphNode, rule *def.Rule, overrides streamOverrides, tp *topo.Topo, store kv.KeyValue, lookupTableChildren map[string]*ast.Options, isTemp bool) (node.DataSourceNode, sourceType, string, []node.OperatorNode, error) {
 	sourceMeta := &def.SourceMeta{
 		SourceType: "stream",
 	}
@@ -456,9 +459,9 @@ func parseSource(nodeName string, gn *def.GraphNode, rule *def.Rule, tp *topo.To
 	}
 	// If source name is specified, find the created stream/table from store
 	if sourceMeta.SourceName != "" {
-		streamStmt, e := processor.GetStreamProcessorDataSource(sourceMeta.SourceName)
+		streamStmt, e := getStreamStmt(sourceMeta.SourceName, rule.Options, overrides)
 		if e != nil {
-			return nil, ILLEGAL, "", nil, fmt.Errorf("fail to get stream %s, please check if stream is created", sourceMeta.SourceName)
+			return nil, ILLEGAL, "", nil, e
 		}
 		// Validate temp streams can only be used by temp rules
 		if streamStmt.Options.Temp && !isTemp {
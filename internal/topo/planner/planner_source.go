@@ -33,6 +33,9 @@ import (
 	"github.com/lf-edge/ekuiper/v2/pkg/model"
 )
 
+// MockTypeProp is the mock source property to use a source type other than the simulator in rule test
+const MockTypeProp = "$$mockType"
+
 func transformSourceNode(ctx api.StreamContext, t *DataSourcePlan, mockSourcesProp map[string]map[string]any, ruleId string, options *def.RuleOption, index int) (node.DataSourceNode, []node.OperatorNode, int, error) {
 	isSchemaless := t.isSchemaless
 	isSliceMode := options.Experiment != nil && options.Experiment.UseSliceTuple
@@ -43,6 +46,9 @@ func transformSourceNode(ctx api.StreamContext, t *DataSourcePlan, mockSourcesPr
 	mockProps, isMock := mockSourcesProp[string(t.name)]
 	if isMock {
 		t.streamStmt.Options.TYPE = "simulator"
+		if mt, ok := mockProps[MockTypeProp].(string); ok && mt != "" {
+			t.streamStmt.Options.TYPE = mt
+		}
 		t.inRuleTest = true
 		t.name = "$$mock_" + t.name
 	}
@@ -0,0 +1,147 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"sync"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/topo"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/context"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/node"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/operator"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+var (
+	// viewLock makes sure a view subtopo is built by only one rule
+	viewLock sync.Mutex
+	// errViewNotBuilt stops creating a view subtopo before its nodes are built
+	errViewNotBuilt = errors.New("the view subtopo is not built")
+)
+
+// buildSharedView runs the plan of a view in a subtopo shared by all the rules selecting from the view. Only the
+// derived table conversion runs in the rule. It returns false if the view plan cannot be shared, then the view is
+// built in the rule like other derived tables.
+func buildSharedView(tp *topo.Topo, sp *SubqueryPlan, options *def.RuleOption, index int) (node.Emitter, int, bool, error) {
+	ds, chain := viewChain(sp.Children()[0])
+	if ds == nil {
+		return nil, 0, false, nil
+	}
+	ctx := tp.GetContext()
+	name := viewSubTopoName(sp.view, ds, chain)
+	viewLock.Lock()
+	defer viewLock.Unlock()
+	st, err := topo.GetOrCreateSubTopo(ctx, name, false, func(*topo.SrcSubTopo) error {
+		return errViewNotBuilt
+	})
+	if errors.Is(err, errViewNotBuilt) {
+		// The nodes are built outside the pool lock as the source of the view may be a shared subtopo too
+		var (
+			src node.DataSourceNode
+			ops []node.OperatorNode
+		)
+		src, ops, err = buildViewNodes(ctx.(*context.DefaultContext), name, ds, chain, options)
+		if err != nil {
+			return nil, 0, false, err
+		}
+		st, err = topo.GetOrCreateSubTopo(ctx, name, false, func(st *topo.SrcSubTopo) error {
+			st.AddSrc(src)
+			inputs := []node.Emitter{st}
+			for _, op := range ops {
+				st.AddOperator(inputs, op)
+				inputs = []node.Emitter{op}
+			}
+			return nil
+		})
+	}
+	if err != nil {
+		return nil, 0, false, err
+	}
+	tp.AddSrc(st)
+	st.StoreSchema(tp.GetName(), string(ds.name), ds.streamFields, ds.isWildCard)
+	index++
+	op := Transform(&operator.SubqueryOp{Emitter: string(sp.name), KeepWindow: sp.keepWindow}, fmt.Sprintf("%d_subquery_%s", index, sp.name), options)
+	tp.AddOperator([]node.Emitter{st}, op)
+	sp.setNodes([]node.TopNode{st, op})
+	return op, index, true, nil
+}
+
+// viewChain returns the source plan and the plans above it if the view plan is a linear chain which can be shared
+func viewChain(lp LogicalPlan) (*DataSourcePlan, []LogicalPlan) {
+	var chain []LogicalPlan
+	for {
+		switch t := lp.(type) {
+		case *DataSourcePlan:
+			if t.streamStmt.StreamType != ast.TypeStream {
+				return nil, nil
+			}
+			for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+				chain[i], chain[j] = chain[j], chain[i]
+			}
+			return t, chain
+		case *AnalyticFuncsPlan, *FilterPlan, *ProjectPlan:
+			chain = append(chain, lp)
+		default:
+			return nil, nil
+		}
+		if len(lp.Children()) != 1 {
+			return nil, nil
+		}
+		lp = lp.Children()[0]
+	}
+}
+
+// viewSubTopoName names the view subtopo by the view and its plan. A rule planned after the view is replaced will not
+// share the subtopo still running the old definition.
+func viewSubTopoName(view string, ds *DataSourcePlan, chain []LogicalPlan) string {
+	h := fnv.New32a()
+	for _, p := range append([]LogicalPlan{ds}, chain...) {
+		p.BuildExplainInfo()
+		_, _ = h.Write([]byte(p.Explain()))
+		if pp, ok := p.(*ProjectPlan); ok {
+			_, _ = h.Write([]byte(strconv.FormatBool(pp.sendNil)))
+		}
+	}
+	return fmt.Sprintf("$$view_%s_%x", view, h.Sum32())
+}
+
+// buildViewNodes builds the source and the operators of the view subtopo. The source is created in the context of
+// the subtopo so that its shared stream or connection is referred by the view subtopo instead of the rules.
+func buildViewNodes(ctx *context.DefaultContext, name string, ds *DataSourcePlan, chain []LogicalPlan, options *def.RuleOption) (node.DataSourceNode, []node.OperatorNode, error) {
+	subCtx := ctx.WithRuleId(fmt.Sprintf("$$subtopo_%s", name)).WithRun(0)
+	index := 1
+	src, ops, indexInc, err := transformSourceNode(subCtx, ds, nil, subCtx.GetRuleId(), options, index)
+	if err != nil {
+		return nil, nil, err
+	}
+	index += indexInc + len(ops) + 1
+	for _, p := range chain {
+		switch t := p.(type) {
+		case *AnalyticFuncsPlan:
+			ops = append(ops, newAnalyticFuncsOp(t, index, options))
+		case *FilterPlan:
+			ops = append(ops, newFilterOp(t, index, options))
+		case *ProjectPlan:
+			ops = append(ops, newProjectOp(t, index, options))
+		}
+		index++
+	}
+	return src, ops, nil
+}
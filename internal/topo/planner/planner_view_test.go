@@ -0,0 +1,118 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/io/memory/pubsub"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/processor"
+	"github.com/lf-edge/ekuiper/v2/internal/topo"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+func TestSharedView(t *testing.T) {
+	p := processor.NewStreamProcessor()
+	for _, sql := range []string{
+		`DROP VIEW sharedViewHot`,
+		`DROP STREAM sharedViewSrc`,
+	} {
+		_, _ = p.ExecStmt(sql)
+	}
+	for _, sql := range []string{
+		`CREATE STREAM sharedViewSrc (id BIGINT, temp FLOAT) WITH (DATASOURCE="sharedViewSrc", TYPE="memory", FORMAT="json", TIMESTAMP="id")`,
+		`CREATE VIEW sharedViewHot AS SELECT id, temp * 2 AS t2 FROM sharedViewSrc WHERE temp > 20`,
+	} {
+		_, err := p.ExecStmt(sql)
+		require.NoError(t, err)
+	}
+	t.Cleanup(func() {
+		_, _ = p.ExecStmt(`DROP VIEW sharedViewHot`)
+		_, _ = p.ExecStmt(`DROP STREAM sharedViewSrc`)
+	})
+
+	explain, err := GetExplainInfoFromLogicalPlan(&def.Rule{Id: "sharedViewExplain", Sql: `SELECT t2 FROM sharedViewHot`, Options: def.GetDefaultRule("", "").Options})
+	require.NoError(t, err)
+	assert.Contains(t, explain, "View:sharedViewHot")
+
+	sizeBefore := topo.GetSubTopoPoolSize()
+	newRule := func(id, sql string, opt func(*def.RuleOption)) *def.Rule {
+		r := def.GetDefaultRule(id, sql)
+		r.Actions = []map[string]any{{"memory": map[string]any{"topic": id, "sendSingle": true}}}
+		if opt != nil {
+			opt(r.Options)
+		}
+		return r
+	}
+	tp1, _, err := PlanSQLWithSourcesAndSinks(newRule("sharedViewRule1", `SELECT id, t2 FROM sharedViewHot WHERE t2 > 50`, nil), nil)
+	require.NoError(t, err)
+	tp2, _, err := PlanSQLWithSourcesAndSinks(newRule("sharedViewRule2", `SELECT v.id AS vid FROM sharedViewHot AS v`, nil), nil)
+	require.NoError(t, err)
+	// The event time rule is not shared
+	tp3, _, err := PlanSQLWithSourcesAndSinks(newRule("sharedViewRule3", `SELECT id FROM sharedViewHot`, func(o *def.RuleOption) { o.IsEventTime = true }), nil)
+	require.NoError(t, err)
+	require.Equal(t, sizeBefore+1, topo.GetSubTopoPoolSize())
+	st1, ok := tp1.GetSourceNodes()[0].(*topo.SrcSubTopo)
+	require.True(t, ok)
+	st2, ok := tp2.GetSourceNodes()[0].(*topo.SrcSubTopo)
+	require.True(t, ok)
+	assert.Same(t, st1, st2)
+	assert.Equal(t, 2, st1.RefCount())
+	assert.Equal(t, 2, st1.OpsCount())
+	_, ok = tp3.GetSourceNodes()[0].(*topo.SrcSubTopo)
+	assert.False(t, ok)
+	tp3.Cancel()
+
+	sub1 := pubsub.CreateSub("sharedViewRule1", nil, "sharedViewRule1", 10)
+	sub2 := pubsub.CreateSub("sharedViewRule2", nil, "sharedViewRule2", 10)
+	tp1.Open()
+	tp2.Open()
+	ctx := mockContext.NewMockContext("sharedViewTest", "op1")
+	// Wait for the source to subscribe
+	time.Sleep(200 * time.Millisecond)
+	for _, m := range []map[string]any{
+		{"id": int64(1), "temp": 10.0},
+		{"id": int64(2), "temp": 22.0},
+		{"id": int64(3), "temp": 30.0},
+	} {
+		pubsub.Produce(ctx, "sharedViewSrc", &xsql.Tuple{Message: m, Timestamp: timex.GetNow()})
+	}
+	receive := func(ch chan any, n int) []map[string]any {
+		var result []map[string]any
+		for len(result) < n {
+			select {
+			case r := <-ch:
+				result = append(result, r.(pubsub.MemTuple).ToMap())
+			case <-time.After(2 * time.Second):
+				return result
+			}
+		}
+		return result
+	}
+	assert.Equal(t, []map[string]any{{"id": int64(3), "t2": 60.0}}, receive(sub1, 1))
+	assert.Equal(t, []map[string]any{{"vid": int64(2)}, {"vid": int64(3)}}, receive(sub2, 2))
+
+	tp1.Cancel()
+	assert.Equal(t, 1, st1.RefCount())
+	tp2.Cancel()
+	assert.Equal(t, sizeBefore, topo.GetSubTopoPoolSize())
+}
@@ -38,6 +38,9 @@ type ProjectPlan struct {
 	exprIndices []int
 	enableLimit bool
 	limitCount  int
+	// rowkindField is the field of the row kind sent to the sinks in changelog mode
+	rowkindField     string
+	sendUpdateBefore bool
 }
 
 func (p ProjectPlan) Init() *ProjectPlan {
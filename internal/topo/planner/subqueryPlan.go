@@ -0,0 +1,58 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"strconv"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+// SubqueryPlan is the derived table of the outer query. Its child is the plan of the subquery which is optimized
+// separately, and its results are converted to the tuples of the derived table.
+type SubqueryPlan struct {
+	baseLogicalPlan
+	name ast.StreamName
+	// windowed indicates the subquery has a window, so that it outputs the results of each window
+	windowed bool
+	// keepWindow sends the results of each window as a whole, so that the outer query can aggregate them
+	keepWindow bool
+	// view is the name of the view which the derived table is expanded from. Its plan runs in a shared subtopo.
+	view string
+}
+
+func (p SubqueryPlan) Init() *SubqueryPlan {
+	p.baseLogicalPlan.self = &p
+	p.setPlanType(SUBQUERY)
+	return &p
+}
+
+func (p *SubqueryPlan) BuildExplainInfo() {
+	info := "Name:" + string(p.name) + ", Windowed:" + strconv.FormatBool(p.windowed) + ", KeepWindow:" + strconv.FormatBool(p.keepWindow)
+	if p.view != "" {
+		info += ", View:" + p.view
+	}
+	p.baseLogicalPlan.ExplainInfo.Info = info
+}
+
+// PushDownPredicate the conditions of the outer query are evaluated on the results of the subquery
+func (p *SubqueryPlan) PushDownPredicate(condition ast.Expr) (ast.Expr, LogicalPlan) {
+	return condition, p.self
+}
+
+// PruneColumns the columns of the subquery are pruned when planning the subquery
+func (p *SubqueryPlan) PruneColumns(_ []ast.Expr) error {
+	return nil
+}
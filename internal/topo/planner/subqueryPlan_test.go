@@ -0,0 +1,86 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+)
+
+func TestSubqueryPlan(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	require.NoError(t, prepareStream())
+
+	testcases := []struct {
+		sql     string
+		explain string
+		err     string
+	}{
+		{
+			sql: `WITH w AS (SELECT b, sum(a) AS s FROM stream GROUP BY b, TumblingWindow(ss, 10)) SELECT max(s) AS m FROM w`,
+			explain: `{"op":"ProjectPlan_0","info":"Fields:[ $$alias.m,aliasRef:Call:{ name:max, args:[w.s] } ]"}
+	{"op":"SubqueryPlan_1","info":"Name:w, Windowed:true, KeepWindow:true"}
+			{"op":"ProjectPlan_2","info":"Fields:[ $$alias.s,aliasRef:Call:{ name:sum, args:[stream.a] }, stream.b ]"}
+					{"op":"AggregatePlan_3","info":"Dimension:{ stream.b }"}
+							{"op":"WindowPlan_4","info":"{ length:10, windowType:TUMBLING_WINDOW, limit: 0 }"}
+									{"op":"DataSourcePlan_5","info":"StreamName: stream, StreamFields:[ a, b ]"}`,
+		},
+		{
+			sql: `SELECT a FROM (SELECT a FROM stream WHERE b > 1) t WHERE a > 2`,
+			explain: `{"op":"ProjectPlan_0","info":"Fields:[ t.a ]"}
+	{"op":"FilterPlan_1","info":"Condition:{ binaryExpr:{ t.a > 2 } }, "}
+			{"op":"SubqueryPlan_2","info":"Name:t, Windowed:false, KeepWindow:false"}
+					{"op":"ProjectPlan_3","info":"Fields:[ stream.a ]"}
+							{"op":"FilterPlan_4","info":"Condition:{ binaryExpr:{ stream.b > 1 } }, "}
+									{"op":"DataSourcePlan_5","info":"StreamName: stream, StreamFields:[ a, b ]"}`,
+		},
+		{
+			sql: `SELECT * FROM (SELECT a FROM stream) AS t INNER JOIN stream ON t.a = stream.a GROUP BY CountWindow(2)`,
+			err: "stream stream is referred more than once by the subqueries",
+		},
+		{
+			sql: `SELECT * FROM (SELECT a FROM stream) AS t INNER JOIN (SELECT b FROM sharedStream) AS t ON t.a = t.b GROUP BY CountWindow(2)`,
+			err: "derived table t is defined more than once",
+		},
+		{
+			sql: `SELECT * FROM (SELECT a FROM nonexist) AS t`,
+			err: "subquery t: fail to get stream nonexist, please check if stream is created",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.sql, func(t *testing.T) {
+			stmt, err := xsql.GetStatementFromSql(tc.sql)
+			require.NoError(t, err)
+			p, err := CreateLogicalPlan(stmt, &def.RuleOption{
+				PlanOptimizeStrategy: &def.PlanOptimizeStrategy{},
+				Qos:                  0,
+			}, kv)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			explain, err := ExplainFromLogicalPlan(p, "")
+			require.NoError(t, err)
+			require.Equal(t, tc.explain, explain)
+		})
+	}
+}
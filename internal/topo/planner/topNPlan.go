@@ -0,0 +1,130 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+// DefaultTopNStateTTL is the retention of the top rows of a stream if the rule option stateTtl is not set,
+// so that the state of the partitions which stop receiving rows is released
+const DefaultTopNStateTTL = 24 * time.Hour
+
+// TopNPlan keeps the first n rows of each partition for the condition `ROW_NUMBER() OVER (...) <= n`
+type TopNPlan struct {
+	baseLogicalPlan
+	partition  *ast.PartitionExpr
+	sortFields ast.SortFields
+	n          int
+	// rankName is the field to set the row number if it is selected
+	rankName string
+	// ttl is the milliseconds to retain the top rows of a stream
+	ttl int64
+}
+
+func (p TopNPlan) Init() *TopNPlan {
+	p.baseLogicalPlan.self = &p
+	p.baseLogicalPlan.setPlanType(TOPN)
+	return &p
+}
+
+func (p *TopNPlan) BuildExplainInfo() {
+	info := "N:" + strconv.Itoa(p.n)
+	if p.partition != nil {
+		info += ", " + p.partition.String()
+	}
+	info += ", SortFields:[ "
+	for i, field := range p.sortFields {
+		info += field.String()
+		if i != len(p.sortFields)-1 {
+			info += ", "
+		}
+	}
+	info += " ]"
+	if p.ttl > 0 {
+		info += ", TTL:" + strconv.FormatInt(p.ttl, 10)
+	}
+	p.baseLogicalPlan.ExplainInfo.Info = info
+}
+
+// PushDownPredicate the conditions after Top-N cannot be evaluated before the rows are ranked
+func (p *TopNPlan) PushDownPredicate(condition ast.Expr) (ast.Expr, LogicalPlan) {
+	_, _ = p.baseLogicalPlan.PushDownPredicate(nil)
+	return condition, p.self
+}
+
+func (p *TopNPlan) PruneColumns(fields []ast.Expr) error {
+	f := getFields(p.sortFields)
+	if p.partition != nil {
+		for _, expr := range p.partition.Exprs {
+			f = append(f, getFields(expr)...)
+		}
+	}
+	return p.baseLogicalPlan.PruneColumns(append(fields, f...))
+}
+
+// extractTopN takes the Top-N conditions out of the WHERE and HAVING clauses. The row_number with the same OVER
+// clause in the select fields reads the rank of the Top-N plan instead of being calculated by the window function.
+func extractTopN(stmt *ast.SelectStatement, opt *def.RuleOption) (*TopNPlan, *TopNPlan, error) {
+	var plans [2]*TopNPlan
+	for i, cond := range []*ast.Expr{&stmt.Condition, &stmt.Having} {
+		tn, rest, err := xsql.ExtractTopN(*cond)
+		if err != nil {
+			return nil, nil, err
+		}
+		if tn == nil {
+			continue
+		}
+		*cond = rest
+		ttl := time.Duration(opt.StateTTL)
+		if ttl == 0 {
+			ttl = DefaultTopNStateTTL
+		}
+		p := TopNPlan{
+			partition:  tn.Call.Partition,
+			sortFields: tn.Call.SortFields,
+			n:          int(tn.N),
+			ttl:        ttl.Milliseconds(),
+		}.Init()
+		over := overString(tn.Call)
+		name := fmt.Sprintf("$$topn_rank_%d", i)
+		ast.WalkFunc(stmt.Fields, func(n ast.Node) bool {
+			if c, ok := n.(*ast.Call); ok && c.FuncType == ast.FuncTypeWindow && c.Name == tn.Call.Name && overString(c) == over {
+				rewriteIntoBypass(&ast.FieldRef{StreamName: ast.DefaultStream, Name: name}, c)
+				p.rankName = name
+			}
+			return true
+		})
+		plans[i] = p
+	}
+	return plans[0], plans[1], nil
+}
+
+func overString(c *ast.Call) string {
+	s := ""
+	if c.Partition != nil {
+		s = c.Partition.String()
+	}
+	for _, f := range c.SortFields {
+		s += ";" + f.String()
+	}
+	return s
+}
@@ -0,0 +1,47 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"strconv"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+// UnionPlan merges the results of the select statements combined by UNION ALL. Each child is the plan of a
+// select statement which is optimized separately.
+type UnionPlan struct {
+	baseLogicalPlan
+}
+
+func (p UnionPlan) Init() *UnionPlan {
+	p.baseLogicalPlan.self = &p
+	p.setPlanType(UNION)
+	return &p
+}
+
+func (p *UnionPlan) BuildExplainInfo() {
+	p.baseLogicalPlan.ExplainInfo.Info = "Inputs:" + strconv.Itoa(len(p.children))
+}
+
+// PushDownPredicate the conditions are not pushed into the select statements
+func (p *UnionPlan) PushDownPredicate(condition ast.Expr) (ast.Expr, LogicalPlan) {
+	return condition, p.self
+}
+
+// PruneColumns the columns of each select statement are pruned when planning it
+func (p *UnionPlan) PruneColumns(_ []ast.Expr) error {
+	return nil
+}
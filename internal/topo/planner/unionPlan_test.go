@@ -0,0 +1,115 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+func TestUnionPlan(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	streamSqls := map[string]string{
+		"site1": `CREATE STREAM site1 (id BIGINT, temp BIGINT, loc STRING) WITH (DATASOURCE="site1");`,
+		"site2": `CREATE STREAM site2 (id BIGINT, temp FLOAT, loc STRING) WITH (DATASOURCE="site2");`,
+		"site3": `CREATE STREAM site3 (id STRING, temp FLOAT, loc STRING) WITH (DATASOURCE="site3");`,
+		"site4": `CREATE STREAM site4 () WITH (DATASOURCE="site4");`,
+	}
+	for name, sql := range streamSqls {
+		s, err := json.Marshal(&xsql.StreamInfo{StreamType: ast.TypeStream, Statement: sql})
+		require.NoError(t, err)
+		require.NoError(t, kv.Set(name, string(s)))
+	}
+
+	testcases := []struct {
+		sql      string
+		children []PlanType
+		err      string
+	}{
+		{
+			sql:      `SELECT * FROM site1 UNION ALL SELECT * FROM site2 UNION ALL SELECT id, temp, loc FROM site4`,
+			children: []PlanType{PROJECT, PROJECT, PROJECT},
+		},
+		{
+			sql:      `SELECT id, temp FROM site1 WHERE temp > 30 UNION ALL SELECT * FROM site4`,
+			children: []PlanType{PROJECT, PROJECT},
+		},
+		{
+			sql:      `SELECT avg(temp) AS temp, 'site1' AS loc FROM site1 GROUP BY TumblingWindow(ss, 10) UNION ALL SELECT temp, loc FROM site2`,
+			children: []PlanType{PROJECT, PROJECT},
+		},
+		{
+			sql: `SELECT id FROM site1 UNION ALL SELECT id, temp FROM site2`,
+			err: "select statement 2 of UNION ALL has 2 columns but select statement 1 has 1",
+		},
+		{
+			sql: `SELECT id, temp FROM site1 UNION ALL SELECT id, loc FROM site2`,
+			err: "column loc of select statement 2 of UNION ALL is not found in select statement 1",
+		},
+		{
+			sql: `SELECT * FROM site4 UNION ALL SELECT id FROM site1 UNION ALL SELECT * FROM site3`,
+			err: "select statement 3 of UNION ALL has 3 columns but select statement 2 has 1",
+		},
+		{
+			sql: `SELECT * FROM site1 UNION ALL SELECT * FROM site3`,
+			err: "column id of select statement 2 of UNION ALL is string but it is bigint in select statement 1",
+		},
+		{
+			sql: `SELECT loc FROM site1 UNION ALL SELECT 1 AS loc FROM site2`,
+			err: "column loc of select statement 2 of UNION ALL is bigint but it is string in select statement 1",
+		},
+		{
+			sql: `SELECT id FROM site1 UNION ALL SELECT id FROM site1`,
+			err: "stream site1 is referred more than once by UNION ALL",
+		},
+		{
+			sql: `SELECT id FROM site1 UNION ALL SELECT id FROM nonexist`,
+			err: "fail to get stream nonexist, please check if stream is created",
+		},
+		{
+			sql: `SELECT id FROM site1 UNION ALL SELECT a AS id FROM site2`,
+			err: "select statement 2 of UNION ALL: unknown field a",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.sql, func(t *testing.T) {
+			stmt, err := xsql.GetStatementFromSql(tc.sql)
+			require.NoError(t, err)
+			p, err := CreateLogicalPlan(stmt, &def.RuleOption{
+				PlanOptimizeStrategy: &def.PlanOptimizeStrategy{},
+				Qos:                  0,
+			}, kv)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.IsType(t, &UnionPlan{}, p)
+			require.Len(t, p.Children(), len(tc.children))
+			for i, c := range p.Children() {
+				require.Equal(t, string(tc.children[i]), c.Type())
+			}
+			require.Len(t, stmt.Unions, len(tc.children)-1)
+		})
+	}
+}
@@ -15,8 +15,12 @@
 package planner
 
 import (
+	"fmt"
+	"sort"
 	"strconv"
+	"time"
 
+	"github.com/lf-edge/ekuiper/v2/internal/topo/node"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
 )
 
@@ -24,6 +28,8 @@ type WatermarkPlan struct {
 	baseLogicalPlan
 	Emitters      []string
 	SendWatermark bool
+	// Strategies are the watermark strategies of the streams which set them in the stream options
+	Strategies map[string]*node.WatermarkStrategy
 }
 
 func (p WatermarkPlan) Init() *WatermarkPlan {
@@ -45,9 +51,55 @@ func (p *WatermarkPlan) BuildExplainInfo() {
 		info += " ], "
 	}
 	info += "SendWatermark:" + strconv.FormatBool(p.SendWatermark)
+	if len(p.Strategies) > 0 {
+		names := make([]string, 0, len(p.Strategies))
+		for name := range p.Strategies {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		info += ", Strategies:[ "
+		for i, name := range names {
+			st := p.Strategies[name]
+			info += fmt.Sprintf("%s:{ delay:%s", name, st.Delay)
+			if st.IdleTimeout > 0 {
+				info += fmt.Sprintf(", idleTimeout:%s", st.IdleTimeout)
+			}
+			if st.Key != "" {
+				info += ", key:" + st.Key
+			}
+			info += " }"
+			if i != len(names)-1 {
+				info += ", "
+			}
+		}
+		info += " ]"
+	}
 	p.baseLogicalPlan.ExplainInfo.Info = info
 }
 
+// watermarkStrategy returns the watermark strategy in the stream options, or nil if the stream just uses the rule lateTolerance
+func watermarkStrategy(opts *ast.Options, lateTol time.Duration) (*node.WatermarkStrategy, error) {
+	if opts == nil || (opts.WATERMARK_DELAY == "" && opts.IDLE_TIMEOUT == "" && opts.WATERMARK_KEY == "") {
+		return nil, nil
+	}
+	st := &node.WatermarkStrategy{Delay: lateTol, Key: opts.WATERMARK_KEY}
+	if opts.WATERMARK_DELAY != "" {
+		d, err := time.ParseDuration(opts.WATERMARK_DELAY)
+		if err != nil {
+			return nil, fmt.Errorf("invalid watermarkDelay %s: %v", opts.WATERMARK_DELAY, err)
+		}
+		st.Delay = d
+	}
+	if opts.IDLE_TIMEOUT != "" {
+		d, err := time.ParseDuration(opts.IDLE_TIMEOUT)
+		if err != nil {
+			return nil, fmt.Errorf("invalid idleTimeout %s: %v", opts.IDLE_TIMEOUT, err)
+		}
+		st.IdleTimeout = d
+	}
+	return st, nil
+}
+
 // PushDownPredicate watermark plan can not push down predicate. It must receive all tuples to process watermark
 func (p *WatermarkPlan) PushDownPredicate(condition ast.Expr) (ast.Expr, LogicalPlan) {
 	if condition != nil {
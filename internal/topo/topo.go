@@ -21,6 +21,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime/debug"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -72,6 +73,7 @@ type Topo struct {
 	sinkSchema   map[string]*ast.JsonStreamField
 	opsWg        *sync.WaitGroup
 	spawnDone    chan struct{}
+	plan         *PlanNode
 	// all other things are read only during lifecycle except state
 	state atomic.Value
 }
@@ -244,6 +246,42 @@ func (s *Topo) GetOperators() []node.OperatorNode {
 	return s.ops
 }
 
+// GetProgress returns the total count of the messages processed by all the nodes and whether all the received messages
+// are processed with nothing left in the buffers. The nodes are checked from the sources to the sinks, so a message in
+// flight is always found except the one just taken from a buffer and not counted yet. Thus, check it twice and compare
+// the counts to make sure the topo is idle.
+func (s *Topo) GetProgress() (int64, bool) {
+	var total int64
+	idle := true
+	check := func(metrics []any) {
+		if len(metrics) <= 2 {
+			return
+		}
+		in, _ := metrics[0].(int64)
+		processed, _ := metrics[2].(int64)
+		total += processed
+		if in != processed {
+			idle = false
+		}
+	}
+	for _, src := range s.sources {
+		check(src.GetMetrics())
+	}
+	for _, op := range s.ops {
+		if ch, _ := op.GetInput(); len(ch) > 0 {
+			idle = false
+		}
+		check(op.GetMetrics())
+	}
+	for _, snk := range s.sinks {
+		if ch, _ := snk.GetInput(); len(ch) > 0 {
+			idle = false
+		}
+		check(snk.GetMetrics())
+	}
+	return total, idle
+}
+
 func (s *Topo) SetSinkSchema(sinkSchema map[string]*ast.JsonStreamField) {
 	s.sinkSchema = sinkSchema
 }
@@ -451,6 +489,10 @@ func (s *Topo) GetMetricsV2() map[string]map[string]any {
 			value := v
 			operatorMetrics[key] = value
 		}
+		wkeys, wvalues := watermarkMetrics(so)
+		for i, key := range wkeys {
+			operatorMetrics[key] = wvalues[i]
+		}
 		allMetrics[so.GetName()] = operatorMetrics
 	}
 	for _, sn := range s.sinks {
@@ -484,6 +526,9 @@ func (s *Topo) GetMetrics() (keys []string, values []any) {
 			keys = append(keys, "op_"+so.GetName()+"_0_"+metric.MetricNames[i])
 			values = append(values, v)
 		}
+		wkeys, wvalues := watermarkMetrics(so)
+		keys = append(keys, wkeys...)
+		values = append(values, wvalues...)
 	}
 	for _, sn := range s.sinks {
 		for i, v := range sn.GetMetrics() {
@@ -494,6 +539,25 @@ func (s *Topo) GetMetrics() (keys []string, values []any) {
 	return
 }
 
+// watermarkMetrics returns the current watermark in milliseconds of each input of the watermark operator
+func watermarkMetrics(op node.OperatorNode) (keys []string, values []any) {
+	wo, ok := op.(*node.WatermarkOp)
+	if !ok {
+		return nil, nil
+	}
+	wms := wo.GetWatermarks()
+	inputs := make([]string, 0, len(wms))
+	for input := range wms {
+		inputs = append(inputs, input)
+	}
+	sort.Strings(inputs)
+	for _, input := range inputs {
+		keys = append(keys, "op_"+op.GetName()+"_0_watermark_"+input)
+		values = append(values, wms[input])
+	}
+	return keys, values
+}
+
 func (s *Topo) RemoveMetrics() {
 	conf.Log.Infof("start removing %v metrics", s.name)
 	for _, sn := range s.sources {
@@ -0,0 +1,87 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topotest
+
+import (
+	"testing"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+)
+
+func TestSubquery(t *testing.T) {
+	// Reset
+	streamList := []string{"demo", "demo1"}
+	HandleStream(false, streamList, t)
+	tests := []RuleTest{
+		{
+			Name: `TestSubqueryRule1`,
+			Sql:  `WITH a AS (SELECT color, size * 2 AS s FROM demo WHERE size > 2) SELECT color, s FROM a WHERE s > 7`,
+			R: [][]map[string]interface{}{
+				{{"color": "blue", "s": int64(12)}},
+				{{"color": "yellow", "s": int64(8)}},
+			},
+			M: map[string]interface{}{
+				"source_demo_0_records_in_total":  int64(5),
+				"source_demo_0_records_out_total": int64(5),
+
+				"op_2_filter_0_records_in_total":  int64(5),
+				"op_2_filter_0_records_out_total": int64(3),
+
+				"op_4_subquery_a_0_exceptions_total":  int64(0),
+				"op_4_subquery_a_0_records_in_total":  int64(3),
+				"op_4_subquery_a_0_records_out_total": int64(3),
+
+				"op_5_filter_0_records_in_total":  int64(3),
+				"op_5_filter_0_records_out_total": int64(2),
+
+				"sink_memory_0_0_records_in_total": int64(2),
+			},
+		},
+		{
+			Name: `TestSubqueryRule2`,
+			Sql:  `SELECT max(total) AS m, count(*) AS c FROM (SELECT color, sum(size) AS total FROM demo GROUP BY color, TumblingWindow(ss, 1)) AS t`,
+			R: [][]map[string]interface{}{
+				{{"m": int64(6), "c": 2}},
+				{{"m": int64(2), "c": 1}},
+				{{"m": int64(4), "c": 1}},
+			},
+			M: map[string]interface{}{},
+		},
+		{
+			Name: `TestSubqueryRule3`,
+			Sql:  `SELECT count(*) AS c FROM (SELECT size FROM demo WHERE size > 1) t GROUP BY TumblingWindow(ss, 1)`,
+			R: [][]map[string]interface{}{
+				{{"c": 2}},
+				{{"c": 1}},
+				{{"c": 1}},
+			},
+			M: map[string]interface{}{},
+		},
+		{
+			Name: `TestSubqueryRule4`,
+			Sql:  `SELECT t.color, temp FROM (SELECT color, ts FROM demo WHERE size > 2) AS t INNER JOIN demo1 ON t.ts = demo1.ts GROUP BY TumblingWindow(ss, 1)`,
+			R: [][]map[string]interface{}{
+				{{"color": "red", "temp": 25.5}},
+				{{"color": "yellow", "temp": 27.4}},
+			},
+			M: map[string]interface{}{},
+		},
+	}
+	HandleStream(true, streamList, t)
+	DoRuleTest(t, tests, &def.RuleOption{
+		BufferLength: 100,
+		SendError:    true,
+	}, 0)
+}
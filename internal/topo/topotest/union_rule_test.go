@@ -0,0 +1,66 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topotest
+
+import (
+	"testing"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+)
+
+func TestUnion(t *testing.T) {
+	// Reset
+	streamList := []string{"demo", "demo1"}
+	HandleStream(false, streamList, t)
+	tests := []RuleTest{
+		{
+			Name: `TestUnionRule1`,
+			Sql:  "SELECT color AS name, size AS v FROM demo WHERE size > 5 UNION ALL SELECT `from` AS name, hum AS v FROM demo1 WHERE hum > 70",
+			R: [][]map[string]interface{}{
+				{{"name": "blue", "v": 6}},
+				{{"name": "device3", "v": 75}},
+				{{"name": "device1", "v": 80}},
+			},
+			M: map[string]interface{}{
+				"source_demo_0_records_in_total":  int64(5),
+				"source_demo_0_records_out_total": int64(5),
+
+				"source_demo1_0_records_in_total":  int64(5),
+				"source_demo1_0_records_out_total": int64(5),
+
+				"op_7_union_0_exceptions_total":  int64(0),
+				"op_7_union_0_records_in_total":  int64(3),
+				"op_7_union_0_records_out_total": int64(3),
+
+				"sink_memory_0_0_records_in_total": int64(3),
+			},
+		},
+		{
+			Name: `TestUnionRule2`,
+			Sql:  `SELECT count(*) AS c, max(v) AS m FROM (SELECT size AS v FROM demo UNION ALL SELECT hum AS v FROM demo1) AS u GROUP BY TumblingWindow(ss, 1)`,
+			R: [][]map[string]interface{}{
+				{{"c": 4, "m": int64(65)}},
+				{{"c": 2, "m": int64(75)}},
+				{{"c": 2, "m": int64(80)}},
+			},
+			M: map[string]interface{}{},
+		},
+	}
+	HandleStream(true, streamList, t)
+	DoRuleTest(t, tests, &def.RuleOption{
+		BufferLength: 100,
+		SendError:    true,
+	}, 0)
+}
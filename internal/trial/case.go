@@ -0,0 +1,376 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trial
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+
+	"github.com/lf-edge/ekuiper/v2/internal/io/memory/pubsub"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/processor"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/planner"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+)
+
+const (
+	defaultCaseTimeout = 30 * time.Second
+	caseSinkBuffer     = 1024
+)
+
+// TestCase is a declarative test of a rule. The input events are sent to the streams of the rule in the order of the
+// timestamps, and the outputs of each sink are compared with the expected ones.
+type TestCase struct {
+	Name string          `json:"name"`
+	Rule json.RawMessage `json:"rule"`
+	// Inputs are the events of each stream
+	Inputs map[string][]*Event `json:"inputs"`
+	// Expected are the outputs of each sink. The sink is named as type_index of the rule actions such as memory_0.
+	// Each output is a list of rows or a single row.
+	Expected map[string][]any `json:"expected"`
+	// Timeout is the max time to run the case
+	Timeout cast.DurationConf `json:"timeout,omitempty"`
+}
+
+// Event is an input event. The timestamp in milliseconds is the timestamp of the tuple and decides the order.
+type Event struct {
+	Timestamp int64          `json:"timestamp"`
+	Data      map[string]any `json:"data"`
+}
+
+type CaseResult struct {
+	Name  string        `json:"name"`
+	Pass  bool          `json:"pass"`
+	Sinks []*SinkResult `json:"sinks"`
+}
+
+type SinkResult struct {
+	Name     string   `json:"name"`
+	Pass     bool     `json:"pass"`
+	Expected []any    `json:"expected,omitempty"`
+	Actual   []any    `json:"actual"`
+	Diff     []string `json:"diff,omitempty"`
+}
+
+// String prints the result as a report
+func (r *CaseResult) String() string {
+	b := &strings.Builder{}
+	status := "PASS"
+	if !r.Pass {
+		status = "FAIL"
+	}
+	fmt.Fprintf(b, "%s: rule test %s", status, r.Name)
+	for _, s := range r.Sinks {
+		if s.Pass {
+			continue
+		}
+		fmt.Fprintf(b, "\n  sink %s:", s.Name)
+		for _, d := range s.Diff {
+			fmt.Fprintf(b, "\n    %s", d)
+		}
+	}
+	return b.String()
+}
+
+// RunCase parses the test case and runs it
+func RunCase(caseJson string) (*CaseResult, error) {
+	tc := &TestCase{}
+	if err := json.Unmarshal([]byte(caseJson), tc); err != nil {
+		return nil, fmt.Errorf("fail to parse test case: %v", err)
+	}
+	return tc.Run()
+}
+
+// RunCaseYaml runs the test case in yaml format. A json test case is also a valid yaml.
+func RunCaseYaml(content string) (*CaseResult, error) {
+	var tc any
+	if err := yaml.Unmarshal([]byte(content), &tc); err != nil {
+		return nil, fmt.Errorf("fail to parse test case: %v", err)
+	}
+	caseJson, err := json.Marshal(tc)
+	if err != nil {
+		return nil, fmt.Errorf("fail to parse test case: %v", err)
+	}
+	return RunCase(string(caseJson))
+}
+
+// Run runs the rule of the test case with the inputs and compares the outputs. The rule is run as a temporary rule
+// with its sinks replaced by memory sinks and the streams replaced by the inputs.
+func (tc *TestCase) Run() (*CaseResult, error) {
+	if len(tc.Rule) == 0 {
+		return nil, errors.New("rule is required in the test case")
+	}
+	rule, err := processor.NewRuleProcessor().GetRuleByJsonValidated("", string(tc.Rule))
+	if err != nil {
+		return nil, err
+	}
+	if rule.Sql == "" {
+		return nil, errors.New("only the rule with sql is supported in the test case")
+	}
+	stmt, err := processor.GetStreamProcessorStatement(rule.Sql)
+	if err != nil {
+		return nil, err
+	}
+	if w := stmt.Dimensions.GetWindow(); w != nil && w.WindowType != ast.COUNT_WINDOW && !rule.Options.IsEventTime {
+		return nil, errors.New("the time window of processing time is not deterministic, set the isEventTime option of the rule and the TIMESTAMP of the streams")
+	}
+	runId := "$$ruletest_" + uuid.New().String()
+	rule.Id = runId
+	rule.Temp = true
+	// Checkpoint is not used in the test
+	rule.Options.Qos = def.AtMostOnce
+	actions := rule.Actions
+	if len(actions) == 0 {
+		actions = []map[string]any{{"memory": map[string]any{}}}
+	}
+	sinks := make([]string, 0, len(actions))
+	rule.Actions = make([]map[string]any, 0, len(actions))
+	for i, action := range actions {
+		for typ, props := range action {
+			name := fmt.Sprintf("%s_%d", typ, i)
+			sinkProps := make(map[string]any)
+			if m, ok := props.(map[string]any); ok {
+				for k, v := range m {
+					sinkProps[k] = v
+				}
+			}
+			sinkProps["topic"] = runId + "/" + name
+			rule.Actions = append(rule.Actions, map[string]any{"memory": sinkProps})
+			sinks = append(sinks, name)
+		}
+	}
+	for name := range tc.Expected {
+		if !slices.Contains(sinks, name) {
+			return nil, fmt.Errorf("sink %s not found in the rule, the sinks are %s", name, strings.Join(sinks, ", "))
+		}
+	}
+	streams := xsql.GetStreams(stmt)
+	for name := range tc.Inputs {
+		if !slices.Contains(streams, name) {
+			return nil, fmt.Errorf("stream %s not found in the rule, the streams are %s", name, strings.Join(streams, ", "))
+		}
+	}
+	mock := make(map[string]map[string]any, len(streams))
+	for _, s := range streams {
+		mock[s] = map[string]any{planner.MockTypeProp: caseSourceType, "run": runId, "stream": s}
+	}
+	timeout := time.Duration(tc.Timeout)
+	if timeout <= 0 {
+		timeout = defaultCaseTimeout
+	}
+	collectors := make([]*sinkCollector, len(sinks))
+	for i, name := range sinks {
+		collectors[i] = newSinkCollector(runId, name)
+	}
+	defer func() {
+		for _, c := range collectors {
+			c.close()
+		}
+	}()
+	tp, _, err := planner.PlanSQLWithSourcesAndSinks(rule, mock)
+	if err != nil {
+		return nil, fmt.Errorf("fail to plan the rule: %v", err)
+	}
+	defer tp.Cancel()
+	f := newFeeder(tc.events(), tp.GetProgress)
+	feeders.Store(runId, f)
+	defer feeders.Delete(runId)
+	errCh := tp.Open()
+	feedErr := make(chan error, 1)
+	go func() {
+		feedErr <- f.feed(tp.GetContext(), timeout)
+	}()
+	// Each sink sends an EOF after processing all the data
+	eofs := 0
+	deadline := time.After(timeout)
+	for eofs < len(sinks) {
+		select {
+		case err = <-errCh:
+			if err == nil {
+				return nil, errors.New("rule is stopped before all the inputs are processed")
+			}
+			if !errorx.IsEOF(err) {
+				return nil, fmt.Errorf("rule run error: %v", err)
+			}
+			eofs++
+		case err = <-feedErr:
+			if err != nil {
+				return nil, err
+			}
+			feedErr = nil
+		case <-deadline:
+			return nil, fmt.Errorf("test case timeout after %v", timeout)
+		}
+	}
+
+	result := &CaseResult{Name: tc.Name, Pass: true}
+	for i, name := range sinks {
+		actual := collectors[i].stop()
+		sr := &SinkResult{Name: name, Pass: true, Actual: actual}
+		if expected, ok := tc.Expected[name]; ok {
+			sr.Expected = normalizeOutputs(expected)
+			sr.Diff = diffOutputs(sr.Expected, actual)
+			sr.Pass = len(sr.Diff) == 0
+		}
+		result.Pass = result.Pass && sr.Pass
+		result.Sinks = append(result.Sinks, sr)
+	}
+	return result, nil
+}
+
+// events returns the events of all streams sorted by the timestamp. The events of the same timestamp are in the
+// order of the stream names and their order in the inputs.
+func (tc *TestCase) events() []*caseEvent {
+	names := make([]string, 0, len(tc.Inputs))
+	for name := range tc.Inputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var events []*caseEvent
+	for _, name := range names {
+		for _, e := range tc.Inputs[name] {
+			if e != nil {
+				events = append(events, &caseEvent{stream: name, Event: e})
+			}
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp < events[j].Timestamp
+	})
+	return events
+}
+
+// sinkCollector receives the outputs of a memory sink
+type sinkCollector struct {
+	topic   string
+	subId   string
+	ch      chan any
+	done    chan struct{}
+	wg      sync.WaitGroup
+	outputs []any
+}
+
+func newSinkCollector(runId, name string) *sinkCollector {
+	c := &sinkCollector{
+		topic: runId + "/" + name,
+		subId: runId + "_" + name,
+		done:  make(chan struct{}),
+	}
+	c.ch = pubsub.CreateSub(c.topic, nil, c.subId, caseSinkBuffer)
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		for {
+			select {
+			case v := <-c.ch:
+				c.outputs = append(c.outputs, v)
+			case <-c.done:
+				for {
+					select {
+					case v := <-c.ch:
+						c.outputs = append(c.outputs, v)
+					default:
+						return
+					}
+				}
+			}
+		}
+	}()
+	return c
+}
+
+// stop stops receiving and returns the outputs converted to json values
+func (c *sinkCollector) stop() []any {
+	select {
+	case <-c.done:
+	default:
+		close(c.done)
+	}
+	c.wg.Wait()
+	result := make([]any, 0, len(c.outputs))
+	for _, o := range c.outputs {
+		switch t := o.(type) {
+		case pubsub.MemTuple:
+			result = append(result, []any{t.ToMap()})
+		case []pubsub.MemTuple:
+			rows := make([]any, 0, len(t))
+			for _, r := range t {
+				rows = append(rows, r.ToMap())
+			}
+			result = append(result, rows)
+		default:
+			result = append(result, []any{t})
+		}
+	}
+	return normalizeOutputs(result)
+}
+
+func (c *sinkCollector) close() {
+	c.stop()
+	pubsub.CloseSourceConsumerChannel(c.topic, c.subId)
+}
+
+// normalizeOutputs converts the outputs to json values so that the numbers of different types can be compared.
+// A single row output is converted to a list of one row.
+func normalizeOutputs(outputs []any) []any {
+	result := make([]any, 0, len(outputs))
+	for _, o := range outputs {
+		var v any
+		if b, err := json.Marshal(o); err == nil && json.Unmarshal(b, &v) == nil {
+			o = v
+		}
+		if m, ok := o.(map[string]any); ok {
+			o = []any{m}
+		}
+		result = append(result, o)
+	}
+	return result
+}
+
+func diffOutputs(expected, actual []any) []string {
+	var diff []string
+	for i := 0; i < len(expected) || i < len(actual); i++ {
+		switch {
+		case i >= len(actual):
+			diff = append(diff, fmt.Sprintf("output #%d: expected %s, got nothing", i+1, toJson(expected[i])))
+		case i >= len(expected):
+			diff = append(diff, fmt.Sprintf("output #%d: unexpected %s", i+1, toJson(actual[i])))
+		case !reflect.DeepEqual(expected[i], actual[i]):
+			diff = append(diff, fmt.Sprintf("output #%d: expected %s, got %s", i+1, toJson(expected[i]), toJson(actual[i])))
+		}
+	}
+	return diff
+}
+
+func toJson(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
@@ -0,0 +1,224 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trial
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+// caseSourceType is the mock source type of the test case streams
+const caseSourceType = "$$ruletest"
+
+func init() {
+	modules.RegisterSource(caseSourceType, func() api.Source { return &caseSource{} })
+}
+
+// feeders holds the feeder of each running test case by the run id
+var feeders sync.Map
+
+// feeder sends the input events of all streams of a test case in the order of the timestamps. An event is sent after
+// the previous one is fully processed by the rule, so the events of different streams are processed in order too.
+type feeder struct {
+	events []*caseEvent
+	// progress returns the processed count of the rule and whether the rule is idle
+	progress func() (int64, bool)
+
+	mu  sync.Mutex
+	chs map[string]chan *caseEvent
+	// ready is closed when all the streams with inputs are subscribed
+	ready   chan struct{}
+	waiting map[string]struct{}
+}
+
+type caseEvent struct {
+	stream string
+	*Event
+	// ingested is closed by the source after the event is ingested into the rule
+	ingested chan struct{}
+}
+
+func newFeeder(events []*caseEvent, progress func() (int64, bool)) *feeder {
+	f := &feeder{
+		events:   events,
+		progress: progress,
+		chs:      make(map[string]chan *caseEvent),
+		ready:    make(chan struct{}),
+		waiting:  make(map[string]struct{}),
+	}
+	for _, e := range events {
+		f.waiting[e.stream] = struct{}{}
+	}
+	if len(f.waiting) == 0 {
+		close(f.ready)
+	}
+	return f
+}
+
+// subscribe returns the channel of the events of the stream. The channel of the stream without inputs is closed.
+func (f *feeder) subscribe(stream string) <-chan *caseEvent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ch := make(chan *caseEvent)
+	if _, ok := f.waiting[stream]; !ok {
+		close(ch)
+		return ch
+	}
+	f.chs[stream] = ch
+	delete(f.waiting, stream)
+	if len(f.waiting) == 0 {
+		close(f.ready)
+	}
+	return ch
+}
+
+// feed sends all the events after all the streams are subscribed and closes the channels at last. Like the topo
+// tests, the clock is set to the timestamp of each event before sending it, which only takes effect for the mock clock.
+func (f *feeder) feed(ctx api.StreamContext, timeout time.Duration) error {
+	select {
+	case <-f.ready:
+	case <-ctx.Done():
+		return nil
+	case <-time.After(timeout):
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		for s := range f.waiting {
+			return fmt.Errorf("stream %s with inputs is not read by the rule", s)
+		}
+	}
+	defer func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		for _, ch := range f.chs {
+			close(ch)
+		}
+	}()
+	for _, e := range f.events {
+		if e.Timestamp > timex.GetNowInMilli() {
+			timex.Set(e.Timestamp)
+		}
+		e.ingested = make(chan struct{})
+		select {
+		case f.chs[e.stream] <- e:
+		case <-ctx.Done():
+			return nil
+		}
+		select {
+		case <-e.ingested:
+		case <-ctx.Done():
+			return nil
+		}
+		if !f.waitProcessed(ctx) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// waitProcessed waits until the rule is idle, which is confirmed by two successive checks with the same processed
+// count. It returns false if the rule is stopped.
+func (f *feeder) waitProcessed(ctx api.StreamContext) bool {
+	ticker := time.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+	last := int64(-1)
+	for {
+		n, idle := f.progress()
+		if idle && n == last {
+			return true
+		}
+		if idle {
+			last = n
+		} else {
+			last = -1
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// caseSource ingests the events of a stream from the feeder of the run. The timestamp of the event is the timestamp
+// of the tuple.
+type caseSource struct {
+	conf *caseSourceConf
+	eof  api.EOFIngest
+}
+
+type caseSourceConf struct {
+	Run    string `json:"run"`
+	Stream string `json:"stream"`
+}
+
+func (s *caseSource) Provision(_ api.StreamContext, props map[string]any) error {
+	c := &caseSourceConf{}
+	if err := cast.MapToStruct(props, c); err != nil {
+		return err
+	}
+	s.conf = c
+	return nil
+}
+
+func (s *caseSource) Connect(_ api.StreamContext, sch api.StatusChangeHandler) error {
+	sch(api.ConnectionConnected, "")
+	return nil
+}
+
+func (s *caseSource) SetEofIngest(eof api.EOFIngest) {
+	s.eof = eof
+}
+
+func (s *caseSource) Subscribe(ctx api.StreamContext, ingest api.TupleIngest, _ api.ErrorIngest) error {
+	f, ok := feeders.Load(s.conf.Run)
+	if !ok {
+		return fmt.Errorf("test case run %s not found", s.conf.Run)
+	}
+	ch := f.(*feeder).subscribe(s.conf.Stream)
+	go func() {
+		for {
+			select {
+			case e, ok := <-ch:
+				if !ok {
+					if s.eof != nil {
+						s.eof(ctx, "")
+					}
+					return
+				}
+				ingest(ctx, e.Data, nil, time.UnixMilli(e.Timestamp))
+				close(e.ingested)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (s *caseSource) Close(_ api.StreamContext) error {
+	return nil
+}
+
+var (
+	_ api.TupleSource = &caseSource{}
+	_ api.Bounded     = &caseSource{}
+)
@@ -0,0 +1,157 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trial
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
+	"github.com/lf-edge/ekuiper/v2/internal/processor"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func setupCaseStreams(t *testing.T) {
+	conf.IsTesting = true
+	conf.InitConf()
+	dataDir, err := conf.GetDataLoc()
+	require.NoError(t, err)
+	require.NoError(t, store.SetupDefault(dataDir))
+	p := processor.NewStreamProcessor()
+	for _, sql := range []string{
+		`CREATE STREAM caseDemo (color string, size bigint) WITH (DATASOURCE="caseDemo", TYPE="mqtt")`,
+		`CREATE STREAM caseDemoE (color string, size bigint, ts bigint) WITH (DATASOURCE="caseDemoE", TIMESTAMP="ts")`,
+		`CREATE STREAM caseDemo2 (color string, hum bigint) WITH (DATASOURCE="caseDemo2")`,
+	} {
+		_, _ = p.ExecStmt(sql)
+	}
+	t.Cleanup(func() {
+		for _, s := range []string{"caseDemo", "caseDemoE", "caseDemo2"} {
+			_, _ = p.ExecStmt("DROP STREAM " + s)
+		}
+	})
+}
+
+func TestRunCase(t *testing.T) {
+	setupCaseStreams(t)
+	tests := []struct {
+		name   string
+		def    string
+		pass   bool
+		report string
+	}{
+		{
+			name: "filter",
+			def: `{
+				"name": "filter",
+				"rule": {"sql": "SELECT color, size FROM caseDemo WHERE size > 2", "actions": [{"mqtt": {"server": "tcp://127.0.0.1:1883", "sendSingle": true}}, {"log": {}}]},
+				"inputs": {"caseDemo": [{"timestamp": 1, "data": {"color": "red", "size": 3}}, {"timestamp": 2, "data": {"color": "blue", "size": 1}}, {"timestamp": 3, "data": {"color": "green", "size": 5}}]},
+				"expected": {"mqtt_0": [{"color": "red", "size": 3}, {"color": "green", "size": 5}]}
+			}`,
+			pass:   true,
+			report: "PASS: rule test filter",
+		},
+		{
+			name: "mismatch",
+			def: `{
+				"name": "mismatch",
+				"rule": {"sql": "SELECT color FROM caseDemo WHERE size > 2"},
+				"inputs": {"caseDemo": [{"timestamp": 1, "data": {"color": "red", "size": 3}}, {"timestamp": 2, "data": {"color": "green", "size": 5}}]},
+				"expected": {"memory_0": [[{"color": "red"}], [{"color": "blue"}], [{"color": "black"}]]}
+			}`,
+			report: "FAIL: rule test mismatch\n  sink memory_0:\n    output #2: expected [{\"color\":\"blue\"}], got [{\"color\":\"green\"}]\n    output #3: expected [{\"color\":\"black\"}], got nothing",
+		},
+		{
+			name: "event time window",
+			def: `{
+				"name": "event time window",
+				"rule": {"sql": "SELECT count(*) AS c, window_end() AS e FROM caseDemoE GROUP BY TUMBLINGWINDOW(ss, 1)", "options": {"isEventTime": true, "lateTolerance": 0}},
+				"inputs": {"caseDemoE": [
+					{"timestamp": 1000, "data": {"color": "red", "size": 3, "ts": 1000}},
+					{"timestamp": 1200, "data": {"color": "red", "size": 3, "ts": 1200}},
+					{"timestamp": 2100, "data": {"color": "red", "size": 3, "ts": 2100}},
+					{"timestamp": 3100, "data": {"color": "red", "size": 3, "ts": 3100}}
+				]},
+				"expected": {"memory_0": [[{"e": 1000}], [{"c": 2, "e": 2000}], [{"c": 1, "e": 3000}]]}
+			}`,
+			pass:   true,
+			report: "PASS: rule test event time window",
+		},
+		{
+			name: "join in order",
+			def: `{
+				"name": "join in order",
+				"rule": {"sql": "SELECT caseDemo.color, caseDemo2.hum FROM caseDemo INNER JOIN caseDemo2 ON caseDemo.color = caseDemo2.color GROUP BY COUNTWINDOW(2)"},
+				"inputs": {
+					"caseDemo": [{"timestamp": 1, "data": {"color": "red", "size": 3}}, {"timestamp": 4, "data": {"color": "blue", "size": 3}}],
+					"caseDemo2": [{"timestamp": 2, "data": {"color": "red", "hum": 50}}, {"timestamp": 3, "data": {"color": "blue", "hum": 60}}]
+				},
+				"expected": {"memory_0": [[{"color": "red", "hum": 50}], [{"color": "blue", "hum": 60}]]}
+			}`,
+			pass:   true,
+			report: "PASS: rule test join in order",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := RunCase(tt.def)
+			require.NoError(t, err)
+			require.Equal(t, tt.pass, r.Pass)
+			require.Equal(t, tt.report, r.String())
+		})
+	}
+}
+
+func TestRunCaseErr(t *testing.T) {
+	setupCaseStreams(t)
+	tests := []struct {
+		def string
+		err string
+	}{
+		{def: `{"name": "a"}`, err: "rule is required in the test case"},
+		{def: `{"rule": {"sql": "SELECT count(*) FROM caseDemo GROUP BY TUMBLINGWINDOW(ss, 1)"}}`, err: "the time window of processing time is not deterministic, set the isEventTime option of the rule and the TIMESTAMP of the streams"},
+		{def: `{"rule": {"sql": "SELECT * FROM caseDemo"}, "expected": {"mqtt_0": []}}`, err: "sink mqtt_0 not found in the rule, the sinks are memory_0"},
+		{def: `{"rule": {"sql": "SELECT * FROM caseDemo"}, "inputs": {"demo": []}}`, err: "stream demo not found in the rule, the streams are caseDemo"},
+		{def: `{"rule": {"sql": "SELECT * FROM caseDemo"}, "timeout": "1m"}`, err: ""},
+	}
+	for _, tt := range tests {
+		_, err := RunCase(tt.def)
+		if tt.err == "" {
+			require.NoError(t, err)
+		} else {
+			require.EqualError(t, err, tt.err)
+		}
+	}
+}
+
+func TestFeederWaitProcessed(t *testing.T) {
+	// The rule is busy for 3 checks and then idle. It must be confirmed idle twice with the same count.
+	calls := 0
+	f := newFeeder(nil, func() (int64, bool) {
+		calls++
+		if calls <= 3 {
+			return int64(calls), false
+		}
+		return 3, true
+	})
+	ctx, cancel := mockContext.NewMockContext("rule1", "op1").WithCancel()
+	require.True(t, f.waitProcessed(ctx))
+	require.Equal(t, 5, calls)
+	cancel()
+	f = newFeeder(nil, func() (int64, bool) { return 0, false })
+	require.False(t, f.waitProcessed(ctx))
+}
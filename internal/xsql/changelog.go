@@ -0,0 +1,68 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xsql
+
+import "strings"
+
+// The row kinds in the changelog mode. An update of a row is sent as a pair of the update before row with the old values
+// and the update after row with the new values, so that the operators can retract the old values.
+const (
+	RowkindInsert       = "+I"
+	RowkindUpdateBefore = "-U"
+	RowkindUpdateAfter  = "+U"
+	RowkindDelete       = "-D"
+)
+
+// ChangelogRow is the row carrying its row kind in the changelog mode
+type ChangelogRow interface {
+	GetRowkind() string
+	SetRowkind(kind string)
+	// ChangelogKey is the identity of the row to find the row to retract
+	ChangelogKey() string
+}
+
+// Changelog is the row kind and the key of a row in the changelog mode. The row kind is empty if the rule is not in the changelog mode.
+type Changelog struct {
+	Rowkind string
+	Key     string
+}
+
+func (c *Changelog) GetRowkind() string {
+	return c.Rowkind
+}
+
+func (c *Changelog) SetRowkind(kind string) {
+	c.Rowkind = kind
+}
+
+func (c *Changelog) ChangelogKey() string {
+	return c.Key
+}
+
+// IsRetract returns whether the row kind retracts a previous row
+func IsRetract(kind string) bool {
+	return kind == RowkindUpdateBefore || kind == RowkindDelete
+}
+
+// ChangelogKey is the key of the joined row which is combined by the keys of its rows
+func (jt *JoinTuple) ChangelogKey() string {
+	keys := make([]string, len(jt.Tuples))
+	for i, t := range jt.Tuples {
+		if cr, ok := t.(ChangelogRow); ok {
+			keys[i] = cr.ChangelogKey()
+		}
+	}
+	return strings.Join(keys, "|")
+}
@@ -0,0 +1,860 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xsql
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+// maxExpandDepth limits the nesting of views and SQL functions to avoid endless expansion of cyclic definitions
+const maxExpandDepth = 16
+
+// ViewGetter returns the view definition by name. It returns nil without error if the name is not a view.
+type ViewGetter func(name string) (*ast.ViewStmt, error)
+
+// Expansion records the views and SQL functions which are expanded into a statement
+type Expansion struct {
+	Views     []string
+	Functions []string
+}
+
+// ExpandStatement expands the views and the SQL functions referred by the select statement in place.
+// The views in the FROM and JOIN clauses are replaced by their underlying streams. The view columns and the
+// view conditions are merged into the statement. The SQL function calls are replaced by their bodies.
+// The subqueries are expanded separately.
+func ExpandStatement(stmt *ast.SelectStatement, getView ViewGetter, getFunc SqlFuncGetter) (*Expansion, error) {
+	return expandStatement(stmt, getView, getFunc, false)
+}
+
+// ExpandStatementWithSharedViews expands the statement like ExpandStatement except that the view which is the only
+// source of the statement is expanded as a derived table marked by the view name. Thus, the planner can run the view
+// once for all the rules selecting from it.
+func ExpandStatementWithSharedViews(stmt *ast.SelectStatement, getView ViewGetter, getFunc SqlFuncGetter) (*Expansion, error) {
+	return expandStatement(stmt, getView, getFunc, true)
+}
+
+func expandStatement(stmt *ast.SelectStatement, getView ViewGetter, getFunc SqlFuncGetter, shareViews bool) (*Expansion, error) {
+	result := &Expansion{}
+	e := &expander{getView: getView, getFunc: getFunc, result: result, shareViews: shareViews}
+	if err := e.expandStatement(stmt); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (e *expander) expandStatement(stmt *ast.SelectStatement) error {
+	// Only the view of the outermost statement is shared
+	share := e.shareViews
+	e.shareViews = false
+	for _, src := range stmt.Sources {
+		if t, ok := src.(*ast.Table); ok && t.Subquery != nil {
+			if err := e.expandStatement(t.Subquery); err != nil {
+				return err
+			}
+		}
+	}
+	for _, j := range stmt.Joins {
+		if j.Subquery != nil {
+			if err := e.expandStatement(j.Subquery); err != nil {
+				return err
+			}
+		}
+	}
+	for _, u := range stmt.Unions {
+		if err := e.expandStatement(u); err != nil {
+			return err
+		}
+	}
+	shared := false
+	if share {
+		var err error
+		if shared, err = e.shareView(stmt); err != nil {
+			return err
+		}
+	}
+	e.expanded = false
+	if !shared {
+		if err := e.expandViews(stmt, 0); err != nil {
+			return err
+		}
+	}
+	for i, f := range stmt.Fields {
+		// Keep the output name of the function call after expanding
+		if c, ok := f.Expr.(*ast.Call); ok && f.AName == "" && e.isSqlFunction(c.Name) {
+			stmt.Fields[i].AName = f.Name
+		}
+	}
+	err := rewriteStatement(stmt, func(expr ast.Expr) (ast.Expr, error) {
+		return e.expandFunc(expr, 0)
+	})
+	if err != nil {
+		return err
+	}
+	if e.expanded {
+		return resetFuncIds(stmt)
+	}
+	return nil
+}
+
+// resetFuncIds numbers the function calls again and validates the statement. The expanded expressions come from
+// different statements, so the function ids may conflict.
+func resetFuncIds(stmt *ast.SelectStatement) error {
+	fid := 0
+	ast.WalkFunc(stmt, func(n ast.Node) bool {
+		if c, ok := n.(*ast.Call); ok {
+			c.FuncId = fid
+			fid++
+		}
+		return true
+	})
+	return Validate(stmt)
+}
+
+// shareView replaces the view which is the only source of the statement with a derived table of the view select.
+// The derived table is named by the alias or the view name so that the references to the view are kept.
+func (e *expander) shareView(stmt *ast.SelectStatement) (bool, error) {
+	if len(stmt.Sources) != 1 || len(stmt.Joins) > 0 || len(stmt.Unions) > 0 {
+		return false, nil
+	}
+	t, ok := stmt.Sources[0].(*ast.Table)
+	if !ok || t.Subquery != nil {
+		return false, nil
+	}
+	vd, err := e.loadView(t.Name, 0)
+	if err != nil || vd == nil {
+		return false, err
+	}
+	// The nested views are already merged into the view select. Expand its SQL functions.
+	if err := e.expandStatement(vd.stmt); err != nil {
+		return false, err
+	}
+	if err := resetFuncIds(vd.stmt); err != nil {
+		return false, err
+	}
+	name := t.Name
+	if t.Alias != "" {
+		name = t.Alias
+	}
+	stmt.Sources[0] = &ast.Table{Name: name, Subquery: vd.stmt, View: vd.name}
+	return true, nil
+}
+
+type expander struct {
+	getView ViewGetter
+	getFunc SqlFuncGetter
+	result  *Expansion
+	// expanded is whether the current statement is changed by the expansion
+	expanded bool
+	// shareViews is whether to expand the only view source of the statement as a derived table
+	shareViews bool
+}
+
+// isSqlFunction checks if the call is a SQL function. The built-in functions take precedence like the parser.
+func (e *expander) isSqlFunction(name string) bool {
+	if _, ok := convFuncName(name); ok {
+		return false
+	}
+	if e.getFunc == nil {
+		return false
+	}
+	_, ok := e.getFunc(name)
+	return ok
+}
+
+// viewDef is the resolved view whose expressions all refer to the underlying stream
+type viewDef struct {
+	name   string
+	stream string
+	// stmt is the view select whose nested views are expanded
+	stmt     *ast.SelectStatement
+	fields   ast.Fields
+	columns  map[string]ast.Expr
+	wildcard bool
+	cond     ast.Expr
+}
+
+func (vd *viewDef) column(name string) (ast.Expr, error) {
+	if expr, ok := vd.columns[name]; ok {
+		return cloneExpr(expr), nil
+	}
+	if vd.wildcard {
+		return &ast.FieldRef{StreamName: ast.StreamName(vd.stream), Name: name}, nil
+	}
+	return nil, fmt.Errorf("column %s is not found in view %s", name, vd.name)
+}
+
+// cloneFields returns the select fields of the view. If qualified, the wildcard only refers to the underlying stream.
+func (vd *viewDef) cloneFields(qualified bool) ast.Fields {
+	result := make(ast.Fields, 0, len(vd.fields))
+	for _, f := range vd.fields {
+		if _, ok := f.Expr.(*ast.Wildcard); ok {
+			if qualified {
+				result = append(result, ast.Field{Name: "*", Expr: &ast.FieldRef{StreamName: ast.StreamName(vd.stream), Name: "*"}})
+			} else {
+				result = append(result, ast.Field{Name: "*", Expr: &ast.Wildcard{Token: ast.ASTERISK}})
+			}
+			continue
+		}
+		nf := f
+		nf.Expr = cloneExpr(f.Expr)
+		result = append(result, nf)
+	}
+	return result
+}
+
+func (e *expander) loadView(name string, depth int) (*viewDef, error) {
+	v, err := e.getView(name)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	if depth >= maxExpandDepth {
+		return nil, fmt.Errorf("view %s is nested too deep, please check if the views refer to each other", name)
+	}
+	if !contains(e.result.Views, v.Name) {
+		e.result.Views = append(e.result.Views, v.Name)
+	}
+	sel := v.Select
+	// Expand the views which this view selects from
+	if err := e.expandViews(sel, depth+1); err != nil {
+		return nil, err
+	}
+	t, ok := sel.Sources[0].(*ast.Table)
+	if !ok {
+		return nil, fmt.Errorf("view %s has invalid source", name)
+	}
+	vd := &viewDef{
+		name:    v.Name,
+		stream:  t.Name,
+		stmt:    sel,
+		columns: make(map[string]ast.Expr),
+	}
+	refNames := []string{string(ast.DefaultStream), t.Name}
+	if t.Alias != "" {
+		refNames = append(refNames, t.Alias)
+	}
+	qualify := func(expr ast.Expr) (ast.Expr, error) {
+		switch r := expr.(type) {
+		case *ast.FieldRef:
+			if !contains(refNames, string(r.StreamName)) {
+				return expr, nil
+			}
+			// Refer to the alias defined by the view
+			if r.StreamName == ast.DefaultStream {
+				if c, ok := vd.columns[r.Name]; ok {
+					return cloneExpr(c), nil
+				}
+			}
+			return &ast.FieldRef{StreamName: ast.StreamName(vd.stream), Name: r.Name}, nil
+		case *ast.MetaRef:
+			if contains(refNames, string(r.StreamName)) {
+				return &ast.MetaRef{StreamName: ast.StreamName(vd.stream), Name: r.Name}, nil
+			}
+		}
+		return expr, nil
+	}
+	for _, f := range sel.Fields {
+		switch fe := f.Expr.(type) {
+		case *ast.Wildcard:
+			vd.wildcard = true
+			vd.fields = append(vd.fields, f)
+			continue
+		case *ast.FieldRef:
+			if fe.Name == "*" {
+				vd.wildcard = true
+				vd.fields = append(vd.fields, ast.Field{Name: "*", Expr: &ast.Wildcard{Token: ast.ASTERISK}})
+				continue
+			}
+		}
+		expr, err := rewriteExpr(f.Expr, qualify)
+		if err != nil {
+			return nil, err
+		}
+		f.Expr = expr
+		vd.fields = append(vd.fields, f)
+		vd.columns[f.GetName()] = expr
+	}
+	if sel.Condition != nil {
+		vd.cond, err = rewriteExpr(sel.Condition, qualify)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return vd, nil
+}
+
+func (e *expander) expandViews(stmt *ast.SelectStatement, depth int) error {
+	var (
+		// the reference name (name or alias) to the view
+		refs      = make(map[string]*viewDef)
+		fromViews []*viewDef
+		joinViews = make(map[int]*viewDef)
+		allViews  []*viewDef
+	)
+	for i, src := range stmt.Sources {
+		t, ok := src.(*ast.Table)
+		if !ok || t.Subquery != nil {
+			continue
+		}
+		vd, err := e.loadView(t.Name, depth)
+		if err != nil {
+			return err
+		}
+		if vd == nil {
+			continue
+		}
+		refs[t.Name] = vd
+		if t.Alias != "" {
+			refs[t.Alias] = vd
+		}
+		stmt.Sources[i] = &ast.Table{Name: vd.stream}
+		fromViews = append(fromViews, vd)
+		allViews = append(allViews, vd)
+	}
+	for i := range stmt.Joins {
+		j := &stmt.Joins[i]
+		if j.Subquery != nil {
+			continue
+		}
+		vd, err := e.loadView(j.Name, depth)
+		if err != nil {
+			return err
+		}
+		if vd == nil {
+			continue
+		}
+		refs[j.Name] = vd
+		if j.Alias != "" {
+			refs[j.Alias] = vd
+		}
+		j.Name = vd.stream
+		j.Alias = ""
+		joinViews[i] = vd
+		allViews = append(allViews, vd)
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+	e.expanded = true
+	streams := GetSources(stmt)
+	for i, s := range streams {
+		if contains(streams[i+1:], s) {
+			return fmt.Errorf("stream %s is referred more than once after expanding the views", s)
+		}
+	}
+	single := len(fromViews) == 1 && len(stmt.Sources) == 1 && len(stmt.Joins) == 0
+	aliases := make(map[string]struct{})
+	for _, f := range stmt.Fields {
+		if f.AName != "" {
+			aliases[f.AName] = struct{}{}
+		}
+	}
+	resolve := func(expr ast.Expr) (ast.Expr, error) {
+		switch r := expr.(type) {
+		case *ast.FieldRef:
+			if vd, ok := refs[string(r.StreamName)]; ok {
+				return vd.column(r.Name)
+			}
+			if r.StreamName != ast.DefaultStream {
+				return expr, nil
+			}
+			if _, ok := aliases[r.Name]; ok {
+				return expr, nil
+			}
+			var found *viewDef
+			for _, vd := range allViews {
+				if _, ok := vd.columns[r.Name]; ok {
+					if found != nil {
+						return nil, fmt.Errorf("column %s is ambiguous in views %s and %s", r.Name, found.name, vd.name)
+					}
+					found = vd
+				}
+			}
+			if found != nil {
+				return found.column(r.Name)
+			}
+			if single {
+				return fromViews[0].column(r.Name)
+			}
+		case *ast.MetaRef:
+			if vd, ok := refs[string(r.StreamName)]; ok {
+				return &ast.MetaRef{StreamName: ast.StreamName(vd.stream), Name: r.Name}, nil
+			}
+		}
+		return expr, nil
+	}
+	fields := make(ast.Fields, 0, len(stmt.Fields))
+	for _, f := range stmt.Fields {
+		switch fe := f.Expr.(type) {
+		case *ast.Wildcard:
+			if !single {
+				return fmt.Errorf("wildcard is not supported when joining views, please select the view columns explicitly")
+			}
+			if len(fe.Except) > 0 || len(fe.Replace) > 0 {
+				return fmt.Errorf("wildcard with EXCEPT or REPLACE is not supported for view %s", fromViews[0].name)
+			}
+			fields = append(fields, fromViews[0].cloneFields(false)...)
+			continue
+		case *ast.FieldRef:
+			if vd, ok := refs[string(fe.StreamName)]; ok && fe.Name == "*" {
+				fields = append(fields, vd.cloneFields(true)...)
+				continue
+			}
+		}
+		expr, err := rewriteExpr(f.Expr, resolve)
+		if err != nil {
+			return err
+		}
+		// Keep the output name of the column after replacing it with the view expression
+		if r, ok := f.Expr.(*ast.FieldRef); ok && f.AName == "" {
+			if nr, ok := expr.(*ast.FieldRef); !ok || nr.Name != r.Name {
+				f.AName = f.Name
+			}
+		}
+		f.Expr = expr
+		fields = append(fields, f)
+	}
+	stmt.Fields = fields
+	if err := rewriteClauses(stmt, resolve); err != nil {
+		return err
+	}
+	for _, vd := range fromViews {
+		if vd.cond == nil {
+			continue
+		}
+		for _, j := range stmt.Joins {
+			if j.JoinType == ast.RIGHT_JOIN || j.JoinType == ast.FULL_JOIN {
+				return fmt.Errorf("view %s with condition cannot be the left side of %s", vd.name, j.JoinType)
+			}
+		}
+		stmt.Condition = andExpr(cloneExpr(vd.cond), stmt.Condition)
+	}
+	for i, vd := range joinViews {
+		if vd.cond == nil {
+			continue
+		}
+		j := &stmt.Joins[i]
+		switch j.JoinType {
+		case ast.FULL_JOIN:
+			return fmt.Errorf("view %s with condition cannot be used in %s", vd.name, j.JoinType)
+		case ast.RIGHT_JOIN, ast.CROSS_JOIN:
+			stmt.Condition = andExpr(cloneExpr(vd.cond), stmt.Condition)
+		default:
+			j.Expr = andExpr(j.Expr, cloneExpr(vd.cond))
+		}
+	}
+	return nil
+}
+
+func (e *expander) expandFunc(expr ast.Expr, depth int) (ast.Expr, error) {
+	c, ok := expr.(*ast.Call)
+	if !ok || !e.isSqlFunction(c.Name) {
+		return expr, nil
+	}
+	if depth >= maxExpandDepth {
+		return nil, fmt.Errorf("function %s is nested too deep, please check if the functions call each other", c.Name)
+	}
+	fs, err := getSqlFunction(e.getFunc, c.Name)
+	if err != nil {
+		return nil, err
+	}
+	if len(c.Args) != len(fs.Params) {
+		return nil, fmt.Errorf("function %s expects %d arguments but got %d", c.Name, len(fs.Params), len(c.Args))
+	}
+	e.expanded = true
+	if !contains(e.result.Functions, fs.Name) {
+		e.result.Functions = append(e.result.Functions, fs.Name)
+	}
+	args := make(map[string]ast.Expr, len(fs.Params))
+	for i, p := range fs.Params {
+		args[p] = c.Args[i]
+	}
+	body, err := rewriteExpr(fs.Body, func(n ast.Expr) (ast.Expr, error) {
+		if r, ok := n.(*ast.FieldRef); ok && r.StreamName == ast.DefaultStream {
+			if arg, ok := args[r.Name]; ok {
+				return cloneExpr(arg), nil
+			}
+		}
+		return n, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// The body may call other SQL functions
+	body, err = rewriteExpr(body, func(n ast.Expr) (ast.Expr, error) {
+		return e.expandFunc(n, depth+1)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ast.ParenExpr{Expr: body}, nil
+}
+
+func andExpr(lhs ast.Expr, rhs ast.Expr) ast.Expr {
+	if lhs == nil {
+		return rhs
+	}
+	if rhs == nil {
+		return lhs
+	}
+	return &ast.BinaryExpr{OP: ast.AND, LHS: lhs, RHS: rhs}
+}
+
+// rewriteStatement rewrites all the expressions of the select statement
+func rewriteStatement(stmt *ast.SelectStatement, fn func(ast.Expr) (ast.Expr, error)) error {
+	for i, f := range stmt.Fields {
+		expr, err := rewriteExpr(f.Expr, fn)
+		if err != nil {
+			return err
+		}
+		stmt.Fields[i].Expr = expr
+	}
+	return rewriteClauses(stmt, fn)
+}
+
+// rewriteClauses rewrites the expressions of the select statement except the select fields
+func rewriteClauses(stmt *ast.SelectStatement, fn func(ast.Expr) (ast.Expr, error)) (err error) {
+	for i, j := range stmt.Joins {
+		if stmt.Joins[i].Expr, err = rewriteExpr(j.Expr, fn); err != nil {
+			return err
+		}
+	}
+	if stmt.Condition, err = rewriteExpr(stmt.Condition, fn); err != nil {
+		return err
+	}
+	for i, d := range stmt.Dimensions {
+		if w, ok := d.Expr.(*ast.Window); ok {
+			if w.Filter, err = rewriteExpr(w.Filter, fn); err != nil {
+				return err
+			}
+			if w.TriggerCondition, err = rewriteExpr(w.TriggerCondition, fn); err != nil {
+				return err
+			}
+			if w.SingleCondition, err = rewriteExpr(w.SingleCondition, fn); err != nil {
+				return err
+			}
+			if w.BeginCondition, err = rewriteExpr(w.BeginCondition, fn); err != nil {
+				return err
+			}
+			if w.EmitCondition, err = rewriteExpr(w.EmitCondition, fn); err != nil {
+				return err
+			}
+			if w.PartitionExpr != nil {
+				for k, pe := range w.PartitionExpr.Exprs {
+					if w.PartitionExpr.Exprs[k], err = rewriteExpr(pe, fn); err != nil {
+						return err
+					}
+				}
+			}
+			continue
+		}
+		if stmt.Dimensions[i].Expr, err = rewriteExpr(d.Expr, fn); err != nil {
+			return err
+		}
+	}
+	if stmt.Having, err = rewriteExpr(stmt.Having, fn); err != nil {
+		return err
+	}
+	for i, s := range stmt.SortFields {
+		expr, err := rewriteExpr(s.FieldExpr, fn)
+		if err != nil {
+			return err
+		}
+		stmt.SortFields[i].FieldExpr = expr
+		if r, ok := expr.(*ast.FieldRef); ok && s.StreamName != "" {
+			stmt.SortFields[i].StreamName = r.StreamName
+		}
+	}
+	return nil
+}
+
+// rewriteExpr walks the expression tree in depth-first order and replaces each node with the result of fn.
+// The children are rewritten before their parent.
+func rewriteExpr(expr ast.Expr, fn func(ast.Expr) (ast.Expr, error)) (ast.Expr, error) {
+	if expr == nil || reflect.ValueOf(expr).IsNil() {
+		return expr, nil
+	}
+	var err error
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		e.Expr, err = rewriteExpr(e.Expr, fn)
+	case *ast.ArrowExpr:
+		e.Expr, err = rewriteExpr(e.Expr, fn)
+	case *ast.BracketExpr:
+		e.Expr, err = rewriteExpr(e.Expr, fn)
+	case *ast.ColonExpr:
+		if e.Start, err = rewriteExpr(e.Start, fn); err == nil {
+			e.End, err = rewriteExpr(e.End, fn)
+		}
+	case *ast.IndexExpr:
+		e.Index, err = rewriteExpr(e.Index, fn)
+	case *ast.Call:
+		for i, arg := range e.Args {
+			if e.Args[i], err = rewriteExpr(arg, fn); err != nil {
+				return nil, err
+			}
+		}
+		if e.Partition != nil {
+			for i, p := range e.Partition.Exprs {
+				if e.Partition.Exprs[i], err = rewriteExpr(p, fn); err != nil {
+					return nil, err
+				}
+			}
+		}
+		e.WhenExpr, err = rewriteExpr(e.WhenExpr, fn)
+	case *ast.BinaryExpr:
+		if e.LHS, err = rewriteExpr(e.LHS, fn); err == nil {
+			e.RHS, err = rewriteExpr(e.RHS, fn)
+		}
+	case *ast.CaseExpr:
+		if e.Value, err = rewriteExpr(e.Value, fn); err != nil {
+			return nil, err
+		}
+		if e.ElseClause, err = rewriteExpr(e.ElseClause, fn); err != nil {
+			return nil, err
+		}
+		for _, w := range e.WhenClauses {
+			if w.Expr, err = rewriteExpr(w.Expr, fn); err != nil {
+				return nil, err
+			}
+			if w.Result, err = rewriteExpr(w.Result, fn); err != nil {
+				return nil, err
+			}
+		}
+	case *ast.ValueSetExpr:
+		if e.ArrayExpr, err = rewriteExpr(e.ArrayExpr, fn); err != nil {
+			return nil, err
+		}
+		for i, v := range e.LiteralExprs {
+			if e.LiteralExprs[i], err = rewriteExpr(v, fn); err != nil {
+				return nil, err
+			}
+		}
+	case *ast.BetweenExpr:
+		if e.Lower, err = rewriteExpr(e.Lower, fn); err == nil {
+			e.Higher, err = rewriteExpr(e.Higher, fn)
+		}
+	case *ast.LikePattern:
+		e.Expr, err = rewriteExpr(e.Expr, fn)
+	case *ast.ColFuncField:
+		e.Expr, err = rewriteExpr(e.Expr, fn)
+	case *ast.Wildcard:
+		for i, r := range e.Replace {
+			if e.Replace[i].Expr, err = rewriteExpr(r.Expr, fn); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return fn(expr)
+}
+
+// cloneExpr copies the expression tree so that the same expression can be expanded into several places.
+// Each node is copied with its children, while the values outside the tree such as the regexp are shared.
+func cloneExpr(expr ast.Expr) ast.Expr {
+	if expr == nil || reflect.ValueOf(expr).IsNil() {
+		return expr
+	}
+	switch e := expr.(type) {
+	case *ast.FieldRef:
+		n := *e
+		if e.AliasRef != nil {
+			ar := *e.AliasRef
+			ar.Expression = cloneExpr(ar.Expression)
+			n.AliasRef = &ar
+		}
+		return &n
+	case *ast.MetaRef:
+		n := *e
+		return &n
+	case *ast.JsonFieldRef:
+		n := *e
+		return &n
+	case *ast.IntegerLiteral:
+		n := *e
+		return &n
+	case *ast.NumberLiteral:
+		n := *e
+		return &n
+	case *ast.StringLiteral:
+		n := *e
+		return &n
+	case *ast.BooleanLiteral:
+		n := *e
+		return &n
+	case *ast.TimeLiteral:
+		n := *e
+		return &n
+	case *ast.ParenExpr:
+		return &ast.ParenExpr{Expr: cloneExpr(e.Expr)}
+	case *ast.ArrowExpr:
+		return &ast.ArrowExpr{Expr: cloneExpr(e.Expr)}
+	case *ast.BracketExpr:
+		return &ast.BracketExpr{Expr: cloneExpr(e.Expr)}
+	case *ast.ColonExpr:
+		return &ast.ColonExpr{Start: cloneExpr(e.Start), End: cloneExpr(e.End)}
+	case *ast.IndexExpr:
+		return &ast.IndexExpr{Index: cloneExpr(e.Index)}
+	case *ast.Call:
+		n := *e
+		n.Args = cloneExprs(e.Args)
+		if e.Partition != nil {
+			n.Partition = &ast.PartitionExpr{Exprs: cloneExprs(e.Partition.Exprs)}
+		}
+		n.WhenExpr = cloneExpr(e.WhenExpr)
+		if e.SortFields != nil {
+			n.SortFields = make(ast.SortFields, len(e.SortFields))
+			for i, sf := range e.SortFields {
+				n.SortFields[i] = sf
+				n.SortFields[i].FieldExpr = cloneExpr(sf.FieldExpr)
+			}
+		}
+		return &n
+	case *ast.BinaryExpr:
+		return &ast.BinaryExpr{OP: e.OP, LHS: cloneExpr(e.LHS), RHS: cloneExpr(e.RHS)}
+	case *ast.CaseExpr:
+		n := &ast.CaseExpr{Value: cloneExpr(e.Value), ElseClause: cloneExpr(e.ElseClause)}
+		for _, w := range e.WhenClauses {
+			n.WhenClauses = append(n.WhenClauses, &ast.WhenClause{Expr: cloneExpr(w.Expr), Result: cloneExpr(w.Result)})
+		}
+		return n
+	case *ast.ValueSetExpr:
+		return &ast.ValueSetExpr{LiteralExprs: cloneExprs(e.LiteralExprs), ArrayExpr: cloneExpr(e.ArrayExpr)}
+	case *ast.BetweenExpr:
+		return &ast.BetweenExpr{Lower: cloneExpr(e.Lower), Higher: cloneExpr(e.Higher)}
+	case *ast.LikePattern:
+		return &ast.LikePattern{Expr: cloneExpr(e.Expr), Pattern: e.Pattern}
+	case *ast.ColFuncField:
+		return &ast.ColFuncField{Name: e.Name, Expr: cloneExpr(e.Expr)}
+	case *ast.Wildcard:
+		n := *e
+		n.Replace = cloneFields(e.Replace)
+		return &n
+	case *ast.Window:
+		n := *e
+		if e.PartitionExpr != nil {
+			n.PartitionExpr = &ast.PartitionExpr{Exprs: cloneExprs(e.PartitionExpr.Exprs)}
+		}
+		n.TriggerCondition = cloneExpr(e.TriggerCondition)
+		n.SingleCondition = cloneExpr(e.SingleCondition)
+		n.BeginCondition = cloneExpr(e.BeginCondition)
+		n.EmitCondition = cloneExpr(e.EmitCondition)
+		n.Filter = cloneExpr(e.Filter)
+		for _, l := range []**ast.IntegerLiteral{&n.Delay, &n.Length, &n.Interval} {
+			if *l != nil {
+				*l = &ast.IntegerLiteral{Val: (*l).Val}
+			}
+		}
+		if e.TimeUnit != nil {
+			n.TimeUnit = &ast.TimeLiteral{Val: e.TimeUnit.Val}
+		}
+		return &n
+	case *ast.LimitExpr:
+		n := *e
+		if e.LimitCount != nil {
+			n.LimitCount = &ast.IntegerLiteral{Val: e.LimitCount.Val}
+		}
+		return &n
+	default:
+		return expr
+	}
+}
+
+func cloneExprs(exprs []ast.Expr) []ast.Expr {
+	if exprs == nil {
+		return nil
+	}
+	result := make([]ast.Expr, len(exprs))
+	for i, e := range exprs {
+		result[i] = cloneExpr(e)
+	}
+	return result
+}
+
+func cloneFields(fields []ast.Field) []ast.Field {
+	if fields == nil {
+		return nil
+	}
+	result := make([]ast.Field, len(fields))
+	for i, f := range fields {
+		result[i] = f
+		result[i].Expr = cloneExpr(f.Expr)
+	}
+	return result
+}
+
+// cloneSelect copies the select statement with all its expressions and subqueries,
+// so that each reference of a common table expression is planned separately
+func cloneSelect(stmt *ast.SelectStatement) *ast.SelectStatement {
+	if stmt == nil {
+		return nil
+	}
+	n := *stmt
+	n.Fields = cloneFields(stmt.Fields)
+	if stmt.DistinctOn != nil {
+		n.DistinctOn = &ast.DistinctOn{Keys: cloneExprs(stmt.DistinctOn.Keys), Within: stmt.DistinctOn.Within}
+	}
+	if stmt.Sources != nil {
+		n.Sources = make(ast.Sources, len(stmt.Sources))
+		for i, src := range stmt.Sources {
+			if t, ok := src.(*ast.Table); ok {
+				nt := *t
+				nt.Subquery = cloneSelect(t.Subquery)
+				src = &nt
+			}
+			n.Sources[i] = src
+		}
+	}
+	if stmt.Joins != nil {
+		n.Joins = make(ast.Joins, len(stmt.Joins))
+		for i, j := range stmt.Joins {
+			j.Expr = cloneExpr(j.Expr)
+			j.Subquery = cloneSelect(j.Subquery)
+			n.Joins[i] = j
+		}
+	}
+	n.Condition = cloneExpr(stmt.Condition)
+	n.Limit = cloneExpr(stmt.Limit)
+	if stmt.Dimensions != nil {
+		n.Dimensions = make(ast.Dimensions, len(stmt.Dimensions))
+		for i, d := range stmt.Dimensions {
+			d.Expr = cloneExpr(d.Expr)
+			n.Dimensions[i] = d
+		}
+	}
+	n.Having = cloneExpr(stmt.Having)
+	if stmt.SortFields != nil {
+		n.SortFields = make(ast.SortFields, len(stmt.SortFields))
+		for i, sf := range stmt.SortFields {
+			sf.FieldExpr = cloneExpr(sf.FieldExpr)
+			n.SortFields[i] = sf
+		}
+	}
+	if stmt.Fill != nil {
+		f := *stmt.Fill
+		n.Fill = &f
+	}
+	if stmt.Unions != nil {
+		n.Unions = make([]*ast.SelectStatement, len(stmt.Unions))
+		for i, u := range stmt.Unions {
+			n.Unions[i] = cloneSelect(u)
+		}
+	}
+	return &n
+}
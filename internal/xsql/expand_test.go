@@ -0,0 +1,356 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xsql
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+func TestParseViewAndFunction(t *testing.T) {
+	tests := []struct {
+		s    string
+		stmt ast.Statement
+		err  string
+	}{
+		{
+			s: `CREATE VIEW v1 AS SELECT temperature FROM demo WHERE humidity > 10`,
+			stmt: &ast.ViewStmt{
+				Name: "v1",
+				Select: &ast.SelectStatement{
+					Fields:  []ast.Field{{Name: "temperature", Expr: &ast.FieldRef{StreamName: ast.DefaultStream, Name: "temperature"}}},
+					Sources: []ast.Source{&ast.Table{Name: "demo"}},
+					Condition: &ast.BinaryExpr{
+						OP:  ast.GT,
+						LHS: &ast.FieldRef{StreamName: ast.DefaultStream, Name: "humidity"},
+						RHS: &ast.IntegerLiteral{Val: 10},
+					},
+				},
+			},
+		},
+		{
+			s: `CREATE FUNCTION c2f(c) AS c * 1.8 + 32`,
+			stmt: &ast.FunctionStmt{
+				Name:   "c2f",
+				Params: []string{"c"},
+				Body: &ast.BinaryExpr{
+					OP: ast.ADD,
+					LHS: &ast.BinaryExpr{
+						OP:  ast.MUL,
+						LHS: &ast.FieldRef{StreamName: ast.DefaultStream, Name: "c"},
+						RHS: &ast.NumberLiteral{Val: 1.8},
+					},
+					RHS: &ast.IntegerLiteral{Val: 32},
+				},
+			},
+		},
+		{
+			s:    `SHOW VIEWS`,
+			stmt: &ast.ShowViewsStatement{},
+		},
+		{
+			s:    `DESCRIBE FUNCTION c2f`,
+			stmt: &ast.DescribeFunctionStatement{Name: "c2f"},
+		},
+		{
+			s:    `DROP VIEW v1`,
+			stmt: &ast.DropViewStatement{Name: "v1"},
+		},
+		{
+			s:   `CREATE VIEW v1 AS SELECT count(*) FROM demo`,
+			err: "view v1 cannot have aggregate function count",
+		},
+		{
+			s:   `CREATE VIEW v1 AS SELECT a FROM demo GROUP BY TUMBLINGWINDOW(ss, 10)`,
+			err: "view v1 cannot have GROUP BY or window",
+		},
+		{
+			s:   `CREATE VIEW v1 AS SELECT a FROM demo LEFT JOIN demo2 ON demo.id = demo2.id`,
+			err: "view v1 must select from exactly one stream without join",
+		},
+		{
+			s:   `CREATE FUNCTION abs(a) AS a`,
+			err: "function abs already exists",
+		},
+		{
+			s:   `CREATE FUNCTION f1(a, a) AS a`,
+			err: "duplicate parameter a",
+		},
+		{
+			s:   `CREATE FUNCTION f1(a) AS a + b`,
+			err: "function f1 can only refer to its parameters but got b",
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			stmt, err := Language.Parse(NewParser(strings.NewReader(tt.s)))
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err, fmt.Sprintf("case %d", i))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.stmt, stmt)
+		})
+	}
+}
+
+func expandTestGetters() (SqlFuncGetter, ViewGetter) {
+	functions := map[string]string{
+		"c2f":       `CREATE FUNCTION c2f(c) AS c * 1.8 + 32`,
+		"inrange":   `CREATE FUNCTION inRange(v, low, high) AS v >= low AND v <= high`,
+		"normalize": `CREATE FUNCTION normalize(c) AS c2f(c) / 100`,
+	}
+	funcs := func(name string) (string, bool) {
+		f, ok := functions[strings.ToLower(name)]
+		return f, ok
+	}
+	views := map[string]string{
+		"v1":     `CREATE VIEW v1 AS SELECT temperature * 2 AS t2, humidity FROM demo WHERE humidity > 10`,
+		"vall":   `CREATE VIEW vall AS SELECT *, temperature AS t FROM demo WHERE humidity > 10`,
+		"vnest":  `CREATE VIEW vnest AS SELECT t2 + 1 AS t3 FROM v1 WHERE t2 < 100`,
+		"vother": `CREATE VIEW vother AS SELECT name FROM demo1 WHERE size > 3`,
+		"vcycle": `CREATE VIEW vcycle AS SELECT a FROM vcycle`,
+		"vfunc":  `CREATE VIEW vfunc AS SELECT c2f(temperature) AS f FROM demo`,
+	}
+	getView := func(name string) (*ast.ViewStmt, error) {
+		sql, ok := views[name]
+		if !ok {
+			return nil, nil
+		}
+		stmt, err := Language.Parse(NewParserWithSqlFunctions(strings.NewReader(sql), funcs))
+		if err != nil {
+			return nil, err
+		}
+		return stmt.(*ast.ViewStmt), nil
+	}
+	return funcs, getView
+}
+
+func TestExpandStatement(t *testing.T) {
+	funcs, getView := expandTestGetters()
+	tests := []struct {
+		sql   string
+		exp   string
+		views []string
+		funcs []string
+		err   string
+	}{
+		{
+			sql: `SELECT temperature FROM demo`,
+			exp: `SELECT temperature FROM demo`,
+		},
+		{
+			sql:   `SELECT t2, humidity FROM v1 WHERE t2 > 5`,
+			exp:   `SELECT demo.temperature * 2 AS t2, demo.humidity FROM demo WHERE demo.humidity > 10 AND demo.temperature * 2 > 5`,
+			views: []string{"v1"},
+		},
+		{
+			sql:   `SELECT * FROM v1`,
+			exp:   `SELECT demo.temperature * 2 AS t2, demo.humidity FROM demo WHERE demo.humidity > 10`,
+			views: []string{"v1"},
+		},
+		{
+			sql:   `SELECT t, other FROM vall`,
+			exp:   `SELECT demo.temperature AS t, demo.other FROM demo WHERE demo.humidity > 10`,
+			views: []string{"vall"},
+		},
+		{
+			sql:   `SELECT t3 FROM vnest`,
+			exp:   `SELECT demo.temperature * 2 + 1 AS t3 FROM demo WHERE demo.humidity > 10 AND demo.temperature * 2 < 100`,
+			views: []string{"vnest", "v1"},
+		},
+		{
+			sql:   `SELECT v.t2, demo1.size FROM v1 AS v INNER JOIN demo1 ON v.humidity = demo1.h GROUP BY TUMBLINGWINDOW(ss, 10)`,
+			exp:   `SELECT demo.temperature * 2 AS t2, demo1.size FROM demo INNER JOIN demo1 ON demo.humidity = demo1.h WHERE demo.humidity > 10 GROUP BY TUMBLINGWINDOW(ss, 10)`,
+			views: []string{"v1"},
+		},
+		{
+			sql:   `SELECT t2, name FROM demo2 LEFT JOIN v1 ON demo2.id = v1.humidity LEFT JOIN vother ON demo2.id = vother.name GROUP BY TUMBLINGWINDOW(ss, 10)`,
+			exp:   `SELECT demo.temperature * 2 AS t2, demo1.name FROM demo2 LEFT JOIN demo ON demo2.id = demo.humidity AND demo.humidity > 10 LEFT JOIN demo1 ON demo2.id = demo1.name AND demo1.size > 3 GROUP BY TUMBLINGWINDOW(ss, 10)`,
+			views: []string{"v1", "vother"},
+		},
+		{
+			sql:   `SELECT c2f(temperature) FROM demo`,
+			exp:   `SELECT (temperature * 1.8 + 32) AS c2f FROM demo`,
+			funcs: []string{"c2f"},
+		},
+		{
+			sql:   `SELECT c2f(a) AS f FROM demo WHERE inRange(c2f(b), 10, 20)`,
+			exp:   `SELECT (a * 1.8 + 32) AS f FROM demo WHERE ((b * 1.8 + 32) >= 10 AND (b * 1.8 + 32) <= 20)`,
+			funcs: []string{"c2f", "inRange"},
+		},
+		{
+			sql:   `SELECT normalize(a) AS n FROM demo`,
+			exp:   `SELECT ((a * 1.8 + 32) / 100) AS n FROM demo`,
+			funcs: []string{"normalize", "c2f"},
+		},
+		{
+			sql:   `SELECT f FROM vfunc`,
+			exp:   `SELECT (demo.temperature * 1.8 + 32) AS f FROM demo`,
+			views: []string{"vfunc"},
+			funcs: []string{"c2f"},
+		},
+		{
+			sql: `SELECT nonexist FROM v1`,
+			err: "column nonexist is not found in view v1",
+		},
+		{
+			sql: `SELECT * FROM v1 INNER JOIN vother ON v1.humidity = vother.name GROUP BY TUMBLINGWINDOW(ss, 10)`,
+			err: "wildcard is not supported when joining views, please select the view columns explicitly",
+		},
+		{
+			sql: `SELECT t2 FROM v1 INNER JOIN vall ON v1.humidity = vall.t GROUP BY TUMBLINGWINDOW(ss, 10)`,
+			err: "stream demo is referred more than once after expanding the views",
+		},
+		{
+			sql: `SELECT a FROM vcycle`,
+			err: "view vcycle is nested too deep, please check if the views refer to each other",
+		},
+		{
+			sql: `SELECT name FROM demo2 FULL JOIN vother ON demo2.id = vother.name GROUP BY TUMBLINGWINDOW(ss, 10)`,
+			err: "view vother with condition cannot be used in FULL_JOIN",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt, err := NewParserWithSqlFunctions(strings.NewReader(tt.sql), funcs).Parse()
+			require.NoError(t, err)
+			result, err := ExpandStatement(stmt, getView, funcs)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.views, result.Views)
+			require.Equal(t, tt.funcs, result.Functions)
+			exp, err := NewParser(strings.NewReader(tt.exp)).Parse()
+			require.NoError(t, err)
+			require.Equal(t, fmt.Sprint(exp.Condition), fmt.Sprint(stmt.Condition))
+			require.Equal(t, exp.Sources, stmt.Sources)
+			require.Equal(t, exp.Joins, stmt.Joins)
+			for i, f := range exp.Fields {
+				require.Equal(t, f.GetName(), stmt.Fields[i].GetName())
+				require.Equal(t, fmt.Sprint(f.Expr), fmt.Sprint(stmt.Fields[i].Expr))
+			}
+		})
+	}
+}
+
+func TestExpandStatementWithSharedViews(t *testing.T) {
+	funcs, getView := expandTestGetters()
+	tests := []struct {
+		sql  string
+		exp  string
+		name string
+		view string
+		sub  string
+	}{
+		{
+			sql:  `SELECT t2 FROM v1 WHERE t2 > 5`,
+			exp:  `SELECT t2 FROM v1 WHERE t2 > 5`,
+			name: "v1",
+			view: "v1",
+			sub:  `SELECT demo.temperature * 2 AS t2, humidity FROM demo WHERE demo.humidity > 10`,
+		},
+		{
+			sql:  `SELECT n.t3 FROM vnest AS n`,
+			exp:  `SELECT n.t3 FROM n`,
+			name: "n",
+			view: "vnest",
+			sub:  `SELECT demo.temperature * 2 + 1 AS t3 FROM demo WHERE demo.humidity > 10 AND demo.temperature * 2 < 100`,
+		},
+		{
+			sql:  `SELECT c2f(f) AS ff FROM vfunc`,
+			exp:  `SELECT (f * 1.8 + 32) AS ff FROM vfunc`,
+			name: "vfunc",
+			view: "vfunc",
+			sub:  `SELECT (demo.temperature * 1.8 + 32) AS f FROM demo`,
+		},
+		{
+			sql: `SELECT v.t2, demo1.size FROM v1 AS v INNER JOIN demo1 ON v.humidity = demo1.h GROUP BY TUMBLINGWINDOW(ss, 10)`,
+			exp: `SELECT demo.temperature * 2 AS t2, demo1.size FROM demo INNER JOIN demo1 ON demo.humidity = demo1.h WHERE demo.humidity > 10 GROUP BY TUMBLINGWINDOW(ss, 10)`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt, err := NewParserWithSqlFunctions(strings.NewReader(tt.sql), funcs).Parse()
+			require.NoError(t, err)
+			_, err = ExpandStatementWithSharedViews(stmt, getView, funcs)
+			require.NoError(t, err)
+			exp, err := NewParser(strings.NewReader(tt.exp)).Parse()
+			require.NoError(t, err)
+			require.Equal(t, fmt.Sprint(exp.Condition), fmt.Sprint(stmt.Condition))
+			for i, f := range exp.Fields {
+				require.Equal(t, f.GetName(), stmt.Fields[i].GetName())
+				require.Equal(t, fmt.Sprint(f.Expr), fmt.Sprint(stmt.Fields[i].Expr))
+			}
+			table := stmt.Sources[0].(*ast.Table)
+			if tt.view == "" {
+				require.Equal(t, exp.Sources, stmt.Sources)
+				require.Equal(t, exp.Joins, stmt.Joins)
+				return
+			}
+			require.Equal(t, tt.name, table.Name)
+			require.Equal(t, tt.view, table.View)
+			sub, err := NewParser(strings.NewReader(tt.sub)).Parse()
+			require.NoError(t, err)
+			require.Equal(t, fmt.Sprint(sub.Condition), fmt.Sprint(table.Subquery.Condition))
+			require.Equal(t, sub.Sources, table.Subquery.Sources)
+			for i, f := range sub.Fields {
+				require.Equal(t, f.GetName(), table.Subquery.Fields[i].GetName())
+				require.Equal(t, fmt.Sprint(f.Expr), fmt.Sprint(table.Subquery.Fields[i].Expr))
+			}
+		})
+	}
+}
+
+func TestCloneExpr(t *testing.T) {
+	// The clone shares no node with the original
+	requireNotShared := func(origin, clone ast.Node) {
+		nodes := make(map[ast.Node]struct{})
+		ast.WalkFunc(origin, func(n ast.Node) bool {
+			if _, ok := n.(ast.Expr); ok {
+				nodes[n] = struct{}{}
+			}
+			return true
+		})
+		ast.WalkFunc(clone, func(n ast.Node) bool {
+			if _, ok := n.(ast.Expr); ok {
+				_, shared := nodes[n]
+				require.False(t, shared, "node %s is shared", n)
+			}
+			return true
+		})
+	}
+	stmt, err := NewParser(strings.NewReader(`SELECT CASE WHEN a > 1 THEN lag(b) OVER (PARTITION BY c WHEN d > 0) ELSE e->f[0] END AS g, h LIKE "x%", i BETWEEN 1 AND 2, j IN (1, 2) FROM demo`)).Parse()
+	require.NoError(t, err)
+	for _, f := range stmt.Fields {
+		c := cloneExpr(f.Expr)
+		require.Equal(t, f.Expr, c)
+		requireNotShared(f.Expr, c)
+	}
+	require.Nil(t, cloneExpr(nil))
+
+	stmt, err = NewParser(strings.NewReader(`SELECT t.a, count(*) FROM (SELECT a FROM demo WHERE b > 1) AS t INNER JOIN demo1 ON t.a = demo1.a GROUP BY t.a, TUMBLINGWINDOW(ss, 10) HAVING count(*) > 1 ORDER BY t.a LIMIT 5 UNION ALL SELECT a, count(*) FROM demo2 GROUP BY a, TUMBLINGWINDOW(ss, 10)`)).Parse()
+	require.NoError(t, err)
+	c := cloneSelect(stmt)
+	require.Equal(t, stmt, c)
+	requireNotShared(stmt, c)
+}
@@ -22,6 +22,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/golang-collections/collections/stack"
 
@@ -45,7 +46,9 @@ type Parser struct {
 	f           int    // anonymous field index number
 	fn          int    // function index number
 	clause      string
-	sourceNames []string // source names in the from/join clause
+	sourceNames []string                        // source names in the from/join clause
+	ctes        map[string]*ast.SelectStatement // common table expressions defined by the WITH clause
+	sqlFuncs    SqlFuncGetter                   // SQL functions which can be called
 }
 
 func (p *Parser) ParseCondition() (ast.Expr, error) {
@@ -122,6 +125,11 @@ func NewParserWithSources(r io.Reader, sources []string) *Parser {
 	return &Parser{s: NewScanner(r), sourceNames: sources}
 }
 
+// NewParserWithSqlFunctions creates a parser which accepts the calls of the SQL functions
+func NewParserWithSqlFunctions(r io.Reader, getFunc SqlFuncGetter) *Parser {
+	return &Parser{s: NewScanner(r), sqlFuncs: getFunc}
+}
+
 func (p *Parser) ParseQueries() ([]ast.SelectStatement, error) {
 	var stmts []ast.SelectStatement
 
@@ -149,14 +157,49 @@ func (p *Parser) ParseQueries() ([]ast.SelectStatement, error) {
 }
 
 func (p *Parser) Parse() (*ast.SelectStatement, error) {
-	selects := &ast.SelectStatement{}
-
+	p.ctes = nil
 	if tok, lit := p.scanIgnoreWhitespace(); tok == ast.EOF {
 		return nil, nil
-	} else if tok != ast.SELECT {
+	} else if tok == ast.IDENT && strings.ToUpper(lit) == ast.WITH {
+		if err := p.parseWith(); err != nil {
+			return nil, err
+		}
+	} else {
+		p.unscan()
+	}
+	selects, err := p.parseUnion()
+	if err != nil {
+		return nil, err
+	}
+	p.clause = ""
+	if tok, lit := p.scanIgnoreWhitespace(); tok == ast.SEMICOLON {
+		validateFields(selects, p.sourceNames)
+		p.unscan()
+		return selects, nil
+	} else if tok != ast.EOF {
+		return nil, fmt.Errorf("found %q, expected EOF.", lit)
+	}
+
+	if err := Validate(selects); err != nil {
+		return nil, err
+	}
+	validateFields(selects, p.sourceNames)
+	return selects, nil
+}
+
+// parseSelect parses the select statement from the SELECT keyword to the LIMIT clause
+func (p *Parser) parseSelect() (*ast.SelectStatement, error) {
+	selects := &ast.SelectStatement{}
+
+	if tok, lit := p.scanIgnoreWhitespace(); tok != ast.SELECT {
 		return nil, fmt.Errorf("Found %q, Expected SELECT.\n", lit)
 	}
 	p.clause = "select"
+	if distinctOn, err := p.parseDistinctOn(); err != nil {
+		return nil, err
+	} else {
+		selects.DistinctOn = distinctOn
+	}
 	if fields, err := p.parseFields(); err != nil {
 		return nil, err
 	} else {
@@ -193,6 +236,11 @@ func (p *Parser) Parse() (*ast.SelectStatement, error) {
 	} else {
 		selects.Dimensions = dims
 	}
+	if fill, err := p.parseFill(); err != nil {
+		return nil, err
+	} else {
+		selects.Fill = fill
+	}
 	p.clause = "having"
 	if having, err := p.parseHaving(); err != nil {
 		return nil, err
@@ -213,19 +261,6 @@ func (p *Parser) Parse() (*ast.SelectStatement, error) {
 			selects.Limit = expr
 		}
 	}
-	p.clause = ""
-	if tok, lit := p.scanIgnoreWhitespace(); tok == ast.SEMICOLON {
-		validateFields(selects, p.sourceNames)
-		p.unscan()
-		return selects, nil
-	} else if tok != ast.EOF {
-		return nil, fmt.Errorf("found %q, expected EOF.", lit)
-	}
-
-	if err := Validate(selects); err != nil {
-		return nil, err
-	}
-	validateFields(selects, p.sourceNames)
 	return selects, nil
 }
 
@@ -235,8 +270,21 @@ func (p *Parser) parseSource() (ast.Sources, error) {
 		return nil, fmt.Errorf("found %q, expected FROM.", lit)
 	}
 
+	if tok, _ := p.scanIgnoreWhitespace(); tok == ast.LPAREN {
+		sel, alias, err := p.parseDerivedTable()
+		if err != nil {
+			return nil, err
+		}
+		return append(sources, &ast.Table{Name: alias, Subquery: sel}), nil
+	}
+	p.unscan()
 	if src, alias, err := p.parseSourceLiteral(); err != nil {
 		return nil, err
+	} else if sel, ok := p.ctes[src]; ok {
+		if alias == "" {
+			alias = src
+		}
+		sources = append(sources, &ast.Table{Name: alias, Subquery: cloneSelect(sel)})
 	} else {
 		sources = append(sources, &ast.Table{Name: src, Alias: alias})
 	}
@@ -250,7 +298,7 @@ func (p *Parser) parseSourceLiteral() (string, string, error) {
 	var alias string
 	for {
 		// HASH, DIV & ADD token is specially support for MQTT topic name patterns.
-		if tok, lit := p.scanIgnoreWhitespace(); tok.AllowedSourceToken() {
+		if tok, lit := p.scanIgnoreWhitespace(); tok.AllowedSourceToken() && !isSourceEnd(tok, lit) {
 			sourceSeg = append(sourceSeg, lit)
 			if tok1, lit1 := p.scanIgnoreWhitespace(); tok1 == ast.AS {
 				if tok2, lit2 := p.scanIgnoreWhitespace(); tok2 == ast.IDENT {
@@ -258,7 +306,7 @@ func (p *Parser) parseSourceLiteral() (string, string, error) {
 				} else {
 					return "", "", fmt.Errorf("found %q, expected JOIN key word.", lit)
 				}
-			} else if tok1.AllowedSourceToken() {
+			} else if tok1.AllowedSourceToken() && !isSourceEnd(tok1, lit1) {
 				sourceSeg = append(sourceSeg, lit1)
 			} else {
 				p.unscan()
@@ -299,22 +347,33 @@ func (p *Parser) parseFieldNameSections(isSubField bool) ([]string, error) {
 func (p *Parser) parseJoins() (ast.Joins, error) {
 	var joins ast.Joins
 	for {
-		if tok, lit := p.scanIgnoreWhitespace(); tok == ast.INNER || tok == ast.LEFT || tok == ast.RIGHT || tok == ast.FULL || tok == ast.CROSS {
-			if tok1, _ := p.scanIgnoreWhitespace(); tok1 == ast.JOIN {
-				jt := ast.INNER_JOIN
-				switch tok {
-				case ast.INNER:
-					jt = ast.INNER_JOIN
-				case ast.LEFT:
-					jt = ast.LEFT_JOIN
-				case ast.RIGHT:
-					jt = ast.RIGHT_JOIN
-				case ast.FULL:
-					jt = ast.FULL_JOIN
-				case ast.CROSS:
-					jt = ast.CROSS_JOIN
+		if tok, lit := p.scanIgnoreWhitespace(); tok == ast.INNER || tok == ast.LEFT || tok == ast.RIGHT || tok == ast.FULL || tok == ast.CROSS || isSemiJoin(tok, lit) {
+			jt := ast.INNER_JOIN
+			switch tok {
+			case ast.INNER:
+				jt = ast.INNER_JOIN
+			case ast.LEFT:
+				jt = ast.LEFT_JOIN
+				// LEFT SEMI JOIN and LEFT ANTI JOIN
+				if tok2, lit2 := p.scanIgnoreWhitespace(); isSemiJoin(tok2, lit2) {
+					lit = lit2
+				} else {
+					p.unscan()
 				}
-
+			case ast.RIGHT:
+				jt = ast.RIGHT_JOIN
+			case ast.FULL:
+				jt = ast.FULL_JOIN
+			case ast.CROSS:
+				jt = ast.CROSS_JOIN
+			}
+			switch strings.ToUpper(lit) {
+			case ast.SEMI:
+				jt = ast.SEMI_JOIN
+			case ast.ANTI:
+				jt = ast.ANTI_JOIN
+			}
+			if tok1, _ := p.scanIgnoreWhitespace(); tok1 == ast.JOIN {
 				if j, err := p.ParseJoin(jt); err != nil {
 					return nil, err
 				} else {
@@ -333,9 +392,39 @@ func (p *Parser) parseJoins() (ast.Joins, error) {
 	}
 }
 
+// isSemiJoin checks the SEMI and ANTI join keywords. They are not reserved so that they can still be used as names.
+func isSemiJoin(tok ast.Token, lit string) bool {
+	if tok != ast.IDENT {
+		return false
+	}
+	u := strings.ToUpper(lit)
+	return u == ast.SEMI || u == ast.ANTI
+}
+
+// isSourceEnd checks the keywords which end the source name
+func isSourceEnd(tok ast.Token, lit string) bool {
+	return isUnion(tok, lit) || isSemiJoin(tok, lit)
+}
+
 func (p *Parser) ParseJoin(joinType ast.JoinType) (*ast.Join, error) {
 	j := &ast.Join{JoinType: joinType}
-	if src, alias, err := p.parseSourceLiteral(); err != nil {
+	var (
+		src, alias string
+		err        error
+	)
+	if tok, _ := p.scanIgnoreWhitespace(); tok == ast.LPAREN {
+		j.Subquery, src, err = p.parseDerivedTable()
+	} else {
+		p.unscan()
+		src, alias, err = p.parseSourceLiteral()
+		if sel, ok := p.ctes[src]; ok && err == nil {
+			if alias != "" {
+				src, alias = alias, ""
+			}
+			j.Subquery = cloneSelect(sel)
+		}
+	}
+	if err != nil {
 		return nil, err
 	} else {
 		j.Name = src
@@ -382,6 +471,84 @@ func (p *Parser) parseDimensions() (ast.Dimensions, error) {
 	return ds, nil
 }
 
+// parseFill parses the optional FILL(null|previous|linear|<literal>) clause after the window.
+// FILL is not a reserved keyword so that it can still be used as a field name.
+func (p *Parser) parseFill() (*ast.Fill, error) {
+	if tok, lit := p.scanIgnoreWhitespace(); tok != ast.IDENT || !strings.EqualFold(lit, "FILL") {
+		p.unscan()
+		return nil, nil
+	}
+	if tok, lit := p.scanIgnoreWhitespace(); tok != ast.LPAREN {
+		return nil, fmt.Errorf("found %q, expected ( after FILL.", lit)
+	}
+	fill := &ast.Fill{}
+	if tok, lit := p.scanIgnoreWhitespace(); tok == ast.IDENT {
+		ft, ok := ast.GetFillType(lit)
+		if !ok {
+			return nil, fmt.Errorf("invalid FILL type %s, expect null, previous, linear or a literal value", lit)
+		}
+		fill.FillType = ft
+	} else {
+		// The sign is parsed here so that the signed expressions like -(1) or +1 are accepted as literals
+		neg := false
+		switch tok {
+		case ast.SUB:
+			neg = true
+		case ast.ADD:
+		default:
+			p.unscan()
+		}
+		exp, err := p.ParseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if pe, ok := exp.(*ast.ParenExpr); ok {
+			exp = pe.Expr
+		}
+		fill.FillType = ast.FILL_VALUE
+		switch v := exp.(type) {
+		case *ast.IntegerLiteral:
+			fill.Value = v.Val
+			if neg {
+				fill.Value = -v.Val
+			}
+		case *ast.NumberLiteral:
+			fill.Value = v.Val
+			if neg {
+				fill.Value = -v.Val
+			}
+		case *ast.StringLiteral:
+			if neg {
+				return nil, fmt.Errorf("invalid FILL value -%s, expect a literal value", exp)
+			}
+			fill.Value = v.Val
+		case *ast.BooleanLiteral:
+			if neg {
+				return nil, fmt.Errorf("invalid FILL value -%s, expect a literal value", exp)
+			}
+			fill.Value = v.Val
+		default:
+			return nil, fmt.Errorf("invalid FILL value %s, expect a literal value", exp)
+		}
+	}
+	tok, lit := p.scanIgnoreWhitespace()
+	if tok == ast.COMMA {
+		if tok, lit = p.scanIgnoreWhitespace(); tok != ast.INTEGER {
+			return nil, fmt.Errorf("found %q, expected the integer TTL of FILL.", lit)
+		}
+		ttl, err := strconv.Atoi(lit)
+		if err != nil || ttl <= 0 {
+			return nil, fmt.Errorf("invalid FILL TTL %s, expect a positive integer", lit)
+		}
+		fill.TTL = ttl
+		tok, lit = p.scanIgnoreWhitespace()
+	}
+	if tok != ast.RPAREN {
+		return nil, fmt.Errorf("found %q, expected ) after FILL type.", lit)
+	}
+	return fill, nil
+}
+
 func (p *Parser) parseHaving() (ast.Expr, error) {
 	if tok, _ := p.scanIgnoreWhitespace(); tok != ast.HAVING {
 		p.unscan()
@@ -892,6 +1059,9 @@ func (p *Parser) parseCall(n string) (ast.Expr, error) {
 	// Check if n function exists and convert it to lowercase for built-in func
 	name, ok := convFuncName(n)
 	if !ok {
+		if paramCount, isSqlFunc := sqlFuncParamCount(p.sqlFuncs, n); isSqlFunc {
+			return p.parseSqlFunctionCall(n, paramCount)
+		}
 		return nil, fmt.Errorf("function %s not found", n)
 	}
 	p.inFunc = name
@@ -1171,8 +1341,12 @@ func (p *Parser) ParseCreateStmt() (ast.Statement, error) {
 			stmt.StreamType = ast.TypeStream
 		case ast.TABLE:
 			stmt.StreamType = ast.TypeTable
+		case ast.VIEW:
+			return p.parseCreateView()
+		case ast.FUNCTION:
+			return p.parseCreateFunction()
 		default:
-			return nil, fmt.Errorf("found %q, expected keyword stream or table.", lit1)
+			return nil, fmt.Errorf("found %q, expected keyword stream, table, view or function.", lit1)
 		}
 		if tok2, lit2 := p.scanIgnoreWhitespace(); tok2 == ast.IDENT {
 			stmt.Name = ast.StreamName(lit2)
@@ -1261,8 +1435,22 @@ func (p *Parser) parseShowStmt() (ast.Statement, error) {
 			} else {
 				return nil, fmt.Errorf("found %q, expected semecolon or EOF.", lit2)
 			}
+		case ast.VIEWS:
+			ss := &ast.ShowViewsStatement{}
+			if tok2, lit2 := p.scanIgnoreWhitespace(); tok2 == ast.EOF || tok2 == ast.SEMICOLON {
+				return ss, nil
+			} else {
+				return nil, fmt.Errorf("found %q, expected semecolon or EOF.", lit2)
+			}
+		case ast.FUNCTIONS:
+			ss := &ast.ShowFunctionsStatement{}
+			if tok2, lit2 := p.scanIgnoreWhitespace(); tok2 == ast.EOF || tok2 == ast.SEMICOLON {
+				return ss, nil
+			} else {
+				return nil, fmt.Errorf("found %q, expected semecolon or EOF.", lit2)
+			}
 		default:
-			return nil, fmt.Errorf("found %q, expected keyword streams or tables.", lit1)
+			return nil, fmt.Errorf("found %q, expected keyword streams, tables, views or functions.", lit1)
 		}
 	} else {
 		p.unscan()
@@ -1293,8 +1481,24 @@ func (p *Parser) parseDescribeStmt() (ast.Statement, error) {
 			} else {
 				return nil, fmt.Errorf("found %q, expected table name.", lit2)
 			}
+		case ast.VIEW:
+			dvs := &ast.DescribeViewStatement{}
+			if tok2, lit2 := p.scanIgnoreWhitespace(); tok2 == ast.IDENT {
+				dvs.Name = lit2
+				return dvs, nil
+			} else {
+				return nil, fmt.Errorf("found %q, expected view name.", lit2)
+			}
+		case ast.FUNCTION:
+			dfs := &ast.DescribeFunctionStatement{}
+			if tok2, lit2 := p.scanIgnoreWhitespace(); tok2 == ast.IDENT {
+				dfs.Name = lit2
+				return dfs, nil
+			} else {
+				return nil, fmt.Errorf("found %q, expected function name.", lit2)
+			}
 		default:
-			return nil, fmt.Errorf("found %q, expected keyword stream or table.", lit1)
+			return nil, fmt.Errorf("found %q, expected keyword stream, table, view or function.", lit1)
 		}
 	} else {
 		p.unscan()
@@ -1357,8 +1561,24 @@ func (p *Parser) parseDropStmt() (ast.Statement, error) {
 			} else {
 				return nil, fmt.Errorf("found %q, expected table name.", lit2)
 			}
+		case ast.VIEW:
+			dvs := &ast.DropViewStatement{}
+			if tok2, lit2 := p.scanIgnoreWhitespace(); tok2 == ast.IDENT {
+				dvs.Name = lit2
+				return dvs, nil
+			} else {
+				return nil, fmt.Errorf("found %q, expected view name.", lit2)
+			}
+		case ast.FUNCTION:
+			dfs := &ast.DropFunctionStatement{}
+			if tok2, lit2 := p.scanIgnoreWhitespace(); tok2 == ast.IDENT {
+				dfs.Name = lit2
+				return dfs, nil
+			} else {
+				return nil, fmt.Errorf("found %q, expected function name.", lit2)
+			}
 		default:
-			return nil, fmt.Errorf("found %q, expected keyword stream or table.", lit1)
+			return nil, fmt.Errorf("found %q, expected keyword stream, table, view or function.", lit1)
 		}
 	} else {
 		p.unscan()
@@ -1616,6 +1836,19 @@ func (p *Parser) parseStreamOptions() (*ast.Options, error) {
 							} else {
 								opts.Temp = val == "TRUE"
 							}
+						case ast.WATERMARK_DELAY, ast.IDLE_TIMEOUT:
+							if d, err := time.ParseDuration(lit3); err != nil || d < 0 {
+								return nil, fmt.Errorf("found %q, expect duration like 5s in %s option.", lit3, lit1)
+							}
+							v.Elem().FieldByName(lit1).SetString(lit3)
+						case ast.SCHEMA_COMPATIBILITY:
+							val := strings.ToUpper(lit3)
+							switch val {
+							case ast.SchemaCompatibilityNone, ast.SchemaCompatibilityBackward, ast.SchemaCompatibilityForward, ast.SchemaCompatibilityFull:
+								opts.SCHEMA_COMPATIBILITY = val
+							default:
+								return nil, fmt.Errorf("found %q, expect NONE/BACKWARD/FORWARD/FULL value in %s option.", lit3, lit1)
+							}
 						default:
 							f := v.Elem().FieldByName(lit1)
 							if f.IsValid() {
@@ -1638,7 +1871,7 @@ func (p *Parser) parseStreamOptions() (*ast.Options, error) {
 				}
 				return nil, fmt.Errorf("Parenthesis is not matched in options definition.")
 			} else {
-				return nil, fmt.Errorf("found %q, unknown option keys(DATASOURCE|FORMAT|KEY|CONF_KEY|SHARED|STRICT_VALIDATION|TYPE|TIMESTAMP|TIMESTAMP_FORMAT|RETAIN_SIZE|SCHEMAID|EXTRA|VERSION|TEMP|KIND|DELIMITER).", lit1)
+				return nil, fmt.Errorf("found %q, unknown option keys(DATASOURCE|FORMAT|KEY|CONF_KEY|SHARED|STRICT_VALIDATION|TYPE|TIMESTAMP|TIMESTAMP_FORMAT|RETAIN_SIZE|SCHEMAID|EXTRA|VERSION|TEMP|KIND|DELIMITER|WATERMARK_DELAY|IDLE_TIMEOUT|WATERMARK_KEY|ROWKIND_FIELD|SCHEMA_COMPATIBILITY).", lit1)
 			}
 		}
 	} else {
@@ -0,0 +1,87 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xsql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+// withinUnits are the time units of the WITHIN duration in milliseconds
+var withinUnits = map[string]int64{
+	"ms": 1,
+	"s":  1000,
+	"m":  60 * 1000,
+	"h":  60 * 60 * 1000,
+	"d":  24 * 60 * 60 * 1000,
+}
+
+// parseDistinctOn parses `DISTINCT ON (keys) [WITHIN duration]` after SELECT.
+// DISTINCT and WITHIN are not reserved so that they can still be used as field names.
+func (p *Parser) parseDistinctOn() (*ast.DistinctOn, error) {
+	if tok, lit := p.scanIgnoreWhitespace(); tok != ast.IDENT || !strings.EqualFold(lit, ast.DISTINCT) {
+		p.unscan()
+		return nil, nil
+	}
+	if tok, _ := p.scanIgnoreWhitespace(); tok != ast.ON {
+		p.unscan()
+		p.unscan()
+		return nil, nil
+	}
+	if tok, lit := p.scanIgnoreWhitespace(); tok != ast.LPAREN {
+		return nil, fmt.Errorf("found %q, expected ( after DISTINCT ON.", lit)
+	}
+	d := &ast.DistinctOn{}
+	for {
+		exp, err := p.ParseExpr()
+		if err != nil {
+			return nil, err
+		}
+		d.Keys = append(d.Keys, exp)
+		tok, lit := p.scanIgnoreWhitespace()
+		if tok == ast.RPAREN {
+			break
+		}
+		if tok != ast.COMMA {
+			return nil, fmt.Errorf("found %q, expected , or ) in DISTINCT ON.", lit)
+		}
+	}
+	if tok, lit := p.scanIgnoreWhitespace(); tok != ast.IDENT || !strings.EqualFold(lit, ast.WITHIN) {
+		p.unscan()
+		return d, nil
+	}
+	tok, lit := p.scanIgnoreWhitespace()
+	if tok != ast.INTEGER {
+		// within is a field name
+		p.unscan()
+		p.unscan()
+		return d, nil
+	}
+	v, err := strconv.ParseInt(lit, 10, 64)
+	if err != nil || v <= 0 {
+		return nil, fmt.Errorf("invalid WITHIN duration %s, expect a positive integer", lit)
+	}
+	// ms is scanned as a keyword of the window time units
+	_, unit := p.scanIgnoreWhitespace()
+	ms, ok := withinUnits[strings.ToLower(unit)]
+	if !ok {
+		return nil, fmt.Errorf("found %q, expected time unit ms, s, m, h or d after WITHIN %s.", unit, lit)
+	}
+	d.Within = v * ms
+	return d, nil
+}
@@ -0,0 +1,144 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xsql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+func TestParseDistinctOn(t *testing.T) {
+	tests := []struct {
+		s      string
+		fields ast.Fields
+		d      *ast.DistinctOn
+		err    string
+	}{
+		{
+			s:      `SELECT DISTINCT ON (id) WITHIN 10m id, temp FROM demo`,
+			fields: ast.Fields{{Name: "id", Expr: &ast.FieldRef{StreamName: ast.DefaultStream, Name: "id"}}, {Name: "temp", Expr: &ast.FieldRef{StreamName: ast.DefaultStream, Name: "temp"}}},
+			d:      &ast.DistinctOn{Keys: []ast.Expr{&ast.FieldRef{StreamName: ast.DefaultStream, Name: "id"}}, Within: 600000},
+		},
+		{
+			s:      `select distinct on (demo.id, lower(name)) within 500 ms * from demo`,
+			fields: ast.Fields{{Name: "*", Expr: &ast.Wildcard{Token: ast.ASTERISK}}},
+			d: &ast.DistinctOn{Keys: []ast.Expr{
+				&ast.FieldRef{StreamName: "demo", Name: "id"},
+				&ast.Call{Name: "lower", FuncType: ast.FuncTypeScalar, Args: []ast.Expr{&ast.FieldRef{StreamName: ast.DefaultStream, Name: "name"}}},
+			}, Within: 500},
+		},
+		{
+			s:      `SELECT DISTINCT ON (id) within FROM demo`,
+			fields: ast.Fields{{Name: "within", Expr: &ast.FieldRef{StreamName: ast.DefaultStream, Name: "within"}}},
+			d:      &ast.DistinctOn{Keys: []ast.Expr{&ast.FieldRef{StreamName: ast.DefaultStream, Name: "id"}}},
+		},
+		{
+			s:      `SELECT distinct FROM demo`,
+			fields: ast.Fields{{Name: "distinct", Expr: &ast.FieldRef{StreamName: ast.DefaultStream, Name: "distinct"}}},
+		},
+		{
+			s:   `SELECT DISTINCT ON id FROM demo`,
+			err: `found "id", expected ( after DISTINCT ON.`,
+		},
+		{
+			s:   `SELECT DISTINCT ON (id WITHIN 1s id FROM demo`,
+			err: `found "WITHIN", expected , or ) in DISTINCT ON.`,
+		},
+		{
+			s:   `SELECT DISTINCT ON (id) WITHIN 10 weeks id FROM demo`,
+			err: `found "weeks", expected time unit ms, s, m, h or d after WITHIN 10.`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			stmt, err := NewParser(strings.NewReader(tt.s)).Parse()
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.fields, stmt.Fields)
+			require.Equal(t, tt.d, stmt.DistinctOn)
+		})
+	}
+}
+
+func TestParseTopN(t *testing.T) {
+	tests := []struct {
+		s   string
+		n   int64
+		err string
+	}{
+		{
+			s: `SELECT * FROM demo WHERE ROW_NUMBER() OVER (PARTITION BY line ORDER BY temp DESC) <= 3 AND temp > 0`,
+			n: 3,
+		},
+		{
+			s: `SELECT * FROM demo WHERE temp > 0 AND (4 > row_number() OVER (ORDER BY temp))`,
+			n: 3,
+		},
+		{
+			s: `SELECT line, max(temp) FROM demo GROUP BY line, TumblingWindow(mi, 1) HAVING row_number() OVER (ORDER BY max(temp) DESC) = 1`,
+			n: 1,
+		},
+		{
+			s:   `SELECT * FROM demo WHERE row_number() OVER (PARTITION BY line) <= 3`,
+			err: "Top-N condition binaryExpr:{ Call:{ name:row_number } <= 3 } requires ORDER BY in the OVER clause",
+		},
+		{
+			s:   `SELECT * FROM demo WHERE row_number() OVER (ORDER BY temp) > 3`,
+			err: "Top-N condition binaryExpr:{ Call:{ name:row_number } > 3 } only supports <, <= or = 1",
+		},
+		{
+			s:   `SELECT * FROM demo WHERE row_number() OVER (ORDER BY temp) < 1`,
+			err: "Top-N condition binaryExpr:{ Call:{ name:row_number } < 1 } must keep at least one row",
+		},
+		{
+			s:   `SELECT * FROM demo WHERE row_number() OVER (ORDER BY temp) <= temp`,
+			err: "Top-N condition binaryExpr:{ Call:{ name:row_number } <= $$default.temp } must compare row_number with an integer",
+		},
+		{
+			s:   `SELECT * FROM demo WHERE row_number() OVER (ORDER BY temp) <= 3 OR temp > 0`,
+			err: "window functions can only be in select fields",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			stmt, err := NewParser(strings.NewReader(tt.s)).Parse()
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			cond := stmt.Condition
+			if stmt.Having != nil {
+				cond = stmt.Having
+			}
+			tn, rest, err := ExtractTopN(cond)
+			require.NoError(t, err)
+			require.Equal(t, tt.n, tn.N)
+			require.Equal(t, "row_number", tn.Call.Name)
+			if stmt.Having == nil {
+				require.Equal(t, "binaryExpr:{ $$default.temp > 0 }", rest.String())
+			} else {
+				require.Nil(t, rest)
+			}
+		})
+	}
+}
@@ -296,7 +296,7 @@ func TestParser_ParseCreateStream(t *testing.T) {
 				StreamFields: nil,
 				Options:      nil,
 			},
-			err: `found "SOURCES", unknown option keys(DATASOURCE|FORMAT|KEY|CONF_KEY|SHARED|STRICT_VALIDATION|TYPE|TIMESTAMP|TIMESTAMP_FORMAT|RETAIN_SIZE|SCHEMAID|EXTRA|VERSION|TEMP|KIND|DELIMITER).`,
+			err: `found "SOURCES", unknown option keys(DATASOURCE|FORMAT|KEY|CONF_KEY|SHARED|STRICT_VALIDATION|TYPE|TIMESTAMP|TIMESTAMP_FORMAT|RETAIN_SIZE|SCHEMAID|EXTRA|VERSION|TEMP|KIND|DELIMITER|WATERMARK_DELAY|IDLE_TIMEOUT|WATERMARK_KEY|ROWKIND_FIELD|SCHEMA_COMPATIBILITY).`,
 		},
 
 		{
@@ -471,6 +471,60 @@ func TestParser_ParseCreateStream(t *testing.T) {
 				},
 			},
 		},
+		{
+			s: `CREATE STREAM demo (
+				) WITH (DATASOURCE="users", FORMAT="JSON", TIMESTAMP="ts", WATERMARK_DELAY="5s", IDLE_TIMEOUT="1m", WATERMARK_KEY="partition");`,
+			stmt: &ast.StreamStmt{
+				Name:         ast.StreamName("demo"),
+				StreamFields: nil,
+				Options: &ast.Options{
+					DATASOURCE:      "users",
+					FORMAT:          "JSON",
+					TIMESTAMP:       "ts",
+					WATERMARK_DELAY: "5s",
+					IDLE_TIMEOUT:    "1m",
+					WATERMARK_KEY:   "partition",
+				},
+			},
+		},
+		{
+			s: `CREATE STREAM demo (
+				) WITH (DATASOURCE="users", FORMAT="JSON", IDLE_TIMEOUT="10");`,
+			stmt: nil,
+			err:  `found "10", expect duration like 5s in IDLE_TIMEOUT option.`,
+		},
+		{
+			s: `CREATE STREAM demo (
+				) WITH (DATASOURCE="users", FORMAT="JSON", KEY="id", ROWKIND_FIELD="op");`,
+			stmt: &ast.StreamStmt{
+				Name:         ast.StreamName("demo"),
+				StreamFields: nil,
+				Options: &ast.Options{
+					DATASOURCE:    "users",
+					FORMAT:        "JSON",
+					KEY:           "id",
+					ROWKIND_FIELD: "op",
+				},
+			},
+		},
+		{
+			s: `CREATE STREAM demo (
+				) WITH (DATASOURCE="users", SCHEMA_COMPATIBILITY="full");`,
+			stmt: &ast.StreamStmt{
+				Name:         ast.StreamName("demo"),
+				StreamFields: nil,
+				Options: &ast.Options{
+					DATASOURCE:           "users",
+					SCHEMA_COMPATIBILITY: "FULL",
+				},
+			},
+		},
+		{
+			s: `CREATE STREAM demo (
+				) WITH (DATASOURCE="users", SCHEMA_COMPATIBILITY="transitive");`,
+			stmt: nil,
+			err:  `found "transitive", expect NONE/BACKWARD/FORWARD/FULL value in SCHEMA_COMPATIBILITY option.`,
+		},
 		{
 			s: `CREATE STREAM demo (
 					USERID BIGINT DEFAULT 10,
@@ -0,0 +1,90 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xsql
+
+import (
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+// parseWith parses the common table expressions `name AS (SELECT ...)[, ...]`. The WITH keyword is consumed.
+// A common table expression can refer to the ones defined before it.
+func (p *Parser) parseWith() error {
+	ctes := make(map[string]*ast.SelectStatement)
+	p.ctes = ctes
+	for {
+		tok, name := p.scanIgnoreWhitespace()
+		if tok != ast.IDENT {
+			return fmt.Errorf("found %q, expected common table expression name.", name)
+		}
+		if _, ok := ctes[name]; ok {
+			return fmt.Errorf("common table expression %s is defined more than once", name)
+		}
+		if tok1, lit1 := p.scanIgnoreWhitespace(); tok1 != ast.AS {
+			return fmt.Errorf("found %q, expected AS.", lit1)
+		}
+		if tok2, lit2 := p.scanIgnoreWhitespace(); tok2 != ast.LPAREN {
+			return fmt.Errorf("found %q, expected (.", lit2)
+		}
+		sel, err := p.parseSubquery()
+		if err != nil {
+			return err
+		}
+		ctes[name] = sel
+		if tok3, _ := p.scanIgnoreWhitespace(); tok3 != ast.COMMA {
+			p.unscan()
+			return nil
+		}
+	}
+}
+
+// parseDerivedTable parses `(SELECT ...) [AS] alias` in the FROM or JOIN clause. The left parenthesis is consumed.
+func (p *Parser) parseDerivedTable() (*ast.SelectStatement, string, error) {
+	sel, err := p.parseSubquery()
+	if err != nil {
+		return nil, "", err
+	}
+	tok, lit := p.scanIgnoreWhitespace()
+	if tok == ast.AS {
+		tok, lit = p.scanIgnoreWhitespace()
+	}
+	if tok != ast.IDENT {
+		return nil, "", fmt.Errorf("found %q, expected alias of the subquery.", lit)
+	}
+	return sel, lit, nil
+}
+
+// parseSubquery parses the select statement in the parentheses. The left parenthesis is consumed.
+// The subquery has its own source names and anonymous field names.
+func (p *Parser) parseSubquery() (*ast.SelectStatement, error) {
+	clause, sourceNames, f := p.clause, p.sourceNames, p.f
+	p.sourceNames, p.f = nil, 0
+	defer func() {
+		p.clause, p.sourceNames, p.f = clause, sourceNames, f
+	}()
+	sel, err := p.parseUnion()
+	if err != nil {
+		return nil, err
+	}
+	if tok, lit := p.scanIgnoreWhitespace(); tok != ast.RPAREN {
+		return nil, fmt.Errorf("found %q, expected ).", lit)
+	}
+	if err := Validate(sel); err != nil {
+		return nil, err
+	}
+	validateFields(sel, p.sourceNames)
+	return sel, nil
+}
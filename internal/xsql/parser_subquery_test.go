@@ -0,0 +1,144 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xsql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+func TestParseSubquery(t *testing.T) {
+	inner := func() *ast.SelectStatement {
+		return &ast.SelectStatement{
+			Fields: []ast.Field{
+				{Name: "color", Expr: &ast.FieldRef{StreamName: ast.DefaultStream, Name: "color"}},
+				{Name: "abs", Expr: &ast.Call{Name: "abs", FuncType: ast.FuncTypeScalar, Args: []ast.Expr{&ast.FieldRef{StreamName: ast.DefaultStream, Name: "size"}}}},
+			},
+			Sources: []ast.Source{&ast.Table{Name: "demo"}},
+		}
+	}
+	tests := []struct {
+		s    string
+		stmt *ast.SelectStatement
+		err  string
+	}{
+		{
+			s: `SELECT t.color, abs FROM (SELECT color, abs(size) FROM demo) AS t`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{Name: "color", Expr: &ast.FieldRef{StreamName: "t", Name: "color"}},
+					{Name: "abs", Expr: &ast.FieldRef{StreamName: ast.DefaultStream, Name: "abs"}},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "t", Subquery: inner()}},
+			},
+		},
+		{
+			s: `WITH a AS (SELECT color, abs(size) FROM demo) SELECT abs(a.color) FROM a`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{Name: "abs", Expr: &ast.Call{Name: "abs", FuncType: ast.FuncTypeScalar, FuncId: 1, Args: []ast.Expr{&ast.FieldRef{StreamName: "a", Name: "color"}}}},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "a", Subquery: inner()}},
+			},
+		},
+		{
+			s: `with a AS (SELECT color, abs(size) FROM demo), b AS (SELECT color FROM a) SELECT color FROM b AS c`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{{Name: "color", Expr: &ast.FieldRef{StreamName: ast.DefaultStream, Name: "color"}}},
+				Sources: []ast.Source{&ast.Table{Name: "c", Subquery: &ast.SelectStatement{
+					Fields:  []ast.Field{{Name: "color", Expr: &ast.FieldRef{StreamName: ast.DefaultStream, Name: "color"}}},
+					Sources: []ast.Source{&ast.Table{Name: "a", Subquery: inner()}},
+				}}},
+			},
+		},
+		{
+			s: `WITH a AS (SELECT color, abs(size) FROM demo) SELECT x.color FROM a AS x INNER JOIN (SELECT color FROM demo1) y ON x.color = y.color GROUP BY TumblingWindow(ss, 10)`,
+			stmt: &ast.SelectStatement{
+				Fields:  []ast.Field{{Name: "color", Expr: &ast.FieldRef{StreamName: "x", Name: "color"}}},
+				Sources: []ast.Source{&ast.Table{Name: "x", Subquery: inner()}},
+				Joins: []ast.Join{{
+					Name:     "y",
+					JoinType: ast.INNER_JOIN,
+					Expr: &ast.BinaryExpr{
+						OP:  ast.EQ,
+						LHS: &ast.FieldRef{StreamName: "x", Name: "color"},
+						RHS: &ast.FieldRef{StreamName: "y", Name: "color"},
+					},
+					Subquery: &ast.SelectStatement{
+						Fields:  []ast.Field{{Name: "color", Expr: &ast.FieldRef{StreamName: ast.DefaultStream, Name: "color"}}},
+						Sources: []ast.Source{&ast.Table{Name: "demo1"}},
+					},
+				}},
+				Dimensions: ast.Dimensions{{Expr: &ast.Window{
+					WindowType: ast.TUMBLING_WINDOW,
+					TimeUnit:   &ast.TimeLiteral{Val: ast.SS},
+					Length:     &ast.IntegerLiteral{Val: 10},
+					Interval:   &ast.IntegerLiteral{Val: 0},
+					Delay:      &ast.IntegerLiteral{Val: 0},
+				}}},
+			},
+		},
+		{
+			s:   `SELECT * FROM (SELECT * FROM demo)`,
+			err: `found "EOF", expected alias of the subquery.`,
+		},
+		{
+			s:   `SELECT * FROM (SELECT * FROM demo WHERE a > 1 SELECT`,
+			err: `found "SELECT", expected ).`,
+		},
+		{
+			s:   `WITH a AS (SELECT * FROM demo), a AS (SELECT * FROM demo1) SELECT * FROM a`,
+			err: `common table expression a is defined more than once`,
+		},
+		{
+			s:   `WITH a AS SELECT * FROM demo SELECT * FROM a`,
+			err: `found "SELECT", expected (.`,
+		},
+		{
+			s:   `WITH a AS (SELECT * FROM demo)`,
+			err: "Found \"EOF\", Expected SELECT.\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			stmt, err := Language.Parse(NewParser(strings.NewReader(tt.s)))
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.stmt, stmt)
+			require.Equal(t, []string{"demo", "demo1"}[:len(GetStreams(tt.stmt))], GetStreams(tt.stmt))
+		})
+	}
+}
+
+// Each reference of a common table expression has its own copy
+func TestParseCommonTableExprReferredTwice(t *testing.T) {
+	stmt, err := GetStatementFromSql(`WITH a AS (SELECT color FROM demo WHERE size > 1) SELECT * FROM a AS x INNER JOIN a AS y ON x.color = y.color GROUP BY TumblingWindow(ss, 10)`)
+	require.NoError(t, err)
+	x := stmt.Sources[0].(*ast.Table)
+	require.Equal(t, "x", x.Name)
+	require.Equal(t, "y", stmt.Joins[0].Name)
+	require.Equal(t, x.Subquery, stmt.Joins[0].Subquery)
+	require.NotSame(t, x.Subquery, stmt.Joins[0].Subquery)
+	require.NotSame(t, x.Subquery.Condition, stmt.Joins[0].Subquery.Condition)
+	require.Equal(t, []string{"x", "y"}, GetSources(stmt))
+	require.Equal(t, []string{"demo", "demo"}, GetStreams(stmt))
+}
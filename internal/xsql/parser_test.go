@@ -3685,6 +3685,102 @@ func TestParser_ParseWindowsExpr(t *testing.T) {
 	}
 }
 
+func TestParser_ParseFill(t *testing.T) {
+	tests := []struct {
+		s    string
+		fill *ast.Fill
+		err  string
+	}{
+		{
+			s:    `SELECT avg(temperature) FROM demo GROUP BY TUMBLINGWINDOW(ss, 10) FILL(previous)`,
+			fill: &ast.Fill{FillType: ast.FILL_PREVIOUS},
+		},
+		{
+			s:    `SELECT deviceId, avg(temperature) FROM demo GROUP BY deviceId, HOPPINGWINDOW(ss, 10, 5) fill(LINEAR) HAVING count(*) > 1`,
+			fill: &ast.Fill{FillType: ast.FILL_LINEAR},
+		},
+		{
+			s:    `SELECT avg(temperature) FROM demo GROUP BY TUMBLINGWINDOW(ss, 10) FILL(null)`,
+			fill: &ast.Fill{FillType: ast.FILL_NULL},
+		},
+		{
+			s:    `SELECT avg(temperature) FROM demo GROUP BY TUMBLINGWINDOW(ss, 10) FILL(0)`,
+			fill: &ast.Fill{FillType: ast.FILL_VALUE, Value: int64(0)},
+		},
+		{
+			s:    `SELECT avg(temperature) FROM demo GROUP BY TUMBLINGWINDOW(ss, 10) FILL(-1.5)`,
+			fill: &ast.Fill{FillType: ast.FILL_VALUE, Value: -1.5},
+		},
+		{
+			s:    `SELECT avg(temperature) FROM demo GROUP BY TUMBLINGWINDOW(ss, 10) FILL(-1)`,
+			fill: &ast.Fill{FillType: ast.FILL_VALUE, Value: int64(-1)},
+		},
+		{
+			s:    `SELECT avg(temperature) FROM demo GROUP BY TUMBLINGWINDOW(ss, 10) FILL(-(2))`,
+			fill: &ast.Fill{FillType: ast.FILL_VALUE, Value: int64(-2)},
+		},
+		{
+			s:    `SELECT avg(temperature) FROM demo GROUP BY TUMBLINGWINDOW(ss, 10) FILL(+0.5)`,
+			fill: &ast.Fill{FillType: ast.FILL_VALUE, Value: 0.5},
+		},
+		{
+			s:    `SELECT avg(temperature) FROM demo GROUP BY TUMBLINGWINDOW(ss, 10) FILL(previous, 10)`,
+			fill: &ast.Fill{FillType: ast.FILL_PREVIOUS, TTL: 10},
+		},
+		{
+			s:    `SELECT avg(temperature) FROM demo GROUP BY TUMBLINGWINDOW(ss, 10) FILL(-1, 3)`,
+			fill: &ast.Fill{FillType: ast.FILL_VALUE, Value: int64(-1), TTL: 3},
+		},
+		{
+			s:    `SELECT avg(temperature) AS fill FROM demo GROUP BY TUMBLINGWINDOW(ss, 10)`,
+			fill: nil,
+		},
+		{
+			s:   `SELECT avg(temperature) FROM demo GROUP BY TUMBLINGWINDOW(ss, 10) FILL(next)`,
+			err: "invalid FILL type next, expect null, previous, linear or a literal value",
+		},
+		{
+			s:   `SELECT avg(temperature) FROM demo GROUP BY TUMBLINGWINDOW(ss, 10) FILL(1 + 1)`,
+			err: "invalid FILL value binaryExpr:{ 1 + 1 }, expect a literal value",
+		},
+		{
+			s:   `SELECT avg(temperature) FROM demo GROUP BY TUMBLINGWINDOW(ss, 10) FILL(-"a")`,
+			err: "invalid FILL value -a, expect a literal value",
+		},
+		{
+			s:   `SELECT avg(temperature) FROM demo GROUP BY TUMBLINGWINDOW(ss, 10) FILL(previous, 0)`,
+			err: "invalid FILL TTL 0, expect a positive integer",
+		},
+		{
+			s:   `SELECT avg(temperature) FROM demo GROUP BY TUMBLINGWINDOW(ss, 10) FILL(previous, x)`,
+			err: "found \"x\", expected the integer TTL of FILL.",
+		},
+		{
+			s:   `SELECT avg(temperature) FROM demo GROUP BY TUMBLINGWINDOW(ss, 10) FILL previous`,
+			err: "found \"previous\", expected ( after FILL.",
+		},
+		{
+			s:   `SELECT avg(temperature) FROM demo GROUP BY SLIDINGWINDOW(ss, 10) FILL(previous)`,
+			err: "FILL clause can only be used with tumbling window or hopping window",
+		},
+		{
+			s:   `SELECT temperature FROM demo GROUP BY TUMBLINGWINDOW(ss, 10) FILL(previous)`,
+			err: "FILL clause requires aggregate functions in select fields",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			stmt, err := NewParser(strings.NewReader(tt.s)).Parse()
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.fill, stmt.Fill)
+		})
+	}
+}
+
 func TestParser_ParseJsonExpr(t *testing.T) {
 	tests := []struct {
 		s    string
@@ -4543,3 +4639,36 @@ func TestParser_ParseLimit(t *testing.T) {
 		require.Equal(t, tt.stmt, stmt)
 	}
 }
+
+func TestParseSemiJoin(t *testing.T) {
+	tests := []struct {
+		s      string
+		source *ast.Table
+		join   ast.JoinType
+		err    string
+	}{
+		{s: `SELECT * FROM s1 SEMI JOIN s2 ON s1.a = s2.a`, source: &ast.Table{Name: "s1"}, join: ast.SEMI_JOIN},
+		{s: `SELECT * FROM s1 AS x left anti join s2 ON x.a = s2.a`, source: &ast.Table{Name: "s1", Alias: "x"}, join: ast.ANTI_JOIN},
+		{s: `SELECT * FROM s1 LEFT SEMI JOIN s2`, source: &ast.Table{Name: "s1"}, join: ast.SEMI_JOIN},
+		{s: `SELECT * FROM s1 ANTI s2`, err: `found "ANTI", expected JOIN key word.`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			stmt, err := NewParser(strings.NewReader(tt.s)).Parse()
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, ast.Sources{tt.source}, stmt.Sources)
+			assert.Len(t, stmt.Joins, 1)
+			assert.Equal(t, "s2", stmt.Joins[0].Name)
+			assert.Equal(t, tt.join, stmt.Joins[0].JoinType)
+		})
+	}
+	// SEMI and ANTI are not reserved
+	stmt, err := NewParser(strings.NewReader(`SELECT semi, anti FROM s1`)).Parse()
+	assert.NoError(t, err)
+	assert.Equal(t, "semi", stmt.Fields[0].Name)
+	assert.Equal(t, "anti", stmt.Fields[1].Name)
+}
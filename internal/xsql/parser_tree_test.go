@@ -146,7 +146,7 @@ func TestParser_ParseTree(t *testing.T) {
 		{
 			s:    `SHOW STREAMSf`,
 			stmt: nil,
-			err:  `found "STREAMSF", expected keyword streams or tables.`,
+			err:  `found "STREAMSF", expected keyword streams, tables, views or functions.`,
 		},
 
 		{
@@ -0,0 +1,67 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xsql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+// parseUnion parses the select statements combined by UNION ALL. The following select statements are saved
+// in the Unions of the first one.
+func (p *Parser) parseUnion() (*ast.SelectStatement, error) {
+	sel, err := p.parseSelect()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if tok, lit := p.scanIgnoreWhitespace(); !isUnion(tok, lit) {
+			p.unscan()
+			return sel, nil
+		}
+		if tok, lit := p.scanIgnoreWhitespace(); tok != ast.IDENT || strings.ToUpper(lit) != ast.ALL {
+			return nil, fmt.Errorf("found %q, expected ALL.", lit)
+		}
+		u, err := p.parseUnionSelect()
+		if err != nil {
+			return nil, err
+		}
+		sel.Unions = append(sel.Unions, u)
+	}
+}
+
+// parseUnionSelect parses a select statement after UNION ALL with its own source names and anonymous field names
+func (p *Parser) parseUnionSelect() (*ast.SelectStatement, error) {
+	clause, sourceNames, f := p.clause, p.sourceNames, p.f
+	p.sourceNames, p.f = nil, 0
+	defer func() {
+		p.clause, p.sourceNames, p.f = clause, sourceNames, f
+	}()
+	sel, err := p.parseSelect()
+	if err != nil {
+		return nil, err
+	}
+	if err := Validate(sel); err != nil {
+		return nil, err
+	}
+	validateFields(sel, p.sourceNames)
+	return sel, nil
+}
+
+func isUnion(tok ast.Token, lit string) bool {
+	return tok == ast.IDENT && strings.ToUpper(lit) == ast.UNION
+}
@@ -0,0 +1,109 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xsql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+func TestParseUnion(t *testing.T) {
+	tests := []struct {
+		s       string
+		stmt    *ast.SelectStatement
+		streams []string
+		err     string
+	}{
+		{
+			s: `SELECT a, abs(b) FROM s1 UNION ALL SELECT a, abs(b) FROM s2 AS x WHERE x.a > 1 union all SELECT * FROM s3`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{Name: "a", Expr: &ast.FieldRef{StreamName: ast.DefaultStream, Name: "a"}},
+					{Name: "abs", Expr: &ast.Call{Name: "abs", FuncType: ast.FuncTypeScalar, Args: []ast.Expr{&ast.FieldRef{StreamName: ast.DefaultStream, Name: "b"}}}},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "s1"}},
+				Unions: []*ast.SelectStatement{
+					{
+						Fields: []ast.Field{
+							{Name: "a", Expr: &ast.FieldRef{StreamName: ast.DefaultStream, Name: "a"}},
+							{Name: "abs", Expr: &ast.Call{Name: "abs", FuncType: ast.FuncTypeScalar, FuncId: 1, Args: []ast.Expr{&ast.FieldRef{StreamName: ast.DefaultStream, Name: "b"}}}},
+						},
+						Sources: []ast.Source{&ast.Table{Name: "s2", Alias: "x"}},
+						Condition: &ast.BinaryExpr{
+							OP:  ast.GT,
+							LHS: &ast.FieldRef{StreamName: "x", Name: "a"},
+							RHS: &ast.IntegerLiteral{Val: 1},
+						},
+					},
+					{
+						Fields:  []ast.Field{{Expr: &ast.Wildcard{Token: ast.ASTERISK}, Name: "*"}},
+						Sources: []ast.Source{&ast.Table{Name: "s3"}},
+					},
+				},
+			},
+			streams: []string{"s1", "s2", "s3"},
+		},
+		{
+			s: `SELECT count(*) AS c FROM (SELECT a FROM s1 UNION ALL SELECT a FROM s2) u GROUP BY TumblingWindow(ss, 10)`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{{Name: "count", AName: "c", Expr: &ast.Call{Name: "count", FuncType: ast.FuncTypeAgg, Args: []ast.Expr{&ast.Wildcard{Token: ast.ASTERISK}}}}},
+				Sources: []ast.Source{&ast.Table{Name: "u", Subquery: &ast.SelectStatement{
+					Fields:  []ast.Field{{Name: "a", Expr: &ast.FieldRef{StreamName: ast.DefaultStream, Name: "a"}}},
+					Sources: []ast.Source{&ast.Table{Name: "s1"}},
+					Unions: []*ast.SelectStatement{{
+						Fields:  []ast.Field{{Name: "a", Expr: &ast.FieldRef{StreamName: ast.DefaultStream, Name: "a"}}},
+						Sources: []ast.Source{&ast.Table{Name: "s2"}},
+					}},
+				}}},
+				Dimensions: ast.Dimensions{{Expr: &ast.Window{
+					WindowType: ast.TUMBLING_WINDOW,
+					TimeUnit:   &ast.TimeLiteral{Val: ast.SS},
+					Length:     &ast.IntegerLiteral{Val: 10},
+					Interval:   &ast.IntegerLiteral{Val: 0},
+					Delay:      &ast.IntegerLiteral{Val: 0},
+				}}},
+			},
+			streams: []string{"s1", "s2"},
+		},
+		{
+			s:   `SELECT a FROM s1 UNION SELECT a FROM s2`,
+			err: `found "SELECT", expected ALL.`,
+		},
+		{
+			s:   `SELECT a FROM s1 UNION ALL a FROM s2`,
+			err: "Found \"a\", Expected SELECT.\n",
+		},
+		{
+			s:   `SELECT a FROM s1 UNION ALL SELECT a`,
+			err: `found "EOF", expected FROM.`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			stmt, err := Language.Parse(NewParser(strings.NewReader(tt.s)))
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.stmt, stmt)
+			require.Equal(t, tt.streams, GetStreams(stmt.(*ast.SelectStatement)))
+		})
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xsql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+// parseCreateView parses `CREATE VIEW name AS SELECT ...`. The CREATE VIEW keywords are consumed.
+func (p *Parser) parseCreateView() (ast.Statement, error) {
+	tok, name := p.scanIgnoreWhitespace()
+	if tok != ast.IDENT {
+		return nil, fmt.Errorf("found %q, expected view name.", name)
+	}
+	if tok1, lit1 := p.scanIgnoreWhitespace(); tok1 != ast.AS {
+		return nil, fmt.Errorf("found %q, expected AS.", lit1)
+	}
+	sel, err := p.Parse()
+	if err != nil {
+		return nil, err
+	}
+	if sel == nil {
+		return nil, fmt.Errorf("found EOF, expected SELECT.")
+	}
+	stmt := &ast.ViewStmt{Name: name, Select: sel}
+	if err := ValidateView(stmt); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+// ValidateView checks if the select statement of a view can be expanded into the rules.
+// Only the filtered projection of a single stream without any state across rows is supported.
+func ValidateView(stmt *ast.ViewStmt) error {
+	sel := stmt.Select
+	if len(sel.Sources) != 1 || len(sel.Joins) > 0 {
+		return fmt.Errorf("view %s must select from exactly one stream without join", stmt.Name)
+	}
+	if t, ok := sel.Sources[0].(*ast.Table); ok && t.Subquery != nil {
+		return fmt.Errorf("view %s cannot select from a subquery", stmt.Name)
+	}
+	if len(sel.Unions) > 0 {
+		return fmt.Errorf("view %s cannot have UNION ALL", stmt.Name)
+	}
+	if sel.Dimensions != nil {
+		return fmt.Errorf("view %s cannot have GROUP BY or window", stmt.Name)
+	}
+	if sel.Having != nil || sel.SortFields != nil || sel.Limit != nil {
+		return fmt.Errorf("view %s cannot have HAVING, ORDER BY or LIMIT", stmt.Name)
+	}
+	var vErr error
+	ast.WalkFunc(sel, func(n ast.Node) bool {
+		switch e := n.(type) {
+		case *ast.Call:
+			switch e.FuncType {
+			case ast.FuncTypeAgg:
+				vErr = fmt.Errorf("view %s cannot have aggregate function %s", stmt.Name, e.Name)
+			case ast.FuncTypeCols, ast.FuncTypeSrf, ast.FuncTypeWindow, ast.FuncTypeTrigger:
+				vErr = fmt.Errorf("view %s does not support function %s", stmt.Name, e.Name)
+			}
+		case *ast.Wildcard:
+			if len(e.Except) > 0 || len(e.Replace) > 0 {
+				vErr = fmt.Errorf("view %s does not support wildcard with EXCEPT or REPLACE", stmt.Name)
+			}
+		}
+		return vErr == nil
+	})
+	return vErr
+}
+
+// parseCreateFunction parses `CREATE FUNCTION name(param1, param2) AS expr`. The CREATE FUNCTION keywords are consumed.
+func (p *Parser) parseCreateFunction() (ast.Statement, error) {
+	stmt, err := p.parseFunctionSignature()
+	if err != nil {
+		return nil, err
+	}
+	body, err := p.ParseExpr()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Body = body
+	if tok5, lit5 := p.scanIgnoreWhitespace(); tok5 == ast.SEMICOLON {
+		p.unscan()
+	} else if tok5 != ast.EOF {
+		return nil, fmt.Errorf("found %q, expected semicolon or EOF.", lit5)
+	}
+	if err := validateFunction(stmt); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+// parseFunctionSignature parses `name(param1, param2) AS` of the CREATE FUNCTION statement
+func (p *Parser) parseFunctionSignature() (*ast.FunctionStmt, error) {
+	tok, name := p.scanIgnoreWhitespace()
+	if tok != ast.IDENT {
+		return nil, fmt.Errorf("found %q, expected function name.", name)
+	}
+	if _, ok := convFuncName(name); ok {
+		return nil, fmt.Errorf("function %s already exists", name)
+	}
+	if tok1, lit1 := p.scanIgnoreWhitespace(); tok1 != ast.LPAREN {
+		return nil, fmt.Errorf("found %q, expected (.", lit1)
+	}
+	stmt := &ast.FunctionStmt{Name: name}
+	for {
+		tok2, lit2 := p.scanIgnoreWhitespace()
+		if tok2 == ast.RPAREN && len(stmt.Params) == 0 {
+			break
+		}
+		if tok2 != ast.IDENT {
+			return nil, fmt.Errorf("found %q, expected parameter name.", lit2)
+		}
+		for _, param := range stmt.Params {
+			if param == lit2 {
+				return nil, fmt.Errorf("duplicate parameter %s", lit2)
+			}
+		}
+		stmt.Params = append(stmt.Params, lit2)
+		if tok3, lit3 := p.scanIgnoreWhitespace(); tok3 == ast.RPAREN {
+			break
+		} else if tok3 != ast.COMMA {
+			return nil, fmt.Errorf("found %q, expected , or ).", lit3)
+		}
+	}
+	if tok4, lit4 := p.scanIgnoreWhitespace(); tok4 != ast.AS {
+		return nil, fmt.Errorf("found %q, expected AS.", lit4)
+	}
+	return stmt, nil
+}
+
+func validateFunction(stmt *ast.FunctionStmt) error {
+	var vErr error
+	ast.WalkFunc(stmt.Body, func(n ast.Node) bool {
+		switch e := n.(type) {
+		case *ast.FieldRef:
+			if e.StreamName != ast.DefaultStream || !contains(stmt.Params, e.Name) {
+				vErr = fmt.Errorf("function %s can only refer to its parameters but got %s", stmt.Name, e.Name)
+			}
+		case *ast.Wildcard:
+			vErr = fmt.Errorf("function %s cannot refer to wildcard", stmt.Name)
+		case *ast.Window:
+			vErr = fmt.Errorf("function %s cannot have window", stmt.Name)
+		case *ast.Call:
+			switch e.FuncType {
+			case ast.FuncTypeCols, ast.FuncTypeSrf, ast.FuncTypeWindow, ast.FuncTypeTrigger:
+				vErr = fmt.Errorf("function %s does not support function %s", stmt.Name, e.Name)
+			}
+		}
+		return vErr == nil
+	})
+	return vErr
+}
+
+// parseSqlFunctionCall parses the arguments of a SQL function call. The function name and left paren are consumed.
+func (p *Parser) parseSqlFunctionCall(n string, paramCount int) (ast.Expr, error) {
+	var args []ast.Expr
+	for {
+		if tok, _ := p.scanIgnoreWhitespace(); tok == ast.RPAREN {
+			break
+		}
+		p.unscan()
+		exp, err := p.ParseExpr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, exp)
+		if tok, lit := p.scanIgnoreWhitespace(); tok != ast.COMMA {
+			if tok != ast.RPAREN {
+				return nil, fmt.Errorf("found function call %q, expected ), but with %q.", n, lit)
+			}
+			break
+		}
+	}
+	if len(args) != paramCount {
+		return nil, fmt.Errorf("function %s expects %d arguments but got %d", n, paramCount, len(args))
+	}
+	c := &ast.Call{Name: strings.ToLower(n), Args: args, FuncId: p.fn, FuncType: ast.FuncTypeScalar}
+	p.fn += 1
+	return c, nil
+}
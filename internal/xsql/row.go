@@ -337,6 +337,7 @@ type Tuple struct {
 	Timestamp time.Time
 	Metadata  Metadata // immutable
 	Props     map[string]string
+	Changelog
 
 	AffiliateRow
 	lock      syncx.Mutex            // lock for the cachedMap, because it is possible to access by multiple sinks
@@ -369,6 +370,7 @@ var (
 type JoinTuple struct {
 	Ctx    api.StreamContext
 	Tuples []Row // The content is immutable, but the slice may be added or removed
+	Changelog
 	AffiliateRow
 	lock      syncx.Mutex
 	cachedMap map[string]interface{} // clone of the row and cached for performance of toMap
@@ -389,6 +391,7 @@ type GroupedTuples struct {
 	Ctx     api.StreamContext
 	Content []Row
 	*WindowRange
+	Changelog
 	AffiliateRow
 	lock      syncx.Mutex
 	cachedMap map[string]interface{} // clone of the row and cached for performance of toMap
@@ -488,6 +491,7 @@ func (t *Tuple) Clone() Row {
 		Timestamp:    t.Timestamp,
 		Message:      t.Message,
 		Metadata:     t.Metadata,
+		Changelog:    t.Changelog,
 		AffiliateRow: t.AffiliateRow.Clone(),
 	}
 }
@@ -684,6 +688,7 @@ func (jt *JoinTuple) Clone() Row {
 	}
 	c := &JoinTuple{
 		Tuples:       ts,
+		Changelog:    jt.Changelog,
 		AffiliateRow: jt.AffiliateRow.Clone(),
 	}
 	return c
@@ -776,6 +781,7 @@ func (s *GroupedTuples) Clone() Row {
 	c := &GroupedTuples{
 		Content:      ts,
 		WindowRange:  s.WindowRange,
+		Changelog:    s.Changelog,
 		AffiliateRow: s.AffiliateRow.Clone(),
 	}
 	return c
@@ -38,11 +38,37 @@ func Validate(stmt *ast.SelectStatement) error {
 	if err := validateWindowFunction(stmt); err != nil {
 		return err
 	}
+	if err := validateFill(stmt); err != nil {
+		return err
+	}
 	return validateSRFForbidden(stmt)
 }
 
+func validateFill(stmt *ast.SelectStatement) error {
+	if stmt.Fill == nil {
+		return nil
+	}
+	w := stmt.Dimensions.GetWindow()
+	if w == nil || (w.WindowType != ast.TUMBLING_WINDOW && w.WindowType != ast.HOPPING_WINDOW) {
+		return fmt.Errorf("FILL clause can only be used with tumbling window or hopping window")
+	}
+	if !WithAggFields(stmt) {
+		return fmt.Errorf("FILL clause requires aggregate functions in select fields")
+	}
+	return nil
+}
+
 func validateWindowFunction(stmt *ast.SelectStatement) error {
-	if exists := isWindowFunctionExists(stmt); exists {
+	// The Top-N condition of WHERE and HAVING is the only window function outside the select fields
+	s := *stmt
+	for _, cond := range []*ast.Expr{&s.Condition, &s.Having} {
+		_, rest, err := ExtractTopN(*cond)
+		if err != nil {
+			return err
+		}
+		*cond = rest
+	}
+	if exists := isWindowFunctionExists(&s); exists {
 		return fmt.Errorf("window functions can only be in select fields")
 	}
 	return nil
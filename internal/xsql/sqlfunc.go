@@ -0,0 +1,74 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xsql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+// SqlFuncGetter returns the CREATE FUNCTION statement of the SQL function by case-insensitive name.
+// ok is false if the name is not a SQL function. The stream processor provides it from the stored definitions,
+// and it is passed to the parser to accept the calls of the SQL functions and to the expander to expand them.
+type SqlFuncGetter func(name string) (statement string, ok bool)
+
+// sqlFuncParamCount returns the number of the parameters of the SQL function. Only the signature is parsed,
+// so that the functions calling each other do not parse each other recursively.
+func sqlFuncParamCount(getFunc SqlFuncGetter, name string) (int, bool) {
+	if getFunc == nil {
+		return 0, false
+	}
+	statement, ok := getFunc(name)
+	if !ok {
+		return 0, false
+	}
+	p := NewParser(strings.NewReader(statement))
+	if _, lit := p.scanIgnoreWhitespace(); !strings.EqualFold(lit, ast.CREATE) {
+		return 0, false
+	}
+	if _, lit := p.scanIgnoreWhitespace(); !strings.EqualFold(lit, ast.FUNCTION) {
+		return 0, false
+	}
+	stmt, err := p.parseFunctionSignature()
+	if err != nil {
+		return 0, false
+	}
+	return len(stmt.Params), true
+}
+
+// getSqlFunction parses the function definition again to get a brand-new body to be expanded
+func getSqlFunction(getFunc SqlFuncGetter, name string) (*ast.FunctionStmt, error) {
+	var (
+		statement string
+		ok        bool
+	)
+	if getFunc != nil {
+		statement, ok = getFunc(name)
+	}
+	if !ok {
+		return nil, fmt.Errorf("function %s not found", name)
+	}
+	stmt, err := Language.Parse(NewParserWithSqlFunctions(strings.NewReader(statement), getFunc))
+	if err != nil {
+		return nil, fmt.Errorf("fail to parse function %s: %v", name, err)
+	}
+	fs, ok := stmt.(*ast.FunctionStmt)
+	if !ok {
+		return nil, fmt.Errorf("Error resolving the function %s, the data in db may be corrupted.", name)
+	}
+	return fs, nil
+}
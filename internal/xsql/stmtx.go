@@ -25,6 +25,8 @@ import (
 	"github.com/lf-edge/ekuiper/v2/pkg/kv"
 )
 
+// GetStreams returns the streams and tables which the statement reads from, including the ones in the subqueries
+// and the select statements combined by UNION ALL
 func GetStreams(stmt *ast.SelectStatement) (result []string) {
 	if stmt == nil {
 		return nil
@@ -32,10 +34,38 @@ func GetStreams(stmt *ast.SelectStatement) (result []string) {
 	// TODO sources must be a stream
 	for _, source := range stmt.Sources {
 		if s, ok := source.(*ast.Table); ok {
-			result = append(result, s.Name)
+			if s.Subquery != nil {
+				result = append(result, GetStreams(s.Subquery)...)
+			} else {
+				result = append(result, s.Name)
+			}
 		}
 	}
 
+	for _, join := range stmt.Joins {
+		if join.Subquery != nil {
+			result = append(result, GetStreams(join.Subquery)...)
+		} else {
+			result = append(result, join.Name)
+		}
+	}
+	for _, u := range stmt.Unions {
+		result = append(result, GetStreams(u)...)
+	}
+	return
+}
+
+// GetSources returns the names of the streams, tables and derived tables in the FROM and JOIN clauses of the statement
+// without descending into the subqueries
+func GetSources(stmt *ast.SelectStatement) (result []string) {
+	if stmt == nil {
+		return nil
+	}
+	for _, source := range stmt.Sources {
+		if s, ok := source.(*ast.Table); ok {
+			result = append(result, s.Name)
+		}
+	}
 	for _, join := range stmt.Joins {
 		result = append(result, join.Name)
 	}
@@ -43,12 +73,17 @@ func GetStreams(stmt *ast.SelectStatement) (result []string) {
 }
 
 func GetStatementFromSql(sql string) (stmt *ast.SelectStatement, err error) {
+	return GetStatementWithSqlFunctions(sql, nil)
+}
+
+// GetStatementWithSqlFunctions parses the select statement which may call the SQL functions
+func GetStatementWithSqlFunctions(sql string, getFunc SqlFuncGetter) (stmt *ast.SelectStatement, err error) {
 	defer func() {
 		if err != nil {
 			err = errorx.NewWithCode(errorx.ParserError, err.Error())
 		}
 	}()
-	parser := NewParser(strings.NewReader(sql))
+	parser := NewParserWithSqlFunctions(strings.NewReader(sql), getFunc)
 	if stmt, err := Language.Parse(parser); err != nil {
 		return nil, fmt.Errorf("Parse SQL %s error: %s.", sql, err)
 	} else {
@@ -0,0 +1,117 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xsql
+
+import (
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+// TopN is the condition `ROW_NUMBER() OVER ([PARTITION BY k] ORDER BY v) <= N` in the WHERE or HAVING clause.
+// It keeps the first N rows of each partition.
+type TopN struct {
+	// Call is the row_number call with the OVER clause
+	Call *ast.Call
+	N    int64
+}
+
+// ExtractTopN finds the Top-N conjunct of the condition and returns it with the remaining condition.
+// The row_number can be called directly or referred by its alias in the select fields.
+func ExtractTopN(cond ast.Expr) (*TopN, ast.Expr, error) {
+	switch e := cond.(type) {
+	case *ast.ParenExpr:
+		tn, rest, err := ExtractTopN(e.Expr)
+		if tn == nil || err != nil {
+			return tn, cond, err
+		}
+		if rest == nil {
+			return tn, nil, nil
+		}
+		return tn, &ast.ParenExpr{Expr: rest}, nil
+	case *ast.BinaryExpr:
+		if e.OP == ast.AND {
+			tn, lhs, err := ExtractTopN(e.LHS)
+			if tn != nil || err != nil {
+				return tn, andExpr(lhs, e.RHS), err
+			}
+			tn, rhs, err := ExtractTopN(e.RHS)
+			if tn != nil || err != nil {
+				return tn, andExpr(e.LHS, rhs), err
+			}
+			return nil, cond, nil
+		}
+		tn, err := topNOf(e)
+		if tn != nil || err != nil {
+			return tn, nil, err
+		}
+	}
+	return nil, cond, nil
+}
+
+// topNOf checks the comparison of row_number and an integer like `row_number() OVER (...) < 3` or `3 >= rn`
+func topNOf(e *ast.BinaryExpr) (*TopN, error) {
+	op := e.OP
+	call, lit := rowNumberCall(e.LHS), e.RHS
+	if call == nil {
+		call, lit = rowNumberCall(e.RHS), e.LHS
+		switch op {
+		case ast.GT:
+			op = ast.LT
+		case ast.GTE:
+			op = ast.LTE
+		}
+	}
+	if call == nil {
+		return nil, nil
+	}
+	il, ok := lit.(*ast.IntegerLiteral)
+	if !ok {
+		return nil, fmt.Errorf("Top-N condition %s must compare row_number with an integer", e)
+	}
+	tn := &TopN{Call: call}
+	switch {
+	case op == ast.LT:
+		tn.N = il.Val - 1
+	case op == ast.LTE:
+		tn.N = il.Val
+	case op == ast.EQ && il.Val == 1:
+		tn.N = 1
+	default:
+		return nil, fmt.Errorf("Top-N condition %s only supports <, <= or = 1", e)
+	}
+	if tn.N < 1 {
+		return nil, fmt.Errorf("Top-N condition %s must keep at least one row", e)
+	}
+	if len(call.SortFields) == 0 {
+		return nil, fmt.Errorf("Top-N condition %s requires ORDER BY in the OVER clause", e)
+	}
+	return tn, nil
+}
+
+// rowNumberCall returns the row_number call with the OVER clause. The call without OVER is not a Top-N condition.
+func rowNumberCall(expr ast.Expr) *ast.Call {
+	switch e := expr.(type) {
+	case *ast.Call:
+		if e.Name == "row_number" && e.FuncType == ast.FuncTypeWindow && (e.Partition != nil || len(e.SortFields) > 0) {
+			return e
+		}
+	case *ast.FieldRef:
+		if e.IsAlias() {
+			return rowNumberCall(e.AliasRef.Expression)
+		}
+	}
+	return nil
+}
@@ -36,6 +36,15 @@ const (
 	StreamKindScan   = "scan"
 )
 
+// The compatibility modes of the stream schema change. BACKWARD means the new schema can read the data of the old
+// schema, FORWARD means the old schema can read the data of the new schema and FULL means both.
+const (
+	SchemaCompatibilityNone     = "NONE"
+	SchemaCompatibilityBackward = "BACKWARD"
+	SchemaCompatibilityForward  = "FORWARD"
+	SchemaCompatibilityFull     = "FULL"
+)
+
 type StreamType int
 
 type StreamStmt struct {
@@ -291,6 +300,14 @@ type Options struct {
 	KIND string `json:"kind,omitempty"`
 	// for delimited format only
 	DELIMITER string `json:"delimiter,omitempty"`
+	// watermark strategy for event time rules
+	WATERMARK_DELAY string `json:"watermarkDelay,omitempty"`
+	IDLE_TIMEOUT    string `json:"idleTimeout,omitempty"`
+	WATERMARK_KEY   string `json:"watermarkKey,omitempty"`
+	// the field of the row kind for the changelog stream, the KEY is the primary key of the rows
+	ROWKIND_FIELD string `json:"rowkindField,omitempty"`
+	// the compatibility mode to check when the schema changes: NONE, BACKWARD, FORWARD or FULL
+	SCHEMA_COMPATIBILITY string `json:"schemaCompatibility,omitempty"`
 
 	RuleID       string                      `json:"-"`
 	Schema       map[string]*JsonStreamField `json:"-"`
@@ -14,7 +14,11 @@
 
 package ast
 
-import "strconv"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 type Statement interface {
 	stmt()
@@ -23,6 +27,7 @@ type Statement interface {
 
 type SelectStatement struct {
 	Fields     Fields
+	DistinctOn *DistinctOn
 	Sources    Sources
 	Joins      Joins
 	Condition  Expr
@@ -30,6 +35,9 @@ type SelectStatement struct {
 	Dimensions Dimensions
 	Having     Expr
 	SortFields SortFields
+	Fill       *Fill
+	// Unions are the select statements combined with this one by UNION ALL
+	Unions []*SelectStatement
 
 	Statement
 }
@@ -102,6 +110,11 @@ type Source interface {
 type Table struct {
 	Name  string
 	Alias string
+	// Subquery is the select statement of a derived table or a common table expression. The Name is its alias.
+	Subquery *SelectStatement
+	// View is the name of the view which the derived table is expanded from. The rules selecting from the same view
+	// share the computation of the view.
+	View string
 	Source
 }
 
@@ -113,6 +126,10 @@ const (
 	RIGHT_JOIN
 	FULL_JOIN
 	CROSS_JOIN
+	// SEMI_JOIN outputs the left rows which have a matched row in the right stream
+	SEMI_JOIN
+	// ANTI_JOIN outputs the left rows which do not have a matched row in the right stream
+	ANTI_JOIN
 )
 
 func (j JoinType) String() string {
@@ -127,6 +144,10 @@ func (j JoinType) String() string {
 		return "FULL_JOIN"
 	case CROSS_JOIN:
 		return "CROSS_JOIN"
+	case SEMI_JOIN:
+		return "SEMI_JOIN"
+	case ANTI_JOIN:
+		return "ANTI_JOIN"
 	default:
 		return ""
 	}
@@ -137,6 +158,8 @@ type Join struct {
 	Alias    string
 	JoinType JoinType
 	Expr     Expr
+	// Subquery is the select statement of a derived table or a common table expression. The Name is its alias.
+	Subquery *SelectStatement
 
 	Node
 }
@@ -219,6 +242,83 @@ type Window struct {
 	Expr
 }
 
+type FillType int
+
+const (
+	FILL_NULL FillType = iota
+	FILL_PREVIOUS
+	FILL_LINEAR
+	FILL_VALUE
+)
+
+var fillTypes = map[string]FillType{
+	"null":     FILL_NULL,
+	"previous": FILL_PREVIOUS,
+	"linear":   FILL_LINEAR,
+}
+
+// GetFillType returns the fill type of the case-insensitive mode name like previous
+func GetFillType(name string) (FillType, bool) {
+	t, ok := fillTypes[strings.ToLower(name)]
+	return t, ok
+}
+
+func (f FillType) String() string {
+	switch f {
+	case FILL_NULL:
+		return "null"
+	case FILL_PREVIOUS:
+		return "previous"
+	case FILL_LINEAR:
+		return "linear"
+	case FILL_VALUE:
+		return "value"
+	}
+	return ""
+}
+
+// Fill is the FILL clause after the time window. It generates the results of the groups
+// which have no data in a window so that the output series is evenly spaced.
+type Fill struct {
+	FillType FillType
+	// Value is the constant to fill for FILL_VALUE type
+	Value interface{}
+	// TTL is the number of the consecutive windows to fill a series without data before it is evicted.
+	// 0 means the default TTL.
+	TTL int
+}
+
+func (f *Fill) String() string {
+	var info string
+	if f.FillType == FILL_VALUE {
+		info = fmt.Sprintf("value:%v", f.Value)
+	} else {
+		info = f.FillType.String()
+	}
+	if f.TTL > 0 {
+		info += fmt.Sprintf(", ttl:%d", f.TTL)
+	}
+	return "fill:{ " + info + " }"
+}
+
+// DistinctOn is the `DISTINCT ON (keys) [WITHIN duration]` clause after SELECT. It keeps the first row of each key.
+// If Within is set, the duplicated rows of a key are dropped until the duration since its first row passes.
+type DistinctOn struct {
+	Keys []Expr
+	// Within is the deduplication horizon in milliseconds, 0 means the rows are only deduplicated in a window
+	Within int64
+}
+
+func (d *DistinctOn) node() {}
+
+func (d *DistinctOn) String() string {
+	keys := make([]string, 0, len(d.Keys))
+	for _, k := range d.Keys {
+		keys = append(keys, k.String())
+	}
+	return "distinctOn:{ keys:[" + strings.Join(keys, ", ") + "], within:" + strconv.FormatInt(d.Within, 10) + " }"
+}
+
 type SortField struct {
 	Name       string
 	StreamName StreamName
@@ -221,24 +221,39 @@ const (
 	TABLE      = "TABLE"
 	STREAMS    = "STREAMS"
 	TABLES     = "TABLES"
+	VIEW       = "VIEW"
+	VIEWS      = "VIEWS"
+	FUNCTION   = "FUNCTION"
+	FUNCTIONS  = "FUNCTIONS"
 	WITH       = "WITH"
-
-	DATASOURCE        = "DATASOURCE"
-	KEY               = "KEY"
-	FORMAT            = "FORMAT"
-	CONF_KEY          = "CONF_KEY"
-	TYPE              = "TYPE"
-	STRICT_VALIDATION = "STRICT_VALIDATION"
-	TIMESTAMP         = "TIMESTAMP"
-	TIMESTAMP_FORMAT  = "TIMESTAMP_FORMAT"
-	RETAIN_SIZE       = "RETAIN_SIZE"
-	SHARED            = "SHARED"
-	SCHEMAID          = "SCHEMAID"
-	KIND              = "KIND"
-	DELIMITER         = "DELIMITER"
-	VERSION           = "VERSION"
-	EXTRA             = "EXTRA"
-	TEMP              = "TEMP"
+	UNION      = "UNION"
+	ALL        = "ALL"
+	SEMI       = "SEMI"
+	ANTI       = "ANTI"
+	DISTINCT   = "DISTINCT"
+	WITHIN     = "WITHIN"
+
+	DATASOURCE           = "DATASOURCE"
+	KEY                  = "KEY"
+	FORMAT               = "FORMAT"
+	CONF_KEY             = "CONF_KEY"
+	TYPE                 = "TYPE"
+	STRICT_VALIDATION    = "STRICT_VALIDATION"
+	TIMESTAMP            = "TIMESTAMP"
+	TIMESTAMP_FORMAT     = "TIMESTAMP_FORMAT"
+	RETAIN_SIZE          = "RETAIN_SIZE"
+	SHARED               = "SHARED"
+	SCHEMAID             = "SCHEMAID"
+	KIND                 = "KIND"
+	DELIMITER            = "DELIMITER"
+	VERSION              = "VERSION"
+	EXTRA                = "EXTRA"
+	TEMP                 = "TEMP"
+	WATERMARK_DELAY      = "WATERMARK_DELAY"
+	IDLE_TIMEOUT         = "IDLE_TIMEOUT"
+	WATERMARK_KEY        = "WATERMARK_KEY"
+	ROWKIND_FIELD        = "ROWKIND_FIELD"
+	SCHEMA_COMPATIBILITY = "SCHEMA_COMPATIBILITY"
 
 	XBIGINT   = "BIGINT"
 	XFLOAT    = "FLOAT"
@@ -251,22 +266,27 @@ const (
 )
 
 var StreamTokens = map[string]struct{}{
-	DATASOURCE:        {},
-	KEY:               {},
-	FORMAT:            {},
-	CONF_KEY:          {},
-	TYPE:              {},
-	STRICT_VALIDATION: {},
-	TIMESTAMP:         {},
-	TIMESTAMP_FORMAT:  {},
-	RETAIN_SIZE:       {},
-	SHARED:            {},
-	SCHEMAID:          {},
-	KIND:              {},
-	DELIMITER:         {},
-	VERSION:           {},
-	EXTRA:             {},
-	TEMP:              {},
+	DATASOURCE:           {},
+	KEY:                  {},
+	FORMAT:               {},
+	CONF_KEY:             {},
+	TYPE:                 {},
+	STRICT_VALIDATION:    {},
+	TIMESTAMP:            {},
+	TIMESTAMP_FORMAT:     {},
+	RETAIN_SIZE:          {},
+	SHARED:               {},
+	SCHEMAID:             {},
+	KIND:                 {},
+	DELIMITER:            {},
+	VERSION:              {},
+	EXTRA:                {},
+	TEMP:                 {},
+	WATERMARK_DELAY:      {},
+	IDLE_TIMEOUT:         {},
+	WATERMARK_KEY:        {},
+	ROWKIND_FIELD:        {},
+	SCHEMA_COMPATIBILITY: {},
 }
 
 var StreamDataTypes = map[string]DataType{
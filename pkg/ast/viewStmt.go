@@ -0,0 +1,72 @@
+// Copyright 2026 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ast
+
+// ViewStmt is the statement of CREATE VIEW. A view is a named select statement which can be
+// selected from like a stream. It is expanded into the rules that refer to it when planning.
+type ViewStmt struct {
+	Name   string
+	Select *SelectStatement
+
+	Statement
+}
+
+// FunctionStmt is the statement of CREATE FUNCTION. The function is an inline SQL macro whose
+// body is expanded into the calling expression when planning.
+type FunctionStmt struct {
+	Name   string
+	Params []string
+	Body   Expr
+
+	Statement
+}
+
+type ShowViewsStatement struct {
+	Statement
+}
+
+type DescribeViewStatement struct {
+	Name string
+
+	Statement
+}
+
+type DropViewStatement struct {
+	Name string
+
+	Statement
+}
+
+func (dvs *DescribeViewStatement) GetName() string { return dvs.Name }
+func (dvs *DropViewStatement) GetName() string     { return dvs.Name }
+
+type ShowFunctionsStatement struct {
+	Statement
+}
+
+type DescribeFunctionStatement struct {
+	Name string
+
+	Statement
+}
+
+type DropFunctionStatement struct {
+	Name string
+
+	Statement
+}
+
+func (dfs *DescribeFunctionStatement) GetName() string { return dfs.Name }
+func (dfs *DropFunctionStatement) GetName() string     { return dfs.Name }
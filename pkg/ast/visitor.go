@@ -34,6 +34,7 @@ func Walk(v Visitor, node Node) {
 	switch n := node.(type) {
 	case *SelectStatement:
 		Walk(v, n.Fields)
+		Walk(v, n.DistinctOn)
 		Walk(v, n.Sources)
 		Walk(v, n.Joins)
 		Walk(v, n.Condition)
@@ -73,6 +74,11 @@ func Walk(v Visitor, node Node) {
 	case *Join:
 		Walk(v, n.Expr)
 
+	case *DistinctOn:
+		for _, key := range n.Keys {
+			Walk(v, key)
+		}
+
 	case Dimensions:
 		Walk(v, n.GetWindow())
 		for _, dimension := range n.GetGroups() {
@@ -65,3 +65,14 @@ type UniqueConn interface {
 type PropsConsumer interface {
 	Consume(props map[string]any)
 }
+
+// CheckpointAware is a source feature to be notified of the checkpoint lifecycle. It is only used when the rule qos is
+// at least once. The source can commit the data to the external system after the checkpoint that contains them completes.
+type CheckpointAware interface {
+	// EnableCheckpoint is called before subscribing if the checkpoint is enabled
+	EnableCheckpoint(ctx api.StreamContext)
+	// OnCheckpoint is called before the barrier of the checkpoint is sent. All data ingested before belong to the checkpoint.
+	OnCheckpoint(ctx api.StreamContext, checkpointId int64)
+	// OnCheckpointComplete is called when the checkpoint is completed by all the nodes of the rule
+	OnCheckpointComplete(ctx api.StreamContext, checkpointId int64)
+}
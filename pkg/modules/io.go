@@ -77,3 +77,22 @@ func IsLookupSource(s api.Source) bool {
 		return false
 	}
 }
+
+// SpatialLookupSource is a lookup source which supports looking up by a point on its geometry column.
+// It returns the rows whose geometry contains the point.
+// The planner pushes down the st_within join condition on the geometry column as the lookup key.
+type SpatialLookupSource interface {
+	api.LookupSource
+	// GeometryColumn returns the name of the indexed geometry column declared in the properties. It is called by the
+	// planner without provisioning, so it must only check the properties without any I/O. It returns empty if the
+	// properties declare no spatial index, then the spatial condition is evaluated as a join filter.
+	GeometryColumn(props map[string]any) string
+}
+
+// GetSpatialLookupColumn returns the geometry column declared in the properties if the source supports spatial lookup
+func GetSpatialLookupColumn(s api.Source, props map[string]any) (string, bool) {
+	if ss, ok := s.(SpatialLookupSource); ok {
+		return ss.GeometryColumn(props), true
+	}
+	return "", false
+}